@@ -24,6 +24,7 @@ import (
 	"os"
 	"os/signal"
 	"os/user"
+	"path"
 	"strings"
 	"syscall"
 	"time"
@@ -39,7 +40,7 @@ import (
 
 var (
 	command      = flag.String("c", "", "Command to execute")
-	engineSocket = flag.String("engine", seesaw.EngineSocket, "Seesaw Engine Socket")
+	runtimeFlags = seesaw.NewRuntimeFlags(flag.CommandLine)
 
 	oldTermState *terminal.State
 	prompt       string
@@ -197,17 +198,28 @@ func interactive() {
 	}
 }
 
+func init() {
+	runtimeFlags.Alias("engine", seesaw.FlagEngineSocket)
+}
+
 func main() {
 	flag.Parse()
 
+	rc, err := runtimeFlags.Config()
+	if err != nil {
+		fatalf("Invalid runtime configuration: %v", err)
+	}
+	if err := seesaw.CheckDir(path.Dir(rc.EngineSocket), false); err != nil {
+		fatalf("Engine socket directory: %v", err)
+	}
+
 	ctx := ipc.NewTrustedContext(seesaw.SCLocalCLI)
 
-	var err error
 	seesawConn, err = conn.NewSeesawIPC(ctx)
 	if err != nil {
 		fatalf("Failed to connect to engine: %v", err)
 	}
-	if err := seesawConn.Dial(*engineSocket); err != nil {
+	if err := seesawConn.Dial(rc.EngineSocket); err != nil {
 		fatalf("Failed to connect to engine: %v", err)
 	}
 	defer seesawConn.Close()
@@ -23,14 +23,18 @@ import (
 	"flag"
 	"fmt"
 	"net"
+	"os"
 	"os/user"
+	"path"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/seesaw/common/seesaw"
 	"github.com/google/seesaw/common/server"
 	"github.com/google/seesaw/engine"
 	"github.com/google/seesaw/engine/config"
+	"github.com/google/seesaw/ipvs"
 
 	conf "github.com/dlintw/goconf"
 	log "github.com/golang/glog"
@@ -41,16 +45,20 @@ var (
 		"Seesaw configuration file")
 	clusterFile = flag.String("cluster", config.DefaultEngineConfig().ClusterFile,
 		"Seesaw cluster configuration file")
-	nccSocket = flag.String("ncc_socket", config.DefaultEngineConfig().NCCSocket,
-		"Seesaw NCC socket")
-	socketPath = flag.String("socket", config.DefaultEngineConfig().SocketPath,
-		"Seesaw Engine socket")
 	runUser = flag.String("user", "seesaw",
 		"User to run the engine as after initialization")
 	noDropPrivileges = flag.Bool("no_drop_privileges", false,
 		"If true, do not drop privileges (run as current user)")
+	dryRun = flag.Bool("dry_run", false,
+		"If true, validate the cluster configuration and print the IPVS services that would be programmed, then exit")
+
+	runtimeFlags = seesaw.NewRuntimeFlags(flag.CommandLine)
 )
 
+func init() {
+	runtimeFlags.Alias("socket", seesaw.FlagEngineSocket)
+}
+
 // cfgOpt returns the configuration option from the specified section. If the
 // option does not exist an empty string is returned.
 func cfgOpt(cfg *conf.ConfigFile, section, option string) string {
@@ -89,9 +97,43 @@ func filterAnycastIPs(ips []net.IP) []net.IP {
 	return filtered
 }
 
+// dryRunEngine validates the cluster configuration and prints the IPVS
+// services that it would result in, returning the exit status.
+func dryRunEngine(cfg *config.EngineConfig) int {
+	n, err := config.ReadConfig(cfg.ClusterFile, cfg.ClusterName)
+	if err != nil {
+		log.Errorf("Failed to read cluster configuration: %v", err)
+		return 1
+	}
+	status := 0
+	for _, v := range n.Cluster.Vservers {
+		for _, warning := range v.Warnings {
+			log.Errorf("%v: %s", v.Name, warning)
+			status = 1
+		}
+	}
+	backend := ipvs.NewDummyBackend()
+	if err := engine.NewDryRunEngine(cfg, backend).DryRun(n.Cluster); err != nil {
+		log.Errorf("Failed to program IPVS services: %v", err)
+		status = 1
+	}
+	for _, svc := range backend.Snapshot() {
+		fmt.Println(svc)
+		for _, dst := range svc.Destinations {
+			fmt.Printf("  -> %v weight %d\n", dst, dst.Weight)
+		}
+	}
+	return status
+}
+
 func main() {
 	flag.Parse()
 
+	rc, err := runtimeFlags.Config()
+	if err != nil {
+		log.Exitf("Invalid runtime configuration: %v", err)
+	}
+
 	cfg, err := conf.ReadConfigFile(*configFile)
 	if err != nil {
 		log.Exitf("Failed to read configuration file: %v", err)
@@ -149,8 +191,90 @@ func main() {
 		garpInterval = time.Duration(it) * time.Second
 	}
 
+	healthcheckAF := config.DefaultEngineConfig().HealthcheckAF
+	if opt := cfgOpt(cfg, "cluster", "healthcheck_af"); opt != "" {
+		switch strings.ToLower(opt) {
+		case "ipv4":
+			healthcheckAF = seesaw.IPv4
+		case "ipv6":
+			healthcheckAF = seesaw.IPv6
+		default:
+			log.Exitf("Invalid healthcheck_af %q - must be ipv4 or ipv6", opt)
+		}
+	}
+
 	// The default VRID may be overridden via the config file.
 	vrid := config.DefaultEngineConfig().VRID
+	hcStaleTimeout := config.DefaultEngineConfig().HealthcheckStaleTimeout
+	if cfg.HasOption("cluster", "healthcheck_stale_timeout_sec") {
+		it, err := cfg.GetInt("cluster", "healthcheck_stale_timeout_sec")
+		if err != nil {
+			log.Exitf("Unable to get healthcheck_stale_timeout_sec: %v", err)
+		}
+		hcStaleTimeout = time.Duration(it) * time.Second
+	}
+
+	hcStalePolicy := config.DefaultEngineConfig().HealthcheckStalePolicy
+	if opt := cfgOpt(cfg, "cluster", "healthcheck_stale_policy"); opt != "" {
+		policy, err := config.ParseStalePolicy(opt)
+		if err != nil {
+			log.Exitf("Invalid healthcheck_stale_policy %q - must be freeze or unknown", opt)
+		}
+		hcStalePolicy = policy
+	}
+
+	hcMaxConcurrent := config.DefaultEngineConfig().HealthcheckMaxConcurrent
+	if cfg.HasOption("cluster", "healthcheck_max_concurrent") {
+		n, err := cfg.GetInt("cluster", "healthcheck_max_concurrent")
+		if err != nil {
+			log.Exitf("Unable to get healthcheck_max_concurrent: %v", err)
+		}
+		if n < 1 {
+			log.Exitf("Invalid healthcheck_max_concurrent %d - must be at least 1", n)
+		}
+		hcMaxConcurrent = n
+	}
+
+	ipvsReconcileInterval := config.DefaultEngineConfig().IPVSReconcileInterval
+	if cfg.HasOption("cluster", "ipvs_reconcile_interval_sec") {
+		it, err := cfg.GetInt("cluster", "ipvs_reconcile_interval_sec")
+		if err != nil {
+			log.Exitf("Unable to get ipvs_reconcile_interval_sec: %v", err)
+		}
+		if it < 0 {
+			log.Exitf("Invalid ipvs_reconcile_interval_sec %d - must not be negative", it)
+		}
+		ipvsReconcileInterval = time.Duration(it) * time.Second
+	}
+
+	// IPVS connection timeouts, which are left unchanged unless configured.
+	var ipvsTimeouts ipvs.Timeouts
+	for _, t := range []struct {
+		opt     string
+		timeout *time.Duration
+	}{
+		{"ipvs_tcp_timeout_sec", &ipvsTimeouts.TCP},
+		{"ipvs_tcpfin_timeout_sec", &ipvsTimeouts.TCPFin},
+		{"ipvs_udp_timeout_sec", &ipvsTimeouts.UDP},
+	} {
+		if !cfg.HasOption("cluster", t.opt) {
+			continue
+		}
+		it, err := cfg.GetInt("cluster", t.opt)
+		if err != nil {
+			log.Exitf("Unable to get %s: %v", t.opt, err)
+		}
+		if it < 1 {
+			log.Exitf("Invalid %s %d - must be at least 1", t.opt, it)
+		}
+		*t.timeout = time.Duration(it) * time.Second
+	}
+	if ipvsTimeouts != (ipvs.Timeouts{}) {
+		if err := ipvsTimeouts.Validate(); err != nil {
+			log.Exitf("Invalid IPVS timeouts: %v", err)
+		}
+	}
+
 	if cfg.HasOption("cluster", "vrid") {
 		id, err := cfg.GetInt("cluster", "vrid")
 		if err != nil {
@@ -162,6 +286,21 @@ func main() {
 		vrid = uint8(id)
 	}
 
+	// The IPVS connection synchronisation daemon is only managed if a
+	// multicast interface is configured.
+	ipvsSyncInterface := cfgOpt(cfg, "cluster", "ipvs_sync_interface")
+	var ipvsSyncID uint8
+	if cfg.HasOption("cluster", "ipvs_sync_id") {
+		id, err := cfg.GetInt("cluster", "ipvs_sync_id")
+		if err != nil {
+			log.Exitf("Unable to get ipvs_sync_id: %v", err)
+		}
+		if id < 0 || id > 255 {
+			log.Exitf("Invalid ipvs_sync_id %d - must be between 0 and 255 inclusive", id)
+		}
+		ipvsSyncID = uint8(id)
+	}
+
 	// Optional primary, secondary and tertiary configuration servers.
 	configServers := make([]string, 0)
 	for _, level := range []string{"primary", "secondary", "tertiary"} {
@@ -233,6 +372,22 @@ func main() {
 		}
 	}
 
+	// Routes to advertise while this node is the HA leader.
+	var routes []*config.Route
+	if cfg.HasSection("bgp_routes") {
+		opts, err := cfg.GetOptions("bgp_routes")
+		if err != nil {
+			log.Exitf("Unable to get bgp_routes options: %v", err)
+		}
+		for _, opt := range opts {
+			r, err := config.ParseRoute(cfgOpt(cfg, "bgp_routes", opt))
+			if err != nil {
+				log.Exitf("Invalid bgp_routes option %q: %v", opt, err)
+			}
+			routes = append(routes, r)
+		}
+	}
+
 	// Override some of the defaults.
 	engineCfg := config.DefaultEngineConfig()
 	engineCfg.AnycastEnabled = anycastEnabled
@@ -242,20 +397,35 @@ func main() {
 	engineCfg.ClusterName = clusterName
 	engineCfg.ClusterVIP.IPv4Addr = clusterVIPv4
 	engineCfg.ClusterVIP.IPv6Addr = clusterVIPv6
+	engineCfg.HealthcheckAF = healthcheckAF
+	engineCfg.HealthcheckMaxConcurrent = hcMaxConcurrent
+	engineCfg.HealthcheckSocket = rc.HealthcheckSocket
+	engineCfg.HealthcheckStalePolicy = hcStalePolicy
+	engineCfg.HealthcheckStaleTimeout = hcStaleTimeout
+	engineCfg.IPVSReconcileInterval = ipvsReconcileInterval
+	engineCfg.IPVSSyncID = ipvsSyncID
+	engineCfg.IPVSSyncInterface = ipvsSyncInterface
+	engineCfg.IPVSTimeouts = ipvsTimeouts
 	engineCfg.LBInterface = lbInterface
-	engineCfg.NCCSocket = *nccSocket
+	engineCfg.NCCSocket = rc.NCCSocket
 	engineCfg.Node.IPv4Addr = nodeIPv4
 	engineCfg.Node.IPv6Addr = nodeIPv6
 	engineCfg.NodeInterface = nodeInterface
 	engineCfg.Peer.IPv4Addr = peerIPv4
 	engineCfg.Peer.IPv6Addr = peerIPv6
+	engineCfg.Routes = routes
 	engineCfg.ServiceAnycastIPv4 = serviceAnycastIPv4
 	engineCfg.ServiceAnycastIPv6 = serviceAnycastIPv6
-	engineCfg.SocketPath = *socketPath
+	engineCfg.SocketPath = rc.EngineSocket
+	engineCfg.SyncPort = rc.SyncPort
 	engineCfg.VRID = vrid
 	engineCfg.UseVMAC = useVMAC
 	engineCfg.GratuitousARPInterval = garpInterval
 
+	if *dryRun {
+		os.Exit(dryRunEngine(&engineCfg))
+	}
+
 	// removes previous leftover socket.
 	if err := server.RemoveUnixSocket(engineCfg.SocketPath); err != nil {
 		log.Exitf("Failed to remove socket: %v", err)
@@ -281,7 +451,12 @@ func main() {
 	// Gentlemen, start your engines...
 	engine := engine.NewEngine(&engineCfg)
 	server.ShutdownHandler(engine)
-	server.ServerRunDirectory("engine", uid, gid)
+	if err := server.ServerRunDirectory(rc.RunPath, "engine", uid, gid); err != nil {
+		log.Exitf("Failed to create run directory: %v", err)
+	}
+	if err := seesaw.CheckDir(path.Dir(engineCfg.SocketPath), true); err != nil {
+		log.Exitf("Engine socket directory: %v", err)
+	}
 
 	// Drop privileges before starting engine.
 	if !*noDropPrivileges {
@@ -30,9 +30,6 @@ import (
 )
 
 var (
-	engineSocket = flag.String("engine", seesaw.EngineSocket,
-		"Seesaw Engine Socket")
-
 	configCheckInterval = flag.Duration("config_check_interval", 15*time.Second,
 		"How frequently to poll the engine for HAConfig changes")
 
@@ -51,6 +48,9 @@ var (
 	preempt = flag.Bool("preempt", false,
 		"If true, a higher priority node will preempt the mastership of a lower priority node")
 
+	preemptDelay = flag.Duration("preempt_delay", 0,
+		"How long a lower priority master must be seen for before it is preempted")
+
 	statusReportInterval = flag.Duration("status_report_interval", 3*time.Second,
 		"How frequently to report the current HAStatus to the engine")
 
@@ -74,8 +74,15 @@ var (
 
 	testVRID = flag.Int("vrid", 100,
 		"VRID - used only when test_mode=true")
+
+	runtimeFlags = seesaw.NewRuntimeFlags(flag.CommandLine)
+	engineSocket string
 )
 
+func init() {
+	runtimeFlags.Alias("engine", seesaw.FlagEngineSocket)
+}
+
 // config reads the HAConfig from the engine. It does not return until it
 // successfully retrieves an HAConfig that has HA peering enabled.
 func config(e ha.Engine) *seesaw.HAConfig {
@@ -107,12 +114,18 @@ func engine() ha.Engine {
 		}
 		return &ha.DummyEngine{Config: &config}
 	}
-	return &ha.EngineClient{Socket: *engineSocket}
+	return &ha.EngineClient{Socket: engineSocket}
 }
 
 func main() {
 	flag.Parse()
 
+	rc, err := runtimeFlags.Config()
+	if err != nil {
+		log.Exitf("Invalid runtime configuration: %v", err)
+	}
+	engineSocket = rc.EngineSocket
+
 	log.Infof("Starting up")
 	engine := engine()
 	config := config(engine)
@@ -128,11 +141,12 @@ func main() {
 		ConfigCheckRetryDelay:   *configCheckRetryDelay,
 		MasterAdvertInterval:    *masterAdvertInterval,
 		Preempt:                 *preempt,
+		PreemptDelay:            *preemptDelay,
 		StatusReportInterval:    *statusReportInterval,
 		StatusReportMaxFailures: *statusReportMaxFailures,
 		StatusReportRetryDelay:  *statusReportRetryDelay,
 	}
-	n := ha.NewNode(nc, conn, engine, *engineSocket)
+	n := ha.NewNode(nc, conn, engine, engineSocket)
 	server.ShutdownHandler(n)
 
 	if err = n.Run(); err != nil {
@@ -21,9 +21,13 @@ package main
 
 import (
 	"flag"
+	"os"
 
+	"github.com/google/seesaw/common/seesaw"
 	"github.com/google/seesaw/common/server"
 	"github.com/google/seesaw/healthcheck"
+
+	log "github.com/golang/glog"
 )
 
 var (
@@ -31,18 +35,14 @@ var (
 		healthcheck.DefaultServerConfig().BatchDelay,
 		"The maximum time to wait for batch to fill before sending the batch to the engine")
 
-	batchSize = flag.Int("batch_size",
-		healthcheck.DefaultServerConfig().BatchSize,
+	maxBatchSize = flag.Int("max_batch_size",
+		healthcheck.DefaultServerConfig().MaxBatchSize,
 		"The maximum number of notifications to include in a single RPC call to the engine")
 
 	channelSize = flag.Int("channel_size",
 		healthcheck.DefaultServerConfig().ChannelSize,
 		"The size of the notification channel")
 
-	engineSocket = flag.String("engine",
-		healthcheck.DefaultServerConfig().EngineSocket,
-		"Seesaw Engine Socket")
-
 	maxFailures = flag.Int("max_failures",
 		healthcheck.DefaultServerConfig().MaxFailures,
 		"The maximum number of consecutive notification failures")
@@ -51,9 +51,13 @@ var (
 		healthcheck.DefaultServerConfig().NotifyInterval,
 		"The time between notifications")
 
+	steadyIntervals = flag.Int("steady_intervals",
+		healthcheck.DefaultServerConfig().SteadyIntervals,
+		"The number of notify intervals between notifications for healthchecks that have not changed state")
+
 	fetchInterval = flag.Duration("fetch_interval",
 		healthcheck.DefaultServerConfig().FetchInterval,
-		"The time between healthcheck config fetches from the Engine")
+		"The maximum time between healthcheck config fetches from the Engine, which are also made as soon as the configs change")
 
 	retryDelay = flag.Duration("retry_delay",
 		healthcheck.DefaultServerConfig().RetryDelay,
@@ -62,22 +66,87 @@ var (
 	dryRun = flag.Bool("dry_run",
 		healthcheck.DefaultServerConfig().DryRun,
 		"Skips actual check and always return healthy as result")
+
+	cancelOverlapping = flag.Bool("cancel_overlapping",
+		healthcheck.DefaultServerConfig().CancelOverlapping,
+		"Cancels a healthcheck that is still running when it is next due, rather than skipping the new run")
+
+	unprivilegedPing = flag.Bool("unprivileged_ping",
+		healthcheck.DefaultServerConfig().UnprivilegedPing,
+		"Uses ICMP datagram sockets for ping healthchecks, rather than raw sockets")
+
+	scriptDir = flag.String("script_dir",
+		healthcheck.DefaultServerConfig().ScriptDir,
+		"The directory containing scripts that may be run by script healthchecks")
+
+	maxScripts = flag.Int("max_scripts",
+		healthcheck.DefaultServerConfig().MaxScripts,
+		"The maximum number of script healthchecks to run concurrently")
+
+	historySize = flag.Int("history_size",
+		healthcheck.DefaultServerConfig().HistorySize,
+		"The number of recent results retained for each healthcheck")
+
+	jitterPercent = flag.Int("jitter_percent",
+		healthcheck.DefaultServerConfig().JitterPercent,
+		"Randomly varies the time between healthcheck runs by up to this percentage of the interval (0-50)")
+
+	runtimeFlags = seesaw.NewRuntimeFlags(flag.CommandLine)
 )
 
+func init() {
+	runtimeFlags.Alias("engine", seesaw.FlagEngineSocket)
+	flag.IntVar(maxBatchSize, "batch_size", *maxBatchSize, "Deprecated: use --max_batch_size")
+}
+
 func main() {
 	flag.Parse()
 
+	rc, err := runtimeFlags.Config()
+	if err != nil {
+		log.Exitf("Invalid runtime configuration: %v", err)
+	}
+	// The engine socket directory may not exist until the engine has
+	// started, however the run directory itself must be usable.
+	if err := seesaw.CheckDir(rc.RunPath, false); err != nil {
+		log.Exitf("Run directory: %v", err)
+	}
+
+	if *historySize < 0 {
+		log.Exitf("Invalid history size %d, must not be negative", *historySize)
+	}
+	if *steadyIntervals < 1 {
+		log.Exitf("Invalid steady intervals %d, must be at least 1", *steadyIntervals)
+	}
+	if *jitterPercent < 0 || *jitterPercent > 50 {
+		log.Exitf("Invalid jitter percentage %d, must be between 0 and 50", *jitterPercent)
+	}
+
 	cfg := healthcheck.DefaultServerConfig()
 
 	cfg.BatchDelay = *batchDelay
-	cfg.BatchSize = *batchSize
+	cfg.MaxBatchSize = *maxBatchSize
 	cfg.ChannelSize = *channelSize
-	cfg.EngineSocket = *engineSocket
+	cfg.EngineSocket = rc.EngineSocket
 	cfg.MaxFailures = *maxFailures
 	cfg.NotifyInterval = *notifyInterval
+	cfg.SteadyIntervals = *steadyIntervals
 	cfg.FetchInterval = *fetchInterval
 	cfg.RetryDelay = *retryDelay
 	cfg.DryRun = *dryRun
+	cfg.CancelOverlapping = *cancelOverlapping
+	cfg.UnprivilegedPing = *unprivilegedPing
+	cfg.ScriptDir = *scriptDir
+	cfg.MaxScripts = *maxScripts
+	cfg.JitterPercent = *jitterPercent
+	cfg.HistorySize = *historySize
+	cfg.Socket = rc.HealthcheckSocket
+
+	// The healthcheck socket is only used to query recent results, so
+	// healthchecks still run if its directory cannot be created.
+	if err := server.ServerRunDirectory(rc.RunPath, "healthcheck", os.Getuid(), os.Getgid()); err != nil {
+		log.Warningf("Healthcheck socket directory: %v", err)
+	}
 
 	hc := healthcheck.NewServer(&cfg)
 	server.ShutdownHandler(hc)
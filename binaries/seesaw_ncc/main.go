@@ -27,6 +27,7 @@ package main
 import (
 	"flag"
 	"os"
+	"path"
 
 	"github.com/google/seesaw/common/seesaw"
 	"github.com/google/seesaw/common/server"
@@ -35,18 +36,32 @@ import (
 	log "github.com/golang/glog"
 )
 
-var socketPath = flag.String("socket", seesaw.NCCSocket, "Seesaw NCC socket")
+var runtimeFlags = seesaw.NewRuntimeFlags(flag.CommandLine)
+
+func init() {
+	runtimeFlags.Alias("socket", seesaw.FlagNCCSocket)
+}
 
 func main() {
 	flag.Parse()
 
+	rc, err := runtimeFlags.Config()
+	if err != nil {
+		log.Exitf("Invalid runtime configuration: %v", err)
+	}
+
 	if os.Getuid() != 0 {
 		log.Fatal("must be run as root")
 	}
 
 	ncc.Init()
-	ncc := ncc.NewServer(*socketPath)
+	ncc := ncc.NewServer(rc.NCCSocket)
 	server.ShutdownHandler(ncc)
-	server.ServerRunDirectory("ncc", 0, 0)
+	if err := server.ServerRunDirectory(rc.RunPath, "ncc", 0, 0); err != nil {
+		log.Exitf("Failed to create run directory: %v", err)
+	}
+	if err := seesaw.CheckDir(path.Dir(rc.NCCSocket), true); err != nil {
+		log.Exitf("NCC socket directory: %v", err)
+	}
 	ncc.Run()
 }
@@ -56,6 +56,18 @@ func failover(cli *SeesawCLI, args []string) error {
 	return nil
 }
 
+func reconcile(cli *SeesawCLI, args []string) error {
+	rs, err := cli.seesaw.Reconcile()
+	if err != nil {
+		return fmt.Errorf("IPVS reconciliation failed: %v", err)
+	}
+	if rs.LastError != "" {
+		return fmt.Errorf("IPVS reconciliation made %d corrections, with errors: %v", rs.LastCorrections, rs.LastError)
+	}
+	fmt.Printf("IPVS reconciliation made %d corrections.\n", rs.LastCorrections)
+	return nil
+}
+
 func help(cli *SeesawCLI, args []string) error {
  	fmt.Println("Use ? for context-aware command completions.")
 	return nil
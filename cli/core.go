@@ -67,6 +67,7 @@ var commands = []Command{
 	{"help", nil, help},
 	{"override", &commandOverride, nil},
 	{"quit", nil, exit}, // An alias for exit, matches JunOS behavior.
+	{"reconcile", nil, reconcile},
 	{"show", &commandShow, nil},
 }
 
@@ -81,9 +82,16 @@ var commandOverride = []Command{
 }
 
 var commandOverrideVserver = []Command{
+	{"passive", &commandOverrideVserverPassive, nil},
 	{"state", &commandOverrideVserverState, nil},
 }
 
+var commandOverrideVserverPassive = []Command{
+	{"default", nil, overrideVserverPassiveDefault},
+	{"disabled", nil, overrideVserverPassiveDisabled},
+	{"enabled", nil, overrideVserverPassiveEnabled},
+}
+
 var commandOverrideVserverState = []Command{
 	{"default", nil, overrideVserverStateDefault},
 	{"disabled", nil, overrideVserverStateDisabled},
@@ -95,7 +103,9 @@ var commandShow = []Command{
 	{"backends", nil, showBackend},
 	{"destinations", nil, showDestination},
 	{"ha", nil, showHAStatus},
+	{"healthchecks", nil, showHealthchecks},
 	{"nodes", nil, showNode},
+	{"reconcile", nil, showReconcile},
 	{"version", nil, showVersion},
 	{"vlans", nil, showVLANs},
 	{"vservers", nil, showVserver},
@@ -104,6 +114,7 @@ var commandShow = []Command{
 
 var commandShowBGP = []Command{
 	{"neighbors", nil, showBGPNeighbors},
+	{"routes", nil, showBGPRoutes},
 }
 
 // FindCommand tokenises a command line and attempts to locate the
@@ -77,6 +77,33 @@ func showBGPNeighbors(cli *SeesawCLI, args []string) error {
 	return nil
 }
 
+func showBGPRoutes(cli *SeesawCLI, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("Too many arguments")
+	}
+	routes, err := cli.seesaw.Routes()
+	if err != nil {
+		return fmt.Errorf("Failed to get BGP routes: %v", err)
+	}
+
+	printHdr("BGP Routes")
+	for i, r := range routes {
+		state := "withdrawn"
+		if r.Advertised {
+			state = "advertised"
+		}
+		fmt.Printf("[%3d] %v (%s, wanted %v)\n", i+1, &r.Network, state, r.Wanted)
+		if !r.LastChange.IsZero() {
+			printVal("Last Change:", r.LastChange.Format(timeStamp))
+		}
+		if r.Failures > 0 {
+			printVal("Failures:", r.Failures)
+			printVal("Last Error:", r.LastError)
+		}
+	}
+	return nil
+}
+
 func showVLANs(cli *SeesawCLI, args []string) error {
 	if len(args) > 1 {
 		fmt.Println("show vlans [<id>|<ip>]")
@@ -158,11 +185,77 @@ func showHAStatus(cli *SeesawCLI, args []string) error {
 	printVal("Transitions:", ha.Transitions)
 	printVal("Advertisements Sent:", ha.Sent)
 	printVal("Advertisements Rcvd:", ha.Received)
+	if ha.PreemptIn > 0 {
+		printVal("Preempting In:", ha.PreemptIn.Round(time.Second))
+	}
 	printVal("Last Update:", ha.LastUpdate.Format(timeStamp))
 
 	return nil
 }
 
+func showHealthchecks(cli *SeesawCLI, args []string) error {
+	hs, err := cli.seesaw.HealthcheckStatus()
+	if err != nil {
+		return fmt.Errorf("Healthcheck status: %v", err)
+	}
+
+	lastStr := "never"
+	if !hs.LastDelivery.IsZero() {
+		age := time.Duration(time.Now().Sub(hs.LastDelivery).Seconds()) * time.Second
+		lastStr = fmt.Sprintf("%s (%s ago)", hs.LastDelivery.Format(timeStamp), age)
+	}
+	state := "ok"
+	if hs.Stale {
+		state = fmt.Sprintf("STALE (since %s)", hs.StaleSince.Format(timeStamp))
+	}
+
+	printHdr("Healthcheck Status")
+	printVal("State:", state)
+	printVal("Last Delivery:", lastStr)
+	printVal("Stale Timeout:", hs.Timeout)
+	printVal("Stale Events:", hs.StaleCount)
+	printVal("Skipped Runs:", hs.SkippedRuns)
+	printVal("Throttled Runs:", hs.ThrottledRuns)
+	for _, src := range hs.Sources {
+		srcState := "ok"
+		if src.Stale {
+			srcState = "stale"
+		}
+		printVal(label(src.Component.String()+":", 2, 18), fmt.Sprintf("%s (%s)", src.LastDelivery.Format(timeStamp), srcState))
+	}
+
+	return nil
+}
+
+func showReconcile(cli *SeesawCLI, args []string) error {
+	rs, err := cli.seesaw.ReconcileStatus()
+	if err != nil {
+		return fmt.Errorf("IPVS reconciliation status: %v", err)
+	}
+
+	interval := "disabled"
+	if rs.Interval > 0 {
+		interval = rs.Interval.String()
+	}
+	lastStr := "never"
+	if !rs.LastRun.IsZero() {
+		lastStr = fmt.Sprintf("%s (took %s)", rs.LastRun.Format(timeStamp), rs.LastDuration)
+	}
+
+	printHdr("IPVS Reconciliation")
+	printVal("Interval:", interval)
+	printVal("Last Run:", lastStr)
+	printVal("Last Corrections:", rs.LastCorrections)
+	if rs.LastError != "" {
+		printVal("Last Error:", rs.LastError)
+	}
+	printVal("Runs:", rs.Runs)
+	printVal("Failures:", rs.Failures)
+	printVal("Corrections:", rs.Corrections)
+
+	return nil
+}
+
 func configStatus(cli *SeesawCLI, args []string) error {
 	cs, err := cli.seesaw.ConfigStatus()
 	if err != nil {
@@ -204,6 +297,9 @@ func showNode(cli *SeesawCLI, args []string) error {
 		printVal("Anycast Enabled:", node.AnycastEnabled)
 		printVal("BGP Enabled:", node.BGPEnabled)
 		printVal("Vservers Enabled:", node.VserversEnabled)
+		if cs.IPVSTimeouts != nil {
+			printVal("IPVS Timeouts:", cs.IPVSTimeouts)
+		}
 		return nil
 	}
 	printHdr("Nodes")
@@ -356,8 +452,18 @@ func showDestination(cli *SeesawCLI, args []string) error {
 		printVal("Backend:", d.Backend.Hostname)
 		printVal("Enabled:", d.Enabled)
 		printVal("Healthy:", d.Healthy)
+		printVal("Passive:", d.Passive)
 		printVal("Active:", d.Active)
 		printVal("Weight:", d.Weight)
+		printVal("Drained:", d.Drained)
+		if d.WarmupRequired > 0 {
+			printVal("Warmup:", fmt.Sprintf("warming up, %d/%d", d.WarmupHealthy, d.WarmupRequired))
+		}
+		if d.Stats != nil && d.Stats.DestinationStats != nil {
+			printVal("Active Conns:", d.Stats.ActiveConns)
+			printVal("Inactive Conns:", d.Stats.InactiveConns)
+		}
+		printDestinationHistory(cli, d)
 		return nil
 	}
 
@@ -370,8 +476,44 @@ func showDestination(cli *SeesawCLI, args []string) error {
 	return nil
 }
 
+// printDestinationHistory prints the recent healthcheck results for a
+// destination, which are unavailable if the healthcheck component is not
+// running.
+func printDestinationHistory(cli *SeesawCLI, d *seesaw.Destination) {
+	dh, err := cli.seesaw.DestinationHistory(d.Name)
+	if err != nil {
+		fmt.Println()
+		fmt.Printf("  Healthcheck history unavailable: %v\n", err)
+		return
+	}
+	for _, hc := range dh.Healthchecks {
+		fmt.Println()
+		fmt.Printf("  Healthcheck %s:\n", hc.Name)
+		if len(hc.Results) == 0 {
+			fmt.Println("    No results")
+		}
+		for _, r := range hc.Results {
+			result := "FAILURE"
+			if r.Success {
+				result = "SUCCESS"
+			}
+			fmt.Printf("    %s %7s %-7s %s\n", r.Time.Format(timeStamp),
+				r.Duration.Round(time.Millisecond), result, r.Message)
+		}
+	}
+}
+
 func destSummary(d *seesaw.Destination, vservers map[string]*seesaw.Vserver) string {
 	status := statusSummary(d.Enabled, d.Healthy, d.Active)
+	if d.WarmupRequired > 0 {
+		status = fmt.Sprintf("%s, warming up %d/%d", status, d.WarmupHealthy, d.WarmupRequired)
+	}
+	if d.Passive {
+		status += ", passive"
+	}
+	if d.Drained {
+		status += ", drained"
+	}
 	if v, ok := vservers[d.VserverName]; ok && !v.Enabled {
 		status = "vserver disabled"
 	}
@@ -475,6 +617,9 @@ func printVserver(vserver *seesaw.Vserver) {
 
 	status := fmt.Sprintf("%s (override state %s; config state %s)",
 		vserverStatus, vserver.OverrideState, configStatus)
+	if vserver.PassiveOverride != seesaw.OverrideDefault {
+		status = fmt.Sprintf("%s, passive override %s", status, vserver.PassiveOverride)
+	}
 
 	printHdr("Vserver")
 	printVal("Name:", vserver.Name)
@@ -509,6 +654,23 @@ func printVserver(vserver *seesaw.Vserver) {
 		watermarkStatus := fmt.Sprintf("Low %.2f, High %.2f, Currently %.2f",
 			svc.LowWatermark, svc.HighWatermark, svc.CurrentWatermark)
 		fmt.Printf("%s %s\n", label("Watermarks:", 8, 20), watermarkStatus)
+
+		if svc.ConnImbalance > 0 {
+			fmt.Printf("%s %.2f (max/min active conns)\n", label("Imbalance:", 8, 20), svc.ConnImbalance)
+		}
+		var names []string
+		for name := range svc.Destinations {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			d := svc.Destinations[name]
+			if d.Stats == nil || d.Stats.DestinationStats == nil {
+				continue
+			}
+			fmt.Printf("%s %d active, %d inactive conns\n", label(name+":", 8, 20),
+				d.Stats.ActiveConns, d.Stats.InactiveConns)
+		}
 	}
 
 	if len(vserver.Warnings) > 0 {
@@ -52,3 +52,33 @@ func overrideVserver(cli *SeesawCLI, args []string, state seesaw.OverrideState)
 	}
 	return nil
 }
+
+func overrideVserverPassiveDefault(cli *SeesawCLI, args []string) error {
+	return overridePassive(cli, args, seesaw.OverrideDefault)
+}
+
+func overrideVserverPassiveDisabled(cli *SeesawCLI, args []string) error {
+	return overridePassive(cli, args, seesaw.OverrideDisable)
+}
+
+func overrideVserverPassiveEnabled(cli *SeesawCLI, args []string) error {
+	return overridePassive(cli, args, seesaw.OverrideEnable)
+}
+
+func overridePassive(cli *SeesawCLI, args []string, state seesaw.OverrideState) error {
+	if len(args) != 1 {
+		fmt.Println("override vserver passive <default|disabled|enabled> <vserver>")
+		return errors.New("Incorrect arguments given.")
+	}
+	vservers, err := cli.seesaw.Vservers()
+	if err != nil {
+		return fmt.Errorf("Failed to retrieve list of vservers: %v", err)
+	}
+	if _, ok := vservers[args[0]]; !ok {
+		return fmt.Errorf("No such vserver - %s", args[0])
+	}
+	if err := cli.seesaw.OverridePassive(&seesaw.PassiveOverride{VserverName: args[0], OverrideState: state}); err != nil {
+		return fmt.Errorf("Override vserver passive failed - %s", err)
+	}
+	return nil
+}
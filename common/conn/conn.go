@@ -35,11 +35,17 @@ type EngineConn interface {
 	ClusterStatus() (*seesaw.ClusterStatus, error)
 	ConfigStatus() (*seesaw.ConfigStatus, error)
 	HAStatus() (*seesaw.HAStatus, error)
+	HealthcheckStatus() (*seesaw.HealthcheckStatus, error)
+	DestinationHistory(destination string) (*seesaw.DestinationHistory, error)
 
 	ConfigSource(source string) (string, error)
 	ConfigReload() error
 
 	BGPNeighbors() ([]*quagga.Neighbor, error)
+	Routes() ([]*seesaw.RouteStatus, error)
+
+	Reconcile() (*seesaw.ReconcileStatus, error)
+	ReconcileStatus() (*seesaw.ReconcileStatus, error)
 
 	VLANs() (*seesaw.VLANs, error)
 
@@ -49,6 +55,7 @@ type EngineConn interface {
 	OverrideBackend(override *seesaw.BackendOverride) error
 	OverrideDestination(override *seesaw.DestinationOverride) error
 	OverrideVserver(override *seesaw.VserverOverride) error
+	OverridePassive(override *seesaw.PassiveOverride) error
 
 	Failover() error
 }
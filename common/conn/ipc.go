@@ -90,6 +90,27 @@ func (c *engineIPC) HAStatus() (*seesaw.HAStatus, error) {
 	return &ha, nil
 }
 
+// HealthcheckStatus requests the delivery status of healthcheck notifications
+// to the Seesaw Engine.
+func (c *engineIPC) HealthcheckStatus() (*seesaw.HealthcheckStatus, error) {
+	var hs seesaw.HealthcheckStatus
+	if err := c.client.Call("SeesawEngine.HealthcheckStatus", c.ctx, &hs); err != nil {
+		return nil, err
+	}
+	return &hs, nil
+}
+
+// DestinationHistory requests the recent healthcheck results for the named
+// destination.
+func (c *engineIPC) DestinationHistory(destination string) (*seesaw.DestinationHistory, error) {
+	var dh seesaw.DestinationHistory
+	args := &ipc.DestinationHistory{Ctx: c.ctx, Destination: destination}
+	if err := c.client.Call("SeesawEngine.DestinationHistory", args, &dh); err != nil {
+		return nil, err
+	}
+	return &dh, nil
+}
+
 // ConfigSource requests the configuration source be changed to the
 // specified source. An empty string results in the source remaining
 // unchanged. The current configuration source is returned.
@@ -116,6 +137,35 @@ func (c *engineIPC) BGPNeighbors() ([]*quagga.Neighbor, error) {
 	return bn.Neighbors, nil
 }
 
+// Routes requests the status of the routes advertised by this seesaw.
+func (c *engineIPC) Routes() ([]*seesaw.RouteStatus, error) {
+	var r seesaw.Routes
+	if err := c.client.Call("SeesawEngine.Routes", c.ctx, &r); err != nil {
+		return nil, err
+	}
+	return r.Routes, nil
+}
+
+// Reconcile requests that the IPVS table be reconciled with the configured
+// services, returning the resulting reconciliation status.
+func (c *engineIPC) Reconcile() (*seesaw.ReconcileStatus, error) {
+	var rs seesaw.ReconcileStatus
+	if err := c.client.Call("SeesawEngine.Reconcile", c.ctx, &rs); err != nil {
+		return nil, err
+	}
+	return &rs, nil
+}
+
+// ReconcileStatus requests the status of the reconciliation of the IPVS
+// table.
+func (c *engineIPC) ReconcileStatus() (*seesaw.ReconcileStatus, error) {
+	var rs seesaw.ReconcileStatus
+	if err := c.client.Call("SeesawEngine.ReconcileStatus", c.ctx, &rs); err != nil {
+		return nil, err
+	}
+	return &rs, nil
+}
+
 // VLANs requests a list of VLANs configured on the cluster.
 func (c *engineIPC) VLANs() (*seesaw.VLANs, error) {
 	var v seesaw.VLANs
@@ -161,6 +211,12 @@ func (c *engineIPC) OverrideVserver(vserver *seesaw.VserverOverride) error {
 	return c.client.Call("SeesawEngine.OverrideVserver", override, nil)
 }
 
+// OverridePassive requests that the specified PassiveOverride be applied.
+func (c *engineIPC) OverridePassive(passive *seesaw.PassiveOverride) error {
+	override := &ipc.Override{Ctx: c.ctx, Passive: passive}
+	return c.client.Call("SeesawEngine.OverridePassive", override, nil)
+}
+
 // Failover requests a failover between the Seesaw Nodes.
 func (c *engineIPC) Failover() error {
 	return c.client.Call("SeesawEngine.Failover", c.ctx, nil)
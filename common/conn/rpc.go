@@ -113,6 +113,27 @@ func (c *engineRPC) HAStatus() (*seesaw.HAStatus, error) {
 	return &ha, nil
 }
 
+// HealthcheckStatus requests the delivery status of healthcheck notifications
+// to the Seesaw Engine.
+func (c *engineRPC) HealthcheckStatus() (*seesaw.HealthcheckStatus, error) {
+	var hs seesaw.HealthcheckStatus
+	if err := c.client.Call("SeesawECU.HealthcheckStatus", c.ctx, &hs); err != nil {
+		return nil, err
+	}
+	return &hs, nil
+}
+
+// DestinationHistory requests the recent healthcheck results for the named
+// destination.
+func (c *engineRPC) DestinationHistory(destination string) (*seesaw.DestinationHistory, error) {
+	var dh seesaw.DestinationHistory
+	args := &ipc.DestinationHistory{Ctx: c.ctx, Destination: destination}
+	if err := c.client.Call("SeesawECU.DestinationHistory", args, &dh); err != nil {
+		return nil, err
+	}
+	return &dh, nil
+}
+
 // ConfigSource requests the configuration source be changed to the
 // specified source. An empty string results in the source remaining
 // unchanged. The current configuration source is returned.
@@ -139,6 +160,35 @@ func (c *engineRPC) BGPNeighbors() ([]*quagga.Neighbor, error) {
 	return bn.Neighbors, nil
 }
 
+// Routes requests the status of the routes advertised by this seesaw.
+func (c *engineRPC) Routes() ([]*seesaw.RouteStatus, error) {
+	var r seesaw.Routes
+	if err := c.client.Call("SeesawECU.Routes", c.ctx, &r); err != nil {
+		return nil, err
+	}
+	return r.Routes, nil
+}
+
+// Reconcile requests that the IPVS table be reconciled with the configured
+// services, returning the resulting reconciliation status.
+func (c *engineRPC) Reconcile() (*seesaw.ReconcileStatus, error) {
+	var rs seesaw.ReconcileStatus
+	if err := c.client.Call("SeesawECU.Reconcile", c.ctx, &rs); err != nil {
+		return nil, err
+	}
+	return &rs, nil
+}
+
+// ReconcileStatus requests the status of the reconciliation of the IPVS
+// table.
+func (c *engineRPC) ReconcileStatus() (*seesaw.ReconcileStatus, error) {
+	var rs seesaw.ReconcileStatus
+	if err := c.client.Call("SeesawECU.ReconcileStatus", c.ctx, &rs); err != nil {
+		return nil, err
+	}
+	return &rs, nil
+}
+
 // VLANs requests a list of VLANs configured on the cluster.
 func (c *engineRPC) VLANs() (*seesaw.VLANs, error) {
 	var v seesaw.VLANs
@@ -184,6 +234,12 @@ func (c *engineRPC) OverrideVserver(vserver *seesaw.VserverOverride) error {
 	return c.client.Call("SeesawECU.OverrideVserver", override, nil)
 }
 
+// OverridePassive requests that the specified PassiveOverride be applied.
+func (c *engineRPC) OverridePassive(passive *seesaw.PassiveOverride) error {
+	override := &ipc.Override{Ctx: c.ctx, Passive: passive}
+	return c.client.Call("SeesawECU.OverridePassive", override, nil)
+}
+
 // Failover requests a failover between the Seesaw Nodes.
 func (c *engineRPC) Failover() error {
 	return c.client.Call("SeesawECU.Failover", c.ctx, nil)
@@ -26,6 +26,7 @@ import (
 	"strings"
 
 	"github.com/google/seesaw/common/seesaw"
+	"github.com/google/seesaw/ipvs"
 	spb "github.com/google/seesaw/pb/seesaw"
 )
 
@@ -244,10 +245,24 @@ type HAState struct {
 	State spb.HaState
 }
 
+// DestinationHistory contains data for a destination history IPC.
+type DestinationHistory struct {
+	Ctx         *Context
+	Destination string
+}
+
+// Connections contains data for an IPVS connections IPC.
+type Connections struct {
+	Ctx    *Context
+	Filter ipvs.ConnectionFilter
+	Max    int
+}
+
 // Override contains data for an override IPC.
 type Override struct {
 	Ctx         *Context
 	Vserver     *seesaw.VserverOverride
 	Destination *seesaw.DestinationOverride
 	Backend     *seesaw.BackendOverride
+	Passive     *seesaw.PassiveOverride
 }
@@ -0,0 +1,187 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package seesaw
+
+// This file contains the runtime configuration shared by the Seesaw
+// components, such as the run directory, socket paths and ports.
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+// DefaultSyncPort is the default port used for sync'ing with a peer node.
+const DefaultSyncPort = 10258
+
+// Environment variables that override the default runtime configuration.
+const (
+	EnvRunPath           = "SEESAW_RUN_PATH"
+	EnvEngineSocket      = "SEESAW_ENGINE_SOCKET"
+	EnvHealthcheckSocket = "SEESAW_HEALTHCHECK_SOCKET"
+	EnvNCCSocket         = "SEESAW_NCC_SOCKET"
+	EnvSyncPort          = "SEESAW_SYNC_PORT"
+)
+
+// Runtime configuration flag names.
+const (
+	FlagRunPath           = "run_path"
+	FlagEngineSocket      = "engine_socket"
+	FlagHealthcheckSocket = "healthcheck_socket"
+	FlagNCCSocket         = "ncc_socket"
+	FlagSyncPort          = "sync_port"
+)
+
+// RuntimeConfig specifies the run directory, sockets and ports used by the
+// Seesaw components.
+type RuntimeConfig struct {
+	RunPath           string
+	EngineSocket      string
+	HealthcheckSocket string
+	NCCSocket         string
+	SyncPort          int
+}
+
+// DefaultRuntimeConfig returns the default runtime configuration.
+func DefaultRuntimeConfig() RuntimeConfig {
+	return RuntimeConfig{
+		RunPath:           RunPath,
+		EngineSocket:      EngineSocket,
+		HealthcheckSocket: HealthcheckSocket,
+		NCCSocket:         NCCSocket,
+		SyncPort:          DefaultSyncPort,
+	}
+}
+
+// RuntimeFlags contains the command line flags for a RuntimeConfig. Values
+// given via flags take precedence over environment variables, which in turn
+// take precedence over the defaults.
+type RuntimeFlags struct {
+	fs      *flag.FlagSet
+	aliases map[string]string
+
+	runPath           string
+	engineSocket      string
+	healthcheckSocket string
+	nccSocket         string
+	syncPort          int
+}
+
+// NewRuntimeFlags registers the runtime configuration flags with the given
+// flag set.
+func NewRuntimeFlags(fs *flag.FlagSet) *RuntimeFlags {
+	rf := &RuntimeFlags{fs: fs, aliases: make(map[string]string)}
+	fs.StringVar(&rf.runPath, FlagRunPath, RunPath,
+		"Seesaw run directory (overrides $"+EnvRunPath+")")
+	fs.StringVar(&rf.engineSocket, FlagEngineSocket, EngineSocket,
+		"Seesaw Engine socket (overrides $"+EnvEngineSocket+")")
+	fs.StringVar(&rf.healthcheckSocket, FlagHealthcheckSocket, HealthcheckSocket,
+		"Seesaw Healthcheck socket (overrides $"+EnvHealthcheckSocket+")")
+	fs.StringVar(&rf.nccSocket, FlagNCCSocket, NCCSocket,
+		"Seesaw NCC socket (overrides $"+EnvNCCSocket+")")
+	fs.IntVar(&rf.syncPort, FlagSyncPort, DefaultSyncPort,
+		"Seesaw sync port (overrides $"+EnvSyncPort+")")
+	return rf
+}
+
+// Alias registers an alternative name for the named runtime flag, allowing
+// binaries to retain their existing flag names.
+func (rf *RuntimeFlags) Alias(alias, name string) {
+	f := rf.fs.Lookup(name)
+	if f == nil {
+		panic(fmt.Sprintf("unknown runtime flag %q", name))
+	}
+	rf.fs.Var(f.Value, alias, f.Usage)
+	rf.aliases[alias] = name
+}
+
+// Config returns the runtime configuration, resolved from the parsed flags,
+// the environment and the defaults.
+func (rf *RuntimeFlags) Config() (*RuntimeConfig, error) {
+	return rf.config(os.LookupEnv)
+}
+
+func (rf *RuntimeFlags) config(lookupEnv func(string) (string, bool)) (*RuntimeConfig, error) {
+	set := make(map[string]bool)
+	rf.fs.Visit(func(f *flag.Flag) {
+		name := f.Name
+		if n, ok := rf.aliases[name]; ok {
+			name = n
+		}
+		set[name] = true
+	})
+
+	resolve := func(name, env, def string) string {
+		if set[name] {
+			return rf.fs.Lookup(name).Value.String()
+		}
+		if v, ok := lookupEnv(env); ok && v != "" {
+			return v
+		}
+		return def
+	}
+
+	rc := &RuntimeConfig{}
+	rc.RunPath = resolve(FlagRunPath, EnvRunPath, RunPath)
+	rc.EngineSocket = resolve(FlagEngineSocket, EnvEngineSocket, socketPath(rc.RunPath, "engine"))
+	rc.HealthcheckSocket = resolve(FlagHealthcheckSocket, EnvHealthcheckSocket, socketPath(rc.RunPath, "healthcheck"))
+	rc.NCCSocket = resolve(FlagNCCSocket, EnvNCCSocket, socketPath(rc.RunPath, "ncc"))
+
+	port := resolve(FlagSyncPort, EnvSyncPort, strconv.Itoa(DefaultSyncPort))
+	p, err := strconv.Atoi(port)
+	if err != nil || p < 1 || p > 0xffff {
+		return nil, fmt.Errorf("invalid sync port %q", port)
+	}
+	rc.SyncPort = p
+
+	for _, s := range []struct{ name, path string }{
+		{"run path", rc.RunPath},
+		{"engine socket", rc.EngineSocket},
+		{"healthcheck socket", rc.HealthcheckSocket},
+		{"NCC socket", rc.NCCSocket},
+	} {
+		if s.path == "" {
+			return nil, fmt.Errorf("%s must not be empty", s.name)
+		}
+	}
+	return rc, nil
+}
+
+// CheckDir ensures that the given directory exists and is accessible. If
+// write is true the directory must also be writable, as is required in order
+// to create a socket within it.
+func CheckDir(dir string, write bool) error {
+	fi, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("directory %q does not exist", dir)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat directory %q: %v", dir, err)
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("%q is not a directory", dir)
+	}
+	mode := uint32(unix.X_OK)
+	if write {
+		mode |= unix.W_OK
+	}
+	if err := unix.Access(dir, mode); err != nil {
+		return fmt.Errorf("insufficient permissions on directory %q: %v", dir, err)
+	}
+	return nil
+}
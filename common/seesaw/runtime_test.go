@@ -0,0 +1,182 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package seesaw
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var runtimeConfigTests = []struct {
+	desc    string
+	args    []string
+	env     map[string]string
+	want    RuntimeConfig
+	wantErr bool
+}{
+	{
+		desc: "defaults",
+		want: DefaultRuntimeConfig(),
+	},
+	{
+		desc: "environment",
+		env: map[string]string{
+			EnvEngineSocket: "/env/engine.sock",
+			EnvSyncPort:     "11000",
+		},
+		want: RuntimeConfig{
+			RunPath:           RunPath,
+			EngineSocket:      "/env/engine.sock",
+			HealthcheckSocket: HealthcheckSocket,
+			NCCSocket:         NCCSocket,
+			SyncPort:          11000,
+		},
+	},
+	{
+		desc: "flags override environment",
+		args: []string{"-engine_socket=/flag/engine.sock", "-sync_port=12000"},
+		env: map[string]string{
+			EnvEngineSocket: "/env/engine.sock",
+			EnvSyncPort:     "11000",
+		},
+		want: RuntimeConfig{
+			RunPath:           RunPath,
+			EngineSocket:      "/flag/engine.sock",
+			HealthcheckSocket: HealthcheckSocket,
+			NCCSocket:         NCCSocket,
+			SyncPort:          12000,
+		},
+	},
+	{
+		desc: "alias overrides environment",
+		args: []string{"-engine=/alias/engine.sock"},
+		env:  map[string]string{EnvEngineSocket: "/env/engine.sock"},
+		want: RuntimeConfig{
+			RunPath:           RunPath,
+			EngineSocket:      "/alias/engine.sock",
+			HealthcheckSocket: HealthcheckSocket,
+			NCCSocket:         NCCSocket,
+			SyncPort:          DefaultSyncPort,
+		},
+	},
+	{
+		desc: "run path from environment",
+		env:  map[string]string{EnvRunPath: "/run/seesaw"},
+		want: RuntimeConfig{
+			RunPath:           "/run/seesaw",
+			EngineSocket:      "/run/seesaw/engine/engine.sock",
+			HealthcheckSocket: "/run/seesaw/healthcheck/healthcheck.sock",
+			NCCSocket:         "/run/seesaw/ncc/ncc.sock",
+			SyncPort:          DefaultSyncPort,
+		},
+	},
+	{
+		desc: "run path from flag",
+		args: []string{"-run_path=/flag"},
+		env: map[string]string{
+			EnvRunPath:   "/run/seesaw",
+			EnvNCCSocket: "/env/ncc.sock",
+		},
+		want: RuntimeConfig{
+			RunPath:           "/flag",
+			EngineSocket:      "/flag/engine/engine.sock",
+			HealthcheckSocket: "/flag/healthcheck/healthcheck.sock",
+			NCCSocket:         "/env/ncc.sock",
+			SyncPort:          DefaultSyncPort,
+		},
+	},
+	{
+		desc: "healthcheck socket from environment",
+		env:  map[string]string{EnvHealthcheckSocket: "/env/healthcheck.sock"},
+		want: RuntimeConfig{
+			RunPath:           RunPath,
+			EngineSocket:      EngineSocket,
+			HealthcheckSocket: "/env/healthcheck.sock",
+			NCCSocket:         NCCSocket,
+			SyncPort:          DefaultSyncPort,
+		},
+	},
+	{
+		desc:    "invalid sync port in environment",
+		env:     map[string]string{EnvSyncPort: "seesaw"},
+		wantErr: true,
+	},
+	{
+		desc:    "invalid sync port flag",
+		args:    []string{"-sync_port=70000"},
+		wantErr: true,
+	},
+	{
+		desc:    "empty socket flag",
+		args:    []string{"-ncc_socket="},
+		wantErr: true,
+	},
+}
+
+func TestRuntimeConfig(t *testing.T) {
+	for _, test := range runtimeConfigTests {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		rf := NewRuntimeFlags(fs)
+		rf.Alias("engine", FlagEngineSocket)
+		if err := fs.Parse(test.args); err != nil {
+			t.Errorf("%s: failed to parse flags: %v", test.desc, err)
+			continue
+		}
+		lookupEnv := func(key string) (string, bool) {
+			v, ok := test.env[key]
+			return v, ok
+		}
+		rc, err := rf.config(lookupEnv)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%s: got config %#v, want error", test.desc, *rc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.desc, err)
+			continue
+		}
+		if !reflect.DeepEqual(*rc, test.want) {
+			t.Errorf("%s: got config %#v, want %#v", test.desc, *rc, test.want)
+		}
+	}
+}
+
+func TestCheckDir(t *testing.T) {
+	dir, err := os.MkdirTemp("", "seesaw")
+	if err != nil {
+		t.Fatalf("Failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0600); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	if err := CheckDir(dir, true); err != nil {
+		t.Errorf("CheckDir(%q) failed: %v", dir, err)
+	}
+	if err := CheckDir(filepath.Join(dir, "missing"), false); err == nil {
+		t.Errorf("CheckDir succeeded for missing directory")
+	}
+	if err := CheckDir(file, false); err == nil {
+		t.Errorf("CheckDir succeeded for file")
+	}
+}
@@ -37,8 +37,9 @@ const (
 )
 
 var (
-	EngineSocket = socketPath("engine")
-	NCCSocket    = socketPath("ncc")
+	EngineSocket      = socketPath(RunPath, "engine")
+	HealthcheckSocket = socketPath(RunPath, "healthcheck")
+	NCCSocket         = socketPath(RunPath, "ncc")
 )
 
 // AF represents a network address family.
@@ -107,6 +108,7 @@ type HAStatus struct {
 	Received       uint64
 	ReceivedQueued uint64
 	Transitions    uint64
+	PreemptIn      time.Duration // Time remaining before preempting a lower priority master.
 }
 
 // HealthcheckMode specifies the mode for a Healthcheck.
@@ -143,6 +145,19 @@ const (
 	HCTypeRADIUS
 	HCTypeTCP
 	HCTypeUDP
+	HCTypeGRPC
+	HCTypeMySQL
+	HCTypePostgres
+	HCTypeRedis
+	HCTypeMemcache
+	HCTypeSMTP
+	HCTypeLDAP
+	HCTypeSIP
+	HCTypeNTP
+	HCTypeFTP
+	HCTypeIMAP
+	HCTypePOP3
+	HCTypeScript
 )
 
 // String returns the name for the given HealthcheckType.
@@ -160,6 +175,32 @@ func (h HealthcheckType) String() string {
 		return "TCP"
 	case HCTypeUDP:
 		return "UDP"
+	case HCTypeGRPC:
+		return "GRPC"
+	case HCTypeMySQL:
+		return "MySQL"
+	case HCTypePostgres:
+		return "PostgreSQL"
+	case HCTypeRedis:
+		return "Redis"
+	case HCTypeMemcache:
+		return "Memcache"
+	case HCTypeSMTP:
+		return "SMTP"
+	case HCTypeLDAP:
+		return "LDAP"
+	case HCTypeSIP:
+		return "SIP"
+	case HCTypeNTP:
+		return "NTP"
+	case HCTypeFTP:
+		return "FTP"
+	case HCTypeIMAP:
+		return "IMAP"
+	case HCTypePOP3:
+		return "POP3"
+	case HCTypeScript:
+		return "Script"
 	}
 	return "(unknown)"
 }
@@ -378,6 +419,14 @@ type DestinationOverride struct {
 	OverrideState
 }
 
+// PassiveOverride controls whether the healthchecks for a vserver are passive.
+// OverrideEnable makes them passive, OverrideDisable makes them active and
+// OverrideDefault uses the configured setting.
+type PassiveOverride struct {
+	VserverName string
+	OverrideState
+}
+
 // Host contains the hostname, IP addresses, and IP masks for a host.
 type Host struct {
 	Hostname string
@@ -415,8 +464,9 @@ type ConfigStatus struct {
 
 // ClusterStatus specifies the status of a Seesaw cluster.
 type ClusterStatus struct {
-	Version int
-	Site    string
+	Version      int
+	Site         string
+	IPVSTimeouts *ipvs.Timeouts // Nil if the timeouts could not be retrieved.
 	Nodes
 }
 
@@ -443,6 +493,84 @@ type VLANs struct {
 	VLANs []*VLAN
 }
 
+// HealthcheckSource specifies the delivery status of healthcheck notifications
+// from a Seesaw component.
+type HealthcheckSource struct {
+	Component    Component
+	LastDelivery time.Time
+	Stale        bool
+}
+
+// HealthcheckStatus specifies the delivery status of healthcheck
+// notifications to the Seesaw Engine.
+type HealthcheckStatus struct {
+	LastDelivery  time.Time
+	Stale         bool
+	StaleSince    time.Time
+	StaleCount    int
+	Timeout       time.Duration
+	Sources       []*HealthcheckSource
+	SkippedRuns   uint64
+	ThrottledRuns uint64
+}
+
+// HealthcheckResult contains the result of a single healthcheck run.
+type HealthcheckResult struct {
+	Time     time.Time
+	Duration time.Duration
+	Success  bool
+	Message  string
+}
+
+// HealthcheckHistory contains the most recent results for a healthcheck,
+// oldest first.
+type HealthcheckHistory struct {
+	Name    string
+	Results []*HealthcheckResult
+}
+
+// DestinationHistory contains the healthcheck history for a destination.
+type DestinationHistory struct {
+	Destination  string
+	Healthchecks []*HealthcheckHistory
+}
+
+// Connections contains entries from the IPVS connection table, which are
+// truncated if more entries matched than could be returned.
+type Connections struct {
+	Connections []*ipvs.Connection
+	Truncated   bool
+}
+
+// ReconcileStatus specifies the status of the reconciliation of the kernel
+// IPVS table with the services that the Seesaw Engine has configured.
+type ReconcileStatus struct {
+	Interval        time.Duration // Zero if periodic reconciliation is disabled.
+	Runs            uint64
+	Failures        uint64
+	Corrections     uint64 // The total number of IPVS changes made to repair drift.
+	LastRun         time.Time
+	LastDuration    time.Duration
+	LastCorrections int
+	LastError       string
+}
+
+// RouteStatus represents the status of a network that the engine advertises
+// via BGP while the node is the HA leader.
+type RouteStatus struct {
+	Network    net.IPNet
+	Wanted     bool // The route should currently be advertised.
+	Advertised bool // The route is currently advertised.
+	Failures   int  // Consecutive failed attempts to advertise or withdraw.
+	LastError  string
+	LastChange time.Time
+}
+
+// Routes provides a slice of RouteStatus.
+type Routes struct {
+	Routes []*RouteStatus
+}
+
 // Vserver represents a virtual server configured for load balancing.
 type Vserver struct {
 	Name    string
@@ -451,9 +579,10 @@ type Vserver struct {
 	Host
 	Services map[ServiceKey]*Service
 	OverrideState
-	Enabled       bool
-	ConfigEnabled bool
-	Warnings      []string
+	PassiveOverride OverrideState
+	Enabled         bool
+	ConfigEnabled   bool
+	Warnings        []string
 }
 
 // VserverEntry represents a port and protocol combination for a Vserver.
@@ -501,6 +630,7 @@ type Service struct {
 	HighWatermark    float32
 	LowWatermark     float32
 	CurrentWatermark float32
+	ConnImbalance    float32 // Max/min active connections across healthy destinations.
 }
 
 // ServiceStats contains statistics for a Service.
@@ -518,6 +648,20 @@ type Destination struct {
 	Enabled     bool
 	Healthy     bool
 	Active      bool
+
+	// WarmupHealthy is the number of consecutive healthy results for a
+	// destination that is warming up, out of WarmupRequired. WarmupRequired
+	// is zero once warmup has completed.
+	WarmupHealthy  int
+	WarmupRequired int
+
+	// Passive is true if the destination has healthchecks whose results are
+	// reported but not acted upon.
+	Passive bool
+
+	// Drained is true if the destination is unhealthy but remains in IPVS
+	// with a weight of zero, so that existing connections can complete.
+	Drained bool
 }
 
 // DestinationStats contains statistics for a Destination.
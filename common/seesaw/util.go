@@ -232,6 +232,9 @@ func (o *BackendOverride) State() OverrideState { return o.OverrideState }
 func (o *DestinationOverride) Target() string       { return o.DestinationName }
 func (o *DestinationOverride) State() OverrideState { return o.OverrideState }
 
+func (o *PassiveOverride) Target() string       { return o.VserverName + "/passive" }
+func (o *PassiveOverride) State() OverrideState { return o.OverrideState }
+
 // IP returns the destination IP address for a given address family.
 func (d *Destination) IP(af AF) net.IP {
 	switch af {
@@ -291,7 +294,8 @@ func TestAnycastHost() Host {
 	return testAnycastHost
 }
 
-// socketPath returns the path of a socket for the given Seesaw v2 component.
-func socketPath(component string) string {
-	return path.Join(RunPath, component, component+".sock")
+// socketPath returns the path of a socket for the given Seesaw v2 component,
+// within the specified run directory.
+func socketPath(runPath, component string) string {
+	return path.Join(runPath, component, component+".sock")
 }
@@ -31,8 +31,6 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/google/seesaw/common/seesaw"
-
 	log "github.com/golang/glog"
 )
 
@@ -98,11 +96,11 @@ func RemoveUnixSocket(socket string) error {
 	return nil
 }
 
-// ServerRunDirectory ensures that the run directory exists and has the
-// appropriate ownership and permissions.
-func ServerRunDirectory(server string, owner, group int) error {
-	serverRunDir := path.Join(seesaw.RunPath, server)
-	if err := os.MkdirAll(seesaw.RunPath, 0755); err != nil {
+// ServerRunDirectory ensures that the run directory for the given server
+// exists within runPath and has the appropriate ownership and permissions.
+func ServerRunDirectory(runPath, server string, owner, group int) error {
+	serverRunDir := path.Join(runPath, server)
+	if err := os.MkdirAll(runPath, 0755); err != nil {
 		return fmt.Errorf("Failed to make run directory: %v", err)
 	}
 	if err := os.MkdirAll(serverRunDir, 0700); err != nil {
@@ -93,6 +93,54 @@ func (s *SeesawECU) HAStatus(ctx *ipc.Context, status *seesaw.HAStatus) error {
 	return nil
 }
 
+// HealthcheckStatus returns the delivery status of healthcheck notifications
+// from the Seesaw Engine.
+func (s *SeesawECU) HealthcheckStatus(ctx *ipc.Context, status *seesaw.HealthcheckStatus) error {
+	s.trace("HealthcheckStatus", ctx)
+
+	authConn, err := s.ecu.authConnect(ctx)
+	if err != nil {
+		return err
+	}
+	defer authConn.Close()
+
+	hs, err := authConn.HealthcheckStatus()
+	if err != nil {
+		return err
+	}
+
+	if status != nil {
+		*status = *hs
+	}
+	return nil
+}
+
+// DestinationHistory returns the recent healthcheck results for a destination
+// from the Seesaw Engine.
+func (s *SeesawECU) DestinationHistory(args *ipc.DestinationHistory, reply *seesaw.DestinationHistory) error {
+	if args == nil {
+		return errors.New("args is nil")
+	}
+	ctx := args.Ctx
+	s.trace("DestinationHistory", ctx)
+
+	authConn, err := s.ecu.authConnect(ctx)
+	if err != nil {
+		return err
+	}
+	defer authConn.Close()
+
+	dh, err := authConn.DestinationHistory(args.Destination)
+	if err != nil {
+		return err
+	}
+
+	if reply != nil {
+		*reply = *dh
+	}
+	return nil
+}
+
 // ConfigStatus returns status information about this Seesaw's current configuration.
 func (s *SeesawECU) ConfigStatus(ctx *ipc.Context, reply *seesaw.ConfigStatus) error {
 	s.trace("ConfigStatus", ctx)
@@ -174,6 +222,71 @@ func (s *SeesawECU) BGPNeighbors(ctx *ipc.Context, reply *quagga.Neighbors) erro
 	return nil
 }
 
+// Routes returns the status of the routes advertised by the engine.
+func (s *SeesawECU) Routes(ctx *ipc.Context, reply *seesaw.Routes) error {
+	s.trace("Routes", ctx)
+
+	authConn, err := s.ecu.authConnect(ctx)
+	if err != nil {
+		return err
+	}
+	defer authConn.Close()
+
+	routes, err := authConn.Routes()
+	if err != nil {
+		return err
+	}
+
+	if reply != nil {
+		reply.Routes = routes
+	}
+	return nil
+}
+
+// Reconcile requests that the Seesaw Engine reconcile the IPVS table with the
+// configured services, returning the resulting reconciliation status.
+func (s *SeesawECU) Reconcile(ctx *ipc.Context, reply *seesaw.ReconcileStatus) error {
+	s.trace("Reconcile", ctx)
+
+	authConn, err := s.ecu.authConnect(ctx)
+	if err != nil {
+		return err
+	}
+	defer authConn.Close()
+
+	rs, err := authConn.Reconcile()
+	if err != nil {
+		return err
+	}
+
+	if reply != nil {
+		*reply = *rs
+	}
+	return nil
+}
+
+// ReconcileStatus returns the status of the reconciliation of the IPVS table
+// from the Seesaw Engine.
+func (s *SeesawECU) ReconcileStatus(ctx *ipc.Context, reply *seesaw.ReconcileStatus) error {
+	s.trace("ReconcileStatus", ctx)
+
+	authConn, err := s.ecu.authConnect(ctx)
+	if err != nil {
+		return err
+	}
+	defer authConn.Close()
+
+	rs, err := authConn.ReconcileStatus()
+	if err != nil {
+		return err
+	}
+
+	if reply != nil {
+		*reply = *rs
+	}
+	return nil
+}
+
 // VLANs returns a list of currently configured VLANs.
 func (s *SeesawECU) VLANs(ctx *ipc.Context, reply *seesaw.VLANs) error {
 	s.trace("VLANs", ctx)
@@ -296,3 +409,23 @@ func (s *SeesawECU) OverrideVserver(args *ipc.Override, reply *int) error {
 	}
 	return authConn.OverrideVserver(args.Vserver)
 }
+
+// OverridePassive requests that the specified PassiveOverride be applied.
+func (s *SeesawECU) OverridePassive(args *ipc.Override, reply *int) error {
+	if args == nil {
+		return errors.New("args is nil")
+	}
+	ctx := args.Ctx
+	s.trace("OverridePassive", ctx)
+
+	authConn, err := s.ecu.authConnect(ctx)
+	if err != nil {
+		return err
+	}
+	defer authConn.Close()
+
+	if args.Passive == nil {
+		return errors.New("passive override is nil")
+	}
+	return authConn.OverridePassive(args.Passive)
+}
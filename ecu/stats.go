@@ -46,7 +46,9 @@ type Stats struct {
 	ClusterStatus seesaw.ClusterStatus
 	ConfigStatus  seesaw.ConfigStatus
 	HAStatus      seesaw.HAStatus
+	Healthchecks  seesaw.HealthcheckStatus
 	Neighbors     []*quagga.Neighbor
+	Reconcile     seesaw.ReconcileStatus
 	VLANs         []*seesaw.VLAN
 	Vservers      map[string]*seesaw.Vserver
 }
@@ -127,11 +129,21 @@ func (e *ecuStats) stats() (*Stats, error) {
 		return nil, fmt.Errorf("get HA status: %v", err)
 	}
 
+	healthchecks, err := seesawConn.HealthcheckStatus()
+	if err != nil {
+		return nil, fmt.Errorf("get healthcheck status: %v", err)
+	}
+
 	neighbors, err := seesawConn.BGPNeighbors()
 	if err != nil {
 		return nil, fmt.Errorf("get BGP neighbors: %v", err)
 	}
 
+	reconcile, err := seesawConn.ReconcileStatus()
+	if err != nil {
+		return nil, fmt.Errorf("get IPVS reconciliation status: %v", err)
+	}
+
 	vlans, err := seesawConn.VLANs()
 	if err != nil {
 		return nil, fmt.Errorf("get VLANs: %v", err)
@@ -147,7 +159,9 @@ func (e *ecuStats) stats() (*Stats, error) {
 		ClusterStatus: *clusterStatus,
 		ConfigStatus:  *configStatus,
 		HAStatus:      *ha,
+		Healthchecks:  *healthchecks,
 		Neighbors:     neighbors,
+		Reconcile:     *reconcile,
 		VLANs:         vlans.VLANs,
 		Vservers:      vservers,
 	}, nil
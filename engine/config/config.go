@@ -23,12 +23,18 @@ import (
 	"io"
 	"io/ioutil"
 	"net"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/seesaw/common/seesaw"
+	"github.com/google/seesaw/healthcheck"
+	"github.com/google/seesaw/ipvs"
 	pb "github.com/google/seesaw/pb/config"
 	spb "github.com/google/seesaw/pb/seesaw"
 
@@ -243,7 +249,7 @@ func protoToHealthcheck(p *pb.Healthcheck, defaultPort uint16) *Healthcheck {
 		hcMode = seesaw.HCModeTUN
 	}
 	var hcType seesaw.HealthcheckType
-	var secure bool
+	var secure, sipOverTCP bool
 	switch p.GetType() {
 	case pb.Healthcheck_ICMP_PING:
 		hcType = seesaw.HCTypeICMP
@@ -263,6 +269,50 @@ func protoToHealthcheck(p *pb.Healthcheck, defaultPort uint16) *Healthcheck {
 		secure = true
 	case pb.Healthcheck_RADIUS:
 		hcType = seesaw.HCTypeRADIUS
+	case pb.Healthcheck_GRPC:
+		hcType = seesaw.HCTypeGRPC
+	case pb.Healthcheck_GRPC_TLS:
+		hcType = seesaw.HCTypeGRPC
+		secure = true
+	case pb.Healthcheck_MYSQL:
+		hcType = seesaw.HCTypeMySQL
+	case pb.Healthcheck_POSTGRESQL:
+		hcType = seesaw.HCTypePostgres
+	case pb.Healthcheck_REDIS:
+		hcType = seesaw.HCTypeRedis
+	case pb.Healthcheck_MEMCACHE:
+		hcType = seesaw.HCTypeMemcache
+	case pb.Healthcheck_SMTP:
+		hcType = seesaw.HCTypeSMTP
+	case pb.Healthcheck_LDAP:
+		hcType = seesaw.HCTypeLDAP
+	case pb.Healthcheck_LDAPS:
+		hcType = seesaw.HCTypeLDAP
+		secure = true
+	case pb.Healthcheck_SIP:
+		hcType = seesaw.HCTypeSIP
+	case pb.Healthcheck_SIP_TCP:
+		hcType = seesaw.HCTypeSIP
+		sipOverTCP = true
+	case pb.Healthcheck_NTP:
+		hcType = seesaw.HCTypeNTP
+	case pb.Healthcheck_FTP:
+		hcType = seesaw.HCTypeFTP
+	case pb.Healthcheck_FTPS:
+		hcType = seesaw.HCTypeFTP
+		secure = true
+	case pb.Healthcheck_IMAP:
+		hcType = seesaw.HCTypeIMAP
+	case pb.Healthcheck_IMAPS:
+		hcType = seesaw.HCTypeIMAP
+		secure = true
+	case pb.Healthcheck_POP3:
+		hcType = seesaw.HCTypePOP3
+	case pb.Healthcheck_POP3S:
+		hcType = seesaw.HCTypePOP3
+		secure = true
+	case pb.Healthcheck_SCRIPT:
+		hcType = seesaw.HCTypeScript
 	}
 	port := uint16(p.GetPort())
 	if port == 0 {
@@ -270,18 +320,310 @@ func protoToHealthcheck(p *pb.Healthcheck, defaultPort uint16) *Healthcheck {
 	}
 	hc := NewHealthcheck(hcMode, hcType, port)
 	hc.Secure = secure
+	hc.SIPOverTCP = sipOverTCP
 	hc.Interval = time.Duration(p.GetInterval()) * time.Second
 	hc.Timeout = time.Duration(p.GetTimeout()) * time.Second
 	hc.Retries = int(p.GetRetries())
+	hc.Rise = int(p.GetRise())
+	hc.Fall = int(p.GetFall())
+	hc.Passive = p.GetPassive()
+	hc.PassiveHold = p.GetPassiveHold()
+	hc.MaxLatency = time.Duration(p.GetMaxLatencyMs()) * time.Millisecond
 	hc.Send = p.GetSend()
 	hc.Receive = p.GetReceive()
 	hc.Code = int(p.GetCode())
+	hc.Codes = p.GetCodes()
 	hc.Proxy = p.GetProxy()
+	hc.ProxyURL = p.GetProxyUrl()
 	hc.Method = p.GetMethod()
+	hc.RequestBody = string(p.GetRequestBody())
+	hc.ContentType = p.GetContentType()
+	hc.MaxRedirects = int(p.GetMaxRedirects())
 	hc.TLSVerify = p.GetTlsVerify()
+	hc.TLSCertFile = p.GetTlsCertFile()
+	hc.TLSKeyFile = p.GetTlsKeyFile()
+	hc.TLSCAFile = p.GetTlsCaFile()
+	hc.TLSServerName = p.GetTlsServerName()
+	hc.TLSResume = p.GetTlsResumeSessions()
+	hc.ReuseConns = p.GetReuseConnections()
+	hc.Headers = protoToHeaders(p.GetHeader())
+	hc.ExpectHeaders = protoToHeaders(p.GetExpectHeader())
+	hc.ForbidHeaders = protoToHeaderNames(p.GetForbidHeader())
+	hc.HeaderRegexp = p.GetExpectHeaderRegexp()
+	hc.ReceiveRegexp = p.GetReceiveRegexp()
+	hc.DNSAuth = p.GetDnsAuthoritative()
+	hc.DNSRcode = p.GetDnsRcode()
+	hc.DNSUDPSize = int(p.GetDnsUdpSize())
+	hc.DNSSEC = p.GetDnssec()
+	hc.DNSWarnAfter = time.Duration(p.GetDnsWarnLatencyMs()) * time.Millisecond
+	hc.DNSFailAfter = time.Duration(p.GetDnsMaxLatencyMs()) * time.Millisecond
+	hc.DNSAttempts = int(p.GetDnsQueryAttempts())
+	hc.DNSStrictID = p.GetDnsStrictId()
+	hc.DNSRandCase = p.GetDnsCaseRandomization()
+	hc.RADIUSUser = p.GetRadiusUsername()
+	hc.RADIUSPass = p.GetRadiusPassword()
+	hc.RADIUSSecret = p.GetRadiusSecret()
+	hc.RADIUSStatus = p.GetRadiusStatusServer()
+	hc.GRPCService = p.GetGrpcService()
+	hc.MySQLUser = p.GetMysqlUser()
+	hc.MySQLPass = p.GetMysqlPassword()
+	hc.MySQLQuery = p.GetMysqlQuery()
+	hc.MySQLWritable = p.GetMysqlExpectWritable()
+	hc.PGUser = p.GetPostgresUser()
+	hc.PGPass = p.GetPostgresPassword()
+	hc.PGDatabase = p.GetPostgresDatabase()
+	hc.PGSSLMode = p.GetPostgresSslmode()
+	hc.PGPrimary = p.GetPostgresExpectPrimary()
+	hc.RedisUser = p.GetRedisUsername()
+	hc.RedisPass = p.GetRedisPassword()
+	hc.RedisRole = p.GetRedisRole()
+	hc.MemcBinary = p.GetMemcacheBinary()
+	hc.MemcStatKey = p.GetMemcacheStatsKey()
+	hc.MemcStatValue = p.GetMemcacheStatsValue()
+	hc.MemcKeyPrefix = p.GetMemcacheKeyPrefix()
+	hc.SMTPHelo = p.GetSmtpHostname()
+	hc.SMTPStartTLS = p.GetSmtpStarttls()
+	hc.SMTPCap = p.GetSmtpCapability()
+	hc.LDAPBindDN = p.GetLdapBindDn()
+	hc.LDAPPass = p.GetLdapPassword()
+	hc.LDAPSearchDN = p.GetLdapSearchDn()
+	hc.LDAPStartTLS = p.GetLdapStarttls()
+	hc.NTPMaxStratum = int(p.GetNtpMaxStratum())
+	hc.NTPMaxDisp = time.Duration(p.GetNtpMaxRootDispersionMs()) * time.Millisecond
+	hc.NTPMaxOffset = time.Duration(p.GetNtpMaxOffsetMs()) * time.Millisecond
+	hc.FTPUser = p.GetFtpUser()
+	hc.FTPPass = p.GetFtpPassword()
+	hc.FTPCommand = p.GetFtpCommand()
+	hc.FTPPath = p.GetFtpPath()
+	hc.IMAPUser = p.GetImapUser()
+	hc.IMAPPass = p.GetImapPassword()
+	hc.IMAPMailbox = p.GetImapMailbox()
+	hc.IMAPStartTLS = p.GetImapStarttls()
+	hc.POP3User = p.GetPop3User()
+	hc.POP3Pass = p.GetPop3Password()
+	hc.POP3StartTLS = p.GetPop3Starttls()
+	hc.ScriptCmd = p.GetScriptCommand()
+	hc.ScriptArgs = protoToScriptArgs(p.GetScriptArg())
+	hc.TCPHalfOpen = p.GetTcpHalfOpen()
+	if response := p.GetRadiusResponse(); response != "" {
+		hc.Receive = response
+	}
 	return hc
 }
 
+// protoToHeaders converts a list of "Name: value" HTTP headers into their
+// canonical, sorted form. Malformed headers are logged and ignored.
+func protoToHeaders(pbs []string) string {
+	headers := make([]string, 0, len(pbs))
+	for _, h := range pbs {
+		name, value, ok := strings.Cut(h, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(value, "\r\n") {
+			log.Warningf("Ignoring malformed healthcheck header %q", h)
+			continue
+		}
+		name = http.CanonicalHeaderKey(name)
+		headers = append(headers, fmt.Sprintf("%s: %s", name, strings.TrimSpace(value)))
+	}
+	sort.Strings(headers)
+	return strings.Join(headers, "\n")
+}
+
+// protoToHeaderNames converts a list of HTTP header names into their
+// canonical, sorted form.
+func protoToHeaderNames(pbs []string) string {
+	names := make([]string, 0, len(pbs))
+	for _, name := range pbs {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, http.CanonicalHeaderKey(name))
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, "\n")
+}
+
+// protoToScriptArgs converts a list of script arguments into their line
+// separated form. Arguments containing line breaks are logged and ignored.
+func protoToScriptArgs(pbs []string) string {
+	args := make([]string, 0, len(pbs))
+	for _, arg := range pbs {
+		if !healthcheck.ValidLineArgument(arg) {
+			log.Warningf("Ignoring healthcheck script argument with line break %q", arg)
+			continue
+		}
+		args = append(args, arg)
+	}
+	return strings.Join(args, "\n")
+}
+
+// validatePersistence returns a warning if the persistence configuration for a
+// vserver entry cannot be programmed into IPVS.
+func validatePersistence(e *VserverEntry) string {
+	switch {
+	case e.Persistence == 0 && (e.PersistenceIPv4Len != 0 || e.PersistenceIPv6Len != 0):
+		return fmt.Sprintf("persistence prefix length requires persistence for %s", e.Key())
+	case e.PersistenceIPv4Len < 0 || e.PersistenceIPv4Len > 32:
+		return fmt.Sprintf("invalid IPv4 persistence prefix length %d for %s", e.PersistenceIPv4Len, e.Key())
+	case e.PersistenceIPv6Len < 0 || e.PersistenceIPv6Len > 128:
+		return fmt.Sprintf("invalid IPv6 persistence prefix length %d for %s", e.PersistenceIPv6Len, e.Key())
+	}
+	return ""
+}
+
+// parseTunnel sets the tunnel encapsulation for a vserver entry, returning a
+// warning if it is invalid. Support for the encapsulation by the running
+// kernel is checked by the engine.
+func parseTunnel(e *VserverEntry, ve *pb.VserverEntry) string {
+	if ve.TunnelType == nil && ve.TunnelPort == nil && ve.TunnelChecksum == nil {
+		return ""
+	}
+	if e.Mode != seesaw.LBModeTUN {
+		return fmt.Sprintf("tunnel options require TUN mode for %s", e.Key())
+	}
+	if ve.TunnelType != nil {
+		t, err := ipvs.ParseTunnelType(ve.GetTunnelType())
+		if err != nil {
+			return fmt.Sprintf("%v for %s", err, e.Key())
+		}
+		e.TunnelType = t
+	}
+	if ve.TunnelChecksum != nil {
+		c, err := ipvs.ParseTunnelChecksum(ve.GetTunnelChecksum())
+		if err != nil {
+			return fmt.Sprintf("%v for %s", err, e.Key())
+		}
+		e.TunnelChecksum = c
+	}
+	port := ve.GetTunnelPort()
+	if port < 0 || port > 0xffff {
+		return fmt.Sprintf("invalid tunnel port %d for %s", port, e.Key())
+	}
+	e.TunnelPort = uint16(port)
+	if err := ipvs.ValidateTunnel(e.TunnelType, e.TunnelPort, e.TunnelChecksum); err != nil {
+		return fmt.Sprintf("%v for %s", err, e.Key())
+	}
+	return ""
+}
+
+// validateMixedFamilies returns a warning if a vserver cannot use backends
+// from the other address family. The kernel only supports destinations with a
+// different address family to their service when they are tunnelled.
+func validateMixedFamilies(v *Vserver) string {
+	keys := make([]string, 0, len(v.Entries))
+	for key := range v.Entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if v.Entries[key].Mode != seesaw.LBModeTUN {
+			return fmt.Sprintf("mixed address families require TUN mode, disabled due to %s", key)
+		}
+	}
+	return ""
+}
+
+// validateHealthcheck records a vserver warning for a healthcheck that cannot
+// be used as configured. The healthcheck is retained, so that it fails rather
+// than silently passing.
+func validateHealthcheck(v *Vserver, hc *Healthcheck) {
+	var warnings []string
+	if hc.ReceiveRegexp != "" {
+		if _, err := regexp.Compile(hc.ReceiveRegexp); err != nil {
+			warnings = append(warnings, fmt.Sprintf("healthcheck %s has invalid receive_regexp: %v", hc.Name, err))
+		}
+	}
+	if hc.HeaderRegexp {
+		for name, expr := range hc.ExpectHeaderMap() {
+			if _, err := regexp.Compile(expr); err != nil {
+				warnings = append(warnings, fmt.Sprintf("healthcheck %s has invalid expect_header regexp for %s: %v", hc.Name, name, err))
+			}
+		}
+	}
+	if hc.ProxyURL != "" {
+		if u, err := url.Parse(hc.ProxyURL); err != nil || u.Host == "" {
+			warnings = append(warnings, fmt.Sprintf("healthcheck %s has invalid proxy_url", hc.Name))
+		}
+	}
+	if hc.Codes != "" {
+		if _, err := healthcheck.ParseResponseCodes(hc.Codes); err != nil {
+			warnings = append(warnings, fmt.Sprintf("healthcheck %s has invalid codes: %v", hc.Name, err))
+		}
+	}
+	if hc.DNSRcode != "" {
+		if _, err := healthcheck.DNSRcode(hc.DNSRcode); err != nil {
+			warnings = append(warnings, fmt.Sprintf("healthcheck %s has invalid dns_rcode: %v", hc.Name, err))
+		}
+	}
+	if hc.DNSUDPSize != 0 && (hc.DNSUDPSize < 512 || hc.DNSUDPSize > 65535) {
+		warnings = append(warnings, fmt.Sprintf("healthcheck %s has invalid dns_udp_size %d", hc.Name, hc.DNSUDPSize))
+	}
+	if hc.Type == seesaw.HCTypeRADIUS && hc.Receive != "" {
+		switch hc.Receive {
+		case "accept", "reject", "challenge", "any":
+		default:
+			warnings = append(warnings, fmt.Sprintf("healthcheck %s has invalid radius_response %q", hc.Name, hc.Receive))
+		}
+	}
+	switch hc.PGSSLMode {
+	case "", "disable", "prefer", "require":
+	default:
+		warnings = append(warnings, fmt.Sprintf("healthcheck %s has invalid postgres_sslmode %q", hc.Name, hc.PGSSLMode))
+	}
+	switch hc.RedisRole {
+	case "", "master", "replica":
+	default:
+		warnings = append(warnings, fmt.Sprintf("healthcheck %s has invalid redis_role %q", hc.Name, hc.RedisRole))
+	}
+	if hc.MemcKeyPrefix != "" && !healthcheck.ValidMemcacheKeyPrefix(hc.MemcKeyPrefix) {
+		warnings = append(warnings, fmt.Sprintf("healthcheck %s has invalid memcache_key_prefix %q", hc.Name, hc.MemcKeyPrefix))
+	}
+	if hc.SMTPHelo != "" && !healthcheck.ValidSMTPHostname(hc.SMTPHelo) {
+		warnings = append(warnings, fmt.Sprintf("healthcheck %s has invalid smtp_hostname %q", hc.Name, hc.SMTPHelo))
+	}
+	if hc.NTPMaxStratum < 0 || hc.NTPMaxStratum > healthcheck.DefaultNTPMaxStratum {
+		warnings = append(warnings, fmt.Sprintf("healthcheck %s has invalid ntp_max_stratum %d", hc.Name, hc.NTPMaxStratum))
+	}
+	if !healthcheck.ValidFTPCommand(hc.FTPCommand) {
+		warnings = append(warnings, fmt.Sprintf("healthcheck %s has invalid ftp_command %q", hc.Name, hc.FTPCommand))
+	}
+	if hc.FTPCommand == "CWD" && hc.FTPPath == "" {
+		warnings = append(warnings, fmt.Sprintf("healthcheck %s has ftp_command CWD without ftp_path", hc.Name))
+	}
+	for _, arg := range []string{hc.FTPUser, hc.FTPPass, hc.FTPPath} {
+		if !healthcheck.ValidLineArgument(arg) {
+			warnings = append(warnings, fmt.Sprintf("healthcheck %s has FTP user, password or path containing a line break", hc.Name))
+			break
+		}
+	}
+	for _, arg := range []string{hc.IMAPUser, hc.IMAPPass, hc.IMAPMailbox, hc.POP3User, hc.POP3Pass} {
+		if !healthcheck.ValidLineArgument(arg) {
+			warnings = append(warnings, fmt.Sprintf("healthcheck %s has IMAP or POP3 credentials or mailbox containing a line break", hc.Name))
+			break
+		}
+	}
+	if hc.Type == seesaw.HCTypeScript && !healthcheck.ValidScriptCommand(hc.ScriptCmd) {
+		warnings = append(warnings, fmt.Sprintf("healthcheck %s has invalid script_command %q", hc.Name, hc.ScriptCmd))
+	}
+	if hc.Rise < 0 || hc.Fall < 0 {
+		warnings = append(warnings, fmt.Sprintf("healthcheck %s has negative rise or fall, which is treated as 1", hc.Name))
+	}
+	if hc.TCPHalfOpen && (hc.Type != seesaw.HCTypeTCP || hc.Secure || hc.Send != "" || hc.Receive != "" || hc.ReceiveRegexp != "") {
+		warnings = append(warnings, fmt.Sprintf("healthcheck %s has tcp_half_open, which only applies to plain TCP healthchecks without send or receive", hc.Name))
+	}
+	if hc.PassiveHold && !hc.Passive {
+		warnings = append(warnings, fmt.Sprintf("healthcheck %s has passive_hold without passive", hc.Name))
+	}
+	if hc.DNSSEC != "" {
+		if _, err := healthcheck.ParseDNSSECMode(hc.DNSSEC); err != nil {
+			warnings = append(warnings, fmt.Sprintf("healthcheck %s has invalid dnssec: %v", hc.Name, err))
+		}
+	}
+	for _, warning := range warnings {
+		log.Errorf("%v: %s", v.Name, warning)
+		v.Warnings = append(v.Warnings, warning)
+	}
+}
+
 func protoToHost(p *pb.Host) seesaw.Host {
 	ipv4, mask4 := parseCIDR(p.GetIpv4())
 	ipv6, mask6 := parseCIDR(p.GetIpv6())
@@ -449,6 +791,7 @@ func addVservers(c *Cluster, p *pb.Cluster) error {
 		v := NewVserver(vs.GetName(), protoToHost(host))
 		v.Enabled = host.GetStatus() == pb.Host_PRODUCTION || host.GetStatus() == pb.Host_TESTING
 		v.UseFWM = vs.GetUseFwm()
+		v.ShareHealthchecks = vs.GetShareHealthchecks()
 		v.Warnings = vs.GetWarning()
 		sort.Strings(v.Warnings)
 
@@ -498,6 +841,17 @@ func addVservers(c *Cluster, p *pb.Cluster) error {
 			}
 			e.Scheduler = scheduler
 
+			switch {
+			case scheduler == seesaw.LBSchedulerSH || scheduler == seesaw.LBSchedulerMH:
+				e.SchedFallback = ve.GetSchedFallback()
+				e.SchedPort = ve.GetSchedPort()
+			case ve.SchedFallback != nil || ve.SchedPort != nil:
+				warning := fmt.Sprintf("scheduler flags are not supported by scheduler %v", scheduler)
+				log.Errorf("%v: %s", vs.GetName(), warning)
+				v.Warnings = append(v.Warnings, warning)
+				continue
+			}
+
 			var mode seesaw.LBMode
 			switch ve.GetMode() {
 			case pb.VserverEntry_DSR:
@@ -515,7 +869,20 @@ func addVservers(c *Cluster, p *pb.Cluster) error {
 			e.Mode = mode
 
 			e.Persistence = int(ve.GetPersistence())
+			e.PersistenceIPv4Len = int(ve.GetPersistenceIpv4PrefixLength())
+			e.PersistenceIPv6Len = int(ve.GetPersistenceIpv6PrefixLength())
+			if warning := validatePersistence(e); warning != "" {
+				log.Errorf("%v: %s", vs.GetName(), warning)
+				v.Warnings = append(v.Warnings, warning)
+				continue
+			}
 			e.OnePacket = ve.GetOnePacket()
+			if e.OnePacket && e.Proto != seesaw.IPProtoUDP {
+				warning := fmt.Sprintf("one-packet scheduling requires UDP for %s", e.Key())
+				log.Errorf("%v: %s", vs.GetName(), warning)
+				v.Warnings = append(v.Warnings, warning)
+				continue
+			}
 			e.HighWatermark = ve.GetServerHighWatermark()
 			e.LowWatermark = ve.GetServerLowWatermark()
 			if e.HighWatermark < e.LowWatermark {
@@ -523,7 +890,15 @@ func addVservers(c *Cluster, p *pb.Cluster) error {
 			}
 			e.LowerThreshold = int(ve.GetLthreshold())
 			e.UpperThreshold = int(ve.GetUthreshold())
+			e.WarmupHealthyCount = int(ve.GetWarmupHealthyCount())
+			e.DrainUnhealthy = ve.GetDrainUnhealthy()
+			if warning := parseTunnel(e, ve); warning != "" {
+				log.Errorf("%v: %s", vs.GetName(), warning)
+				v.Warnings = append(v.Warnings, warning)
+				continue
+			}
 			for _, hc := range protosToHealthchecks(ve.Healthcheck, e.Port) {
+				validateHealthcheck(v, hc)
 				if err := e.AddHealthcheck(hc); err != nil {
 					log.Warning(err)
 				}
@@ -532,6 +907,14 @@ func addVservers(c *Cluster, p *pb.Cluster) error {
 				log.Warning(err)
 			}
 		}
+		if vs.GetMixedAddressFamilies() {
+			if warning := validateMixedFamilies(v); warning != "" {
+				log.Errorf("%v: %s", vs.GetName(), warning)
+				v.Warnings = append(v.Warnings, warning)
+			} else {
+				v.MixedFamilies = true
+			}
+		}
 		for _, backend := range vs.Backend {
 			status := backend.GetHost().GetStatus()
 			b := &seesaw.Backend{
@@ -545,6 +928,7 @@ func addVservers(c *Cluster, p *pb.Cluster) error {
 			}
 		}
 		for _, hc := range protosToHealthchecks(vs.Healthcheck, 0) {
+			validateHealthcheck(v, hc)
 			if err := v.AddHealthcheck(hc); err != nil {
 				log.Warning(err)
 			}
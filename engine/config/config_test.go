@@ -27,6 +27,7 @@ import (
 	"time"
 
 	"github.com/google/seesaw/common/seesaw"
+	"github.com/google/seesaw/ipvs"
 	pb "github.com/google/seesaw/pb/config"
 	spb "github.com/google/seesaw/pb/seesaw"
 
@@ -55,32 +56,310 @@ var healthcheckTests = []struct {
 		"Full Healthcheck",
 		"healthcheck1.pb",
 		&Healthcheck{
-			Mode:      seesaw.HCModeDSR,
-			Type:      seesaw.HCTypeHTTP,
-			Interval:  time.Duration(100 * time.Second),
-			Timeout:   time.Duration(200 * time.Second),
-			TLSVerify: true,
-			Code:      200,
-			Method:    "HEAD",
-			Port:      99,
-			Send:      "foo",
-			Receive:   "bar",
-			Proxy:     true,
+			Mode:          seesaw.HCModeDSR,
+			Type:          seesaw.HCTypeHTTP,
+			Interval:      time.Duration(100 * time.Second),
+			Timeout:       time.Duration(200 * time.Second),
+			MaxLatency:    1500 * time.Millisecond,
+			TLSVerify:     true,
+			TLSServerName: "www.example.com",
+			TLSResume:     true,
+			ReuseConns:    true,
+			Code:          200,
+			Codes:         "2xx,404",
+			Method:        "HEAD",
+			RequestBody:   "{}",
+			ContentType:   "application/json",
+			MaxRedirects:  3,
+			Port:          99,
+			Send:          "foo",
+			Receive:       "bar",
+			Proxy:         true,
+			Headers:       "Host: www.example.com\nX-Seesaw-Check: yes",
+			ReceiveRegexp: "^ba",
+			ExpectHeaders: "X-Version: 1.2.3",
+			ForbidHeaders: "X-Drain\nX-Maintenance",
 		},
 	},
 	{
 		"DNS Healthcheck",
 		"healthcheck2.pb",
 		&Healthcheck{
-			Mode:      seesaw.HCModeDSR,
-			Type:      seesaw.HCTypeDNS,
-			Interval:  time.Duration(2 * time.Second),
-			Timeout:   time.Duration(1 * time.Second),
+			Mode:         seesaw.HCModeDSR,
+			Type:         seesaw.HCTypeDNS,
+			Interval:     time.Duration(2 * time.Second),
+			Timeout:      time.Duration(1 * time.Second),
+			TLSVerify:    true,
+			Method:       "A",
+			Port:         53,
+			Send:         "www.example.com",
+			Receive:      "192.168.0.1",
+			DNSAuth:      true,
+			DNSRcode:     "NOERROR",
+			DNSUDPSize:   4096,
+			DNSSEC:       "AD",
+			DNSWarnAfter: 50 * time.Millisecond,
+			DNSFailAfter: 200 * time.Millisecond,
+			DNSAttempts:  2,
+			DNSStrictID:  true,
+			DNSRandCase:  true,
+		},
+	},
+	{
+		"RADIUS Healthcheck",
+		"healthcheck3.pb",
+		&Healthcheck{
+			Mode:         seesaw.HCModePlain,
+			Type:         seesaw.HCTypeRADIUS,
+			Interval:     time.Duration(10 * time.Second),
+			Timeout:      time.Duration(5 * time.Second),
+			TLSVerify:    true,
+			Port:         1812,
+			Receive:      "reject",
+			RADIUSUser:   "monitor",
+			RADIUSPass:   "ignored",
+			RADIUSSecret: "sharedsecret",
+			RADIUSStatus: true,
+		},
+	},
+	{
+		"gRPC Healthcheck",
+		"healthcheck4.pb",
+		&Healthcheck{
+			Mode:          seesaw.HCModePlain,
+			Type:          seesaw.HCTypeGRPC,
+			Interval:      time.Duration(10 * time.Second),
+			Timeout:       time.Duration(5 * time.Second),
+			Port:          8443,
+			Secure:        true,
+			TLSServerName: "backend.example.com",
+			GRPCService:   "example.Backend",
+		},
+	},
+	{
+		"MySQL Healthcheck",
+		"healthcheck5.pb",
+		&Healthcheck{
+			Mode:          seesaw.HCModePlain,
+			Type:          seesaw.HCTypeMySQL,
+			Interval:      time.Duration(10 * time.Second),
+			Timeout:       time.Duration(5 * time.Second),
+			TLSVerify:     true,
+			Port:          3306,
+			MySQLUser:     "seesaw",
+			MySQLPass:     "monitor",
+			MySQLQuery:    "SELECT 1",
+			MySQLWritable: true,
+		},
+	},
+	{
+		"PostgreSQL Healthcheck",
+		"healthcheck6.pb",
+		&Healthcheck{
+			Mode:       seesaw.HCModePlain,
+			Type:       seesaw.HCTypePostgres,
+			Interval:   time.Duration(10 * time.Second),
+			Timeout:    time.Duration(5 * time.Second),
+			TLSVerify:  true,
+			Port:       5432,
+			PGUser:     "seesaw",
+			PGPass:     "monitor",
+			PGDatabase: "postgres",
+			PGSSLMode:  "require",
+			PGPrimary:  true,
+		},
+	},
+	{
+		"Redis Healthcheck",
+		"healthcheck7.pb",
+		&Healthcheck{
+			Mode:      seesaw.HCModePlain,
+			Type:      seesaw.HCTypeRedis,
+			Interval:  time.Duration(10 * time.Second),
+			Timeout:   time.Duration(5 * time.Second),
 			TLSVerify: true,
-			Method:    "A",
-			Port:      53,
-			Send:      "www.example.com",
-			Receive:   "192.168.0.1",
+			Port:      6379,
+			RedisUser: "seesaw",
+			RedisPass: "monitor",
+			RedisRole: "master",
+		},
+	},
+	{
+		"Memcache Healthcheck",
+		"healthcheck8.pb",
+		&Healthcheck{
+			Mode:          seesaw.HCModePlain,
+			Type:          seesaw.HCTypeMemcache,
+			Interval:      time.Duration(10 * time.Second),
+			Timeout:       time.Duration(5 * time.Second),
+			TLSVerify:     true,
+			Port:          11211,
+			MemcStatKey:   "accepting_conns",
+			MemcStatValue: "1",
+			MemcKeyPrefix: "seesaw-hc-",
+		},
+	},
+	{
+		"SMTP Healthcheck",
+		"healthcheck9.pb",
+		&Healthcheck{
+			Mode:         seesaw.HCModePlain,
+			Type:         seesaw.HCTypeSMTP,
+			Interval:     time.Duration(10 * time.Second),
+			Timeout:      time.Duration(5 * time.Second),
+			Port:         25,
+			SMTPHelo:     "seesaw.example.com",
+			SMTPStartTLS: true,
+			SMTPCap:      "PIPELINING",
+		},
+	},
+	{
+		"LDAPS Healthcheck",
+		"healthcheck10.pb",
+		&Healthcheck{
+			Mode:          seesaw.HCModePlain,
+			Type:          seesaw.HCTypeLDAP,
+			Interval:      time.Duration(10 * time.Second),
+			Timeout:       time.Duration(5 * time.Second),
+			Secure:        true,
+			TLSVerify:     true,
+			TLSServerName: "ldap.example.com",
+			Port:          636,
+			LDAPBindDN:    "cn=seesaw,ou=services,dc=example,dc=com",
+			LDAPPass:      "monitor",
+			LDAPSearchDN:  "dc=example,dc=com",
+		},
+	},
+	{
+		"SIP over TCP Healthcheck",
+		"healthcheck11.pb",
+		&Healthcheck{
+			Mode:       seesaw.HCModePlain,
+			Type:       seesaw.HCTypeSIP,
+			Interval:   time.Duration(10 * time.Second),
+			Timeout:    time.Duration(5 * time.Second),
+			TLSVerify:  true,
+			Port:       5060,
+			Code:       200,
+			SIPOverTCP: true,
+		},
+	},
+	{
+		"NTP Healthcheck",
+		"healthcheck12.pb",
+		&Healthcheck{
+			Mode:          seesaw.HCModePlain,
+			Type:          seesaw.HCTypeNTP,
+			Interval:      time.Duration(10 * time.Second),
+			Timeout:       time.Duration(5 * time.Second),
+			TLSVerify:     true,
+			Port:          123,
+			NTPMaxStratum: 4,
+			NTPMaxDisp:    500 * time.Millisecond,
+			NTPMaxOffset:  time.Second,
+		},
+	},
+	{
+		"FTPS Healthcheck",
+		"healthcheck13.pb",
+		&Healthcheck{
+			Mode:       seesaw.HCModePlain,
+			Type:       seesaw.HCTypeFTP,
+			Interval:   time.Duration(10 * time.Second),
+			Timeout:    time.Duration(5 * time.Second),
+			TLSVerify:  true,
+			Port:       21,
+			Secure:     true,
+			FTPUser:    "mirror",
+			FTPPass:    "mirror-pass",
+			FTPCommand: "CWD",
+			FTPPath:    "/pub",
+		},
+	},
+	{
+		"IMAP Healthcheck",
+		"healthcheck14.pb",
+		&Healthcheck{
+			Mode:         seesaw.HCModePlain,
+			Type:         seesaw.HCTypeIMAP,
+			Interval:     time.Duration(10 * time.Second),
+			Timeout:      time.Duration(5 * time.Second),
+			TLSVerify:    true,
+			Port:         143,
+			IMAPUser:     "mailcheck",
+			IMAPPass:     "mailcheck-pass",
+			IMAPMailbox:  "INBOX",
+			IMAPStartTLS: true,
+		},
+	},
+	{
+		"POP3S Healthcheck",
+		"healthcheck15.pb",
+		&Healthcheck{
+			Mode:     seesaw.HCModePlain,
+			Type:     seesaw.HCTypePOP3,
+			Interval: time.Duration(10 * time.Second),
+			Timeout:  time.Duration(5 * time.Second),
+			Port:     995,
+			Secure:   true,
+			POP3User: "mailcheck",
+			POP3Pass: "mailcheck-pass",
+		},
+	},
+	{
+		"Script Healthcheck",
+		"healthcheck16.pb",
+		&Healthcheck{
+			Mode:       seesaw.HCModePlain,
+			Type:       seesaw.HCTypeScript,
+			Interval:   time.Duration(10 * time.Second),
+			Timeout:    time.Duration(5 * time.Second),
+			TLSVerify:  true,
+			Port:       8080,
+			ScriptCmd:  "check_backend",
+			ScriptArgs: "--service\nsearch",
+		},
+	},
+	{
+		"TCP Half-open Healthcheck",
+		"healthcheck17.pb",
+		&Healthcheck{
+			Mode:        seesaw.HCModePlain,
+			Type:        seesaw.HCTypeTCP,
+			Interval:    time.Duration(10 * time.Second),
+			Timeout:     time.Duration(5 * time.Second),
+			TLSVerify:   true,
+			Port:        443,
+			TCPHalfOpen: true,
+		},
+	},
+	{
+		"Rise and Fall Healthcheck",
+		"healthcheck18.pb",
+		&Healthcheck{
+			Mode:      seesaw.HCModePlain,
+			Type:      seesaw.HCTypeTCP,
+			Interval:  time.Duration(10 * time.Second),
+			Timeout:   time.Duration(5 * time.Second),
+			TLSVerify: true,
+			Port:      80,
+			Retries:   1,
+			Rise:      3,
+			Fall:      2,
+		},
+	},
+	{
+		"Passive Healthcheck",
+		"healthcheck19.pb",
+		&Healthcheck{
+			Mode:        seesaw.HCModePlain,
+			Type:        seesaw.HCTypeTCP,
+			Interval:    time.Duration(10 * time.Second),
+			Timeout:     time.Duration(5 * time.Second),
+			TLSVerify:   true,
+			Port:        80,
+			Passive:     true,
+			PassiveHold: true,
 		},
 	},
 }
@@ -447,10 +726,11 @@ var vserverTests = []struct {
 						Type: seesaw.DedicatedVIP,
 					},
 				},
-				AccessGrants: map[string]*AccessGrant{},
-				Enabled:      true,
-				UseFWM:       false,
-				Warnings:     nil,
+				AccessGrants:      map[string]*AccessGrant{},
+				Enabled:           true,
+				UseFWM:            false,
+				ShareHealthchecks: true,
+				Warnings:          nil,
 			},
 			"irc.server@au-syd": {
 				Name: "irc.server@au-syd",
@@ -561,11 +841,13 @@ var vserverTests = []struct {
 				},
 				Entries: map[string]*VserverEntry{
 					"443/TCP": {
-						Port:         443,
-						Proto:        seesaw.IPProtoTCP,
-						Scheduler:    seesaw.LBSchedulerMH,
-						Mode:         seesaw.LBModeTUN,
-						Healthchecks: make(map[string]*Healthcheck),
+						Port:          443,
+						Proto:         seesaw.IPProtoTCP,
+						Scheduler:     seesaw.LBSchedulerMH,
+						Mode:          seesaw.LBModeTUN,
+						SchedFallback: true,
+						SchedPort:     true,
+						Healthchecks:  make(map[string]*Healthcheck),
 					},
 				},
 				Backends: map[string]*seesaw.Backend{
@@ -611,6 +893,220 @@ var vserverTests = []struct {
 			},
 		},
 	},
+	{
+		"1 Vserver with scheduler flags",
+		"vservers3.pb",
+		map[string]*Vserver{
+			"dns.resolver@au-syd": {
+				Name: "dns.resolver@au-syd",
+				Host: seesaw.Host{
+					Hostname: "dns-vip1.example.com.",
+					IPv4Addr: net.ParseIP("192.168.36.1").To4(),
+					IPv4Mask: net.CIDRMask(26, 32),
+				},
+				Entries: map[string]*VserverEntry{
+					"53/UDP": {
+						Port:          53,
+						Proto:         seesaw.IPProtoUDP,
+						Scheduler:     seesaw.LBSchedulerSH,
+						Mode:          seesaw.LBModeDSR,
+						SchedFallback: true,
+						SchedPort:     false,
+						Healthchecks:  make(map[string]*Healthcheck),
+					},
+				},
+				Backends:     map[string]*seesaw.Backend{},
+				Healthchecks: map[string]*Healthcheck{},
+				VIPs: map[string]*seesaw.VIP{
+					"192.168.36.1 (Unicast)": {
+						IP:   seesaw.NewIP(net.ParseIP("192.168.36.1")),
+						Type: seesaw.UnicastVIP,
+					},
+				},
+				AccessGrants: map[string]*AccessGrant{},
+				Enabled:      true,
+				Warnings:     []string{"scheduler flags are not supported by scheduler wrr"},
+			},
+		},
+	},
+	{
+		"1 Vserver with persistence prefix lengths",
+		"vservers4.pb",
+		map[string]*Vserver{
+			"web.frontend@au-syd": {
+				Name: "web.frontend@au-syd",
+				Host: seesaw.Host{
+					Hostname: "web-vip1.example.com.",
+					IPv4Addr: net.ParseIP("192.168.36.1").To4(),
+					IPv4Mask: net.CIDRMask(26, 32),
+				},
+				Entries: map[string]*VserverEntry{
+					"443/TCP": {
+						Port:               443,
+						Proto:              seesaw.IPProtoTCP,
+						Scheduler:          seesaw.LBSchedulerWRR,
+						Mode:               seesaw.LBModeDSR,
+						Persistence:        300,
+						PersistenceIPv4Len: 24,
+						PersistenceIPv6Len: 64,
+						Healthchecks:       make(map[string]*Healthcheck),
+					},
+				},
+				Backends:     map[string]*seesaw.Backend{},
+				Healthchecks: map[string]*Healthcheck{},
+				VIPs: map[string]*seesaw.VIP{
+					"192.168.36.1 (Unicast)": {
+						IP:   seesaw.NewIP(net.ParseIP("192.168.36.1")),
+						Type: seesaw.UnicastVIP,
+					},
+				},
+				AccessGrants: map[string]*AccessGrant{},
+				Enabled:      true,
+				Warnings:     []string{"persistence prefix length requires persistence for 80/TCP"},
+			},
+		},
+	},
+	{
+		"1 Vserver with tunnel encapsulation",
+		"vservers5.pb",
+		map[string]*Vserver{
+			"web.frontend@au-syd": {
+				Name: "web.frontend@au-syd",
+				Host: seesaw.Host{
+					Hostname: "web-vip1.example.com.",
+					IPv4Addr: net.ParseIP("192.168.36.1").To4(),
+					IPv4Mask: net.CIDRMask(26, 32),
+				},
+				Entries: map[string]*VserverEntry{
+					"443/TCP": {
+						Port:           443,
+						Proto:          seesaw.IPProtoTCP,
+						Scheduler:      seesaw.LBSchedulerWRR,
+						Mode:           seesaw.LBModeTUN,
+						TunnelType:     ipvs.TunnelGUE,
+						TunnelPort:     6080,
+						TunnelChecksum: ipvs.TunnelChecksumRemote,
+						Healthchecks:   make(map[string]*Healthcheck),
+					},
+				},
+				Backends:     map[string]*seesaw.Backend{},
+				Healthchecks: map[string]*Healthcheck{},
+				VIPs: map[string]*seesaw.VIP{
+					"192.168.36.1 (Unicast)": {
+						IP:   seesaw.NewIP(net.ParseIP("192.168.36.1")),
+						Type: seesaw.UnicastVIP,
+					},
+				},
+				AccessGrants: map[string]*AccessGrant{},
+				Enabled:      true,
+				Warnings: []string{
+					"gre tunnels do not support a port for 80/TCP",
+					"tunnel options require TUN mode for 8080/TCP",
+				},
+			},
+		},
+	},
+	{
+		"2 Vservers with mixed address families",
+		"vservers6.pb",
+		map[string]*Vserver{
+			"dns.resolver@au-syd": {
+				Name: "dns.resolver@au-syd",
+				Host: seesaw.Host{
+					Hostname: "dns-vip1.example.com.",
+					IPv4Addr: net.ParseIP("192.168.36.2").To4(),
+					IPv4Mask: net.CIDRMask(26, 32),
+				},
+				Entries: map[string]*VserverEntry{
+					"53/UDP": {
+						Port:         53,
+						Proto:        seesaw.IPProtoUDP,
+						Scheduler:    seesaw.LBSchedulerWRR,
+						Mode:         seesaw.LBModeDSR,
+						Healthchecks: make(map[string]*Healthcheck),
+					},
+				},
+				Backends:     map[string]*seesaw.Backend{},
+				Healthchecks: map[string]*Healthcheck{},
+				VIPs: map[string]*seesaw.VIP{
+					"192.168.36.2 (Unicast)": {
+						IP:   seesaw.NewIP(net.ParseIP("192.168.36.2")),
+						Type: seesaw.UnicastVIP,
+					},
+				},
+				AccessGrants: map[string]*AccessGrant{},
+				Enabled:      true,
+				Warnings: []string{
+					"mixed address families require TUN mode, disabled due to 53/UDP",
+				},
+			},
+			"web.frontend@au-syd": {
+				Name: "web.frontend@au-syd",
+				Host: seesaw.Host{
+					Hostname: "web-vip1.example.com.",
+					IPv4Addr: net.ParseIP("192.168.36.1").To4(),
+					IPv4Mask: net.CIDRMask(26, 32),
+				},
+				Entries: map[string]*VserverEntry{
+					"443/TCP": {
+						Port:         443,
+						Proto:        seesaw.IPProtoTCP,
+						Scheduler:    seesaw.LBSchedulerWRR,
+						Mode:         seesaw.LBModeTUN,
+						Healthchecks: make(map[string]*Healthcheck),
+					},
+				},
+				Backends:     map[string]*seesaw.Backend{},
+				Healthchecks: map[string]*Healthcheck{},
+				VIPs: map[string]*seesaw.VIP{
+					"192.168.36.1 (Unicast)": {
+						IP:   seesaw.NewIP(net.ParseIP("192.168.36.1")),
+						Type: seesaw.UnicastVIP,
+					},
+				},
+				AccessGrants:  map[string]*AccessGrant{},
+				Enabled:       true,
+				MixedFamilies: true,
+			},
+		},
+	},
+	{
+		"1 Vserver with one-packet scheduling",
+		"vservers7.pb",
+		map[string]*Vserver{
+			"dns.resolver@au-syd": {
+				Name: "dns.resolver@au-syd",
+				Host: seesaw.Host{
+					Hostname: "dns-vip1.example.com.",
+					IPv4Addr: net.ParseIP("192.168.36.2").To4(),
+					IPv4Mask: net.CIDRMask(26, 32),
+				},
+				Entries: map[string]*VserverEntry{
+					"53/UDP": {
+						Port:         53,
+						Proto:        seesaw.IPProtoUDP,
+						Scheduler:    seesaw.LBSchedulerWRR,
+						Mode:         seesaw.LBModeDSR,
+						OnePacket:    true,
+						Healthchecks: make(map[string]*Healthcheck),
+					},
+				},
+				Backends:     map[string]*seesaw.Backend{},
+				Healthchecks: map[string]*Healthcheck{},
+				VIPs: map[string]*seesaw.VIP{
+					"192.168.36.2 (Unicast)": {
+						IP:   seesaw.NewIP(net.ParseIP("192.168.36.2")),
+						Type: seesaw.UnicastVIP,
+					},
+				},
+				AccessGrants: map[string]*AccessGrant{},
+				Enabled:      true,
+				Warnings: []string{
+					"one-packet scheduling requires UDP for 53/TCP",
+				},
+			},
+		},
+	},
 }
 
 func readHealthcheck(f string) (*pb.Healthcheck, error) {
@@ -20,41 +20,86 @@ package config
 // for a seesaw engine.
 
 import (
+	"fmt"
 	"net"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/google/seesaw/common/seesaw"
+	"github.com/google/seesaw/ipvs"
 )
 
 var defaultEngineConfig = EngineConfig{
-	AnycastEnabled:          true,
-	BGPUpdateInterval:       15 * time.Second,
-	CACertFile:              path.Join(seesaw.ConfigPath, "ssl", "ca.crt"),
-	CertFile:                path.Join(seesaw.ConfigPath, "ssl", "seesaw.crt"),
-	KeyFile:                 path.Join(seesaw.ConfigPath, "ssl", "seesaw.key"),
-	ConfigFile:              path.Join(seesaw.ConfigPath, "seesaw.cfg"),
-	ConfigInterval:          1 * time.Minute,
-	ConfigServers:           []string{"seesaw-config.example.com"},
-	ConfigServerPort:        10255,
-	ConfigServerTimeout:     20 * time.Second,
-	ClusterFile:             path.Join(seesaw.ConfigPath, "cluster.pb"),
-	DummyInterface:          "dummy0",
-	GratuitousARPInterval:   10 * time.Second,
-	HAStateTimeout:          30 * time.Second,
-	LBInterface:             "eth1",
-	MaxPeerConfigSyncErrors: 3,
-	NCCSocket:               seesaw.NCCSocket,
-	NodeInterface:           "eth0",
-	RoutingTableID:          2,
-	ServiceAnycastIPv4:      []net.IP{seesaw.TestAnycastHost().IPv4Addr},
-	ServiceAnycastIPv6:      []net.IP{seesaw.TestAnycastHost().IPv6Addr},
-	SocketPath:              seesaw.EngineSocket,
-	StatsInterval:           15 * time.Second,
-	SyncPort:                10258,
-	UseVMAC:                 true,
-	VRID:                    60,
-	VRRPDestIP:              net.ParseIP("224.0.0.18"),
+	AnycastEnabled:           true,
+	BGPUpdateInterval:        15 * time.Second,
+	CACertFile:               path.Join(seesaw.ConfigPath, "ssl", "ca.crt"),
+	CertFile:                 path.Join(seesaw.ConfigPath, "ssl", "seesaw.crt"),
+	KeyFile:                  path.Join(seesaw.ConfigPath, "ssl", "seesaw.key"),
+	ConfigFile:               path.Join(seesaw.ConfigPath, "seesaw.cfg"),
+	ConfigInterval:           1 * time.Minute,
+	ConfigServers:            []string{"seesaw-config.example.com"},
+	ConfigServerPort:         10255,
+	ConfigServerTimeout:      20 * time.Second,
+	ClusterFile:              path.Join(seesaw.ConfigPath, "cluster.pb"),
+	DummyInterface:           "dummy0",
+	GratuitousARPInterval:    10 * time.Second,
+	HAStateTimeout:           30 * time.Second,
+	HealthcheckAF:            seesaw.IPv4,
+	HealthcheckMaxConcurrent: 1000,
+	HealthcheckSocket:        seesaw.HealthcheckSocket,
+	HealthcheckStalePolicy:   StalePolicyFreeze,
+	HealthcheckStaleTimeout:  1 * time.Minute,
+	IPVSReconcileInterval:    1 * time.Minute,
+	LBInterface:              "eth1",
+	MaxPeerConfigSyncErrors:  3,
+	NCCSocket:                seesaw.NCCSocket,
+	NodeInterface:            "eth0",
+	RouteRetryInterval:       10 * time.Second,
+	RoutingTableID:           2,
+	ServiceAnycastIPv4:       []net.IP{seesaw.TestAnycastHost().IPv4Addr},
+	ServiceAnycastIPv6:       []net.IP{seesaw.TestAnycastHost().IPv6Addr},
+	SocketPath:               seesaw.EngineSocket,
+	StatsInterval:            15 * time.Second,
+	SyncPort:                 seesaw.DefaultSyncPort,
+	UseVMAC:                  true,
+	VRID:                     60,
+	VRRPDestIP:               net.ParseIP("224.0.0.18"),
+}
+
+// StalePolicy specifies how the engine handles healthcheck states once
+// notifications from the healthcheck component have become stale.
+type StalePolicy int
+
+const (
+	// StalePolicyFreeze retains the last known healthcheck states.
+	StalePolicyFreeze StalePolicy = iota
+	// StalePolicyUnknown marks all healthcheck states as unknown, which
+	// results in the destinations being treated as unhealthy.
+	StalePolicyUnknown
+)
+
+var stalePolicyNames = map[StalePolicy]string{
+	StalePolicyFreeze:  "freeze",
+	StalePolicyUnknown: "unknown",
+}
+
+// String returns the string representation of a StalePolicy.
+func (p StalePolicy) String() string {
+	if name, ok := stalePolicyNames[p]; ok {
+		return name
+	}
+	return "(unknown)"
+}
+
+// ParseStalePolicy returns the StalePolicy with the given name.
+func ParseStalePolicy(name string) (StalePolicy, error) {
+	for p, n := range stalePolicyNames {
+		if strings.EqualFold(name, n) {
+			return p, nil
+		}
+	}
+	return StalePolicyFreeze, fmt.Errorf("unknown stale policy %q", name)
 }
 
 // DefaultEngineConfig returns the default engine configuration.
@@ -64,36 +109,47 @@ func DefaultEngineConfig() EngineConfig {
 
 // EngineConfig provides configuration details for an Engine.
 type EngineConfig struct {
-	AnycastEnabled          bool          // Flag to enable or disable anycast.
-	BGPUpdateInterval       time.Duration // The BGP update interval.
-	CACertFile              string        // The path to the SSL/TLS CA cert file.
-	CertFile                string        // The path to the SSL/TLS certificate file.
-	KeyFile                 string        // The path to the SSL/TLS key file.
-	ClusterFile             string        // The path to the cluster protobuf file.
-	ClusterName             string        // The name of the cluster the engine is running in.
-	ClusterVIP              seesaw.Host   // The VIP for this Seesaw Cluster.
-	ConfigInterval          time.Duration // The cluster configuration update interval.
-	ConfigFile              string        // The path to the engine config file.
-	ConfigServers           []string      // The list of configuration servers (hostnames) in priority order.
-	ConfigServerPort        int           // The configuration server port number.
-	ConfigServerTimeout     time.Duration // The configuration server client timeout (per TCP connection).
-	DummyInterface          string        // The dummy network interface.
-	GratuitousARPInterval   time.Duration // The interval for gratuitous ARP messages.
-	HAStateTimeout          time.Duration // The timeout for receiving HAState updates.
-	LBInterface             string        // The network interface to use for load balancing.
-	MaxPeerConfigSyncErrors int           // The number of allowable peer config sync errors.
-	NCCSocket               string        // The Network Control Center socket.
-	NodeInterface           string        // The primary network interface for this node.
-	Node                    seesaw.Host   // The node the engine is running on.
-	Peer                    seesaw.Host   // The node's peer.
-	RoutingTableID          uint8         // The routing table ID to use for load balanced traffic.
-	ServiceAnycastIPv4      []net.IP      // IPv4 anycast addresses that are always advertised.
-	ServiceAnycastIPv6      []net.IP      // IPv6 anycast addresses that are always advertised.
-	SocketPath              string        // The path to the engine socket.
-	StatsInterval           time.Duration // The statistics update interval.
-	SyncPort                int           // The port for sync'ing with this node's peer.
-	UseVMAC                 bool          // Use VRRP MAC. If false, Seesaw uses gratuitous arp for failover (ipv6 not supported yet). Default true.
-	VMAC                    string        // The VMAC address to use for the load balancing network interface.
-	VRID                    uint8         // The VRRP virtual router ID for the cluster.
-	VRRPDestIP              net.IP        // The destination IP for VRRP advertisements.
+	AnycastEnabled           bool          // Flag to enable or disable anycast.
+	BGPUpdateInterval        time.Duration // The BGP update interval.
+	CACertFile               string        // The path to the SSL/TLS CA cert file.
+	CertFile                 string        // The path to the SSL/TLS certificate file.
+	KeyFile                  string        // The path to the SSL/TLS key file.
+	ClusterFile              string        // The path to the cluster protobuf file.
+	ClusterName              string        // The name of the cluster the engine is running in.
+	ClusterVIP               seesaw.Host   // The VIP for this Seesaw Cluster.
+	ConfigInterval           time.Duration // The cluster configuration update interval.
+	ConfigFile               string        // The path to the engine config file.
+	ConfigServers            []string      // The list of configuration servers (hostnames) in priority order.
+	ConfigServerPort         int           // The configuration server port number.
+	ConfigServerTimeout      time.Duration // The configuration server client timeout (per TCP connection).
+	DummyInterface           string        // The dummy network interface.
+	GratuitousARPInterval    time.Duration // The interval for gratuitous ARP messages.
+	HAStateTimeout           time.Duration // The timeout for receiving HAState updates.
+	HealthcheckAF            seesaw.AF     // The preferred address family for shared dual-stack healthchecks.
+	HealthcheckMaxConcurrent int           // The maximum number of healthchecks executed concurrently.
+	HealthcheckSocket        string        // The healthcheck component socket.
+	HealthcheckStalePolicy   StalePolicy   // The handling of healthcheck states once notifications are stale.
+	HealthcheckStaleTimeout  time.Duration // The time without healthcheck notifications before they are considered stale.
+	IPVSReconcileInterval    time.Duration // The interval between repairs of drift in the IPVS table. Zero disables periodic repairs.
+	IPVSSyncID               uint8         // The sync ID for the IPVS connection synchronisation daemon.
+	IPVSSyncInterface        string        // The multicast interface for the IPVS connection synchronisation daemon.
+	IPVSTimeouts             ipvs.Timeouts // The IPVS connection timeouts. Zero timeouts are left unchanged.
+	LBInterface              string        // The network interface to use for load balancing.
+	MaxPeerConfigSyncErrors  int           // The number of allowable peer config sync errors.
+	NCCSocket                string        // The Network Control Center socket.
+	NodeInterface            string        // The primary network interface for this node.
+	Node                     seesaw.Host   // The node the engine is running on.
+	Peer                     seesaw.Host   // The node's peer.
+	RouteRetryInterval       time.Duration // The interval between attempts to update route advertisements.
+	Routes                   []*Route      // The networks to advertise via BGP while this node is the HA leader.
+	RoutingTableID           uint8         // The routing table ID to use for load balanced traffic.
+	ServiceAnycastIPv4       []net.IP      // IPv4 anycast addresses that are always advertised.
+	ServiceAnycastIPv6       []net.IP      // IPv6 anycast addresses that are always advertised.
+	SocketPath               string        // The path to the engine socket.
+	StatsInterval            time.Duration // The statistics update interval.
+	SyncPort                 int           // The port for sync'ing with this node's peer.
+	UseVMAC                  bool          // Use VRRP MAC. If false, Seesaw uses gratuitous arp for failover (ipv6 not supported yet). Default true.
+	VMAC                     string        // The VMAC address to use for the load balancing network interface.
+	VRID                     uint8         // The VRRP virtual router ID for the cluster.
+	VRRPDestIP               net.IP        // The destination IP for VRRP advertisements.
 }
@@ -0,0 +1,70 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Route specifies a network that is advertised via BGP while this node is the
+// HA leader.
+type Route struct {
+	Network    net.IPNet
+	Anycast    bool // Only advertise while anycast is enabled.
+	MinHealthy int  // The minimum number of healthy VIPs within the network.
+}
+
+// String returns the string representation of a Route.
+func (r *Route) String() string {
+	s := r.Network.String()
+	if r.Anycast {
+		s += " anycast"
+	}
+	if r.MinHealthy > 0 {
+		s += fmt.Sprintf(" min_healthy=%d", r.MinHealthy)
+	}
+	return s
+}
+
+// ParseRoute parses a route of the form "<network> [anycast] [min_healthy=<n>]".
+func ParseRoute(s string) (*Route, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty route")
+	}
+	_, network, err := net.ParseCIDR(fields[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid route network %q: %v", fields[0], err)
+	}
+	r := &Route{Network: *network}
+	for _, f := range fields[1:] {
+		switch {
+		case f == "anycast":
+			r.Anycast = true
+		case strings.HasPrefix(f, "min_healthy="):
+			n, err := strconv.Atoi(strings.TrimPrefix(f, "min_healthy="))
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid route option %q", f)
+			}
+			r.MinHealthy = n
+		default:
+			return nil, fmt.Errorf("unknown route option %q", f)
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+func mustParseCIDR(s string) net.IPNet {
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return *n
+}
+
+var routeTests = []struct {
+	in   string
+	want *Route
+}{
+	{"192.168.0.0/24", &Route{Network: mustParseCIDR("192.168.0.0/24")}},
+	{"192.168.0.1/24", &Route{Network: mustParseCIDR("192.168.0.0/24")}},
+	{"2015:cafe::/48 anycast", &Route{Network: mustParseCIDR("2015:cafe::/48"), Anycast: true}},
+	{
+		"10.0.0.0/8 min_healthy=2 anycast",
+		&Route{Network: mustParseCIDR("10.0.0.0/8"), Anycast: true, MinHealthy: 2},
+	},
+	{"", nil},
+	{"10.0.0.1", nil},
+	{"10.0.0.0/8 always", nil},
+	{"10.0.0.0/8 min_healthy=x", nil},
+	{"10.0.0.0/8 min_healthy=-1", nil},
+}
+
+func TestParseRoute(t *testing.T) {
+	for _, test := range routeTests {
+		got, err := ParseRoute(test.in)
+		if test.want == nil {
+			if err == nil {
+				t.Errorf("ParseRoute(%q) = %v, want error", test.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseRoute(%q) failed: %v", test.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ParseRoute(%q) = %v, want %v", test.in, got, test.want)
+		}
+	}
+}
@@ -8,3 +8,18 @@ method: "HEAD"
 send: "foo"
 receive: "bar"
 proxy: true
+header: "x-seesaw-check: yes"
+header: "Host:www.example.com"
+header: "bogus"
+receive_regexp: "^ba"
+codes: "2xx,404"
+max_redirects: 3
+expect_header: "x-version: 1.2.3"
+forbid_header: "x-drain"
+forbid_header: " X-Maintenance "
+max_latency_ms: 1500
+request_body: "{}"
+content_type: "application/json"
+tls_server_name: "www.example.com"
+tls_resume_sessions: true
+reuse_connections: true
@@ -0,0 +1,6 @@
+type: LDAPS
+port: 636
+tls_server_name: "ldap.example.com"
+ldap_bind_dn: "cn=seesaw,ou=services,dc=example,dc=com"
+ldap_password: "monitor"
+ldap_search_dn: "dc=example,dc=com"
@@ -0,0 +1,3 @@
+type: SIP_TCP
+port: 5060
+code: 200
@@ -0,0 +1,5 @@
+type: NTP
+port: 123
+ntp_max_stratum: 4
+ntp_max_root_dispersion_ms: 500
+ntp_max_offset_ms: 1000
@@ -0,0 +1,6 @@
+type: FTPS
+port: 21
+ftp_user: "mirror"
+ftp_password: "mirror-pass"
+ftp_command: "CWD"
+ftp_path: "/pub"
@@ -0,0 +1,6 @@
+type: IMAP
+port: 143
+imap_user: "mailcheck"
+imap_password: "mailcheck-pass"
+imap_mailbox: "INBOX"
+imap_starttls: true
@@ -0,0 +1,5 @@
+type: POP3S
+port: 995
+pop3_user: "mailcheck"
+pop3_password: "mailcheck-pass"
+tls_verify: false
@@ -0,0 +1,5 @@
+type: SCRIPT
+port: 8080
+script_command: "check_backend"
+script_arg: "--service"
+script_arg: "search"
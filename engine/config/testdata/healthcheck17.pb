@@ -0,0 +1,3 @@
+type: TCP
+port: 443
+tcp_half_open: true
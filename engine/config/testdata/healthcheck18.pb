@@ -0,0 +1,5 @@
+type: TCP
+port: 80
+retries: 1
+rise: 3
+fall: 2
@@ -0,0 +1,4 @@
+type: TCP
+port: 80
+passive: true
+passive_hold: true
@@ -6,3 +6,12 @@ mode: DSR
 method: "A"
 send: "www.example.com"
 receive: "192.168.0.1"
+dns_authoritative: true
+dns_rcode: "NOERROR"
+dns_udp_size: 4096
+dnssec: "AD"
+dns_warn_latency_ms: 50
+dns_max_latency_ms: 200
+dns_query_attempts: 2
+dns_strict_id: true
+dns_case_randomization: true
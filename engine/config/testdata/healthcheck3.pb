@@ -0,0 +1,7 @@
+type: RADIUS
+port: 1812
+radius_username: "monitor"
+radius_password: "ignored"
+radius_secret: "sharedsecret"
+radius_response: "reject"
+radius_status_server: true
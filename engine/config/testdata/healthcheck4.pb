@@ -0,0 +1,5 @@
+type: GRPC_TLS
+port: 8443
+tls_verify: false
+tls_server_name: "backend.example.com"
+grpc_service: "example.Backend"
@@ -0,0 +1,6 @@
+type: MYSQL
+port: 3306
+mysql_user: "seesaw"
+mysql_password: "monitor"
+mysql_query: "SELECT 1"
+mysql_expect_writable: true
@@ -0,0 +1,7 @@
+type: POSTGRESQL
+port: 5432
+postgres_user: "seesaw"
+postgres_password: "monitor"
+postgres_database: "postgres"
+postgres_sslmode: "require"
+postgres_expect_primary: true
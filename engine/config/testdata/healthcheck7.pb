@@ -0,0 +1,5 @@
+type: REDIS
+port: 6379
+redis_username: "seesaw"
+redis_password: "monitor"
+redis_role: "master"
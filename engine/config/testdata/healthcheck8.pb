@@ -0,0 +1,5 @@
+type: MEMCACHE
+port: 11211
+memcache_stats_key: "accepting_conns"
+memcache_stats_value: "1"
+memcache_key_prefix: "seesaw-hc-"
@@ -0,0 +1,6 @@
+type: SMTP
+port: 25
+tls_verify: false
+smtp_hostname: "seesaw.example.com"
+smtp_starttls: true
+smtp_capability: "PIPELINING"
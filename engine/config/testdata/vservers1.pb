@@ -6,6 +6,7 @@ seesaw_vip <
 vserver <
   name: "dns.resolver@au-syd"
   rp: "foo"
+  share_healthchecks: true
   entry_address <
     fqdn: "dns-vip1.example.com."
     ipv4: "192.168.36.1/26"
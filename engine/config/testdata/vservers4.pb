@@ -0,0 +1,28 @@
+seesaw_vip <
+  fqdn: "seesaw-vip1.example.com."
+  ipv4: "192.168.36.16/26"
+  status: PRODUCTION
+>
+vserver <
+  name: "web.frontend@au-syd"
+  rp: "foo"
+  entry_address <
+    fqdn: "web-vip1.example.com."
+    ipv4: "192.168.36.1/26"
+    status: PRODUCTION
+  >
+  vserver_entry <
+    protocol: TCP
+    port: 443
+    scheduler: WRR
+    persistence: 300
+    persistence_ipv4_prefix_length: 24
+    persistence_ipv6_prefix_length: 64
+  >
+  vserver_entry <
+    protocol: TCP
+    port: 80
+    scheduler: WRR
+    persistence_ipv4_prefix_length: 24
+  >
+>
@@ -0,0 +1,38 @@
+seesaw_vip <
+  fqdn: "seesaw-vip1.example.com."
+  ipv4: "192.168.36.16/26"
+  status: PRODUCTION
+>
+vserver <
+  name: "web.frontend@au-syd"
+  rp: "foo"
+  entry_address <
+    fqdn: "web-vip1.example.com."
+    ipv4: "192.168.36.1/26"
+    status: PRODUCTION
+  >
+  vserver_entry <
+    protocol: TCP
+    port: 443
+    scheduler: WRR
+    mode: TUN
+    tunnel_type: "gue"
+    tunnel_port: 6080
+    tunnel_checksum: "remcsum"
+  >
+  vserver_entry <
+    protocol: TCP
+    port: 80
+    scheduler: WRR
+    mode: TUN
+    tunnel_type: "gre"
+    tunnel_port: 6080
+  >
+  vserver_entry <
+    protocol: TCP
+    port: 8080
+    scheduler: WRR
+    tunnel_type: "gue"
+    tunnel_port: 6080
+  >
+>
@@ -0,0 +1,37 @@
+seesaw_vip <
+  fqdn: "seesaw-vip1.example.com."
+  ipv4: "192.168.36.16/26"
+  status: PRODUCTION
+>
+vserver <
+  name: "dns.resolver@au-syd"
+  rp: "foo"
+  entry_address <
+    fqdn: "dns-vip1.example.com."
+    ipv4: "192.168.36.2/26"
+    status: PRODUCTION
+  >
+  vserver_entry <
+    protocol: UDP
+    port: 53
+    scheduler: WRR
+    mode: DSR
+  >
+  mixed_address_families: true
+>
+vserver <
+  name: "web.frontend@au-syd"
+  rp: "foo"
+  entry_address <
+    fqdn: "web-vip1.example.com."
+    ipv4: "192.168.36.1/26"
+    status: PRODUCTION
+  >
+  vserver_entry <
+    protocol: TCP
+    port: 443
+    scheduler: WRR
+    mode: TUN
+  >
+  mixed_address_families: true
+>
@@ -0,0 +1,28 @@
+seesaw_vip <
+  fqdn: "seesaw-vip1.example.com."
+  ipv4: "192.168.36.16/26"
+  status: PRODUCTION
+>
+vserver <
+  name: "dns.resolver@au-syd"
+  rp: "foo"
+  entry_address <
+    fqdn: "dns-vip1.example.com."
+    ipv4: "192.168.36.2/26"
+    status: PRODUCTION
+  >
+  vserver_entry <
+    protocol: UDP
+    port: 53
+    scheduler: WRR
+    mode: DSR
+    one_packet: true
+  >
+  vserver_entry <
+    protocol: TCP
+    port: 53
+    scheduler: WRR
+    mode: DSR
+    one_packet: true
+  >
+>
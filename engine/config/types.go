@@ -22,9 +22,11 @@ import (
 	"fmt"
 	"net"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/google/seesaw/common/seesaw"
+	"github.com/google/seesaw/ipvs"
 )
 
 // AccessGrant specifies an access grant for a user or group.
@@ -149,14 +151,16 @@ func (c *Cluster) Equal(other *Cluster) bool {
 type Vserver struct {
 	Name string
 	seesaw.Host
-	Entries      map[string]*VserverEntry   // by VserverEntry.Key()
-	Backends     map[string]*seesaw.Backend // by Backend.Key()
-	Healthchecks map[string]*Healthcheck    // by Healthcheck.Key()
-	VIPs         map[string]*seesaw.VIP     // by VIP.IP.String()
-	AccessGrants map[string]*AccessGrant    // by AccessGrant.Key()
-	Enabled      bool
-	UseFWM       bool
-	Warnings     []string
+	Entries           map[string]*VserverEntry   // by VserverEntry.Key()
+	Backends          map[string]*seesaw.Backend // by Backend.Key()
+	Healthchecks      map[string]*Healthcheck    // by Healthcheck.Key()
+	VIPs              map[string]*seesaw.VIP     // by VIP.IP.String()
+	AccessGrants      map[string]*AccessGrant    // by AccessGrant.Key()
+	Enabled           bool
+	UseFWM            bool
+	ShareHealthchecks bool // Healthcheck dual-stack backends once for both address families.
+	MixedFamilies     bool // Use backends from the other address family, via tunnels.
+	Warnings          []string
 }
 
 // NewVserver creates a new, initialised Vserver structure.
@@ -231,17 +235,26 @@ func (v *Vserver) AddVIP(vip *seesaw.VIP) error {
 // VserverEntry specifies the configuration for a port and protocol combination
 // for a Vserver.
 type VserverEntry struct {
-	Port          uint16
-	Proto         seesaw.IPProto
-	Scheduler     seesaw.LBScheduler
-	Mode          seesaw.LBMode
-	Persistence   int
-	OnePacket     bool
-	HighWatermark float32
-	LowWatermark  float32
-	LowerThreshold    int
-	UpperThreshold    int
-	Healthchecks  map[string]*Healthcheck // by Healthcheck.Key()
+	Port               uint16
+	Proto              seesaw.IPProto
+	Scheduler          seesaw.LBScheduler
+	Mode               seesaw.LBMode
+	Persistence        int
+	OnePacket          bool
+	HighWatermark      float32
+	LowWatermark       float32
+	LowerThreshold     int
+	UpperThreshold     int
+	WarmupHealthyCount int                     // Consecutive healthy results required for new destinations.
+	SchedFallback      bool                    // Fallback for the SH and MH schedulers.
+	SchedPort          bool                    // Include the port in the hash for the SH and MH schedulers.
+	PersistenceIPv4Len int                     // IPv4 prefix length for grouping persistent clients.
+	PersistenceIPv6Len int                     // IPv6 prefix length for grouping persistent clients.
+	DrainUnhealthy     bool                    // Drain rather than delete unhealthy destinations.
+	TunnelType         ipvs.TunnelType         // Encapsulation for TUN mode.
+	TunnelPort         uint16                  // UDP destination port for GUE encapsulation.
+	TunnelChecksum     ipvs.TunnelChecksum     // Checksum handling for GUE and GRE encapsulation.
+	Healthchecks       map[string]*Healthcheck // by Healthcheck.Key()
 }
 
 // NewVserverEntry creates a new, initialised VserverEntry structure.
@@ -271,36 +284,112 @@ func (v *VserverEntry) Key() string {
 // Snapshot returns a snapshot for a VserverEntry.
 func (v *VserverEntry) Snapshot() *seesaw.VserverEntry {
 	return &seesaw.VserverEntry{
-		Port:          v.Port,
-		Proto:         v.Proto,
-		Scheduler:     v.Scheduler,
-		Mode:          v.Mode,
-		Persistence:   v.Persistence,
-		OnePacket:     v.OnePacket,
-		HighWatermark: v.HighWatermark,
-		LowWatermark:  v.LowWatermark,
-		LowerThreshold:    v.LowerThreshold,
-		UpperThreshold:    v.UpperThreshold,
+		Port:           v.Port,
+		Proto:          v.Proto,
+		Scheduler:      v.Scheduler,
+		Mode:           v.Mode,
+		Persistence:    v.Persistence,
+		OnePacket:      v.OnePacket,
+		HighWatermark:  v.HighWatermark,
+		LowWatermark:   v.LowWatermark,
+		LowerThreshold: v.LowerThreshold,
+		UpperThreshold: v.UpperThreshold,
 	}
 }
 
 // Healthcheck represents a healthcheck that needs to be run against a
 // Backend or Destination.
 type Healthcheck struct {
-	Name      string
-	Mode      seesaw.HealthcheckMode
-	Type      seesaw.HealthcheckType
-	Port      uint16        // The backend port to connect to.
-	Interval  time.Duration // How frequently this healthcheck is executed.
-	Timeout   time.Duration // The execution timeout.
-	Retries   int           // Number of times to retry a healthcheck.
-	Send      string        // The request to be sent to the backend.
-	Receive   string        // The expected response from the backend.
-	Code      int           // The expected response code from the backend.
-	Proxy     bool          // Perform healthchecks against an HTTP proxy.
-	Method    string        // The request method for an HTTP/S healthcheck.
-	Secure    bool          // Use TLS for the healthcheck connection.
-	TLSVerify bool          // Do TLS verification.
+	Name          string
+	Mode          seesaw.HealthcheckMode
+	Type          seesaw.HealthcheckType
+	Port          uint16        // The backend port to connect to.
+	Interval      time.Duration // How frequently this healthcheck is executed.
+	Timeout       time.Duration // The execution timeout.
+	Retries       int           // Number of times to retry a healthcheck.
+	Rise          int           // Consecutive successes before becoming healthy.
+	Fall          int           // Consecutive failures before becoming unhealthy.
+	Passive       bool          // Report results without acting upon them.
+	PassiveHold   bool          // Hold the last state while passive, rather than healthy.
+	MaxLatency    time.Duration // Slower successful healthchecks fail.
+	Send          string        // The request to be sent to the backend.
+	Receive       string        // The expected response from the backend.
+	Code          int           // The expected response code from the backend.
+	Codes         string        // The expected HTTP response codes, e.g. "200,3xx".
+	Proxy         bool          // Perform healthchecks against an HTTP proxy.
+	ProxyURL      string        // Tunnel HTTP healthchecks through this proxy.
+	Method        string        // The request method for an HTTP/S healthcheck.
+	RequestBody   string        // The request body for a POST or PUT HTTP/S healthcheck.
+	ContentType   string        // The content type of the request body.
+	MaxRedirects  int           // The number of HTTP redirects to follow.
+	Secure        bool          // Use TLS for the healthcheck connection.
+	TLSVerify     bool          // Do TLS verification.
+	TLSCertFile   string        // Client certificate to present for TLS.
+	TLSKeyFile    string        // Client certificate key.
+	TLSCAFile     string        // CA certificates used to verify the backend.
+	TLSServerName string        // Server name for SNI and verification.
+	TLSResume     bool          // Resume TLS sessions across healthchecks.
+	ReuseConns    bool          // Hold connections open between healthchecks.
+	DNSAuth       bool          // Require authoritative DNS answers.
+	DNSRcode      string        // The expected DNS response code, e.g. "NXDOMAIN".
+	DNSUDPSize    int           // The EDNS0 UDP buffer size for DNS healthchecks.
+	DNSSEC        string        // The DNSSEC validation mode, "AD" or "RRSIG".
+	DNSWarnAfter  time.Duration // DNS query latency that is reported as slow.
+	DNSFailAfter  time.Duration // DNS query latency that fails the healthcheck.
+	DNSAttempts   int           // Number of times a DNS query is sent.
+	DNSStrictID   bool          // Reject DNS responses with a mismatched ID.
+	DNSRandCase   bool          // Use DNS 0x20 encoding for query names.
+	RADIUSUser    string        // The RADIUS username.
+	RADIUSPass    string        // The RADIUS password.
+	RADIUSSecret  string        // The RADIUS shared secret.
+	RADIUSStatus  bool          // Send a RADIUS Status-Server request.
+	GRPCService   string        // The service to query with a gRPC health check.
+	MySQLUser     string        // The MySQL user to log in as.
+	MySQLPass     string        // The MySQL password.
+	MySQLQuery    string        // A query to run once logged in to MySQL.
+	MySQLWritable bool          // Fail if the MySQL server is read-only.
+	PGUser        string        // The PostgreSQL user to log in as.
+	PGPass        string        // The PostgreSQL password.
+	PGDatabase    string        // The PostgreSQL database to connect to.
+	PGSSLMode     string        // The PostgreSQL TLS mode, e.g. "require".
+	PGPrimary     bool          // Fail if the PostgreSQL server is in recovery.
+	RedisUser     string        // The Redis ACL user name.
+	RedisPass     string        // The Redis password.
+	RedisRole     string        // The required Redis role, "master" or "replica".
+	MemcBinary    bool          // Use the memcache binary protocol.
+	MemcStatKey   string        // A memcache statistic that must be present.
+	MemcStatValue string        // The required value of MemcStatKey.
+	MemcKeyPrefix string        // Key prefix for a memcache set/get round trip.
+	SMTPHelo      string        // The hostname sent in SMTP EHLO.
+	SMTPStartTLS  bool          // Upgrade SMTP connections with STARTTLS.
+	SMTPCap       string        // An extension the SMTP server must advertise.
+	LDAPBindDN    string        // The DN for an LDAP simple bind.
+	LDAPPass      string        // The password for an LDAP simple bind.
+	LDAPSearchDN  string        // A DN that an LDAP base scope search must return.
+	LDAPStartTLS  bool          // Upgrade LDAP connections with StartTLS.
+	SIPOverTCP    bool          // Send SIP healthchecks over TCP instead of UDP.
+	NTPMaxStratum int           // The highest NTP stratum accepted.
+	NTPMaxDisp    time.Duration // NTP root dispersion that fails the healthcheck.
+	NTPMaxOffset  time.Duration // NTP clock offset that fails the healthcheck.
+	FTPUser       string        // The FTP user to log in as.
+	FTPPass       string        // The FTP password.
+	FTPCommand    string        // An FTP command to issue once logged in, "STAT" or "CWD".
+	FTPPath       string        // The path argument to FTPCommand.
+	IMAPUser      string        // The IMAP user to log in as.
+	IMAPPass      string        // The IMAP password.
+	IMAPMailbox   string        // An IMAP mailbox to SELECT once logged in.
+	IMAPStartTLS  bool          // Upgrade IMAP connections with STARTTLS.
+	POP3User      string        // The POP3 user to log in as.
+	POP3Pass      string        // The POP3 password.
+	POP3StartTLS  bool          // Upgrade POP3 connections with STLS.
+	ScriptCmd     string        // The script to run, relative to the script directory.
+	ScriptArgs    string        // Arguments to ScriptCmd, one per line.
+	TCPHalfOpen   bool          // Use a half-open (SYN) TCP healthcheck.
+	Headers       string        // Extra HTTP request headers, as sorted "Name: value" lines.
+	ExpectHeaders string        // Required HTTP response headers, as sorted "Name: value" lines.
+	ForbidHeaders string        // Forbidden HTTP response header names, as sorted lines.
+	HeaderRegexp  bool          // Treat ExpectHeaders values as regular expressions.
+	ReceiveRegexp string        // Regular expression the HTTP or TCP response must match.
 }
 
 // NewHealthcheck creates a new, initialised Healthcheck structure.
@@ -317,6 +406,49 @@ func (h *Healthcheck) Key() string {
 	return h.Name
 }
 
+// HeaderMap returns the extra HTTP request headers for a Healthcheck, keyed by
+// header name.
+func (h *Healthcheck) HeaderMap() map[string]string {
+	return headerMap(h.Headers)
+}
+
+// ExpectHeaderMap returns the required HTTP response headers for a
+// Healthcheck, keyed by header name.
+func (h *Healthcheck) ExpectHeaderMap() map[string]string {
+	return headerMap(h.ExpectHeaders)
+}
+
+// ForbidHeaderList returns the forbidden HTTP response header names for a
+// Healthcheck.
+func (h *Healthcheck) ForbidHeaderList() []string {
+	if h.ForbidHeaders == "" {
+		return nil
+	}
+	return strings.Split(h.ForbidHeaders, "\n")
+}
+
+// ScriptArgList returns the script arguments for a Healthcheck.
+func (h *Healthcheck) ScriptArgList() []string {
+	if h.ScriptArgs == "" {
+		return nil
+	}
+	return strings.Split(h.ScriptArgs, "\n")
+}
+
+// headerMap converts "Name: value" lines into a map keyed by header name.
+func headerMap(lines string) map[string]string {
+	if lines == "" {
+		return nil
+	}
+	headers := make(map[string]string)
+	for _, line := range strings.Split(lines, "\n") {
+		if name, value, ok := strings.Cut(line, ":"); ok {
+			headers[name] = strings.TrimSpace(value)
+		}
+	}
+	return headers
+}
+
 // Healthchecks is a list of Healthchecks.
 type Healthchecks []*Healthcheck
 
@@ -343,15 +475,315 @@ func (h Healthchecks) Less(i, j int) bool {
 		return h[i].Method < h[j].Method
 	}
 
+	if h[i].RequestBody != h[j].RequestBody {
+		return h[i].RequestBody < h[j].RequestBody
+	}
+
+	if h[i].ContentType != h[j].ContentType {
+		return h[i].ContentType < h[j].ContentType
+	}
+
 	if h[i].Code != h[j].Code {
 		return h[i].Code < h[j].Code
 	}
 
+	if h[i].Codes != h[j].Codes {
+		return h[i].Codes < h[j].Codes
+	}
+
+	if h[i].ProxyURL != h[j].ProxyURL {
+		return h[i].ProxyURL < h[j].ProxyURL
+	}
+
+	if h[i].MaxRedirects != h[j].MaxRedirects {
+		return h[i].MaxRedirects < h[j].MaxRedirects
+	}
+
 	if h[i].Proxy != h[j].Proxy {
 		// false < true
 		return h[j].Proxy
 	}
 
+	if h[i].DNSAuth != h[j].DNSAuth {
+		// false < true
+		return h[j].DNSAuth
+	}
+
+	if h[i].DNSRcode != h[j].DNSRcode {
+		return h[i].DNSRcode < h[j].DNSRcode
+	}
+
+	if h[i].DNSUDPSize != h[j].DNSUDPSize {
+		return h[i].DNSUDPSize < h[j].DNSUDPSize
+	}
+
+	if h[i].DNSSEC != h[j].DNSSEC {
+		return h[i].DNSSEC < h[j].DNSSEC
+	}
+
+	if h[i].DNSWarnAfter != h[j].DNSWarnAfter {
+		return h[i].DNSWarnAfter < h[j].DNSWarnAfter
+	}
+
+	if h[i].DNSFailAfter != h[j].DNSFailAfter {
+		return h[i].DNSFailAfter < h[j].DNSFailAfter
+	}
+
+	if h[i].DNSAttempts != h[j].DNSAttempts {
+		return h[i].DNSAttempts < h[j].DNSAttempts
+	}
+
+	if h[i].DNSStrictID != h[j].DNSStrictID {
+		// false < true
+		return h[j].DNSStrictID
+	}
+
+	if h[i].DNSRandCase != h[j].DNSRandCase {
+		// false < true
+		return h[j].DNSRandCase
+	}
+
+	if h[i].RADIUSUser != h[j].RADIUSUser {
+		return h[i].RADIUSUser < h[j].RADIUSUser
+	}
+
+	if h[i].RADIUSPass != h[j].RADIUSPass {
+		return h[i].RADIUSPass < h[j].RADIUSPass
+	}
+
+	if h[i].RADIUSSecret != h[j].RADIUSSecret {
+		return h[i].RADIUSSecret < h[j].RADIUSSecret
+	}
+
+	if h[i].RADIUSStatus != h[j].RADIUSStatus {
+		// false < true
+		return h[j].RADIUSStatus
+	}
+
+	if h[i].GRPCService != h[j].GRPCService {
+		return h[i].GRPCService < h[j].GRPCService
+	}
+
+	if h[i].MySQLUser != h[j].MySQLUser {
+		return h[i].MySQLUser < h[j].MySQLUser
+	}
+
+	if h[i].MySQLPass != h[j].MySQLPass {
+		return h[i].MySQLPass < h[j].MySQLPass
+	}
+
+	if h[i].MySQLQuery != h[j].MySQLQuery {
+		return h[i].MySQLQuery < h[j].MySQLQuery
+	}
+
+	if h[i].MySQLWritable != h[j].MySQLWritable {
+		// false < true
+		return h[j].MySQLWritable
+	}
+
+	if h[i].PGUser != h[j].PGUser {
+		return h[i].PGUser < h[j].PGUser
+	}
+
+	if h[i].PGPass != h[j].PGPass {
+		return h[i].PGPass < h[j].PGPass
+	}
+
+	if h[i].PGDatabase != h[j].PGDatabase {
+		return h[i].PGDatabase < h[j].PGDatabase
+	}
+
+	if h[i].PGSSLMode != h[j].PGSSLMode {
+		return h[i].PGSSLMode < h[j].PGSSLMode
+	}
+
+	if h[i].PGPrimary != h[j].PGPrimary {
+		// false < true
+		return h[j].PGPrimary
+	}
+
+	if h[i].RedisUser != h[j].RedisUser {
+		return h[i].RedisUser < h[j].RedisUser
+	}
+
+	if h[i].RedisPass != h[j].RedisPass {
+		return h[i].RedisPass < h[j].RedisPass
+	}
+
+	if h[i].RedisRole != h[j].RedisRole {
+		return h[i].RedisRole < h[j].RedisRole
+	}
+
+	if h[i].MemcBinary != h[j].MemcBinary {
+		// false < true
+		return h[j].MemcBinary
+	}
+
+	if h[i].MemcStatKey != h[j].MemcStatKey {
+		return h[i].MemcStatKey < h[j].MemcStatKey
+	}
+
+	if h[i].MemcStatValue != h[j].MemcStatValue {
+		return h[i].MemcStatValue < h[j].MemcStatValue
+	}
+
+	if h[i].MemcKeyPrefix != h[j].MemcKeyPrefix {
+		return h[i].MemcKeyPrefix < h[j].MemcKeyPrefix
+	}
+
+	if h[i].SMTPHelo != h[j].SMTPHelo {
+		return h[i].SMTPHelo < h[j].SMTPHelo
+	}
+
+	if h[i].SMTPStartTLS != h[j].SMTPStartTLS {
+		// false < true
+		return h[j].SMTPStartTLS
+	}
+
+	if h[i].SMTPCap != h[j].SMTPCap {
+		return h[i].SMTPCap < h[j].SMTPCap
+	}
+
+	if h[i].LDAPBindDN != h[j].LDAPBindDN {
+		return h[i].LDAPBindDN < h[j].LDAPBindDN
+	}
+
+	if h[i].LDAPPass != h[j].LDAPPass {
+		return h[i].LDAPPass < h[j].LDAPPass
+	}
+
+	if h[i].LDAPSearchDN != h[j].LDAPSearchDN {
+		return h[i].LDAPSearchDN < h[j].LDAPSearchDN
+	}
+
+	if h[i].LDAPStartTLS != h[j].LDAPStartTLS {
+		// false < true
+		return h[j].LDAPStartTLS
+	}
+
+	if h[i].SIPOverTCP != h[j].SIPOverTCP {
+		// false < true
+		return h[j].SIPOverTCP
+	}
+
+	if h[i].NTPMaxStratum != h[j].NTPMaxStratum {
+		return h[i].NTPMaxStratum < h[j].NTPMaxStratum
+	}
+
+	if h[i].NTPMaxDisp != h[j].NTPMaxDisp {
+		return h[i].NTPMaxDisp < h[j].NTPMaxDisp
+	}
+
+	if h[i].NTPMaxOffset != h[j].NTPMaxOffset {
+		return h[i].NTPMaxOffset < h[j].NTPMaxOffset
+	}
+
+	if h[i].FTPUser != h[j].FTPUser {
+		return h[i].FTPUser < h[j].FTPUser
+	}
+
+	if h[i].FTPPass != h[j].FTPPass {
+		return h[i].FTPPass < h[j].FTPPass
+	}
+
+	if h[i].FTPCommand != h[j].FTPCommand {
+		return h[i].FTPCommand < h[j].FTPCommand
+	}
+
+	if h[i].FTPPath != h[j].FTPPath {
+		return h[i].FTPPath < h[j].FTPPath
+	}
+
+	if h[i].IMAPUser != h[j].IMAPUser {
+		return h[i].IMAPUser < h[j].IMAPUser
+	}
+
+	if h[i].IMAPPass != h[j].IMAPPass {
+		return h[i].IMAPPass < h[j].IMAPPass
+	}
+
+	if h[i].IMAPMailbox != h[j].IMAPMailbox {
+		return h[i].IMAPMailbox < h[j].IMAPMailbox
+	}
+
+	if h[i].IMAPStartTLS != h[j].IMAPStartTLS {
+		// false < true
+		return h[j].IMAPStartTLS
+	}
+
+	if h[i].POP3User != h[j].POP3User {
+		return h[i].POP3User < h[j].POP3User
+	}
+
+	if h[i].POP3Pass != h[j].POP3Pass {
+		return h[i].POP3Pass < h[j].POP3Pass
+	}
+
+	if h[i].POP3StartTLS != h[j].POP3StartTLS {
+		// false < true
+		return h[j].POP3StartTLS
+	}
+
+	if h[i].ScriptCmd != h[j].ScriptCmd {
+		return h[i].ScriptCmd < h[j].ScriptCmd
+	}
+
+	if h[i].ScriptArgs != h[j].ScriptArgs {
+		return h[i].ScriptArgs < h[j].ScriptArgs
+	}
+
+	if h[i].TCPHalfOpen != h[j].TCPHalfOpen {
+		// false < true
+		return h[j].TCPHalfOpen
+	}
+
+	if h[i].ReceiveRegexp != h[j].ReceiveRegexp {
+		return h[i].ReceiveRegexp < h[j].ReceiveRegexp
+	}
+
+	if h[i].Headers != h[j].Headers {
+		return h[i].Headers < h[j].Headers
+	}
+
+	if h[i].ExpectHeaders != h[j].ExpectHeaders {
+		return h[i].ExpectHeaders < h[j].ExpectHeaders
+	}
+
+	if h[i].ForbidHeaders != h[j].ForbidHeaders {
+		return h[i].ForbidHeaders < h[j].ForbidHeaders
+	}
+
+	if h[i].HeaderRegexp != h[j].HeaderRegexp {
+		// false < true
+		return h[j].HeaderRegexp
+	}
+
+	if h[i].TLSCertFile != h[j].TLSCertFile {
+		return h[i].TLSCertFile < h[j].TLSCertFile
+	}
+
+	if h[i].TLSKeyFile != h[j].TLSKeyFile {
+		return h[i].TLSKeyFile < h[j].TLSKeyFile
+	}
+
+	if h[i].TLSCAFile != h[j].TLSCAFile {
+		return h[i].TLSCAFile < h[j].TLSCAFile
+	}
+
+	if h[i].TLSServerName != h[j].TLSServerName {
+		return h[i].TLSServerName < h[j].TLSServerName
+	}
+
+	if h[i].TLSResume != h[j].TLSResume {
+		// false < true
+		return h[j].TLSResume
+	}
+
+	if h[i].ReuseConns != h[j].ReuseConns {
+		// false < true
+		return h[j].ReuseConns
+	}
+
 	if h[i].TLSVerify != h[j].TLSVerify {
 		// false < true
 		return h[j].TLSVerify
@@ -365,5 +797,27 @@ func (h Healthchecks) Less(i, j int) bool {
 		return h[i].Timeout < h[j].Timeout
 	}
 
+	if h[i].MaxLatency != h[j].MaxLatency {
+		return h[i].MaxLatency < h[j].MaxLatency
+	}
+
+	if h[i].Rise != h[j].Rise {
+		return h[i].Rise < h[j].Rise
+	}
+
+	if h[i].Fall != h[j].Fall {
+		return h[i].Fall < h[j].Fall
+	}
+
+	if h[i].Passive != h[j].Passive {
+		// false < true
+		return h[j].Passive
+	}
+
+	if h[i].PassiveHold != h[j].PassiveHold {
+		// false < true
+		return h[j].PassiveHold
+	}
+
 	return false
 }
@@ -29,12 +29,14 @@ import (
 	"net"
 	"net/rpc"
 	"os"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/google/seesaw/common/seesaw"
 	"github.com/google/seesaw/common/server"
 	"github.com/google/seesaw/engine/config"
+	"github.com/google/seesaw/ipvs"
 	ncclient "github.com/google/seesaw/ncc/client"
 	ncctypes "github.com/google/seesaw/ncc/types"
 	spb "github.com/google/seesaw/pb/seesaw"
@@ -54,12 +56,16 @@ type Engine struct {
 
 	fwmAlloc *markAllocator
 
-	bgpManager *bgpManager
-	haManager  *haManager
-	hcManager  *healthcheckManager
+	bgpManager   *bgpManager
+	haManager    *haManager
+	hcManager    *healthcheckManager
+	hcWatchdog   *healthcheckWatchdog
+	reconciler   *reconciler
+	routeManager *routeManager
 
 	ncc         ncclient.NCC
 	lbInterface ncclient.LBInterface
+	ipvsCaps    *ipvs.Capabilities
 
 	cluster     *config.Cluster
 	clusterLock sync.RWMutex
@@ -72,8 +78,13 @@ type Engine struct {
 	syncClient *syncClient
 	syncServer *syncServer
 
-	overrides    map[string]seesaw.Override
-	overrideChan chan seesaw.Override
+	overrides     map[string]seesaw.Override
+	overrideChan  chan seesaw.Override
+	overridesLock sync.RWMutex
+
+	syncSnapshotChan chan *SyncSnapshot
+
+	reconcileChan chan *reconcileRequest
 
 	vlans    map[uint16]*seesaw.VLAN
 	vlanLock sync.RWMutex
@@ -115,6 +126,10 @@ func newEngineWithNCC(cfg *config.EngineConfig, ncc ncclient.NCC) *Engine {
 		overrides:    make(map[string]seesaw.Override),
 		overrideChan: make(chan seesaw.Override),
 
+		syncSnapshotChan: make(chan *SyncSnapshot, 1),
+
+		reconcileChan: make(chan *reconcileRequest),
+
 		vlans:    make(map[uint16]*seesaw.VLAN),
 		vservers: make(map[string]*vserver),
 
@@ -131,6 +146,9 @@ func newEngineWithNCC(cfg *config.EngineConfig, ncc ncclient.NCC) *Engine {
 	engine.bgpManager = newBGPManager(engine, cfg.BGPUpdateInterval)
 	engine.haManager = newHAManager(engine, cfg.HAStateTimeout)
 	engine.hcManager = newHealthcheckManager(engine)
+	engine.hcWatchdog = newHealthcheckWatchdog(engine)
+	engine.reconciler = newReconciler(engine)
+	engine.routeManager = newRouteManager(engine, &nccRouteAdvertiser{ncc})
 	engine.syncClient = newSyncClient(engine)
 	engine.syncServer = newSyncServer(engine)
 	return engine
@@ -145,6 +163,13 @@ func NewEngine(cfg *config.EngineConfig) *Engine {
 	return newEngineWithNCC(cfg, ncc)
 }
 
+// SetRouteAdvertiser replaces the RouteAdvertiser used to advertise the
+// configured routes while the engine is the HA leader. It must be called
+// before the engine is started.
+func (e *Engine) SetRouteAdvertiser(advertiser RouteAdvertiser) {
+	e.routeManager.advertiser = advertiser
+}
+
 // Run starts the Engine.
 func (e *Engine) Run() {
 	log.Infof("Seesaw Engine starting for %s", e.config.ClusterName)
@@ -161,6 +186,9 @@ func (e *Engine) Run() {
 		go e.bgpManager.run()
 	}
 	go e.hcManager.run()
+	go e.hcWatchdog.run()
+	go e.reconciler.run()
+	go e.routeManager.run()
 
 	go e.syncClient.run()
 	go e.syncServer.run()
@@ -339,6 +367,22 @@ func (e *Engine) initNetwork() {
 	if err := e.ncc.IPVSFlush(); err != nil {
 		log.Fatalf("Failed to flush IPVS table: %v", err)
 	}
+	if e.config.IPVSTimeouts != (ipvs.Timeouts{}) {
+		if err := e.ncc.IPVSSetTimeouts(&e.config.IPVSTimeouts); err != nil {
+			log.Fatalf("Failed to set IPVS timeouts: %v", err)
+		}
+	}
+	if e.config.IPVSSyncInterface != "" {
+		if err := e.ncc.IPVSStopSyncDaemon(); err != nil {
+			log.Warningf("Failed to stop IPVS sync daemon: %v", err)
+		}
+	}
+	if caps, err := e.ncc.IPVSCapabilities(); err != nil {
+		log.Warningf("Failed to get IPVS capabilities: %v", err)
+	} else {
+		log.Infof("IPVS capabilities: %v", caps)
+		e.ipvsCaps = caps
+	}
 
 	lbCfg := &ncctypes.LBConfig{
 		ClusterVIP:     e.config.ClusterVIP,
@@ -444,7 +488,6 @@ func (e *Engine) manager() {
 
 		case n := <-e.notifier.C:
 			log.Infof("Received cluster config notification; %v", &n)
-			e.syncServer.notify(&SyncNote{Type: SNTConfigUpdate, Time: time.Now()})
 
 			vua, err := newVserverUserAccess(n.Cluster)
 			if err != nil {
@@ -452,10 +495,16 @@ func (e *Engine) manager() {
 				return
 			}
 
+			checkCapabilities(n.Cluster, e.ipvsCaps)
+
 			e.clusterLock.Lock()
 			e.cluster = n.Cluster
 			e.clusterLock.Unlock()
 
+			// Notify only once the configuration is in place, so that a
+			// snapshot sequenced after this note includes it.
+			e.syncServer.notify(&SyncNote{Type: SNTConfigUpdate, Time: time.Now()})
+
 			e.vserverAccess.update(vua)
 
 			if n.MetadataOnly {
@@ -502,6 +551,7 @@ func (e *Engine) manager() {
 			e.vserverLock.Lock()
 			e.vserverSnapshots[svs.Name] = svs
 			e.vserverLock.Unlock()
+			e.routeManager.triggerUpdate()
 
 		case override := <-e.overrideChan:
 			sn := &SyncNote{Type: SNTOverride, Time: time.Now()}
@@ -512,9 +562,17 @@ func (e *Engine) manager() {
 				sn.DestinationOverride = o
 			case *seesaw.VserverOverride:
 				sn.VserverOverride = o
+			case *seesaw.PassiveOverride:
+				sn.PassiveOverride = o
 			}
-			e.syncServer.notify(sn)
 			e.handleOverride(override)
+			e.syncServer.notify(sn)
+
+		case ss := <-e.syncSnapshotChan:
+			e.applySyncSnapshot(ss)
+
+		case req := <-e.reconcileChan:
+			e.distributeReconcile(req)
 
 		case <-e.shutdown:
 			log.Info("Shutting down engine...")
@@ -527,6 +585,12 @@ func (e *Engine) manager() {
 			<-e.shutdownRPC
 
 			e.syncClient.disable()
+			e.routeManager.withdrawAll()
+			if e.config.IPVSSyncInterface != "" {
+				if err := e.ncc.IPVSStopSyncDaemon(); err != nil {
+					log.Warningf("Failed to stop IPVS sync daemon: %v", err)
+				}
+			}
 			e.shutdownVservers()
 			e.hcManager.shutdown()
 			e.deleteVLANs()
@@ -538,6 +602,47 @@ func (e *Engine) manager() {
 	}
 }
 
+// checkCapabilities removes vserver entries that require IPVS features that
+// are not supported by the running kernel, recording a warning for each.
+// Mixed address families are disabled for vservers if the kernel lacks
+// support for them.
+func checkCapabilities(cluster *config.Cluster, caps *ipvs.Capabilities) {
+	if caps == nil {
+		return
+	}
+	for _, v := range cluster.Vservers {
+		if v.MixedFamilies && !caps.MixedFamilies() {
+			warning := fmt.Sprintf("mixed address families are not supported by kernel %s, disabled", caps.KernelRelease)
+			log.Errorf("%v: %s", v.Name, warning)
+			v.Warnings = append(v.Warnings, warning)
+			v.MixedFamilies = false
+		}
+		keys := make([]string, 0, len(v.Entries))
+		for key := range v.Entries {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			var missing string
+			ve := v.Entries[key]
+			switch {
+			case ve.Scheduler != seesaw.LBSchedulerNone && !caps.HasScheduler(ve.Scheduler.String()):
+				missing = fmt.Sprintf("scheduler %v (ip_vs_%v module)", ve.Scheduler, ve.Scheduler)
+			case ve.Mode == seesaw.LBModeTUN && !caps.HasTunnel(ve.TunnelType):
+				missing = fmt.Sprintf("%v tunnels", ve.TunnelType)
+			case ve.OnePacket && !caps.OnePacket():
+				missing = "one-packet scheduling"
+			default:
+				continue
+			}
+			warning := fmt.Sprintf("%s requires %s, which is not supported by kernel %s", key, missing, caps.KernelRelease)
+			log.Errorf("%v: %s", v.Name, warning)
+			v.Warnings = append(v.Warnings, warning)
+			delete(v.Entries, key)
+		}
+	}
+}
+
 // updateVservers processes a list of vserver configurations then stops
 // deleted vservers, spawns new vservers and updates the existing vservers.
 func (e *Engine) updateVservers() {
@@ -566,9 +671,11 @@ func (e *Engine) updateVservers() {
 			e.vservers[config.Name] = vserver
 		}
 	}
+	e.overridesLock.RLock()
 	for _, override := range e.overrides {
 		e.distributeOverride(override)
 	}
+	e.overridesLock.RUnlock()
 	for _, config := range cluster.Vservers {
 		e.vservers[config.Name].updateConfig(config)
 	}
@@ -705,22 +812,28 @@ func (e *Engine) deleteVLANs() {
 
 // handleOverride handles an incoming Override.
 func (e *Engine) handleOverride(o seesaw.Override) {
+	e.overridesLock.Lock()
 	e.overrides[o.Target()] = o
 	e.distributeOverride(o)
 	if o.State() == seesaw.OverrideDefault {
 		delete(e.overrides, o.Target())
 	}
+	e.overridesLock.Unlock()
 }
 
 // distributeOverride distributes an Override to the appropriate vservers.
 func (e *Engine) distributeOverride(o seesaw.Override) {
-	// Send VserverOverrides and DestinationOverrides to the appropriate vserver.
-	// Send BackendOverrides to all vservers.
+	// Send VserverOverrides, DestinationOverrides and PassiveOverrides to the
+	// appropriate vserver. Send BackendOverrides to all vservers.
 	switch override := o.(type) {
 	case *seesaw.VserverOverride:
 		if vserver, ok := e.vservers[override.VserverName]; ok {
 			vserver.queueOverride(o)
 		}
+	case *seesaw.PassiveOverride:
+		if vserver, ok := e.vservers[override.VserverName]; ok {
+			vserver.queueOverride(o)
+		}
 	case *seesaw.DestinationOverride:
 		if vserver, ok := e.vservers[override.VserverName]; ok {
 			vserver.queueOverride(o)
@@ -732,6 +845,69 @@ func (e *Engine) distributeOverride(o seesaw.Override) {
 	}
 }
 
+// applySyncSnapshot applies a synchronisation snapshot received from the
+// peer Seesaw node, replacing the current overrides and healthcheck states.
+// If the snapshot contains a different cluster configuration, a reload is
+// requested from the configuration source.
+func (e *Engine) applySyncSnapshot(ss *SyncSnapshot) {
+	log.Infof("Applying sync snapshot at sequence %d (peer %v, config epoch %v, %d healthchecks)",
+		ss.Seq, ss.HAStatus.State, ss.ConfigEpoch, len(ss.Healthchecks))
+
+	e.clusterLock.RLock()
+	cluster := e.cluster
+	e.clusterLock.RUnlock()
+	if ss.Cluster != nil && (cluster == nil || !cluster.Equal(ss.Cluster)) && e.notifier != nil {
+		log.Infof("Sync snapshot contains new config, triggering config reload")
+		if err := e.notifier.Reload(); err != nil {
+			log.Warningf("Config reload after sync snapshot failed: %v", err)
+		}
+	}
+
+	overrides := make(map[string]seesaw.Override)
+	for _, o := range ss.overrides() {
+		overrides[o.Target()] = o
+	}
+	e.overridesLock.RLock()
+	var stale []seesaw.Override
+	for target, o := range e.overrides {
+		if _, ok := overrides[target]; !ok {
+			stale = append(stale, o)
+		}
+	}
+	e.overridesLock.RUnlock()
+	for _, o := range stale {
+		e.handleOverride(defaultOverride(o))
+	}
+	for _, o := range overrides {
+		e.handleOverride(o)
+	}
+
+	e.hcManager.applySyncStates(ss.Healthchecks)
+}
+
+// defaultOverride returns a copy of the given Override with the default state.
+func defaultOverride(o seesaw.Override) seesaw.Override {
+	switch override := o.(type) {
+	case *seesaw.BackendOverride:
+		do := *override
+		do.OverrideState = seesaw.OverrideDefault
+		return &do
+	case *seesaw.DestinationOverride:
+		do := *override
+		do.OverrideState = seesaw.OverrideDefault
+		return &do
+	case *seesaw.VserverOverride:
+		do := *override
+		do.OverrideState = seesaw.OverrideDefault
+		return &do
+	case *seesaw.PassiveOverride:
+		do := *override
+		do.OverrideState = seesaw.OverrideDefault
+		return &do
+	}
+	return o
+}
+
 // becomeMaster performs the necessary actions for the Seesaw Engine to
 // become the master node.
 func (e *Engine) becomeMaster() {
@@ -741,17 +917,42 @@ func (e *Engine) becomeMaster() {
 	if err := e.lbInterface.Up(); err != nil {
 		log.Fatalf("Failed to bring LB interface up: %v", err)
 	}
+	e.routeManager.setLeader(true)
+	e.updateSyncDaemon(ipvs.SyncStateMaster)
 }
 
 // becomeBackup performs the neccesary actions for the Seesaw Engine to
 // stop being the master node and become the backup node.
 func (e *Engine) becomeBackup() {
+	e.routeManager.setLeader(false)
 	e.syncClient.enable()
 	e.notifier.SetSource(config.SourceServer)
 
 	if err := e.lbInterface.Down(); err != nil {
 		log.Fatalf("Failed to bring LB interface down: %v", err)
 	}
+	e.updateSyncDaemon(ipvs.SyncStateBackup)
+}
+
+// updateSyncDaemon switches the IPVS connection synchronisation daemon to the
+// given state, if it is configured. Failures are logged rather than blocking
+// the HA state transition, since connections are still served without it.
+func (e *Engine) updateSyncDaemon(state ipvs.SyncState) {
+	if e.config.IPVSSyncInterface == "" {
+		return
+	}
+	if err := e.ncc.IPVSStopSyncDaemon(); err != nil {
+		log.Warningf("Failed to stop IPVS sync daemon: %v", err)
+	}
+	d := &ipvs.SyncDaemon{
+		State:     state,
+		Interface: e.config.IPVSSyncInterface,
+		SyncID:    e.config.IPVSSyncID,
+	}
+	log.Infof("Starting IPVS %v", d)
+	if err := e.ncc.IPVSStartSyncDaemon(d); err != nil {
+		log.Warningf("Failed to start IPVS %v: %v", d, err)
+	}
 }
 
 // markAllocator handles the allocation of marks.
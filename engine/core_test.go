@@ -0,0 +1,165 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/google/seesaw/common/seesaw"
+	"github.com/google/seesaw/engine/config"
+	"github.com/google/seesaw/ipvs"
+	ncclient "github.com/google/seesaw/ncc/client"
+)
+
+func TestCheckCapabilities(t *testing.T) {
+	newCluster := func() *config.Cluster {
+		v := config.NewVserver("web.frontend@au-syd", vserverHost)
+		v.MixedFamilies = true
+		for _, e := range []*config.VserverEntry{
+			{Port: 80, Proto: seesaw.IPProtoTCP, Scheduler: seesaw.LBSchedulerWRR, Mode: seesaw.LBModeDSR},
+			{Port: 443, Proto: seesaw.IPProtoTCP, Scheduler: seesaw.LBSchedulerMH, Mode: seesaw.LBModeDSR},
+			{Port: 8080, Proto: seesaw.IPProtoTCP, Scheduler: seesaw.LBSchedulerWRR, Mode: seesaw.LBModeTUN, TunnelType: ipvs.TunnelGRE},
+			{Port: 53, Proto: seesaw.IPProtoUDP, Scheduler: seesaw.LBSchedulerWRR, Mode: seesaw.LBModeDSR, OnePacket: true},
+		} {
+			v.Entries[e.Key()] = e
+		}
+		cluster := config.NewCluster("au-syd")
+		cluster.Vservers[v.Name] = v
+		return cluster
+	}
+
+	tests := []struct {
+		desc         string
+		caps         *ipvs.Capabilities
+		wantEntries  []string
+		wantMixed    bool
+		wantWarnings []string
+	}{
+		{
+			desc:        "capabilities unavailable",
+			wantEntries: []string{"443/TCP", "53/UDP", "80/TCP", "8080/TCP"},
+			wantMixed:   true,
+		},
+		{
+			desc:        "support unknown",
+			caps:        &ipvs.Capabilities{},
+			wantEntries: []string{"443/TCP", "53/UDP", "80/TCP", "8080/TCP"},
+			wantMixed:   true,
+		},
+		{
+			desc:        "missing mixed address family support",
+			caps:        &ipvs.Capabilities{KernelRelease: "3.16.0"},
+			wantEntries: []string{"443/TCP", "53/UDP", "80/TCP", "8080/TCP"},
+			wantWarnings: []string{
+				"mixed address families are not supported by kernel 3.16.0, disabled",
+			},
+		},
+		{
+			desc:        "missing one-packet scheduling",
+			caps:        &ipvs.Capabilities{KernelRelease: "3.2.0"},
+			wantEntries: []string{"443/TCP", "80/TCP", "8080/TCP"},
+			wantWarnings: []string{
+				"mixed address families are not supported by kernel 3.2.0, disabled",
+				"53/UDP requires one-packet scheduling, which is not supported by kernel 3.2.0",
+			},
+		},
+		{
+			desc: "missing scheduler and tunnel",
+			caps: &ipvs.Capabilities{
+				KernelRelease: "5.2.0",
+				Schedulers:    []string{"rr", "wrr"},
+				Tunnels:       []ipvs.TunnelType{ipvs.TunnelIPIP, ipvs.TunnelGUE},
+			},
+			wantEntries: []string{"53/UDP", "80/TCP"},
+			wantMixed:   true,
+			wantWarnings: []string{
+				"443/TCP requires scheduler mh (ip_vs_mh module), which is not supported by kernel 5.2.0",
+				"8080/TCP requires gre tunnels, which is not supported by kernel 5.2.0",
+			},
+		},
+	}
+	for _, test := range tests {
+		cluster := newCluster()
+		checkCapabilities(cluster, test.caps)
+		v := cluster.Vservers["web.frontend@au-syd"]
+		var entries []string
+		for key := range v.Entries {
+			entries = append(entries, key)
+		}
+		sort.Strings(entries)
+		if !reflect.DeepEqual(entries, test.wantEntries) {
+			t.Errorf("%s: got entries %q, want %q", test.desc, entries, test.wantEntries)
+		}
+		if v.MixedFamilies != test.wantMixed {
+			t.Errorf("%s: got mixed families %v, want %v", test.desc, v.MixedFamilies, test.wantMixed)
+		}
+		if len(v.Warnings) != 0 || len(test.wantWarnings) != 0 {
+			if !reflect.DeepEqual(v.Warnings, test.wantWarnings) {
+				t.Errorf("%s: got warnings %q, want %q", test.desc, v.Warnings, test.wantWarnings)
+			}
+		}
+	}
+}
+
+// syncDaemonNCC is an NCC that records IPVS sync daemon operations.
+type syncDaemonNCC struct {
+	ncclient.NCC
+	ops      []string
+	startErr error
+}
+
+func (nc *syncDaemonNCC) IPVSStartSyncDaemon(d *ipvs.SyncDaemon) error {
+	nc.ops = append(nc.ops, fmt.Sprintf("start %v", d))
+	return nc.startErr
+}
+
+func (nc *syncDaemonNCC) IPVSStopSyncDaemon() error {
+	nc.ops = append(nc.ops, "stop")
+	return nil
+}
+
+func TestUpdateSyncDaemon(t *testing.T) {
+	e := newTestEngine()
+	nc := &syncDaemonNCC{NCC: e.ncc}
+	e.ncc = nc
+
+	// The sync daemon is not managed unless an interface is configured.
+	e.updateSyncDaemon(ipvs.SyncStateMaster)
+	if len(nc.ops) != 0 {
+		t.Errorf("Got sync daemon operations %q without an interface, want none", nc.ops)
+	}
+
+	e.config.IPVSSyncInterface = "eth1"
+	e.config.IPVSSyncID = 7
+	e.updateSyncDaemon(ipvs.SyncStateMaster)
+	e.updateSyncDaemon(ipvs.SyncStateBackup)
+
+	// A failure to start the daemon does not prevent the transition.
+	nc.startErr = errors.New("failed")
+	e.updateSyncDaemon(ipvs.SyncStateMaster)
+
+	want := []string{
+		"stop", "start master sync daemon on eth1 (syncid 7)",
+		"stop", "start backup sync daemon on eth1 (syncid 7)",
+		"stop", "start master sync daemon on eth1 (syncid 7)",
+	}
+	if !reflect.DeepEqual(nc.ops, want) {
+		t.Errorf("Got sync daemon operations %q, want %q", nc.ops, want)
+	}
+}
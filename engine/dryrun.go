@@ -0,0 +1,82 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+// This file implements dry runs, which program the IPVS services for a
+// cluster configuration into an in-memory IPVS table rather than the kernel.
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/seesaw/engine/config"
+	"github.com/google/seesaw/ipvs"
+	ncclient "github.com/google/seesaw/ncc/client"
+)
+
+// NewDryRunEngine returns an Engine that applies IPVS operations to the given
+// in-memory backend, rather than communicating with the NCC.
+func NewDryRunEngine(cfg *config.EngineConfig, backend *ipvs.DummyBackend) *Engine {
+	e := newEngineWithNCC(cfg, ncclient.NewIPVSDummyNCC(backend))
+	e.lbInterface = ncclient.NewDummyLBInterface()
+	return e
+}
+
+// DryRun programs the IPVS services for each vserver in the given cluster
+// configuration, as though all of the enabled backends were healthy. An error
+// is returned describing the services that could not be programmed.
+func (e *Engine) DryRun(cluster *config.Cluster) error {
+	names := make([]string, 0, len(cluster.Vservers))
+	for name := range cluster.Vservers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []string
+	for _, name := range names {
+		v := newVserver(e)
+		v.config = cluster.Vservers[name]
+		if !v.config.Enabled {
+			continue
+		}
+		svcs := v.expandServices()
+		keys := make([]serviceKey, 0, len(svcs))
+		for key := range svcs {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return svcs[keys[i]].String() < svcs[keys[j]].String()
+		})
+		for _, key := range keys {
+			svc := svcs[key]
+			txn := ipvs.Begin()
+			txn.AddService(*svc.ipvsSvc)
+			for _, d := range v.expandDests(svc) {
+				if d.backend.Enabled {
+					txn.AddDestination(*svc.ipvsSvc, *d.ipvsDst)
+				}
+			}
+			if err := v.ncc.IPVSApply(txn); err != nil {
+				errs = append(errs, fmt.Sprintf("%v: %v", v, err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/google/seesaw/common/seesaw"
+	"github.com/google/seesaw/engine/config"
+	"github.com/google/seesaw/ipvs"
+)
+
+func TestDryRun(t *testing.T) {
+	backend := ipvs.NewDummyBackend()
+	e := NewDryRunEngine(newTestEngine().config, backend)
+
+	// backend3 is disabled, so is not programmed.
+	backend3 := newTestBackend(3)
+	backend3.Enabled = false
+	vsConfig := vserverConfig
+	vsConfig.Backends = map[string]*seesaw.Backend{
+		backend1.Hostname: backend1,
+		backend2.Hostname: backend2,
+		backend3.Hostname: backend3,
+	}
+	disabled := vserverConfig
+	disabled.Name = "disabled.resolver@au-syd"
+	disabled.Enabled = false
+
+	cluster := config.NewCluster("au-syd")
+	cluster.Vservers[vsConfig.Name] = &vsConfig
+	cluster.Vservers[disabled.Name] = &disabled
+	if err := e.DryRun(cluster); err != nil {
+		t.Fatalf("DryRun failed: %v", err)
+	}
+
+	svcs := backend.Snapshot()
+	if len(svcs) != len(expectedServices) {
+		t.Fatalf("Got %d IPVS services, want %d", len(svcs), len(expectedServices))
+	}
+	for _, svc := range svcs {
+		if len(svc.Destinations) != 2 {
+			t.Errorf("Got %d IPVS destinations for %v, want 2", len(svc.Destinations), svc)
+		}
+	}
+
+	// Services that are already programmed cannot be added again.
+	if err := e.DryRun(cluster); err == nil {
+		t.Error("DryRun succeeded with existing services, want error")
+	}
+}
@@ -144,6 +144,7 @@ func (h *haManager) setStatus(s seesaw.HAStatus) {
 	h.status.Sent = s.Sent
 	h.status.Received = s.Received
 	h.status.Transitions = s.Transitions
+	h.status.PreemptIn = s.PreemptIn
 	h.statusLock.Unlock()
 }
 
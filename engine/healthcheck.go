@@ -23,10 +23,14 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/rpc"
 	"os"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/google/seesaw/common/ipc"
 	"github.com/google/seesaw/common/seesaw"
 	"github.com/google/seesaw/engine/config"
 	"github.com/google/seesaw/healthcheck"
@@ -41,6 +45,12 @@ const (
 
 	dsrMarkBase = 1 << 16
 	dsrMarkSize = 16000
+
+	healthcheckTimeout = 5 * time.Second
+
+	// healthcheckMaxWait is the maximum time that the healthcheck component
+	// may wait for the healthcheck configurations to change.
+	healthcheckMaxWait = 1 * time.Minute
 )
 
 // checkerKey is the unique key of the health checker.
@@ -65,11 +75,17 @@ type healthcheckManager struct {
 	next          healthcheck.Id
 	vserverChecks map[string]map[CheckKey]*check // keyed by vserver name
 
-	cfgs    map[healthcheck.Id]*healthcheck.Config
-	checks  map[healthcheck.Id][]*check
-	ids     map[checkerKey]healthcheck.Id
-	enabled bool
-	lock    sync.RWMutex // Guards cfgs, checks, enabled and ids.
+	cfgs      map[healthcheck.Id]*healthcheck.Config
+	checks    map[healthcheck.Id][]*check
+	dests     map[string][]healthcheck.Id // keyed by destination name
+	ids       map[checkerKey]healthcheck.Id
+	states    map[CheckKey]healthcheck.Status
+	skipped   map[healthcheck.Id]uint64
+	throttled map[healthcheck.Id]uint64
+	enabled   bool
+	version   uint64        // Changes whenever the result of configs changes.
+	changed   chan struct{} // Closed when the version changes.
+	lock      sync.RWMutex  // Guards cfgs, checks, dests, enabled, ids, states, skipped, throttled, version and changed.
 
 	quit    chan bool
 	stopped chan bool
@@ -88,7 +104,13 @@ func newHealthcheckManager(e *Engine) *healthcheckManager {
 		quit:          make(chan bool),
 		stopped:       make(chan bool),
 		vcc:           make(chan vserverChecks, 1000),
+		states:        make(map[CheckKey]healthcheck.Status),
+		skipped:       make(map[healthcheck.Id]uint64),
+		throttled:     make(map[healthcheck.Id]uint64),
 		enabled:       true,
+		// The initial version differs between engine restarts.
+		version: uint64(time.Now().UnixNano()),
+		changed: make(chan struct{}),
 	}
 }
 
@@ -96,12 +118,45 @@ func newHealthcheckManager(e *Engine) *healthcheckManager {
 // map should only be read, not mutated. If the healthcheckManager is disabled,
 // then nil is returned.
 func (h *healthcheckManager) configs() map[healthcheck.Id]*healthcheck.Config {
+	cfgs, _ := h.versionedConfigs()
+	return cfgs
+}
+
+// versionedConfigs returns the healthcheck Configs for a Seesaw Engine, along
+// with their version.
+func (h *healthcheckManager) versionedConfigs() (map[healthcheck.Id]*healthcheck.Config, uint64) {
 	h.lock.RLock()
 	defer h.lock.RUnlock()
 	if !h.enabled {
-		return nil
+		return nil, h.version
+	}
+	return h.cfgs, h.version
+}
+
+// configsSince returns the healthcheck Configs for a Seesaw Engine and their
+// version, once the version differs from the given version or the timeout
+// has passed.
+func (h *healthcheckManager) configsSince(version uint64, timeout time.Duration) (map[healthcheck.Id]*healthcheck.Config, uint64) {
+	h.lock.RLock()
+	current, changed := h.version, h.changed
+	h.lock.RUnlock()
+	if current == version {
+		timer := time.NewTimer(timeout)
+		select {
+		case <-changed:
+		case <-timer.C:
+		}
+		timer.Stop()
 	}
-	return h.cfgs
+	return h.versionedConfigs()
+}
+
+// bumpVersion changes the version of the healthcheck Configs and wakes those
+// that are waiting for a change. The lock must be held by the caller.
+func (h *healthcheckManager) bumpVersion() {
+	h.version++
+	close(h.changed)
+	h.changed = make(chan struct{})
 }
 
 // update updates the healthchecks for a vserver.
@@ -118,14 +173,20 @@ func (h *healthcheckManager) update(vserverName string, checks map[CheckKey]*che
 func (h *healthcheckManager) enable() {
 	h.lock.Lock()
 	defer h.lock.Unlock()
-	h.enabled = true
+	if !h.enabled {
+		h.enabled = true
+		h.bumpVersion()
+	}
 }
 
 // disable disables the healthcheck manager for the Seesaw Engine.
 func (h *healthcheckManager) disable() {
 	h.lock.Lock()
 	defer h.lock.Unlock()
-	h.enabled = false
+	if h.enabled {
+		h.enabled = false
+		h.bumpVersion()
+	}
 }
 
 // shutdown requests the healthcheck manager to shutdown.
@@ -180,15 +241,75 @@ func (h *healthcheckManager) buildMaps() {
 		newChecks[id] = append(newChecks[id], c)
 	}
 
+	newDests := make(map[string][]healthcheck.Id)
+	for vserverName, vchecks := range h.vserverChecks {
+		for k, c := range vchecks {
+			id, ok := newIDs[checkerKey{key: dedup(k), cfg: *c.healthcheck}]
+			if !ok || newCfgs[id] == nil {
+				continue
+			}
+			for _, d := range c.dests {
+				name := fmt.Sprintf("%s/%v", vserverName, d)
+				if !containsID(newDests[name], id) {
+					newDests[name] = append(newDests[name], id)
+				}
+			}
+		}
+	}
+
 	h.lock.Lock()
+	if !sameConfigs(h.cfgs, newCfgs) {
+		h.bumpVersion()
+	}
 	h.ids = newIDs
 	h.cfgs = newCfgs
 	h.checks = newChecks
+	h.dests = newDests
+	for key := range h.states {
+		if allChecks[key] == nil {
+			delete(h.states, key)
+		}
+	}
+	for id := range h.skipped {
+		if newCfgs[id] == nil {
+			delete(h.skipped, id)
+		}
+	}
+	for id := range h.throttled {
+		if newCfgs[id] == nil {
+			delete(h.throttled, id)
+		}
+	}
 	h.lock.Unlock()
 
 	h.pruneMarks()
 }
 
+// sameConfigs returns true if two sets of healthcheck Configs are the same.
+// Configs are only created for new healthcheck Ids, so they are compared by
+// identity.
+func sameConfigs(a, b map[healthcheck.Id]*healthcheck.Config) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for id, cfg := range a {
+		if b[id] != cfg {
+			return false
+		}
+	}
+	return true
+}
+
+// containsID returns true if ids contains the given healthcheck Id.
+func containsID(ids []healthcheck.Id, id healthcheck.Id) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
 // dedup removes service related fields in a CheckKey which doesn't affect how a hc work.
 // Note that for DSR or TUN typed healthcheck, they are needed.
 func dedup(key CheckKey) CheckKey {
@@ -221,6 +342,14 @@ func (h *healthcheckManager) queueHealthState(n *healthcheck.Notification) error
 		return nil
 	}
 
+	h.lock.Lock()
+	for _, check := range checkList {
+		h.states[check.key] = n.Status
+	}
+	h.skipped[n.Id] = n.Status.Skipped
+	h.throttled[n.Id] = n.Status.Throttled
+	h.lock.Unlock()
+
 	for _, check := range checkList {
 		note := &checkNotification{
 			key:         check.key,
@@ -233,6 +362,110 @@ func (h *healthcheckManager) queueHealthState(n *healthcheck.Notification) error
 	return nil
 }
 
+// history returns the recent results of the healthchecks for the named
+// destination, as retained by the healthcheck component.
+func (h *healthcheckManager) history(dest string) ([]*seesaw.HealthcheckHistory, error) {
+	h.lock.RLock()
+	ids, ok := h.dests[dest]
+	names := make(map[healthcheck.Id]string)
+	for _, id := range ids {
+		names[id] = h.cfgs[id].Checker.String()
+	}
+	h.lock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown destination %q", dest)
+	}
+
+	conn, err := net.DialTimeout("unix", h.engine.config.HealthcheckSocket, healthcheckTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("healthcheck component unavailable: %v", err)
+	}
+	conn.SetDeadline(time.Now().Add(healthcheckTimeout))
+	client := rpc.NewClient(conn)
+	defer client.Close()
+
+	var reply healthcheck.History
+	args := &healthcheck.HistoryRequest{Ctx: ipc.NewTrustedContext(seesaw.SCEngine), Ids: ids}
+	if err := client.Call("SeesawHealthcheck.CheckHistory", args, &reply); err != nil {
+		return nil, fmt.Errorf("SeesawHealthcheck.CheckHistory failed: %v", err)
+	}
+
+	var hcs []*seesaw.HealthcheckHistory
+	for _, id := range ids {
+		hcs = append(hcs, &seesaw.HealthcheckHistory{Name: names[id], Results: reply.Results[id]})
+	}
+	sort.Slice(hcs, func(i, j int) bool { return hcs[i].Name < hcs[j].Name })
+	return hcs, nil
+}
+
+// skippedRuns returns the total number of healthcheck runs that have been
+// skipped, since the previous run of the healthcheck was still in progress.
+func (h *healthcheckManager) skippedRuns() uint64 {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	var skipped uint64
+	for _, n := range h.skipped {
+		skipped += n
+	}
+	return skipped
+}
+
+// throttledRuns returns the total number of healthcheck runs that have been
+// skipped, since the concurrency limit did not allow them to start before
+// the next run was due.
+func (h *healthcheckManager) throttledRuns() uint64 {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	var throttled uint64
+	for _, n := range h.throttled {
+		throttled += n
+	}
+	return throttled
+}
+
+// syncStates returns the most recent status of each healthcheck, for
+// inclusion in a synchronisation snapshot.
+func (h *healthcheckManager) syncStates() []*SyncHealthCheckNotification {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	states := make([]*SyncHealthCheckNotification, 0, len(h.states))
+	for key, status := range h.states {
+		states = append(states, &SyncHealthCheckNotification{Key: key, Status: status})
+	}
+	return states
+}
+
+// applySyncStates replaces the healthcheck states with those received in a
+// synchronisation snapshot and notifies the vservers of each known check.
+func (h *healthcheckManager) applySyncStates(states []*SyncHealthCheckNotification) {
+	h.lock.Lock()
+	checks := make(map[CheckKey]*check)
+	descriptions := make(map[CheckKey]string)
+	for id, checkList := range h.checks {
+		for _, c := range checkList {
+			checks[c.key] = c
+			descriptions[c.key] = h.cfgs[id].Checker.String()
+		}
+	}
+	h.states = make(map[CheckKey]healthcheck.Status)
+	for _, s := range states {
+		h.states[s.Key] = s.Status
+	}
+	h.lock.Unlock()
+
+	for _, s := range states {
+		c, ok := checks[s.Key]
+		if !ok {
+			continue
+		}
+		c.vserver.queueCheckNotification(&checkNotification{
+			key:         s.Key,
+			description: descriptions[s.Key],
+			status:      s.Status,
+		})
+	}
+}
+
 // SyncHealthCheckNotification stores a status notification for a healthcheck.
 type SyncHealthCheckNotification struct {
 	Key CheckKey
@@ -274,6 +507,24 @@ func (h *healthcheckManager) newConfig(id healthcheck.Id, key CheckKey, hc *conf
 		dns.Answer = hc.Receive
 		dns.Question.Name = hc.Send
 		dns.Question.Qtype = queryType
+		dns.RequireAuthoritative = hc.DNSAuth
+		dns.UDPSize = uint16(hc.DNSUDPSize)
+		dns.WarnLatency = hc.DNSWarnAfter
+		dns.MaxLatency = hc.DNSFailAfter
+		dns.QueryAttempts = hc.DNSAttempts
+		dns.StrictIDCheck = hc.DNSStrictID
+		dns.Use0x20 = hc.DNSRandCase
+		if hc.DNSSEC != "" {
+			dns.RequireDNSSEC = true
+			if dns.DNSSECMode, err = healthcheck.ParseDNSSECMode(hc.DNSSEC); err != nil {
+				return nil, err
+			}
+		}
+		if hc.DNSRcode != "" {
+			if dns.ExpectedRcode, err = healthcheck.DNSRcode(hc.DNSRcode); err != nil {
+				return nil, err
+			}
+		}
 
 		checker = dns
 	case seesaw.HCTypeHTTP:
@@ -288,15 +539,255 @@ func (h *healthcheckManager) newConfig(id healthcheck.Id, key CheckKey, hc *conf
 		if hc.Code != 0 {
 			http.ResponseCode = hc.Code
 		}
+		if hc.Codes != "" {
+			codes, err := healthcheck.ParseResponseCodes(hc.Codes)
+			if err != nil {
+				return nil, err
+			}
+			http.ResponseCodes = codes
+		}
 		http.Secure = hc.Secure
 		if hc.Secure {
 			http.TLSVerify = hc.TLSVerify
+			http.CertFile = hc.TLSCertFile
+			http.KeyFile = hc.TLSKeyFile
+			http.CACertFile = hc.TLSCAFile
+			http.ServerName = hc.TLSServerName
+			http.ResumeSessions = hc.TLSResume
+			if err := http.LoadCertificates(); err != nil {
+				return nil, err
+			}
 		}
 		http.Proxy = hc.Proxy
+		http.ProxyURL = hc.ProxyURL
+		http.ReuseConnections = hc.ReuseConns
 		if hc.Method != "" {
 			http.Method = hc.Method
 		}
+		if hc.RequestBody != "" {
+			http.RequestBody = []byte(hc.RequestBody)
+		}
+		http.ContentType = hc.ContentType
+		http.Headers = hc.HeaderMap()
+		http.ForbiddenHeaders = hc.ForbidHeaderList()
+		if hc.ExpectHeaders != "" {
+			if err := http.SetExpectedHeaders(hc.ExpectHeaderMap(), hc.HeaderRegexp); err != nil {
+				return nil, err
+			}
+		}
+		if hc.MaxRedirects > 0 {
+			http.FollowRedirects = true
+			http.MaxRedirects = hc.MaxRedirects
+		}
+		if hc.ReceiveRegexp != "" {
+			if err := http.SetResponseRegexp(hc.ReceiveRegexp); err != nil {
+				return nil, err
+			}
+		}
 		checker = http
+	case seesaw.HCTypeGRPC:
+		grpc := healthcheck.NewGRPCChecker(ip, port)
+		target = &grpc.Target
+		grpc.Service = hc.GRPCService
+		grpc.Secure = hc.Secure
+		if hc.Secure {
+			grpc.TLSVerify = hc.TLSVerify
+			grpc.ServerName = hc.TLSServerName
+		}
+		checker = grpc
+	case seesaw.HCTypeMySQL:
+		if hc.MySQLUser == "" && (hc.MySQLQuery != "" || hc.MySQLWritable) {
+			return nil, errors.New("MySQL healthcheck query and expect_writable require a user")
+		}
+		mysql := healthcheck.NewMySQLChecker(ip, port)
+		target = &mysql.Target
+		mysql.User = hc.MySQLUser
+		mysql.Password = hc.MySQLPass
+		mysql.Query = hc.MySQLQuery
+		mysql.ExpectWritable = hc.MySQLWritable
+		checker = mysql
+	case seesaw.HCTypePostgres:
+		if hc.PGUser == "" {
+			return nil, errors.New("PostgreSQL healthcheck requires a user")
+		}
+		pg := healthcheck.NewPostgresChecker(ip, port)
+		target = &pg.Target
+		pg.User = hc.PGUser
+		pg.Password = hc.PGPass
+		pg.Database = hc.PGDatabase
+		switch hc.PGSSLMode {
+		case "disable":
+		case "", "prefer":
+			pg.Secure = true
+		case "require":
+			pg.Secure = true
+			pg.RequireTLS = true
+		default:
+			return nil, fmt.Errorf("unknown PostgreSQL sslmode %q", hc.PGSSLMode)
+		}
+		pg.TLSVerify = hc.TLSVerify
+		pg.ServerName = hc.TLSServerName
+		pg.ExpectPrimary = hc.PGPrimary
+		checker = pg
+	case seesaw.HCTypeRedis:
+		switch hc.RedisRole {
+		case "", "master", "replica":
+		default:
+			return nil, fmt.Errorf("unknown Redis role %q", hc.RedisRole)
+		}
+		if hc.RedisUser != "" && hc.RedisPass == "" {
+			return nil, errors.New("Redis healthcheck username requires a password")
+		}
+		redis := healthcheck.NewRedisChecker(ip, port)
+		target = &redis.Target
+		redis.Username = hc.RedisUser
+		redis.Password = hc.RedisPass
+		redis.RequireRole = hc.RedisRole
+		checker = redis
+	case seesaw.HCTypeMemcache:
+		if hc.MemcBinary && (hc.MemcStatKey != "" || hc.MemcKeyPrefix != "") {
+			return nil, errors.New("memcache binary healthchecks do not support stats or key_prefix")
+		}
+		if hc.MemcKeyPrefix != "" && !healthcheck.ValidMemcacheKeyPrefix(hc.MemcKeyPrefix) {
+			return nil, fmt.Errorf("invalid memcache key prefix %q", hc.MemcKeyPrefix)
+		}
+		memcache := healthcheck.NewMemcacheChecker(ip, port)
+		target = &memcache.Target
+		memcache.Binary = hc.MemcBinary
+		memcache.StatsKey = hc.MemcStatKey
+		memcache.StatsValue = hc.MemcStatValue
+		memcache.KeyPrefix = hc.MemcKeyPrefix
+		checker = memcache
+	case seesaw.HCTypeSMTP:
+		if hc.SMTPHelo != "" && !healthcheck.ValidSMTPHostname(hc.SMTPHelo) {
+			return nil, fmt.Errorf("invalid SMTP hostname %q", hc.SMTPHelo)
+		}
+		smtp := healthcheck.NewSMTPChecker(ip, port)
+		target = &smtp.Target
+		if hc.SMTPHelo != "" {
+			smtp.Hostname = hc.SMTPHelo
+		}
+		smtp.StartTLS = hc.SMTPStartTLS
+		smtp.TLSVerify = hc.TLSVerify
+		smtp.ServerName = hc.TLSServerName
+		smtp.ExpectedCapability = hc.SMTPCap
+		checker = smtp
+	case seesaw.HCTypeLDAP:
+		if hc.Secure && hc.LDAPStartTLS {
+			return nil, errors.New("LDAPS healthchecks cannot use StartTLS")
+		}
+		if hc.LDAPBindDN == "" && hc.LDAPPass != "" {
+			return nil, errors.New("LDAP healthcheck password requires a bind DN")
+		}
+		if hc.LDAPBindDN != "" && hc.LDAPPass == "" {
+			return nil, errors.New("LDAP healthcheck bind DN requires a password")
+		}
+		ldap := healthcheck.NewLDAPChecker(ip, port)
+		target = &ldap.Target
+		ldap.BindDN = hc.LDAPBindDN
+		ldap.Password = hc.LDAPPass
+		ldap.SearchDN = hc.LDAPSearchDN
+		ldap.Secure = hc.Secure
+		ldap.StartTLS = hc.LDAPStartTLS
+		ldap.TLSVerify = hc.TLSVerify
+		ldap.ServerName = hc.TLSServerName
+		checker = ldap
+	case seesaw.HCTypeSIP:
+		sip := healthcheck.NewSIPChecker(ip, port)
+		target = &sip.Target
+		if hc.SIPOverTCP {
+			sip.Proto = seesaw.IPProtoTCP
+		}
+		sip.ExpectedStatus = hc.Code
+		checker = sip
+	case seesaw.HCTypeNTP:
+		if hc.NTPMaxStratum < 0 || hc.NTPMaxStratum > healthcheck.DefaultNTPMaxStratum {
+			return nil, fmt.Errorf("invalid NTP max stratum %d", hc.NTPMaxStratum)
+		}
+		ntp := healthcheck.NewNTPChecker(ip, port)
+		target = &ntp.Target
+		if hc.NTPMaxStratum != 0 {
+			ntp.MaxStratum = hc.NTPMaxStratum
+		}
+		ntp.MaxRootDispersion = hc.NTPMaxDisp
+		ntp.MaxOffset = hc.NTPMaxOffset
+		checker = ntp
+	case seesaw.HCTypeFTP:
+		if !healthcheck.ValidFTPCommand(hc.FTPCommand) {
+			return nil, fmt.Errorf("invalid FTP command %q", hc.FTPCommand)
+		}
+		if hc.FTPCommand == "CWD" && hc.FTPPath == "" {
+			return nil, errors.New("FTP CWD healthcheck requires a path")
+		}
+		for _, arg := range []string{hc.FTPUser, hc.FTPPass, hc.FTPPath} {
+			if !healthcheck.ValidLineArgument(arg) {
+				return nil, errors.New("FTP healthcheck user, password and path cannot contain line breaks")
+			}
+		}
+		ftp := healthcheck.NewFTPChecker(ip, port)
+		target = &ftp.Target
+		if hc.FTPUser != "" {
+			ftp.User = hc.FTPUser
+			ftp.Password = hc.FTPPass
+		}
+		ftp.Command = hc.FTPCommand
+		ftp.Path = hc.FTPPath
+		ftp.Secure = hc.Secure
+		ftp.TLSVerify = hc.TLSVerify
+		ftp.ServerName = hc.TLSServerName
+		checker = ftp
+	case seesaw.HCTypeIMAP:
+		if hc.Secure && hc.IMAPStartTLS {
+			return nil, errors.New("IMAPS healthchecks cannot use STARTTLS")
+		}
+		if hc.IMAPUser == "" && hc.IMAPPass != "" {
+			return nil, errors.New("IMAP healthcheck password requires a user")
+		}
+		for _, arg := range []string{hc.IMAPUser, hc.IMAPPass, hc.IMAPMailbox} {
+			if !healthcheck.ValidLineArgument(arg) {
+				return nil, errors.New("IMAP healthcheck user, password and mailbox cannot contain line breaks")
+			}
+		}
+		imap := healthcheck.NewIMAPChecker(ip, port)
+		target = &imap.Target
+		imap.User = hc.IMAPUser
+		imap.Password = hc.IMAPPass
+		imap.Mailbox = hc.IMAPMailbox
+		imap.Secure = hc.Secure
+		imap.StartTLS = hc.IMAPStartTLS
+		imap.TLSVerify = hc.TLSVerify
+		imap.ServerName = hc.TLSServerName
+		checker = imap
+	case seesaw.HCTypePOP3:
+		if hc.Secure && hc.POP3StartTLS {
+			return nil, errors.New("POP3S healthchecks cannot use STLS")
+		}
+		if hc.POP3User == "" && hc.POP3Pass != "" {
+			return nil, errors.New("POP3 healthcheck password requires a user")
+		}
+		for _, arg := range []string{hc.POP3User, hc.POP3Pass} {
+			if !healthcheck.ValidLineArgument(arg) {
+				return nil, errors.New("POP3 healthcheck user and password cannot contain line breaks")
+			}
+		}
+		pop3 := healthcheck.NewPOP3Checker(ip, port)
+		target = &pop3.Target
+		pop3.User = hc.POP3User
+		pop3.Password = hc.POP3Pass
+		pop3.Secure = hc.Secure
+		pop3.StartTLS = hc.POP3StartTLS
+		pop3.TLSVerify = hc.TLSVerify
+		pop3.ServerName = hc.TLSServerName
+		checker = pop3
+	case seesaw.HCTypeScript:
+		if !healthcheck.ValidScriptCommand(hc.ScriptCmd) {
+			return nil, fmt.Errorf("invalid script command %q", hc.ScriptCmd)
+		}
+		script := healthcheck.NewScriptChecker(ip, port)
+		target = &script.Target
+		script.Command = hc.ScriptCmd
+		script.Args = hc.ScriptArgList()
+		checker = script
 	case seesaw.HCTypeICMP:
 		// DSR or TUN cannot be used with ICMP (at least for now).
 		if key.HealthcheckMode != seesaw.HCModePlain {
@@ -308,24 +799,39 @@ func (h *healthcheckManager) newConfig(id healthcheck.Id, key CheckKey, hc *conf
 	case seesaw.HCTypeRADIUS:
 		radius := healthcheck.NewRADIUSChecker(ip, port)
 		target = &radius.Target
-		// RADIUS credentials are packed into the generic Send field as
-		// "username:password:secret". Per-type fields are defined in
-		// config.proto but require protoc regeneration to use natively.
-		send := strings.Split(hc.Send, ":")
-		if len(send) != 3 {
-			return nil, fmt.Errorf("RADIUS healthcheck send must be 'username:password:secret', got %d parts", len(send))
-		}
-		if send[0] == "" || send[1] == "" || send[2] == "" {
+		radius.StatusServer = hc.RADIUSStatus
+		if hc.RADIUSSecret != "" {
+			radius.Username = hc.RADIUSUser
+			radius.Password = hc.RADIUSPass
+			radius.Secret = hc.RADIUSSecret
+		} else {
+			// Legacy configurations pack the RADIUS credentials into the
+			// generic Send field as "username:password:secret".
+			send := strings.Split(hc.Send, ":")
+			if len(send) != 3 {
+				return nil, fmt.Errorf("RADIUS healthcheck send must be 'username:password:secret', got %d parts", len(send))
+			}
+			radius.Username = send[0]
+			radius.Password = send[1]
+			radius.Secret = send[2]
+		}
+		if radius.Secret == "" || !radius.StatusServer && (radius.Username == "" || radius.Password == "") {
 			return nil, errors.New("RADIUS healthcheck username, password, and secret must all be non-empty")
 		}
-		radius.Username = send[0]
-		radius.Password = send[1]
-		radius.Secret = send[2]
 		if hc.Receive != "" {
 			radius.Response = hc.Receive
 		}
 		checker = radius
 	case seesaw.HCTypeTCP:
+		if hc.TCPHalfOpen {
+			if hc.Secure || hc.Send != "" || hc.Receive != "" || hc.ReceiveRegexp != "" {
+				return nil, errors.New("TCP half-open healthcheck cannot use TLS, send or receive")
+			}
+			syn := healthcheck.NewSYNChecker(ip, port)
+			target = &syn.Target
+			checker = syn
+			break
+		}
 		tcp := healthcheck.NewTCPChecker(ip, port)
 		target = &tcp.Target
 		tcp.Send = hc.Send
@@ -334,6 +840,11 @@ func (h *healthcheckManager) newConfig(id healthcheck.Id, key CheckKey, hc *conf
 		if hc.Secure {
 			tcp.TLSVerify = hc.TLSVerify
 		}
+		if hc.ReceiveRegexp != "" {
+			if err := tcp.SetReceiveRegexp(hc.ReceiveRegexp); err != nil {
+				return nil, err
+			}
+		}
 		checker = tcp
 	case seesaw.HCTypeUDP:
 		udp := healthcheck.NewUDPChecker(ip, port)
@@ -353,6 +864,10 @@ func (h *healthcheckManager) newConfig(id healthcheck.Id, key CheckKey, hc *conf
 	hcc.Interval = hc.Interval
 	hcc.Timeout = hc.Timeout
 	hcc.Retries = hc.Retries
+	hcc.Rise = hc.Rise
+	hcc.Fall = hc.Fall
+	hcc.Passive = hc.Passive
+	hcc.MaxLatency = hc.MaxLatency
 
 	return hcc, nil
 }
@@ -423,7 +938,7 @@ func (h *healthcheckManager) markBackend(key markKey) uint32 {
 	}
 
 	log.Infof("Adding DSR/TUN IPVS service for %s (mark %d)", key.backend, mark)
-	if err := h.ncc.IPVSAddService(ipvsSvc); err != nil {
+	if err := h.ncc.IPVSEnsureService(ipvsSvc); err != nil {
 		log.Fatalf("Failed to add IPVS service for DSR/TUN: %v", err)
 	}
 
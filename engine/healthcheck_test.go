@@ -19,7 +19,11 @@ package engine
 // This file contains the tests for engine_healthcheck.go.
 
 import (
+	"fmt"
 	"net"
+	"net/rpc"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
@@ -544,3 +548,149 @@ func TestHealthcheckUpdates(t *testing.T) {
 		}
 	}
 }
+
+func TestHealthcheckConfigVersions(t *testing.T) {
+	hcm := newHealthcheckManager(newTestEngine())
+	_, version := hcm.versionedConfigs()
+
+	start := time.Now()
+	if _, v := hcm.configsSince(version, 50*time.Millisecond); v != version {
+		t.Errorf("Got version %d without a change, want %d", v, version)
+	}
+	if d := time.Since(start); d < 50*time.Millisecond {
+		t.Errorf("Returned after %v without a change, want at least %v", d, 50*time.Millisecond)
+	}
+
+	checks := hcUpdateTests[0].checks
+	tests := []struct {
+		desc   string
+		update func()
+		change bool
+	}{
+		{"add healthchecks", func() { hcm.update("test", checks) }, true},
+		{"same healthchecks", func() { hcm.update("test", checks) }, false},
+		{"other vserver", func() { hcm.update("other", hcUpdateTests[2].checks) }, true},
+		{"disable", hcm.disable, true},
+		{"disable again", hcm.disable, false},
+		{"enable", hcm.enable, true},
+		{"remove healthchecks", func() { hcm.update("test", nil) }, true},
+	}
+	for _, test := range tests {
+		waiter := make(chan uint64, 1)
+		if test.change {
+			go func(version uint64) {
+				_, v := hcm.configsSince(version, 5*time.Second)
+				waiter <- v
+			}(version)
+		}
+		test.update()
+		cfgs, v := hcm.versionedConfigs()
+		if (v != version) != test.change {
+			t.Errorf("%s: got version %d after version %d, want change %v", test.desc, v, version, test.change)
+		}
+		if test.change {
+			select {
+			case got := <-waiter:
+				if got != v {
+					t.Errorf("%s: waiter got version %d, want %d", test.desc, got, v)
+				}
+			case <-time.After(time.Second):
+				t.Errorf("%s: waiter was not woken by change", test.desc)
+			}
+		}
+		if _, got := hcm.configsSince(version, 0); got != v {
+			t.Errorf("%s: got version %d since %d, want %d", test.desc, got, version, v)
+		}
+		if test.desc == "disable" && cfgs != nil {
+			t.Errorf("%s: got %d configs, want none", test.desc, len(cfgs))
+		}
+		version = v
+	}
+}
+
+// historyServer serves canned healthcheck history over IPC.
+type historyServer struct{}
+
+func (s *historyServer) CheckHistory(args *healthcheck.HistoryRequest, reply *healthcheck.History) error {
+	reply.Results = make(map[healthcheck.Id][]*seesaw.HealthcheckResult)
+	for _, id := range args.Ids {
+		reply.Results[id] = []*seesaw.HealthcheckResult{{Success: true, Message: fmt.Sprintf("check %d", id)}}
+	}
+	return nil
+}
+
+func TestDestinationHistory(t *testing.T) {
+	dir, err := os.MkdirTemp("", "seesaw")
+	if err != nil {
+		t.Fatalf("Failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "healthcheck.sock")
+
+	// Use a vserver-level TCP healthcheck for each destination.
+	hc := &config.Healthcheck{
+		Name:     "TCP/80_0",
+		Mode:     seesaw.HCModePlain,
+		Type:     seesaw.HCTypeTCP,
+		Port:     80,
+		Interval: 10 * time.Second,
+		Timeout:  5 * time.Second,
+	}
+	vsConfig := vserverConfig
+	vsConfig.Entries = make(map[string]*config.VserverEntry)
+	for k, vse := range vserverConfig.Entries {
+		vseCopy := *vse
+		vseCopy.Healthchecks = nil
+		vsConfig.Entries[k] = &vseCopy
+	}
+	vsConfig.Healthchecks = map[string]*config.Healthcheck{hc.Key(): hc}
+
+	engine := newTestEngine()
+	engine.config.HealthcheckSocket = socket
+	vserver := newTestVserver(engine)
+	vserver.handleConfigUpdate(&vsConfig)
+	hcm := newHealthcheckManager(engine)
+	hcm.update(vsConfig.Name, vserver.checks)
+
+	var dest *destination
+	for _, svc := range vserver.services {
+		for _, d := range svc.dests {
+			if ids := hcm.dests[d.name()]; len(ids) != 1 {
+				t.Errorf("Destination %v got %d healthcheck IDs, want 1", d.name(), len(ids))
+			}
+			dest = d
+		}
+	}
+	if dest == nil {
+		t.Fatal("No destinations found")
+	}
+
+	if _, err := hcm.history(dest.name()); err == nil {
+		t.Error("Got history without a healthcheck component")
+	}
+
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("Failed to listen on %v: %v", socket, err)
+	}
+	defer ln.Close()
+	server := rpc.NewServer()
+	server.RegisterName("SeesawHealthcheck", &historyServer{})
+	go server.Accept(ln)
+
+	hcs, err := hcm.history(dest.name())
+	if err != nil {
+		t.Fatalf("Failed to get history for %v: %v", dest.name(), err)
+	}
+	if len(hcs) != 1 {
+		t.Errorf("Got %d healthchecks, want 1", len(hcs))
+	}
+	for _, hc := range hcs {
+		if len(hc.Results) != 1 || !hc.Results[0].Success {
+			t.Errorf("Healthcheck %s got results %v, want one success", hc.Name, hc.Results)
+		}
+	}
+	if _, err := hcm.history("unknown/1.1.1.1:80/TCP"); err == nil {
+		t.Error("Got history for an unknown destination")
+	}
+}
@@ -32,12 +32,30 @@ import (
 
 func init() {
 	gob.Register(&healthcheck.DNSChecker{})
+	gob.Register(&healthcheck.FTPChecker{})
+	gob.Register(&healthcheck.GRPCChecker{})
 	gob.Register(&healthcheck.HTTPChecker{})
+	gob.Register(&healthcheck.IMAPChecker{})
+	gob.Register(&healthcheck.LDAPChecker{})
+	gob.Register(&healthcheck.MemcacheChecker{})
+	gob.Register(&healthcheck.MySQLChecker{})
+	gob.Register(&healthcheck.NTPChecker{})
+	gob.Register(&healthcheck.POP3Checker{})
 	gob.Register(&healthcheck.PingChecker{})
+	gob.Register(&healthcheck.PostgresChecker{})
+	gob.Register(&healthcheck.RedisChecker{})
+	gob.Register(&healthcheck.SIPChecker{})
+	gob.Register(&healthcheck.ScriptChecker{})
+	gob.Register(&healthcheck.SMTPChecker{})
+	gob.Register(&healthcheck.SYNChecker{})
 	gob.Register(&healthcheck.TCPChecker{})
 	gob.Register(&healthcheck.UDPChecker{})
 }
 
+// maxConnections is the maximum number of IPVS connections that are returned
+// by a single Connections IPC.
+const maxConnections = 1000
+
 var (
 	errAccess  = errors.New("insufficient access")
 	errContext = errors.New("context is nil")
@@ -180,9 +198,40 @@ func (s *SeesawEngine) Healthchecks(ctx *ipc.Context, reply *healthcheck.Checks)
 		return errAccess
 	}
 
-	configs := s.engine.hcManager.configs()
+	configs, version := s.engine.hcManager.versionedConfigs()
+	if reply != nil {
+		reply.Configs = configs
+		reply.MaxConcurrent = s.engine.config.HealthcheckMaxConcurrent
+		reply.Version = version
+	}
+	return nil
+}
+
+// HealthchecksSince returns the currently configured healthchecks once they
+// differ from the requested version, or once the requested wait has passed.
+// This allows the Seesaw Healthcheck component to promptly apply changes.
+func (s *SeesawEngine) HealthchecksSince(args *healthcheck.ChecksRequest, reply *healthcheck.Checks) error {
+	if args == nil {
+		return errors.New("args is nil")
+	}
+	s.trace("HealthchecksSince", args.Ctx)
+	if args.Ctx == nil {
+		return errContext
+	}
+
+	if !args.Ctx.IsTrusted() {
+		return errAccess
+	}
+
+	wait := args.Wait
+	if wait > healthcheckMaxWait {
+		wait = healthcheckMaxWait
+	}
+	configs, version := s.engine.hcManager.configsSince(args.Version, wait)
 	if reply != nil {
 		reply.Configs = configs
+		reply.MaxConcurrent = s.engine.config.HealthcheckMaxConcurrent
+		reply.Version = version
 	}
 	return nil
 }
@@ -203,6 +252,7 @@ func (s *SeesawEngine) HealthState(args *healthcheck.HealthState, reply *int) er
 		return errAccess
 	}
 
+	s.engine.hcWatchdog.delivered(ctx.Peer.Component)
 	for _, n := range args.Notifications {
 		if err := s.engine.hcManager.queueHealthState(n); err != nil {
 			return err
@@ -212,6 +262,86 @@ func (s *SeesawEngine) HealthState(args *healthcheck.HealthState, reply *int) er
 	return nil
 }
 
+// HealthcheckStatus returns the delivery status of healthcheck notifications.
+func (s *SeesawEngine) HealthcheckStatus(ctx *ipc.Context, reply *seesaw.HealthcheckStatus) error {
+	s.trace("HealthcheckStatus", ctx)
+	if ctx == nil {
+		return errContext
+	}
+
+	if !ctx.CanRead() {
+		return errAccess
+	}
+
+	if reply == nil {
+		return fmt.Errorf("HealthcheckStatus is nil")
+	}
+	*reply = *s.engine.hcWatchdog.status()
+	reply.SkippedRuns = s.engine.hcManager.skippedRuns()
+	reply.ThrottledRuns = s.engine.hcManager.throttledRuns()
+	return nil
+}
+
+// DestinationHistory returns the recent healthcheck results for a destination,
+// as retained by the healthcheck component.
+func (s *SeesawEngine) DestinationHistory(args *ipc.DestinationHistory, reply *seesaw.DestinationHistory) error {
+	if args == nil {
+		return errors.New("args is nil")
+	}
+	ctx := args.Ctx
+	s.trace("DestinationHistory", ctx)
+	if ctx == nil {
+		return errContext
+	}
+
+	if !ctx.CanRead() {
+		return errAccess
+	}
+
+	if reply == nil {
+		return fmt.Errorf("DestinationHistory is nil")
+	}
+	hcs, err := s.engine.hcManager.history(args.Destination)
+	if err != nil {
+		return err
+	}
+	reply.Destination = args.Destination
+	reply.Healthchecks = hcs
+	return nil
+}
+
+// Connections returns entries from the IPVS connection table, limited to the
+// requested number of entries and at most maxConnections.
+func (s *SeesawEngine) Connections(args *ipc.Connections, reply *seesaw.Connections) error {
+	if args == nil {
+		return errors.New("args is nil")
+	}
+	ctx := args.Ctx
+	s.trace("Connections", ctx)
+	if ctx == nil {
+		return errContext
+	}
+
+	if !ctx.CanRead() {
+		return errAccess
+	}
+
+	if reply == nil {
+		return fmt.Errorf("Connections is nil")
+	}
+	max := args.Max
+	if max <= 0 || max > maxConnections {
+		max = maxConnections
+	}
+	conns, err := s.engine.ncc.IPVSConnections(args.Filter, max)
+	if err != nil {
+		return err
+	}
+	reply.Connections = conns.Connections
+	reply.Truncated = conns.Truncated
+	return nil
+}
+
 // ClusterStatus returns status information about this Seesaw Cluster.
 func (s *SeesawEngine) ClusterStatus(ctx *ipc.Context, reply *seesaw.ClusterStatus) error {
 	s.trace("ClusterStatus", ctx)
@@ -233,6 +363,11 @@ func (s *SeesawEngine) ClusterStatus(ctx *ipc.Context, reply *seesaw.ClusterStat
 
 	reply.Version = seesaw.SeesawVersion
 	reply.Site = cluster.Site
+	timeouts, err := s.engine.ncc.IPVSGetTimeouts()
+	if err != nil {
+		log.Warningf("Failed to get IPVS timeouts: %v", err)
+	}
+	reply.IPVSTimeouts = timeouts
 	reply.Nodes = make([]*seesaw.Node, 0, len(cluster.Nodes))
 	for _, node := range cluster.Nodes {
 		reply.Nodes = append(reply.Nodes, node.Clone())
@@ -338,6 +473,62 @@ func (s *SeesawEngine) BGPNeighbors(ctx *ipc.Context, reply *quagga.Neighbors) e
 	return nil
 }
 
+// Routes returns the status of the routes that are advertised while this
+// node is the HA leader.
+func (s *SeesawEngine) Routes(ctx *ipc.Context, reply *seesaw.Routes) error {
+	s.trace("Routes", ctx)
+	if ctx == nil {
+		return errContext
+	}
+
+	if !ctx.CanRead() {
+		return errAccess
+	}
+
+	if reply == nil {
+		return fmt.Errorf("Routes is nil")
+	}
+	reply.Routes = s.engine.routeManager.status()
+	return nil
+}
+
+// Reconcile reconciles the IPVS table with the configured services, repairing
+// any drift, and returns the resulting reconciliation status.
+func (s *SeesawEngine) Reconcile(ctx *ipc.Context, reply *seesaw.ReconcileStatus) error {
+	s.trace("Reconcile", ctx)
+	if ctx == nil {
+		return errContext
+	}
+
+	if !ctx.CanWrite() {
+		return errAccess
+	}
+
+	s.engine.reconciler.reconcile()
+	if reply != nil {
+		*reply = *s.engine.reconciler.status()
+	}
+	return nil
+}
+
+// ReconcileStatus returns the status of the reconciliation of the IPVS table.
+func (s *SeesawEngine) ReconcileStatus(ctx *ipc.Context, reply *seesaw.ReconcileStatus) error {
+	s.trace("ReconcileStatus", ctx)
+	if ctx == nil {
+		return errContext
+	}
+
+	if !ctx.CanRead() {
+		return errAccess
+	}
+
+	if reply == nil {
+		return fmt.Errorf("ReconcileStatus is nil")
+	}
+	*reply = *s.engine.reconciler.status()
+	return nil
+}
+
 // VLANs returns a list of VLANs configured for this cluster.
 func (s *SeesawEngine) VLANs(ctx *ipc.Context, reply *seesaw.VLANs) error {
 	s.trace("VLANs", ctx)
@@ -459,6 +650,37 @@ func (s *SeesawEngine) OverrideVserver(args *ipc.Override, reply *int) error {
 	return nil
 }
 
+// OverridePassive passes a PassiveOverride to the engine.
+func (s *SeesawEngine) OverridePassive(args *ipc.Override, reply *int) error {
+	if args == nil {
+		return errors.New("args is nil")
+	}
+	ctx := args.Ctx
+	s.trace("OverridePassive", ctx)
+	if ctx == nil {
+		return errContext
+	}
+
+	if !ctx.IsAuthenticated() && !ctx.IsTrusted() {
+		return errAccess
+	}
+
+	override := args.Passive
+	if override == nil {
+		return errors.New("override passive is nil")
+	}
+
+	reason, err := s.accessCheck(ctx, override.VserverName)
+	if err != nil {
+		log.Warningf("Passive override on %q denied for %v: %v", override.VserverName, ctx, err)
+		return err
+	}
+
+	log.Infof("Passive override for %q requested by %v (%s)", override.VserverName, ctx, reason)
+	s.engine.queueOverride(override)
+	return nil
+}
+
 // Backends returns a list of currently configured Backends.
 func (s *SeesawEngine) Backends(ctx *ipc.Context, reply *seesaw.BackendMap) error {
 	s.trace("Backends", ctx)
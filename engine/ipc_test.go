@@ -19,8 +19,12 @@ package engine
 import (
 	"testing"
 
+	"github.com/google/seesaw/common/ipc"
 	"github.com/google/seesaw/common/seesaw"
 	"github.com/google/seesaw/healthcheck"
+	"github.com/google/seesaw/ipvs"
+	ncclient "github.com/google/seesaw/ncc/client"
+	ncctypes "github.com/google/seesaw/ncc/types"
 )
 
 func TestEnableDisableBackend(t *testing.T) {
@@ -91,3 +95,42 @@ func TestEnableDisableBackend(t *testing.T) {
 		}
 	}
 }
+
+// connectionsNCC is an NCC that records the maximum number of IPVS
+// connections requested.
+type connectionsNCC struct {
+	ncclient.NCC
+	max int
+}
+
+func (nc *connectionsNCC) IPVSConnections(filter ipvs.ConnectionFilter, max int) (*ncctypes.IPVSConnections, error) {
+	nc.max = max
+	return &ncctypes.IPVSConnections{Truncated: true}, nil
+}
+
+func TestConnectionsLimit(t *testing.T) {
+	e := newTestEngine()
+	nc := &connectionsNCC{NCC: e.ncc}
+	e.ncc = nc
+	s := &SeesawEngine{engine: e}
+	ctx := ipc.NewTrustedContext(seesaw.SCLocalCLI)
+
+	for _, test := range []struct {
+		max, want int
+	}{
+		{0, maxConnections},
+		{10, 10},
+		{maxConnections + 1, maxConnections},
+	} {
+		var reply seesaw.Connections
+		if err := s.Connections(&ipc.Connections{Ctx: ctx, Max: test.max}, &reply); err != nil {
+			t.Fatalf("Connections failed: %v", err)
+		}
+		if nc.max != test.want {
+			t.Errorf("Connections with max %d requested %d connections, want %d", test.max, nc.max, test.want)
+		}
+		if !reply.Truncated {
+			t.Errorf("Connections with max %d is not truncated", test.max)
+		}
+	}
+}
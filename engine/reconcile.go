@@ -0,0 +1,459 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+// This file contains structures and functions to reconcile the kernel IPVS
+// table with the services configured by the vservers, repairing drift caused
+// by manual changes or by the kernel discarding state.
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/seesaw/common/seesaw"
+	"github.com/google/seesaw/engine/config"
+	"github.com/google/seesaw/ipvs"
+
+	log "github.com/golang/glog"
+)
+
+// reconcileTimeout is the maximum time to wait for the vservers to reconcile
+// their services.
+const reconcileTimeout = 30 * time.Second
+
+// reconcileRequest is a request for the vservers to reconcile their services
+// with a snapshot of the IPVS table.
+type reconcileRequest struct {
+	services   map[string]*ipvs.Service
+	dispatched chan bool
+	wg         sync.WaitGroup
+
+	lock        sync.Mutex
+	claimed     map[string]bool
+	corrections int
+	errs        []string
+	skipped     bool // A vserver did not reconcile, so claims are incomplete.
+}
+
+// newReconcileRequest returns a reconcileRequest for the given IPVS services.
+func newReconcileRequest(svcs []*ipvs.Service) *reconcileRequest {
+	req := &reconcileRequest{
+		services:   make(map[string]*ipvs.Service),
+		dispatched: make(chan bool),
+		claimed:    make(map[string]bool),
+	}
+	for _, svc := range svcs {
+		req.services[ipvsServiceKey(svc)] = svc
+	}
+	return req
+}
+
+// done records the result of reconciliation by a vserver, which claims the
+// IPVS services with the given keys.
+func (req *reconcileRequest) done(claimed []string, corrections int, errs []string) {
+	req.lock.Lock()
+	for _, key := range claimed {
+		req.claimed[key] = true
+	}
+	req.corrections += corrections
+	req.errs = append(req.errs, errs...)
+	req.lock.Unlock()
+	req.wg.Done()
+}
+
+// skip records that a vserver did not reconcile its services. Since the
+// vserver has not claimed its IPVS services, no unclaimed services may be
+// removed.
+func (req *reconcileRequest) skip(err string) {
+	req.lock.Lock()
+	req.skipped = true
+	req.errs = append(req.errs, err)
+	req.lock.Unlock()
+	req.wg.Done()
+}
+
+// wait waits for all vservers to complete reconciliation.
+func (req *reconcileRequest) wait(timeout time.Duration) error {
+	done := make(chan bool)
+	go func() {
+		req.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+		return errors.New("timed out waiting for vservers")
+	}
+}
+
+// reconciler periodically reconciles the kernel IPVS table with the services
+// configured by the vservers.
+type reconciler struct {
+	engine   *Engine
+	interval time.Duration
+	dispatch func(req *reconcileRequest)
+
+	runLock sync.Mutex // Held for the duration of a reconciliation.
+
+	lock   sync.RWMutex
+	result seesaw.ReconcileStatus
+}
+
+// newReconciler returns an initialised reconciler.
+func newReconciler(e *Engine) *reconciler {
+	r := &reconciler{
+		engine:   e,
+		interval: e.config.IPVSReconcileInterval,
+		result:   seesaw.ReconcileStatus{Interval: e.config.IPVSReconcileInterval},
+	}
+	// Requests are distributed to the vservers by the manager, which owns
+	// the set of running vservers.
+	r.dispatch = func(req *reconcileRequest) {
+		e.reconcileChan <- req
+		<-req.dispatched
+	}
+	return r
+}
+
+// run periodically reconciles the IPVS table.
+func (r *reconciler) run() {
+	if r.interval <= 0 {
+		log.Infof("Periodic IPVS reconciliation is disabled")
+		return
+	}
+	for range time.Tick(r.interval) {
+		r.reconcile()
+	}
+}
+
+// reconcile reconciles the IPVS table and records the outcome.
+func (r *reconciler) reconcile() {
+	r.runLock.Lock()
+	defer r.runLock.Unlock()
+
+	start := time.Now()
+	corrections, err := r.reconcileIPVS()
+	if err != nil {
+		log.Errorf("IPVS reconciliation failed: %v", err)
+	} else if corrections > 0 {
+		log.Warningf("IPVS reconciliation made %d corrections", corrections)
+	} else {
+		log.V(1).Infof("IPVS reconciliation found no drift")
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.result.Runs++
+	r.result.Corrections += uint64(corrections)
+	r.result.LastRun = start
+	r.result.LastDuration = time.Since(start)
+	r.result.LastCorrections = corrections
+	r.result.LastError = ""
+	if err != nil {
+		r.result.Failures++
+		r.result.LastError = err.Error()
+	}
+}
+
+// reconcileIPVS has each vserver repair its services in the IPVS table, then
+// removes services that are owned by the engine but no longer configured. It
+// returns the number of corrections made.
+func (r *reconciler) reconcileIPVS() (int, error) {
+	e := r.engine
+	e.clusterLock.RLock()
+	cluster := e.cluster
+	e.clusterLock.RUnlock()
+	if cluster == nil {
+		return 0, nil
+	}
+
+	svcs, err := e.ncc.IPVSGetServices()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get IPVS services: %v", err)
+	}
+	req := newReconcileRequest(svcs)
+	r.dispatch(req)
+	if err := req.wait(reconcileTimeout); err != nil {
+		return 0, err
+	}
+
+	req.lock.Lock()
+	corrections := req.corrections
+	errs := req.errs
+	claimed := req.claimed
+	skipped := req.skipped
+	req.lock.Unlock()
+
+	// A vserver may have configured services since the snapshot was taken,
+	// in which case unclaimed services cannot safely be removed until the
+	// next reconciliation.
+	e.clusterLock.RLock()
+	changed := e.cluster != cluster
+	e.clusterLock.RUnlock()
+	if changed {
+		log.Infof("Cluster configuration changed during IPVS reconciliation, not removing unconfigured services")
+	} else if skipped {
+		log.Warningf("Not all vservers completed IPVS reconciliation, not removing unconfigured services")
+	} else {
+		keys := make([]string, 0, len(req.services))
+		for key := range req.services {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			svc := req.services[key]
+			if claimed[key] || !ownsIPVSService(svc, cluster) {
+				continue
+			}
+			log.Warningf("Removing unconfigured IPVS service %v", svc)
+			if err := e.ncc.IPVSFlushService(svc); err != nil {
+				errs = append(errs, fmt.Sprintf("failed to remove %v: %v", svc, err))
+				continue
+			}
+			corrections++
+		}
+	}
+
+	if len(errs) > 0 {
+		return corrections, errors.New(strings.Join(errs, "; "))
+	}
+	return corrections, nil
+}
+
+// status returns the current reconciliation status.
+func (r *reconciler) status() *seesaw.ReconcileStatus {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	s := r.result
+	return &s
+}
+
+// distributeReconcile queues a reconciliation request for each vserver.
+func (e *Engine) distributeReconcile(req *reconcileRequest) {
+	for _, v := range e.vservers {
+		req.wg.Add(1)
+		v.queueReconcile(req)
+	}
+	close(req.dispatched)
+}
+
+// ipvsServiceKey returns a key that identifies a service in the IPVS table.
+func ipvsServiceKey(svc *ipvs.Service) string {
+	if svc.FirewallMark > 0 {
+		af := seesaw.IPv6
+		if svc.Address.To4() != nil {
+			af = seesaw.IPv4
+		}
+		return fmt.Sprintf("%v FWM %d", af, svc.FirewallMark)
+	}
+	return fmt.Sprintf("%v %v:%d", svc.Protocol, svc.Address, svc.Port)
+}
+
+// ownsIPVSService returns true if the given IPVS service belongs to the
+// engine, either because it uses a firewall mark that is allocated to
+// vservers or because its address is a configured VIP. Services that belong
+// to other software, or to the healthcheck manager, are never modified.
+func ownsIPVSService(svc *ipvs.Service, cluster *config.Cluster) bool {
+	if svc.FirewallMark > 0 {
+		return svc.FirewallMark >= fwmAllocBase && svc.FirewallMark < fwmAllocBase+fwmAllocSize
+	}
+	for _, v := range cluster.Vservers {
+		if v.IPv4Addr.Equal(svc.Address) || v.IPv6Addr.Equal(svc.Address) {
+			return true
+		}
+		for _, vip := range v.VIPs {
+			if vip.IP.IP().Equal(svc.Address) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// kernelServiceFlags are the service flags that are set by the kernel, or
+// which are represented by other fields.
+const kernelServiceFlags = ipvs.SFHashed | ipvs.SFOnePacket
+
+// ipvsServiceEqual returns true if a service in the IPVS table has the given
+// configuration. Statistics and destinations are ignored.
+func ipvsServiceEqual(want, got *ipvs.Service) bool {
+	w, g := *want, *got
+	w.Flags &^= kernelServiceFlags
+	g.Flags &^= kernelServiceFlags
+	return w.Equal(g)
+}
+
+// queueReconcile queues a reconciliation request for processing.
+func (v *vserver) queueReconcile(req *reconcileRequest) {
+	select {
+	case v.reconcile <- req:
+	default:
+		log.Warningf("%v: reconciliation skipped because a previous request is still pending", v)
+		req.skip(fmt.Sprintf("%v: reconciliation skipped", v))
+	}
+}
+
+// reconcileIPVS repairs the IPVS services for this vserver, given a snapshot
+// of the IPVS table. Since changes are only made by the vserver go routine,
+// differences from the snapshot are confirmed against the current IPVS table
+// before any repair is made.
+func (v *vserver) reconcileIPVS(req *reconcileRequest) {
+	var claimed, errs []string
+	corrections := 0
+	for _, s := range v.services {
+		key := ipvsServiceKey(s.ipvsSvc)
+		claimed = append(claimed, key)
+		n, err := s.reconcile(req.services[key])
+		corrections += n
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%v: %v: %v", v, s, err))
+		}
+	}
+	req.done(claimed, corrections, errs)
+}
+
+// currentIPVSDestination returns the IPVS destination as it is currently
+// expected to be configured, accounting for draining.
+func (d *destination) currentIPVSDestination() *ipvs.Destination {
+	dst := *d.ipvsDst
+	if d.drained {
+		dst.Weight = 0
+	}
+	return &dst
+}
+
+// ipvsMatch returns true if the given IPVS service matches the running state
+// of this service, including its active destinations.
+func (s *service) ipvsMatch(svc *ipvs.Service) bool {
+	if !ipvsServiceEqual(s.ipvsSvc, svc) {
+		return false
+	}
+	active := 0
+	for _, d := range s.dests {
+		if d.active {
+			active++
+		}
+	}
+	if active != len(svc.Destinations) {
+		return false
+	}
+	for _, dst := range svc.Destinations {
+		d := s.findDestination(dst)
+		if d == nil || !d.active || !d.currentIPVSDestination().Equal(*dst) {
+			return false
+		}
+	}
+	return true
+}
+
+// findDestination returns the destination that corresponds to the given
+// IPVS destination, or nil if there is none.
+func (s *service) findDestination(dst *ipvs.Destination) *destination {
+	for _, d := range s.dests {
+		if d.ipvsDst.Address.Equal(dst.Address) && d.ipvsDst.Port == dst.Port {
+			return d
+		}
+	}
+	return nil
+}
+
+// reconcile repairs the IPVS service and destinations for this service, given
+// the service in a snapshot of the IPVS table, which is nil if the service
+// was absent. It returns the number of corrections made.
+func (s *service) reconcile(snapshot *ipvs.Service) (int, error) {
+	ncc := s.vserver.ncc
+
+	if !s.active {
+		if snapshot == nil {
+			return 0, nil
+		}
+		if _, err := ncc.IPVSGetService(s.ipvsSvc); err != nil {
+			return 0, nil
+		}
+		log.Warningf("%v: removing IPVS service %v for inactive service %v", s.vserver, s.ipvsSvc, s)
+		if err := ncc.IPVSFlushService(s.ipvsSvc); err != nil {
+			return 0, fmt.Errorf("failed to remove IPVS service: %v", err)
+		}
+		return 1, nil
+	}
+
+	if snapshot != nil && s.ipvsMatch(snapshot) {
+		return 0, nil
+	}
+	current, err := ncc.IPVSGetService(s.ipvsSvc)
+	if err != nil {
+		log.Warningf("%v: IPVS service %v for %v is missing, adding it", s.vserver, s.ipvsSvc, s)
+		txn := ipvs.Begin()
+		txn.EnsureService(*s.ipvsSvc)
+		for _, d := range s.dests {
+			if d.active {
+				txn.EnsureDestination(*s.ipvsSvc, *d.currentIPVSDestination())
+			}
+		}
+		if err := ncc.IPVSApply(txn); err != nil {
+			return 0, fmt.Errorf("failed to add IPVS service: %v", err)
+		}
+		return 1, nil
+	}
+	if s.ipvsMatch(current) {
+		return 0, nil
+	}
+
+	corrections := 0
+	if !ipvsServiceEqual(s.ipvsSvc, current) {
+		log.Warningf("%v: IPVS service %v differs from %v, updating it", s.vserver, current, s.ipvsSvc)
+		if err := ncc.IPVSUpdateService(s.ipvsSvc); err != nil {
+			return corrections, fmt.Errorf("failed to update IPVS service: %v", err)
+		}
+		corrections++
+	}
+	found := make(map[*destination]bool)
+	for _, dst := range current.Destinations {
+		d := s.findDestination(dst)
+		if d == nil || !d.active {
+			log.Warningf("%v: %v removing unexpected IPVS destination %v", s.vserver, s, dst)
+			if err := ncc.IPVSDeleteDestination(s.ipvsSvc, dst); err != nil {
+				return corrections, fmt.Errorf("failed to delete IPVS destination %v: %v", dst, err)
+			}
+			corrections++
+			continue
+		}
+		found[d] = true
+		if want := d.currentIPVSDestination(); !want.Equal(*dst) {
+			log.Warningf("%v: %v IPVS destination %v differs from %v, updating it", s.vserver, s, dst, want)
+			if err := ncc.IPVSUpdateDestination(s.ipvsSvc, want); err != nil {
+				return corrections, fmt.Errorf("failed to update IPVS destination %v: %v", want, err)
+			}
+			corrections++
+		}
+	}
+	for _, d := range s.dests {
+		if !d.active || found[d] {
+			continue
+		}
+		want := d.currentIPVSDestination()
+		log.Warningf("%v: %v IPVS destination %v is missing, adding it", s.vserver, s, want)
+		if err := ncc.IPVSEnsureDestination(s.ipvsSvc, want); err != nil {
+			return corrections, fmt.Errorf("failed to add IPVS destination %v: %v", want, err)
+		}
+		corrections++
+	}
+	return corrections, nil
+}
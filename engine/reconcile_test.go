@@ -0,0 +1,303 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/seesaw/common/seesaw"
+	"github.com/google/seesaw/engine/config"
+	"github.com/google/seesaw/ipvs"
+	ncclient "github.com/google/seesaw/ncc/client"
+)
+
+// newTestReconciler returns a reconciler for a single healthy vserver, which
+// programs the given in-memory IPVS backend.
+func newTestReconciler(backend *ipvs.DummyBackend) (*reconciler, *vserver) {
+	e := newTestEngine()
+	e.ncc = ncclient.NewIPVSDummyNCC(backend)
+	v := newTestVserver(e)
+	v.handleConfigUpdate(&vserverConfig)
+	for _, c := range v.checks {
+		v.handleCheckNotification(&checkNotification{key: c.key, status: statusHealthy})
+	}
+	e.vservers[vserverConfig.Name] = v
+	e.cluster = config.NewCluster("au-syd")
+	e.cluster.Vservers[vserverConfig.Name] = &vserverConfig
+
+	r := newReconciler(e)
+	r.dispatch = func(req *reconcileRequest) {
+		e.distributeReconcile(req)
+		v.reconcileIPVS(<-v.reconcile)
+	}
+	return r, v
+}
+
+// findIPVSService returns the service with the given address and port from
+// the IPVS backend, or nil if it does not exist.
+func findIPVSService(backend *ipvs.DummyBackend, ip string, port uint16) *ipvs.Service {
+	for _, svc := range backend.Snapshot() {
+		if svc.Address.Equal(net.ParseIP(ip)) && svc.Port == port {
+			return svc
+		}
+	}
+	return nil
+}
+
+func TestReconcile(t *testing.T) {
+	backend := ipvs.NewDummyBackend()
+	r, _ := newTestReconciler(backend)
+	want := backend.Snapshot()
+	if len(want) != len(expectedServices) {
+		t.Fatalf("Got %d IPVS services, want %d", len(want), len(expectedServices))
+	}
+
+	// An unowned service and a healthcheck service must not be removed.
+	other := ipvs.Service{
+		Address:   net.ParseIP("10.10.10.10"),
+		Protocol:  ipvs.IPProto(seesaw.IPProtoTCP),
+		Port:      80,
+		Scheduler: "rr",
+	}
+	hcMark := ipvs.Service{
+		Address:      net.ParseIP("0.0.0.0"),
+		FirewallMark: dsrMarkBase + 1,
+		Scheduler:    "rr",
+	}
+	for _, svc := range []ipvs.Service{other, hcMark} {
+		if err := backend.AddService(svc); err != nil {
+			t.Fatalf("Failed to add %v: %v", svc, err)
+		}
+	}
+
+	tests := []struct {
+		desc  string
+		drift func() error
+	}{
+		{"no drift", func() error { return nil }},
+		{"deleted service", func() error {
+			return backend.DeleteService(*findIPVSService(backend, "192.168.255.1", 53))
+		}},
+		{"deleted destination", func() error {
+			svc := findIPVSService(backend, "192.168.255.1", 8053)
+			return backend.DeleteDestination(*svc, *svc.Destinations[0])
+		}},
+		{"modified weight", func() error {
+			svc := findIPVSService(backend, "2012::1", 53)
+			dst := *svc.Destinations[0]
+			dst.Weight += 10
+			return backend.UpdateDestination(*svc, dst)
+		}},
+		{"modified scheduler", func() error {
+			svc := *findIPVSService(backend, "2012::1", 8053)
+			svc.Scheduler = "rr"
+			return backend.UpdateService(svc)
+		}},
+		{"unexpected destination", func() error {
+			svc := findIPVSService(backend, "192.168.255.1", 53)
+			dst := *svc.Destinations[0]
+			dst.Address = net.ParseIP("1.1.1.100")
+			return backend.AddDestination(*svc, dst)
+		}},
+		{"unconfigured service", func() error {
+			return backend.AddService(ipvs.Service{
+				Address:   net.ParseIP("192.168.255.1"),
+				Protocol:  ipvs.IPProto(seesaw.IPProtoTCP),
+				Port:      80,
+				Scheduler: "wrr",
+			})
+		}},
+		{"unconfigured firewall mark", func() error {
+			return backend.AddService(ipvs.Service{
+				Address:      net.ParseIP("0.0.0.0"),
+				FirewallMark: fwmAllocBase,
+				Scheduler:    "wrr",
+			})
+		}},
+	}
+	var total uint64
+	for _, test := range tests {
+		if err := test.drift(); err != nil {
+			t.Fatalf("%s: failed to modify IPVS table: %v", test.desc, err)
+		}
+		wantCorrections := 1
+		if test.desc == "no drift" {
+			wantCorrections = 0
+		}
+		r.reconcile()
+		total += uint64(wantCorrections)
+
+		status := r.status()
+		if status.LastError != "" {
+			t.Errorf("%s: reconciliation failed: %v", test.desc, status.LastError)
+		}
+		if status.LastCorrections != wantCorrections {
+			t.Errorf("%s: got %d corrections, want %d", test.desc, status.LastCorrections, wantCorrections)
+		}
+		if status.Corrections != total {
+			t.Errorf("%s: got %d total corrections, want %d", test.desc, status.Corrections, total)
+		}
+
+		got := backend.Snapshot()
+		if len(got) != len(want)+2 {
+			t.Errorf("%s: got %d IPVS services, want %d", test.desc, len(got), len(want)+2)
+		}
+		for _, w := range want {
+			var svc *ipvs.Service
+			for _, g := range got {
+				if ipvsServiceKey(g) == ipvsServiceKey(w) {
+					svc = g
+				}
+			}
+			if svc == nil {
+				t.Errorf("%s: IPVS service %v is missing", test.desc, w)
+				continue
+			}
+			if !svc.Equal(*w) {
+				t.Errorf("%s: got IPVS service %v, want %v", test.desc, svc, w)
+			}
+			if len(svc.Destinations) != len(w.Destinations) {
+				t.Errorf("%s: got %d destinations for %v, want %d", test.desc, len(svc.Destinations), svc, len(w.Destinations))
+				continue
+			}
+			for i, dst := range svc.Destinations {
+				if !dst.Equal(*w.Destinations[i]) {
+					t.Errorf("%s: got IPVS destination %v, want %v", test.desc, dst, w.Destinations[i])
+				}
+			}
+		}
+		if _, err := backend.GetService(other); err != nil {
+			t.Errorf("%s: unowned service %v was removed", test.desc, other)
+		}
+		if _, err := backend.GetService(hcMark); err != nil {
+			t.Errorf("%s: healthcheck service %v was removed", test.desc, hcMark)
+		}
+	}
+	if status := r.status(); status.Runs != uint64(len(tests)) || status.Failures != 0 {
+		t.Errorf("Got %d runs and %d failures, want %d runs and no failures", status.Runs, status.Failures, len(tests))
+	}
+}
+
+func TestReconcileClusterChange(t *testing.T) {
+	backend := ipvs.NewDummyBackend()
+	r, v := newTestReconciler(backend)
+	orphan := ipvs.Service{
+		Address:      net.ParseIP("0.0.0.0"),
+		FirewallMark: fwmAllocBase,
+		Scheduler:    "wrr",
+	}
+	if err := backend.AddService(orphan); err != nil {
+		t.Fatalf("Failed to add %v: %v", orphan, err)
+	}
+
+	// A configuration push during reconciliation defers the removal of
+	// unconfigured services.
+	e := r.engine
+	r.dispatch = func(req *reconcileRequest) {
+		e.distributeReconcile(req)
+		e.clusterLock.Lock()
+		e.cluster = config.NewCluster("au-syd")
+		e.cluster.Vservers[vserverConfig.Name] = &vserverConfig
+		e.clusterLock.Unlock()
+		v.reconcileIPVS(<-v.reconcile)
+	}
+	r.reconcile()
+	if _, err := backend.GetService(orphan); err != nil {
+		t.Errorf("Unconfigured service %v was removed after cluster change", orphan)
+	}
+	if status := r.status(); status.LastCorrections != 0 {
+		t.Errorf("Got %d corrections, want 0", status.LastCorrections)
+	}
+}
+
+func TestReconcileSkippedVserver(t *testing.T) {
+	backend := ipvs.NewDummyBackend()
+	r, v := newTestReconciler(backend)
+	want := backend.Snapshot()
+	orphan := ipvs.Service{
+		Address:      net.ParseIP("0.0.0.0"),
+		FirewallMark: fwmAllocBase,
+		Scheduler:    "wrr",
+	}
+	if err := backend.AddService(orphan); err != nil {
+		t.Fatalf("Failed to add %v: %v", orphan, err)
+	}
+
+	// A vserver that never drains its reconcile channel does not claim its
+	// services, which must not be removed as a result.
+	v.reconcile <- newReconcileRequest(nil)
+	e := r.engine
+	r.dispatch = e.distributeReconcile
+	r.reconcile()
+
+	status := r.status()
+	if status.LastError == "" {
+		t.Errorf("Reconciliation succeeded with a skipped vserver")
+	}
+	if status.LastCorrections != 0 {
+		t.Errorf("Got %d corrections, want 0", status.LastCorrections)
+	}
+	for _, w := range want {
+		if _, err := backend.GetService(*w); err != nil {
+			t.Errorf("IPVS service %v was removed after vserver was skipped", w)
+		}
+	}
+	if _, err := backend.GetService(orphan); err != nil {
+		t.Errorf("Unconfigured service %v was removed after vserver was skipped", orphan)
+	}
+}
+
+func TestReconcileNoCluster(t *testing.T) {
+	backend := ipvs.NewDummyBackend()
+	r, _ := newTestReconciler(backend)
+	r.engine.cluster = nil
+	if err := backend.Flush(); err != nil {
+		t.Fatalf("Failed to flush IPVS table: %v", err)
+	}
+	r.reconcile()
+	if svcs := backend.Snapshot(); len(svcs) != 0 {
+		t.Errorf("Got %d IPVS services without a cluster configuration, want 0", len(svcs))
+	}
+}
+
+func TestOwnsIPVSService(t *testing.T) {
+	vsConfig := vserverConfig
+	vsConfig.VIPs = map[string]*seesaw.VIP{
+		"192.168.255.2": {IP: seesaw.ParseIP("192.168.255.2"), Type: seesaw.UnicastVIP},
+	}
+	cluster := config.NewCluster("au-syd")
+	cluster.Vservers[vsConfig.Name] = &vsConfig
+
+	tests := []struct {
+		svc  ipvs.Service
+		want bool
+	}{
+		{ipvs.Service{Address: net.ParseIP("192.168.255.1"), Port: 53}, true},
+		{ipvs.Service{Address: net.ParseIP("2012::1"), Port: 53}, true},
+		{ipvs.Service{Address: net.ParseIP("192.168.255.2"), Port: 80}, true},
+		{ipvs.Service{Address: net.ParseIP("192.168.255.3"), Port: 80}, false},
+		{ipvs.Service{FirewallMark: fwmAllocBase}, true},
+		{ipvs.Service{FirewallMark: fwmAllocBase + fwmAllocSize - 1}, true},
+		{ipvs.Service{FirewallMark: fwmAllocBase + fwmAllocSize}, false},
+		{ipvs.Service{FirewallMark: 1}, false},
+		{ipvs.Service{FirewallMark: dsrMarkBase + 1}, false},
+	}
+	for _, test := range tests {
+		if got := ownsIPVSService(&test.svc, cluster); got != test.want {
+			t.Errorf("ownsIPVSService(%v) = %v, want %v", &test.svc, got, test.want)
+		}
+	}
+}
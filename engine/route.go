@@ -0,0 +1,269 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+// This file contains structures and functions to advertise routes while the
+// Seesaw Engine is the HA leader.
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/google/seesaw/common/seesaw"
+	"github.com/google/seesaw/engine/config"
+	ncclient "github.com/google/seesaw/ncc/client"
+
+	log "github.com/golang/glog"
+)
+
+// RouteAdvertiser advertises and withdraws routes for networks.
+type RouteAdvertiser interface {
+	Advertise(network *net.IPNet) error
+	Withdraw(network *net.IPNet) error
+}
+
+// nccRouteAdvertiser is a RouteAdvertiser that uses the NCC to advertise
+// routes via the Quagga BGP daemon.
+type nccRouteAdvertiser struct {
+	ncc ncclient.NCC
+}
+
+// Advertise advertises a route for the given network.
+func (a *nccRouteAdvertiser) Advertise(network *net.IPNet) error {
+	return a.ncc.BGPAdvertiseNetwork(network)
+}
+
+// Withdraw withdraws the route for the given network.
+func (a *nccRouteAdvertiser) Withdraw(network *net.IPNet) error {
+	return a.ncc.BGPWithdrawNetwork(network)
+}
+
+// route contains the running state for a configured route.
+type route struct {
+	config *config.Route
+	status seesaw.RouteStatus
+}
+
+// routeManager advertises the configured routes while the engine is the HA
+// leader, retrying failed advertisements and withdrawals.
+type routeManager struct {
+	engine     *Engine
+	advertiser RouteAdvertiser
+	interval   time.Duration
+	update     chan bool
+
+	// advertLock serialises calls to the advertiser, so that routes are not
+	// advertised while withdrawAll is withdrawing them.
+	advertLock sync.Mutex
+
+	// withdrawing, if set, is called by withdrawAll once leadership has been
+	// relinquished, before waiting for any in-progress advertisement.
+	withdrawing func()
+
+	lock   sync.RWMutex
+	leader bool
+	routes []*route
+}
+
+// newRouteManager returns an initialised routeManager struct.
+func newRouteManager(engine *Engine, advertiser RouteAdvertiser) *routeManager {
+	rm := &routeManager{
+		engine:     engine,
+		advertiser: advertiser,
+		interval:   engine.config.RouteRetryInterval,
+		update:     make(chan bool, 1),
+	}
+	for _, r := range engine.config.Routes {
+		rm.routes = append(rm.routes, &route{
+			config: r,
+			status: seesaw.RouteStatus{Network: r.Network},
+		})
+	}
+	return rm
+}
+
+// run runs the route manager.
+func (rm *routeManager) run() {
+	if len(rm.routes) == 0 {
+		return
+	}
+
+	// Withdraw any routes that may remain from a previous instance.
+	for _, r := range rm.routes {
+		if err := rm.advertiser.Withdraw(&r.config.Network); err != nil {
+			log.Warningf("Failed to withdraw route for %v: %v", &r.config.Network, err)
+		}
+	}
+
+	ticker := time.NewTicker(rm.interval)
+	for {
+		rm.updateRoutes()
+		select {
+		case <-ticker.C:
+		case <-rm.update:
+		}
+	}
+}
+
+// setLeader sets whether the engine is the HA leader and triggers an update
+// of the advertised routes.
+func (rm *routeManager) setLeader(leader bool) {
+	rm.lock.Lock()
+	rm.leader = leader
+	rm.lock.Unlock()
+	rm.triggerUpdate()
+}
+
+// triggerUpdate triggers an update of the advertised routes.
+func (rm *routeManager) triggerUpdate() {
+	select {
+	case rm.update <- true:
+	default:
+	}
+}
+
+// healthyVIPs returns the number of VIPs within the given network that have
+// at least one active service.
+func (rm *routeManager) healthyVIPs(network *net.IPNet) int {
+	vips := make(map[string]bool)
+	rm.engine.vserverLock.RLock()
+	defer rm.engine.vserverLock.RUnlock()
+	for _, vs := range rm.engine.vserverSnapshots {
+		for _, svc := range vs.Services {
+			if svc.Active && network.Contains(svc.IP) {
+				vips[svc.IP.String()] = true
+			}
+		}
+	}
+	return len(vips)
+}
+
+// wanted returns true if the given route should currently be advertised.
+func (rm *routeManager) wanted(r *config.Route, leader bool) bool {
+	if !leader {
+		return false
+	}
+	if r.Anycast && !rm.engine.config.AnycastEnabled {
+		return false
+	}
+	if r.MinHealthy > 0 && rm.healthyVIPs(&r.Network) < r.MinHealthy {
+		return false
+	}
+	return true
+}
+
+// updateRoutes advertises or withdraws routes as necessary. Failures are
+// recorded in the route status and retried on the next update.
+func (rm *routeManager) updateRoutes() {
+	for _, r := range rm.routes {
+		rm.updateRoute(r)
+	}
+}
+
+// updateRoute advertises or withdraws the given route as necessary.
+func (rm *routeManager) updateRoute(r *route) {
+	rm.advertLock.Lock()
+	defer rm.advertLock.Unlock()
+
+	rm.lock.RLock()
+	leader := rm.leader
+	rm.lock.RUnlock()
+	wanted := rm.wanted(r.config, leader)
+
+	rm.lock.Lock()
+	r.status.Wanted = wanted
+	advertised := r.status.Advertised
+	if wanted == advertised {
+		r.status.Failures = 0
+		r.status.LastError = ""
+	}
+	rm.lock.Unlock()
+	if wanted == advertised {
+		return
+	}
+
+	var err error
+	op := "advertise"
+	if wanted {
+		log.Infof("Advertising route for %v", &r.config.Network)
+		err = rm.advertiser.Advertise(&r.config.Network)
+	} else {
+		op = "withdraw"
+		log.Infof("Withdrawing route for %v", &r.config.Network)
+		err = rm.advertiser.Withdraw(&r.config.Network)
+	}
+
+	rm.lock.Lock()
+	defer rm.lock.Unlock()
+	if err != nil {
+		r.status.Failures++
+		r.status.LastError = err.Error()
+		log.Errorf("Failed to %s route for %v (%d failures): %v", op, &r.config.Network, r.status.Failures, err)
+		return
+	}
+	r.status.Advertised = wanted
+	r.status.Failures = 0
+	r.status.LastError = ""
+	r.status.LastChange = time.Now()
+}
+
+// withdrawAll withdraws all advertised routes, as is required when the engine
+// is shutting down. Routes are not advertised again after it returns.
+func (rm *routeManager) withdrawAll() {
+	rm.lock.Lock()
+	rm.leader = false
+	rm.lock.Unlock()
+	if rm.withdrawing != nil {
+		rm.withdrawing()
+	}
+
+	// Wait for any in-progress advertisement to complete, so that the route
+	// is seen as advertised and withdrawn below.
+	rm.advertLock.Lock()
+	defer rm.advertLock.Unlock()
+
+	var advertised []*route
+	rm.lock.RLock()
+	for _, r := range rm.routes {
+		if r.status.Advertised {
+			advertised = append(advertised, r)
+		}
+	}
+	rm.lock.RUnlock()
+
+	for _, r := range advertised {
+		if err := rm.advertiser.Withdraw(&r.config.Network); err != nil {
+			log.Errorf("Failed to withdraw route for %v: %v", &r.config.Network, err)
+			continue
+		}
+		rm.lock.Lock()
+		r.status.Advertised = false
+		r.status.LastChange = time.Now()
+		rm.lock.Unlock()
+	}
+}
+
+// status returns the current status of the configured routes.
+func (rm *routeManager) status() []*seesaw.RouteStatus {
+	rm.lock.RLock()
+	defer rm.lock.RUnlock()
+	routes := make([]*seesaw.RouteStatus, 0, len(rm.routes))
+	for _, r := range rm.routes {
+		s := r.status
+		routes = append(routes, &s)
+	}
+	return routes
+}
@@ -0,0 +1,216 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/google/seesaw/common/seesaw"
+	"github.com/google/seesaw/engine/config"
+)
+
+// recordingAdvertiser is a RouteAdvertiser that records the operations
+// performed and fails on demand.
+type recordingAdvertiser struct {
+	ops  []string
+	fail bool
+}
+
+func (a *recordingAdvertiser) Advertise(network *net.IPNet) error {
+	if a.fail {
+		return errors.New("advertise failed")
+	}
+	a.ops = append(a.ops, "advertise "+network.String())
+	return nil
+}
+
+func (a *recordingAdvertiser) Withdraw(network *net.IPNet) error {
+	if a.fail {
+		return errors.New("withdraw failed")
+	}
+	a.ops = append(a.ops, "withdraw "+network.String())
+	return nil
+}
+
+func (a *recordingAdvertiser) reset() []string {
+	ops := a.ops
+	a.ops = nil
+	return ops
+}
+
+// blockingAdvertiser is a recordingAdvertiser that blocks advertisements
+// until released.
+type blockingAdvertiser struct {
+	recordingAdvertiser
+	started chan bool
+	release chan bool
+}
+
+func (a *blockingAdvertiser) Advertise(network *net.IPNet) error {
+	a.started <- true
+	<-a.release
+	return a.recordingAdvertiser.Advertise(network)
+}
+
+func newTestRouteManager(t *testing.T, routes ...string) (*Engine, *recordingAdvertiser) {
+	e := newTestEngine()
+	for _, s := range routes {
+		r, err := config.ParseRoute(s)
+		if err != nil {
+			t.Fatalf("ParseRoute(%q) failed: %v", s, err)
+		}
+		e.config.Routes = append(e.config.Routes, r)
+	}
+	a := &recordingAdvertiser{}
+	e.routeManager = newRouteManager(e, a)
+	return e, a
+}
+
+func setActiveVIPs(e *Engine, vips ...string) {
+	vs := &seesaw.Vserver{
+		Name:     "vserver1",
+		Services: make(map[seesaw.ServiceKey]*seesaw.Service),
+	}
+	for i, vip := range vips {
+		key := seesaw.ServiceKey{AF: seesaw.IPv4, Proto: seesaw.IPProtoTCP, Port: uint16(80 + i)}
+		vs.Services[key] = &seesaw.Service{ServiceKey: key, IP: net.ParseIP(vip), Active: true}
+	}
+	e.vserverLock.Lock()
+	e.vserverSnapshots[vs.Name] = vs
+	e.vserverLock.Unlock()
+}
+
+func checkOps(t *testing.T, desc string, a *recordingAdvertiser, want ...string) {
+	t.Helper()
+	if got := a.reset(); !reflect.DeepEqual(got, want) {
+		t.Errorf("%s: got operations %q, want %q", desc, got, want)
+	}
+}
+
+func TestRouteTransitions(t *testing.T) {
+	e, a := newTestRouteManager(t, "10.1.0.0/24", "10.2.0.0/24")
+	rm := e.routeManager
+
+	rm.updateRoutes()
+	checkOps(t, "initial", a)
+
+	rm.setLeader(true)
+	rm.updateRoutes()
+	checkOps(t, "leader", a, "advertise 10.1.0.0/24", "advertise 10.2.0.0/24")
+
+	rm.updateRoutes()
+	checkOps(t, "leader again", a)
+
+	rm.setLeader(false)
+	rm.updateRoutes()
+	checkOps(t, "backup", a, "withdraw 10.1.0.0/24", "withdraw 10.2.0.0/24")
+
+	rm.setLeader(true)
+	rm.updateRoutes()
+	checkOps(t, "leader after backup", a, "advertise 10.1.0.0/24", "advertise 10.2.0.0/24")
+
+	rm.withdrawAll()
+	checkOps(t, "shutdown", a, "withdraw 10.1.0.0/24", "withdraw 10.2.0.0/24")
+	rm.updateRoutes()
+	checkOps(t, "after shutdown", a)
+}
+
+func TestRouteConditions(t *testing.T) {
+	e, a := newTestRouteManager(t, "10.1.0.0/24 min_healthy=2", "10.2.0.0/24 anycast")
+	rm := e.routeManager
+
+	e.config.AnycastEnabled = false
+	setActiveVIPs(e, "10.1.0.1")
+	rm.setLeader(true)
+	rm.updateRoutes()
+	checkOps(t, "one healthy VIP", a)
+
+	setActiveVIPs(e, "10.1.0.1", "10.1.0.2", "10.2.0.1")
+	rm.updateRoutes()
+	checkOps(t, "two healthy VIPs", a, "advertise 10.1.0.0/24")
+
+	e.config.AnycastEnabled = true
+	rm.updateRoutes()
+	checkOps(t, "anycast enabled", a, "advertise 10.2.0.0/24")
+
+	setActiveVIPs(e, "10.1.0.1", "10.2.0.1")
+	rm.updateRoutes()
+	checkOps(t, "VIP unhealthy", a, "withdraw 10.1.0.0/24")
+}
+
+func TestRouteFailures(t *testing.T) {
+	e, a := newTestRouteManager(t, "10.1.0.0/24")
+	rm := e.routeManager
+
+	a.fail = true
+	rm.setLeader(true)
+	rm.updateRoutes()
+	rm.updateRoutes()
+	status := rm.status()
+	if len(status) != 1 {
+		t.Fatalf("Got %d route statuses, want 1", len(status))
+	}
+	if s := status[0]; !s.Wanted || s.Advertised || s.Failures != 2 || s.LastError != "advertise failed" {
+		t.Errorf("Got route status %+v after failures", s)
+	}
+
+	a.fail = false
+	rm.updateRoutes()
+	checkOps(t, "retry", a, "advertise 10.1.0.0/24")
+	if s := rm.status()[0]; !s.Advertised || s.Failures != 0 || s.LastError != "" || s.LastChange.IsZero() {
+		t.Errorf("Got route status %+v after retry", s)
+	}
+}
+
+func TestRouteWithdrawDuringAdvertise(t *testing.T) {
+	e, _ := newTestRouteManager(t, "10.1.0.0/24", "10.2.0.0/24")
+	a := &blockingAdvertiser{started: make(chan bool, 2), release: make(chan bool)}
+	rm := newRouteManager(e, a)
+
+	rm.setLeader(true)
+	updated := make(chan bool)
+	go func() {
+		rm.updateRoutes()
+		updated <- true
+	}()
+	<-a.started
+
+	// Release the advertisement only after withdrawAll has relinquished
+	// leadership and is waiting for it.
+	notLeader := make(chan bool)
+	rm.withdrawing = func() { close(notLeader) }
+	withdrawn := make(chan bool)
+	go func() {
+		rm.withdrawAll()
+		withdrawn <- true
+	}()
+	<-notLeader
+	if s := rm.status(); len(s) != 2 {
+		t.Fatalf("Got %d route statuses, want 2", len(s))
+	}
+
+	close(a.release)
+	<-updated
+	<-withdrawn
+	checkOps(t, "withdraw during advertise", &a.recordingAdvertiser, "advertise 10.1.0.0/24", "withdraw 10.1.0.0/24")
+	for _, s := range rm.status() {
+		if s.Advertised {
+			t.Errorf("Route for %v is advertised after withdrawal", &s.Network)
+		}
+	}
+}
@@ -47,6 +47,8 @@ const (
 	syncPollMsgLimit = 100
 	syncPollTimeout  = 30 * time.Second
 	syncRPCTimeout   = 10 * time.Second
+
+	syncSnapshotChunkSize = 500
 )
 
 // SyncSessionID specifies a synchronisation session identifier.
@@ -62,6 +64,7 @@ const (
 	SNTConfigUpdate
 	SNTHealthcheck
 	SNTOverride
+	SNTSnapshot
 )
 
 var syncNoteTypeNames = map[SyncNoteType]string{
@@ -70,6 +73,7 @@ var syncNoteTypeNames = map[SyncNoteType]string{
 	SNTConfigUpdate: "Config Update",
 	SNTHealthcheck:  "Healthcheck",
 	SNTOverride:     "Override",
+	SNTSnapshot:     "Snapshot",
 }
 
 // String returns the string representation of a synchronisation notification
@@ -81,17 +85,63 @@ func (snt SyncNoteType) String() string {
 	return fmt.Sprintf("(Unknown %d)", snt)
 }
 
-// SyncNote represents a synchronisation notification.
+// SyncNote represents a synchronisation notification. Notes that change
+// state are assigned an increasing sequence number by the synchronisation
+// server, while heartbeats, desyncs and snapshots have a sequence number of
+// zero.
 type SyncNote struct {
 	Type SyncNoteType
 	Time time.Time
+	Seq  uint64
 
 	Config      *config.Notification
 	Healthcheck *SyncHealthCheckNotification
+	Snapshot    *SyncSnapshot
 
 	BackendOverride     *seesaw.BackendOverride
 	DestinationOverride *seesaw.DestinationOverride
 	VserverOverride     *seesaw.VserverOverride
+	PassiveOverride     *seesaw.PassiveOverride
+}
+
+// SyncSnapshot contains a consistent copy of the state of a Seesaw Engine,
+// which is used to bootstrap a synchronisation session. A snapshot is sent
+// as one or more chunks, with the healthcheck states being split across the
+// chunks and all other state being carried by the first chunk. Notes with a
+// sequence number less than or equal to Seq are reflected in the snapshot.
+type SyncSnapshot struct {
+	Seq    uint64
+	Chunk  int
+	Chunks int
+
+	Cluster     *config.Cluster
+	ConfigEpoch time.Time
+	HAStatus    seesaw.HAStatus
+
+	BackendOverrides     []*seesaw.BackendOverride
+	DestinationOverrides []*seesaw.DestinationOverride
+	VserverOverrides     []*seesaw.VserverOverride
+	PassiveOverrides     []*seesaw.PassiveOverride
+
+	Healthchecks []*SyncHealthCheckNotification
+}
+
+// overrides returns the overrides contained in the snapshot.
+func (ss *SyncSnapshot) overrides() []seesaw.Override {
+	var overrides []seesaw.Override
+	for _, o := range ss.BackendOverrides {
+		overrides = append(overrides, o)
+	}
+	for _, o := range ss.DestinationOverrides {
+		overrides = append(overrides, o)
+	}
+	for _, o := range ss.VserverOverrides {
+		overrides = append(overrides, o)
+	}
+	for _, o := range ss.PassiveOverrides {
+		overrides = append(overrides, o)
+	}
+	return overrides
 }
 
 // SyncNotes specifies a collection of SyncNotes.
@@ -154,7 +204,8 @@ func (s *SeesawSync) Poll(id SyncSessionID, sn *SyncNotes) error {
 	// Reset expiry time and check for desynchronisation.
 	session.Lock()
 	session.expiryTime = time.Now().Add(sessionDeadtime)
-	if session.desync {
+	desync := session.desync
+	if desync {
 		// Drain stale notes before sending desync notification.
 		for {
 			select {
@@ -164,9 +215,34 @@ func (s *SeesawSync) Poll(id SyncSessionID, sn *SyncNotes) error {
 			}
 		}
 	drained:
-		sn.Notes = append(sn.Notes, SyncNote{Type: SNTDesync, Time: time.Now()})
 		session.desync = false
+	}
+	session.Unlock()
+
+	// Follow a desync notification with a snapshot of the current state.
+	// The snapshot is taken without holding the session lock, since notes
+	// may be queued for the session while the engine state is captured.
+	if desync {
+		sn.Notes = append(sn.Notes, SyncNote{Type: SNTDesync, Time: time.Now()})
+		snapshot := s.sync.snapshot()
+		session.Lock()
+		session.snapshot = snapshot
+		session.Unlock()
+	}
+
+	// Any outstanding snapshot chunks are sent before queued notes.
+	session.Lock()
+	if len(session.snapshot) > 0 {
+		n := len(session.snapshot)
+		if n > syncPollMsgLimit {
+			n = syncPollMsgLimit
+		}
+		for _, note := range session.snapshot[:n] {
+			sn.Notes = append(sn.Notes, *note)
+		}
+		session.snapshot = session.snapshot[n:]
 		session.Unlock()
+		log.V(1).Infof("Sync server poll returning %d notifications", len(sn.Notes))
 		return nil
 	}
 	session.Unlock()
@@ -223,6 +299,7 @@ type syncSession struct {
 	desync     bool
 	startTime  time.Time
 	expiryTime time.Time
+	snapshot   []*SyncNote
 	sync.RWMutex
 
 	notes chan *SyncNote
@@ -249,6 +326,10 @@ type syncServer struct {
 	engine            *Engine
 	heartbeatInterval time.Duration
 	server            *rpc.Server
+	snapshotChunkSize int
+
+	seq     uint64
+	seqLock sync.Mutex
 
 	sessionLock   sync.RWMutex
 	nextSessionID SyncSessionID
@@ -260,6 +341,7 @@ func newSyncServer(e *Engine) *syncServer {
 	return &syncServer{
 		engine:            e,
 		heartbeatInterval: syncHeartbeatInterval,
+		snapshotChunkSize: syncSnapshotChunkSize,
 		sessions:          make(map[SyncSessionID]*syncSession),
 	}
 }
@@ -319,9 +401,16 @@ func (s *syncServer) serve(l net.Listener) error {
 	}
 }
 
-// notify queues a synchronisation notification with each of the active
-// synchronisation sessions.
+// notify assigns the next sequence number to a synchronisation notification
+// and queues it with each of the active synchronisation sessions. The state
+// change described by the notification must already have been applied, so
+// that any snapshot taken after the sequence number has been assigned
+// reflects the change.
 func (s *syncServer) notify(sn *SyncNote) {
+	s.seqLock.Lock()
+	defer s.seqLock.Unlock()
+	s.seq++
+	sn.Seq = s.seq
 	s.sessionLock.RLock()
 	sessions := s.sessions
 	s.sessionLock.RUnlock()
@@ -330,6 +419,71 @@ func (s *syncServer) notify(sn *SyncNote) {
 	}
 }
 
+// snapshot captures the current state of the engine and returns it as a
+// series of snapshot notes. Every note sequenced before the snapshot is
+// reflected in it, while notes sequenced after it may also be reflected -
+// applying these again on the client is harmless.
+func (s *syncServer) snapshot() []*SyncNote {
+	s.seqLock.Lock()
+	seq := s.seq
+	s.seqLock.Unlock()
+
+	e := s.engine
+	ss := &SyncSnapshot{
+		Seq:      seq,
+		HAStatus: e.haStatus(),
+	}
+
+	e.clusterLock.RLock()
+	ss.Cluster = e.cluster
+	e.clusterLock.RUnlock()
+	if ss.Cluster != nil {
+		ss.ConfigEpoch = ss.Cluster.Status.LastUpdate
+	}
+
+	e.overridesLock.RLock()
+	for _, override := range e.overrides {
+		switch o := override.(type) {
+		case *seesaw.BackendOverride:
+			ss.BackendOverrides = append(ss.BackendOverrides, o)
+		case *seesaw.DestinationOverride:
+			ss.DestinationOverrides = append(ss.DestinationOverrides, o)
+		case *seesaw.VserverOverride:
+			ss.VserverOverrides = append(ss.VserverOverrides, o)
+		case *seesaw.PassiveOverride:
+			ss.PassiveOverrides = append(ss.PassiveOverrides, o)
+		}
+	}
+	e.overridesLock.RUnlock()
+
+	hcs := e.hcManager.syncStates()
+	chunks := (len(hcs) + s.snapshotChunkSize - 1) / s.snapshotChunkSize
+	if chunks == 0 {
+		chunks = 1
+	}
+
+	now := time.Now()
+	notes := make([]*SyncNote, 0, chunks)
+	for i := 0; i < chunks; i++ {
+		chunk := ss
+		if i > 0 {
+			chunk = &SyncSnapshot{Seq: seq}
+		}
+		chunk.Chunk = i
+		chunk.Chunks = chunks
+		n := len(hcs)
+		if n > s.snapshotChunkSize {
+			n = s.snapshotChunkSize
+		}
+		chunk.Healthchecks = hcs[:n]
+		hcs = hcs[n:]
+		notes = append(notes, &SyncNote{Type: SNTSnapshot, Time: now, Snapshot: chunk})
+	}
+	log.Infof("Sync server created snapshot at sequence %d with %d chunks", seq, chunks)
+
+	return notes
+}
+
 // run runs the synchronisation server, which is responsible for queueing
 // heartbeat notifications and removing expired synchronisation sessions.
 func (s *syncServer) run() {
@@ -361,6 +515,10 @@ type syncClient struct {
 	refs    uint
 	lock    sync.Mutex
 
+	// Accessed only by the polling goroutine.
+	seq      uint64
+	snapshot *SyncSnapshot
+
 	quit    chan bool
 	start   chan bool
 	stopped chan bool
@@ -522,6 +680,11 @@ func (sc *syncClient) poll(sid SyncSessionID) bool {
 
 // handleNote dispatches a synchronisation note to the appropriate handler.
 func (sc *syncClient) handleNote(note *SyncNote) {
+	if sc.applied(note) {
+		log.V(1).Infof("Sync client skipping %v notification %d, already reflected in snapshot", note.Type, note.Seq)
+		return
+	}
+
 	switch note.Type {
 	case SNTHeartbeat:
 		log.V(1).Infoln("Sync client received heartbeat")
@@ -538,18 +701,71 @@ func (sc *syncClient) handleNote(note *SyncNote) {
 	case SNTOverride:
 		sc.handleOverride(note)
 
+	case SNTSnapshot:
+		sc.handleSnapshot(note)
+
 	default:
 		log.Errorf("Unable to handle sync notification type %s (%d)", note.Type, note.Type)
 	}
 }
 
-// handleDesync handles a desync notification by triggering a config reload.
-// Healthcheck state naturally re-converges via the healthcheck polling cycle.
+// applied returns true if the state change described by the given note has
+// already been applied via a snapshot.
+func (sc *syncClient) applied(note *SyncNote) bool {
+	return note.Seq != 0 && note.Seq <= sc.seq
+}
+
+// handleDesync handles a desync notification by discarding any partially
+// received snapshot. The server follows the desync with a new snapshot.
 func (sc *syncClient) handleDesync() {
-	log.Infof("Sync client desynchronised, triggering config reload")
-	if err := sc.engine.notifier.Reload(); err != nil {
-		log.Warningf("Config reload after desync failed: %v", err)
+	log.Infof("Sync client desynchronised, awaiting snapshot")
+	sc.snapshot = nil
+	sc.seq = 0
+}
+
+// addSnapshotChunk adds a snapshot chunk to the snapshot that is being
+// received, returning the snapshot once all of its chunks have been received.
+func (sc *syncClient) addSnapshotChunk(chunk *SyncSnapshot) (*SyncSnapshot, error) {
+	switch {
+	case chunk.Chunk == 0:
+		ss := *chunk
+		sc.snapshot = &ss
+	case sc.snapshot == nil:
+		return nil, fmt.Errorf("received snapshot chunk %d/%d without chunk 0", chunk.Chunk, chunk.Chunks)
+	case chunk.Seq != sc.snapshot.Seq || chunk.Chunk != sc.snapshot.Chunk+1:
+		sc.snapshot = nil
+		return nil, fmt.Errorf("received out of order snapshot chunk %d/%d", chunk.Chunk, chunk.Chunks)
+	default:
+		sc.snapshot.Chunk = chunk.Chunk
+		sc.snapshot.Healthchecks = append(sc.snapshot.Healthchecks, chunk.Healthchecks...)
+	}
+	if sc.snapshot.Chunk < sc.snapshot.Chunks-1 {
+		return nil, nil
+	}
+	ss := sc.snapshot
+	sc.snapshot = nil
+	return ss, nil
+}
+
+// handleSnapshot handles a snapshot notification. Once the complete snapshot
+// has been received it is queued for the engine to apply, after which
+// notifications that are already reflected in the snapshot are skipped.
+func (sc *syncClient) handleSnapshot(sn *SyncNote) {
+	if sn.Snapshot == nil {
+		log.Errorf("Sync client received snapshot notification without snapshot")
+		return
 	}
+	ss, err := sc.addSnapshotChunk(sn.Snapshot)
+	if err != nil {
+		log.Errorf("Sync client failed to receive snapshot: %v", err)
+		return
+	}
+	if ss == nil {
+		return
+	}
+	log.Infof("Sync client received snapshot at sequence %d", ss.Seq)
+	sc.seq = ss.Seq
+	sc.engine.syncSnapshotChan <- ss
 }
 
 // handleConfigUpdate handles a config update notification by triggering a
@@ -579,6 +795,9 @@ func (sc *syncClient) handleOverride(sn *SyncNote) {
 	if o := sn.BackendOverride; o != nil {
 		sc.engine.queueOverride(o)
 	}
+	if o := sn.PassiveOverride; o != nil {
+		sc.engine.queueOverride(o)
+	}
 }
 
 // run runs the synchronisation client.
@@ -30,10 +30,14 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/google/seesaw/common/seesaw"
+	"github.com/google/seesaw/engine/config"
+	"github.com/google/seesaw/healthcheck"
 	spb "github.com/google/seesaw/pb/seesaw"
 )
 
@@ -202,6 +206,15 @@ func TestBasicSync(t *testing.T) {
 		t.Fatalf("Initial note type = %v, want %v", n.Type, SNTDesync)
 	}
 
+	// The desync should be followed by a snapshot.
+	n, err = dispatcher.nextNote()
+	if err != nil {
+		t.Fatalf("Expected initial snapshot, got error: %v", err)
+	}
+	if n.Type != SNTSnapshot {
+		t.Fatalf("Second note type = %v, want %v", n.Type, SNTSnapshot)
+	}
+
 	// Send a notification for each sync note type.
 	for nt := range syncNoteTypeNames {
 		server.notify(&SyncNote{Type: nt})
@@ -227,14 +240,16 @@ func TestSyncHeartbeats(t *testing.T) {
 	go client.run()
 
 	// Enabling briefly shouldn't have time to get a heartbeat, but should get
-	// the initial desync.
+	// the initial desync and snapshot.
 	client.enable()
-	// Make sure we can read the desync (and flush it so the later client doesn't
-	// see it).
-	if n, err := dispatcher.nextNote(); err != nil || n.Type != SNTDesync {
-		t.Errorf("During short enablement, nextNote() = %v, %v; expected desync", n, err)
-	} else {
-		t.Logf("Got short note: %v, %v", n, err)
+	// Make sure we can read the desync and snapshot (and flush them so the
+	// later client doesn't see them).
+	for _, nt := range []SyncNoteType{SNTDesync, SNTSnapshot} {
+		if n, err := dispatcher.nextNote(); err != nil || n.Type != nt {
+			t.Errorf("During short enablement, nextNote() = %v, %v; expected %v", n, err, nt)
+		} else {
+			t.Logf("Got short note: %v, %v", n, err)
+		}
 	}
 	client.disable()
 
@@ -247,7 +262,7 @@ func TestSyncHeartbeats(t *testing.T) {
 	// should make sure we receive the ones we had queued).
 	time.Sleep(server.heartbeatInterval + 50*time.Millisecond)
 
-	wantNotes := []SyncNoteType{SNTDesync, SNTHeartbeat, SNTHeartbeat}
+	wantNotes := []SyncNoteType{SNTDesync, SNTSnapshot, SNTHeartbeat, SNTHeartbeat}
 	for _, nt := range wantNotes {
 		n, err := dispatcher.nextNote()
 		if err != nil || n.Type != nt {
@@ -306,6 +321,9 @@ func TestSyncDesync(t *testing.T) {
 	if n.Type != SNTDesync {
 		t.Fatalf("Got %v, want %v", n.Type, SNTDesync)
 	}
+	if n, err := dispatcher.nextNote(); err != nil || n.Type != SNTSnapshot {
+		t.Fatalf("nextNote() = %v, %v; want %v", n, err, SNTSnapshot)
+	}
 
 	// Send a single notification to complete the poll.
 	server.notify(&SyncNote{Type: SNTHeartbeat})
@@ -347,3 +365,95 @@ noteLoop:
 		t.Errorf("While waiting for desync, received: %v; expected 1 Heartbeat", received)
 	}
 }
+
+func TestSyncSnapshot(t *testing.T) {
+	ln, client, server, dispatcher, err := newSyncTest(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	// Build up state on the leader, including notifications that are sent
+	// before the client attaches.
+	leader := server.engine
+	cluster := config.NewCluster("au-syd")
+	cluster.Status.LastUpdate = time.Unix(1500000000, 0)
+	leader.cluster = cluster
+	leader.handleOverride(&seesaw.BackendOverride{Hostname: "backend1.example.com", OverrideState: seesaw.OverrideDisable})
+	leader.handleOverride(&seesaw.VserverOverride{VserverName: "vserver1", OverrideState: seesaw.OverrideEnable})
+	server.notify(&SyncNote{Type: SNTOverride})
+	server.notify(&SyncNote{Type: SNTOverride})
+	for i := 0; i < 5; i++ {
+		key := CheckKey{
+			BackendIP:       seesaw.ParseIP(fmt.Sprintf("10.0.1.%d", i+1)),
+			HealthcheckType: seesaw.HCTypeTCP,
+			HealthcheckPort: 80,
+		}
+		leader.hcManager.states[key] = healthcheck.Status{State: healthcheck.StateHealthy, Successes: uint64(i)}
+	}
+	server.snapshotChunkSize = 2
+
+	// The follower starts with stale state that is not present on the leader.
+	follower := newTestEngine()
+	follower.handleOverride(&seesaw.VserverOverride{VserverName: "vserver2", OverrideState: seesaw.OverrideDisable})
+	follower.hcManager.states[CheckKey{BackendIP: seesaw.ParseIP("10.0.2.1")}] = healthcheck.Status{State: healthcheck.StateUnhealthy}
+	fc := newSyncClient(follower)
+
+	go client.runOnce()
+	defer func() { client.quit <- true }()
+
+	if n, err := dispatcher.nextNote(); err != nil || n.Type != SNTDesync {
+		t.Fatalf("nextNote() = %v, %v; want %v", n, err, SNTDesync)
+	}
+	for i := 0; i < 3; i++ {
+		n, err := dispatcher.nextNote()
+		if err != nil || n.Type != SNTSnapshot {
+			t.Fatalf("nextNote() = %v, %v; want %v", n, err, SNTSnapshot)
+		}
+		if n.Snapshot.Chunk != i || n.Snapshot.Chunks != 3 {
+			t.Errorf("Got snapshot chunk %d/%d, want %d/3", n.Snapshot.Chunk, n.Snapshot.Chunks, i)
+		}
+		fc.handleNote(n)
+	}
+
+	var ss *SyncSnapshot
+	select {
+	case ss = <-follower.syncSnapshotChan:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for snapshot")
+	}
+	if ss.Seq != 2 {
+		t.Errorf("Snapshot sequence = %d, want 2", ss.Seq)
+	}
+	if !ss.ConfigEpoch.Equal(cluster.Status.LastUpdate) {
+		t.Errorf("Snapshot config epoch = %v, want %v", ss.ConfigEpoch, cluster.Status.LastUpdate)
+	}
+	if ss.HAStatus.State != spb.HaState_LEADER {
+		t.Errorf("Snapshot HA state = %v, want %v", ss.HAStatus.State, spb.HaState_LEADER)
+	}
+	follower.applySyncSnapshot(ss)
+
+	// The follower should have converged from the snapshot alone.
+	if !reflect.DeepEqual(follower.overrides, leader.overrides) {
+		t.Errorf("Follower overrides = %v, want %v", follower.overrides, leader.overrides)
+	}
+	if !reflect.DeepEqual(follower.hcManager.states, leader.hcManager.states) {
+		t.Errorf("Follower healthcheck states = %v, want %v", follower.hcManager.states, leader.hcManager.states)
+	}
+
+	// Notifications reflected in the snapshot are skipped, while subsequent
+	// notifications are processed.
+	for _, seq := range []uint64{1, 2} {
+		if !fc.applied(&SyncNote{Type: SNTOverride, Seq: seq}) {
+			t.Errorf("Notification %d not reflected in snapshot at sequence %d", seq, ss.Seq)
+		}
+	}
+	server.notify(&SyncNote{Type: SNTConfigUpdate})
+	n, err := dispatcher.nextNote()
+	if err != nil || n.Type != SNTConfigUpdate {
+		t.Fatalf("nextNote() = %v, %v; want %v", n, err, SNTConfigUpdate)
+	}
+	if n.Seq != 3 || fc.applied(n) {
+		t.Errorf("Notification %d after snapshot at sequence %d would be skipped", n.Seq, ss.Seq)
+	}
+}
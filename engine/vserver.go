@@ -50,12 +50,14 @@ type vserver struct {
 	vips       map[seesaw.VIP]bool           // unicast VIPs
 
 	vserverOverride seesaw.VserverOverride
+	passiveOverride seesaw.PassiveOverride
 	overrideChan    chan seesaw.Override
 
-	notify  chan *checkNotification
-	update  chan *config.Vserver
-	quit    chan bool
-	stopped chan bool
+	notify    chan *checkNotification
+	update    chan *config.Vserver
+	reconcile chan *reconcileRequest
+	quit      chan bool
+	stopped   chan bool
 }
 
 // newVserver returns an initialised vserver struct.
@@ -71,10 +73,11 @@ func newVserver(e *Engine) *vserver {
 
 		overrideChan: make(chan seesaw.Override, 5),
 
-		notify:  make(chan *checkNotification, 1000),
-		update:  make(chan *config.Vserver, 20),
-		quit:    make(chan bool, 1),
-		stopped: make(chan bool, 1),
+		notify:    make(chan *checkNotification, 1000),
+		update:    make(chan *config.Vserver, 20),
+		reconcile: make(chan *reconcileRequest, 1),
+		quit:      make(chan bool, 1),
+		stopped:   make(chan bool, 1),
 	}
 }
 
@@ -98,7 +101,7 @@ type serviceKey struct {
 type service struct {
 	vserver *vserver
 	serviceKey
-	vip seesaw.VIP
+	vip     seesaw.VIP
 	ventry  *config.VserverEntry
 	ipvsSvc *ipvs.Service
 	stats   *seesaw.ServiceStats
@@ -113,16 +116,24 @@ func (s *service) ipvsService() *ipvs.Service {
 	if s.ventry.Persistence > 0 {
 		flags |= ipvs.SFPersistent
 	}
-	if s.ventry.OnePacket {
-		flags |= ipvs.SFOnePacket
-	}
-	// Enables fallback and port for hashing schedulers.
+	// Enables fallback and port for hashing schedulers, as configured.
 	// Maps to ipvs sh-fallback, sh-port, mh-fallback and mh-port.
+	var schedFlags ipvs.ServiceFlags
 	switch s.ventry.Scheduler {
 	case seesaw.LBSchedulerSH:
-		flags |= ipvs.SFSchedSHFallback | ipvs.SFSchedSHPort
+		if s.ventry.SchedFallback {
+			schedFlags |= ipvs.SFSchedSHFallback
+		}
+		if s.ventry.SchedPort {
+			schedFlags |= ipvs.SFSchedSHPort
+		}
 	case seesaw.LBSchedulerMH:
-		flags |= ipvs.SFSchedMHFallback | ipvs.SFSchedMHPort
+		if s.ventry.SchedFallback {
+			schedFlags |= ipvs.SFSchedMHFallback
+		}
+		if s.ventry.SchedPort {
+			schedFlags |= ipvs.SFSchedMHPort
+		}
 	}
 	var ip net.IP
 	switch {
@@ -133,7 +144,7 @@ func (s *service) ipvsService() *ipvs.Service {
 	default:
 		ip = s.vip.IP.IP()
 	}
-	return &ipvs.Service{
+	svc := &ipvs.Service{
 		Address:      ip,
 		Protocol:     ipvs.IPProto(s.proto),
 		Port:         s.port,
@@ -141,7 +152,23 @@ func (s *service) ipvsService() *ipvs.Service {
 		FirewallMark: s.fwm,
 		Flags:        flags,
 		Timeout:      uint32(s.ventry.Persistence),
+		OnePacket:    s.ventry.OnePacket,
 	}
+	// Group persistent clients by network, as configured. A full length prefix
+	// is equivalent to the default of grouping clients by address.
+	switch {
+	case s.ventry.Persistence == 0:
+	case s.af == seesaw.IPv4 && s.ventry.PersistenceIPv4Len > 0 && s.ventry.PersistenceIPv4Len < 32:
+		svc.PersistenceNetmask = net.CIDRMask(s.ventry.PersistenceIPv4Len, 8*net.IPv4len)
+	case s.af == seesaw.IPv6 && s.ventry.PersistenceIPv6Len > 0 && s.ventry.PersistenceIPv6Len < 128:
+		svc.PersistenceNetmask = net.CIDRMask(s.ventry.PersistenceIPv6Len, 8*net.IPv6len)
+	}
+	// The configuration only permits scheduler flags for the SH and MH
+	// schedulers, so this should never fail.
+	if err := svc.SetSchedFlags(schedFlags); err != nil {
+		log.Errorf("%v: %v: %v", s.vserver, s, err)
+	}
+	return svc
 }
 
 // ipvsEqual returns true if two services have the same IPVS configuration.
@@ -184,6 +211,55 @@ type destination struct {
 	checks  []*check
 	healthy bool
 	active  bool
+
+	// Unhealthy destinations are drained rather than deleted if configured,
+	// remaining active in IPVS with a weight of zero until they recover.
+	drained bool
+
+	// Newly added destinations must pass warmupNeeded consecutive healthy
+	// results before being brought up.
+	warmupNeeded  int
+	warmupHealthy int
+}
+
+// startWarmup requires the destination to pass the configured number of
+// consecutive healthy results before it is brought up.
+func (d *destination) startWarmup() {
+	d.warmupNeeded = d.service.ventry.WarmupHealthyCount
+	d.warmupHealthy = 0
+}
+
+// warmingUp returns true if the destination has not yet completed warmup.
+func (d *destination) warmingUp() bool {
+	return d.warmupHealthy < d.warmupNeeded
+}
+
+// updateWarmup records a healthcheck result for a destination that is warming
+// up. It returns true if the destination has just completed warmup.
+func (d *destination) updateWarmup() bool {
+	if !d.warmingUp() {
+		return false
+	}
+	healthy := true
+	for _, c := range d.checks {
+		if c.state() != healthcheck.StateHealthy {
+			healthy = false
+			break
+		}
+	}
+	if !healthy {
+		d.warmupHealthy = 0
+		return false
+	}
+	d.warmupHealthy++
+	if d.warmingUp() {
+		log.Infof("%v: destination %v warming up, %d/%d", d.service.vserver, d, d.warmupHealthy, d.warmupNeeded)
+		return false
+	}
+	log.Infof("%v: destination %v completed warmup", d.service.vserver, d)
+	d.warmupNeeded = 0
+	d.warmupHealthy = 0
+	return true
 }
 
 // ipvsDestination returns an IPVS Destination for the given destination.
@@ -199,7 +275,7 @@ func (d *destination) ipvsDestination() *ipvs.Destination {
 	case seesaw.LBModeTUN:
 		flags |= ipvs.DFForwardTunnel
 	}
-	return &ipvs.Destination{
+	dst := &ipvs.Destination{
 		Address:        d.ip.IP(),
 		Port:           d.service.port,
 		Weight:         d.weight,
@@ -207,6 +283,12 @@ func (d *destination) ipvsDestination() *ipvs.Destination {
 		LowerThreshold: uint32(d.service.ventry.LowerThreshold),
 		UpperThreshold: uint32(d.service.ventry.UpperThreshold),
 	}
+	if d.service.ventry.Mode == seesaw.LBModeTUN {
+		dst.TunnelType = d.service.ventry.TunnelType
+		dst.TunnelPort = d.service.ventry.TunnelPort
+		dst.TunnelChecksum = d.service.ventry.TunnelChecksum
+	}
+	return dst
 }
 
 // ipvsEqual returns true if two destinations have the same IPVS configuration.
@@ -272,6 +354,33 @@ type check struct {
 	healthcheck *config.Healthcheck
 	description string
 	status      healthcheck.Status
+
+	// While a check is passive its results are reported but not acted upon,
+	// with its destinations using the pinned state instead.
+	passive bool
+	pinned  healthcheck.State
+}
+
+// state returns the state of a check that its destinations act upon.
+func (c *check) state() healthcheck.State {
+	if c.passive {
+		return c.pinned
+	}
+	return c.status.State
+}
+
+// setPassive starts or stops passive mode for a check. Passive checks are
+// pinned healthy, or to their current state if the healthcheck holds state.
+func (c *check) setPassive(passive bool) {
+	c.passive = passive
+	c.pinned = healthcheck.StateUnknown
+	if !passive {
+		return
+	}
+	c.pinned = healthcheck.StateHealthy
+	if c.healthcheck.PassiveHold {
+		c.pinned = c.status.State
+	}
 }
 
 // newCheck returns an initialised check.
@@ -404,12 +513,17 @@ func (v *vserver) expandFWMServices() map[serviceKey]*service {
 func (v *vserver) expandDests(svc *service) map[destinationKey]*destination {
 	dsts := make(map[destinationKey]*destination, len(v.config.Backends))
 	for _, backend := range v.config.Backends {
-		var ip net.IP
+		var ip, otherIP net.IP
 		switch svc.af {
 		case seesaw.IPv4:
-			ip = backend.Host.IPv4Addr
+			ip, otherIP = backend.Host.IPv4Addr, backend.Host.IPv6Addr
 		case seesaw.IPv6:
-			ip = backend.Host.IPv6Addr
+			ip, otherIP = backend.Host.IPv6Addr, backend.Host.IPv4Addr
+		}
+		// Single-stack backends are reached over a tunnel from the other
+		// address family, if the vserver permits it.
+		if ip == nil && v.config.MixedFamilies {
+			ip = otherIP
 		}
 		if ip == nil {
 			continue
@@ -427,46 +541,76 @@ func (v *vserver) expandDests(svc *service) map[destinationKey]*destination {
 	return dsts
 }
 
+// checkIPs returns the vserver and backend IPs to healthcheck for the given
+// service and destination. If the vserver shares healthchecks between address
+// families and both the vserver and backend are dual-stack, the IPs for the
+// preferred healthcheck address family are returned, so that the same check
+// is used for the destinations in both address families.
+func (v *vserver) checkIPs(svc *service, dest *destination) (seesaw.IP, seesaw.IP) {
+	if dest.ip.AF() != svc.af {
+		// Healthcheck a destination from the other address family using
+		// the vserver address in the same family, if there is one.
+		vip := v.config.Host.IPv4Addr
+		if dest.ip.AF() == seesaw.IPv6 {
+			vip = v.config.Host.IPv6Addr
+		}
+		if vip != nil {
+			return seesaw.NewIP(vip), dest.ip
+		}
+		return svc.vip.IP, dest.ip
+	}
+	if !v.config.ShareHealthchecks {
+		return svc.vip.IP, dest.ip
+	}
+	var vip, bip net.IP
+	switch v.engine.config.HealthcheckAF {
+	case seesaw.IPv4:
+		vip, bip = v.config.Host.IPv4Addr, dest.backend.Host.IPv4Addr
+	case seesaw.IPv6:
+		vip, bip = v.config.Host.IPv6Addr, dest.backend.Host.IPv6Addr
+	}
+	if vip == nil || bip == nil {
+		return svc.vip.IP, dest.ip
+	}
+	return seesaw.NewIP(vip), seesaw.NewIP(bip)
+}
+
 // expandChecks returns a list of checks that have been expanded from the
 // vserver configuration.
 func (v *vserver) expandChecks() map[CheckKey]*check {
 	checks := make(map[CheckKey]*check)
+	addCheck := func(key CheckKey, hc *config.Healthcheck, dest *destination) {
+		c := checks[key]
+		if c == nil {
+			c = newCheck(key, v, hc)
+			checks[key] = c
+		}
+		dest.checks = append(dest.checks, c)
+		c.dests = append(c.dests, dest)
+	}
+
 	for _, svc := range v.services {
 		for _, dest := range svc.dests {
 			dest.checks = make([]*check, 0)
 			if !dest.backend.Enabled {
 				continue
 			}
+			vip, bip := v.checkIPs(svc, dest)
 			for _, hc := range v.config.Healthchecks {
 				// vserver-level healthchecks
-				key := newCheckKey(svc.vip.IP, dest.ip, 0, 0, hc)
-				c := checks[key]
-				if c == nil {
-					c = newCheck(key, v, hc)
-					checks[key] = c
-				}
-				dest.checks = append(dest.checks, c)
-				c.dests = append(c.dests, dest)
+				addCheck(newCheckKey(vip, bip, 0, 0, hc), hc, dest)
 			}
 
 			// ventry-level healthchecks
 			if v.config.UseFWM {
 				for _, ve := range svc.vserver.config.Entries {
 					for _, hc := range ve.Healthchecks {
-						key := newCheckKey(svc.vip.IP, dest.ip, ve.Port, ve.Proto, hc)
-						c := newCheck(key, v, hc)
-						checks[key] = c
-						dest.checks = append(dest.checks, c)
-						c.dests = append(c.dests, dest)
+						addCheck(newCheckKey(vip, bip, ve.Port, ve.Proto, hc), hc, dest)
 					}
 				}
 			} else {
 				for _, hc := range svc.ventry.Healthchecks {
-					key := newCheckKey(svc.vip.IP, dest.ip, svc.port, svc.proto, hc)
-					c := newCheck(key, v, hc)
-					checks[key] = c
-					dest.checks = append(dest.checks, c)
-					c.dests = append(c.dests, dest)
+					addCheck(newCheckKey(vip, bip, svc.port, svc.proto, hc), hc, dest)
 				}
 			}
 		}
@@ -506,6 +650,13 @@ func (v *vserver) run() {
 				v.engine.fwmAlloc.put(fwm)
 			}
 
+			// A stopped vserver has no services to reconcile.
+			select {
+			case req := <-v.reconcile:
+				req.done(nil, 0, nil)
+			default:
+			}
+
 			v.stopped <- true
 			return
 
@@ -520,6 +671,9 @@ func (v *vserver) run() {
 		case n := <-v.notify:
 			v.handleCheckNotification(n)
 
+		case req := <-v.reconcile:
+			v.reconcileIPVS(req)
+
 		case <-statsTicker.C:
 			v.updateStats()
 			select {
@@ -615,6 +769,8 @@ func (v *vserver) handleConfigUpdate(config *config.Vserver) {
 		switch {
 		case config.UseFWM != v.config.UseFWM:
 			reInit = true
+		case config.MixedFamilies != v.config.MixedFamilies:
+			reInit = true
 		case len(config.Entries) != len(v.config.Entries):
 			reInit = true
 		default:
@@ -645,23 +801,35 @@ func (v *vserver) handleConfigUpdate(config *config.Vserver) {
 // configInit initialises all services, destinations, healthchecks and VIPs for
 // a vserver.
 func (v *vserver) configInit(config *config.Vserver) {
+	reinit := v.config != nil
 	v.config = config
 	v.enabled = vserverEnabled(config, v.vserverOverride.State())
 	newSvcs := v.expandServices()
 	// Preserve stats if this is a reinit
 	for svcK, svc := range newSvcs {
 		svc.dests = v.expandDests(svc)
-		if oldSvc, ok := v.services[svcK]; ok {
+		oldSvc := v.services[svcK]
+		if oldSvc != nil {
 			*svc.stats = *oldSvc.stats
-			for dstK, dst := range svc.dests {
-				if oldDst, ok := oldSvc.dests[dstK]; ok {
-					*dst.stats = *oldDst.stats
-				}
+		}
+		for dstK, dst := range svc.dests {
+			var oldDst *destination
+			if oldSvc != nil {
+				oldDst = oldSvc.dests[dstK]
+			}
+			switch {
+			case oldDst != nil:
+				*dst.stats = *oldDst.stats
+				dst.warmupNeeded = oldDst.warmupNeeded
+				dst.warmupHealthy = oldDst.warmupHealthy
+			case reinit:
+				dst.startWarmup()
 			}
 		}
 	}
 	v.services = newSvcs
 	v.checks = v.expandChecks()
+	v.updatePassive()
 	if v.enabled {
 		v.configureVIPs()
 	}
@@ -701,6 +869,7 @@ func (v *vserver) configUpdate() {
 				continue
 			}
 			log.Infof("%v: service %v: adding new destination: %v", v, svc, newDest)
+			newDest.startWarmup()
 			svc.dests[destKey] = newDest
 		}
 
@@ -711,6 +880,10 @@ func (v *vserver) configUpdate() {
 					dest.healthy = false
 					svc.updateState()
 				}
+				if dest.active {
+					// The destination was drained rather than taken down.
+					dest.down()
+				}
 				log.Infof("%v: service %v: deleting destination: %v", v, svc, dest)
 				delete(svc.dests, destKey)
 			}
@@ -735,9 +908,12 @@ func (v *vserver) configUpdate() {
 		if checks[k] != nil {
 			checks[k].description = oldCheck.description
 			checks[k].status = oldCheck.status
+			checks[k].passive = oldCheck.passive
+			checks[k].pinned = oldCheck.pinned
 		}
 	}
 	v.checks = checks
+	v.updatePassive()
 	if v.enabled {
 		v.configureVIPs()
 	}
@@ -773,9 +949,23 @@ func (v *vserver) handleCheckNotification(n *checkNotification) {
 	transition := (check.status.State != n.status.State)
 	check.description = n.description
 	check.status = n.status
+	if check.passive {
+		check.status.Passive = true
+		if check.pinned == healthcheck.StateUnknown {
+			// A held check that had no state when it became passive is held
+			// in its first reported state.
+			check.pinned = n.status.State
+		}
+	}
 	if transition {
-		log.Infof("%v: healthcheck %s - %v (%s)", v, n.description, n.status.State, n.status.Message)
-		for _, d := range check.dests {
+		passive := ""
+		if check.passive {
+			passive = " (passive)"
+		}
+		log.Infof("%v: healthcheck %s - %v (%s)%s", v, n.description, n.status.State, n.status.Message, passive)
+	}
+	for _, d := range check.dests {
+		if d.updateWarmup() || transition {
 			d.updateState()
 		}
 	}
@@ -838,6 +1028,14 @@ func (v *vserver) handleOverride(o seesaw.Override) {
 			}
 		}
 		return
+	case *seesaw.PassiveOverride:
+		if v.passiveOverride == *override {
+			// No change
+			return
+		}
+		v.passiveOverride = *override
+		v.updatePassive()
+		return
 	default:
 		return
 	}
@@ -846,6 +1044,42 @@ func (v *vserver) handleOverride(o seesaw.Override) {
 	}
 }
 
+// checkPassive returns true if the given check should be passive, based on
+// its configuration and the passive override state.
+func (v *vserver) checkPassive(c *check) bool {
+	switch v.passiveOverride.State() {
+	case seesaw.OverrideDisable:
+		return false
+	case seesaw.OverrideEnable:
+		return true
+	}
+	return c.healthcheck.Passive
+}
+
+// updatePassive starts or stops passive mode for the checks of a vserver,
+// updating the state of the destinations for any checks that change.
+func (v *vserver) updatePassive() {
+	for _, c := range v.checks {
+		passive := v.checkPassive(c)
+		if c.passive == passive {
+			continue
+		}
+		c.setPassive(passive)
+		if passive {
+			log.Infof("%v: healthcheck %s is passive, pinned %v", v, c.key, c.pinned)
+		} else {
+			log.Infof("%v: healthcheck %s is no longer passive", v, c.key)
+		}
+		if !v.enabled || c.status.State == healthcheck.StateUnknown {
+			// Destinations are updated when a status is received.
+			continue
+		}
+		for _, d := range c.dests {
+			d.updateState()
+		}
+	}
+}
+
 // vserverEnabled returns true if a vserver having the given configuration
 // and override state should be enabled.
 func vserverEnabled(config *config.Vserver, os seesaw.OverrideState) bool {
@@ -875,12 +1109,13 @@ func (v *vserver) snapshot() *seesaw.Vserver {
 			IPv6Addr: v.config.IPv6Addr,
 			IPv6Mask: v.config.IPv6Mask,
 		},
-		FWM:           make(map[seesaw.AF]uint32),
-		Services:      make(map[seesaw.ServiceKey]*seesaw.Service, len(v.services)),
-		OverrideState: v.vserverOverride.State(),
-		Enabled:       v.enabled,
-		ConfigEnabled: v.config.Enabled,
-		Warnings:      v.config.Warnings,
+		FWM:             make(map[seesaw.AF]uint32),
+		Services:        make(map[seesaw.ServiceKey]*seesaw.Service, len(v.services)),
+		OverrideState:   v.vserverOverride.State(),
+		PassiveOverride: v.passiveOverride.State(),
+		Enabled:         v.enabled,
+		ConfigEnabled:   v.config.Enabled,
+		Warnings:        v.config.Warnings,
 	}
 	for _, ve := range v.config.Entries {
 		sv.Entries = append(sv.Entries, ve.Snapshot())
@@ -899,10 +1134,10 @@ func (v *vserver) snapshot() *seesaw.Vserver {
 func (d *destination) updateState() {
 	// The destination is healthy if the backend is enabled and *all* the checks
 	// for that destination are healthy.
-	healthy := d.backend.Enabled
+	healthy := d.backend.Enabled && !d.warmingUp()
 	if healthy {
 		for _, c := range d.checks {
-			if c.status.State != healthcheck.StateHealthy {
+			if c.state() != healthcheck.StateHealthy {
 				healthy = false
 				break
 			}
@@ -928,11 +1163,15 @@ func (d *destination) updateState() {
 
 // up brings up a destination.
 func (d *destination) up() {
+	if d.drained {
+		d.restore()
+		return
+	}
 	d.active = true
 	log.Infof("%v: %v backend %v up", d.service.vserver, d.service, d)
 
 	ncc := d.service.vserver.ncc
-	if err := ncc.IPVSAddDestination(d.service.ipvsSvc, d.ipvsDst); err != nil {
+	if err := ncc.IPVSEnsureDestination(d.service.ipvsSvc, d.ipvsDst); err != nil {
 		log.Fatalf("%v: failed to add destination %v: %v", d.service.vserver, d, err)
 	}
 }
@@ -940,6 +1179,7 @@ func (d *destination) up() {
 // down takes down a destination.
 func (d *destination) down() {
 	d.active = false
+	d.drained = false
 	log.Infof("%v: %v backend %v down", d.service.vserver, d.service, d)
 
 	ncc := d.service.vserver.ncc
@@ -948,6 +1188,29 @@ func (d *destination) down() {
 	}
 }
 
+// drain sets the IPVS weight for a destination to zero, so that existing
+// connections complete while no new connections are scheduled to it.
+func (d *destination) drain() {
+	d.drained = true
+	log.Infof("%v: %v backend %v draining", d.service.vserver, d.service, d)
+
+	ncc := d.service.vserver.ncc
+	if err := ncc.IPVSDrainDestination(d.service.ipvsSvc, d.ipvsDst); err != nil {
+		log.Fatalf("%v: failed to drain destination %v: %v", d.service.vserver, d, err)
+	}
+}
+
+// restore restores the configured IPVS weight for a drained destination.
+func (d *destination) restore() {
+	d.drained = false
+	log.Infof("%v: %v backend %v restored", d.service.vserver, d.service, d)
+
+	ncc := d.service.vserver.ncc
+	if err := ncc.IPVSUpdateDestination(d.service.ipvsSvc, d.ipvsDst); err != nil {
+		log.Fatalf("%v: failed to restore destination %v: %v", d.service.vserver, d, err)
+	}
+}
+
 // update updates a destination while preserving its running state.
 func (d *destination) update(dest *destination) {
 	if d.destinationKey != dest.destinationKey {
@@ -960,10 +1223,19 @@ func (d *destination) update(dest *destination) {
 
 	dest.active = d.active
 	dest.healthy = d.healthy
+	dest.drained = d.drained
 	dest.stats = d.stats
+	dest.warmupNeeded = d.warmupNeeded
+	dest.warmupHealthy = d.warmupHealthy
 	*d = *dest
 
 	if !d.healthy {
+		switch {
+		case d.drained && !d.service.ventry.DrainUnhealthy:
+			d.down()
+		case d.drained && updateIPVS:
+			d.drain()
+		}
 		return
 	}
 
@@ -989,17 +1261,31 @@ func (d *destination) update(dest *destination) {
 // snapshot exports the current running state of a destination.
 func (d *destination) snapshot() *seesaw.Destination {
 	return &seesaw.Destination{
-		Backend:     d.backend,
-		Name:        d.name(),
-		VserverName: d.service.vserver.String(),
-		Stats:       d.stats,
-		Enabled:     d.backend.Enabled,
-		Weight:      d.weight,
-		Healthy:     d.healthy,
-		Active:      d.active,
+		Backend:        d.backend,
+		Name:           d.name(),
+		VserverName:    d.service.vserver.String(),
+		Stats:          d.stats,
+		Enabled:        d.backend.Enabled,
+		Weight:         d.weight,
+		Healthy:        d.healthy,
+		Active:         d.active,
+		WarmupHealthy:  d.warmupHealthy,
+		WarmupRequired: d.warmupNeeded,
+		Passive:        d.passive(),
+		Drained:        d.drained,
 	}
 }
 
+// passive returns true if any of the checks for a destination are passive.
+func (d *destination) passive() bool {
+	for _, c := range d.checks {
+		if c.passive {
+			return true
+		}
+	}
+	return false
+}
+
 // updateState updates the state of a service based on the state of its
 // destinations and propagates state changes to the vserver level if necessary.
 func (s *service) updateState() {
@@ -1080,8 +1366,14 @@ func (s *service) updateDests() {
 			continue
 		}
 		switch {
+		case !d.healthy && d.drained:
+			// Drained destinations remain in IPVS until they recover.
+		case !d.healthy && d.active && s.ventry.DrainUnhealthy:
+			d.drain()
 		case !d.healthy && d.active:
 			d.down()
+		case d.healthy && d.drained:
+			d.restore()
 		case d.healthy && !d.active:
 			d.up()
 		}
@@ -1095,13 +1387,28 @@ func (s *service) up() {
 
 	ncc := s.vserver.ncc
 
+	// Add the IPVS service and its healthy destinations in a single
+	// transaction, so that a failure does not leave a partial service.
+	// Services and destinations that are already in the IPVS table, for
+	// example after an engine restart, are updated rather than failing.
+	txn := ipvs.Begin()
+	txn.EnsureService(*s.ipvsSvc)
+	var dests []*destination
+	for _, d := range s.dests {
+		if d.healthy && !d.active {
+			txn.EnsureDestination(*s.ipvsSvc, *d.ipvsDst)
+			dests = append(dests, d)
+		}
+	}
+
 	log.Infof("%v: adding IPVS service %v", s.vserver, s.ipvsSvc)
-	if err := ncc.IPVSAddService(s.ipvsSvc); err != nil {
+	if err := ncc.IPVSApply(txn); err != nil {
 		log.Fatalf("%v: failed to add service %v: %v", s.vserver, s, err)
 	}
-
-	// Update destinations *after* the IPVS service exists.
-	s.updateDests()
+	for _, d := range dests {
+		d.active = true
+		log.Infof("%v: %v backend %v up", s.vserver, s, d)
+	}
 }
 
 // down takes down all destinations for a service, then takes down the
@@ -1113,11 +1420,19 @@ func (s *service) down() {
 
 	ncc := s.vserver.ncc
 
-	// Remove IPVS destinations *before* the IPVS service is removed.
-	s.updateDests()
+	// Flushing the IPVS service removes its destinations *before* the
+	// service itself is removed.
+	for _, d := range s.dests {
+		d.stats.DestinationStats = &ipvs.DestinationStats{}
+		d.drained = false
+		if d.active {
+			d.active = false
+			log.Infof("%v: %v backend %v down", s.vserver, s, d)
+		}
+	}
 
-	if err := ncc.IPVSDeleteService(s.ipvsSvc); err != nil {
-		log.Fatalf("%v: failed to delete service %v: %v", s.vserver, s, err)
+	if err := ncc.IPVSFlushService(s.ipvsSvc); err != nil {
+		log.Fatalf("%v: failed to flush service %v: %v", s.vserver, s, err)
 	}
 }
 
@@ -1219,10 +1534,40 @@ func (s *service) snapshot() *seesaw.Service {
 	if numBackends > 0 {
 		ss.CurrentWatermark = float32(numHealthyDests) / float32(numBackends)
 	}
+	ss.ConnImbalance = s.connImbalance()
 
 	return ss
 }
 
+// connImbalance returns the ratio of the maximum to the minimum number of
+// active connections across the healthy destinations for this service, with
+// a destination that has no active connections being counted as having one.
+// Zero is returned if there are fewer than two healthy destinations.
+func (s *service) connImbalance() float32 {
+	var n int
+	var min, max uint32
+	for _, d := range s.dests {
+		if !d.healthy || d.stats.DestinationStats == nil {
+			continue
+		}
+		conns := d.stats.ActiveConns
+		if conns < 1 {
+			conns = 1
+		}
+		if n == 0 || conns < min {
+			min = conns
+		}
+		if conns > max {
+			max = conns
+		}
+		n++
+	}
+	if n < 2 {
+		return 0
+	}
+	return float32(max) / float32(min)
+}
+
 // updateState updates the state of an IP for a vserver based on the state of
 // that IP's services.
 func (v *vserver) updateState(ip seesaw.IP) {
@@ -17,6 +17,7 @@
 package engine
 
 import (
+	"bytes"
 	"fmt"
 	"net"
 	"path/filepath"
@@ -28,6 +29,7 @@ import (
 	"github.com/google/seesaw/common/seesaw"
 	"github.com/google/seesaw/engine/config"
 	"github.com/google/seesaw/healthcheck"
+	"github.com/google/seesaw/ipvs"
 	ncclient "github.com/google/seesaw/ncc/client"
 	"github.com/kylelemons/godebug/pretty"
 
@@ -296,6 +298,116 @@ func TestExpandChecks(t *testing.T) {
 	}
 }
 
+func TestExpandSharedChecks(t *testing.T) {
+	// backend3 only has an IPv6 address, so must still be checked via IPv6.
+	backend3 := newTestBackend(3)
+	backend3.IPv4Addr = nil
+
+	sharedConfig := vserverConfig
+	sharedConfig.ShareHealthchecks = true
+	sharedConfig.Backends = map[string]*seesaw.Backend{
+		backend1.Hostname: backend1,
+		backend2.Hostname: backend2,
+		backend3.Hostname: backend3,
+	}
+
+	for _, af := range seesaw.AFs() {
+		vserver := newTestVserver(nil)
+		vserver.engine.config.HealthcheckAF = af
+		vserver.handleConfigUpdate(&sharedConfig)
+
+		// 1 vserver healthcheck x 3 backends +
+		// 2 ventry healthchecks x 3 backends x 2 ventries = 15
+		if len(vserver.checks) != 15 {
+			t.Errorf("%v: Expected 15 total checks, got %d", af, len(vserver.checks))
+		}
+
+		for _, c := range vserver.checks {
+			wantAF := af
+			wantDests := 2
+			if c.key.BackendIP.Equal(seesaw.ParseIP("2012::12")) {
+				wantAF = seesaw.IPv6
+				wantDests = 1
+			}
+			if c.healthcheck.Port == vserverHC.Port {
+				// The vserver healthcheck is used by each ventry.
+				wantDests *= 2
+			}
+			if c.key.VserverIP.AF() != wantAF || c.key.BackendIP.AF() != wantAF {
+				t.Errorf("%v: Check %v uses wrong address family, want %v", af, c.key, wantAF)
+			}
+			if len(c.dests) != wantDests {
+				t.Errorf("%v: Expected %d dests for check %v, got %d", af, wantDests, c.key, len(c.dests))
+			}
+		}
+
+		// Healthcheck state must be applied to destinations in both address
+		// families.
+		for key := range vserver.checks {
+			vserver.handleCheckNotification(&checkNotification{key: key, status: statusHealthy})
+		}
+		for _, s := range vserver.services {
+			for _, d := range s.dests {
+				if !d.healthy {
+					t.Errorf("%v: Expected destination %v to be healthy", af, d)
+				}
+			}
+		}
+	}
+
+	// Without shared healthchecks, each address family is checked separately.
+	sharedConfig.ShareHealthchecks = false
+	vserver := newTestVserver(nil)
+	vserver.handleConfigUpdate(&sharedConfig)
+
+	// 1 vserver healthcheck x 5 backend IPs +
+	// 2 ventry healthchecks x 5 backend IPs x 2 ventries = 25
+	if len(vserver.checks) != 25 {
+		t.Errorf("Expected 25 total checks, got %d", len(vserver.checks))
+	}
+}
+
+func TestExpandMixedFamilyDests(t *testing.T) {
+	// backend3 only has an IPv6 address, so is only used by IPv4 services
+	// when mixed address families are enabled.
+	backend3 := newTestBackend(3)
+	backend3.IPv4Addr = nil
+
+	mixedConfig := vserverConfig
+	mixedConfig.Backends = map[string]*seesaw.Backend{
+		backend1.Hostname: backend1,
+		backend2.Hostname: backend2,
+		backend3.Hostname: backend3,
+	}
+
+	for _, mixed := range []bool{false, true} {
+		mixedConfig.MixedFamilies = mixed
+		vserver := newTestVserver(nil)
+		vserver.handleConfigUpdate(&mixedConfig)
+
+		mixedKey := newDestinationKey(net.ParseIP("2012::12"))
+		for _, s := range vserver.services {
+			wantDests := 3
+			if s.af == seesaw.IPv4 && !mixed {
+				wantDests = 2
+			}
+			if len(s.dests) != wantDests {
+				t.Errorf("Mixed %v: Expected %d dests for %v, got %d", mixed, wantDests, s, len(s.dests))
+			}
+			d := s.dests[mixedKey]
+			if d == nil || s.af == seesaw.IPv6 {
+				continue
+			}
+			// The IPv6 destination is healthchecked using the IPv6 VIP.
+			for _, c := range d.checks {
+				if !c.key.VserverIP.Equal(seesaw.ParseIP("2012::1")) {
+					t.Errorf("Mixed %v: Check %v for %v uses wrong vserver IP", mixed, c.key, d)
+				}
+			}
+		}
+	}
+}
+
 type testStates struct {
 	active  []bool
 	healthy []bool
@@ -969,6 +1081,63 @@ var expectedSnapshot = seesaw.Vserver{
 	},
 }
 
+func TestVserverIPVS(t *testing.T) {
+	backend := ipvs.NewDummyBackend()
+	engine := newTestEngine()
+	engine.ncc = ncclient.NewIPVSDummyNCC(backend)
+	vserver := newTestVserver(engine)
+	vserver.handleConfigUpdate(&vserverConfig)
+
+	checkIPVS := func(desc string, wantDests int) {
+		t.Helper()
+		svcs := backend.Snapshot()
+		wantSvcs := len(expectedServices)
+		if wantDests == 0 {
+			wantSvcs = 0
+		}
+		if len(svcs) != wantSvcs {
+			t.Errorf("%s: got %d IPVS services, want %d", desc, len(svcs), wantSvcs)
+		}
+		for _, svc := range svcs {
+			if len(svc.Destinations) != wantDests {
+				t.Errorf("%s: got %d IPVS destinations for %v, want %d", desc, len(svc.Destinations), svc, wantDests)
+			}
+		}
+	}
+	setHealth := func(status healthcheck.Status) {
+		for _, c := range vserver.checks {
+			vserver.handleCheckNotification(&checkNotification{key: c.key, status: status})
+		}
+	}
+
+	checkIPVS("initial", 0)
+	setHealth(statusHealthy)
+	checkIPVS("healthy", 2)
+
+	// Taking down one backend removes it from every service.
+	for _, c := range vserver.checks {
+		if c.key.BackendIP.Equal(seesaw.NewIP(backend1.IPv4Addr)) || c.key.BackendIP.Equal(seesaw.NewIP(backend1.IPv6Addr)) {
+			vserver.handleCheckNotification(&checkNotification{key: c.key, status: statusUnhealthy})
+		}
+	}
+	checkIPVS("backend unhealthy", 1)
+	setHealth(statusHealthy)
+	checkIPVS("backend healthy", 2)
+
+	vserver.handleOverride(&seesaw.VserverOverride{
+		VserverName:   vserverConfig.Name,
+		OverrideState: seesaw.OverrideDisable,
+	})
+	checkIPVS("override disabled", 0)
+
+	vserver.handleOverride(&seesaw.VserverOverride{
+		VserverName:   vserverConfig.Name,
+		OverrideState: seesaw.OverrideDefault,
+	})
+	setHealth(statusHealthy)
+	checkIPVS("override default", 2)
+}
+
 func TestVserverSnapshot(t *testing.T) {
 	vserver := newTestVserver(nil)
 	if vserver.snapshot() != nil {
@@ -1841,3 +2010,657 @@ func TestReIPVserver(t *testing.T) {
 		}
 	}
 }
+
+// statsNCC is an NCC that returns IPVS statistics with the given number of
+// active connections for each destination IP.
+type statsNCC struct {
+	ncclient.NCC
+	conns map[string]uint32
+}
+
+func (nc *statsNCC) IPVSGetService(svc *ipvs.Service) (*ipvs.Service, error) {
+	s := *svc
+	s.Statistics = &ipvs.ServiceStats{}
+	s.Destinations = nil
+	for ip, conns := range nc.conns {
+		addr := net.ParseIP(ip)
+		if (addr.To4() == nil) != (svc.Address.To4() == nil) {
+			continue
+		}
+		s.Destinations = append(s.Destinations, &ipvs.Destination{
+			Address: addr,
+			Port:    svc.Port,
+			Statistics: &ipvs.DestinationStats{
+				ActiveConns:   conns,
+				InactiveConns: 2 * conns,
+			},
+		})
+	}
+	return &s, nil
+}
+
+func TestConnImbalance(t *testing.T) {
+	vserver := newTestVserver(nil)
+	nc := &statsNCC{NCC: ncclient.NewDummyNCC()}
+	vserver.ncc = nc
+	vserver.handleConfigUpdate(&vserverConfig)
+	for _, c := range vserver.checks {
+		vserver.handleCheckNotification(&checkNotification{key: c.key, status: statusHealthy})
+	}
+	for _, err := range checkAllUp(vserver) {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		desc  string
+		conns map[string]uint32
+		down  *seesaw.Backend
+		want  float32
+	}{
+		{
+			desc: "skewed",
+			conns: map[string]uint32{
+				"1.1.1.10": 300, "1.1.1.11": 100,
+				"2012::10": 300, "2012::11": 100,
+			},
+			want: 3,
+		},
+		{
+			desc: "balanced",
+			conns: map[string]uint32{
+				"1.1.1.10": 50, "1.1.1.11": 50,
+				"2012::10": 50, "2012::11": 50,
+			},
+			want: 1,
+		},
+		{
+			desc: "idle destination",
+			conns: map[string]uint32{
+				"1.1.1.10": 40, "1.1.1.11": 0,
+				"2012::10": 40, "2012::11": 0,
+			},
+			want: 40,
+		},
+		{
+			desc: "single healthy destination",
+			conns: map[string]uint32{
+				"1.1.1.10": 300, "1.1.1.11": 100,
+				"2012::10": 300, "2012::11": 100,
+			},
+			down: backend2,
+			want: 0,
+		},
+	}
+	for _, test := range tests {
+		if test.down != nil {
+			for _, c := range vserver.checks {
+				if c.key.BackendIP.Equal(seesaw.NewIP(test.down.IPv4Addr)) ||
+					c.key.BackendIP.Equal(seesaw.NewIP(test.down.IPv6Addr)) {
+					vserver.handleCheckNotification(&checkNotification{key: c.key, status: statusUnhealthy})
+				}
+			}
+		}
+		nc.conns = test.conns
+		vserver.updateStats()
+		snapshot := vserver.snapshot()
+		for _, svc := range snapshot.Services {
+			if svc.ConnImbalance != test.want {
+				t.Errorf("%s: service %v got imbalance %v, want %v", test.desc, svc.ServiceKey, svc.ConnImbalance, test.want)
+			}
+			d := svc.Destinations[backend1.Hostname]
+			want := test.conns[backend1.IPv4Addr.String()]
+			if svc.AF == seesaw.IPv6 {
+				want = test.conns[backend1.IPv6Addr.String()]
+			}
+			if d.Stats.ActiveConns != want || d.Stats.InactiveConns != 2*want {
+				t.Errorf("%s: service %v got %d/%d active/inactive conns for %s, want %d/%d",
+					test.desc, svc.ServiceKey, d.Stats.ActiveConns, d.Stats.InactiveConns, d.Name, want, 2*want)
+			}
+		}
+	}
+}
+
+func TestDestinationWarmup(t *testing.T) {
+	const warmup = 5
+	newConfig := func(backends ...*seesaw.Backend) *config.Vserver {
+		vsConfig := vserverConfig
+		vsConfig.Entries = make(map[string]*config.VserverEntry)
+		for k, vse := range vserverConfig.Entries {
+			vseCopy := *vse
+			vseCopy.WarmupHealthyCount = warmup
+			vsConfig.Entries[k] = &vseCopy
+		}
+		vsConfig.Backends = make(map[string]*seesaw.Backend)
+		for _, b := range backends {
+			vsConfig.Backends[b.Hostname] = b
+		}
+		return &vsConfig
+	}
+	notify := func(v *vserver, b *seesaw.Backend, status healthcheck.Status) {
+		for _, c := range v.checks {
+			if c.key.BackendIP.Equal(seesaw.NewIP(b.IPv4Addr)) || c.key.BackendIP.Equal(seesaw.NewIP(b.IPv6Addr)) {
+				v.handleCheckNotification(&checkNotification{key: c.key, status: status})
+			}
+		}
+	}
+	backendDests := func(v *vserver, b *seesaw.Backend) []*destination {
+		var dests []*destination
+		for _, svc := range v.services {
+			for _, d := range svc.dests {
+				if d.backend.Hostname == b.Hostname {
+					dests = append(dests, d)
+				}
+			}
+		}
+		return dests
+	}
+
+	// Destinations in the initial configuration do not require warmup.
+	vserver := newTestVserver(nil)
+	vserver.handleConfigUpdate(newConfig(backend1))
+	notify(vserver, backend1, statusHealthy)
+	for _, err := range checkAllUp(vserver) {
+		t.Fatal(err)
+	}
+
+	// A newly added backend must warm up before it is brought up.
+	vserver.handleConfigUpdate(newConfig(backend1, backend2))
+	for _, d := range backendDests(vserver, backend1) {
+		if d.warmingUp() || !d.active {
+			t.Errorf("Existing destination %v is warming up or inactive", d)
+		}
+	}
+	notify(vserver, backend2, statusHealthy)
+	for _, d := range backendDests(vserver, backend2) {
+		sd := d.snapshot()
+		if sd.WarmupRequired != warmup || sd.WarmupHealthy < 1 || sd.WarmupHealthy >= warmup {
+			t.Errorf("Destination %v got warmup %d/%d, want in progress", d, sd.WarmupHealthy, sd.WarmupRequired)
+		}
+		if d.healthy || d.active {
+			t.Errorf("Destination %v brought up during warmup", d)
+		}
+	}
+
+	// An unhealthy result restarts warmup.
+	notify(vserver, backend2, statusUnhealthy)
+	for _, d := range backendDests(vserver, backend2) {
+		if d.warmupHealthy != 0 || !d.warmingUp() {
+			t.Errorf("Destination %v got warmup %d/%d after unhealthy result, want 0/%d",
+				d, d.warmupHealthy, d.warmupNeeded, warmup)
+		}
+	}
+
+	for i := 0; i < warmup; i++ {
+		notify(vserver, backend2, statusHealthy)
+	}
+	for _, d := range backendDests(vserver, backend2) {
+		if d.warmingUp() || !d.healthy || !d.active {
+			t.Errorf("Destination %v not brought up after warmup", d)
+		}
+		if sd := d.snapshot(); sd.WarmupRequired != 0 {
+			t.Errorf("Destination %v still reports warmup %d/%d", d, sd.WarmupHealthy, sd.WarmupRequired)
+		}
+	}
+
+	// Completed warmup survives a configuration update.
+	vserver.handleConfigUpdate(newConfig(backend1, backend2))
+	for _, d := range backendDests(vserver, backend2) {
+		if d.warmingUp() || !d.active {
+			t.Errorf("Destination %v warming up again after config update", d)
+		}
+	}
+}
+
+// flushNCC is an NCC that records IPVS deletions and flushes.
+type flushNCC struct {
+	ncclient.NCC
+	deletes int
+	flushes []*ipvs.Service
+}
+
+func (nc *flushNCC) IPVSDeleteService(svc *ipvs.Service) error {
+	nc.deletes++
+	return nil
+}
+
+func (nc *flushNCC) IPVSDeleteDestination(svc *ipvs.Service, dst *ipvs.Destination) error {
+	nc.deletes++
+	return nil
+}
+
+func (nc *flushNCC) IPVSFlushService(svc *ipvs.Service) error {
+	nc.flushes = append(nc.flushes, svc)
+	return nil
+}
+
+func TestServiceDownFlushesService(t *testing.T) {
+	vserver := newTestVserver(nil)
+	nc := &flushNCC{NCC: ncclient.NewDummyNCC()}
+	vserver.ncc = nc
+	vserver.handleConfigUpdate(&vserverConfig)
+	for _, c := range vserver.checks {
+		vserver.handleCheckNotification(&checkNotification{key: c.key, status: statusHealthy})
+	}
+	for _, err := range checkAllUp(vserver) {
+		t.Fatal(err)
+	}
+
+	vserver.downAll()
+	if nc.deletes != 0 {
+		t.Errorf("Got %d IPVS deletions during teardown, want 0", nc.deletes)
+	}
+	if got, want := len(nc.flushes), len(vserver.services); got != want {
+		t.Errorf("Got %d IPVS service flushes, want %d", got, want)
+	}
+	for _, err := range checkAllDown(vserver) {
+		t.Error(err)
+	}
+}
+
+func TestServiceSchedFlags(t *testing.T) {
+	tests := []struct {
+		scheduler seesaw.LBScheduler
+		fallback  bool
+		port      bool
+		want      ipvs.ServiceFlags
+	}{
+		{seesaw.LBSchedulerSH, true, true, ipvs.SFSchedSHFallback | ipvs.SFSchedSHPort},
+		{seesaw.LBSchedulerSH, true, false, ipvs.SFSchedSHFallback},
+		{seesaw.LBSchedulerMH, false, true, ipvs.SFSchedMHPort},
+		{seesaw.LBSchedulerMH, false, false, 0},
+		{seesaw.LBSchedulerWRR, false, false, 0},
+	}
+	for _, test := range tests {
+		ve := config.NewVserverEntry(53, seesaw.IPProtoUDP)
+		ve.Scheduler = test.scheduler
+		ve.SchedFallback = test.fallback
+		ve.SchedPort = test.port
+		s := &service{
+			serviceKey: serviceKey{af: seesaw.IPv4, proto: seesaw.IPProtoUDP, port: 53},
+			vip:        *seesaw.NewVIP(net.ParseIP("192.168.36.1"), nil),
+			ventry:     ve,
+		}
+		if got := s.ipvsService().Flags; got != test.want {
+			t.Errorf("%v (fallback %v, port %v): got flags 0x%x, want 0x%x",
+				test.scheduler, test.fallback, test.port, uint32(got), uint32(test.want))
+		}
+	}
+}
+
+func TestServicePersistenceNetmask(t *testing.T) {
+	tests := []struct {
+		af          seesaw.AF
+		ip          string
+		persistence int
+		want        net.IPMask
+	}{
+		{seesaw.IPv4, "192.168.36.1", 300, net.CIDRMask(24, 32)},
+		{seesaw.IPv6, "2015:cafe:36::1", 300, net.CIDRMask(64, 128)},
+		{seesaw.IPv4, "192.168.36.1", 0, nil},
+	}
+	for _, test := range tests {
+		ve := config.NewVserverEntry(443, seesaw.IPProtoTCP)
+		ve.Persistence = test.persistence
+		ve.PersistenceIPv4Len = 24
+		ve.PersistenceIPv6Len = 64
+		s := &service{
+			serviceKey: serviceKey{af: test.af, proto: seesaw.IPProtoTCP, port: 443},
+			vip:        *seesaw.NewVIP(net.ParseIP(test.ip), nil),
+			ventry:     ve,
+		}
+		svc := s.ipvsService()
+		if !bytes.Equal(svc.PersistenceNetmask, test.want) {
+			t.Errorf("%v with %ds persistence: got persistence netmask %v, want %v",
+				test.ip, test.persistence, svc.PersistenceNetmask, test.want)
+		}
+		if got, want := svc.Flags&ipvs.SFPersistent != 0, test.persistence > 0; got != want {
+			t.Errorf("%v with %ds persistence: got persistent %v, want %v",
+				test.ip, test.persistence, got, want)
+		}
+	}
+}
+
+// updateNCC is an NCC that records IPVS service updates and deletions.
+type updateNCC struct {
+	flushNCC
+	updates []*ipvs.Service
+}
+
+func (nc *updateNCC) IPVSUpdateService(svc *ipvs.Service) error {
+	nc.updates = append(nc.updates, svc)
+	return nil
+}
+
+func TestServicePersistenceUpdate(t *testing.T) {
+	vserver := newTestVserver(nil)
+	nc := &updateNCC{flushNCC: flushNCC{NCC: ncclient.NewDummyNCC()}}
+	vserver.ncc = nc
+	vserver.handleConfigUpdate(&vserverConfig)
+	for _, c := range vserver.checks {
+		vserver.handleCheckNotification(&checkNotification{key: c.key, status: statusHealthy})
+	}
+	for _, err := range checkAllUp(vserver) {
+		t.Fatal(err)
+	}
+
+	vsConfig := vserverConfig
+	vsConfig.Entries = make(map[string]*config.VserverEntry)
+	for k, vse := range vserverConfig.Entries {
+		vseCopy := *vse
+		vseCopy.Persistence = 300
+		vseCopy.PersistenceIPv4Len = 24
+		vsConfig.Entries[k] = &vseCopy
+	}
+	vserver.handleConfigUpdate(&vsConfig)
+
+	if nc.deletes != 0 || len(nc.flushes) != 0 {
+		t.Errorf("Got %d IPVS deletions and %d flushes, want 0", nc.deletes, len(nc.flushes))
+	}
+	if got, want := len(nc.updates), len(vserver.services); got != want {
+		t.Errorf("Got %d IPVS service updates, want %d", got, want)
+	}
+	for _, svc := range nc.updates {
+		if svc.Timeout != 300 || svc.Flags&ipvs.SFPersistent == 0 {
+			t.Errorf("Got updated service %v, want 300s persistence", svc)
+		}
+	}
+	for _, err := range checkAllUp(vserver) {
+		t.Error(err)
+	}
+}
+
+func TestServiceSchedulerUpdate(t *testing.T) {
+	backend := ipvs.NewDummyBackend()
+	engine := newTestEngine()
+	engine.ncc = ncclient.NewIPVSDummyNCC(backend)
+	vserver := newTestVserver(engine)
+	vserver.handleConfigUpdate(&vserverConfig)
+	for _, c := range vserver.checks {
+		vserver.handleCheckNotification(&checkNotification{key: c.key, status: statusHealthy})
+	}
+
+	newConfig := func(scheduler seesaw.LBScheduler, fallback, port bool) *config.Vserver {
+		vsConfig := vserverConfig
+		vsConfig.Entries = make(map[string]*config.VserverEntry)
+		for k, vse := range vserverConfig.Entries {
+			vseCopy := *vse
+			vseCopy.Scheduler = scheduler
+			vseCopy.SchedFallback = fallback
+			vseCopy.SchedPort = port
+			vsConfig.Entries[k] = &vseCopy
+		}
+		return &vsConfig
+	}
+	tests := []struct {
+		scheduler seesaw.LBScheduler
+		fallback  bool
+		port      bool
+		wantFlags ipvs.ServiceFlags
+	}{
+		{seesaw.LBSchedulerMH, true, true, ipvs.SFSchedMHFallback | ipvs.SFSchedMHPort},
+		{seesaw.LBSchedulerMH, false, true, ipvs.SFSchedMHPort},
+		{seesaw.LBSchedulerWRR, false, false, 0},
+	}
+	for _, test := range tests {
+		vserver.handleConfigUpdate(newConfig(test.scheduler, test.fallback, test.port))
+		svcs := backend.Snapshot()
+		if len(svcs) != len(expectedServices) {
+			t.Errorf("%v: got %d IPVS services, want %d", test.scheduler, len(svcs), len(expectedServices))
+		}
+		for _, svc := range svcs {
+			if svc.Scheduler != test.scheduler.String() || svc.Flags&ipvs.SFSchedMask != test.wantFlags {
+				t.Errorf("%v: got service %v with scheduler flags 0x%x, want scheduler %v with flags 0x%x",
+					test.scheduler, svc, uint32(svc.Flags&ipvs.SFSchedMask), test.scheduler, uint32(test.wantFlags))
+			}
+			if len(svc.Destinations) != 2 {
+				t.Errorf("%v: got %d IPVS destinations for %v, want 2", test.scheduler, len(svc.Destinations), svc)
+			}
+		}
+	}
+}
+
+// drainNCC is an NCC that records IPVS destination drains, updates and
+// deletions.
+type drainNCC struct {
+	ncclient.NCC
+	drains  int
+	updates []*ipvs.Destination
+	deletes int
+}
+
+func (nc *drainNCC) IPVSDrainDestination(svc *ipvs.Service, dst *ipvs.Destination) error {
+	nc.drains++
+	return nil
+}
+
+func (nc *drainNCC) IPVSUpdateDestination(svc *ipvs.Service, dst *ipvs.Destination) error {
+	nc.updates = append(nc.updates, dst)
+	return nil
+}
+
+func (nc *drainNCC) IPVSDeleteDestination(svc *ipvs.Service, dst *ipvs.Destination) error {
+	nc.deletes++
+	return nil
+}
+
+func TestDestinationDrain(t *testing.T) {
+	newConfig := func(drain bool) *config.Vserver {
+		vsConfig := vserverConfig
+		vsConfig.Entries = make(map[string]*config.VserverEntry)
+		for k, vse := range vserverConfig.Entries {
+			vseCopy := *vse
+			vseCopy.DrainUnhealthy = drain
+			vsConfig.Entries[k] = &vseCopy
+		}
+		return &vsConfig
+	}
+	notify := func(v *vserver, b *seesaw.Backend, status healthcheck.Status) {
+		for _, c := range v.checks {
+			if c.key.BackendIP.Equal(seesaw.NewIP(b.IPv4Addr)) || c.key.BackendIP.Equal(seesaw.NewIP(b.IPv6Addr)) {
+				v.handleCheckNotification(&checkNotification{key: c.key, status: status})
+			}
+		}
+	}
+	backendDests := func(v *vserver, b *seesaw.Backend) []*destination {
+		var dests []*destination
+		for _, svc := range v.services {
+			for _, d := range svc.dests {
+				if d.backend.Hostname == b.Hostname {
+					dests = append(dests, d)
+				}
+			}
+		}
+		return dests
+	}
+
+	vserver := newTestVserver(nil)
+	nc := &drainNCC{NCC: ncclient.NewDummyNCC()}
+	vserver.ncc = nc
+	vserver.handleConfigUpdate(newConfig(true))
+	notify(vserver, backend1, statusHealthy)
+	notify(vserver, backend2, statusHealthy)
+	for _, err := range checkAllUp(vserver) {
+		t.Fatal(err)
+	}
+	dests := backendDests(vserver, backend2)
+
+	// Unhealthy destinations are drained rather than deleted.
+	notify(vserver, backend2, statusUnhealthy)
+	if nc.deletes != 0 {
+		t.Errorf("Got %d IPVS destination deletions, want 0", nc.deletes)
+	}
+	if nc.drains != len(dests) {
+		t.Errorf("Got %d IPVS destination drains, want %d", nc.drains, len(dests))
+	}
+	for _, d := range dests {
+		if !d.drained || !d.active {
+			t.Errorf("Destination %v got drained %v, active %v, want drained and active", d, d.drained, d.active)
+		}
+		if !d.snapshot().Drained {
+			t.Errorf("Destination %v snapshot is not drained", d)
+		}
+	}
+
+	// Recovered destinations have their weight restored.
+	notify(vserver, backend2, statusHealthy)
+	if len(nc.updates) != len(dests) {
+		t.Errorf("Got %d IPVS destination updates, want %d", len(nc.updates), len(dests))
+	}
+	for _, dst := range nc.updates {
+		if dst.Weight != backend2.Weight {
+			t.Errorf("Got restored destination %v with weight %d, want %d", dst, dst.Weight, backend2.Weight)
+		}
+	}
+	for _, d := range dests {
+		if d.drained || !d.active {
+			t.Errorf("Destination %v got drained %v, active %v, want active", d, d.drained, d.active)
+		}
+	}
+
+	// Draining destinations are deleted once draining is disabled.
+	notify(vserver, backend2, statusUnhealthy)
+	vserver.handleConfigUpdate(newConfig(false))
+	if nc.deletes != len(dests) {
+		t.Errorf("Got %d IPVS destination deletions, want %d", nc.deletes, len(dests))
+	}
+	for _, d := range backendDests(vserver, backend2) {
+		if d.drained || d.active {
+			t.Errorf("Destination %v got drained %v, active %v, want inactive", d, d.drained, d.active)
+		}
+	}
+}
+
+// applyNCC is an NCC that records IPVS transactions and additions.
+type applyNCC struct {
+	ncclient.NCC
+	txns     []*ipvs.Transaction
+	services int
+	dests    int
+}
+
+func (nc *applyNCC) IPVSApply(txn *ipvs.Transaction) error {
+	nc.txns = append(nc.txns, txn)
+	return nil
+}
+
+func (nc *applyNCC) IPVSAddService(svc *ipvs.Service) error {
+	nc.services++
+	return nil
+}
+
+func (nc *applyNCC) IPVSEnsureDestination(svc *ipvs.Service, dst *ipvs.Destination) error {
+	nc.dests++
+	return nil
+}
+
+func TestServiceUpTransaction(t *testing.T) {
+	vserver := newTestVserver(nil)
+	nc := &applyNCC{NCC: ncclient.NewDummyNCC()}
+	vserver.ncc = nc
+	vserver.handleConfigUpdate(&vserverConfig)
+	for _, c := range vserver.checks {
+		vserver.handleCheckNotification(&checkNotification{key: c.key, status: statusHealthy})
+	}
+	for _, err := range checkAllUp(vserver) {
+		t.Fatal(err)
+	}
+
+	if nc.services != 0 {
+		t.Errorf("Got %d IPVS services added outside a transaction, want 0", nc.services)
+	}
+	if got, want := len(nc.txns), len(vserver.services); got != want {
+		t.Fatalf("Got %d IPVS transactions, want %d", got, want)
+	}
+	dests := nc.dests
+	for _, txn := range nc.txns {
+		ops := txn.Operations
+		if len(ops) < 2 || ops[0].Type != ipvs.OpEnsureService {
+			t.Errorf("Got transaction %v, want a service addition with destinations", ops)
+			continue
+		}
+		for _, op := range ops[1:] {
+			if op.Type != ipvs.OpEnsureDestination || !op.Service.Equal(ops[0].Service) {
+				t.Errorf("Got operation %v, want destination addition for %v", op, ops[0].Service)
+			}
+			dests++
+		}
+	}
+	want := 0
+	for _, s := range vserver.services {
+		want += len(s.dests)
+	}
+	if dests != want {
+		t.Errorf("Got %d IPVS destinations added, want %d", dests, want)
+	}
+}
+
+func TestPassiveHealthchecks(t *testing.T) {
+	notifyAll := func(v *vserver, status healthcheck.Status) {
+		for _, c := range v.checks {
+			v.handleCheckNotification(&checkNotification{key: c.key, status: status})
+		}
+	}
+
+	// Passive checks are pinned healthy.
+	vserver := newTestVserver(nil)
+	vserver.handleConfigUpdate(&vserverConfig)
+	notifyAll(vserver, statusHealthy)
+	for _, err := range checkAllUp(vserver) {
+		t.Fatal(err)
+	}
+	vserver.handleOverride(&seesaw.PassiveOverride{
+		VserverName:   vserverConfig.Name,
+		OverrideState: seesaw.OverrideEnable,
+	})
+	notifyAll(vserver, statusUnhealthy)
+	for _, err := range checkAllUp(vserver) {
+		t.Error(err)
+	}
+	for _, c := range vserver.checks {
+		if !c.status.Passive || c.status.State != healthcheck.StateUnhealthy {
+			t.Errorf("Check %v got status %v (passive %v), want passive unhealthy", c.key, c.status.State, c.status.Passive)
+		}
+	}
+	for _, svc := range vserver.services {
+		for _, d := range svc.dests {
+			if sd := d.snapshot(); !sd.Passive || !sd.Healthy {
+				t.Errorf("Destination %v got passive %v, healthy %v, want passive and healthy", d, sd.Passive, sd.Healthy)
+			}
+		}
+	}
+	if sv := vserver.snapshot(); sv.PassiveOverride != seesaw.OverrideEnable {
+		t.Errorf("Got passive override %v, want %v", sv.PassiveOverride, seesaw.OverrideEnable)
+	}
+
+	// Results are acted upon once the checks are no longer passive.
+	vserver.handleOverride(&seesaw.PassiveOverride{
+		VserverName:   vserverConfig.Name,
+		OverrideState: seesaw.OverrideDefault,
+	})
+	for _, err := range checkAllDown(vserver) {
+		t.Error(err)
+	}
+
+	// Passive checks that hold state keep their first reported state.
+	held := *vserverHC
+	held.Passive = true
+	held.PassiveHold = true
+	vsConfig := vserverConfig
+	vsConfig.Healthchecks = map[string]*config.Healthcheck{held.Key(): &held}
+	vserver = newTestVserver(nil)
+	vserver.handleConfigUpdate(&vsConfig)
+	notifyAll(vserver, statusUnhealthy)
+	notifyAll(vserver, statusHealthy)
+	for _, err := range checkAllDown(vserver) {
+		t.Error(err)
+	}
+
+	// Disabling passive mode acts upon the current results.
+	vserver.handleOverride(&seesaw.PassiveOverride{
+		VserverName:   vsConfig.Name,
+		OverrideState: seesaw.OverrideDisable,
+	})
+	for _, err := range checkAllUp(vserver) {
+		t.Error(err)
+	}
+}
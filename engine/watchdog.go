@@ -0,0 +1,154 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+// This file contains structures and functions to detect a stalled healthcheck
+// pipeline, where healthcheck notifications are no longer being delivered to
+// the Seesaw Engine.
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/seesaw/common/seesaw"
+	"github.com/google/seesaw/engine/config"
+
+	log "github.com/golang/glog"
+)
+
+const watchdogInterval = 5 * time.Second
+
+// healthcheckWatchdog tracks the delivery of healthcheck notifications and
+// raises a critical condition when they become stale.
+type healthcheckWatchdog struct {
+	engine  *Engine
+	timeout time.Duration
+	policy  config.StalePolicy
+	now     func() time.Time
+
+	lock       sync.RWMutex
+	baseline   time.Time // When notifications were first expected.
+	last       time.Time
+	sources    map[seesaw.Component]time.Time
+	staleSrcs  map[seesaw.Component]bool
+	stale      bool
+	staleSince time.Time
+	staleCount int
+}
+
+// newHealthcheckWatchdog returns an initialised healthcheckWatchdog.
+func newHealthcheckWatchdog(e *Engine) *healthcheckWatchdog {
+	return &healthcheckWatchdog{
+		engine:    e,
+		timeout:   e.config.HealthcheckStaleTimeout,
+		policy:    e.config.HealthcheckStalePolicy,
+		now:       time.Now,
+		sources:   make(map[seesaw.Component]time.Time),
+		staleSrcs: make(map[seesaw.Component]bool),
+	}
+}
+
+// run periodically checks for stale healthcheck notifications.
+func (w *healthcheckWatchdog) run() {
+	if w.timeout <= 0 {
+		log.Infof("Healthcheck watchdog is disabled")
+		return
+	}
+	for range time.Tick(watchdogInterval) {
+		w.check()
+	}
+}
+
+// delivered records the delivery of healthcheck notifications from the
+// given component. Delivery clears any stale condition.
+func (w *healthcheckWatchdog) delivered(source seesaw.Component) {
+	now := w.now()
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.last = now
+	w.sources[source] = now
+	if w.staleSrcs[source] {
+		log.Infof("Healthcheck notifications from %v have recovered", source)
+		delete(w.staleSrcs, source)
+	}
+	if w.stale {
+		log.Infof("Healthcheck notifications have recovered after %v", now.Sub(w.staleSince))
+		w.stale = false
+		w.staleSince = time.Time{}
+	}
+}
+
+// check determines whether healthcheck notifications have become stale,
+// raising the stale condition and applying the stale policy if so.
+func (w *healthcheckWatchdog) check() {
+	now := w.now()
+	configured := len(w.engine.hcManager.configs()) > 0
+
+	w.lock.Lock()
+	if w.baseline.IsZero() || !configured {
+		// Notifications are not expected until healthchecks exist.
+		w.baseline = now
+	}
+	for source, last := range w.sources {
+		stale := configured && now.Sub(last) > w.timeout
+		if stale && !w.staleSrcs[source] {
+			log.Errorf("Healthcheck notifications from %v are stale - last delivered %v ago", source, now.Sub(last))
+			w.staleSrcs[source] = true
+		}
+	}
+	last := w.last
+	if last.Before(w.baseline) {
+		last = w.baseline
+	}
+	raise := configured && !w.stale && now.Sub(last) > w.timeout
+	if raise {
+		w.stale = true
+		w.staleSince = now
+		w.staleCount++
+		log.Errorf("CRITICAL: Healthcheck notifications are stale - none delivered for %v", now.Sub(last))
+	}
+	w.lock.Unlock()
+
+	if raise && w.policy == config.StalePolicyUnknown {
+		log.Errorf("Marking all healthcheck states as unknown")
+		w.engine.hcManager.expire()
+	}
+}
+
+// status returns the current healthcheck notification delivery status.
+func (w *healthcheckWatchdog) status() *seesaw.HealthcheckStatus {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+	hs := &seesaw.HealthcheckStatus{
+		LastDelivery: w.last,
+		Stale:        w.stale,
+		StaleSince:   w.staleSince,
+		StaleCount:   w.staleCount,
+		Timeout:      w.timeout,
+		Sources:      make([]*seesaw.HealthcheckSource, 0, len(w.sources)),
+	}
+	for source, last := range w.sources {
+		hs.Sources = append(hs.Sources, &seesaw.HealthcheckSource{
+			Component:    source,
+			LastDelivery: last,
+			Stale:        w.staleSrcs[source],
+		})
+	}
+	sort.Slice(hs.Sources, func(i, j int) bool {
+		return hs.Sources[i].Component < hs.Sources[j].Component
+	})
+	return hs
+}
@@ -0,0 +1,156 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/seesaw/common/seesaw"
+	"github.com/google/seesaw/engine/config"
+	"github.com/google/seesaw/healthcheck"
+)
+
+// fakeClock provides a manually advanced time source.
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.t
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.t = c.t.Add(d)
+}
+
+// newTestWatchdog returns a watchdog for an engine with a single configured
+// healthcheck, along with the vserver that receives its notifications.
+func newTestWatchdog(policy config.StalePolicy) (*healthcheckWatchdog, *fakeClock, *vserver) {
+	e := newTestEngine()
+	e.config.HealthcheckStaleTimeout = time.Minute
+	e.config.HealthcheckStalePolicy = policy
+	v := newTestVserver(e)
+
+	id := healthcheck.Id(1)
+	key := CheckKey{BackendIP: seesaw.ParseIP("10.0.1.1"), HealthcheckType: seesaw.HCTypeTCP}
+	hc := &config.Healthcheck{Type: seesaw.HCTypeTCP, Port: 80}
+	e.hcManager.ids = map[checkerKey]healthcheck.Id{{key: key, cfg: *hc}: id}
+	e.hcManager.cfgs = map[healthcheck.Id]*healthcheck.Config{
+		id: healthcheck.NewConfig(id, healthcheck.NewTCPChecker(key.BackendIP.IP(), 80)),
+	}
+	e.hcManager.checks = map[healthcheck.Id][]*check{id: {newCheck(key, v, hc)}}
+
+	clock := &fakeClock{t: time.Unix(1000000, 0)}
+	w := newHealthcheckWatchdog(e)
+	w.now = clock.now
+	return w, clock, v
+}
+
+func TestWatchdogStale(t *testing.T) {
+	w, clock, v := newTestWatchdog(config.StalePolicyFreeze)
+
+	w.check()
+	w.delivered(seesaw.SCHealthcheck)
+	clock.advance(30 * time.Second)
+	w.check()
+	if hs := w.status(); hs.Stale {
+		t.Fatalf("Watchdog is stale after 30s: %+v", hs)
+	}
+
+	clock.advance(31 * time.Second)
+	w.check()
+	hs := w.status()
+	if !hs.Stale || hs.StaleCount != 1 || !hs.StaleSince.Equal(clock.now()) {
+		t.Errorf("Got status %+v after 61s, want stale", hs)
+	}
+	if len(hs.Sources) != 1 || hs.Sources[0].Component != seesaw.SCHealthcheck || !hs.Sources[0].Stale {
+		t.Errorf("Got sources %+v, want stale healthcheck source", hs.Sources)
+	}
+
+	// Repeated checks must not raise the condition again.
+	clock.advance(time.Minute)
+	w.check()
+	if hs := w.status(); hs.StaleCount != 1 {
+		t.Errorf("Got stale count %d, want 1", hs.StaleCount)
+	}
+
+	// The freeze policy must leave the healthcheck states untouched.
+	select {
+	case n := <-v.notify:
+		t.Errorf("Got unexpected check notification %v", n)
+	default:
+	}
+
+	w.delivered(seesaw.SCHealthcheck)
+	hs = w.status()
+	if hs.Stale || !hs.StaleSince.IsZero() || hs.Sources[0].Stale {
+		t.Errorf("Got status %+v after delivery, want recovered", hs)
+	}
+	if !hs.LastDelivery.Equal(clock.now()) {
+		t.Errorf("Got last delivery %v, want %v", hs.LastDelivery, clock.now())
+	}
+}
+
+func TestWatchdogNoDelivery(t *testing.T) {
+	w, clock, _ := newTestWatchdog(config.StalePolicyFreeze)
+
+	// The time that the watchdog starts is used in the absence of any
+	// deliveries.
+	w.check()
+	clock.advance(50 * time.Second)
+	w.check()
+	if w.status().Stale {
+		t.Errorf("Watchdog is stale after 50s")
+	}
+	clock.advance(20 * time.Second)
+	w.check()
+	if !w.status().Stale {
+		t.Errorf("Watchdog is not stale after 70s without delivery")
+	}
+}
+
+func TestWatchdogNoHealthchecks(t *testing.T) {
+	w, clock, _ := newTestWatchdog(config.StalePolicyFreeze)
+	w.engine.hcManager.cfgs = nil
+
+	w.check()
+	w.delivered(seesaw.SCHealthcheck)
+	clock.advance(time.Hour)
+	w.check()
+	if hs := w.status(); hs.Stale || hs.Sources[0].Stale {
+		t.Errorf("Got status %+v without healthchecks, want not stale", hs)
+	}
+}
+
+func TestWatchdogUnknownPolicy(t *testing.T) {
+	w, clock, v := newTestWatchdog(config.StalePolicyUnknown)
+
+	w.check()
+	clock.advance(2 * time.Minute)
+	w.check()
+	if !w.status().Stale {
+		t.Fatalf("Watchdog is not stale after 2m without delivery")
+	}
+
+	select {
+	case n := <-v.notify:
+		if n.status.State != healthcheck.StateUnknown {
+			t.Errorf("Got check state %v, want %v", n.status.State, healthcheck.StateUnknown)
+		}
+	default:
+		t.Errorf("No check notification queued for unknown policy")
+	}
+}
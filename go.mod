@@ -10,13 +10,13 @@ require (
 	github.com/kylelemons/godebug v1.1.0
 	github.com/miekg/dns v1.1.51
 	golang.org/x/crypto v0.45.0
+	golang.org/x/sys v0.38.0
 	google.golang.org/protobuf v1.33.0
 )
 
 require (
 	golang.org/x/mod v0.7.0 // indirect
 	golang.org/x/net v0.47.0 // indirect
-	golang.org/x/sys v0.38.0 // indirect
 	golang.org/x/term v0.37.0 // indirect
 	golang.org/x/tools v0.3.0 // indirect
 )
@@ -70,6 +70,7 @@ type NodeConfig struct {
 	ConfigCheckRetryDelay   time.Duration
 	MasterAdvertInterval    time.Duration
 	Preempt                 bool
+	PreemptDelay            time.Duration // Time that a lower priority master must be seen for before preempting.
 	StatusReportInterval    time.Duration
 	StatusReportMaxFailures int
 	StatusReportRetryDelay  time.Duration
@@ -87,6 +88,7 @@ type Node struct {
 	receiveCount         uint64
 	masterDownInterval   time.Duration
 	lastMasterAdvertTime time.Time
+	preemptStart         time.Time // Guarded by statusLock.
 	errChannel           chan error
 	recvChannel          chan *advertisement
 	stopSenderChannel    chan spb.HaState
@@ -136,6 +138,7 @@ func (n *Node) setState(s spb.HaState) {
 		n.haStatus.State = s
 		n.haStatus.Since = time.Now()
 		n.haStatus.Transitions++
+		n.preemptStart = time.Time{}
 	}
 }
 
@@ -146,9 +149,42 @@ func (n *Node) status() seesaw.HAStatus {
 	n.haStatus.Sent = atomic.LoadUint64(&n.sendCount)
 	n.haStatus.Received = atomic.LoadUint64(&n.receiveCount)
 	n.haStatus.ReceivedQueued = uint64(len(n.recvChannel))
+	n.haStatus.PreemptIn = 0
+	if !n.preemptStart.IsZero() {
+		if remaining := n.PreemptDelay - time.Since(n.preemptStart); remaining > 0 {
+			n.haStatus.PreemptIn = remaining
+		}
+	}
 	return n.haStatus
 }
 
+// preemptReady returns true if this node has waited long enough to preempt a
+// lower priority master. The wait starts with the first advertisement from a
+// lower priority master, and is restarted if an advertisement is received
+// that should not be preempted, or if this node changes state.
+func (n *Node) preemptReady() bool {
+	n.statusLock.Lock()
+	defer n.statusLock.Unlock()
+	if n.PreemptDelay <= 0 {
+		return true
+	}
+	if n.preemptStart.IsZero() {
+		log.Infof("preemptReady: waiting %v before preempting lower priority master", n.PreemptDelay)
+		n.preemptStart = time.Now()
+	}
+	return time.Since(n.preemptStart) >= n.PreemptDelay
+}
+
+// resetPreempt restarts the wait before preempting a lower priority master.
+func (n *Node) resetPreempt() {
+	n.statusLock.Lock()
+	defer n.statusLock.Unlock()
+	if !n.preemptStart.IsZero() {
+		log.Infof("resetPreempt: no longer waiting to preempt lower priority master")
+		n.preemptStart = time.Time{}
+	}
+}
+
 // newAdvertisement creates a new advertisement with this Node's VRID and priority.
 func (n *Node) newAdvertisement() *advertisement {
 	return &advertisement{
@@ -369,9 +405,14 @@ func (n *Node) backupHandleAdvertisement(advert *advertisement) spb.HaState {
 		return spb.HaState_LEADER
 
 	case n.Preempt && advert.Priority < n.Priority:
-		log.Infof("backupHandleAdvertisement: peer priority (%v) < my priority (%v) - becoming MASTER",
-			advert.Priority, n.Priority)
-		return spb.HaState_LEADER
+		if n.preemptReady() {
+			log.Infof("backupHandleAdvertisement: peer priority (%v) < my priority (%v) - becoming MASTER",
+				advert.Priority, n.Priority)
+			return spb.HaState_LEADER
+		}
+
+	default:
+		n.resetPreempt()
 	}
 
 	// Per RFC 5798, set the masterDownInterval based on the advert interval received from the
@@ -171,6 +171,49 @@ func TestPreempt(t *testing.T) {
 	}
 }
 
+func TestPreemptDelay(t *testing.T) {
+	node := newTestNode()
+	node.Preempt = true
+	node.PreemptDelay = time.Hour
+	node.queueAdvertisement(&vrrpTestAdvert)
+	node.runOnce()
+	if node.state() != spb.HaState_BACKUP {
+		t.Errorf("Expected state to be %v but was %v", spb.HaState_BACKUP, node.state())
+	}
+	if in := node.status().PreemptIn; in <= 0 || in > time.Hour {
+		t.Errorf("Expected to preempt within %v but was %v", time.Hour, in)
+	}
+
+	// An advertisement from a master that cannot be preempted restarts the wait.
+	advert := vrrpTestAdvert
+	advert.Priority = 200
+	node.queueAdvertisement(&advert)
+	node.runOnce()
+	if in := node.status().PreemptIn; in != 0 {
+		t.Errorf("Expected no preemption wait but was %v", in)
+	}
+
+	node.queueAdvertisement(&vrrpTestAdvert)
+	node.runOnce()
+	if node.state() != spb.HaState_BACKUP {
+		t.Errorf("Expected state to be %v but was %v", spb.HaState_BACKUP, node.state())
+	}
+	node.statusLock.Lock()
+	node.preemptStart = time.Now().Add(-time.Hour)
+	node.statusLock.Unlock()
+	node.queueAdvertisement(&vrrpTestAdvert)
+	node.runOnce()
+	if node.state() != spb.HaState_LEADER {
+		t.Errorf("Expected state to be %v but was %v", spb.HaState_LEADER, node.state())
+	}
+	if in := node.status().PreemptIn; in != 0 {
+		t.Errorf("Expected no preemption wait after preempting but was %v", in)
+	}
+
+	// clean up
+	node.becomeBackup()
+}
+
 func TestShutdown(t *testing.T) {
 	node := newTestNode()
 	advert := vrrpTestAdvert
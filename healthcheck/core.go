@@ -18,29 +18,60 @@
 package healthcheck
 
 import (
+	"context"
 	"encoding/gob"
 	"fmt"
 	"math/rand"
 	"net"
 	"net/rpc"
+	"os"
 	"sync"
 	"time"
 
 	"github.com/google/seesaw/common/ipc"
 	"github.com/google/seesaw/common/seesaw"
+	"github.com/google/seesaw/common/server"
 
 	log "github.com/golang/glog"
 )
 
-const engineTimeout = 10 * time.Second
+const (
+	engineTimeout = 10 * time.Second
+
+	// overlapCancelWait is the maximum time to wait for a cancelled
+	// healthcheck run to complete before skipping the new run.
+	overlapCancelWait = 1 * time.Second
+
+	// maxJitterPercent bounds the scheduling jitter, so that runs are never
+	// less than half an interval apart.
+	maxJitterPercent = 50
+
+	// fetchMinInterval is the minimum time between healthcheck config
+	// fetches, which limits the rate of fetches while configs are changing.
+	fetchMinInterval = 500 * time.Millisecond
+)
 
 func init() {
 	rand.Seed(time.Now().UnixNano())
 
 	gob.Register(&DNSChecker{})
+	gob.Register(&FTPChecker{})
+	gob.Register(&GRPCChecker{})
 	gob.Register(&HTTPChecker{})
+	gob.Register(&IMAPChecker{})
+	gob.Register(&LDAPChecker{})
+	gob.Register(&MemcacheChecker{})
+	gob.Register(&MySQLChecker{})
+	gob.Register(&NTPChecker{})
+	gob.Register(&POP3Checker{})
 	gob.Register(&PingChecker{})
+	gob.Register(&PostgresChecker{})
 	gob.Register(&RADIUSChecker{})
+	gob.Register(&RedisChecker{})
+	gob.Register(&SIPChecker{})
+	gob.Register(&ScriptChecker{})
+	gob.Register(&SMTPChecker{})
+	gob.Register(&SYNChecker{})
 	gob.Register(&TCPChecker{})
 	gob.Register(&UDPChecker{})
 }
@@ -77,6 +108,19 @@ type Checker interface {
 	String() string
 }
 
+// ContextChecker is implemented by healthchecks that may be cancelled while
+// they are running.
+type ContextChecker interface {
+	CheckContext(ctx context.Context, timeout time.Duration) *Result
+}
+
+// StatefulChecker is implemented by healthchecks that hold state across runs,
+// such as cached connections. When a healthcheck's configuration is updated,
+// the new checker inherits the state of the previous checker.
+type StatefulChecker interface {
+	Inherit(prev Checker)
+}
+
 // Target specifies the target for a healthcheck.
 type Target struct {
 	IP    net.IP // IP address of the healthcheck target.
@@ -156,6 +200,10 @@ func complete(start time.Time, msg string, success bool, err error) *Result {
 type Notification struct {
 	Id
 	Status
+
+	check      *Check
+	seq        uint64 // Orders the notifications for a healthcheck.
+	transition bool   // The notification is for a state transition.
 }
 
 // String returns the string representation for the given notification.
@@ -169,17 +217,44 @@ type HealthState struct {
 	Notifications []*Notification
 }
 
+// ChecksRequest contains data for a healthcheck configuration IPC, which
+// waits for up to Wait for the configurations to differ from Version.
+type ChecksRequest struct {
+	Ctx     *ipc.Context
+	Version uint64
+	Wait    time.Duration
+}
+
 // Checks provides a map of healthcheck configurations.
 type Checks struct {
 	Configs map[Id]*Config
+
+	// MaxConcurrent limits the number of healthchecks that are executed
+	// concurrently, if non-zero.
+	MaxConcurrent int
+
+	// Version identifies the set of configurations, and changes whenever
+	// the configurations change.
+	Version uint64
 }
 
 // Config contains the configuration for a healthcheck.
+//
+// A healthy check becomes unhealthy after Fall consecutive failures, or
+// Retries + 1 if that is greater, and an unhealthy check becomes healthy
+// after Rise consecutive successes. Values below one are treated as one.
+//
+// A passive healthcheck runs and reports its status as usual, but the status
+// is tagged so that the engine does not act upon it.
 type Config struct {
 	Id
-	Interval time.Duration
-	Timeout  time.Duration
-	Retries  int
+	Interval   time.Duration
+	Timeout    time.Duration
+	Retries    int
+	Rise       int
+	Fall       int
+	MaxLatency time.Duration // Slower successful checks fail, if non-zero.
+	Passive    bool
 	Checker
 }
 
@@ -190,16 +265,44 @@ func NewConfig(id Id, checker Checker) *Config {
 		Interval: 5 * time.Second,
 		Timeout:  30 * time.Second,
 		Retries:  0,
+		Rise:     1,
+		Fall:     1,
 		Checker:  checker,
 	}
 }
 
+// riseThreshold returns the number of consecutive successes required for an
+// unhealthy check to become healthy.
+func (c *Config) riseThreshold() uint64 {
+	if c.Rise < 1 {
+		return 1
+	}
+	return uint64(c.Rise)
+}
+
+// fallThreshold returns the number of consecutive failures required for a
+// healthy check to become unhealthy.
+func (c *Config) fallThreshold() uint64 {
+	fall := c.Fall
+	if c.Retries+1 > fall {
+		fall = c.Retries + 1
+	}
+	if fall < 1 {
+		return 1
+	}
+	return uint64(fall)
+}
+
 // Status represents the current status of a healthcheck instance.
 type Status struct {
 	LastCheck time.Time
 	Duration  time.Duration
 	Failures  uint64
 	Successes uint64
+	Skipped   uint64
+	Throttled uint64        // Runs skipped due to the concurrency limit.
+	Queued    time.Duration // The time the last run waited to start.
+	Passive   bool          // The status is reported but not acted upon.
 	State
 	Message string
 }
@@ -208,28 +311,54 @@ type Status struct {
 type Check struct {
 	Config
 
-	lock      sync.RWMutex
-	blocking  bool
-	dryrun    bool
-	start     time.Time
-	failed    uint64
-	failures  uint64
-	successes uint64
-	state     State
-	result    *Result
-
-	update chan Config
-	notify chan<- *Notification
-	quit   chan bool
+	lock          sync.RWMutex
+	blocking      bool
+	dryrun        bool
+	cancelOverlap bool
+	splay         bool
+	jitter        int // Percentage of the interval.
+	start         time.Time
+	failed        uint64 // Consecutive failures.
+	succeeded     uint64 // Consecutive successes.
+	failures      uint64
+	successes     uint64
+	skipped       uint64
+	skipping      bool
+	throttled     uint64
+	throttling    bool
+	queued        time.Duration
+	passive       bool          // Whether the last result was from a passive run.
+	limiter       *checkLimiter // Bounds concurrent runs, if non-nil.
+	state         State
+	result        *Result
+	history       *history // Recent results, if non-nil.
+
+	// Used to order notifications and to determine whether the counters
+	// need to be synced with the engine.
+	seq               uint64
+	transitionSeq     uint64
+	notifiedSkipped   uint64
+	notifiedThrottled uint64
+
+	// Accessed only by the goroutine running the healthcheck.
+	inflight chan struct{}      // Closed once the checker returns.
+	cancel   context.CancelFunc // Cancels the running checker.
+
+	update     chan Config
+	notify     chan<- *Notification
+	transition chan<- *Notification // Receives state transitions.
+	quit       chan bool
 }
 
-// NewCheck returns an initialised Check.
+// NewCheck returns an initialised Check. Both steady-state notifications and
+// state transitions are sent to the given notification channel.
 func NewCheck(notify chan<- *Notification) *Check {
 	return &Check{
-		state:  StateUnknown,
-		notify: notify,
-		update: make(chan Config, 1),
-		quit:   make(chan bool, 1),
+		state:      StateUnknown,
+		notify:     notify,
+		transition: notify,
+		update:     make(chan Config, 1),
+		quit:       make(chan bool, 1),
 	}
 }
 
@@ -237,26 +366,43 @@ func NewCheck(notify chan<- *Notification) *Check {
 func (hc *Check) Status() Status {
 	hc.lock.RLock()
 	defer hc.lock.RUnlock()
+	return hc.status()
+}
+
+// status returns the current status for this healthcheck instance. It must be
+// called with the lock held.
+func (hc *Check) status() Status {
 	status := Status{
 		LastCheck: hc.start,
 		Failures:  hc.failures,
 		Successes: hc.successes,
+		Skipped:   hc.skipped,
+		Throttled: hc.throttled,
+		Queued:    hc.queued,
+		Passive:   hc.passive,
 		State:     hc.state,
 	}
 	if hc.result != nil {
 		status.Duration = hc.result.Duration
 		status.Message = hc.result.String()
 	}
+	if hc.skipping {
+		status.Message = "skipped: previous still running"
+	}
+	if hc.throttling {
+		status.Message = "skipped: concurrency limit reached"
+	}
 	return status
 }
 
 // Run invokes a healthcheck. It waits for the initial configuration to be
 // provided via the configuration channel, after which the configured
-// healthchecker is invoked at the given interval. If a new configuration
-// is provided the healthchecker is updated and checks are scheduled at the
-// new interval. Notifications are generated and sent via the notification
-// channel whenever a state transition occurs. Run will terminate once a
-// value is received on the quit channel.
+// healthchecker is invoked, with each run scheduled the interval after the
+// previous run completed. If a new configuration is provided the
+// healthchecker is updated and checks are scheduled at the new interval.
+// Notifications are generated and sent via the notification channel
+// whenever a state transition occurs. Run will terminate once a value is
+// received on the quit channel.
 func (hc *Check) Run(start <-chan time.Time) {
 
 	// Wait for initial configuration.
@@ -274,99 +420,294 @@ func (hc *Check) Run(start <-chan time.Time) {
 	}
 	log.Infof("Starting healthchecker for %d (%s)", hc.Id, hc)
 
-	ticker := time.NewTicker(hc.Interval)
-	hc.healthcheck()
+	delay := hc.firstDelay()
+	if delay == 0 {
+		hc.healthcheck()
+		delay = hc.nextDelay()
+	}
+	timer := time.NewTimer(delay)
 	for {
 		select {
 		case <-hc.quit:
-			ticker.Stop()
+			timer.Stop()
 			log.Infof("Stopping healthchecker for %d (%s)", hc.Id, hc)
 			return
 
 		case config := <-hc.update:
-			if hc.Interval != config.Interval {
-				ticker.Stop()
-				if start != nil {
-					<-start
-				}
-				ticker = time.NewTicker(config.Interval)
+			reschedule := hc.Interval != config.Interval
+			if reschedule && start != nil {
+				<-start
+			}
+			if sc, ok := config.Checker.(StatefulChecker); ok && hc.Checker != nil {
+				sc.Inherit(hc.Checker)
 			}
 			hc.Config = config
+			if reschedule {
+				timer.Reset(hc.firstDelay())
+			}
 
-		case <-ticker.C:
+		case <-timer.C:
 			hc.healthcheck()
+			timer.Reset(hc.nextDelay())
 		}
 	}
 }
 
-// healthcheck executes the given checker.
+// firstDelay returns the delay before the first run at the current interval.
+// When splaying is enabled this is a random fraction of the interval, so that
+// healthchecks with the same interval do not run in lockstep.
+func (hc *Check) firstDelay() time.Duration {
+	if !hc.splay || hc.Interval <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(hc.Interval)))
+}
+
+// nextDelay returns the delay between the completion of a run and the start
+// of the next, which is the interval varied by up to the jitter percentage.
+func (hc *Check) nextDelay() time.Duration {
+	if hc.jitter <= 0 || hc.Interval <= 0 {
+		return hc.Interval
+	}
+	jitter := int64(hc.Interval) * int64(hc.jitter) / 100
+	return hc.Interval + time.Duration(rand.Int63n(2*jitter+1)-jitter)
+}
+
+// running returns true if the checker from a previous run has not returned.
+func (hc *Check) running() bool {
+	if hc.inflight == nil {
+		return false
+	}
+	select {
+	case <-hc.inflight:
+		return false
+	default:
+		return true
+	}
+}
+
+// overlapping determines whether a previous run of the checker is still in
+// progress, which occurs if the checker does not return within the timeout.
+// If cancellation is enabled and supported by the checker, the previous run
+// is cancelled. It returns true if the previous run is still in progress.
+func (hc *Check) overlapping() bool {
+	if !hc.running() {
+		return false
+	}
+	if !hc.cancelOverlap || hc.cancel == nil {
+		return true
+	}
+	log.Warningf("%d: (%s) cancelling previous run, still running", hc.Id, hc)
+	hc.cancel()
+	select {
+	case <-hc.inflight:
+		return false
+	case <-time.After(overlapCancelWait):
+		return true
+	}
+}
+
+// skip records that a run was skipped since the previous run is still in
+// progress.
+func (hc *Check) skip() {
+	log.Warningf("%d: (%s) skipped: previous still running", hc.Id, hc)
+	hc.lock.Lock()
+	hc.skipped++
+	hc.skipping = true
+	hc.lock.Unlock()
+}
+
+// throttle records that a run was skipped since the concurrency limit did
+// not allow it to start before the next run was due.
+func (hc *Check) throttle() {
+	log.Warningf("%d: (%s) skipped: concurrency limit reached", hc.Id, hc)
+	hc.lock.Lock()
+	hc.throttled++
+	hc.throttling = true
+	hc.queued = hc.Interval
+	hc.lock.Unlock()
+}
+
+// healthcheck executes the given checker, unless a previous run is still in
+// progress.
 func (hc *Check) healthcheck() {
 	if hc.Checker == nil {
 		return
 	}
+	if hc.overlapping() {
+		hc.skip()
+		return
+	}
+
+	// Wait for the concurrency limit to allow the run, for no longer than
+	// the interval, so that delayed runs do not accumulate.
+	var release func()
+	var queued time.Duration
+	if hc.limiter != nil && !hc.dryrun {
+		release, queued = hc.limiter.acquire(hc.Interval)
+		if release == nil {
+			hc.throttle()
+			return
+		}
+		if queued > hc.Interval/10 {
+			log.Warningf("%d: (%s) waited %v for the concurrency limit", hc.Id, hc, queued)
+		}
+	}
 	start := time.Now()
 
 	var result *Result
 	if hc.dryrun {
 		result = complete(start, "dryrun mode; always succeed", true, nil)
 	} else {
-		result = hc.execute()
+		result = hc.execute(release)
+	}
+	if threshold := hc.MaxLatency; threshold > 0 && result.Success && result.Duration > threshold {
+		msg := fmt.Sprintf("latency %v exceeds threshold %v", result.Duration.Round(time.Millisecond), threshold)
+		if result.Message != "" {
+			msg = fmt.Sprintf("%s; %s", result.Message, msg)
+		}
+		result = &Result{msg, false, result.Duration, nil}
 	}
 
 	status := "SUCCESS"
 	if !result.Success {
 		status = "FAILURE"
 	}
+	if hc.Passive {
+		status += " (passive)"
+	}
 	log.Infof("%d: (%s) %s: %v", hc.Id, hc, status, result)
 
 	hc.lock.Lock()
 
 	hc.start = start
 	hc.result = result
+	hc.skipping = false
+	hc.throttling = false
+	hc.queued = queued
+	hc.passive = hc.Passive
+	if hc.history != nil {
+		hc.history.add(seesaw.HealthcheckResult{
+			Time:     start,
+			Duration: result.Duration,
+			Success:  result.Success,
+			Message:  result.String(),
+		})
+	}
 
-	var state State
 	if result.Success {
-		state = StateHealthy
 		hc.failed = 0
+		hc.succeeded++
 		hc.successes++
 	} else {
+		hc.succeeded = 0
 		hc.failed++
 		hc.failures++
-		state = StateUnhealthy
 	}
 
-	if hc.state == StateHealthy && hc.failed > 0 && hc.failed <= uint64(hc.Config.Retries) {
-		log.Infof("%d: Failure %d - retrying...", hc.Id, hc.failed)
-		state = StateHealthy
+	state := hc.nextState(result.Success)
+	var transition *Notification
+	if hc.state != state {
+		hc.state = state
+		transition = hc.notification(true)
 	}
-	transition := (hc.state != state)
-	hc.state = state
 
 	hc.lock.Unlock()
 
-	if transition {
-		hc.Notify()
+	if transition != nil {
+		hc.transition <- transition
+	}
+}
+
+// nextState returns the state following a healthcheck result, given the
+// current counts of consecutive successes and failures. The first result
+// determines the initial state. It must be called with the lock held.
+func (hc *Check) nextState(success bool) State {
+	switch {
+	case hc.state == StateUnknown && success:
+		return StateHealthy
+	case hc.state == StateUnknown:
+		return StateUnhealthy
+	case success && hc.state != StateHealthy:
+		if rise := hc.Config.riseThreshold(); hc.succeeded < rise {
+			log.Infof("%d: Success %d of %d - waiting to rise...", hc.Id, hc.succeeded, rise)
+			return hc.state
+		}
+		return StateHealthy
+	case !success && hc.state != StateUnhealthy:
+		if fall := hc.Config.fallThreshold(); hc.failed < fall {
+			log.Infof("%d: Failure %d - retrying...", hc.Id, hc.failed)
+			return hc.state
+		}
+		return StateUnhealthy
 	}
+	return hc.state
 }
 
-// Notify generates a healthcheck notification for this checker.
+// Notify generates a steady-state healthcheck notification for this checker.
 func (hc *Check) Notify() {
-	hc.notify <- &Notification{
-		Id:     hc.Id,
-		Status: hc.Status(),
+	hc.lock.Lock()
+	n := hc.notification(false)
+	hc.lock.Unlock()
+	hc.notify <- n
+}
+
+// notification returns a notification for the current status of this
+// healthcheck. It must be called with the lock held.
+func (hc *Check) notification(transition bool) *Notification {
+	hc.seq++
+	if transition {
+		hc.transitionSeq = hc.seq
+	}
+	hc.notifiedSkipped = hc.skipped
+	hc.notifiedThrottled = hc.throttled
+	return &Notification{
+		Id:         hc.Id,
+		Status:     hc.status(),
+		check:      hc,
+		seq:        hc.seq,
+		transition: transition,
 	}
 }
 
+// needsSync returns true if the skipped or throttled counters have changed
+// since the last notification for this healthcheck.
+func (hc *Check) needsSync() bool {
+	hc.lock.RLock()
+	defer hc.lock.RUnlock()
+	return hc.skipped != hc.notifiedSkipped || hc.throttled != hc.notifiedThrottled
+}
+
 // execute invokes the given healthcheck checker with the configured timeout.
 // The checker goroutine may outlive the timeout if the checker itself does not
 // respect the timeout parameter, but the buffered channel ensures it will not
-// block and will be garbage collected once the check completes.
-func (hc *Check) execute() *Result {
+// block and will be garbage collected once the check completes. Subsequent
+// runs are not started until the checker goroutine has completed. If release
+// is non-nil, it is called once the checker goroutine has completed.
+func (hc *Check) execute(release func()) *Result {
 	ch := make(chan *Result, 1)
+	done := make(chan struct{})
 	checker := hc.Checker
 	timeout := hc.Timeout
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cc, ok := checker.(ContextChecker)
+	hc.inflight = done
+	hc.cancel = nil
+	if ok {
+		hc.cancel = cancel
+	}
 	go func() {
-		ch <- checker.Check(timeout)
+		defer close(done)
+		defer cancel()
+		if release != nil {
+			defer release()
+		}
+		if cc != nil {
+			ch <- cc.CheckContext(ctx, timeout)
+		} else {
+			ch <- checker.Check(timeout)
+		}
 	}()
 	select {
 	case result := <-ch:
@@ -376,8 +717,14 @@ func (hc *Check) execute() *Result {
 	}
 }
 
-// Stop notifies a running healthcheck that it should quit.
+// Stop notifies a running healthcheck that it should quit, discarding its
+// recent results.
 func (hc *Check) Stop() {
+	hc.lock.Lock()
+	if hc.history != nil {
+		hc.history.clear()
+	}
+	hc.lock.Unlock()
 	select {
 	case hc.quit <- true:
 	default:
@@ -399,6 +746,54 @@ func (hc *Check) Dryrun(dryrun bool) {
 	hc.dryrun = dryrun
 }
 
+// CancelOverlapping enables or disables the cancellation of a previous run
+// that is still in progress when the healthcheck is next due to run. When
+// disabled, or if the checker does not implement ContextChecker, the new run
+// is skipped instead.
+func (hc *Check) CancelOverlapping(cancel bool) {
+	hc.cancelOverlap = cancel
+}
+
+// Splay enables or disables delaying the first run of a healthcheck by a
+// random fraction of its interval.
+func (hc *Check) Splay(splay bool) {
+	hc.splay = splay
+}
+
+// Jitter sets the percentage of the interval by which the time between runs
+// is randomly varied, limited to 50%.
+func (hc *Check) Jitter(percent int) {
+	if percent < 0 {
+		percent = 0
+	}
+	if percent > maxJitterPercent {
+		percent = maxJitterPercent
+	}
+	hc.jitter = percent
+}
+
+// History sets the number of recent results that are retained for a
+// healthcheck, discarding any results already retained. No results are
+// retained if size is zero.
+func (hc *Check) History(size int) {
+	hc.lock.Lock()
+	defer hc.lock.Unlock()
+	hc.history = nil
+	if size > 0 {
+		hc.history = newHistory(size)
+	}
+}
+
+// Results returns the recent results of a healthcheck, oldest first.
+func (hc *Check) Results() []*seesaw.HealthcheckResult {
+	hc.lock.RLock()
+	defer hc.lock.RUnlock()
+	if hc.history == nil {
+		return nil
+	}
+	return hc.history.list()
+}
+
 // Update queues a healthcheck configuration update for processing.
 func (hc *Check) Update(config *Config) {
 	if hc.blocking {
@@ -412,10 +807,64 @@ func (hc *Check) Update(config *Config) {
 	}
 }
 
+// checkLimiter bounds the number of healthchecks that are executing
+// concurrently. A limit of zero or less disables the limiter.
+type checkLimiter struct {
+	lock sync.Mutex
+	sem  chan struct{}
+}
+
+// newCheckLimiter returns a checkLimiter with the given limit.
+func newCheckLimiter(limit int) *checkLimiter {
+	l := &checkLimiter{}
+	l.setLimit(limit)
+	return l
+}
+
+// setLimit changes the limit. Runs that started under the previous limit
+// still count against it, so the new limit fully applies once they complete.
+func (l *checkLimiter) setLimit(limit int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	switch {
+	case limit <= 0:
+		l.sem = nil
+	case l.sem == nil || cap(l.sem) != limit:
+		l.sem = make(chan struct{}, limit)
+	}
+}
+
+// acquire waits up to timeout for the limit to allow a run to start. It
+// returns a function that must be called once the run completes, or nil if
+// the run cannot start, along with the time spent waiting.
+func (l *checkLimiter) acquire(timeout time.Duration) (func(), time.Duration) {
+	l.lock.Lock()
+	sem := l.sem
+	l.lock.Unlock()
+	if sem == nil {
+		return func() {}, 0
+	}
+	release := func() { <-sem }
+	select {
+	case sem <- struct{}{}:
+		return release, 0
+	default:
+	}
+	start := time.Now()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case sem <- struct{}{}:
+		return release, time.Since(start)
+	case <-timer.C:
+		return nil, time.Since(start)
+	}
+}
+
 // ServerConfig specifies the configuration for a healthcheck server.
 type ServerConfig struct {
 	BatchDelay     time.Duration
-	BatchSize      int
+	MaxBatchSize   int
 	ChannelSize    int
 	EngineSocket   string
 	MaxFailures    int
@@ -423,17 +872,50 @@ type ServerConfig struct {
 	FetchInterval  time.Duration
 	RetryDelay     time.Duration
 	DryRun         bool
+
+	// SteadyIntervals is the number of notify intervals between steady-state
+	// notifications for healthchecks that have not changed state. In between,
+	// only healthchecks with counters that need syncing are notified.
+	SteadyIntervals int
+
+	CancelOverlapping bool
+	UnprivilegedPing  bool
+
+	// JitterPercent randomly varies the time between healthcheck runs by up
+	// to this percentage of the interval, limited to 50%.
+	JitterPercent int
+
+	// MaxConcurrentChecks limits the number of healthchecks that are
+	// executed concurrently, until a limit is provided by the engine.
+	MaxConcurrentChecks int
+
+	// HistorySize is the number of recent results retained for each
+	// healthcheck, which are served on Socket.
+	HistorySize int
+	Socket      string
+
+	// ScriptDir is the directory containing the scripts that may be run by
+	// script healthchecks. Script healthchecks fail if it is empty.
+	ScriptDir string
+	// MaxScripts limits the number of concurrently running scripts.
+	MaxScripts int
 }
 
 var defaultServerConfig = ServerConfig{
 	BatchDelay:     100 * time.Millisecond,
-	BatchSize:      100,
+	MaxBatchSize:   100,
 	ChannelSize:    1000,
 	EngineSocket:   seesaw.EngineSocket,
 	MaxFailures:    10,
 	NotifyInterval: 15 * time.Second,
 	FetchInterval:  15 * time.Second,
 	RetryDelay:     2 * time.Second,
+	MaxScripts:     8,
+
+	SteadyIntervals:     2,
+	MaxConcurrentChecks: 1000,
+	HistorySize:         16,
+	Socket:              seesaw.HealthcheckSocket,
 }
 
 // DefaultServerConfig returns the default server configuration.
@@ -443,12 +925,17 @@ func DefaultServerConfig() ServerConfig {
 
 // Server contains the data needed to run a healthcheck server.
 type Server struct {
-	config *ServerConfig
+	config    *ServerConfig
+	engine    *engineClient
+	scriptSem chan struct{}
+	limiter   *checkLimiter
 
 	healthchecks map[Id]*Check
-	configs      chan map[Id]*Config
+	lock         sync.RWMutex // Guards healthchecks.
+	configs      chan *Checks
 	notify       chan *Notification
-	batch        []*Notification
+	transition   chan *Notification
+	pending      *notifyQueue
 
 	quit chan bool
 }
@@ -459,13 +946,21 @@ func NewServer(cfg *ServerConfig) *Server {
 		defaultCfg := DefaultServerConfig()
 		cfg = &defaultCfg
 	}
+	var scriptSem chan struct{}
+	if cfg.MaxScripts > 0 {
+		scriptSem = make(chan struct{}, cfg.MaxScripts)
+	}
 	return &Server{
-		config: cfg,
+		config:    cfg,
+		engine:    newEngineClient(cfg.EngineSocket),
+		scriptSem: scriptSem,
+		limiter:   newCheckLimiter(cfg.MaxConcurrentChecks),
 
 		healthchecks: make(map[Id]*Check),
 		notify:       make(chan *Notification, cfg.ChannelSize),
-		configs:      make(chan map[Id]*Config),
-		batch:        make([]*Notification, 0, cfg.BatchSize),
+		transition:   make(chan *Notification, cfg.ChannelSize),
+		configs:      make(chan *Checks),
+		pending:      newNotifyQueue(),
 
 		quit: make(chan bool, 1),
 	}
@@ -481,6 +976,16 @@ func (s *Server) Shutdown() {
 
 // Run runs a healthcheck server.
 func (s *Server) Run() {
+	if ln := s.listen(); ln != nil {
+		defer os.Remove(s.config.Socket)
+		defer ln.Close()
+		seesawHealthcheck := rpc.NewServer()
+		seesawHealthcheck.Register(&SeesawHealthcheck{s})
+		go server.RPCAccept(ln, seesawHealthcheck)
+	}
+
+	defer s.engine.close()
+
 	go s.updater()
 	go s.notifier()
 	go s.manager()
@@ -488,41 +993,62 @@ func (s *Server) Run() {
 	<-s.quit
 }
 
-// getHealthchecks attempts to get the current healthcheck configurations from
-// the Seesaw Engine.
-func (s *Server) getHealthchecks() (*Checks, error) {
-	engineConn, err := net.DialTimeout("unix", s.config.EngineSocket, engineTimeout)
+// listen returns a listener for the healthcheck socket. Healthchecks run
+// regardless of whether the socket is available, so failures are logged and
+// result in a nil listener.
+func (s *Server) listen() net.Listener {
+	if s.config.Socket == "" {
+		return nil
+	}
+	if err := server.RemoveUnixSocket(s.config.Socket); err != nil {
+		log.Errorf("Failed to remove socket: %v", err)
+		return nil
+	}
+	ln, err := net.Listen("unix", s.config.Socket)
 	if err != nil {
-		return nil, fmt.Errorf("Dial failed: %v", err)
+		log.Errorf("Failed to listen on healthcheck socket: %v", err)
+		return nil
 	}
-	engineConn.SetDeadline(time.Now().Add(engineTimeout))
-	engine := rpc.NewClient(engineConn)
-	defer engine.Close()
+	return ln
+}
 
+// getHealthchecks attempts to get the current healthcheck configurations from
+// the Seesaw Engine. If the configurations have the given version, the engine
+// waits for up to FetchInterval for them to change before replying.
+func (s *Server) getHealthchecks(version uint64) (*Checks, error) {
 	var checks Checks
-	ctx := ipc.NewTrustedContext(seesaw.SCHealthcheck)
-	if err := engine.Call("SeesawEngine.Healthchecks", ctx, &checks); err != nil {
-		return nil, fmt.Errorf("SeesawEngine.Healthchecks failed: %v", err)
+	args := &ChecksRequest{
+		Ctx:     ipc.NewTrustedContext(seesaw.SCHealthcheck),
+		Version: version,
+		Wait:    s.config.FetchInterval,
+	}
+	if err := s.engine.callWait("SeesawEngine.HealthchecksSince", args, &checks, args.Wait); err != nil {
+		return nil, fmt.Errorf("SeesawEngine.HealthchecksSince failed: %v", err)
 	}
 
 	return &checks, nil
 }
 
-// updater attempts to fetch healthcheck configurations at regular intervals.
-// When configurations are successfully retrieved they are provided to the
-// manager via the configs channel.
+// updater fetches healthcheck configurations from the Seesaw Engine as soon as
+// they change. When configurations are successfully retrieved they are
+// provided to the manager via the configs channel, which also happens at
+// least every FetchInterval while they are unchanged.
 func (s *Server) updater() {
+	var version uint64
 	for {
-		log.Info("Getting healthchecks from engine...")
-		checks, err := s.getHealthchecks()
+		log.V(1).Infof("Getting healthchecks from engine (version %d)...", version)
+		checks, err := s.getHealthchecks(version)
 		if err != nil {
 			log.Error(err)
 			time.Sleep(5 * time.Second)
-		} else {
-			log.Infof("Engine returned %d healthchecks", len(checks.Configs))
-			s.configs <- checks.Configs
-			time.Sleep(s.config.FetchInterval)
+			continue
 		}
+		if checks.Version != version {
+			log.Infof("Engine returned %d healthchecks (version %d)", len(checks.Configs), checks.Version)
+			version = checks.Version
+		}
+		s.configs <- checks
+		time.Sleep(fetchMinInterval)
 	}
 }
 
@@ -531,77 +1057,116 @@ func (s *Server) updater() {
 // stop and remove deleted healthchecks, spawn new healthchecks and provide
 // the current configurations to each of the running healthchecks.
 func (s *Server) manager() {
-	checkTicker := time.NewTicker(50 * time.Millisecond)
 	notifyTicker := time.NewTicker(s.config.NotifyInterval)
+	ticks := 0
 	for {
 		select {
-		case configs := <-s.configs:
+		case checks := <-s.configs:
+			if checks.MaxConcurrent > 0 {
+				s.limiter.setLimit(checks.MaxConcurrent)
+			}
+			configs := checks.Configs
 
 			// Remove healthchecks that have been deleted.
+			s.lock.Lock()
 			for id, hc := range s.healthchecks {
 				if configs[id] == nil {
 					hc.Stop()
 					delete(s.healthchecks, id)
 				}
 			}
+			s.lock.Unlock()
 
 			// Spawn new healthchecks.
 			for id := range configs {
 				if s.healthchecks[id] == nil {
 					hc := NewCheck(s.notify)
+					hc.transition = s.transition
 					hc.Dryrun(s.config.DryRun)
+					hc.CancelOverlapping(s.config.CancelOverlapping)
+					hc.Splay(true)
+					hc.Jitter(s.config.JitterPercent)
+					hc.History(s.config.HistorySize)
+					hc.limiter = s.limiter
+					s.lock.Lock()
 					s.healthchecks[id] = hc
-					go hc.Run(checkTicker.C)
+					s.lock.Unlock()
+					go hc.Run(nil)
 				}
 			}
 
 			// Update configurations.
 			for id, hc := range s.healthchecks {
+				if pc, ok := configs[id].Checker.(*PingChecker); ok && s.config.UnprivilegedPing {
+					pc.Unprivileged = true
+				}
+				if sc, ok := configs[id].Checker.(*ScriptChecker); ok {
+					sc.dir = s.config.ScriptDir
+					sc.sem = s.scriptSem
+				}
 				hc.Update(configs[id])
 			}
 		case <-notifyTicker.C:
-			// Send status notifications for all healthchecks.
+			// Send status notifications for all healthchecks every
+			// SteadyIntervals, otherwise only for those that need their
+			// counters synced.
+			ticks++
+			steady := s.config.SteadyIntervals <= 1 || ticks%s.config.SteadyIntervals == 0
 			for _, hc := range s.healthchecks {
-				hc.Notify()
+				if steady || hc.needsSync() {
+					hc.Notify()
+				}
 			}
 		}
 	}
 }
 
 // notifier batches healthcheck notifications and sends them to the Seesaw
-// Engine.
+// Engine. State transitions are received ahead of steady-state notifications
+// and are sent in the first batch after they are received, which is flushed
+// once BatchDelay passes or MaxBatchSize notifications are queued.
 func (s *Server) notifier() {
 	var timer <-chan time.Time
 	for {
-		var err error
+		var notification *Notification
 		select {
-		case notification := <-s.notify:
-			s.batch = append(s.batch, notification)
-			// Collect until BatchDelay passes, or BatchSize are queued.
-			switch len(s.batch) {
-			case 1:
-				timer = time.After(s.config.BatchDelay)
-			case s.config.BatchSize:
-				err = s.send()
-				timer = nil
+		case notification = <-s.transition:
+		default:
+			select {
+			case notification = <-s.transition:
+			case notification = <-s.notify:
+			case <-timer:
 			}
-
-		case <-timer:
-			err = s.send()
 		}
 
+		var err error
+		if notification != nil {
+			s.pending.add(notification)
+		}
+		if notification == nil || s.pending.full(s.config.MaxBatchSize) {
+			err = s.send(s.pending.next(s.config.MaxBatchSize))
+			timer = nil
+		}
 		if err != nil {
 			log.Fatal(err)
 		}
+
+		// Collect until BatchDelay passes, or MaxBatchSize are queued.
+		if timer == nil && s.pending.len() > 0 {
+			timer = time.After(s.config.BatchDelay)
+		}
 	}
 }
 
 // send sends a batch of notifications to the Seesaw Engine, retrying on any
 // error and giving up after MaxFailures.
-func (s *Server) send() error {
+func (s *Server) send(batch []*Notification) error {
+	if len(batch) == 0 {
+		return nil
+	}
 	failures := 0
 	for {
-		err := s.sendBatch(s.batch)
+		err := s.sendBatch(batch)
 		if err == nil {
 			break
 		}
@@ -614,22 +1179,12 @@ func (s *Server) send() error {
 
 		time.Sleep(s.config.RetryDelay)
 	}
-
-	s.batch = s.batch[:0]
 	return nil
 }
 
 // sendBatch sends a batch of notifications to the Seesaw Engine.
 func (s *Server) sendBatch(batch []*Notification) error {
-	engineConn, err := net.DialTimeout("unix", s.config.EngineSocket, engineTimeout)
-	if err != nil {
-		return err
-	}
-	engineConn.SetDeadline(time.Now().Add(engineTimeout))
-	engine := rpc.NewClient(engineConn)
-	defer engine.Close()
-
 	var reply int
 	ctx := ipc.NewTrustedContext(seesaw.SCHealthcheck)
-	return engine.Call("SeesawEngine.HealthState", &HealthState{ctx, batch}, &reply)
+	return s.engine.call("SeesawEngine.HealthState", &HealthState{ctx, batch}, &reply)
 }
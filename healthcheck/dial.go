@@ -19,6 +19,7 @@ package healthcheck
 // This file contains helper routines for dialing connections.
 
 import (
+	"context"
 	"errors"
 	"net"
 	"os"
@@ -55,6 +56,12 @@ func (c *conn) control(network, address string, rawc syscall.RawConn) error {
 // socket. The host must be given as an IP address. A mark of zero results in a
 // normal (non-marked) connection.
 func dialTCP(network, addr string, timeout time.Duration, mark int) (nc net.Conn, err error) {
+	return dialTCPContext(context.Background(), network, addr, timeout, mark)
+}
+
+// dialTCPContext is like dialTCP, however the dial is abandoned if the given
+// context is cancelled.
+func dialTCPContext(ctx context.Context, network, addr string, timeout time.Duration, mark int) (nc net.Conn, err error) {
 	c := &conn{
 		mark: mark,
 	}
@@ -62,7 +69,7 @@ func dialTCP(network, addr string, timeout time.Duration, mark int) (nc net.Conn
 		Timeout: timeout,
 		Control: c.control,
 	}
-	c.Conn, err = dial.Dial(network, addr)
+	c.Conn, err = dial.DialContext(ctx, network, addr)
 	return c, err
 }
 
@@ -19,8 +19,10 @@
 package healthcheck
 
 import (
+	"crypto/rand"
 	"fmt"
 	"net"
+	"strconv"
 	"strings"
 	"time"
 
@@ -31,6 +33,7 @@ import (
 
 const (
 	defaultDNSTimeout = 3 * time.Second
+	defaultDNSUDPSize = 1232
 )
 
 // DNSType returns the dnsType that corresponds with the given name.
@@ -42,12 +45,115 @@ func DNSType(name string) (uint16, error) {
 	return dt, nil
 }
 
+// DNSRcode returns the DNS response code that corresponds with the given name.
+func DNSRcode(name string) (int, error) {
+	rc, ok := dns.StringToRcode[strings.ToUpper(name)]
+	if !ok {
+		return 0, fmt.Errorf("unknown DNS response code %q", name)
+	}
+	return rc, nil
+}
+
+// DNSSECMode specifies how a DNS healthcheck validates DNSSEC.
+type DNSSECMode int
+
+const (
+	// DNSSECAuthenticated requires the response to have the authenticated
+	// data (AD) bit set by a validating resolver.
+	DNSSECAuthenticated DNSSECMode = iota
+	// DNSSECSigned requires an RRSIG record covering the matched answer.
+	DNSSECSigned
+)
+
+// String returns the name for a given DNSSECMode.
+func (m DNSSECMode) String() string {
+	switch m {
+	case DNSSECAuthenticated:
+		return "AD"
+	case DNSSECSigned:
+		return "RRSIG"
+	default:
+		return "(unknown)"
+	}
+}
+
+// ParseDNSSECMode returns the DNSSECMode that corresponds with the given name.
+func ParseDNSSECMode(name string) (DNSSECMode, error) {
+	switch strings.ToUpper(name) {
+	case "AD":
+		return DNSSECAuthenticated, nil
+	case "RRSIG":
+		return DNSSECSigned, nil
+	}
+	return 0, fmt.Errorf("unknown DNSSEC mode %q", name)
+}
+
+// parseSRVAnswer parses an expected SRV answer of the form "target" or
+// "target:port". A port of zero indicates that any port is accepted.
+func parseSRVAnswer(answer string) (string, uint16, error) {
+	i := strings.LastIndex(answer, ":")
+	if i < 0 {
+		return dns.Fqdn(answer), 0, nil
+	}
+	port, err := strconv.ParseUint(answer[i+1:], 10, 16)
+	if err != nil || port == 0 {
+		return "", 0, fmt.Errorf("invalid SRV port %q", answer[i+1:])
+	}
+	return dns.Fqdn(answer[:i]), uint16(port), nil
+}
+
 // DNSChecker contains configuration specific to a DNS healthcheck.
+//
+// The Answer is interpreted according to the query type. For TXT queries it
+// is matched against the concatenated strings of each record, either exactly
+// or as a substring. For SRV queries it is a target host with an optional
+// port ("target" or "target:port"), while for MX and PTR queries it is the
+// mail exchange and the target name respectively.
 type DNSChecker struct {
 	Target
 	Question dns.Question
 	Answer   string
 	UseTCP   bool // Use TCP instead of UDP for DNS queries (e.g., for large responses).
+
+	// RequireDNSSEC sets the DNSSEC OK bit in queries and fails the
+	// healthcheck unless the response is validated as per DNSSECMode.
+	RequireDNSSEC bool
+	DNSSECMode    DNSSECMode
+
+	// UDPSize is the EDNS0 UDP buffer size advertised in queries. If zero,
+	// a default of 1232 bytes is used. Truncated UDP responses are retried
+	// over TCP.
+	UDPSize uint16
+
+	// RequireAuthoritative fails the healthcheck unless the response has
+	// the authoritative answer bit set.
+	RequireAuthoritative bool
+
+	// ExpectedRcode is the response code the server must return. If it is
+	// not NOERROR, a matching response code is sufficient for success and
+	// the answer section is not checked.
+	ExpectedRcode int
+
+	// WarnLatency and MaxLatency apply to the query that produced the
+	// response, rather than to the healthcheck as a whole. A response that
+	// takes longer than MaxLatency fails the healthcheck, while one that
+	// takes longer than WarnLatency is noted in the result message. Zero
+	// disables either threshold.
+	WarnLatency time.Duration
+	MaxLatency  time.Duration
+
+	// QueryAttempts is the number of times a query is sent before the
+	// healthcheck fails, with the timeout divided between the attempts.
+	// Zero or one results in a single attempt.
+	QueryAttempts int
+
+	// StrictIDCheck fails the healthcheck if the response ID does not match
+	// the ID of the query.
+	StrictIDCheck bool
+
+	// Use0x20 randomises the case of the query name (DNS 0x20 encoding) and
+	// fails the healthcheck unless the response question preserves it.
+	Use0x20 bool
 }
 
 // NewDNSChecker returns an initialised DNSChecker.
@@ -73,6 +179,12 @@ func (hc *DNSChecker) tcpNetwork() string {
 	return "tcp6"
 }
 
+// sameQuestion returns true if the questions are equal, ignoring the case
+// of the name.
+func sameQuestion(a, b dns.Question) bool {
+	return strings.EqualFold(a.Name, b.Name) && a.Qtype == b.Qtype && a.Qclass == b.Qclass
+}
+
 func questionToString(q dns.Question) string {
 	return fmt.Sprintf("%s %s %s", q.Name, dns.Class(q.Qclass), dns.Type(q.Qtype))
 }
@@ -82,6 +194,98 @@ func (hc *DNSChecker) String() string {
 	return fmt.Sprintf("DNS %s %s", questionToString(hc.Question), hc.Target)
 }
 
+// udpSize returns the EDNS0 UDP buffer size for the DNS checker.
+func (hc *DNSChecker) udpSize() uint16 {
+	if hc.UDPSize == 0 {
+		return defaultDNSUDPSize
+	}
+	return hc.UDPSize
+}
+
+// exchange sends a DNS query to the target, over either UDP or TCP, and
+// returns the response. If an error occurs, a description of the failed
+// step is also returned.
+func (hc *DNSChecker) exchange(q *dns.Msg, useTCP bool, deadline time.Time) (*dns.Msg, string, error) {
+	var conn net.Conn
+	var err error
+	if useTCP {
+		conn, err = dialTCP(hc.tcpNetwork(), hc.addr(), time.Until(deadline), hc.Mark)
+	} else {
+		conn, err = dialUDP(hc.network(), hc.addr(), time.Until(deadline), hc.Mark)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, "failed to set deadline", err
+	}
+
+	dnsConn := &dns.Conn{Conn: conn, UDPSize: hc.udpSize()}
+	if err := dnsConn.WriteMsg(q); err != nil {
+		return nil, "failed to send request", err
+	}
+	r, err := dnsConn.ReadMsg()
+	if err != nil {
+		return nil, "failed to read response", err
+	}
+	return r, "", nil
+}
+
+// encode0x20 returns name with the case of each letter randomised, as per
+// DNS 0x20 encoding.
+func encode0x20(name string) string {
+	b := []byte(strings.ToLower(name))
+	bits := make([]byte, (len(b)+7)/8)
+	if _, err := rand.Read(bits); err != nil {
+		return name
+	}
+	for i, c := range b {
+		if c >= 'a' && c <= 'z' && bits[i/8]&(1<<uint(i%8)) != 0 {
+			b[i] = c - 'a' + 'A'
+		}
+	}
+	return string(b)
+}
+
+// query sends a DNS query to the target, retrying over TCP if the response
+// is truncated. It returns the response and whether TCP fallback occurred.
+// If an error occurs, a description of the failed step is also returned.
+func (hc *DNSChecker) query(q *dns.Msg, deadline time.Time) (*dns.Msg, bool, string, error) {
+	r, failure, err := hc.exchange(q, hc.UseTCP, deadline)
+	if err != nil || !r.Truncated || hc.UseTCP {
+		return r, false, failure, err
+	}
+	// Retry over TCP within the remaining time, rather than trying to
+	// match a truncated answer.
+	r, failure, err = hc.exchange(q, true, deadline)
+	return r, true, failure, err
+}
+
+// signed returns true if the given records include an RRSIG covering rr.
+func signed(rr dns.RR, records []dns.RR) bool {
+	for _, sig := range records {
+		if sig, ok := sig.(*dns.RRSIG); ok &&
+			sig.TypeCovered == rr.Header().Rrtype &&
+			strings.EqualFold(sig.Hdr.Name, rr.Header().Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matched completes a DNS healthcheck whose answer has matched rr. If
+// DNSSEC signatures are required, rr must also be covered by an RRSIG.
+func (hc *DNSChecker) matched(start time.Time, msg string, rr dns.RR, r *dns.Msg) *Result {
+	if hc.RequireDNSSEC && hc.DNSSECMode == DNSSECSigned && !signed(rr, r.Answer) {
+		msg = fmt.Sprintf("%s; DNSSEC validation failed: no RRSIG present for %s %s",
+			msg, rr.Header().Name, dns.Type(rr.Header().Rrtype))
+		return complete(start, msg, false, nil)
+	}
+	return complete(start, msg, true, nil)
+}
+
 // Check executes a DNS healthcheck.
 func (hc *DNSChecker) Check(timeout time.Duration) *Result {
 	if !strings.HasSuffix(hc.Question.Name, ".") {
@@ -96,6 +300,8 @@ func (hc *DNSChecker) Check(timeout time.Duration) *Result {
 	deadline := start.Add(timeout)
 
 	var aIP net.IP
+	var srvTarget string
+	var srvPort uint16
 	switch hc.Question.Qtype {
 	case dns.TypeA:
 		if aIP = net.ParseIP(hc.Answer); aIP == nil || aIP.To4() == nil {
@@ -107,6 +313,12 @@ func (hc *DNSChecker) Check(timeout time.Duration) *Result {
 			msg = fmt.Sprintf("%s; %q is not a valid IPv6 address", msg, hc.Answer)
 			return complete(start, msg, false, nil)
 		}
+	case dns.TypeSRV:
+		var err error
+		if srvTarget, srvPort, err = parseSRVAnswer(hc.Answer); err != nil {
+			msg = fmt.Sprintf("%s; %q is not a valid SRV answer", msg, hc.Answer)
+			return complete(start, msg, false, err)
+		}
 	}
 
 	// Build DNS query.
@@ -117,35 +329,54 @@ func (hc *DNSChecker) Check(timeout time.Duration) *Result {
 		},
 		Question: []dns.Question{hc.Question},
 	}
+	q.SetEdns0(hc.udpSize(), hc.RequireDNSSEC)
 
-	var conn net.Conn
+	attempts := hc.QueryAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	var r *dns.Msg
+	var fallback bool
+	var failure string
 	var err error
-	if hc.UseTCP {
-		conn, err = dialTCP(hc.tcpNetwork(), hc.addr(), timeout, hc.Mark)
-	} else {
-		conn, err = dialUDP(hc.network(), hc.addr(), timeout, hc.Mark)
+	var latency time.Duration
+	attempt := 1
+	for ; ; attempt++ {
+		// Divide the remaining time between the remaining attempts, so
+		// that a single lost packet does not consume the entire timeout.
+		queryStart := time.Now()
+		queryDeadline := queryStart.Add(time.Until(deadline) / time.Duration(attempts-attempt+1))
+		q.Id = dns.Id()
+		if hc.Use0x20 {
+			q.Question[0].Name = encode0x20(hc.Question.Name)
+		}
+		r, fallback, failure, err = hc.query(q, queryDeadline)
+		latency = time.Since(queryStart)
+		if err == nil || attempt >= attempts {
+			break
+		}
 	}
-	if err != nil {
-		return complete(start, msg, false, err)
+	if fallback {
+		msg = fmt.Sprintf("%s; response truncated, retried over TCP", msg)
+	}
+	if attempts > 1 {
+		msg = fmt.Sprintf("%s; attempt %d of %d", msg, attempt, attempts)
 	}
-	defer conn.Close()
-
-	err = conn.SetDeadline(deadline)
 	if err != nil {
-		msg = fmt.Sprintf("%s; failed to set deadline", msg)
+		if failure != "" {
+			msg = fmt.Sprintf("%s; %s", msg, failure)
+		}
 		return complete(start, msg, false, err)
 	}
 
-	dnsConn := &dns.Conn{Conn: conn}
-	if err := dnsConn.WriteMsg(q); err != nil {
-		msg = fmt.Sprintf("%s; failed to send request", msg)
-		return complete(start, msg, false, err)
+	latency = latency.Round(time.Microsecond)
+	msg = fmt.Sprintf("%s; query latency %v", msg, latency)
+	if hc.MaxLatency > 0 && latency > hc.MaxLatency {
+		msg = fmt.Sprintf("%s exceeds maximum %v", msg, hc.MaxLatency)
+		return complete(start, msg, false, nil)
 	}
-
-	r, err := dnsConn.ReadMsg()
-	if err != nil {
-		msg = fmt.Sprintf("%s; failed to read response", msg)
-		return complete(start, msg, false, err)
+	if hc.WarnLatency > 0 && latency > hc.WarnLatency {
+		msg = fmt.Sprintf("%s exceeds warning threshold %v", msg, hc.WarnLatency)
 	}
 
 	// Check reply.
@@ -153,27 +384,64 @@ func (hc *DNSChecker) Check(timeout time.Duration) *Result {
 		msg = fmt.Sprintf("%s; not a query response", msg)
 		return complete(start, msg, false, nil)
 	}
-	if rc := r.Rcode; rc != dns.RcodeSuccess {
-		msg = fmt.Sprintf("%s; non-zero response code - %d", msg, rc)
+	if hc.StrictIDCheck && r.Id != q.Id {
+		msg = fmt.Sprintf("%s; response ID %d does not match query ID %d", msg, r.Id, q.Id)
+		return complete(start, msg, false, nil)
+	}
+	if hc.RequireAuthoritative && !r.Authoritative {
+		msg = fmt.Sprintf("%s; response is not authoritative", msg)
+		return complete(start, msg, false, nil)
+	}
+	if rc := r.Rcode; rc != hc.ExpectedRcode {
+		msg = fmt.Sprintf("%s; unexpected response code %s, want %s",
+			msg, dns.RcodeToString[rc], dns.RcodeToString[hc.ExpectedRcode])
+		return complete(start, msg, false, nil)
+	}
+	if hc.RequireDNSSEC && hc.DNSSECMode == DNSSECAuthenticated && !r.AuthenticatedData {
+		msg = fmt.Sprintf("%s; DNSSEC validation failed: AD not set", msg)
 		return complete(start, msg, false, nil)
 	}
+	if hc.ExpectedRcode != dns.RcodeSuccess {
+		if hc.RequireDNSSEC && hc.DNSSECMode == DNSSECSigned {
+			// A signed denial carries its signatures in the authority section.
+			hasSig := false
+			for _, rr := range r.Ns {
+				if _, ok := rr.(*dns.RRSIG); ok {
+					hasSig = true
+				}
+			}
+			if !hasSig {
+				msg = fmt.Sprintf("%s; DNSSEC validation failed: no RRSIG present in authority section", msg)
+				return complete(start, msg, false, nil)
+			}
+		}
+		msg = fmt.Sprintf("%s; received expected response code %s", msg, dns.RcodeToString[hc.ExpectedRcode])
+		return complete(start, msg, true, nil)
+	}
 	if len(r.Answer) < 1 {
 		msg = fmt.Sprintf("%s; no answers received for query %s", msg, questionToString(hc.Question))
 		return complete(start, msg, false, nil)
 	}
 
-	// Validate that the response question section matches our query.
-	if len(r.Question) > 0 && r.Question[0] != hc.Question {
-		msg = fmt.Sprintf("%s; response question mismatch: got %s, want %s",
-			msg, questionToString(r.Question[0]), questionToString(hc.Question))
+	// Validate that the response question section matches our query. With
+	// 0x20 encoding, the case of the query name must also be preserved.
+	if len(r.Question) > 0 && r.Question[0] != q.Question[0] {
+		if hc.Use0x20 && sameQuestion(r.Question[0], q.Question[0]) {
+			msg = fmt.Sprintf("%s; response question %s does not preserve 0x20 encoding of %s",
+				msg, r.Question[0].Name, q.Question[0].Name)
+		} else {
+			msg = fmt.Sprintf("%s; response question mismatch: got %s, want %s",
+				msg, questionToString(r.Question[0]), questionToString(q.Question[0]))
+		}
 		return complete(start, msg, false, nil)
 	}
 
 	// Build a CNAME chain map for following aliases in A/AAAA queries.
+	// Names are compared case-insensitively.
 	cnameMap := make(map[string]string)
 	for _, rr := range r.Answer {
 		if cname, ok := rr.(*dns.CNAME); ok {
-			cnameMap[cname.Hdr.Name] = cname.Target
+			cnameMap[strings.ToLower(cname.Hdr.Name)] = cname.Target
 		}
 	}
 
@@ -181,6 +449,7 @@ func (hc *DNSChecker) Check(timeout time.Duration) *Result {
 	resolveCNAME := func(name string) string {
 		seen := make(map[string]bool)
 		for {
+			name = strings.ToLower(name)
 			target, ok := cnameMap[name]
 			if !ok || seen[name] {
 				return name
@@ -201,39 +470,74 @@ func (hc *DNSChecker) Check(timeout time.Duration) *Result {
 			// is reachable from the question name via CNAME chain.
 			if hc.Question.Qtype == dns.TypeA {
 				canonical := resolveCNAME(hc.Question.Name)
-				if rr.Hdr.Name == canonical && aIP.Equal(rr.A) {
+				if strings.EqualFold(rr.Hdr.Name, canonical) && aIP.Equal(rr.A) {
 					msg = fmt.Sprintf("%s; received answer %s", msg, rr.A)
-					return complete(start, msg, true, err)
+					return hc.matched(start, msg, rr, r)
 				}
 			}
 		case *dns.AAAA:
 			// For AAAA queries, follow CNAMEs similarly.
 			if hc.Question.Qtype == dns.TypeAAAA {
 				canonical := resolveCNAME(hc.Question.Name)
-				if rr.Hdr.Name == canonical && aIP.Equal(rr.AAAA) {
+				if strings.EqualFold(rr.Hdr.Name, canonical) && aIP.Equal(rr.AAAA) {
 					msg = fmt.Sprintf("%s; received answer %s", msg, rr.AAAA)
-					return complete(start, msg, true, err)
+					return hc.matched(start, msg, rr, r)
 				}
 			}
 		case *dns.CNAME:
 			if hc.Question.Qtype == dns.TypeCNAME &&
-				rr.Hdr.Name == hc.Question.Name &&
+				strings.EqualFold(rr.Hdr.Name, hc.Question.Name) &&
 				strings.EqualFold(rr.Target, hc.Answer+".") {
 				msg = fmt.Sprintf("%s; received CNAME %s", msg, rr.Target)
-				return complete(start, msg, true, err)
+				return hc.matched(start, msg, rr, r)
 			}
 		case *dns.NS:
 			if hc.Question.Qtype == dns.TypeNS &&
-				rr.Hdr.Name == hc.Question.Name &&
+				strings.EqualFold(rr.Hdr.Name, hc.Question.Name) &&
 				strings.EqualFold(rr.Ns, hc.Answer+".") {
 				msg = fmt.Sprintf("%s; received NS %s", msg, rr.Ns)
-				return complete(start, msg, true, err)
+				return hc.matched(start, msg, rr, r)
 			}
 		case *dns.SOA:
 			if hc.Question.Qtype == dns.TypeSOA &&
-				rr.Hdr.Name == hc.Question.Name {
+				strings.EqualFold(rr.Hdr.Name, hc.Question.Name) {
 				msg = fmt.Sprintf("%s; received SOA %s %s", msg, rr.Ns, rr.Mbox)
-				return complete(start, msg, true, err)
+				return hc.matched(start, msg, rr, r)
+			}
+		case *dns.TXT:
+			if hc.Question.Qtype != dns.TypeTXT || !strings.EqualFold(rr.Hdr.Name, hc.Question.Name) {
+				continue
+			}
+			txt := strings.Join(rr.Txt, "")
+			if txt == hc.Answer {
+				msg = fmt.Sprintf("%s; received TXT %q (exact match)", msg, txt)
+				return hc.matched(start, msg, rr, r)
+			}
+			if strings.Contains(txt, hc.Answer) {
+				msg = fmt.Sprintf("%s; received TXT %q (substring match %q)", msg, txt, hc.Answer)
+				return hc.matched(start, msg, rr, r)
+			}
+		case *dns.SRV:
+			if hc.Question.Qtype == dns.TypeSRV &&
+				strings.EqualFold(rr.Hdr.Name, hc.Question.Name) &&
+				strings.EqualFold(rr.Target, srvTarget) &&
+				(srvPort == 0 || rr.Port == srvPort) {
+				msg = fmt.Sprintf("%s; received SRV %s port %d", msg, rr.Target, rr.Port)
+				return hc.matched(start, msg, rr, r)
+			}
+		case *dns.MX:
+			if hc.Question.Qtype == dns.TypeMX &&
+				strings.EqualFold(rr.Hdr.Name, hc.Question.Name) &&
+				strings.EqualFold(rr.Mx, dns.Fqdn(hc.Answer)) {
+				msg = fmt.Sprintf("%s; received MX %d %s", msg, rr.Preference, rr.Mx)
+				return hc.matched(start, msg, rr, r)
+			}
+		case *dns.PTR:
+			if hc.Question.Qtype == dns.TypePTR &&
+				strings.EqualFold(rr.Hdr.Name, hc.Question.Name) &&
+				strings.EqualFold(rr.Ptr, dns.Fqdn(hc.Answer)) {
+				msg = fmt.Sprintf("%s; received PTR %s", msg, rr.Ptr)
+				return hc.matched(start, msg, rr, r)
 			}
 		}
 	}
@@ -0,0 +1,149 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+// This file contains the client that is used to communicate with the Seesaw
+// Engine over a persistent RPC connection.
+
+import (
+	"fmt"
+	"net"
+	"net/rpc"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+)
+
+const (
+	// engineRetryMin and engineRetryMax bound the exponential backoff
+	// between attempts to connect to the Seesaw Engine.
+	engineRetryMin = 500 * time.Millisecond
+	engineRetryMax = 30 * time.Second
+)
+
+// engineClient maintains a single RPC connection to the Seesaw Engine, which
+// is shared by the healthcheck config fetcher and notifier. The connection is
+// established when first needed and re-established after it fails, with an
+// exponential backoff between failed attempts. It is safe for concurrent use.
+type engineClient struct {
+	socket  string
+	timeout time.Duration
+	dial    func(socket string, timeout time.Duration) (net.Conn, error)
+
+	lock     sync.Mutex
+	client   *rpc.Client
+	failures uint
+	retry    time.Time // No connection is attempted before this time.
+}
+
+// newEngineClient returns an engineClient for the engine on the given socket.
+func newEngineClient(socket string) *engineClient {
+	return &engineClient{
+		socket:  socket,
+		timeout: engineTimeout,
+		dial: func(socket string, timeout time.Duration) (net.Conn, error) {
+			return net.DialTimeout("unix", socket, timeout)
+		},
+	}
+}
+
+// connect returns the RPC client for the current connection, establishing a
+// new connection if there is none.
+func (c *engineClient) connect() (*rpc.Client, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.client != nil {
+		return c.client, nil
+	}
+	if wait := time.Until(c.retry); wait > 0 {
+		return nil, fmt.Errorf("engine connection failed %d times, retrying in %v", c.failures, wait.Round(time.Millisecond))
+	}
+	conn, err := c.dial(c.socket, c.timeout)
+	if err != nil {
+		c.failures++
+		backoff := engineRetryMax
+		if c.failures < 16 {
+			backoff = engineRetryMin << (c.failures - 1)
+		}
+		if backoff > engineRetryMax {
+			backoff = engineRetryMax
+		}
+		c.retry = time.Now().Add(backoff)
+		return nil, fmt.Errorf("Dial failed: %v", err)
+	}
+	if c.failures > 0 {
+		log.Infof("Reconnected to engine after %d failed attempts", c.failures)
+	}
+	c.failures = 0
+	c.client = rpc.NewClient(conn)
+	return c.client, nil
+}
+
+// reset closes the given client, if it is still the current client, so that
+// the next call establishes a new connection.
+func (c *engineClient) reset(client *rpc.Client) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.client == client {
+		c.client = nil
+	}
+	client.Close()
+}
+
+// call invokes the named engine RPC method, waiting no longer than the
+// timeout for it to complete. The connection is discarded if the call fails
+// for any reason other than an error returned by the engine.
+func (c *engineClient) call(method string, args interface{}, reply interface{}) error {
+	return c.callWait(method, args, reply, 0)
+}
+
+// callWait invokes the named engine RPC method, for which the engine may wait
+// up to the given duration before replying, in addition to the timeout.
+func (c *engineClient) callWait(method string, args interface{}, reply interface{}, wait time.Duration) error {
+	client, err := c.connect()
+	if err != nil {
+		return err
+	}
+	timeout := c.timeout + wait
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	call := client.Go(method, args, reply, make(chan *rpc.Call, 1))
+	select {
+	case <-call.Done:
+	case <-timer.C:
+		// Closing the client completes the call, which ensures that the
+		// reply is not written after returning.
+		c.reset(client)
+		<-call.Done
+		return fmt.Errorf("%s timed out after %v", method, timeout)
+	}
+	if call.Error != nil {
+		if _, ok := call.Error.(rpc.ServerError); !ok {
+			c.reset(client)
+		}
+	}
+	return call.Error
+}
+
+// close closes the current connection, if any.
+func (c *engineClient) close() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.client != nil {
+		c.client.Close()
+		c.client = nil
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Support for line-oriented protocols with textual responses, such as IMAP
+// and POP3.
+
+package healthcheck
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// ValidLineArgument reports whether the given value can be sent as an
+// argument in a line-oriented protocol command.
+func ValidLineArgument(arg string) bool {
+	return !strings.ContainsAny(arg, "\r\n\x00")
+}
+
+// lineConn sends command lines to and reads response lines from a server.
+// Every read must complete by the deadline and the total amount of data
+// read is bounded. The first unexpected response line is recorded.
+type lineConn struct {
+	conn       net.Conn
+	r          *bufio.Reader
+	deadline   time.Time
+	unexpected string
+}
+
+func newLineConn(conn net.Conn, deadline time.Time, maxResponseSize int64) *lineConn {
+	return &lineConn{
+		conn:     conn,
+		r:        bufio.NewReader(io.LimitReader(conn, maxResponseSize)),
+		deadline: deadline,
+	}
+}
+
+// readLine reads a single response line, without the line terminator.
+func (c *lineConn) readLine() (string, error) {
+	if err := c.conn.SetReadDeadline(c.deadline); err != nil {
+		return "", err
+	}
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// writeLine sends a single command line.
+func (c *lineConn) writeLine(line string) error {
+	if err := c.conn.SetWriteDeadline(c.deadline); err != nil {
+		return err
+	}
+	_, err := io.WriteString(c.conn, line+"\r\n")
+	return err
+}
+
+// mismatch records line as unexpected and returns an error describing it.
+func (c *lineConn) mismatch(line string) error {
+	if c.unexpected == "" {
+		c.unexpected = line
+	}
+	return fmt.Errorf("unexpected response %q", line)
+}
+
+// expect reads a response line and returns an error unless it starts with
+// prefix.
+func (c *lineConn) expect(prefix string) (string, error) {
+	line, err := c.readLine()
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(line, prefix) {
+		return line, c.mismatch(line)
+	}
+	return line, nil
+}
+
+// command sends a command line and expects a response starting with prefix.
+func (c *lineConn) command(line, prefix string) (string, error) {
+	if err := c.writeLine(line); err != nil {
+		return "", err
+	}
+	return c.expect(prefix)
+}
+
+// clientTLS performs a TLS client handshake over conn. If serverName is
+// empty, the target IP address is used.
+func clientTLS(conn net.Conn, ip net.IP, serverName string, verify bool) (*tls.Conn, error) {
+	if serverName == "" {
+		serverName = ip.String()
+	}
+	tlsConn := tls.Client(conn, &tls.Config{
+		InsecureSkipVerify: !verify,
+		ServerName:         serverName,
+	})
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, err
+	}
+	return tlsConn, nil
+}
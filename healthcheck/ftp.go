@@ -0,0 +1,189 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// FTP healthcheck implementation.
+
+package healthcheck
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/seesaw/common/seesaw"
+)
+
+const (
+	defaultFTPTimeout  = 10 * time.Second
+	defaultFTPUser     = "anonymous"
+	defaultFTPPassword = "seesaw@"
+
+	// ftpMaxResponseSize bounds the amount of data read from the server
+	// on each connection.
+	ftpMaxResponseSize = 64 * 1024
+)
+
+// ValidFTPCommand reports whether the given command can be issued by an FTP
+// healthcheck once logged in. The empty string means no command.
+func ValidFTPCommand(cmd string) bool {
+	switch cmd {
+	case "", "STAT", "CWD":
+		return true
+	}
+	return false
+}
+
+// FTPChecker contains configuration specific to an FTP healthcheck.
+//
+// The healthcheck reads the 220 banner and logs in, anonymously by default.
+// If Command is "STAT" or "CWD", it is then issued with Path as its argument
+// and must succeed. If Secure is set, the control connection is upgraded
+// with AUTH TLS before logging in. Data connections are not used.
+type FTPChecker struct {
+	Target
+	User       string
+	Password   string
+	Command    string
+	Path       string
+	Secure     bool
+	TLSVerify  bool
+	ServerName string // TLS ServerName override. If empty, derived from target address.
+}
+
+// NewFTPChecker returns an initialised FTPChecker.
+func NewFTPChecker(ip net.IP, port int) *FTPChecker {
+	return &FTPChecker{
+		Target: Target{
+			IP:    ip,
+			Port:  port,
+			Proto: seesaw.IPProtoTCP,
+		},
+		User:     defaultFTPUser,
+		Password: defaultFTPPassword,
+	}
+}
+
+// String returns the string representation of an FTP healthcheck.
+func (hc *FTPChecker) String() string {
+	attr := []string{"user " + hc.User}
+	if hc.Command != "" {
+		attr = append(attr, strings.TrimSpace(hc.Command+" "+hc.Path))
+	}
+	if hc.Secure && hc.TLSVerify {
+		attr = append(attr, "verify")
+	}
+	proto := "FTP"
+	if hc.Secure {
+		proto = "FTPS"
+	}
+	return fmt.Sprintf("%s [%s] %s", proto, strings.Join(attr, "; "), hc.Target)
+}
+
+// Check executes an FTP healthcheck.
+func (hc *FTPChecker) Check(timeout time.Duration) *Result {
+	proto := "FTP"
+	if hc.Secure {
+		proto = "FTPS"
+	}
+	msg := fmt.Sprintf("%s connect to %s", proto, hc.addr())
+	start := time.Now()
+	if timeout == time.Duration(0) {
+		timeout = defaultFTPTimeout
+	}
+	deadline := start.Add(timeout)
+
+	conn, err := dialTCP(hc.network(), hc.addr(), timeout, hc.Mark)
+	if err != nil {
+		msg = fmt.Sprintf("%s; failed to connect", msg)
+		return complete(start, msg, false, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(deadline); err != nil {
+		msg = fmt.Sprintf("%s; failed to set deadline", msg)
+		return complete(start, msg, false, err)
+	}
+
+	c := newReplyConn(conn, ftpMaxResponseSize)
+	failed := func(step string, err error) *Result {
+		msg = fmt.Sprintf("%s; %s failed", msg, step)
+		if c.lastCode != 0 {
+			msg = fmt.Sprintf("%s (last code %d)", msg, c.lastCode)
+		}
+		return complete(start, msg, false, err)
+	}
+
+	if _, _, err := c.readReply(220); err != nil {
+		return failed("banner", err)
+	}
+
+	if hc.Secure {
+		if _, _, err := c.command(234, "AUTH TLS"); err != nil {
+			return failed("AUTH TLS", err)
+		}
+		serverName := hc.ServerName
+		if serverName == "" {
+			serverName = hc.IP.String()
+		}
+		tlsConn := tls.Client(conn, &tls.Config{
+			InsecureSkipVerify: !hc.TLSVerify,
+			ServerName:         serverName,
+		})
+		if err := tlsConn.Handshake(); err != nil {
+			return failed("TLS handshake", err)
+		}
+		defer tlsConn.Close()
+		lastCode := c.lastCode
+		c = newReplyConn(tlsConn, ftpMaxResponseSize)
+		c.lastCode = lastCode
+	}
+
+	user := hc.User
+	if user == "" {
+		user = defaultFTPUser
+	}
+	code, _, err := c.command(0, "USER "+user)
+	if err == nil && code == 331 {
+		code, _, err = c.command(0, "PASS "+hc.Password)
+	}
+	if err == nil && code != 230 && code != 202 {
+		err = fmt.Errorf("unexpected reply code %d", code)
+	}
+	if err != nil {
+		return failed("login", err)
+	}
+
+	switch hc.Command {
+	case "STAT":
+		cmd := strings.TrimSpace("STAT " + hc.Path)
+		if _, _, err := c.command(2, cmd); err != nil {
+			return failed(cmd, err)
+		}
+	case "CWD":
+		cmd := "CWD " + hc.Path
+		if _, _, err := c.command(250, cmd); err != nil {
+			return failed(cmd, err)
+		}
+	case "":
+	default:
+		return failed(hc.Command, fmt.Errorf("unsupported command %q", hc.Command))
+	}
+
+	msg = fmt.Sprintf("%s; logged in as %s (last code %d)", msg, user, c.lastCode)
+
+	// Some servers close the connection without answering QUIT.
+	c.command(221, "QUIT")
+	return complete(start, msg, true, nil)
+}
@@ -0,0 +1,262 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"bufio"
+	"crypto/tls"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ftpStub is a scripted FTP server. Replies are sent verbatim, and may span
+// multiple lines.
+type ftpStub struct {
+	banner    string
+	user      string
+	pass      string
+	cwd       string
+	tlsConfig *tls.Config
+
+	mu       sync.Mutex
+	commands []string
+}
+
+func (fs *ftpStub) handle(conn net.Conn) {
+	defer func() { conn.Close() }()
+	io.WriteString(conn, fs.banner)
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimRight(line, "\r\n")
+		fs.mu.Lock()
+		fs.commands = append(fs.commands, cmd)
+		fs.mu.Unlock()
+
+		verb := strings.ToUpper(strings.Fields(cmd + " ")[0])
+		switch {
+		case verb == "AUTH" && fs.tlsConfig != nil:
+			io.WriteString(conn, "234 Proceed with negotiation.\r\n")
+			tlsConn := tls.Server(conn, fs.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			r = bufio.NewReader(conn)
+		case verb == "USER":
+			io.WriteString(conn, fs.user)
+		case verb == "PASS":
+			io.WriteString(conn, fs.pass)
+		case verb == "CWD":
+			io.WriteString(conn, fs.cwd)
+		case verb == "STAT":
+			io.WriteString(conn, "211-FTP server status:\r\n Connected to 127.0.0.1\r\n211 End of status\r\n")
+		case verb == "QUIT":
+			io.WriteString(conn, "221 Goodbye.\r\n")
+			return
+		default:
+			io.WriteString(conn, "502 Command not implemented.\r\n")
+		}
+	}
+}
+
+const (
+	ftpBanner = "220 (vsFTPd 3.0.3)\r\n"
+	ftpUser   = "331 Please specify the password.\r\n"
+	ftpPass   = "230-Welcome to the mirror.\r\n230-\r\n230 Login successful.\r\n"
+	ftpCWD    = "250 Directory successfully changed.\r\n"
+)
+
+var ftpCheckerTests = []struct {
+	desc    string
+	stub    *ftpStub
+	command string
+	path    string
+	secure  bool
+	success bool
+	message string
+}{
+	{
+		"login",
+		&ftpStub{banner: ftpBanner, user: ftpUser, pass: ftpPass},
+		"", "", false,
+		true, "logged in as anonymous (last code 230)",
+	},
+	{
+		"multi-line banner",
+		&ftpStub{banner: "220-Mirror\r\n220-Be nice\r\n220 Ready\r\n", user: ftpUser, pass: ftpPass},
+		"", "", false,
+		true, "logged in",
+	},
+	{
+		"no password required",
+		&ftpStub{banner: ftpBanner, user: "230 Login successful.\r\n"},
+		"", "", false,
+		true, "logged in",
+	},
+	{
+		"cwd",
+		&ftpStub{banner: ftpBanner, user: ftpUser, pass: ftpPass, cwd: ftpCWD},
+		"CWD", "/pub", false,
+		true, "logged in as anonymous (last code 250)",
+	},
+	{
+		"cwd failed",
+		&ftpStub{banner: ftpBanner, user: ftpUser, pass: ftpPass, cwd: "550 Failed to change directory.\r\n"},
+		"CWD", "/pub", false,
+		false, "CWD /pub failed (last code 550)",
+	},
+	{
+		"stat",
+		&ftpStub{banner: ftpBanner, user: ftpUser, pass: ftpPass},
+		"STAT", "", false,
+		true, "logged in as anonymous (last code 211)",
+	},
+	{
+		"bad password",
+		&ftpStub{banner: ftpBanner, user: ftpUser, pass: "530 Login incorrect.\r\n"},
+		"", "", false,
+		false, "login failed (last code 530)",
+	},
+	{
+		"account required",
+		&ftpStub{banner: ftpBanner, user: ftpUser, pass: "332 Need account for login.\r\n"},
+		"", "", false,
+		false, "login failed (last code 332)",
+	},
+	{
+		"service unavailable",
+		&ftpStub{banner: "421 Too many connections.\r\n"},
+		"", "", false,
+		false, "banner failed (last code 421)",
+	},
+	{
+		"auth tls",
+		&ftpStub{banner: ftpBanner, user: ftpUser, pass: ftpPass, cwd: ftpCWD},
+		"CWD", "/pub", true,
+		true, "FTPS connect",
+	},
+	{
+		"not ftp",
+		&ftpStub{banner: "SSH-2.0-OpenSSH_9.6\r\n"},
+		"", "", false,
+		false, "banner failed",
+	},
+}
+
+func TestFTPChecker(t *testing.T) {
+	cert, _ := newServerCert(t, "ftp.example.com")
+	for _, test := range ftpCheckerTests {
+		l, addr, err := newLocalTCPListener("tcp4")
+		if err != nil {
+			t.Fatalf("Failed to create local TCP listener: %v", err)
+		}
+		fs := test.stub
+		fs.tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+		go serveConns(l, fs.handle)
+
+		hc := NewFTPChecker(addr.IP, addr.Port)
+		hc.Command = test.command
+		hc.Path = test.path
+		hc.Secure = test.secure
+		result := hc.Check(time.Second)
+		l.Close()
+		if result.Success != test.success {
+			t.Errorf("%s: got success %v, want %v: %v", test.desc, result.Success, test.success, result)
+		}
+		if !strings.Contains(result.Message, test.message) {
+			t.Errorf("%s: got message %q, want it to contain %q", test.desc, result.Message, test.message)
+		}
+		if test.success {
+			// The stub records QUIT before replying to it.
+			fs.mu.Lock()
+			commands := fs.commands
+			fs.mu.Unlock()
+			if len(commands) == 0 || commands[len(commands)-1] != "QUIT" {
+				t.Errorf("%s: got commands %q, want QUIT last", test.desc, commands)
+			}
+			if test.secure && commands[0] != "AUTH TLS" {
+				t.Errorf("%s: got commands %q, want AUTH TLS first", test.desc, commands)
+			}
+		}
+	}
+}
+
+func TestFTPCheckerCredentials(t *testing.T) {
+	l, addr, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to create local TCP listener: %v", err)
+	}
+	defer l.Close()
+	fs := &ftpStub{banner: ftpBanner, user: ftpUser, pass: ftpPass}
+	go serveConns(l, fs.handle)
+
+	hc := NewFTPChecker(addr.IP, addr.Port)
+	hc.User = "mirror"
+	hc.Password = "secret"
+	result := hc.Check(time.Second)
+	if !result.Success {
+		t.Fatalf("FTP healthcheck failed: %v", result)
+	}
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if len(fs.commands) < 2 || fs.commands[0] != "USER mirror" || fs.commands[1] != "PASS secret" {
+		t.Errorf("Got commands %q, want USER mirror and PASS secret", fs.commands)
+	}
+	if strings.Contains(result.Message, "secret") {
+		t.Errorf("Message %q contains the password", result.Message)
+	}
+}
+
+func TestFTPCheckerBannerTimeout(t *testing.T) {
+	l, addr, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to create local TCP listener: %v", err)
+	}
+	defer l.Close()
+	// Accept connections but never send the banner.
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				io.Copy(io.Discard, conn)
+				conn.Close()
+			}()
+		}
+	}()
+
+	hc := NewFTPChecker(addr.IP, addr.Port)
+	start := time.Now()
+	result := hc.Check(200 * time.Millisecond)
+	if result.Success {
+		t.Fatalf("FTP healthcheck succeeded without a banner: %v", result)
+	}
+	if !strings.Contains(result.Message, "banner failed") {
+		t.Errorf("got message %q, want it to contain %q", result.Message, "banner failed")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("FTP healthcheck took %v, want it bounded by the timeout", elapsed)
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// gRPC healthcheck implementation.
+
+package healthcheck
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/seesaw/common/seesaw"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	defaultGRPCTimeout = 5 * time.Second
+
+	grpcHealthCheckPath = "/grpc.health.v1.Health/Check"
+	grpcMaxMessageSize  = 4096
+)
+
+// grpcServingStatus is a grpc.health.v1.HealthCheckResponse.ServingStatus.
+type grpcServingStatus uint64
+
+const (
+	grpcStatusUnknown        grpcServingStatus = 0
+	grpcStatusServing        grpcServingStatus = 1
+	grpcStatusNotServing     grpcServingStatus = 2
+	grpcStatusServiceUnknown grpcServingStatus = 3
+)
+
+var grpcServingStatusNames = map[grpcServingStatus]string{
+	grpcStatusUnknown:        "UNKNOWN",
+	grpcStatusServing:        "SERVING",
+	grpcStatusNotServing:     "NOT_SERVING",
+	grpcStatusServiceUnknown: "SERVICE_UNKNOWN",
+}
+
+// String returns the string representation of a gRPC serving status.
+func (s grpcServingStatus) String() string {
+	if name, ok := grpcServingStatusNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("(unknown %d)", s)
+}
+
+var grpcCodeNames = []string{
+	"OK", "CANCELLED", "UNKNOWN", "INVALID_ARGUMENT", "DEADLINE_EXCEEDED",
+	"NOT_FOUND", "ALREADY_EXISTS", "PERMISSION_DENIED", "RESOURCE_EXHAUSTED",
+	"FAILED_PRECONDITION", "ABORTED", "OUT_OF_RANGE", "UNIMPLEMENTED",
+	"INTERNAL", "UNAVAILABLE", "DATA_LOSS", "UNAUTHENTICATED",
+}
+
+// grpcCodeName returns the name of a gRPC status code.
+func grpcCodeName(code int) string {
+	if code >= 0 && code < len(grpcCodeNames) {
+		return grpcCodeNames[code]
+	}
+	return fmt.Sprintf("(unknown %d)", code)
+}
+
+// GRPCChecker contains configuration specific to a gRPC healthcheck, which
+// calls the standard grpc.health.v1.Health/Check method and succeeds only if
+// the service is SERVING.
+type GRPCChecker struct {
+	Target
+	Service    string // The service to query, or empty for the server as a whole.
+	Secure     bool
+	TLSVerify  bool
+	ServerName string // Server name for SNI and verification, if set.
+}
+
+// NewGRPCChecker returns an initialised GRPCChecker.
+func NewGRPCChecker(ip net.IP, port int) *GRPCChecker {
+	return &GRPCChecker{
+		Target: Target{
+			IP:    ip,
+			Port:  port,
+			Proto: seesaw.IPProtoTCP,
+		},
+		TLSVerify: true,
+	}
+}
+
+// String returns the string representation of a gRPC healthcheck.
+func (hc *GRPCChecker) String() string {
+	attr := []string{fmt.Sprintf("service %q", hc.Service)}
+	if hc.Secure {
+		attr = append(attr, "secure")
+		if hc.TLSVerify {
+			attr = append(attr, "verify")
+		}
+		if hc.ServerName != "" {
+			attr = append(attr, "server name "+hc.ServerName)
+		}
+	}
+	return fmt.Sprintf("GRPC %s %v", hc.Target, attr)
+}
+
+// grpcHealthCheckRequest returns a length-prefixed gRPC message containing a
+// grpc.health.v1.HealthCheckRequest for the given service.
+func grpcHealthCheckRequest(service string) []byte {
+	var msg []byte
+	if service != "" {
+		msg = protowire.AppendTag(msg, 1, protowire.BytesType)
+		msg = protowire.AppendString(msg, service)
+	}
+	b := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(b[1:], uint32(len(msg)))
+	return append(b, msg...)
+}
+
+// parseGRPCHealthCheckResponse parses a length-prefixed gRPC message
+// containing a grpc.health.v1.HealthCheckResponse.
+func parseGRPCHealthCheckResponse(b []byte) (grpcServingStatus, error) {
+	if len(b) < 5 {
+		return 0, errors.New("short response message")
+	}
+	if b[0] != 0 {
+		return 0, errors.New("compressed response message")
+	}
+	n := binary.BigEndian.Uint32(b[1:5])
+	if int(n) != len(b)-5 {
+		return 0, fmt.Errorf("response message length %d, want %d", len(b)-5, n)
+	}
+	msg := b[5:]
+	status := grpcStatusUnknown
+	for len(msg) > 0 {
+		num, typ, n := protowire.ConsumeTag(msg)
+		if n < 0 {
+			return 0, protowire.ParseError(n)
+		}
+		msg = msg[n:]
+		if num == 1 && typ == protowire.VarintType {
+			v, n := protowire.ConsumeVarint(msg)
+			if n < 0 {
+				return 0, protowire.ParseError(n)
+			}
+			status = grpcServingStatus(v)
+			msg = msg[n:]
+			continue
+		}
+		n = protowire.ConsumeFieldValue(num, typ, msg)
+		if n < 0 {
+			return 0, protowire.ParseError(n)
+		}
+		msg = msg[n:]
+	}
+	return status, nil
+}
+
+// Check executes a gRPC healthcheck.
+func (hc *GRPCChecker) Check(timeout time.Duration) *Result {
+	msg := fmt.Sprintf("gRPC health check for service %q to port %d", hc.Service, hc.Port)
+	start := time.Now()
+	if timeout == time.Duration(0) {
+		timeout = defaultGRPCTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	u := &url.URL{Scheme: "http", Host: hc.addr(), Path: grpcHealthCheckPath}
+	protocols := new(http.Protocols)
+	if hc.Secure {
+		u.Scheme = "https"
+		protocols.SetHTTP2(true)
+	} else {
+		protocols.SetUnencryptedHTTP2(true)
+	}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dialTCPContext(ctx, hc.network(), hc.addr(), timeout, hc.Mark)
+		},
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: !hc.TLSVerify,
+			ServerName:         hc.ServerName,
+		},
+		Protocols: protocols,
+	}
+	defer transport.CloseIdleConnections()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), bytes.NewReader(grpcHealthCheckRequest(hc.Service)))
+	if err != nil {
+		return complete(start, msg, false, err)
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	req.Header.Set("Grpc-Timeout", strconv.FormatInt(timeout.Milliseconds(), 10)+"m")
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			msg = fmt.Sprintf("%s; RPC failed with status %s", msg, grpcCodeName(4))
+		} else {
+			msg = fmt.Sprintf("%s; connection failed", msg)
+		}
+		return complete(start, msg, false, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg = fmt.Sprintf("%s; RPC failed with HTTP status %d", msg, resp.StatusCode)
+		return complete(start, msg, false, nil)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, grpcMaxMessageSize))
+	if err != nil {
+		msg = fmt.Sprintf("%s; RPC failed reading response", msg)
+		return complete(start, msg, false, err)
+	}
+
+	// The gRPC status is sent in the trailers, or in the headers of a
+	// response without a body.
+	grpcStatus := resp.Trailer.Get("Grpc-Status")
+	grpcMessage := resp.Trailer.Get("Grpc-Message")
+	if grpcStatus == "" {
+		grpcStatus = resp.Header.Get("Grpc-Status")
+		grpcMessage = resp.Header.Get("Grpc-Message")
+	}
+	code, err := strconv.Atoi(grpcStatus)
+	if err != nil {
+		msg = fmt.Sprintf("%s; RPC failed with invalid gRPC status %q", msg, grpcStatus)
+		return complete(start, msg, false, nil)
+	}
+	if code != 0 {
+		msg = fmt.Sprintf("%s; RPC failed with status %s", msg, grpcCodeName(code))
+		if grpcMessage != "" {
+			if m, err := url.PathUnescape(grpcMessage); err == nil {
+				grpcMessage = m
+			}
+			msg = fmt.Sprintf("%s: %s", msg, grpcMessage)
+		}
+		return complete(start, msg, false, nil)
+	}
+
+	status, err := parseGRPCHealthCheckResponse(body)
+	if err != nil {
+		msg = fmt.Sprintf("%s; RPC failed with invalid response", msg)
+		return complete(start, msg, false, err)
+	}
+	msg = fmt.Sprintf("%s; service is %s", msg, status)
+	return complete(start, msg, status == grpcStatusServing, nil)
+}
@@ -0,0 +1,163 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// grpcHealthHandler implements the grpc.health.v1.Health/Check method,
+// returning the configured status for each known service.
+type grpcHealthHandler map[string]grpcServingStatus
+
+func (h grpcHealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != grpcHealthCheckPath || r.Header.Get("Content-Type") != "application/grpc" {
+		http.NotFound(w, r)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	var service string
+	if len(body) > 5 {
+		msg := body[5:]
+		if num, typ, n := protowire.ConsumeTag(msg); n > 0 && num == 1 && typ == protowire.BytesType {
+			service, _ = protowire.ConsumeString(msg[n:])
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/grpc")
+	status, ok := h[service]
+	if !ok {
+		// Trailers-only response.
+		w.Header().Set("Grpc-Status", "5")
+		w.Header().Set("Grpc-Message", "unknown service "+service)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	w.Header().Set("Trailer", "Grpc-Status")
+	msg := protowire.AppendTag(nil, 1, protowire.VarintType)
+	msg = protowire.AppendVarint(msg, uint64(status))
+	resp := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(resp[1:], uint32(len(msg)))
+	w.Write(append(resp, msg...))
+	w.Header().Set("Grpc-Status", "0")
+}
+
+func newGRPCTestServer(t *testing.T, h http.Handler, secure bool) (net.IP, int) {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(h)
+	if secure {
+		srv.EnableHTTP2 = true
+		srv.StartTLS()
+	} else {
+		srv.Config.Protocols = new(http.Protocols)
+		srv.Config.Protocols.SetUnencryptedHTTP2(true)
+		srv.Start()
+	}
+	t.Cleanup(srv.Close)
+	addr := srv.Listener.Addr().(*net.TCPAddr)
+	return addr.IP, addr.Port
+}
+
+var grpcTests = []struct {
+	desc    string
+	service string
+	secure  bool
+	success bool
+	message string
+}{
+	{"serving", "", false, true, "service is SERVING"},
+	{"serving service", "backend", false, true, "service is SERVING"},
+	{"not serving", "draining", false, false, "service is NOT_SERVING"},
+	{"rpc error", "missing", false, false, "RPC failed with status NOT_FOUND: unknown service missing"},
+	{"secure serving", "backend", true, true, "service is SERVING"},
+}
+
+func TestGRPCChecker(t *testing.T) {
+	handler := grpcHealthHandler{
+		"":         grpcStatusServing,
+		"backend":  grpcStatusServing,
+		"draining": grpcStatusNotServing,
+	}
+	plainIP, plainPort := newGRPCTestServer(t, handler, false)
+	secureIP, securePort := newGRPCTestServer(t, handler, true)
+
+	for _, test := range grpcTests {
+		hc := NewGRPCChecker(plainIP, plainPort)
+		if test.secure {
+			hc = NewGRPCChecker(secureIP, securePort)
+			hc.Secure = true
+			hc.TLSVerify = false
+		}
+		hc.Service = test.service
+		result := hc.Check(time.Second)
+		if result.Success != test.success {
+			t.Errorf("%s: got success %v, want %v (%s)", test.desc, result.Success, test.success, result.Message)
+		}
+		if !strings.Contains(result.Message, test.message) {
+			t.Errorf("%s: got message %q, want it to contain %q", test.desc, result.Message, test.message)
+		}
+	}
+}
+
+func TestGRPCCheckerConnectionFailure(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	addr := ln.Addr().(*net.TCPAddr)
+	ln.Close()
+
+	hc := NewGRPCChecker(addr.IP, addr.Port)
+	result := hc.Check(time.Second)
+	if result.Success {
+		t.Fatalf("Check succeeded against a closed port: %s", result.Message)
+	}
+	if !strings.Contains(result.Message, "connection failed") {
+		t.Errorf("got message %q, want it to contain %q", result.Message, "connection failed")
+	}
+}
+
+func TestGRPCCheckerDeadline(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	ip, port := newGRPCTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-block:
+		case <-r.Context().Done():
+		}
+	}), false)
+
+	hc := NewGRPCChecker(ip, port)
+	start := time.Now()
+	result := hc.Check(200 * time.Millisecond)
+	if result.Success {
+		t.Fatalf("Check succeeded against a stalled server: %s", result.Message)
+	}
+	if !strings.Contains(result.Message, "DEADLINE_EXCEEDED") {
+		t.Errorf("got message %q, want it to contain %q", result.Message, "DEADLINE_EXCEEDED")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Check took %v, want it bounded by the timeout", elapsed)
+	}
+}
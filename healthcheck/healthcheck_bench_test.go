@@ -0,0 +1,62 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+// This file contains benchmarks for healthchecks.
+
+import (
+	"testing"
+)
+
+// benchmarkHTTPSCheck runs HTTPS healthchecks against a local server, with the
+// checker configured by the given function.
+func benchmarkHTTPSCheck(b *testing.B, configure func(hc *HTTPChecker)) {
+	l, a, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		b.Fatalf("Failed to get TCP listener: %v", err)
+	}
+	srv := newLocalHTTPServer(l)
+	srv.StartTLS()
+	defer srv.Close()
+
+	hc := NewHTTPChecker(a.IP, a.Port)
+	hc.Secure = true
+	hc.TLSVerify = false
+	hc.Request = "/healthz"
+	configure(hc)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if result := hc.Check(timeout); !result.Success {
+			b.Fatalf("HTTPS healthcheck to %v failed: %v", a, result)
+		}
+	}
+}
+
+func BenchmarkHTTPSCheckHandshake(b *testing.B) {
+	benchmarkHTTPSCheck(b, func(hc *HTTPChecker) {})
+}
+
+func BenchmarkHTTPSCheckResumedSessions(b *testing.B) {
+	benchmarkHTTPSCheck(b, func(hc *HTTPChecker) {
+		hc.ResumeSessions = true
+	})
+}
+
+func BenchmarkHTTPSCheckReusedConnections(b *testing.B) {
+	benchmarkHTTPSCheck(b, func(hc *HTTPChecker) {
+		hc.ReuseConnections = true
+	})
+}
@@ -17,12 +17,37 @@
 package healthcheck
 
 import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"io"
+	"math/big"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/rpc"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/google/seesaw/common/ipc"
+	"github.com/google/seesaw/common/seesaw"
+	"github.com/miekg/dns"
 )
 
 const timeout = 1 * time.Second
@@ -51,6 +76,18 @@ func newLocalTCPListener(n string) (*net.TCPListener, *net.TCPAddr, error) {
 	return l, tcpAddr, nil
 }
 
+// serveConns accepts connections on the listener until it is closed, calling
+// handle for each connection in a separate goroutine.
+func serveConns(l net.Listener, handle func(net.Conn)) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go handle(conn)
+	}
+}
+
 func newLocalUDPConn(n string) (*net.UDPConn, *net.UDPAddr, error) {
 	var addr string
 	switch n {
@@ -194,6 +231,749 @@ func TestHTTPCheckerSecure(t *testing.T) {
 	testHTTPChecker(t, true)
 }
 
+func TestHTTPCheckerHeaders(t *testing.T) {
+	l, a, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to get TCP listener: %v", err)
+	}
+	srv := &httptest.Server{
+		Listener: l,
+		Config: &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Seesaw-Check") != "yes" || r.Host != "www.example.com" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		})},
+	}
+	srv.Start()
+	defer srv.Close()
+
+	hc := NewHTTPChecker(a.IP, a.Port)
+	if result := hc.Check(timeout); result.Success {
+		t.Errorf("HTTP healthcheck to %v without headers succeeded: %v", a, result)
+	}
+	hc.Headers = map[string]string{"X-Seesaw-Check": "yes"}
+	if result := hc.Check(timeout); result.Success {
+		t.Errorf("HTTP healthcheck to %v without Host succeeded: %v", a, result)
+	}
+	hc.Headers["host"] = "www.example.com"
+	if result := hc.Check(timeout); !result.Success {
+		t.Errorf("HTTP healthcheck to %v with headers failed: %v", a, result)
+	}
+}
+
+func TestHTTPCheckerResponseRegexp(t *testing.T) {
+	l, a, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to get TCP listener: %v", err)
+	}
+	status := "degraded"
+	srv := &httptest.Server{
+		Listener: l,
+		Config: &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "%s\nstatus: %s\n", strings.Repeat("x", 200), status)
+		})},
+	}
+	srv.Start()
+	defer srv.Close()
+
+	hc := NewHTTPChecker(a.IP, a.Port)
+	if err := hc.SetResponseRegexp("status: (ok"); err == nil {
+		t.Error("SetResponseRegexp succeeded with invalid regexp")
+	}
+	if err := hc.SetResponseRegexp("(?m)^status: ok$"); err != nil {
+		t.Fatalf("SetResponseRegexp failed: %v", err)
+	}
+	result := hc.Check(timeout)
+	if result.Success {
+		t.Errorf("HTTP healthcheck to %v succeeded with degraded response: %v", a, result)
+	}
+	if !strings.Contains(result.Message, `"xxxx`) || !strings.HasSuffix(result.Message, `"...`) {
+		t.Errorf("HTTP healthcheck message lacks truncated response: %q", result.Message)
+	}
+
+	status = "ok"
+	if result := hc.Check(timeout); !result.Success {
+		t.Errorf("HTTP healthcheck to %v failed with ok response: %v", a, result)
+	}
+
+	// The match only considers the first MaxBodyBytes of the response.
+	hc.MaxBodyBytes = 100
+	if result := hc.Check(timeout); result.Success {
+		t.Errorf("HTTP healthcheck to %v matched beyond MaxBodyBytes: %v", a, result)
+	}
+}
+
+func TestParseResponseCodes(t *testing.T) {
+	tests := []struct {
+		in    string
+		codes []int
+		ok    bool
+	}{
+		{"200", []int{200}, true},
+		{"200, 204,301-302", []int{200, 204, 301, 302}, true},
+		{"5XX", nil, true},
+		{"", nil, false},
+		{"2x", nil, false},
+		{"302-301", nil, false},
+		{"99", nil, false},
+		{"600", nil, false},
+		{"200,foo", nil, false},
+	}
+	for _, test := range tests {
+		codes, err := ParseResponseCodes(test.in)
+		if (err == nil) != test.ok {
+			t.Errorf("ParseResponseCodes(%q) error = %v, want ok %t", test.in, err, test.ok)
+			continue
+		}
+		if test.codes != nil && !reflect.DeepEqual(codes, test.codes) {
+			t.Errorf("ParseResponseCodes(%q) = %v, want %v", test.in, codes, test.codes)
+		}
+	}
+	if codes, _ := ParseResponseCodes("5xx"); len(codes) != 100 || codes[0] != 500 || codes[99] != 599 {
+		t.Errorf("ParseResponseCodes(%q) = %v, want 500-599", "5xx", codes)
+	}
+}
+
+func TestHTTPCheckerResponseCodes(t *testing.T) {
+	l, a, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to get TCP listener: %v", err)
+	}
+	srv := newLocalHTTPServer(l)
+	srv.Start()
+	defer srv.Close()
+
+	hc := NewHTTPChecker(a.IP, a.Port)
+	hc.Request = "/notfound"
+	hc.ResponseCodes = []int{200, 404}
+	if result := hc.Check(timeout); !result.Success {
+		t.Errorf("HTTP healthcheck to %v failed with codes %v: %v", a, hc.ResponseCodes, result)
+	}
+	hc.ResponseCodes = []int{200, 204}
+	if result := hc.Check(timeout); result.Success {
+		t.Errorf("HTTP healthcheck to %v succeeded with codes %v: %v", a, hc.ResponseCodes, result)
+	}
+
+	// ResponseCodes takes precedence over ResponseCode.
+	hc.ResponseCode = 404
+	if result := hc.Check(timeout); result.Success {
+		t.Errorf("HTTP healthcheck to %v succeeded with codes %v: %v", a, hc.ResponseCodes, result)
+	}
+}
+
+func TestHTTPCheckerRedirects(t *testing.T) {
+	l, a, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to get TCP listener: %v", err)
+	}
+	var liveHost string
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", http.RedirectHandler("/healthz/live", http.StatusFound))
+	mux.Handle("/loop", http.RedirectHandler("/loop", http.StatusFound))
+	mux.Handle("/away", http.RedirectHandler("http://other.example.com/healthz/live", http.StatusFound))
+	mux.Handle("/vhost", http.RedirectHandler("http://www.example.com/healthz/live", http.StatusFound))
+	mux.HandleFunc("/healthz/live", func(w http.ResponseWriter, r *http.Request) {
+		liveHost = r.Host
+		fmt.Fprintf(w, "ok\n")
+	})
+	srv := &httptest.Server{Listener: l, Config: &http.Server{Handler: mux}}
+	srv.Start()
+	defer srv.Close()
+
+	hc := NewHTTPChecker(a.IP, a.Port)
+	hc.Request = "/healthz"
+	if result := hc.Check(timeout); result.Success {
+		t.Errorf("HTTP healthcheck to %v followed redirect: %v", a, result)
+	}
+
+	hc.FollowRedirects = true
+	result := hc.Check(timeout)
+	if !result.Success {
+		t.Errorf("HTTP healthcheck to %v failed to follow redirect: %v", a, result)
+	}
+	if !strings.Contains(result.Message, "redirected via") {
+		t.Errorf("HTTP healthcheck message lacks redirect chain: %q", result.Message)
+	}
+
+	hc.Request = "/loop"
+	hc.MaxRedirects = 2
+	result = hc.Check(timeout)
+	if result.Success || !strings.Contains(result.Message, "stopped after 2 redirects") {
+		t.Errorf("HTTP healthcheck to %v with redirect loop: %v", a, result)
+	}
+
+	hc.Request = "/away"
+	if result := hc.Check(timeout); result.Success {
+		t.Errorf("HTTP healthcheck to %v followed cross-host redirect: %v", a, result)
+	}
+	hc.CrossHostRedirects = true
+	if result := hc.Check(timeout); !result.Success {
+		t.Errorf("HTTP healthcheck to %v failed to follow cross-host redirect: %v", a, result)
+	}
+	if liveHost != "other.example.com" {
+		t.Errorf("Redirected request has Host %q, want %q", liveHost, "other.example.com")
+	}
+
+	// A redirect to the host named in the Host header is not cross-host.
+	hc.CrossHostRedirects = false
+	hc.Request = "/vhost"
+	hc.Headers = map[string]string{"Host": "www.example.com"}
+	if result := hc.Check(timeout); !result.Success {
+		t.Errorf("HTTP healthcheck to %v failed to follow redirect to Host: %v", a, result)
+	}
+	if liveHost != "www.example.com" {
+		t.Errorf("Redirected request has Host %q, want %q", liveHost, "www.example.com")
+	}
+}
+
+func TestHTTPCheckerHeaderChecks(t *testing.T) {
+	l, a, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to get TCP listener: %v", err)
+	}
+	drain := false
+	srv := &httptest.Server{
+		Listener: l,
+		Config: &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if drain {
+				w.Header().Set("X-Drain", "true")
+			}
+			w.Header().Set("X-Version", "1.2.3")
+			fmt.Fprintf(w, "ok\n")
+		})},
+	}
+	srv.Start()
+	defer srv.Close()
+
+	hc := NewHTTPChecker(a.IP, a.Port)
+	hc.ForbiddenHeaders = []string{"x-drain"}
+	hc.ExpectedHeaders = map[string]string{"x-version": "1.2.3"}
+	if result := hc.Check(timeout); !result.Success {
+		t.Errorf("HTTP healthcheck to %v failed with expected headers: %v", a, result)
+	}
+
+	drain = true
+	result := hc.Check(timeout)
+	if result.Success || !strings.Contains(result.Message, "forbidden header x-drain") {
+		t.Errorf("HTTP healthcheck to %v with forbidden header: %v", a, result)
+	}
+	drain = false
+
+	hc.ExpectedHeaders = map[string]string{"X-Version": "1.2"}
+	result = hc.Check(timeout)
+	if result.Success || !strings.Contains(result.Message, "unexpected header X-Version") {
+		t.Errorf("HTTP healthcheck to %v with mismatched header: %v", a, result)
+	}
+
+	hc.ExpectedHeaders = map[string]string{"X-Missing": "yes"}
+	result = hc.Check(timeout)
+	if result.Success || !strings.Contains(result.Message, "missing header X-Missing") {
+		t.Errorf("HTTP healthcheck to %v with missing header: %v", a, result)
+	}
+
+	if err := hc.SetExpectedHeaders(map[string]string{"X-Version": "(1"}, true); err == nil {
+		t.Error("SetExpectedHeaders succeeded with invalid regexp")
+	}
+	if err := hc.SetExpectedHeaders(map[string]string{"X-Version": `^1\.\d+\.\d+$`}, true); err != nil {
+		t.Fatalf("SetExpectedHeaders failed: %v", err)
+	}
+	if result := hc.Check(timeout); !result.Success {
+		t.Errorf("HTTP healthcheck to %v failed with header regexp: %v", a, result)
+	}
+	if err := hc.SetExpectedHeaders(map[string]string{"X-Version": `^2\.`}, true); err != nil {
+		t.Fatalf("SetExpectedHeaders failed: %v", err)
+	}
+	if result := hc.Check(timeout); result.Success {
+		t.Errorf("HTTP healthcheck to %v succeeded with mismatched header regexp: %v", a, result)
+	}
+}
+
+func TestHTTPCheckerRequestBody(t *testing.T) {
+	l, a, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to get TCP listener: %v", err)
+	}
+	payload := `{"subsystem":"db"}`
+	srv := &httptest.Server{
+		Listener: l,
+		Config: &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			if err != nil || string(body) != payload || r.ContentLength != int64(len(payload)) ||
+				r.Header.Get("Content-Type") != "application/json" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		})},
+	}
+	srv.Start()
+	defer srv.Close()
+
+	hc := NewHTTPChecker(a.IP, a.Port)
+	hc.Method = "POST"
+	hc.Request = "/check"
+	hc.RequestBody = []byte(payload)
+	hc.ContentType = "application/json"
+
+	// The body must be sent in full on every check.
+	for i := 0; i < 3; i++ {
+		if result := hc.Check(timeout); !result.Success {
+			t.Errorf("HTTP POST healthcheck %d to %v failed: %v", i, a, result)
+		}
+	}
+
+	// The body is only sent for POST and PUT.
+	hc.Method = "GET"
+	if result := hc.Check(timeout); result.Success {
+		t.Errorf("HTTP GET healthcheck to %v sent a request body: %v", a, result)
+	}
+}
+
+func TestHTTPCheckerNoBody(t *testing.T) {
+	l, a, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to get TCP listener: %v", err)
+	}
+	defer l.Close()
+
+	// The server announces a body that it never sends and holds the
+	// connection open until the client closes it.
+	closed := make(chan bool, 10)
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				br := bufio.NewReader(c)
+				req, err := http.ReadRequest(br)
+				if err != nil {
+					return
+				}
+				status := "200 OK"
+				if req.URL.Path == "/nocontent" {
+					status = "204 No Content"
+				}
+				fmt.Fprintf(c, "HTTP/1.1 %s\r\nContent-Length: 100\r\n\r\n", status)
+				c.SetReadDeadline(time.Now().Add(5 * time.Second))
+				_, err = br.ReadByte()
+				closed <- err == io.EOF
+			}()
+		}
+	}()
+
+	for _, test := range []struct {
+		method  string
+		request string
+	}{
+		{"HEAD", "/"},
+		{"GET", "/nocontent"},
+	} {
+		hc := NewHTTPChecker(a.IP, a.Port)
+		hc.Method = test.method
+		hc.Request = test.request
+		hc.ResponseCodes = []int{200, 204}
+		hc.Response = "ok"
+		start := time.Now()
+		result := hc.Check(5 * time.Second)
+		if d := time.Since(start); d > time.Second {
+			t.Errorf("HTTP %s %s took %v", test.method, test.request, d)
+		}
+		if result.Success {
+			t.Errorf("HTTP %s %s succeeded without a body: %v", test.method, test.request, result)
+		}
+		select {
+		case ok := <-closed:
+			if !ok {
+				t.Errorf("HTTP %s %s connection was not closed by the client", test.method, test.request)
+			}
+		case <-time.After(2 * time.Second):
+			t.Errorf("HTTP %s %s connection was not closed", test.method, test.request)
+		}
+	}
+}
+
+// newServerCert returns a self-signed server certificate for host, along with
+// its PEM encoding.
+func newServerCert(t *testing.T, host string) (tls.Certificate, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key},
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestHTTPCheckerServerName(t *testing.T) {
+	certA, pemA := newServerCert(t, "a.example.com")
+	certB, pemB := newServerCert(t, "b.example.com")
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	if err := os.WriteFile(caFile, append(pemA, pemB...), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	l, a, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to get TCP listener: %v", err)
+	}
+	var serverName string
+	srv := &httptest.Server{
+		Listener: l,
+		Config: &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			serverName = r.TLS.ServerName
+		})},
+		TLS: &tls.Config{
+			GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+				if hello.ServerName == "b.example.com" {
+					return &certB, nil
+				}
+				return &certA, nil
+			},
+		},
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	hc := NewHTTPChecker(a.IP, a.Port)
+	hc.Secure = true
+	hc.CACertFile = caFile
+
+	// Without a server name, the certificate cannot be verified against
+	// the target IP.
+	if result := hc.Check(timeout); result.Success {
+		t.Errorf("HTTPS healthcheck to %v succeeded without server name: %v", a, result)
+	}
+
+	for _, name := range []string{"a.example.com", "b.example.com"} {
+		hc.ServerName = name
+		hc.Headers = map[string]string{"Host": "other.example.com"}
+		if result := hc.Check(timeout); !result.Success {
+			t.Errorf("HTTPS healthcheck to %v with server name %q failed: %v", a, name, result)
+		}
+		if serverName != name {
+			t.Errorf("HTTPS healthcheck to %v sent SNI %q, want %q", a, serverName, name)
+		}
+	}
+}
+
+// connTracker counts the connections accepted by an HTTP server and the
+// requests made on resumed TLS sessions.
+type connTracker struct {
+	conns   atomic.Int32
+	resumed atomic.Int32
+}
+
+func (ct *connTracker) server(l net.Listener) *httptest.Server {
+	srv := newLocalHTTPServer(l)
+	handler := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil && r.TLS.DidResume {
+			ct.resumed.Add(1)
+		}
+		handler.ServeHTTP(w, r)
+	})
+	srv.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			ct.conns.Add(1)
+		}
+	}
+	return srv
+}
+
+func TestHTTPCheckerConnectionReuse(t *testing.T) {
+	l, a, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to get TCP listener: %v", err)
+	}
+	var ct connTracker
+	srv := ct.server(l)
+	srv.StartTLS()
+	defer srv.Close()
+
+	newChecker := func() *HTTPChecker {
+		hc := NewHTTPChecker(a.IP, a.Port)
+		hc.Secure = true
+		hc.TLSVerify = false
+		hc.Request = "/healthz"
+		return hc
+	}
+	check := func(hc *HTTPChecker, n int) {
+		t.Helper()
+		for i := 0; i < n; i++ {
+			if result := hc.Check(timeout); !result.Success {
+				t.Fatalf("HTTPS healthcheck to %v failed: %v", a, result)
+			}
+		}
+	}
+	expect := func(desc string, conns, resumed int32) {
+		t.Helper()
+		if got := ct.conns.Swap(0); got != conns {
+			t.Errorf("%s: got %d connections, want %d", desc, got, conns)
+		}
+		if got := ct.resumed.Swap(0); got != resumed {
+			t.Errorf("%s: got %d resumed sessions, want %d", desc, got, resumed)
+		}
+	}
+
+	check(newChecker(), 3)
+	expect("default", 3, 0)
+
+	hc := newChecker()
+	hc.ResumeSessions = true
+	check(hc, 3)
+	expect("resumed sessions", 3, 2)
+
+	hc = newChecker()
+	hc.ReuseConnections = true
+	check(hc, 3)
+	expect("reused connections", 1, 0)
+
+	// A connection closed by the server is replaced.
+	srv.CloseClientConnections()
+	check(hc, 1)
+	expect("closed connection", 1, 0)
+
+	// A new checker with the same settings inherits the held connection,
+	// while one with different settings does not.
+	next := newChecker()
+	next.ReuseConnections = true
+	next.Inherit(hc)
+	check(next, 1)
+	expect("inherited connection", 0, 0)
+
+	other := newChecker()
+	other.ReuseConnections = true
+	other.ServerName = "example.com"
+	other.Inherit(next)
+	check(other, 1)
+	expect("changed settings", 1, 0)
+}
+
+// connectProxy is a minimal HTTP CONNECT proxy that requires basic
+// authentication.
+func connectProxy(l net.Listener, user, password string) {
+	auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password))
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer c.Close()
+			br := bufio.NewReader(c)
+			req, err := http.ReadRequest(br)
+			if err != nil {
+				return
+			}
+			if req.Method != "CONNECT" || req.Header.Get("Proxy-Authorization") != auth {
+				fmt.Fprintf(c, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n")
+				return
+			}
+			backend, err := net.Dial("tcp", req.Host)
+			if err != nil {
+				fmt.Fprintf(c, "HTTP/1.1 502 Bad Gateway\r\n\r\n")
+				return
+			}
+			defer backend.Close()
+			fmt.Fprintf(c, "HTTP/1.1 200 Connection established\r\n\r\n")
+			go io.Copy(backend, br)
+			io.Copy(c, backend)
+		}()
+	}
+}
+
+func TestHTTPCheckerProxyURL(t *testing.T) {
+	l, a, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to get TCP listener: %v", err)
+	}
+	srv := newLocalHTTPServer(l)
+	srv.Start()
+	defer srv.Close()
+
+	pl, pa, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to get TCP listener: %v", err)
+	}
+	defer pl.Close()
+	go connectProxy(pl, "probe", "s3cret")
+
+	hc := NewHTTPChecker(a.IP, a.Port)
+	hc.Request = "/healthz"
+	hc.ProxyURL = fmt.Sprintf("http://probe:s3cret@%v", pa)
+	result := hc.Check(timeout)
+	if !result.Success {
+		t.Errorf("HTTP healthcheck to %v via proxy %v failed: %v", a, pa, result)
+	}
+	if !strings.Contains(result.Message, "via proxy") {
+		t.Errorf("HTTP healthcheck message lacks proxy: %q", result.Message)
+	}
+
+	hc.ProxyURL = fmt.Sprintf("http://probe:wrong@%v", pa)
+	result = hc.Check(timeout)
+	if result.Success || !strings.HasPrefix(result.String(), "proxy ") || !strings.Contains(result.String(), "407") {
+		t.Errorf("HTTP healthcheck to %v via proxy with bad credentials: %v", a, result)
+	}
+
+	// A backend that closes the connection fails at the backend stage.
+	bl, ba, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to get TCP listener: %v", err)
+	}
+	defer bl.Close()
+	go func() {
+		for {
+			c, err := bl.Accept()
+			if err != nil {
+				return
+			}
+			c.Close()
+		}
+	}()
+	hc = NewHTTPChecker(ba.IP, ba.Port)
+	hc.ProxyURL = fmt.Sprintf("http://probe:s3cret@%v", pa)
+	result = hc.Check(timeout)
+	if result.Success || !strings.HasPrefix(result.String(), "backend ") {
+		t.Errorf("HTTP healthcheck to closed backend %v via proxy: %v", ba, result)
+	}
+
+	// The timeout covers the proxy handshake.
+	sl, sa, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to get TCP listener: %v", err)
+	}
+	defer sl.Close()
+	hc = NewHTTPChecker(a.IP, a.Port)
+	hc.ProxyURL = fmt.Sprintf("http://%v", sa)
+	start := time.Now()
+	result = hc.Check(200 * time.Millisecond)
+	if result.Success || !strings.HasPrefix(result.String(), "proxy ") {
+		t.Errorf("HTTP healthcheck to %v via unresponsive proxy: %v", a, result)
+	}
+	if d := time.Since(start); d > timeout {
+		t.Errorf("HTTP healthcheck via unresponsive proxy took %v", d)
+	}
+}
+
+// writeClientCert writes a self-signed client certificate and key to dir,
+// returning the certificate and the paths of the written files.
+func writeClientCert(t *testing.T, dir, name string) (*x509.Certificate, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return cert, certFile, keyFile
+}
+
+func TestHTTPCheckerClientCert(t *testing.T) {
+	dir := t.TempDir()
+	clientCert, certFile, keyFile := writeClientCert(t, dir, "client")
+	_, otherCertFile, otherKeyFile := writeClientCert(t, dir, "other")
+
+	l, a, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to get TCP listener: %v", err)
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	srv := newLocalHTTPServer(l)
+	srv.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	caFile := filepath.Join(dir, "ca.crt")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	hc := NewHTTPChecker(a.IP, a.Port)
+	hc.Secure = true
+	if result := hc.Check(timeout); result.Success || !strings.Contains(result.String(), "server certificate verification failed") {
+		t.Errorf("HTTPS healthcheck to %v without CA certificates: %v", a, result)
+	}
+
+	hc.CACertFile = caFile
+	if err := hc.LoadCertificates(); err != nil {
+		t.Fatalf("LoadCertificates failed: %v", err)
+	}
+	if result := hc.Check(timeout); result.Success {
+		t.Errorf("HTTPS healthcheck to %v succeeded without client certificate: %v", a, result)
+	}
+
+	hc.CertFile, hc.KeyFile = otherCertFile, otherKeyFile
+	if err := hc.LoadCertificates(); err != nil {
+		t.Fatalf("LoadCertificates failed: %v", err)
+	}
+	if result := hc.Check(timeout); result.Success || !strings.Contains(result.String(), "client certificate rejected") {
+		t.Errorf("HTTPS healthcheck to %v with untrusted client certificate: %v", a, result)
+	}
+
+	hc.CertFile, hc.KeyFile = certFile, keyFile
+	if err := hc.LoadCertificates(); err != nil {
+		t.Fatalf("LoadCertificates failed: %v", err)
+	}
+	if result := hc.Check(timeout); !result.Success {
+		t.Errorf("HTTPS healthcheck to %v with client certificate failed: %v", a, result)
+	}
+
+	// Certificates are cached rather than reloaded on each check.
+	if err := os.Remove(certFile); err != nil {
+		t.Fatal(err)
+	}
+	if result := hc.Check(timeout); !result.Success {
+		t.Errorf("HTTPS healthcheck to %v with cached client certificate failed: %v", a, result)
+	}
+	if err := hc.LoadCertificates(); err == nil {
+		t.Error("LoadCertificates succeeded with missing certificate file")
+	}
+}
+
 type tcpTest struct {
 	send     string
 	receive  string
@@ -267,30 +1047,165 @@ func TestTCPChecker(t *testing.T) {
 	}
 }
 
-type udpTest struct {
-	send     string
-	receive  string
-	expected bool
-}
-
-func (ut udpTest) configure(hc *UDPChecker) {
-	hc.Send = ut.send
-	hc.Receive = ut.receive
-}
-
-func (ut udpTest) String() string {
-	return fmt.Sprintf("{%q, %q}", ut.send, ut.receive)
+func TestTCPCheckerCancel(t *testing.T) {
+	l, a, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to get TCP listener: %v", err)
+	}
+	defer l.Close()
+	// Accept connections but never respond.
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer c.Close()
+		}
+	}()
+
+	hc := NewTCPChecker(a.IP, a.Port)
+	hc.Receive = "never"
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start := time.Now()
+	if result := hc.CheckContext(ctx, 10*time.Second); result.Success {
+		t.Errorf("Cancelled TCP healthcheck %v succeeded: %v", hc, result)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Cancelled TCP healthcheck took %v to return", elapsed)
+	}
 }
 
-func udpEchoHandler(c *net.UDPConn) {
-	buf := make([]byte, 64)
+// tcpStatusHandler answers each connection with reply once a line has been
+// received, then holds the connection open until the client closes it.
+func tcpStatusHandler(l net.Listener, reply string) {
 	for {
-		n, addr, err := c.ReadFrom(buf)
+		c, err := l.Accept()
 		if err != nil {
 			return
 		}
-		if _, err = c.WriteTo(buf[0:n], addr); err != nil {
-			return
+		go func() {
+			defer c.Close()
+			if _, err := bufio.NewReader(c).ReadString('\n'); err != nil {
+				return
+			}
+			io.WriteString(c, reply)
+			io.Copy(io.Discard, c)
+		}()
+	}
+}
+
+var tcpRegexpTests = []struct {
+	desc     string
+	reply    string
+	receive  string
+	regexp   string
+	max      int
+	expected bool
+	message  string
+}{
+	{"match", "STATUS OK 42\r\n", "", `OK \d+`, 0, true, "response matched"},
+	{"prefix and match", "STATUS OK 42\r\n", "STATUS", `OK \d+`, 0, true, "response matched"},
+	{"prefix mismatch", "STATE OK 42\r\n", "STATUS", `OK \d+`, 0, false, `unexpected response - "STATE OK`},
+	{"no match", "STATUS DEGRADED\r\n", "", `OK \d+`, 0, false, "response does not match"},
+	{"match beyond limit", "STATUS OK 42\r\n", "", `OK \d+`, 8, false, `response does not match "OK \\d+" - "STATUS O"`},
+}
+
+func TestTCPCheckerReceiveRegexp(t *testing.T) {
+	cert, _ := newServerCert(t, "status.example.com")
+	for _, secure := range []bool{false, true} {
+		for _, test := range tcpRegexpTests {
+			tl, a, err := newLocalTCPListener("tcp4")
+			if err != nil {
+				t.Fatalf("Failed to get TCP listener: %v", err)
+			}
+			l := net.Listener(tl)
+			if secure {
+				l = tls.NewListener(tl, &tls.Config{Certificates: []tls.Certificate{cert}})
+			}
+			go tcpStatusHandler(l, test.reply)
+
+			hc := NewTCPChecker(a.IP, a.Port)
+			hc.Send = "STATUS\n"
+			hc.Receive = test.receive
+			hc.MaxReceiveBytes = test.max
+			hc.Secure = secure
+			if err := hc.SetReceiveRegexp(test.regexp); err != nil {
+				t.Fatalf("SetReceiveRegexp(%q) failed: %v", test.regexp, err)
+			}
+			// The connection is held open, so a match must not wait for
+			// the timeout.
+			start := time.Now()
+			result := hc.Check(time.Second)
+			l.Close()
+			if result.Success != test.expected {
+				t.Errorf("%s (secure %v): got success %v, want %v: %v", test.desc, secure, result.Success, test.expected, result)
+			}
+			if !strings.Contains(result.Message, test.message) {
+				t.Errorf("%s (secure %v): got message %q, want it to contain %q", test.desc, secure, result.Message, test.message)
+			}
+			if test.expected && time.Since(start) > 500*time.Millisecond {
+				t.Errorf("%s (secure %v): healthcheck took %v, want it to return once matched", test.desc, secure, time.Since(start))
+			}
+		}
+	}
+}
+
+func TestTCPCheckerReceiveRegexpTimeout(t *testing.T) {
+	l, a, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to get TCP listener: %v", err)
+	}
+	defer l.Close()
+	go tcpStatusHandler(l, "STATUS")
+
+	hc := NewTCPChecker(a.IP, a.Port)
+	hc.Send = "STATUS\n"
+	if err := hc.SetReceiveRegexp(`OK \d+`); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	result := hc.Check(200 * time.Millisecond)
+	if result.Success {
+		t.Fatalf("TCP healthcheck succeeded without a matching response: %v", result)
+	}
+	if want := `response does not match "OK \\d+" - "STATUS"`; !strings.Contains(result.Message, want) {
+		t.Errorf("got message %q, want it to contain %q", result.Message, want)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("TCP healthcheck took %v, want it bounded by the timeout", elapsed)
+	}
+
+	if err := hc.SetReceiveRegexp("("); err == nil {
+		t.Error("SetReceiveRegexp succeeded with an invalid expression")
+	}
+}
+
+type udpTest struct {
+	send     string
+	receive  string
+	expected bool
+}
+
+func (ut udpTest) configure(hc *UDPChecker) {
+	hc.Send = ut.send
+	hc.Receive = ut.receive
+}
+
+func (ut udpTest) String() string {
+	return fmt.Sprintf("{%q, %q}", ut.send, ut.receive)
+}
+
+func udpEchoHandler(c *net.UDPConn) {
+	buf := make([]byte, 64)
+	for {
+		n, addr, err := c.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		if _, err = c.WriteTo(buf[0:n], addr); err != nil {
+			return
 		}
 	}
 }
@@ -332,6 +1247,520 @@ func TestUDPChecker(t *testing.T) {
 	}
 }
 
+func TestUDPCheckerNoResponse(t *testing.T) {
+	c, a, err := newLocalUDPConn("udp4")
+	if err != nil {
+		t.Fatalf("Failed to get UDPConn: %v", err)
+	}
+	defer c.Close()
+
+	// Without an expected response, a service that does not reply is healthy.
+	hc := NewUDPChecker(a.IP, a.Port)
+	hc.Send = "foo"
+	if result := hc.Check(200 * time.Millisecond); !result.Success {
+		t.Errorf("UDP healthcheck %v to %v failed: %v", hc, a, result)
+	}
+
+	hc.Receive = "foo"
+	if result := hc.Check(200 * time.Millisecond); result.Success {
+		t.Errorf("UDP healthcheck %v to %v succeeded: %v", hc, a, result)
+	}
+}
+
+// dnsZone answers DNS queries from a fixed set of resource records,
+// returning NXDOMAIN for names that have no records. RRSIG records are only
+// returned, and the AD bit only set, for queries with the DNSSEC OK bit.
+type dnsZone struct {
+	records       []dns.RR
+	authoritative bool
+	authenticated bool
+}
+
+func newDNSZone(t *testing.T, records ...string) *dnsZone {
+	zone := &dnsZone{}
+	for _, s := range records {
+		rr, err := dns.NewRR(s)
+		if err != nil {
+			t.Fatalf("Failed to parse RR %q: %v", s, err)
+		}
+		zone.records = append(zone.records, rr)
+	}
+	return zone
+}
+
+func (z *dnsZone) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	m := new(dns.Msg)
+	m.SetReply(r)
+	m.Authoritative = z.authoritative
+	m.Rcode = dns.RcodeNameError
+	q := r.Question[0]
+	do := r.IsEdns0() != nil && r.IsEdns0().Do()
+	for _, rr := range z.records {
+		if !strings.EqualFold(rr.Header().Name, q.Name) {
+			continue
+		}
+		m.Rcode = dns.RcodeSuccess
+		if rr.Header().Rrtype == q.Qtype {
+			m.Answer = append(m.Answer, rr)
+		}
+		if sig, ok := rr.(*dns.RRSIG); ok && do && sig.TypeCovered == q.Qtype {
+			m.Answer = append(m.Answer, rr)
+		}
+	}
+	if m.Rcode == dns.RcodeNameError && do {
+		for _, rr := range z.records {
+			if sig, ok := rr.(*dns.RRSIG); ok && sig.TypeCovered == dns.TypeNSEC {
+				m.Ns = append(m.Ns, rr)
+			}
+		}
+	}
+	m.AuthenticatedData = z.authenticated && do
+	w.WriteMsg(m)
+}
+
+// newLocalDNSServer starts DNS servers listening on the same local UDP and
+// TCP port. The returned function shuts them down.
+func newLocalDNSServer(t *testing.T, h dns.Handler) (*net.UDPAddr, func()) {
+	c, a, err := newLocalUDPConn("udp4")
+	if err != nil {
+		t.Fatalf("Failed to get UDPConn: %v", err)
+	}
+	l, err := net.ListenTCP("tcp4", &net.TCPAddr{IP: a.IP, Port: a.Port})
+	if err != nil {
+		c.Close()
+		t.Skipf("Failed to listen on TCP port %d: %v", a.Port, err)
+	}
+	servers := []*dns.Server{
+		{PacketConn: c, Handler: h},
+		{Listener: l, Handler: h},
+	}
+	for _, srv := range servers {
+		started := make(chan struct{})
+		srv.NotifyStartedFunc = func() { close(started) }
+		go srv.ActivateAndServe()
+		<-started
+	}
+	return a, func() {
+		for _, srv := range servers {
+			srv.Shutdown()
+		}
+	}
+}
+
+type dnsTest struct {
+	qtype    uint16
+	name     string
+	answer   string
+	expected bool
+}
+
+var dnsTests = []dnsTest{
+	{dns.TypeA, "www.example.com", "192.0.2.1", true},
+	{dns.TypeA, "www.example.com", "192.0.2.2", false},
+	{dns.TypeTXT, "svc.example.com", "v=svc1 weight=10", true},
+	{dns.TypeTXT, "svc.example.com", "weight=10", true},
+	{dns.TypeTXT, "svc.example.com", "weight=20", false},
+	{dns.TypeTXT, "www.example.com", "v=svc1", false},
+	{dns.TypeSRV, "_http._tcp.example.com", "backend1.example.com", true},
+	{dns.TypeSRV, "_http._tcp.example.com", "BACKEND2.example.com.", true},
+	{dns.TypeSRV, "_http._tcp.example.com", "backend1.example.com:8080", true},
+	{dns.TypeSRV, "_http._tcp.example.com", "backend1.example.com:8081", false},
+	{dns.TypeSRV, "_http._tcp.example.com", "backend2.example.com:8081", true},
+	{dns.TypeSRV, "_http._tcp.example.com", "backend3.example.com", false},
+	{dns.TypeSRV, "_http._tcp.example.com", "backend1.example.com:http", false},
+	{dns.TypeMX, "example.com", "mail.example.com", true},
+	{dns.TypeMX, "example.com", "mail.example.com.", true},
+	{dns.TypeMX, "example.com", "smtp.example.com", false},
+	{dns.TypePTR, "1.2.0.192.in-addr.arpa", "www.example.com", true},
+	{dns.TypePTR, "1.2.0.192.in-addr.arpa", "mail.example.com", false},
+}
+
+func TestDNSChecker(t *testing.T) {
+	a, stop := newLocalDNSServer(t, newDNSZone(t,
+		"www.example.com. 60 IN A 192.0.2.1",
+		`svc.example.com. 60 IN TXT "v=svc1 " "weight=10"`,
+		"_http._tcp.example.com. 60 IN SRV 10 5 8080 backend1.example.com.",
+		"_http._tcp.example.com. 60 IN SRV 10 5 8081 backend2.example.com.",
+		"example.com. 60 IN MX 10 mail.example.com.",
+		"1.2.0.192.in-addr.arpa. 60 IN PTR www.example.com.",
+	))
+	defer stop()
+
+	for _, dt := range dnsTests {
+		hc := NewDNSChecker(a.IP, a.Port)
+		hc.Question.Name = dt.name
+		hc.Question.Qtype = dt.qtype
+		hc.Answer = dt.answer
+		if result := hc.Check(timeout); result.Success != dt.expected {
+			t.Errorf("DNS healthcheck %v for %q got success %v, want %v: %v",
+				hc, dt.answer, result.Success, dt.expected, result)
+		}
+	}
+}
+
+func TestDNSCheckerRcode(t *testing.T) {
+	tests := []struct {
+		name          string
+		authoritative bool
+		requireAuth   bool
+		rcode         int
+		expected      bool
+		msg           string
+	}{
+		{"www.example.com", false, false, dns.RcodeSuccess, true, "received answer"},
+		{"www.example.com", false, true, dns.RcodeSuccess, false, "not authoritative"},
+		{"www.example.com", true, true, dns.RcodeSuccess, true, "received answer"},
+		{"missing.example.com", false, false, dns.RcodeSuccess, false, "unexpected response code NXDOMAIN, want NOERROR"},
+		{"missing.example.com", false, false, dns.RcodeNameError, true, "received expected response code NXDOMAIN"},
+		{"missing.example.com", false, true, dns.RcodeNameError, false, "not authoritative"},
+		{"missing.example.com", true, true, dns.RcodeNameError, true, "received expected response code NXDOMAIN"},
+		{"www.example.com", true, false, dns.RcodeNameError, false, "unexpected response code NOERROR, want NXDOMAIN"},
+	}
+	for _, test := range tests {
+		zone := newDNSZone(t, "www.example.com. 60 IN A 192.0.2.1")
+		zone.authoritative = test.authoritative
+		a, stop := newLocalDNSServer(t, zone)
+		hc := NewDNSChecker(a.IP, a.Port)
+		hc.Question.Name = test.name
+		hc.Answer = "192.0.2.1"
+		hc.RequireAuthoritative = test.requireAuth
+		hc.ExpectedRcode = test.rcode
+		result := hc.Check(timeout)
+		if result.Success != test.expected {
+			t.Errorf("DNS healthcheck %v (authoritative %v, rcode %s) got success %v, want %v: %v",
+				hc, test.authoritative, dns.RcodeToString[test.rcode], result.Success, test.expected, result)
+		}
+		if !strings.Contains(result.Message, test.msg) {
+			t.Errorf("DNS healthcheck %v message %q does not contain %q", hc, result.Message, test.msg)
+		}
+		stop()
+	}
+}
+
+func TestDNSCheckerDNSSEC(t *testing.T) {
+	const (
+		sigA    = "www.example.com. 60 IN RRSIG A 8 3 60 20300101000000 20200101000000 12345 example.com. AAAA"
+		sigNSEC = "example.com. 60 IN RRSIG NSEC 8 2 60 20300101000000 20200101000000 12345 example.com. AAAA"
+	)
+	tests := []struct {
+		desc          string
+		records       []string
+		authenticated bool
+		name          string
+		rcode         int
+		require       bool
+		mode          DNSSECMode
+		expected      bool
+		msg           string
+	}{
+		{"not required", nil, false, "www.example.com", dns.RcodeSuccess, false, DNSSECAuthenticated, true, "received answer"},
+		{"AD set", nil, true, "www.example.com", dns.RcodeSuccess, true, DNSSECAuthenticated, true, "received answer"},
+		{"AD not set", []string{sigA}, false, "www.example.com", dns.RcodeSuccess, true, DNSSECAuthenticated, false, "AD not set"},
+		{"RRSIG present", []string{sigA}, false, "www.example.com", dns.RcodeSuccess, true, DNSSECSigned, true, "received answer"},
+		{"RRSIG missing", nil, true, "www.example.com", dns.RcodeSuccess, true, DNSSECSigned, false, "no RRSIG present for www.example.com. A"},
+		{"signed denial", []string{sigNSEC}, false, "missing.example.com", dns.RcodeNameError, true, DNSSECSigned, true, "received expected response code NXDOMAIN"},
+		{"unsigned denial", nil, false, "missing.example.com", dns.RcodeNameError, true, DNSSECSigned, false, "no RRSIG present in authority section"},
+	}
+	for _, test := range tests {
+		zone := newDNSZone(t, append([]string{"www.example.com. 60 IN A 192.0.2.1"}, test.records...)...)
+		zone.authenticated = test.authenticated
+		a, stop := newLocalDNSServer(t, zone)
+		for _, useTCP := range []bool{false, true} {
+			hc := NewDNSChecker(a.IP, a.Port)
+			hc.Question.Name = test.name
+			hc.Answer = "192.0.2.1"
+			hc.ExpectedRcode = test.rcode
+			hc.RequireDNSSEC = test.require
+			hc.DNSSECMode = test.mode
+			hc.UseTCP = useTCP
+			result := hc.Check(timeout)
+			if result.Success != test.expected {
+				t.Errorf("%s: DNS healthcheck %v (TCP %v) got success %v, want %v: %v",
+					test.desc, hc, useTCP, result.Success, test.expected, result)
+			}
+			if !strings.Contains(result.Message, test.msg) {
+				t.Errorf("%s: DNS healthcheck %v (TCP %v) message %q does not contain %q",
+					test.desc, hc, useTCP, result.Message, test.msg)
+			}
+		}
+		stop()
+	}
+}
+
+func TestDNSCheckerTruncated(t *testing.T) {
+	rr, err := dns.NewRR("www.example.com. 60 IN A 192.0.2.1")
+	if err != nil {
+		t.Fatalf("Failed to parse RR: %v", err)
+	}
+	var udpQueries, tcpQueries, udpSize uint32
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(r)
+		if _, ok := w.RemoteAddr().(*net.UDPAddr); ok {
+			atomic.AddUint32(&udpQueries, 1)
+			if opt := r.IsEdns0(); opt != nil {
+				atomic.StoreUint32(&udpSize, uint32(opt.UDPSize()))
+			}
+			m.Truncated = true
+		} else {
+			atomic.AddUint32(&tcpQueries, 1)
+			m.Answer = []dns.RR{rr}
+		}
+		w.WriteMsg(m)
+	})
+
+	a, stop := newLocalDNSServer(t, handler)
+	defer stop()
+
+	hc := NewDNSChecker(a.IP, a.Port)
+	hc.Question.Name = "www.example.com"
+	hc.Answer = "192.0.2.1"
+	result := hc.Check(timeout)
+	if !result.Success {
+		t.Errorf("DNS healthcheck %v failed: %v", hc, result)
+	}
+	if !strings.Contains(result.Message, "retried over TCP") {
+		t.Errorf("DNS healthcheck %v message %q does not note TCP fallback", hc, result.Message)
+	}
+	if got := atomic.LoadUint32(&udpQueries); got != 1 {
+		t.Errorf("DNS healthcheck %v sent %d UDP queries, want 1", hc, got)
+	}
+	if got := atomic.LoadUint32(&tcpQueries); got != 1 {
+		t.Errorf("DNS healthcheck %v sent %d TCP queries, want 1", hc, got)
+	}
+	if got := atomic.LoadUint32(&udpSize); got != defaultDNSUDPSize {
+		t.Errorf("DNS healthcheck %v advertised EDNS0 UDP size %d, want %d", hc, got, defaultDNSUDPSize)
+	}
+
+	hc.UDPSize = 4096
+	if result := hc.Check(timeout); !result.Success {
+		t.Errorf("DNS healthcheck %v failed: %v", hc, result)
+	}
+	if got := atomic.LoadUint32(&udpSize); got != 4096 {
+		t.Errorf("DNS healthcheck %v advertised EDNS0 UDP size %d, want 4096", hc, got)
+	}
+
+	// Queries made over TCP are not retried.
+	hc.UseTCP = true
+	result = hc.Check(timeout)
+	if !result.Success {
+		t.Errorf("DNS healthcheck %v failed: %v", hc, result)
+	}
+	if strings.Contains(result.Message, "retried over TCP") {
+		t.Errorf("DNS healthcheck %v message %q notes TCP fallback", hc, result.Message)
+	}
+	if got := atomic.LoadUint32(&udpQueries); got != 2 {
+		t.Errorf("DNS healthcheck %v sent %d UDP queries, want 2", hc, got)
+	}
+}
+
+func TestDNSCheckerStrict(t *testing.T) {
+	zone := newDNSZone(t, "www.example.com. 60 IN A 192.0.2.1")
+	tests := []struct {
+		desc     string
+		mangle   func(m *dns.Msg)
+		strictID bool
+		use0x20  bool
+		expected bool
+		msg      string
+	}{
+		{"default", nil, false, false, true, "received answer"},
+		{"0x20", nil, false, true, true, "received answer"},
+		{"strict ID", nil, true, false, true, "received answer"},
+		{"mismatched ID", func(m *dns.Msg) { m.Id++ }, false, false, true, "received answer"},
+		{"strict mismatched ID", func(m *dns.Msg) { m.Id++ }, true, false, false, "does not match query ID"},
+		{"lowercased question", func(m *dns.Msg) { m.Question[0].Name = "www.example.com." }, false, false, true, "received answer"},
+		{"0x20 lowercased question", func(m *dns.Msg) { m.Question[0].Name = "www.example.com." }, false, true, false, "does not preserve 0x20 encoding"},
+		{"0x20 wrong question", func(m *dns.Msg) { m.Question[0].Name = "ftp.example.com." }, false, true, false, "response question mismatch"},
+	}
+	for _, test := range tests {
+		a, stop := newLocalDNSServer(t, dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			zone.ServeDNS(&dnsMangleWriter{ResponseWriter: w, mangle: test.mangle}, r)
+		}))
+		hc := NewDNSChecker(a.IP, a.Port)
+		hc.Question.Name = "www.example.com"
+		hc.Answer = "192.0.2.1"
+		hc.StrictIDCheck = test.strictID
+		hc.Use0x20 = test.use0x20
+		result := hc.Check(timeout)
+		if result.Success != test.expected {
+			t.Errorf("%s: DNS healthcheck %v got success %v, want %v: %v",
+				test.desc, hc, result.Success, test.expected, result)
+		}
+		if !strings.Contains(result.Message, test.msg) {
+			t.Errorf("%s: DNS healthcheck %v message %q does not contain %q",
+				test.desc, hc, result.Message, test.msg)
+		}
+		stop()
+	}
+
+	// With 0x20 encoding, query names should vary in case.
+	var names []string
+	var namesLock sync.Mutex
+	a, stop := newLocalDNSServer(t, dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		namesLock.Lock()
+		names = append(names, r.Question[0].Name)
+		namesLock.Unlock()
+		zone.ServeDNS(w, r)
+	}))
+	defer stop()
+	hc := NewDNSChecker(a.IP, a.Port)
+	hc.Question.Name = "www.example.com"
+	hc.Answer = "192.0.2.1"
+	hc.Use0x20 = true
+	for i := 0; i < 8; i++ {
+		if result := hc.Check(timeout); !result.Success {
+			t.Errorf("DNS healthcheck %v failed: %v", hc, result)
+		}
+	}
+	namesLock.Lock()
+	sent := append([]string(nil), names...)
+	namesLock.Unlock()
+	seen := make(map[string]bool)
+	for _, name := range sent {
+		if !strings.EqualFold(name, "www.example.com.") {
+			t.Errorf("DNS healthcheck %v sent query name %q", hc, name)
+		}
+		seen[name] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("DNS healthcheck %v sent query names %q, want randomised case", hc, sent)
+	}
+}
+
+// dnsMangleWriter modifies DNS responses before they are written.
+type dnsMangleWriter struct {
+	dns.ResponseWriter
+	mangle func(m *dns.Msg)
+}
+
+func (w *dnsMangleWriter) WriteMsg(m *dns.Msg) error {
+	if w.mangle != nil {
+		w.mangle(m)
+	}
+	return w.ResponseWriter.WriteMsg(m)
+}
+
+// dnsDelayHandler answers A queries for www.example.com after a delay,
+// without answering the first drop queries it receives.
+type dnsDelayHandler struct {
+	delay   time.Duration
+	drop    uint32
+	queries uint32
+}
+
+func (h *dnsDelayHandler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	if atomic.AddUint32(&h.queries, 1) <= h.drop {
+		return
+	}
+	time.Sleep(h.delay)
+	m := new(dns.Msg)
+	m.SetReply(r)
+	rr, _ := dns.NewRR("www.example.com. 60 IN A 192.0.2.1")
+	m.Answer = []dns.RR{rr}
+	w.WriteMsg(m)
+}
+
+func TestDNSCheckerLatency(t *testing.T) {
+	a, stop := newLocalDNSServer(t, &dnsDelayHandler{delay: 50 * time.Millisecond})
+	defer stop()
+
+	tests := []struct {
+		warn, max time.Duration
+		expected  bool
+		msg       string
+	}{
+		{0, 0, true, "query latency"},
+		{0, time.Second, true, "query latency"},
+		{0, 10 * time.Millisecond, false, "exceeds maximum 10ms"},
+		{10 * time.Millisecond, 0, true, "exceeds warning threshold 10ms"},
+		{10 * time.Millisecond, time.Second, true, "exceeds warning threshold 10ms"},
+	}
+	for _, test := range tests {
+		hc := NewDNSChecker(a.IP, a.Port)
+		hc.Question.Name = "www.example.com"
+		hc.Answer = "192.0.2.1"
+		hc.WarnLatency = test.warn
+		hc.MaxLatency = test.max
+		result := hc.Check(timeout)
+		if result.Success != test.expected {
+			t.Errorf("DNS healthcheck %v (warn %v, max %v) got success %v, want %v: %v",
+				hc, test.warn, test.max, result.Success, test.expected, result)
+		}
+		if !strings.Contains(result.Message, test.msg) {
+			t.Errorf("DNS healthcheck %v message %q does not contain %q", hc, result.Message, test.msg)
+		}
+	}
+}
+
+func TestDNSCheckerQueryAttempts(t *testing.T) {
+	tests := []struct {
+		drop     uint32
+		attempts int
+		expected bool
+		msg      string
+	}{
+		{0, 0, true, "received answer"},
+		{1, 0, false, "failed to read response"},
+		{1, 1, false, "failed to read response"},
+		{1, 3, true, "attempt 2 of 3"},
+		{2, 3, true, "attempt 3 of 3"},
+		{3, 3, false, "attempt 3 of 3; failed to read response"},
+	}
+	for _, test := range tests {
+		h := &dnsDelayHandler{drop: test.drop}
+		a, stop := newLocalDNSServer(t, h)
+		hc := NewDNSChecker(a.IP, a.Port)
+		hc.Question.Name = "www.example.com"
+		hc.Answer = "192.0.2.1"
+		hc.QueryAttempts = test.attempts
+		result := hc.Check(300 * time.Millisecond)
+		if result.Success != test.expected {
+			t.Errorf("DNS healthcheck %v (drop %d, attempts %d) got success %v, want %v: %v",
+				hc, test.drop, test.attempts, result.Success, test.expected, result)
+		}
+		if !strings.Contains(result.Message, test.msg) {
+			t.Errorf("DNS healthcheck %v message %q does not contain %q", hc, result.Message, test.msg)
+		}
+		stop()
+	}
+}
+
+func TestPingChecker(t *testing.T) {
+	for _, unprivileged := range []bool{false, true} {
+		for _, ip := range []net.IP{net.ParseIP("127.0.0.1"), net.IPv6loopback} {
+			// Run several checks concurrently, to ensure that replies are
+			// matched to the correct checker.
+			var wg sync.WaitGroup
+			results := make([]*Result, 8)
+			checkers := make([]*PingChecker, len(results))
+			for i := range results {
+				checkers[i] = NewPingChecker(ip)
+				checkers[i].Unprivileged = unprivileged
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					for j := 0; j < 3; j++ {
+						results[i] = checkers[i].Check(timeout)
+						if !results[i].Success {
+							return
+						}
+					}
+				}(i)
+			}
+			wg.Wait()
+			for i, result := range results {
+				if errors.Is(result.Err, os.ErrPermission) {
+					t.Skipf("Ping healthcheck %v (unprivileged %v) not permitted: %v", checkers[i], unprivileged, result.Err)
+				}
+				if !result.Success {
+					t.Errorf("Ping healthcheck %v (unprivileged %v) failed: %v", checkers[i], unprivileged, result)
+				}
+			}
+		}
+	}
+}
+
 type fakeChecker struct {
 	succeed bool
 	sleepy  bool
@@ -415,77 +1844,379 @@ func TestCheckRetries(t *testing.T) {
 	}
 }
 
-func TestCheckRun(t *testing.T) {
+func TestCheckRiseFall(t *testing.T) {
 	notify := make(chan *Notification, 10)
+	checker := &fakeChecker{}
+	config := NewConfig(1, checker)
 	hc := NewCheck(notify)
-	hc.Blocking(true)
-	go hc.Run(nil)
-
-	config := NewConfig(1, &fakeChecker{succeed: true})
-	config.Interval = 250 * time.Millisecond
-	hc.Update(config)
+	hc.Config = *config
+	hc.Config.Rise = 3
+	hc.Config.Fall = 2
 
-	time.Sleep(800 * time.Millisecond)
-	config.Checker = &fakeChecker{succeed: false}
-	config.Interval = 500 * time.Millisecond
-	hc.Update(config)
+	// The first result determines the initial state.
+	checker.succeed = true
+	hc.healthcheck()
+	if hc.state != StateHealthy {
+		t.Errorf("Unexpected healthcheck state - got %v, want %v", hc.state, StateHealthy)
+	}
 
-	time.Sleep(1200 * time.Millisecond)
-	hc.Stop()
+	// Interleaved results do not cause transitions.
+	for _, succeed := range []bool{false, true, false, true, false, true} {
+		checker.succeed = succeed
+		hc.healthcheck()
+		if hc.state != StateHealthy {
+			t.Errorf("Unexpected healthcheck state - got %v, want %v", hc.state, StateHealthy)
+		}
+	}
 
-	s := hc.Status()
-	t.Logf("Healthchecks resulted in %d failure(s) and %d success(es)",
-		s.Failures, s.Successes)
+	// Fall consecutive failures result in a transition.
+	checker.succeed = false
+	hc.healthcheck()
+	hc.healthcheck()
+	if hc.state != StateUnhealthy {
+		t.Errorf("Unexpected healthcheck state - got %v, want %v", hc.state, StateUnhealthy)
+	}
 
-	// We expect 4 successes and 2 failures - allow a tolerance of -1/+1.
-	if s.Successes < 3 || s.Successes > 5 {
-		t.Errorf("Unexpected number of successes - got %d, want 4",
-			s.Successes)
+	// Rise consecutive successes are needed to recover, restarting the
+	// count after a failure.
+	for _, succeed := range []bool{true, true, false, true, true} {
+		checker.succeed = succeed
+		hc.healthcheck()
+		if hc.state != StateUnhealthy {
+			t.Errorf("Unexpected healthcheck state - got %v, want %v", hc.state, StateUnhealthy)
+		}
 	}
-	if s.Failures < 1 || s.Failures > 3 {
-		t.Errorf("Unexpected number of failures - got %d, want 2",
-			s.Failures)
+	checker.succeed = true
+	hc.healthcheck()
+	if hc.state != StateHealthy {
+		t.Errorf("Unexpected healthcheck state - got %v, want %v", hc.state, StateHealthy)
 	}
 
-	// We should have a notification for StateHealthy, followed by a
-	// notification for StateUnhealthy.
-	select {
-	case n := <-notify:
-		if n.State != StateHealthy {
-			t.Errorf("Unexpected state - got %v, want %v",
-				n.State, StateHealthy)
+	// Status counts every probe, regardless of transitions.
+	status := hc.Status()
+	if status.Successes != 9 || status.Failures != 6 {
+		t.Errorf("Got %d successes and %d failures, want 9 and 6", status.Successes, status.Failures)
+	}
+
+	for i, state := range []State{StateHealthy, StateUnhealthy, StateHealthy} {
+		select {
+		case n := <-notify:
+			if n.State != state {
+				t.Errorf("Notification %d got unexpected state %v, want %v", i+1, n.State, state)
+			}
+		default:
+			t.Errorf("Expected state change notification not received")
 		}
-	default:
-		t.Errorf("Expected state change notification not received")
 	}
 	select {
 	case n := <-notify:
-		if n.State != StateUnhealthy {
-			t.Errorf("Unexpected state - got %v, want %v",
-				n.State, StateUnhealthy)
-		}
+		t.Errorf("Received unexpected state change notification: %v", n.State)
 	default:
-		t.Errorf("Expected state change notification not received")
 	}
 }
 
-func TestCheckTimeout(t *testing.T) {
+func TestCheckFallRetries(t *testing.T) {
+	for _, test := range []struct {
+		retries, fall int
+		want          uint64
+	}{
+		{0, 0, 1},
+		{0, 1, 1},
+		{2, 1, 3},
+		{1, 4, 4},
+	} {
+		c := &Config{Retries: test.retries, Fall: test.fall}
+		if got := c.fallThreshold(); got != test.want {
+			t.Errorf("Retries %d, fall %d: got threshold %d, want %d", test.retries, test.fall, got, test.want)
+		}
+	}
+}
+
+func TestCheckPassive(t *testing.T) {
 	notify := make(chan *Notification, 10)
+	checker := &fakeChecker{}
+	config := NewConfig(1, checker)
+	config.Passive = true
 	hc := NewCheck(notify)
-	hc.Blocking(true)
-	go hc.Run(nil)
+	hc.Config = *config
 
-	// Increase timeout from 200ms to 1s, after 800ms. This should result
-	// in the config update being applied at the end of four failures
-	// (1000ms). We then wait for 1200ms before sending a stop notification,
-	// which should allow for two successful checks to complete.
-	config := NewConfig(1, &fakeChecker{succeed: true, sleepy: true})
+	// Passive checks still change state and notify, with the status tagged.
+	hc.healthcheck()
+	checker.succeed = true
+	hc.healthcheck()
+	for i, state := range []State{StateUnhealthy, StateHealthy} {
+		select {
+		case n := <-notify:
+			if n.State != state || !n.Passive {
+				t.Errorf("Notification %d got state %v (passive %v), want passive %v", i+1, n.State, n.Passive, state)
+			}
+		default:
+			t.Errorf("Expected state change notification not received")
+		}
+	}
+
+	hc.Config.Passive = false
+	checker.succeed = false
+	hc.healthcheck()
+	select {
+	case n := <-notify:
+		if n.State != StateUnhealthy || n.Passive {
+			t.Errorf("Got state %v (passive %v), want active %v", n.State, n.Passive, StateUnhealthy)
+		}
+	default:
+		t.Errorf("Expected state change notification not received")
+	}
+}
+
+func TestCheckHistory(t *testing.T) {
+	notify := make(chan *Notification, 10)
+	checker := &fakeChecker{}
+	hc := NewCheck(notify)
+	hc.Config = *NewConfig(1, checker)
+	hc.History(3)
+
+	// Only the most recent results are retained, oldest first.
+	for _, succeed := range []bool{true, true, false, true, false} {
+		checker.succeed = succeed
+		hc.healthcheck()
+	}
+	results := hc.Results()
+	var got []bool
+	for _, r := range results {
+		got = append(got, r.Success)
+	}
+	if want := []bool{false, true, false}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got results %v, want %v", got, want)
+	}
+	for i := 1; i < len(results); i++ {
+		if results[i].Time.Before(results[i-1].Time) {
+			t.Errorf("Result %d is older than result %d", i, i-1)
+		}
+	}
+
+	// Results are discarded when the healthcheck is stopped.
+	hc.Stop()
+	if results := hc.Results(); len(results) != 0 {
+		t.Errorf("Got %d results after stop, want 0", len(results))
+	}
+
+	hc.History(0)
+	hc.healthcheck()
+	if results := hc.Results(); results != nil {
+		t.Errorf("Got %d results with history disabled, want none", len(results))
+	}
+}
+
+func TestCheckHistoryIPC(t *testing.T) {
+	s := NewServer(&ServerConfig{ChannelSize: 10})
+	hc := NewCheck(s.notify)
+	hc.Config = *NewConfig(1, &fakeChecker{succeed: true})
+	hc.History(2)
+	hc.healthcheck()
+	s.healthchecks[1] = hc
+
+	ipcHC := &SeesawHealthcheck{s}
+	var reply History
+	args := &HistoryRequest{Ctx: ipc.NewContext(seesaw.SCLocalCLI), Ids: []Id{1}}
+	if err := ipcHC.CheckHistory(args, &reply); err == nil {
+		t.Error("Got history with an untrusted context")
+	}
+	args = &HistoryRequest{Ctx: ipc.NewTrustedContext(seesaw.SCEngine), Ids: []Id{1, 2}}
+	if err := ipcHC.CheckHistory(args, &reply); err != nil {
+		t.Fatalf("Failed to get history: %v", err)
+	}
+	if len(reply.Results) != 1 || len(reply.Results[1]) != 1 || !reply.Results[1][0].Success {
+		t.Errorf("Got results %v, want one success for healthcheck 1", reply.Results)
+	}
+}
+
+// fakeEngine receives healthcheck notifications on behalf of the engine.
+type fakeEngine struct {
+	batches chan []*Notification
+}
+
+func (e *fakeEngine) HealthState(args *HealthState, reply *int) error {
+	e.batches <- args.Notifications
+	return nil
+}
+
+// newFakeEngine returns a fakeEngine that is serving on a temporary socket,
+// along with the path of the socket.
+func newFakeEngine(t *testing.T) (*fakeEngine, string) {
+	socket := filepath.Join(t.TempDir(), "engine")
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("Failed to listen on %v: %v", socket, err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	engine := &fakeEngine{batches: make(chan []*Notification, 100)}
+	server := rpc.NewServer()
+	server.RegisterName("SeesawEngine", engine)
+	go server.Accept(ln)
+	return engine, socket
+}
+
+// batchIds returns the healthcheck IDs and states for a batch.
+func batchIds(batch []*Notification) []string {
+	var ids []string
+	for _, n := range batch {
+		ids = append(ids, fmt.Sprintf("%d/%v", n.Id, n.State))
+	}
+	return ids
+}
+
+func TestNotifyQueue(t *testing.T) {
+	hc := NewCheck(nil)
+	hc.Config = *NewConfig(1, &fakeChecker{succeed: true})
+	notification := func(state State, transition bool) *Notification {
+		hc.lock.Lock()
+		defer hc.lock.Unlock()
+		hc.state = state
+		return hc.notification(transition)
+	}
+
+	q := newNotifyQueue()
+	older := notification(StateHealthy, false)
+	q.add(&Notification{Id: 2, Status: Status{State: StateHealthy}})
+	q.add(older)
+	q.add(notification(StateUnhealthy, true))
+	q.add(notification(StateHealthy, true))
+	// A steady-state notification that arrives after a newer transition
+	// must be discarded.
+	q.add(older)
+	q.add(&Notification{Id: 2, Status: Status{State: StateUnhealthy}})
+	newer := notification(StateHealthy, false)
+	q.add(newer)
+
+	if got, want := q.len(), 4; got != want {
+		t.Fatalf("Got %d queued notifications, want %d", got, want)
+	}
+	if !q.full(4) || q.full(5) || q.full(0) {
+		t.Errorf("Got unexpected full queue for %d notifications", q.len())
+	}
+	got := batchIds(q.next(3))
+	want := []string{"1/Unhealthy", "1/Healthy", "2/Unhealthy"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got first batch %v, want %v", got, want)
+	}
+	got = batchIds(q.next(0))
+	want = []string{"1/Healthy"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got second batch %v, want %v", got, want)
+	}
+	if q.len() != 0 || len(q.next(0)) != 0 {
+		t.Errorf("Got %d queued notifications, want 0", q.len())
+	}
+}
+
+func TestNotifierTransitionPriority(t *testing.T) {
+	engine, socket := newFakeEngine(t)
+	s := NewServer(&ServerConfig{
+		BatchDelay:   time.Hour,
+		ChannelSize:  100,
+		EngineSocket: socket,
+		MaxBatchSize: 10,
+		MaxFailures:  1,
+	})
+	for i := 0; i < 50; i++ {
+		s.notify <- &Notification{Id: Id(100 + i), Status: Status{State: StateHealthy}}
+	}
+	s.transition <- &Notification{Id: 1, Status: Status{State: StateUnhealthy}, transition: true}
+	go s.notifier()
+
+	// The transition must be sent in the first batch, ahead of the queued
+	// steady-state notifications, which are sent once batches are full.
+	for i := 0; i < 5; i++ {
+		select {
+		case batch := <-engine.batches:
+			if len(batch) != 10 {
+				t.Errorf("Got batch of %d notifications, want 10", len(batch))
+			}
+			if i == 0 && (len(batch) == 0 || batch[0].Id != 1) {
+				t.Errorf("Got first batch %v, want transition for healthcheck 1 first", batchIds(batch))
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for batch %d", i+1)
+		}
+	}
+}
+
+func TestNotifierTransitionDelay(t *testing.T) {
+	engine, socket := newFakeEngine(t)
+	s := NewServer(&ServerConfig{
+		BatchDelay:   50 * time.Millisecond,
+		ChannelSize:  100,
+		EngineSocket: socket,
+		MaxBatchSize: 100,
+		MaxFailures:  1,
+	})
+	go s.notifier()
+
+	hc := NewCheck(s.notify)
+	hc.Config = *NewConfig(1, &fakeChecker{succeed: true})
+	hc.transition = s.transition
+	for i := 0; i < 10; i++ {
+		hc.Notify()
+	}
+	for _, succeed := range []bool{false, true} {
+		hc.Checker = &fakeChecker{succeed: succeed}
+		hc.healthcheck()
+	}
+
+	// The transitions must be sent within the flush window, in order, with
+	// the coalesced steady-state notifications discarded as they precede
+	// the transitions.
+	start := time.Now()
+	select {
+	case batch := <-engine.batches:
+		if d := time.Since(start); d > time.Second {
+			t.Errorf("Transition took %v to be sent, want at most %v", d, time.Second)
+		}
+		got := batchIds(batch)
+		want := []string{"1/Unhealthy", "1/Healthy"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Got batch %v, want %v", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for transitions")
+	}
+}
+
+func TestCheckNeedsSync(t *testing.T) {
+	notify := make(chan *Notification, 10)
+	hc := NewCheck(notify)
+	hc.Config = *NewConfig(1, &fakeChecker{succeed: true})
+	if hc.needsSync() {
+		t.Error("New healthcheck needs sync")
+	}
+	hc.lock.Lock()
+	hc.skipped++
+	hc.lock.Unlock()
+	if !hc.needsSync() {
+		t.Error("Healthcheck with skipped run does not need sync")
+	}
+	hc.Notify()
+	<-notify
+	if hc.needsSync() {
+		t.Error("Healthcheck needs sync after notification")
+	}
+}
+
+func TestCheckRun(t *testing.T) {
+	notify := make(chan *Notification, 10)
+	hc := NewCheck(notify)
+	hc.Blocking(true)
+	go hc.Run(nil)
+
+	config := NewConfig(1, &fakeChecker{succeed: true})
 	config.Interval = 250 * time.Millisecond
-	config.Timeout = 200 * time.Millisecond
 	hc.Update(config)
+
 	time.Sleep(800 * time.Millisecond)
-	config.Timeout = 1 * time.Second
+	config.Checker = &fakeChecker{succeed: false}
+	config.Interval = 500 * time.Millisecond
 	hc.Update(config)
+
 	time.Sleep(1200 * time.Millisecond)
 	hc.Stop()
 
@@ -493,15 +2224,76 @@ func TestCheckTimeout(t *testing.T) {
 	t.Logf("Healthchecks resulted in %d failure(s) and %d success(es)",
 		s.Failures, s.Successes)
 
-	// We expect 4 failures and 2 successes - allow a tolerance of -1/+1.
-	if s.Failures < 3 || s.Failures > 5 {
-		t.Errorf("Unexpected number of failures - got %d, want 4",
+	// We expect 4 successes and 2 failures - allow a tolerance of -1/+1.
+	if s.Successes < 3 || s.Successes > 5 {
+		t.Errorf("Unexpected number of successes - got %d, want 4",
+			s.Successes)
+	}
+	if s.Failures < 1 || s.Failures > 3 {
+		t.Errorf("Unexpected number of failures - got %d, want 2",
+			s.Failures)
+	}
+
+	// We should have a notification for StateHealthy, followed by a
+	// notification for StateUnhealthy.
+	select {
+	case n := <-notify:
+		if n.State != StateHealthy {
+			t.Errorf("Unexpected state - got %v, want %v",
+				n.State, StateHealthy)
+		}
+	default:
+		t.Errorf("Expected state change notification not received")
+	}
+	select {
+	case n := <-notify:
+		if n.State != StateUnhealthy {
+			t.Errorf("Unexpected state - got %v, want %v",
+				n.State, StateUnhealthy)
+		}
+	default:
+		t.Errorf("Expected state change notification not received")
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	notify := make(chan *Notification, 10)
+	hc := NewCheck(notify)
+	hc.Blocking(true)
+	go hc.Run(nil)
+
+	// The checker takes 500ms, so the interval is long enough for each
+	// timed out run to have completed before the next one is due. Increase
+	// timeout from 200ms to 1s, after 1300ms. This should result in the
+	// config update being applied at the end of three failures (1400ms).
+	// We then wait for 1700ms before sending a stop notification, which
+	// should allow for two successful checks to complete.
+	config := NewConfig(1, &fakeChecker{succeed: true, sleepy: true})
+	config.Interval = 600 * time.Millisecond
+	config.Timeout = 200 * time.Millisecond
+	hc.Update(config)
+	time.Sleep(1300 * time.Millisecond)
+	config.Timeout = 1 * time.Second
+	hc.Update(config)
+	time.Sleep(1700 * time.Millisecond)
+	hc.Stop()
+
+	s := hc.Status()
+	t.Logf("Healthchecks resulted in %d failure(s) and %d success(es)",
+		s.Failures, s.Successes)
+
+	// We expect 3 failures and 2 successes - allow a tolerance of -1/+1.
+	if s.Failures < 2 || s.Failures > 4 {
+		t.Errorf("Unexpected number of failures - got %d, want 3",
 			s.Failures)
 	}
 	if s.Successes < 1 || s.Successes > 3 {
 		t.Errorf("Unexpected number of successes - got %d, want 2",
 			s.Successes)
 	}
+	if s.Skipped != 0 {
+		t.Errorf("Unexpected number of skipped runs - got %d, want 0", s.Skipped)
+	}
 
 	// We should have a notification for StateUnhealthy, followed by a
 	// notification for StateHealthy.
@@ -525,6 +2317,352 @@ func TestCheckTimeout(t *testing.T) {
 	}
 }
 
+// latencyChecker is a checker that succeeds with a fixed duration.
+type latencyChecker struct {
+	duration time.Duration
+}
+
+func (hc *latencyChecker) String() string {
+	return "LATENCY"
+}
+
+func (hc *latencyChecker) Check(timeout time.Duration) *Result {
+	return &Result{Message: "ok", Success: true, Duration: hc.duration}
+}
+
+func TestCheckMaxLatency(t *testing.T) {
+	notify := make(chan *Notification, 10)
+	checker := &latencyChecker{duration: 4900 * time.Millisecond}
+	hc := NewCheck(notify)
+	hc.Config = *NewConfig(1, checker)
+
+	hc.healthcheck()
+	if hc.state != StateHealthy {
+		t.Errorf("Unexpected healthcheck state - got %v, want %v", hc.state, StateHealthy)
+	}
+
+	hc.Config.MaxLatency = time.Second
+	hc.healthcheck()
+	if hc.state != StateUnhealthy {
+		t.Errorf("Unexpected healthcheck state - got %v, want %v", hc.state, StateUnhealthy)
+	}
+	if got, want := hc.Status().Message, "ok; latency 4.9s exceeds threshold 1s"; got != want {
+		t.Errorf("Unexpected healthcheck message - got %q, want %q", got, want)
+	}
+
+	checker.duration = 500 * time.Millisecond
+	hc.healthcheck()
+	if hc.state != StateHealthy {
+		t.Errorf("Unexpected healthcheck state - got %v, want %v", hc.state, StateHealthy)
+	}
+}
+
+// slowChecker is a checker that takes longer than its timeout, while
+// tracking the number of concurrent runs.
+type slowChecker struct {
+	delay time.Duration
+
+	lock       sync.Mutex
+	running    int
+	maxRunning int
+	runs       int
+	cancelled  int
+}
+
+func (hc *slowChecker) String() string {
+	return "SLOW"
+}
+
+func (hc *slowChecker) begin() {
+	hc.lock.Lock()
+	defer hc.lock.Unlock()
+	hc.runs++
+	hc.running++
+	if hc.running > hc.maxRunning {
+		hc.maxRunning = hc.running
+	}
+}
+
+func (hc *slowChecker) end(cancelled bool) {
+	hc.lock.Lock()
+	defer hc.lock.Unlock()
+	hc.running--
+	if cancelled {
+		hc.cancelled++
+	}
+}
+
+func (hc *slowChecker) stats() (runs, maxRunning, cancelled int) {
+	hc.lock.Lock()
+	defer hc.lock.Unlock()
+	return hc.runs, hc.maxRunning, hc.cancelled
+}
+
+func (hc *slowChecker) Check(timeout time.Duration) *Result {
+	hc.begin()
+	time.Sleep(hc.delay)
+	hc.end(false)
+	return &Result{Success: true}
+}
+
+// cancellableChecker is a slowChecker that supports cancellation.
+type cancellableChecker struct {
+	slowChecker
+}
+
+func (hc *cancellableChecker) CheckContext(ctx context.Context, timeout time.Duration) *Result {
+	hc.begin()
+	select {
+	case <-time.After(hc.delay):
+		hc.end(false)
+		return &Result{Success: true}
+	case <-ctx.Done():
+		hc.end(true)
+		return &Result{Success: false, Err: ctx.Err()}
+	}
+}
+
+func TestCheckOverlapSkipped(t *testing.T) {
+	notify := make(chan *Notification, 10)
+	checker := &slowChecker{delay: 300 * time.Millisecond}
+	hc := NewCheck(notify)
+	hc.Config = *NewConfig(1, checker)
+	hc.Config.Timeout = 20 * time.Millisecond
+
+	// The first run times out while the checker is still running, so the
+	// second run must be skipped.
+	hc.healthcheck()
+	hc.healthcheck()
+	s := hc.Status()
+	if s.Failures != 1 || s.Skipped != 1 {
+		t.Errorf("Got %d failure(s) and %d skipped run(s), want 1 and 1", s.Failures, s.Skipped)
+	}
+	if want := "skipped: previous still running"; s.Message != want {
+		t.Errorf("Got status message %q, want %q", s.Message, want)
+	}
+	if runs, _, _ := checker.stats(); runs != 1 {
+		t.Errorf("Checker ran %d times, want 1", runs)
+	}
+
+	// Once the checker has returned, the next run proceeds.
+	time.Sleep(checker.delay + 50*time.Millisecond)
+	hc.healthcheck()
+	if s := hc.Status(); s.Failures != 2 || s.Skipped != 1 || s.Message == "skipped: previous still running" {
+		t.Errorf("Got status %+v, want 2 failures and 1 skipped run", s)
+	}
+}
+
+func TestCheckConcurrencyLimit(t *testing.T) {
+	const limit = 2
+	limiter := newCheckLimiter(limit)
+	// The checker ignores the timeout, so runs hold their slot after they
+	// have timed out. A single checker records the concurrency across all
+	// of the healthchecks.
+	checker := &slowChecker{delay: 150 * time.Millisecond}
+	var checks []*Check
+	for i := 0; i < 6; i++ {
+		hc := NewCheck(make(chan *Notification, 10))
+		hc.limiter = limiter
+		go hc.Run(nil)
+		config := NewConfig(Id(i), checker)
+		config.Interval = 200 * time.Millisecond
+		config.Timeout = 50 * time.Millisecond
+		hc.Update(config)
+		checks = append(checks, hc)
+	}
+	time.Sleep(time.Second)
+	for _, hc := range checks {
+		hc.Stop()
+	}
+
+	runs, maxRunning, _ := checker.stats()
+	var throttled uint64
+	var queued bool
+	for _, hc := range checks {
+		s := hc.Status()
+		throttled += s.Throttled
+		queued = queued || s.Queued > 0
+	}
+	t.Logf("%d run(s), %d throttled", runs, throttled)
+	if maxRunning > limit {
+		t.Errorf("Got %d concurrent runs, want at most %d", maxRunning, limit)
+	}
+	if runs < limit {
+		t.Errorf("Got %d runs, want at least %d", runs, limit)
+	}
+	if throttled == 0 {
+		t.Error("No runs were throttled")
+	}
+	if !queued {
+		t.Error("No runs recorded a queue delay")
+	}
+}
+
+func TestCheckLimiter(t *testing.T) {
+	l := newCheckLimiter(1)
+	release, queued := l.acquire(time.Second)
+	if release == nil || queued != 0 {
+		t.Fatalf("Failed to acquire an idle limiter: %v", queued)
+	}
+	if r, _ := l.acquire(50 * time.Millisecond); r != nil {
+		t.Fatal("Acquired a limiter beyond its limit")
+	}
+	time.AfterFunc(50*time.Millisecond, release)
+	r, queued := l.acquire(time.Second)
+	if r == nil {
+		t.Fatal("Failed to acquire a released limiter")
+	}
+	if queued < 25*time.Millisecond {
+		t.Errorf("Got queue delay %v, want at least 25ms", queued)
+	}
+
+	// Raising the limit allows further runs immediately, while a limit of
+	// zero disables the limiter.
+	l.setLimit(2)
+	if r, _ := l.acquire(0); r == nil {
+		t.Error("Failed to acquire a limiter after raising its limit")
+	}
+	l.setLimit(0)
+	for i := 0; i < 10; i++ {
+		if r, _ := l.acquire(0); r == nil {
+			t.Fatal("Failed to acquire a disabled limiter")
+		}
+	}
+}
+
+func TestCheckNoOverlap(t *testing.T) {
+	for _, cancel := range []bool{false, true} {
+		notify := make(chan *Notification, 10)
+		checker := &cancellableChecker{slowChecker{delay: 300 * time.Millisecond}}
+		hc := NewCheck(notify)
+		hc.Blocking(true)
+		hc.CancelOverlapping(cancel)
+		go hc.Run(nil)
+
+		config := NewConfig(1, checker)
+		config.Interval = 10 * time.Millisecond
+		config.Timeout = 20 * time.Millisecond
+		hc.Update(config)
+		time.Sleep(1 * time.Second)
+		hc.Stop()
+
+		runs, maxRunning, cancelled := checker.stats()
+		s := hc.Status()
+		t.Logf("Cancel %v: %d run(s), %d cancelled, %d skipped", cancel, runs, cancelled, s.Skipped)
+		if maxRunning != 1 {
+			t.Errorf("Cancel %v: got %d concurrent runs, want 1", cancel, maxRunning)
+		}
+		if runs < 2 {
+			t.Errorf("Cancel %v: got %d runs, want at least 2", cancel, runs)
+		}
+		if cancel {
+			if cancelled == 0 {
+				t.Errorf("Cancel %v: no runs were cancelled", cancel)
+			}
+		} else {
+			if cancelled != 0 {
+				t.Errorf("Cancel %v: got %d cancelled runs, want 0", cancel, cancelled)
+			}
+			if s.Skipped == 0 {
+				t.Errorf("Cancel %v: no runs were skipped", cancel)
+			}
+		}
+	}
+}
+
+// startChecker records the time of its first run.
+type startChecker struct {
+	once    sync.Once
+	started chan time.Time
+}
+
+func (hc *startChecker) String() string {
+	return "START"
+}
+
+func (hc *startChecker) Check(timeout time.Duration) *Result {
+	hc.once.Do(func() { hc.started <- time.Now() })
+	return &Result{Success: true}
+}
+
+func TestCheckSplay(t *testing.T) {
+	const (
+		checks   = 100
+		interval = time.Second
+		buckets  = 10
+	)
+	notify := make(chan *Notification, 2*checks)
+	started := make(chan time.Time, checks)
+	begin := time.Now()
+	for i := 0; i < checks; i++ {
+		hc := NewCheck(notify)
+		hc.Splay(true)
+		go hc.Run(nil)
+		defer hc.Stop()
+		config := NewConfig(Id(i), &startChecker{started: started})
+		config.Interval = interval
+		hc.Update(config)
+	}
+
+	// Every check starts within its first interval, spread across it
+	// rather than clustered at the beginning.
+	counts := make([]int, buckets)
+	for i := 0; i < checks; i++ {
+		select {
+		case start := <-started:
+			bucket := int(start.Sub(begin) * buckets / interval)
+			if bucket >= buckets {
+				bucket = buckets - 1
+			}
+			counts[bucket]++
+		case <-time.After(2 * interval):
+			t.Fatalf("Only %d of %d checks started", i, checks)
+		}
+	}
+	t.Logf("Start times per %v: %v", interval/buckets, counts)
+	used := 0
+	for _, count := range counts {
+		if count > 0 {
+			used++
+		}
+	}
+	if max := slices.Max(counts); max > 30 || used < 8 {
+		t.Errorf("Got start times per %v of %v, want them spread across the interval", interval/buckets, counts)
+	}
+}
+
+func TestCheckJitter(t *testing.T) {
+	hc := NewCheck(nil)
+	hc.Interval = time.Second
+	if d := hc.firstDelay(); d != 0 {
+		t.Errorf("Got first delay %v without splay, want 0", d)
+	}
+	if d := hc.nextDelay(); d != time.Second {
+		t.Errorf("Got next delay %v without jitter, want %v", d, time.Second)
+	}
+
+	for _, test := range []struct {
+		percent  int
+		min, max time.Duration
+	}{
+		{10, 900 * time.Millisecond, 1100 * time.Millisecond},
+		{200, 500 * time.Millisecond, 1500 * time.Millisecond},
+	} {
+		hc.Jitter(test.percent)
+		varied := false
+		for i := 0; i < 100; i++ {
+			d := hc.nextDelay()
+			if d < test.min || d > test.max {
+				t.Errorf("Jitter %d%%: got next delay %v, want between %v and %v", test.percent, d, test.min, test.max)
+			}
+			varied = varied || d != time.Second
+		}
+		if !varied {
+			t.Errorf("Jitter %d%%: next delay was never varied", test.percent)
+		}
+	}
+}
+
 func TestCheckDryrun(t *testing.T) {
 	notify := make(chan *Notification, 10)
 	hc := NewCheck(notify)
@@ -544,3 +2682,266 @@ func TestCheckDryrun(t *testing.T) {
 			n.State, StateHealthy)
 	}
 }
+
+// droppingEngine is a fake engine that counts the connections that it
+// accepts, and which can drop all connections in the middle of a call.
+type droppingEngine struct {
+	lock    sync.Mutex
+	conns   []net.Conn
+	accepts int
+	drops   int           // Number of calls for which connections are dropped.
+	block   chan bool     // Blocks HealthchecksSince calls until closed, if non-nil.
+	version uint64        // The version of the healthcheck configurations.
+	changed chan struct{} // Closed when the version changes.
+}
+
+func (e *droppingEngine) HealthState(args *HealthState, reply *int) error {
+	e.lock.Lock()
+	drop := e.drops > 0
+	if drop {
+		e.drops--
+		for _, conn := range e.conns {
+			conn.Close()
+		}
+		e.conns = nil
+	}
+	e.lock.Unlock()
+	if drop {
+		return errors.New("connection dropped")
+	}
+	*reply = len(args.Notifications)
+	return nil
+}
+
+func (e *droppingEngine) HealthchecksSince(args *ChecksRequest, reply *Checks) error {
+	e.lock.Lock()
+	block, version, changed := e.block, e.version, e.changed
+	e.lock.Unlock()
+	if block != nil {
+		<-block
+	}
+	if args.Version == version {
+		select {
+		case <-changed:
+		case <-time.After(args.Wait):
+		}
+	}
+	e.lock.Lock()
+	reply.Version = e.version
+	e.lock.Unlock()
+	reply.MaxConcurrent = 10
+	return nil
+}
+
+// setVersion changes the version of the healthcheck configurations.
+func (e *droppingEngine) setVersion(version uint64) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.version = version
+	close(e.changed)
+	e.changed = make(chan struct{})
+}
+
+// connections returns the number of connections accepted by the engine.
+func (e *droppingEngine) connections() int {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.accepts
+}
+
+// newDroppingEngine returns a droppingEngine that is serving on a temporary
+// socket, along with a healthcheck server that uses it.
+func newDroppingEngine(t *testing.T) (*droppingEngine, *Server) {
+	socket := filepath.Join(t.TempDir(), "engine")
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("Failed to listen on %v: %v", socket, err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	engine := &droppingEngine{changed: make(chan struct{})}
+	server := rpc.NewServer()
+	server.RegisterName("SeesawEngine", engine)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			engine.lock.Lock()
+			engine.accepts++
+			engine.conns = append(engine.conns, conn)
+			engine.lock.Unlock()
+			go server.ServeConn(conn)
+		}
+	}()
+	s := NewServer(&ServerConfig{EngineSocket: socket})
+	t.Cleanup(s.engine.close)
+	return engine, s
+}
+
+func TestEngineClientReuse(t *testing.T) {
+	engine, s := newDroppingEngine(t)
+	for i := 0; i < 5; i++ {
+		if err := s.sendBatch([]*Notification{{Id: 1}}); err != nil {
+			t.Fatalf("Failed to send batch: %v", err)
+		}
+		checks, err := s.getHealthchecks(0)
+		if err != nil {
+			t.Fatalf("Failed to get healthchecks: %v", err)
+		}
+		if checks.MaxConcurrent != 10 {
+			t.Errorf("Got max concurrent healthchecks %d, want 10", checks.MaxConcurrent)
+		}
+	}
+	if got := engine.connections(); got != 1 {
+		t.Errorf("Got %d engine connections, want 1", got)
+	}
+}
+
+func TestEngineClientReconnect(t *testing.T) {
+	engine, s := newDroppingEngine(t)
+	if _, err := s.getHealthchecks(0); err != nil {
+		t.Fatalf("Failed to get healthchecks: %v", err)
+	}
+	engine.lock.Lock()
+	engine.drops = 1
+	engine.lock.Unlock()
+	if err := s.sendBatch([]*Notification{{Id: 1}}); err == nil {
+		t.Fatal("Sent batch over dropped connection")
+	}
+	if err := s.sendBatch([]*Notification{{Id: 1}}); err != nil {
+		t.Fatalf("Failed to send batch after reconnecting: %v", err)
+	}
+	if got := engine.connections(); got != 2 {
+		t.Errorf("Got %d engine connections, want 2", got)
+	}
+}
+
+func TestEngineClientConcurrent(t *testing.T) {
+	engine, s := newDroppingEngine(t)
+	engine.lock.Lock()
+	engine.drops = 5
+	engine.lock.Unlock()
+
+	// Calls made concurrently with dropped connections fail, but must
+	// eventually succeed over a new connection.
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				var err error
+				for tries := 0; tries < 10; tries++ {
+					if i%2 == 0 {
+						err = s.sendBatch([]*Notification{{Id: Id(i)}})
+					} else {
+						_, err = s.getHealthchecks(0)
+					}
+					if err == nil {
+						break
+					}
+				}
+				if err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("Engine call failed: %v", err)
+	}
+	if got := engine.connections(); got < 2 || got > 6 {
+		t.Errorf("Got %d engine connections, want between 2 and 6", got)
+	}
+}
+
+func TestEngineClientTimeout(t *testing.T) {
+	engine, s := newDroppingEngine(t)
+	block := make(chan bool)
+	engine.lock.Lock()
+	engine.block = block
+	engine.lock.Unlock()
+	s.engine.timeout = 100 * time.Millisecond
+	if _, err := s.getHealthchecks(0); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Got error %v, want timeout", err)
+	}
+	close(block)
+	if _, err := s.getHealthchecks(0); err != nil {
+		t.Fatalf("Failed to get healthchecks after timeout: %v", err)
+	}
+	if got := engine.connections(); got != 2 {
+		t.Errorf("Got %d engine connections, want 2", got)
+	}
+}
+
+func TestEngineClientBackoff(t *testing.T) {
+	engine, s := newDroppingEngine(t)
+	c := s.engine
+	dial := c.dial
+	dials := 0
+	c.dial = func(socket string, timeout time.Duration) (net.Conn, error) {
+		dials++
+		return nil, errors.New("connection refused")
+	}
+
+	for failures := uint(1); failures <= 3; failures++ {
+		if err := s.sendBatch([]*Notification{{Id: 1}}); err == nil {
+			t.Fatal("Sent batch without a connection")
+		}
+		// No connection is attempted until the backoff has passed.
+		if err := s.sendBatch([]*Notification{{Id: 1}}); err == nil {
+			t.Fatal("Sent batch without a connection")
+		}
+		if dials != int(failures) {
+			t.Errorf("Got %d dials after %d failures, want %d", dials, failures, failures)
+		}
+		want := engineRetryMin << (failures - 1)
+		if wait := time.Until(c.retry); wait <= want/2 || wait > want {
+			t.Errorf("Got backoff %v after %d failures, want %v", wait, failures, want)
+		}
+		c.retry = time.Time{}
+	}
+
+	c.dial = dial
+	if err := s.sendBatch([]*Notification{{Id: 1}}); err != nil {
+		t.Fatalf("Failed to send batch after reconnecting: %v", err)
+	}
+	if c.failures != 0 || engine.connections() != 1 {
+		t.Errorf("Got %d failures and %d connections, want 0 and 1", c.failures, engine.connections())
+	}
+}
+
+func TestUpdaterWaitsForChanges(t *testing.T) {
+	engine, s := newDroppingEngine(t)
+	s.config.FetchInterval = time.Hour
+	engine.setVersion(1)
+	go s.updater()
+
+	receive := func(want uint64) {
+		select {
+		case checks := <-s.configs:
+			if checks.Version != want {
+				t.Errorf("Got healthchecks version %d, want %d", checks.Version, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for healthchecks version %d", want)
+		}
+	}
+	receive(1)
+
+	// A change must be received promptly, rather than after FetchInterval.
+	start := time.Now()
+	engine.setVersion(2)
+	receive(2)
+	if d := time.Since(start); d > 2*time.Second {
+		t.Errorf("Change took %v to be received", d)
+	}
+	if got := engine.connections(); got != 1 {
+		t.Errorf("Got %d engine connections, want 1", got)
+	}
+}
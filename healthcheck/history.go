@@ -0,0 +1,109 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"errors"
+
+	"github.com/google/seesaw/common/ipc"
+	"github.com/google/seesaw/common/seesaw"
+)
+
+// history retains the most recent results of a healthcheck in a fixed size
+// ring buffer.
+type history struct {
+	results []seesaw.HealthcheckResult
+	next    int
+	full    bool
+}
+
+// newHistory returns a history that retains up to size results.
+func newHistory(size int) *history {
+	return &history{results: make([]seesaw.HealthcheckResult, size)}
+}
+
+// add records a result, replacing the oldest result once the history is full.
+func (h *history) add(r seesaw.HealthcheckResult) {
+	if len(h.results) == 0 {
+		return
+	}
+	h.results[h.next] = r
+	h.next = (h.next + 1) % len(h.results)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// list returns copies of the recorded results, oldest first.
+func (h *history) list() []*seesaw.HealthcheckResult {
+	var results []*seesaw.HealthcheckResult
+	if h.full {
+		for _, r := range h.results[h.next:] {
+			results = append(results, &r)
+		}
+	}
+	for _, r := range h.results[:h.next] {
+		results = append(results, &r)
+	}
+	return results
+}
+
+// clear removes all recorded results.
+func (h *history) clear() {
+	for i := range h.results {
+		h.results[i] = seesaw.HealthcheckResult{}
+	}
+	h.next = 0
+	h.full = false
+}
+
+// HistoryRequest contains data for a healthcheck history IPC.
+type HistoryRequest struct {
+	Ctx *ipc.Context
+	Ids []Id
+}
+
+// History contains the most recent results of healthchecks, oldest first.
+type History struct {
+	Results map[Id][]*seesaw.HealthcheckResult
+}
+
+// SeesawHealthcheck provides the IPC interface to a healthcheck server.
+type SeesawHealthcheck struct {
+	server *Server
+}
+
+// CheckHistory returns the most recent results of the requested healthchecks.
+// Healthchecks that are not running are omitted from the reply.
+func (s *SeesawHealthcheck) CheckHistory(args *HistoryRequest, reply *History) error {
+	if args == nil {
+		return errors.New("args is nil")
+	}
+	if args.Ctx == nil || !args.Ctx.IsTrusted() {
+		return errors.New("insufficient access")
+	}
+	if reply == nil {
+		return errors.New("History is nil")
+	}
+	reply.Results = make(map[Id][]*seesaw.HealthcheckResult)
+	s.server.lock.RLock()
+	defer s.server.lock.RUnlock()
+	for _, id := range args.Ids {
+		if hc, ok := s.server.healthchecks[id]; ok {
+			reply.Results[id] = hc.Results()
+		}
+	}
+	return nil
+}
@@ -19,14 +19,25 @@
 package healthcheck
 
 import (
+	"bufio"
+	"bytes"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/seesaw/common/seesaw"
@@ -34,6 +45,26 @@ import (
 
 const (
 	defaultHTTPTimeout = 5 * time.Second
+
+	// defaultHTTPMaxRedirects is the default number of redirects that are
+	// followed when FollowRedirects is enabled.
+	defaultHTTPMaxRedirects = 5
+
+	// httpSessionCacheSize is the number of TLS sessions cached per checker
+	// when ResumeSessions is enabled.
+	httpSessionCacheSize = 4
+
+	// httpIdleConnTimeout is the time after which a connection held open by
+	// ReuseConnections is closed if it has not been used.
+	httpIdleConnTimeout = 60 * time.Second
+
+	// defaultHTTPMaxBody is the default number of bytes of the response
+	// body that are read when matching against a regular expression.
+	defaultHTTPMaxBody = 64 << 10
+
+	// httpSnippetLen is the number of bytes of an unexpected response body
+	// that are included in the healthcheck message.
+	httpSnippetLen = 128
 )
 
 // HTTPChecker contains configuration specific to a HTTP healthcheck.
@@ -42,10 +73,119 @@ type HTTPChecker struct {
 	Secure       bool
 	TLSVerify    bool
 	Method       string
-	Proxy        bool
+	Proxy        bool // The target is itself an HTTP proxy.
 	Request      string
 	Response     string
 	ResponseCode int
+	// ResponseCodes, if non-empty, lists the acceptable response codes and
+	// takes precedence over ResponseCode.
+	ResponseCodes []int
+
+	// Headers are added to the request. A Host header sets the request host.
+	Headers map[string]string
+
+	// RequestBody and ContentType are sent with POST and PUT requests.
+	RequestBody []byte
+	ContentType string
+
+	// ResponseRegexp, if set, must match the first MaxBodyBytes of the
+	// response body. It should be set via SetResponseRegexp so that an
+	// invalid expression is detected at configuration time.
+	ResponseRegexp string
+	MaxBodyBytes   int // Defaults to 64KB if zero.
+
+	// FollowRedirects enables following up to MaxRedirects redirects before
+	// the response is evaluated. Redirected requests are always sent to the
+	// healthcheck target, with only the path and Host header rewritten.
+	// Redirects to a different host are rejected unless CrossHostRedirects
+	// is set.
+	FollowRedirects    bool
+	MaxRedirects       int // Defaults to 5 if zero.
+	CrossHostRedirects bool
+
+	// CertFile and KeyFile specify a client certificate to present for
+	// secure healthchecks, while CACertFile specifies the CA certificates
+	// used to verify the server. The files are loaded once, either by
+	// LoadCertificates or on the first check, and then cached.
+	CertFile   string
+	KeyFile    string
+	CACertFile string
+
+	// ServerName, if set, is sent via SNI and used to verify the server's
+	// certificate, independently of the target address and Host header.
+	ServerName string
+
+	// ResumeSessions keeps a TLS session cache for the checker, so that
+	// secure checks resume a previous session instead of performing a full
+	// handshake. ReuseConnections holds a keep-alive connection open between
+	// checks; a check that fails on a reused connection is retried once on
+	// a new connection. ReuseConnections has no effect in DSR or TUN mode,
+	// or when ProxyURL is set. Both are disabled by default.
+	ResumeSessions   bool
+	ReuseConnections bool
+
+	connLock     sync.Mutex
+	sessionCache tls.ClientSessionCache
+	transport    *http.Transport
+
+	// ProxyURL, if set, is the URL of an HTTP proxy through which a CONNECT
+	// tunnel to the target is established before the request is sent.
+	// Credentials in the URL are used for basic proxy authentication.
+	ProxyURL string
+
+	certLock   sync.Mutex
+	certLoaded bool
+	clientCert *tls.Certificate
+	rootCAs    *x509.CertPool
+
+	// ExpectedHeaders lists response headers that must be present with the
+	// given values, while ForbiddenHeaders lists response headers that must
+	// be absent. Header names are case-insensitive and values are compared
+	// exactly, unless HeaderRegexps is set in which case each value is a
+	// regular expression that must match. Expected headers with regular
+	// expressions should be set via SetExpectedHeaders.
+	ExpectedHeaders  map[string]string
+	ForbiddenHeaders []string
+	HeaderRegexps    bool
+
+	reLock     sync.Mutex
+	responseRe *regexp.Regexp
+	headerRes  map[string]*regexp.Regexp
+
+	SocketOptions *SocketOptions // Applied once connected, if non-nil.
+}
+
+// ParseResponseCodes parses a comma separated list of HTTP response codes.
+// Each element may be a single code ("200"), an inclusive range ("301-302")
+// or a class of codes ("2xx").
+func ParseResponseCodes(s string) ([]int, error) {
+	var codes []int
+	for _, elem := range strings.Split(s, ",") {
+		elem = strings.TrimSpace(elem)
+		var lo, hi int
+		var err error
+		switch {
+		case len(elem) == 3 && strings.EqualFold(elem[1:], "xx"):
+			lo, err = strconv.Atoi(elem[:1])
+			lo *= 100
+			hi = lo + 99
+		case strings.Contains(elem, "-"):
+			l, h, _ := strings.Cut(elem, "-")
+			if lo, err = strconv.Atoi(strings.TrimSpace(l)); err == nil {
+				hi, err = strconv.Atoi(strings.TrimSpace(h))
+			}
+		default:
+			lo, err = strconv.Atoi(elem)
+			hi = lo
+		}
+		if err != nil || lo < 100 || hi > 599 || lo > hi {
+			return nil, fmt.Errorf("invalid HTTP response code %q", elem)
+		}
+		for c := lo; c <= hi; c++ {
+			codes = append(codes, c)
+		}
+	}
+	return codes, nil
 }
 
 // NewHTTPChecker returns an initialised HTTPChecker.
@@ -66,12 +206,410 @@ func NewHTTPChecker(ip net.IP, port int) *HTTPChecker {
 	}
 }
 
+// SetSocketOptions validates and sets the socket options for the healthcheck.
+func (hc *HTTPChecker) SetSocketOptions(o *SocketOptions) error {
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	hc.SocketOptions = o
+	return nil
+}
+
+// SetResponseRegexp compiles and sets the regular expression that the
+// response body must match.
+func (hc *HTTPChecker) SetResponseRegexp(expr string) error {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return fmt.Errorf("invalid response regexp %q: %v", expr, err)
+	}
+	hc.reLock.Lock()
+	defer hc.reLock.Unlock()
+	hc.ResponseRegexp = expr
+	hc.responseRe = re
+	return nil
+}
+
+// responseRegexp returns the compiled response regexp, if any. The
+// expression is compiled at most once, including after the checker has been
+// transferred to the healthcheck server.
+func (hc *HTTPChecker) responseRegexp() (*regexp.Regexp, error) {
+	hc.reLock.Lock()
+	defer hc.reLock.Unlock()
+	if hc.ResponseRegexp == "" {
+		return nil, nil
+	}
+	if hc.responseRe == nil || hc.responseRe.String() != hc.ResponseRegexp {
+		re, err := regexp.Compile(hc.ResponseRegexp)
+		if err != nil {
+			return nil, err
+		}
+		hc.responseRe = re
+	}
+	return hc.responseRe, nil
+}
+
+// LoadCertificates loads the client certificate and CA certificates for the
+// healthcheck, replacing any that were previously cached. Any connection held
+// open by the checker is closed, so that the new certificates are used.
+func (hc *HTTPChecker) LoadCertificates() error {
+	if err := hc.loadCertificates(); err != nil {
+		return err
+	}
+	hc.connLock.Lock()
+	defer hc.connLock.Unlock()
+	if hc.transport != nil {
+		hc.transport.CloseIdleConnections()
+		hc.transport = nil
+	}
+	return nil
+}
+
+// loadCertificates loads and caches the client certificate and CA
+// certificates for the healthcheck.
+func (hc *HTTPChecker) loadCertificates() error {
+	var cert *tls.Certificate
+	if hc.CertFile != "" || hc.KeyFile != "" {
+		c, err := tls.LoadX509KeyPair(hc.CertFile, hc.KeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		cert = &c
+	}
+	var pool *x509.CertPool
+	if hc.CACertFile != "" {
+		pem, err := os.ReadFile(hc.CACertFile)
+		if err != nil {
+			return fmt.Errorf("failed to load CA certificates: %v", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no CA certificates found in %s", hc.CACertFile)
+		}
+	}
+	hc.certLock.Lock()
+	defer hc.certLock.Unlock()
+	hc.clientCert = cert
+	hc.rootCAs = pool
+	hc.certLoaded = true
+	return nil
+}
+
+// certificates returns the cached client certificate and CA certificates,
+// loading them if necessary.
+func (hc *HTTPChecker) certificates() (*tls.Certificate, *x509.CertPool, error) {
+	hc.certLock.Lock()
+	loaded := hc.certLoaded
+	hc.certLock.Unlock()
+	if !loaded {
+		if err := hc.loadCertificates(); err != nil {
+			return nil, nil, err
+		}
+	}
+	hc.certLock.Lock()
+	defer hc.certLock.Unlock()
+	return hc.clientCert, hc.rootCAs, nil
+}
+
+// tlsFailure returns a description of the TLS handshake failure that caused
+// err, or an empty string if err is not a handshake failure.
+func (hc *HTTPChecker) tlsFailure(err error) string {
+	var verifyErr *tls.CertificateVerificationError
+	if errors.As(err, &verifyErr) {
+		return "server certificate verification failed"
+	}
+	var opErr *net.OpError
+	if hc.CertFile != "" && errors.As(err, &opErr) && opErr.Op == "remote error" {
+		return "client certificate rejected"
+	}
+	return ""
+}
+
+// connKey returns a string that identifies the settings that determine how a
+// connection is established to the target.
+func (hc *HTTPChecker) connKey() string {
+	var opts SocketOptions
+	if hc.SocketOptions != nil {
+		opts = *hc.SocketOptions
+	}
+	return fmt.Sprintf("%s %v %d %t %t %t %q %q %q %q %q %+v", hc.addr(), hc.Mode, hc.Mark,
+		hc.Proxy, hc.Secure, hc.TLSVerify, hc.ServerName, hc.CertFile, hc.KeyFile,
+		hc.CACertFile, hc.ProxyURL, opts)
+}
+
+// Inherit takes over the TLS session cache and held connection of a previous
+// checker, provided that the connection settings are unchanged. Otherwise
+// the held connection of the previous checker is closed.
+func (hc *HTTPChecker) Inherit(prev Checker) {
+	p, ok := prev.(*HTTPChecker)
+	if !ok || p == hc {
+		return
+	}
+	p.connLock.Lock()
+	defer p.connLock.Unlock()
+	hc.connLock.Lock()
+	defer hc.connLock.Unlock()
+
+	same := hc.connKey() == p.connKey()
+	if same && hc.ResumeSessions {
+		hc.sessionCache = p.sessionCache
+	}
+	if same && hc.ReuseConnections && hc.transport == nil {
+		hc.transport = p.transport
+	} else if p.transport != nil {
+		p.transport.CloseIdleConnections()
+	}
+	p.sessionCache = nil
+	p.transport = nil
+}
+
+// sessions returns the TLS session cache for the checker.
+func (hc *HTTPChecker) sessions() tls.ClientSessionCache {
+	hc.connLock.Lock()
+	defer hc.connLock.Unlock()
+	if hc.sessionCache == nil {
+		hc.sessionCache = tls.NewLRUClientSessionCache(httpSessionCacheSize)
+	}
+	return hc.sessionCache
+}
+
+// heldTransport returns the transport that is held between checks, making tr
+// the held transport if there is none.
+func (hc *HTTPChecker) heldTransport(tr *http.Transport) *http.Transport {
+	hc.connLock.Lock()
+	defer hc.connLock.Unlock()
+	if hc.transport == nil {
+		tr.IdleConnTimeout = httpIdleConnTimeout
+		hc.transport = tr
+	}
+	return hc.transport
+}
+
+// newRequest returns a new request for the healthcheck. A new body reader is
+// used for every request, so that the body is sent in full each time and
+// the content length is set.
+func (hc *HTTPChecker) newRequest(u *url.URL) (*http.Request, error) {
+	var body io.Reader
+	if hc.Method == http.MethodPost || hc.Method == http.MethodPut {
+		body = bytes.NewReader(hc.RequestBody)
+	}
+	req, err := http.NewRequest(hc.Method, hc.Request, body)
+	if err != nil {
+		return nil, err
+	}
+	req.URL = u
+	for name, value := range hc.Headers {
+		if strings.EqualFold(name, "Host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(name, value)
+	}
+	if body != nil && hc.ContentType != "" {
+		req.Header.Set("Content-Type", hc.ContentType)
+	}
+	return req, nil
+}
+
+// proxyError indicates a failure to establish a tunnel through an HTTP proxy.
+type proxyError struct {
+	proxy string
+	err   error
+}
+
+func (e *proxyError) Error() string {
+	return fmt.Sprintf("proxy %s failed: %v", e.proxy, e.err)
+}
+
+func (e *proxyError) Unwrap() error {
+	return e.err
+}
+
+// dialProxy establishes a tunnel to addr through the HTTP proxy at proxyURL,
+// using the CONNECT method. The tunnel must be established by deadline.
+func dialProxy(proxyURL *url.URL, addr string, deadline time.Time, opts *SocketOptions) (net.Conn, error) {
+	fail := func(conn net.Conn, err error) (net.Conn, error) {
+		if conn != nil {
+			conn.Close()
+		}
+		return nil, &proxyError{proxyURL.Host, err}
+	}
+	conn, err := dialTCP("tcp", proxyURL.Host, time.Until(deadline), 0)
+	if err != nil {
+		return fail(nil, err)
+	}
+	if err := setSocketOptions(conn, opts); err != nil {
+		return fail(conn, err)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return fail(conn, err)
+	}
+
+	req := &http.Request{
+		Method: "CONNECT",
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if user := proxyURL.User; user != nil {
+		password, _ := user.Password()
+		auth := base64.StdEncoding.EncodeToString([]byte(user.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+auth)
+	}
+	if err := req.Write(conn); err != nil {
+		return fail(conn, err)
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		return fail(conn, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fail(conn, fmt.Errorf("CONNECT to %s returned %s", addr, resp.Status))
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		return fail(conn, err)
+	}
+	return conn, nil
+}
+
+// redirect validates a redirected request and rewrites it so that it is sent
+// to the healthcheck target, which is the URL of the initial request.
+func (hc *HTTPChecker) redirect(target *url.URL, req *http.Request, via []*http.Request) error {
+	maxRedirects := hc.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = defaultHTTPMaxRedirects
+	}
+	if len(via) > maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	if host := req.URL.Host; host != target.Host {
+		if host != via[0].Host && !hc.CrossHostRedirects {
+			return fmt.Errorf("redirect to host %s not permitted", host)
+		}
+		req.Host = host
+	}
+	req.URL.Scheme = target.Scheme
+	req.URL.Host = target.Host
+	return nil
+}
+
+// SetExpectedHeaders sets the expected response headers, compiling the values
+// if they are regular expressions.
+func (hc *HTTPChecker) SetExpectedHeaders(headers map[string]string, regexps bool) error {
+	var res map[string]*regexp.Regexp
+	if regexps {
+		res = make(map[string]*regexp.Regexp)
+		for name, expr := range headers {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return fmt.Errorf("invalid regexp %q for header %s: %v", expr, name, err)
+			}
+			res[name] = re
+		}
+	}
+	hc.reLock.Lock()
+	defer hc.reLock.Unlock()
+	hc.ExpectedHeaders = headers
+	hc.HeaderRegexps = regexps
+	hc.headerRes = res
+	return nil
+}
+
+// headerRegexp returns the compiled regexp for an expected header value.
+func (hc *HTTPChecker) headerRegexp(name, expr string) (*regexp.Regexp, error) {
+	hc.reLock.Lock()
+	defer hc.reLock.Unlock()
+	if re := hc.headerRes[name]; re != nil && re.String() == expr {
+		return re, nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	if hc.headerRes == nil {
+		hc.headerRes = make(map[string]*regexp.Regexp)
+	}
+	hc.headerRes[name] = re
+	return re, nil
+}
+
+// checkHeaders verifies the response headers against the expected and
+// forbidden headers, returning a description of the first mismatch.
+func (hc *HTTPChecker) checkHeaders(header http.Header) string {
+	for _, name := range hc.ForbiddenHeaders {
+		if values := header.Values(name); len(values) > 0 {
+			return fmt.Sprintf("forbidden header %s present - %q", name, values[0])
+		}
+	}
+	names := make([]string, 0, len(hc.ExpectedHeaders))
+	for name := range hc.ExpectedHeaders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		want := hc.ExpectedHeaders[name]
+		values := header.Values(name)
+		if len(values) == 0 {
+			return fmt.Sprintf("missing header %s", name)
+		}
+		var re *regexp.Regexp
+		if hc.HeaderRegexps {
+			var err error
+			if re, err = hc.headerRegexp(name, want); err != nil {
+				return fmt.Sprintf("invalid regexp for header %s: %v", name, err)
+			}
+		}
+		matched := false
+		for _, v := range values {
+			if (re != nil && re.MatchString(v)) || (re == nil && v == want) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Sprintf("unexpected header %s - %q", name, values[0])
+		}
+	}
+	return ""
+}
+
+// hasBody reports whether a response with the given status code, to a request
+// with the given method, may carry a body.
+func hasBody(method string, code int) bool {
+	switch {
+	case method == http.MethodHead:
+		return false
+	case code >= 100 && code < 200:
+		return false
+	case code == http.StatusNoContent, code == http.StatusNotModified:
+		return false
+	}
+	return true
+}
+
+// snippet returns a quoted, truncated representation of a response body.
+func snippet(body []byte) string {
+	if len(body) > httpSnippetLen {
+		return fmt.Sprintf("%q...", body[:httpSnippetLen])
+	}
+	return fmt.Sprintf("%q", body)
+}
+
 // String returns the string representation of an HTTP healthcheck.
 func (hc *HTTPChecker) String() string {
 	attr := []string{fmt.Sprintf("code %d", hc.ResponseCode)}
+	if len(hc.ResponseCodes) > 0 {
+		attr[0] = fmt.Sprintf("codes %v", hc.ResponseCodes)
+	}
 	if hc.Proxy {
 		attr = append(attr, "proxy")
 	}
+	if hc.ResponseRegexp != "" {
+		attr = append(attr, fmt.Sprintf("body ~ %q", hc.ResponseRegexp))
+	}
+	if hc.FollowRedirects {
+		attr = append(attr, "follow redirects")
+	}
 	if hc.Secure {
 		attr = append(attr, "secure")
 		if hc.TLSVerify {
@@ -108,73 +646,230 @@ func (hc *HTTPChecker) Check(timeout time.Duration) *Result {
 		proxy = http.ProxyURL(u)
 	}
 
+	var proxyURL *url.URL
+	if hc.ProxyURL != "" {
+		if hc.Mode != seesaw.HCModePlain {
+			return complete(start, "", false, errors.New("proxy cannot be used with DSR or TUN mode"))
+		}
+		if proxyURL, err = url.Parse(hc.ProxyURL); err != nil {
+			return complete(start, "", false, err)
+		}
+		msg = fmt.Sprintf("%s via proxy %s", msg, proxyURL.Host)
+	}
+
 	var dialer func(network, addr string) (net.Conn, error)
+	var tunnelled atomic.Bool
 
-	// Both DSR and TUN mode requires socket marks
-	if hc.Mode != seesaw.HCModePlain {
+	if proxyURL != nil {
+		// The deadline covers both the proxy handshake and the request.
+		deadline := start.Add(timeout)
+		dialer = func(network string, addr string) (net.Conn, error) {
+			conn, err := dialProxy(proxyURL, addr, deadline, hc.SocketOptions)
+			if err == nil {
+				tunnelled.Store(true)
+			}
+			return conn, err
+		}
+	} else if hc.Mode != seesaw.HCModePlain {
+		// Both DSR and TUN mode requires socket marks
 		conn, err := dialTCP(hc.network(), hc.addr(), timeout, hc.Mark)
 		if err != nil {
 			return complete(start, "", false, err)
 		}
 		defer conn.Close()
+		if err := setSocketOptions(conn, hc.SocketOptions); err != nil {
+			return complete(start, "failed to set socket options", false, err)
+		}
 
 		dialer = func(net string, addr string) (net.Conn, error) {
 			return conn, nil
 		}
+	} else if hc.SocketOptions != nil {
+		dialer = func(network string, addr string) (net.Conn, error) {
+			conn, err := dialTCP(network, addr, timeout, 0)
+			if err != nil {
+				return nil, err
+			}
+			if err := setSocketOptions(conn, hc.SocketOptions); err != nil {
+				conn.Close()
+				return nil, err
+			}
+			return conn, nil
+		}
 	}
 	tlsConfig := &tls.Config{
 		InsecureSkipVerify: !hc.TLSVerify,
+		ServerName:         hc.ServerName,
+	}
+	if hc.Secure {
+		cert, pool, err := hc.certificates()
+		if err != nil {
+			return complete(start, "", false, err)
+		}
+		if cert != nil {
+			tlsConfig.Certificates = []tls.Certificate{*cert}
+		}
+		tlsConfig.RootCAs = pool
+	}
+	var redirects []string
+	checkRedirect := func(req *http.Request, via []*http.Request) error {
+		return errors.New("redirect not permitted")
+	}
+	if hc.FollowRedirects {
+		checkRedirect = func(req *http.Request, via []*http.Request) error {
+			loc := req.URL.String()
+			if err := hc.redirect(u, req, via); err != nil {
+				return err
+			}
+			redirects = append(redirects, loc)
+			return nil
+		}
+	}
+	// Unless connections are reused, each check uses a new transport and
+	// closes its idle connections rather than leaving them for the server to
+	// time out.
+	reuse := hc.ReuseConnections && hc.Mode == seesaw.HCModePlain && proxyURL == nil
+	if hc.Secure && hc.ResumeSessions {
+		tlsConfig.ClientSessionCache = hc.sessions()
+	}
+	transport := &http.Transport{
+		Dial:            dialer,
+		Proxy:           proxy,
+		TLSClientConfig: tlsConfig,
+	}
+	if reuse {
+		transport = hc.heldTransport(transport)
+	} else {
+		defer transport.CloseIdleConnections()
 	}
 	client := &http.Client{
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			return errors.New("redirect not permitted")
-		},
-		Transport: &http.Transport{
-			Dial:            dialer,
-			Proxy:           proxy,
-			TLSClientConfig: tlsConfig,
-		},
-		Timeout: timeout,
+		CheckRedirect: checkRedirect,
+		Transport:     transport,
+		Timeout:       timeout,
+	}
+	req, err := hc.newRequest(u)
+	if err != nil {
+		return complete(start, "", false, err)
+	}
+	var reused bool
+	if reuse {
+		trace := &httptrace.ClientTrace{
+			GotConn: func(info httptrace.GotConnInfo) {
+				reused = info.Reused
+			},
+		}
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
 	}
-	req, err := http.NewRequest(hc.Method, hc.Request, nil)
-	req.URL = u
 
 	// If we received a response we want to process it, even in the
 	// presence of an error - a redirect 3xx will result in both the
 	// response and an error being returned.
 	resp, err := client.Do(req)
+	if resp == nil && reused {
+		// The held connection may have been closed by the server, so retry
+		// once on a new connection.
+		transport.CloseIdleConnections()
+		redirects = nil
+		client.Timeout = time.Until(start.Add(timeout))
+		if req, err = hc.newRequest(u); err != nil {
+			return complete(start, "", false, err)
+		}
+		resp, err = client.Do(req)
+	}
 	if resp == nil {
+		var pErr *proxyError
+		if failure := hc.tlsFailure(err); failure != "" {
+			err = fmt.Errorf("%s: %v", failure, err)
+		}
+		switch {
+		case errors.As(err, &pErr):
+			err = pErr
+		case proxyURL != nil && !tunnelled.Load():
+			err = &proxyError{proxyURL.Host, err}
+		case proxyURL != nil:
+			err = fmt.Errorf("backend %s failed via proxy %s: %v", hc.addr(), proxyURL.Host, err)
+		}
 		return complete(start, "", false, err)
 	}
 	if resp.Body != nil {
-		defer resp.Body.Close()
+		defer func() {
+			// A held connection is only reused once its body is drained.
+			if reuse && hasBody(req.Method, resp.StatusCode) {
+				io.Copy(io.Discard, io.LimitReader(resp.Body, defaultHTTPMaxBody))
+			}
+			resp.Body.Close()
+		}()
+	}
+	if len(redirects) > 0 {
+		msg = fmt.Sprintf("%s; redirected via %s", msg, strings.Join(redirects, " -> "))
+	}
+	if hc.FollowRedirects && err != nil {
+		msg = fmt.Sprintf("%s; %v", msg, err)
 	}
 	err = nil
 
 	// Check response code.
 	var codeOk bool
-	if hc.ResponseCode == 0 {
+	if len(hc.ResponseCodes) > 0 {
+		for _, code := range hc.ResponseCodes {
+			if resp.StatusCode == code {
+				codeOk = true
+				break
+			}
+		}
+	} else if hc.ResponseCode == 0 {
 		codeOk = true
 	} else if resp.StatusCode == hc.ResponseCode {
 		codeOk = true
 	}
 
 	// Check response body.
-	var bodyOk bool
+	bodyOk := true
 	msg = fmt.Sprintf("%s; got %s", msg, resp.Status)
-	if hc.Response == "" {
-		bodyOk = true
-	} else if resp.Body != nil {
-		buf := make([]byte, len(hc.Response))
-		n, err := io.ReadFull(resp.Body, buf)
-		if err != nil && err != io.ErrUnexpectedEOF {
-			msg = fmt.Sprintf("%s; failed to read HTTP response", msg)
-		} else if string(buf) != hc.Response {
-			msg = fmt.Sprintf("%s; unexpected response - %q", msg, string(buf[0:n]))
+	if hc.Response != "" || hc.ResponseRegexp != "" {
+		bodyOk = false
+		re, reErr := hc.responseRegexp()
+		limit := len(hc.Response)
+		if re != nil {
+			maxBody := hc.MaxBodyBytes
+			if maxBody <= 0 {
+				maxBody = defaultHTTPMaxBody
+			}
+			if maxBody > limit {
+				limit = maxBody
+			}
+		}
+		if reErr != nil {
+			msg = fmt.Sprintf("%s; invalid response regexp: %v", msg, reErr)
 		} else {
-			bodyOk = true
+			// Responses that cannot carry a body are not read, since a
+			// server may hold the connection open after the headers.
+			var body []byte
+			var err error
+			if resp.Body != nil && hasBody(req.Method, resp.StatusCode) {
+				body, err = io.ReadAll(io.LimitReader(resp.Body, int64(limit)))
+			}
+			switch {
+			case err != nil:
+				msg = fmt.Sprintf("%s; failed to read HTTP response", msg)
+			case !strings.HasPrefix(string(body), hc.Response):
+				msg = fmt.Sprintf("%s; unexpected response - %s", msg, snippet(body))
+			case re != nil && !re.Match(body):
+				msg = fmt.Sprintf("%s; response does not match %q - %s", msg, hc.ResponseRegexp, snippet(body))
+			default:
+				bodyOk = true
+			}
+		}
+	}
+
+	// Check response headers.
+	headersOk := true
+	if codeOk {
+		if mismatch := hc.checkHeaders(resp.Header); mismatch != "" {
+			msg = fmt.Sprintf("%s; %s", msg, mismatch)
+			headersOk = false
 		}
 	}
 
-	return complete(start, msg, codeOk && bodyOk, err)
+	return complete(start, msg, codeOk && bodyOk && headersOk, err)
 }
@@ -0,0 +1,202 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// IMAP healthcheck implementation.
+
+package healthcheck
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/seesaw/common/seesaw"
+)
+
+const (
+	defaultIMAPTimeout = 10 * time.Second
+
+	// imapMaxResponseSize bounds the amount of data read from the server
+	// on each connection.
+	imapMaxResponseSize = 64 * 1024
+)
+
+// IMAPChecker contains configuration specific to an IMAP healthcheck.
+//
+// The healthcheck expects an "* OK" greeting. If User is set it then logs in
+// with LOGIN, and if Mailbox is set it selects the mailbox, before logging
+// out. If Secure is set the connection uses implicit TLS, otherwise if
+// StartTLS is set it is upgraded with STARTTLS after the greeting.
+type IMAPChecker struct {
+	Target
+	User       string
+	Password   string
+	Mailbox    string // A mailbox to SELECT, e.g. "INBOX".
+	Secure     bool
+	StartTLS   bool
+	TLSVerify  bool
+	ServerName string // TLS ServerName override. If empty, derived from target address.
+}
+
+// NewIMAPChecker returns an initialised IMAPChecker.
+func NewIMAPChecker(ip net.IP, port int) *IMAPChecker {
+	return &IMAPChecker{
+		Target: Target{
+			IP:    ip,
+			Port:  port,
+			Proto: seesaw.IPProtoTCP,
+		},
+	}
+}
+
+func (hc *IMAPChecker) proto() string {
+	if hc.Secure {
+		return "IMAPS"
+	}
+	return "IMAP"
+}
+
+// String returns the string representation of an IMAP healthcheck.
+func (hc *IMAPChecker) String() string {
+	var attr []string
+	if hc.User != "" {
+		attr = append(attr, "user "+hc.User)
+	}
+	if hc.Mailbox != "" {
+		attr = append(attr, "select "+hc.Mailbox)
+	}
+	if hc.StartTLS && !hc.Secure {
+		attr = append(attr, "starttls")
+	}
+	if (hc.Secure || hc.StartTLS) && hc.TLSVerify {
+		attr = append(attr, "verify")
+	}
+	return fmt.Sprintf("%s [%s] %s", hc.proto(), strings.Join(attr, "; "), hc.Target)
+}
+
+// imapQuote returns s as an IMAP quoted string.
+func imapQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// imapCommand sends a tagged command and reads responses until the tagged
+// response, which must be OK. Untagged responses are skipped.
+func imapCommand(c *lineConn, tag, cmd string) error {
+	if err := c.writeLine(tag + " " + cmd); err != nil {
+		return err
+	}
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(line, "* ") {
+			continue
+		}
+		if !strings.HasPrefix(line, tag+" OK") {
+			return c.mismatch(line)
+		}
+		return nil
+	}
+}
+
+// Check executes an IMAP healthcheck.
+func (hc *IMAPChecker) Check(timeout time.Duration) *Result {
+	msg := fmt.Sprintf("%s connect to %s", hc.proto(), hc.addr())
+	start := time.Now()
+	if timeout == time.Duration(0) {
+		timeout = defaultIMAPTimeout
+	}
+	deadline := start.Add(timeout)
+
+	conn, err := dialTCP(hc.network(), hc.addr(), timeout, hc.Mark)
+	if err != nil {
+		msg = fmt.Sprintf("%s; failed to connect", msg)
+		return complete(start, msg, false, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(deadline); err != nil {
+		msg = fmt.Sprintf("%s; failed to set deadline", msg)
+		return complete(start, msg, false, err)
+	}
+
+	c := newLineConn(conn, deadline, imapMaxResponseSize)
+	failed := func(step string, err error) *Result {
+		msg = fmt.Sprintf("%s; %s failed", msg, step)
+		if c.unexpected != "" {
+			msg = fmt.Sprintf("%s (unexpected response %q)", msg, c.unexpected)
+		}
+		return complete(start, msg, false, err)
+	}
+
+	if hc.Secure {
+		tlsConn, err := clientTLS(conn, hc.IP, hc.ServerName, hc.TLSVerify)
+		if err != nil {
+			return failed("TLS handshake", err)
+		}
+		defer tlsConn.Close()
+		c = newLineConn(tlsConn, deadline, imapMaxResponseSize)
+	}
+
+	// A PREAUTH greeting means the connection is already authenticated.
+	greeting, err := c.readLine()
+	if err != nil {
+		return failed("greeting", err)
+	}
+	preauth := strings.HasPrefix(greeting, "* PREAUTH")
+	if !preauth && !strings.HasPrefix(greeting, "* OK") {
+		return failed("greeting", c.mismatch(greeting))
+	}
+
+	n := 0
+	tag := func() string {
+		n++
+		return fmt.Sprintf("a%d", n)
+	}
+
+	if hc.StartTLS && !hc.Secure {
+		if err := imapCommand(c, tag(), "STARTTLS"); err != nil {
+			return failed("STARTTLS", err)
+		}
+		tlsConn, err := clientTLS(conn, hc.IP, hc.ServerName, hc.TLSVerify)
+		if err != nil {
+			return failed("TLS handshake", err)
+		}
+		defer tlsConn.Close()
+		c = newLineConn(tlsConn, deadline, imapMaxResponseSize)
+	}
+
+	status := "greeting received"
+	if hc.User != "" && !preauth {
+		cmd := fmt.Sprintf("LOGIN %s %s", imapQuote(hc.User), imapQuote(hc.Password))
+		if err := imapCommand(c, tag(), cmd); err != nil {
+			return failed("LOGIN", err)
+		}
+		status = "logged in as " + hc.User
+	}
+	if hc.Mailbox != "" {
+		if err := imapCommand(c, tag(), "SELECT "+imapQuote(hc.Mailbox)); err != nil {
+			return failed("SELECT", err)
+		}
+		status = fmt.Sprintf("%s, selected %s", status, hc.Mailbox)
+	}
+	msg = fmt.Sprintf("%s; %s", msg, status)
+
+	// The BYE and tagged LOGOUT responses are not checked.
+	imapCommand(c, tag(), "LOGOUT")
+	return complete(start, msg, true, nil)
+}
@@ -0,0 +1,257 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// imapStub is a scripted IMAP server. Tagged replies are format strings
+// taking the command tag, and may be preceded by untagged responses.
+type imapStub struct {
+	greeting  string
+	login     string
+	sel       string
+	implicit  bool // Use implicit TLS.
+	tlsConfig *tls.Config
+
+	mu       sync.Mutex
+	commands []string
+}
+
+func (is *imapStub) handle(conn net.Conn) {
+	defer func() { conn.Close() }()
+	if is.implicit {
+		tlsConn := tls.Server(conn, is.tlsConfig)
+		if err := tlsConn.Handshake(); err != nil {
+			return
+		}
+		conn = tlsConn
+	}
+	io.WriteString(conn, is.greeting)
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimRight(line, "\r\n")
+		is.mu.Lock()
+		is.commands = append(is.commands, cmd)
+		is.mu.Unlock()
+
+		fields := strings.Fields(cmd + " x")
+		tag, verb := fields[0], strings.ToUpper(fields[1])
+		switch verb {
+		case "STARTTLS":
+			fmt.Fprintf(conn, "%s OK Begin TLS negotiation now\r\n", tag)
+			tlsConn := tls.Server(conn, is.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			r = bufio.NewReader(conn)
+		case "LOGIN":
+			fmt.Fprintf(conn, is.login, tag)
+		case "SELECT":
+			fmt.Fprintf(conn, is.sel, tag)
+		case "LOGOUT":
+			fmt.Fprintf(conn, "* BYE Logging out\r\n%s OK LOGOUT completed\r\n", tag)
+			return
+		default:
+			fmt.Fprintf(conn, "%s BAD Unknown command\r\n", tag)
+		}
+	}
+}
+
+const (
+	imapGreeting = "* OK [CAPABILITY IMAP4rev1 STARTTLS] Dovecot ready.\r\n"
+	imapLogin    = "* CAPABILITY IMAP4rev1 IDLE\r\n%s OK Logged in\r\n"
+	imapSelect   = "* FLAGS (\\Answered \\Seen)\r\n* 3 EXISTS\r\n* 0 RECENT\r\n%s OK [READ-WRITE] Select completed\r\n"
+)
+
+var imapCheckerTests = []struct {
+	desc     string
+	stub     *imapStub
+	user     string
+	mailbox  string
+	secure   bool
+	starttls bool
+	success  bool
+	message  string
+}{
+	{
+		"greeting",
+		&imapStub{greeting: imapGreeting},
+		"", "", false, false,
+		true, "IMAP connect to 127.0.0.1:",
+	},
+	{
+		"login and select",
+		&imapStub{greeting: imapGreeting, login: imapLogin, sel: imapSelect},
+		"mailcheck", "INBOX", false, false,
+		true, "logged in as mailcheck, selected INBOX",
+	},
+	{
+		"login failed",
+		&imapStub{greeting: imapGreeting, login: "%s NO [AUTHENTICATIONFAILED] Authentication failed.\r\n"},
+		"mailcheck", "", false, false,
+		false, `LOGIN failed (unexpected response "a1 NO [AUTHENTICATIONFAILED] Authentication failed.")`,
+	},
+	{
+		"select failed",
+		&imapStub{greeting: imapGreeting, login: imapLogin, sel: "%s NO Mailbox doesn't exist\r\n"},
+		"mailcheck", "INBOX", false, false,
+		false, `SELECT failed (unexpected response "a2 NO Mailbox doesn't exist")`,
+	},
+	{
+		"preauth",
+		&imapStub{greeting: "* PREAUTH IMAP4rev1 server logged in as mailcheck\r\n", sel: imapSelect},
+		"mailcheck", "INBOX", false, false,
+		true, "greeting received, selected INBOX",
+	},
+	{
+		"bye greeting",
+		&imapStub{greeting: "* BYE Too many connections\r\n"},
+		"", "", false, false,
+		false, `greeting failed (unexpected response "* BYE Too many connections")`,
+	},
+	{
+		"starttls",
+		&imapStub{greeting: imapGreeting, login: imapLogin, sel: imapSelect},
+		"mailcheck", "INBOX", false, true,
+		true, "logged in as mailcheck, selected INBOX",
+	},
+	{
+		"implicit tls",
+		&imapStub{greeting: imapGreeting, login: imapLogin, implicit: true},
+		"mailcheck", "", true, false,
+		true, "IMAPS connect",
+	},
+	{
+		"not imap",
+		&imapStub{greeting: "+OK POP3 server ready\r\n"},
+		"", "", false, false,
+		false, `greeting failed (unexpected response "+OK POP3 server ready")`,
+	},
+}
+
+func TestIMAPChecker(t *testing.T) {
+	cert, _ := newServerCert(t, "imap.example.com")
+	for _, test := range imapCheckerTests {
+		l, addr, err := newLocalTCPListener("tcp4")
+		if err != nil {
+			t.Fatalf("Failed to create local TCP listener: %v", err)
+		}
+		is := test.stub
+		is.tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+		go serveConns(l, is.handle)
+
+		hc := NewIMAPChecker(addr.IP, addr.Port)
+		hc.User = test.user
+		hc.Password = "secret"
+		hc.Mailbox = test.mailbox
+		hc.Secure = test.secure
+		hc.StartTLS = test.starttls
+		result := hc.Check(time.Second)
+		l.Close()
+		if result.Success != test.success {
+			t.Errorf("%s: got success %v, want %v: %v", test.desc, result.Success, test.success, result)
+		}
+		if !strings.Contains(result.Message, test.message) {
+			t.Errorf("%s: got message %q, want it to contain %q", test.desc, result.Message, test.message)
+		}
+		if test.success {
+			// The stub records LOGOUT before replying to it.
+			is.mu.Lock()
+			commands := is.commands
+			is.mu.Unlock()
+			if len(commands) == 0 || !strings.HasSuffix(commands[len(commands)-1], " LOGOUT") {
+				t.Errorf("%s: got commands %q, want LOGOUT last", test.desc, commands)
+			}
+			if test.starttls && commands[0] != "a1 STARTTLS" {
+				t.Errorf("%s: got commands %q, want STARTTLS first", test.desc, commands)
+			}
+		}
+	}
+}
+
+func TestIMAPCheckerQuoting(t *testing.T) {
+	l, addr, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to create local TCP listener: %v", err)
+	}
+	defer l.Close()
+	is := &imapStub{greeting: imapGreeting, login: imapLogin}
+	go serveConns(l, is.handle)
+
+	hc := NewIMAPChecker(addr.IP, addr.Port)
+	hc.User = "mail check"
+	hc.Password = `pa"ss\word`
+	result := hc.Check(time.Second)
+	if !result.Success {
+		t.Fatalf("IMAP healthcheck failed: %v", result)
+	}
+	is.mu.Lock()
+	defer is.mu.Unlock()
+	if want := `a1 LOGIN "mail check" "pa\"ss\\word"`; len(is.commands) == 0 || is.commands[0] != want {
+		t.Errorf("Got commands %q, want %q first", is.commands, want)
+	}
+	if strings.Contains(result.Message, "word") {
+		t.Errorf("Message %q contains the password", result.Message)
+	}
+}
+
+func TestIMAPCheckerGreetingTimeout(t *testing.T) {
+	l, addr, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to create local TCP listener: %v", err)
+	}
+	defer l.Close()
+	// Accept connections but never send the greeting.
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				io.Copy(io.Discard, conn)
+				conn.Close()
+			}()
+		}
+	}()
+
+	hc := NewIMAPChecker(addr.IP, addr.Port)
+	start := time.Now()
+	result := hc.Check(200 * time.Millisecond)
+	if result.Success {
+		t.Fatalf("IMAP healthcheck succeeded without a greeting: %v", result)
+	}
+	if !strings.Contains(result.Message, "greeting failed") {
+		t.Errorf("got message %q, want it to contain %q", result.Message, "greeting failed")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("IMAP healthcheck took %v, want it bounded by the timeout", elapsed)
+	}
+}
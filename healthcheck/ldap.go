@@ -0,0 +1,520 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// LDAP healthcheck implementation.
+
+package healthcheck
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/seesaw/common/seesaw"
+)
+
+const (
+	defaultLDAPTimeout = 10 * time.Second
+
+	// ldapMaxMessageSize bounds the size of a single message read from the
+	// server.
+	ldapMaxMessageSize = 64 * 1024
+
+	ldapVersion     = 3
+	ldapStartTLSOID = "1.3.6.1.4.1.1466.20037"
+)
+
+// BER universal tags.
+const (
+	berTagBoolean    = 0x01
+	berTagInteger    = 0x02
+	berTagOctetStr   = 0x04
+	berTagEnumerated = 0x0a
+	berTagSequence   = 0x30
+)
+
+// LDAP protocol operation and context-specific tags (RFC 4511).
+const (
+	ldapTagUnbindRequest    = 0x42
+	ldapTagBindRequest      = 0x60
+	ldapTagBindResponse     = 0x61
+	ldapTagSearchRequest    = 0x63
+	ldapTagSearchEntry      = 0x64
+	ldapTagSearchDone       = 0x65
+	ldapTagSearchReference  = 0x73
+	ldapTagExtendedRequest  = 0x77
+	ldapTagExtendedResponse = 0x78
+	ldapTagSimpleAuth       = 0x80
+	ldapTagExtendedName     = 0x80
+	ldapTagFilterPresent    = 0x87
+)
+
+const (
+	ldapResultSuccess     = 0
+	ldapResultSizeLimit   = 4
+	ldapScopeBaseObject   = 0
+	ldapDerefAliasesNever = 0
+
+	// ldapNoticeOfDisconnectID is the message ID of an unsolicited notice
+	// of disconnection.
+	ldapNoticeOfDisconnectID = 0
+)
+
+// ldapResultCodes maps common LDAP result codes to their names.
+var ldapResultCodes = map[int]string{
+	0:  "success",
+	1:  "operationsError",
+	2:  "protocolError",
+	3:  "timeLimitExceeded",
+	4:  "sizeLimitExceeded",
+	7:  "authMethodNotSupported",
+	8:  "strongerAuthRequired",
+	11: "adminLimitExceeded",
+	13: "confidentialityRequired",
+	32: "noSuchObject",
+	34: "invalidDNSyntax",
+	48: "inappropriateAuthentication",
+	49: "invalidCredentials",
+	50: "insufficientAccessRights",
+	51: "busy",
+	52: "unavailable",
+	53: "unwillingToPerform",
+	80: "other",
+}
+
+// LDAPChecker contains configuration specific to an LDAP healthcheck.
+//
+// The healthcheck performs an anonymous bind, or a simple bind if BindDN is
+// set, over plaintext, LDAPS (Secure) or StartTLS. If SearchDN is set, a
+// base scope search of that entry must return it. The connection is then
+// unbound.
+type LDAPChecker struct {
+	Target
+	BindDN     string
+	Password   string
+	SearchDN   string
+	Secure     bool // Use LDAPS.
+	StartTLS   bool
+	TLSVerify  bool
+	ServerName string // TLS ServerName override. If empty, derived from target address.
+}
+
+// NewLDAPChecker returns an initialised LDAPChecker.
+func NewLDAPChecker(ip net.IP, port int) *LDAPChecker {
+	return &LDAPChecker{
+		Target: Target{
+			IP:    ip,
+			Port:  port,
+			Proto: seesaw.IPProtoTCP,
+		},
+	}
+}
+
+// String returns the string representation of an LDAP healthcheck.
+func (hc *LDAPChecker) String() string {
+	attr := []string{}
+	if hc.BindDN != "" {
+		attr = append(attr, "bind "+hc.BindDN)
+	}
+	if hc.SearchDN != "" {
+		attr = append(attr, "search "+hc.SearchDN)
+	}
+	if hc.StartTLS {
+		attr = append(attr, "starttls")
+	}
+	if (hc.Secure || hc.StartTLS) && hc.TLSVerify {
+		attr = append(attr, "verify")
+	}
+	var s string
+	if len(attr) > 0 {
+		s = fmt.Sprintf(" [%s]", strings.Join(attr, "; "))
+	}
+	proto := "LDAP"
+	if hc.Secure {
+		proto = "LDAPS"
+	}
+	return fmt.Sprintf("%s%s %s", proto, s, hc.Target)
+}
+
+// berElement is a decoded BER element. For constructed elements, data
+// holds the encoded child elements.
+type berElement struct {
+	tag  byte
+	data []byte
+}
+
+// berLength encodes a BER definite length.
+func berLength(n int) []byte {
+	if n < 0x80 {
+		return []byte{byte(n)}
+	}
+	var b []byte
+	for ; n > 0; n >>= 8 {
+		b = append([]byte{byte(n)}, b...)
+	}
+	return append([]byte{0x80 | byte(len(b))}, b...)
+}
+
+// berEncode encodes an element with the given tag and contents.
+func berEncode(tag byte, contents ...[]byte) []byte {
+	var data []byte
+	for _, c := range contents {
+		data = append(data, c...)
+	}
+	b := append([]byte{tag}, berLength(len(data))...)
+	return append(b, data...)
+}
+
+// berInt encodes a non-negative integer with the given tag.
+func berInt(tag byte, v int) []byte {
+	b := []byte{byte(v)}
+	for v >>= 8; v > 0; v >>= 8 {
+		b = append([]byte{byte(v)}, b...)
+	}
+	if b[0]&0x80 != 0 {
+		b = append([]byte{0}, b...)
+	}
+	return berEncode(tag, b)
+}
+
+// berString encodes a string with the given tag.
+func berString(tag byte, s string) []byte {
+	return berEncode(tag, []byte(s))
+}
+
+// int decodes the element as an integer.
+func (e berElement) int() (int, error) {
+	if len(e.data) == 0 || len(e.data) > 4 {
+		return 0, fmt.Errorf("invalid BER integer length %d", len(e.data))
+	}
+	v := int(int8(e.data[0]))
+	for _, b := range e.data[1:] {
+		v = v<<8 | int(b)
+	}
+	return v, nil
+}
+
+// children decodes the child elements of a constructed element.
+func (e berElement) children() ([]berElement, error) {
+	var elements []berElement
+	b := e.data
+	for len(b) > 0 {
+		if len(b) < 2 {
+			return nil, errors.New("truncated BER element")
+		}
+		tag := b[0]
+		n, hdr, err := berDecodeLength(b[1:])
+		if err != nil {
+			return nil, err
+		}
+		b = b[1+hdr:]
+		if n > len(b) {
+			return nil, errors.New("truncated BER element")
+		}
+		elements = append(elements, berElement{tag: tag, data: b[:n]})
+		b = b[n:]
+	}
+	return elements, nil
+}
+
+// berDecodeLength decodes a BER definite length, returning the length and
+// the number of bytes it was encoded in.
+func berDecodeLength(b []byte) (int, int, error) {
+	if b[0] < 0x80 {
+		return int(b[0]), 1, nil
+	}
+	n := int(b[0] & 0x7f)
+	if n == 0 || n > 3 {
+		return 0, 0, fmt.Errorf("unsupported BER length encoding 0x%02x", b[0])
+	}
+	if len(b) < 1+n {
+		return 0, 0, errors.New("truncated BER length")
+	}
+	var length int
+	for _, c := range b[1 : 1+n] {
+		length = length<<8 | int(c)
+	}
+	return length, 1 + n, nil
+}
+
+// readBER reads a single BER element.
+func readBER(r *bufio.Reader) (berElement, error) {
+	hdr := make([]byte, 2, 5)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return berElement{}, err
+	}
+	if hdr[1]&0x80 != 0 {
+		n := int(hdr[1] & 0x7f)
+		if n == 0 || n > 3 {
+			return berElement{}, fmt.Errorf("unsupported BER length encoding 0x%02x", hdr[1])
+		}
+		hdr = hdr[:2+n]
+		if _, err := io.ReadFull(r, hdr[2:]); err != nil {
+			return berElement{}, err
+		}
+	}
+	length, _, err := berDecodeLength(hdr[1:])
+	if err != nil {
+		return berElement{}, err
+	}
+	if length > ldapMaxMessageSize {
+		return berElement{}, fmt.Errorf("LDAP message of %d bytes is too large", length)
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return berElement{}, err
+	}
+	return berElement{tag: hdr[0], data: data}, nil
+}
+
+// ldapResultError is a non-success LDAPResult returned by the server.
+type ldapResultError struct {
+	code       int
+	diagnostic string
+}
+
+func (e *ldapResultError) Error() string {
+	name, ok := ldapResultCodes[e.code]
+	if !ok {
+		name = "unknown"
+	}
+	s := fmt.Sprintf("resultCode %d (%s)", e.code, name)
+	if e.diagnostic != "" {
+		s = fmt.Sprintf("%s: %s", s, e.diagnostic)
+	}
+	return s
+}
+
+// ldapResult decodes the LDAPResult in a response, returning an
+// ldapResultError if the result code is not success.
+func ldapResult(op berElement) error {
+	fields, err := op.children()
+	if err != nil {
+		return err
+	}
+	if len(fields) < 3 || fields[0].tag != berTagEnumerated {
+		return errors.New("malformed LDAPResult")
+	}
+	code, err := fields[0].int()
+	if err != nil {
+		return err
+	}
+	if code != ldapResultSuccess {
+		return &ldapResultError{code: code, diagnostic: string(fields[2].data)}
+	}
+	return nil
+}
+
+// ldapConn sends requests to and reads responses from an LDAP server.
+type ldapConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	id   int
+}
+
+func newLDAPConn(conn net.Conn, id int) *ldapConn {
+	return &ldapConn{conn: conn, r: bufio.NewReader(conn), id: id}
+}
+
+// send sends a request with a new message ID.
+func (c *ldapConn) send(op []byte) error {
+	c.id++
+	return writeFull(c.conn, berEncode(berTagSequence, berInt(berTagInteger, c.id), op))
+}
+
+// read reads the protocol operation of the next response to the last
+// request.
+func (c *ldapConn) read() (berElement, error) {
+	msg, err := readBER(c.r)
+	if err != nil {
+		return berElement{}, err
+	}
+	if msg.tag != berTagSequence {
+		return berElement{}, fmt.Errorf("unexpected LDAP message tag 0x%02x", msg.tag)
+	}
+	fields, err := msg.children()
+	if err != nil {
+		return berElement{}, err
+	}
+	if len(fields) < 2 || fields[0].tag != berTagInteger {
+		return berElement{}, errors.New("malformed LDAP message")
+	}
+	id, err := fields[0].int()
+	if err != nil {
+		return berElement{}, err
+	}
+	if id == ldapNoticeOfDisconnectID {
+		err := errors.New("server sent notice of disconnection")
+		if rerr := ldapResult(fields[1]); rerr != nil {
+			err = fmt.Errorf("%v: %v", err, rerr)
+		}
+		return berElement{}, err
+	}
+	if id != c.id {
+		return berElement{}, fmt.Errorf("unexpected LDAP message ID %d, want %d", id, c.id)
+	}
+	return fields[1], nil
+}
+
+// request sends a request and reads a single response with the given tag.
+func (c *ldapConn) request(op []byte, tag byte) (berElement, error) {
+	if err := c.send(op); err != nil {
+		return berElement{}, err
+	}
+	resp, err := c.read()
+	if err != nil {
+		return berElement{}, err
+	}
+	if resp.tag != tag {
+		return berElement{}, fmt.Errorf("unexpected LDAP response tag 0x%02x, want 0x%02x", resp.tag, tag)
+	}
+	return resp, nil
+}
+
+// search performs a base scope search of dn and returns the number of
+// entries returned.
+func (c *ldapConn) search(dn string, timeLimit time.Duration) (int, error) {
+	req := berEncode(ldapTagSearchRequest,
+		berString(berTagOctetStr, dn),
+		berInt(berTagEnumerated, ldapScopeBaseObject),
+		berInt(berTagEnumerated, ldapDerefAliasesNever),
+		berInt(berTagInteger, 1),
+		berInt(berTagInteger, int((timeLimit+time.Second-1)/time.Second)),
+		berEncode(berTagBoolean, []byte{0xff}),
+		berString(ldapTagFilterPresent, "objectClass"),
+		berEncode(berTagSequence, berString(berTagOctetStr, "1.1")))
+	if err := c.send(req); err != nil {
+		return 0, err
+	}
+	var entries int
+	for {
+		resp, err := c.read()
+		if err != nil {
+			return 0, err
+		}
+		switch resp.tag {
+		case ldapTagSearchEntry:
+			entries++
+		case ldapTagSearchReference:
+		case ldapTagSearchDone:
+			err := ldapResult(resp)
+			var rerr *ldapResultError
+			if errors.As(err, &rerr) && rerr.code == ldapResultSizeLimit && entries > 0 {
+				err = nil
+			}
+			return entries, err
+		default:
+			return 0, fmt.Errorf("unexpected LDAP search response tag 0x%02x", resp.tag)
+		}
+	}
+}
+
+// Check executes an LDAP healthcheck.
+func (hc *LDAPChecker) Check(timeout time.Duration) *Result {
+	msg := fmt.Sprintf("LDAP connect to %s", hc.addr())
+	start := time.Now()
+	if timeout == time.Duration(0) {
+		timeout = defaultLDAPTimeout
+	}
+	deadline := start.Add(timeout)
+
+	conn, err := dialTCP(hc.network(), hc.addr(), timeout, hc.Mark)
+	if err != nil {
+		msg = fmt.Sprintf("%s; failed to connect", msg)
+		return complete(start, msg, false, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(deadline); err != nil {
+		msg = fmt.Sprintf("%s; failed to set deadline", msg)
+		return complete(start, msg, false, err)
+	}
+
+	// Server results are reported in the message, while network and
+	// protocol errors are returned as the healthcheck error.
+	var rerr *ldapResultError
+	failed := func(step string, err error) *Result {
+		if errors.As(err, &rerr) {
+			msg = fmt.Sprintf("%s; %s failed: %v", msg, step, rerr)
+			return complete(start, msg, false, nil)
+		}
+		msg = fmt.Sprintf("%s; network failure during %s", msg, step)
+		return complete(start, msg, false, err)
+	}
+
+	serverName := hc.ServerName
+	if serverName == "" {
+		serverName = hc.IP.String()
+	}
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: !hc.TLSVerify,
+		ServerName:         serverName,
+	}
+	if hc.Secure {
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.Handshake(); err != nil {
+			return failed("TLS handshake", err)
+		}
+		conn = tlsConn
+	}
+	c := newLDAPConn(conn, 0)
+
+	if hc.StartTLS && !hc.Secure {
+		req := berEncode(ldapTagExtendedRequest, berString(ldapTagExtendedName, ldapStartTLSOID))
+		resp, err := c.request(req, ldapTagExtendedResponse)
+		if err == nil {
+			err = ldapResult(resp)
+		}
+		if err != nil {
+			return failed("StartTLS", err)
+		}
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.Handshake(); err != nil {
+			return failed("TLS handshake", err)
+		}
+		defer tlsConn.Close()
+		c = newLDAPConn(tlsConn, c.id)
+	}
+
+	bind := berEncode(ldapTagBindRequest,
+		berInt(berTagInteger, ldapVersion),
+		berString(berTagOctetStr, hc.BindDN),
+		berString(ldapTagSimpleAuth, hc.Password))
+	resp, err := c.request(bind, ldapTagBindResponse)
+	if err == nil {
+		err = ldapResult(resp)
+	}
+	if err != nil {
+		return failed("bind", err)
+	}
+
+	if hc.SearchDN != "" {
+		entries, err := c.search(hc.SearchDN, deadline.Sub(time.Now()))
+		if err != nil {
+			return failed("search", err)
+		}
+		if entries == 0 {
+			msg = fmt.Sprintf("%s; search of %q returned no entries", msg, hc.SearchDN)
+			return complete(start, msg, false, nil)
+		}
+	}
+
+	// The healthcheck has already succeeded, so unbind errors are ignored.
+	c.send(berEncode(ldapTagUnbindRequest))
+	return complete(start, msg, true, nil)
+}
@@ -0,0 +1,266 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"bufio"
+	"crypto/tls"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ldapStub is a stub LDAP server supporting simple binds, StartTLS, base
+// scope searches and unbind.
+type ldapStub struct {
+	tlsConfig   *tls.Config
+	noAnonymous bool
+	password    string          // The password for any non-empty bind DN.
+	entries     map[string]bool // DNs that exist. False entries never match the filter.
+	unbound     chan struct{}   // Closed on unbind.
+	once        sync.Once
+}
+
+func ldapResponse(id int, tag byte, code int, diagnostic string, extra ...[]byte) []byte {
+	op := append([][]byte{
+		berInt(berTagEnumerated, code),
+		berString(berTagOctetStr, ""),
+		berString(berTagOctetStr, diagnostic),
+	}, extra...)
+	return berEncode(berTagSequence, berInt(berTagInteger, id), berEncode(tag, op...))
+}
+
+func (ls *ldapStub) handle(conn net.Conn) {
+	defer func() { conn.Close() }()
+	r := bufio.NewReader(conn)
+	for {
+		msg, err := readBER(r)
+		if err != nil {
+			return
+		}
+		fields, err := msg.children()
+		if err != nil || len(fields) < 2 {
+			return
+		}
+		id, _ := fields[0].int()
+		op := fields[1]
+		args, _ := op.children()
+
+		var resp []byte
+		switch op.tag {
+		case ldapTagExtendedRequest:
+			if ls.tlsConfig == nil || len(args) == 0 || string(args[0].data) != ldapStartTLSOID {
+				resp = ldapResponse(id, ldapTagExtendedResponse, 2, "unsupported extended operation")
+				break
+			}
+			writeFull(conn, ldapResponse(id, ldapTagExtendedResponse, 0, "", berString(0x8a, ldapStartTLSOID)))
+			tlsConn := tls.Server(conn, ls.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			r = bufio.NewReader(conn)
+			continue
+		case ldapTagBindRequest:
+			dn, password := string(args[1].data), string(args[2].data)
+			switch {
+			case dn == "" && ls.noAnonymous:
+				resp = ldapResponse(id, ldapTagBindResponse, 48, "anonymous bind disallowed")
+			case dn != "" && password != ls.password:
+				resp = ldapResponse(id, ldapTagBindResponse, 49, "")
+			default:
+				resp = ldapResponse(id, ldapTagBindResponse, 0, "")
+			}
+		case ldapTagSearchRequest:
+			base := string(args[0].data)
+			match, ok := ls.entries[base]
+			if !ok {
+				resp = ldapResponse(id, ldapTagSearchDone, 32, "")
+				break
+			}
+			if match {
+				entry := berEncode(ldapTagSearchEntry, berString(berTagOctetStr, base), berEncode(berTagSequence))
+				writeFull(conn, berEncode(berTagSequence, berInt(berTagInteger, id), entry))
+			}
+			resp = ldapResponse(id, ldapTagSearchDone, 0, "")
+		case ldapTagUnbindRequest:
+			ls.once.Do(func() { close(ls.unbound) })
+			return
+		default:
+			return
+		}
+		if err := writeFull(conn, resp); err != nil {
+			return
+		}
+	}
+}
+
+var ldapCheckerTests = []struct {
+	desc        string
+	ldaps       bool
+	starttls    bool
+	noAnonymous bool
+	bindDN      string
+	password    string
+	searchDN    string
+	success     bool
+	message     string
+}{
+	{"anonymous", false, false, false, "", "", "", true, "LDAP connect"},
+	{"simple bind", false, false, false, "cn=seesaw,dc=example,dc=com", "secret", "", true, "LDAP connect"},
+	{
+		"invalid credentials", false, false, false, "cn=seesaw,dc=example,dc=com", "wrong", "",
+		false, "bind failed: resultCode 49 (invalidCredentials)",
+	},
+	{
+		"anonymous disallowed", false, false, true, "", "", "",
+		false, "bind failed: resultCode 48 (inappropriateAuthentication): anonymous bind disallowed",
+	},
+	{"search", false, false, false, "", "", "dc=example,dc=com", true, "LDAP connect"},
+	{
+		"search no such object", false, false, false, "", "", "dc=example,dc=org",
+		false, "search failed: resultCode 32 (noSuchObject)",
+	},
+	{
+		"search no entries", false, false, false, "", "", "ou=hidden,dc=example,dc=com",
+		false, `search of "ou=hidden,dc=example,dc=com" returned no entries`,
+	},
+	{"ldaps", true, false, false, "cn=seesaw,dc=example,dc=com", "secret", "dc=example,dc=com", true, "LDAP connect"},
+	{"starttls", false, true, false, "cn=seesaw,dc=example,dc=com", "secret", "dc=example,dc=com", true, "LDAP connect"},
+}
+
+func TestLDAPChecker(t *testing.T) {
+	cert, _ := newServerCert(t, "ldap.example.com")
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}}
+	for _, test := range ldapCheckerTests {
+		tl, addr, err := newLocalTCPListener("tcp4")
+		if err != nil {
+			t.Fatalf("Failed to create local TCP listener: %v", err)
+		}
+		var l net.Listener = tl
+		if test.ldaps {
+			l = tls.NewListener(tl, tlsConfig)
+		}
+		ls := &ldapStub{
+			noAnonymous: test.noAnonymous,
+			password:    "secret",
+			entries: map[string]bool{
+				"dc=example,dc=com":           true,
+				"ou=hidden,dc=example,dc=com": false,
+			},
+			unbound: make(chan struct{}),
+		}
+		if test.starttls {
+			ls.tlsConfig = tlsConfig
+		}
+		go serveConns(l, ls.handle)
+
+		hc := NewLDAPChecker(addr.IP, addr.Port)
+		hc.Secure = test.ldaps
+		hc.StartTLS = test.starttls
+		hc.BindDN = test.bindDN
+		hc.Password = test.password
+		hc.SearchDN = test.searchDN
+		result := hc.Check(time.Second)
+		if result.Success != test.success {
+			t.Errorf("%s: got success %v, want %v: %v", test.desc, result.Success, test.success, result)
+		}
+		if !strings.Contains(result.Message, test.message) {
+			t.Errorf("%s: got message %q, want it to contain %q", test.desc, result.Message, test.message)
+		}
+		if test.success {
+			select {
+			case <-ls.unbound:
+			case <-time.After(time.Second):
+				t.Errorf("%s: healthcheck did not unbind", test.desc)
+			}
+		}
+		l.Close()
+	}
+}
+
+func TestLDAPCheckerStartTLSUnsupported(t *testing.T) {
+	l, addr, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to create local TCP listener: %v", err)
+	}
+	defer l.Close()
+	go serveConns(l, (&ldapStub{unbound: make(chan struct{})}).handle)
+
+	hc := NewLDAPChecker(addr.IP, addr.Port)
+	hc.StartTLS = true
+	result := hc.Check(time.Second)
+	if result.Success {
+		t.Fatalf("LDAP healthcheck succeeded without StartTLS support: %v", result)
+	}
+	if want := "StartTLS failed: resultCode 2 (protocolError)"; !strings.Contains(result.Message, want) {
+		t.Errorf("got message %q, want it to contain %q", result.Message, want)
+	}
+}
+
+func TestLDAPCheckerNetworkFailure(t *testing.T) {
+	l, addr, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to create local TCP listener: %v", err)
+	}
+	defer l.Close()
+	// Accept connections but never reply.
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				io.Copy(io.Discard, conn)
+				conn.Close()
+			}()
+		}
+	}()
+
+	hc := NewLDAPChecker(addr.IP, addr.Port)
+	start := time.Now()
+	result := hc.Check(200 * time.Millisecond)
+	if result.Success {
+		t.Fatalf("LDAP healthcheck succeeded without a reply: %v", result)
+	}
+	if !strings.Contains(result.Message, "network failure during bind") {
+		t.Errorf("got message %q, want it to contain %q", result.Message, "network failure during bind")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("LDAP healthcheck took %v, want it bounded by the timeout", elapsed)
+	}
+}
+
+func TestBERRoundTrip(t *testing.T) {
+	for _, v := range []int{0, 1, 127, 128, 255, 256, 65535, 1 << 20} {
+		e := berInt(berTagInteger, v)
+		got, err := readBER(bufio.NewReader(strings.NewReader(string(e))))
+		if err != nil {
+			t.Fatalf("readBER(%x): %v", e, err)
+		}
+		if n, err := got.int(); err != nil || n != v {
+			t.Errorf("BER integer %d decoded as %d (%v)", v, n, err)
+		}
+	}
+	long := berString(berTagOctetStr, strings.Repeat("x", 300))
+	got, err := readBER(bufio.NewReader(strings.NewReader(string(long))))
+	if err != nil || len(got.data) != 300 {
+		t.Errorf("Long BER string decoded with length %d (%v), want 300", len(got.data), err)
+	}
+}
@@ -0,0 +1,339 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Memcache healthcheck implementation.
+
+package healthcheck
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/seesaw/common/seesaw"
+)
+
+const (
+	defaultMemcacheTimeout = 10 * time.Second
+
+	// memcacheMaxResponseSize bounds the total amount of data read from the
+	// server during a healthcheck.
+	memcacheMaxResponseSize = 64 * 1024
+
+	memcacheValueExpiry = 60 // seconds
+
+	// memcacheMaxKeyLength is the maximum memcache key length. Round trip
+	// keys are the key prefix followed by 16 random hex digits.
+	memcacheMaxKeyLength = 250
+)
+
+// Memcache binary protocol constants.
+const (
+	memcacheBinaryRequest    = 0x80
+	memcacheBinaryResponse   = 0x81
+	memcacheBinaryNoop       = 0x0a
+	memcacheBinaryHeaderSize = 24
+)
+
+// MemcacheChecker contains configuration specific to a memcache healthcheck.
+//
+// By default the healthcheck sends the ASCII "version" command. If StatsKey
+// is set, it also sends "stats" and fails unless the statistic is present
+// (and equal to StatsValue, if set). If KeyPrefix is set, it also stores,
+// fetches and deletes a value under a random key with that prefix. If Binary
+// is set, a binary protocol NOOP is sent instead and the other options are
+// ignored.
+type MemcacheChecker struct {
+	Target
+	Binary     bool
+	StatsKey   string
+	StatsValue string
+	KeyPrefix  string
+}
+
+// NewMemcacheChecker returns an initialised MemcacheChecker.
+func NewMemcacheChecker(ip net.IP, port int) *MemcacheChecker {
+	return &MemcacheChecker{
+		Target: Target{
+			IP:    ip,
+			Port:  port,
+			Proto: seesaw.IPProtoTCP,
+		},
+	}
+}
+
+// ValidMemcacheKeyPrefix reports whether the given prefix can be used for
+// the keys of a memcache set/get round trip.
+func ValidMemcacheKeyPrefix(prefix string) bool {
+	if len(prefix)+16 > memcacheMaxKeyLength {
+		return false
+	}
+	for _, r := range prefix {
+		if r <= ' ' || r == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the string representation of a memcache healthcheck.
+func (hc *MemcacheChecker) String() string {
+	attr := []string{}
+	if hc.Binary {
+		attr = append(attr, "binary")
+	} else {
+		if hc.StatsKey != "" {
+			attr = append(attr, "stat "+hc.StatsKey)
+		}
+		if hc.KeyPrefix != "" {
+			attr = append(attr, "key prefix "+hc.KeyPrefix)
+		}
+	}
+	var s string
+	if len(attr) > 0 {
+		s = fmt.Sprintf(" [%s]", strings.Join(attr, "; "))
+	}
+	return fmt.Sprintf("Memcache%s %s", s, hc.Target)
+}
+
+// memcacheConn sends commands to and reads responses from a memcache
+// server. Reads are bounded by memcacheMaxResponseSize in total.
+type memcacheConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func newMemcacheConn(conn net.Conn) *memcacheConn {
+	return &memcacheConn{
+		conn: conn,
+		r:    bufio.NewReader(io.LimitReader(conn, memcacheMaxResponseSize)),
+	}
+}
+
+// readLine reads a CRLF terminated response line.
+func (c *memcacheConn) readLine() (string, error) {
+	line, err := c.r.ReadString('\n')
+	if err == io.EOF && len(line) > 0 {
+		return "", errors.New("response too large or truncated")
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// command sends an ASCII command and returns the first response line.
+// Error responses are returned as errors.
+func (c *memcacheConn) command(cmd string) (string, error) {
+	if err := writeFull(c.conn, []byte(cmd+"\r\n")); err != nil {
+		return "", err
+	}
+	line, err := c.readLine()
+	if err != nil {
+		return "", err
+	}
+	if line == "ERROR" || strings.HasPrefix(line, "CLIENT_ERROR") || strings.HasPrefix(line, "SERVER_ERROR") {
+		return "", fmt.Errorf("%s returned %q", strings.Fields(cmd)[0], line)
+	}
+	return line, nil
+}
+
+// version returns the server version.
+func (c *memcacheConn) version() (string, error) {
+	line, err := c.command("version")
+	if err != nil {
+		return "", err
+	}
+	version, ok := strings.CutPrefix(line, "VERSION ")
+	if !ok {
+		return "", fmt.Errorf("unexpected version response %q", line)
+	}
+	return version, nil
+}
+
+// stat returns the value of the named statistic, and whether it was found.
+func (c *memcacheConn) stat(name string) (string, bool, error) {
+	line, err := c.command("stats")
+	if err != nil {
+		return "", false, err
+	}
+	var value string
+	var found bool
+	for ; line != "END"; line, err = c.readLine() {
+		if err != nil {
+			return "", false, err
+		}
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) != 3 || fields[0] != "STAT" {
+			return "", false, fmt.Errorf("unexpected stats response %q", line)
+		}
+		if fields[1] == name {
+			value, found = fields[2], true
+		}
+	}
+	return value, found, nil
+}
+
+// roundTrip stores, fetches and deletes a value under the given key.
+func (c *memcacheConn) roundTrip(key string) error {
+	value := make([]byte, 16)
+	if _, err := rand.Read(value); err != nil {
+		return err
+	}
+	data := hex.EncodeToString(value)
+
+	line, err := c.command(fmt.Sprintf("set %s 0 %d %d\r\n%s", key, memcacheValueExpiry, len(data), data))
+	if err != nil {
+		return err
+	}
+	if line != "STORED" {
+		return fmt.Errorf("unexpected set response %q", line)
+	}
+
+	line, err = c.command("get " + key)
+	if err != nil {
+		return err
+	}
+	if line == "END" {
+		return errors.New("stored value not found")
+	}
+	if want := fmt.Sprintf("VALUE %s 0 %d", key, len(data)); line != want {
+		return fmt.Errorf("unexpected get response %q", line)
+	}
+	got := make([]byte, len(data)+2)
+	if _, err := io.ReadFull(c.r, got); err != nil {
+		return err
+	}
+	if string(got) != data+"\r\n" {
+		return errors.New("fetched value does not match stored value")
+	}
+	if line, err := c.readLine(); err != nil || line != "END" {
+		return fmt.Errorf("unexpected get response %q: %v", line, err)
+	}
+
+	line, err = c.command("delete " + key)
+	if err != nil {
+		return err
+	}
+	if line != "DELETED" && line != "NOT_FOUND" {
+		return fmt.Errorf("unexpected delete response %q", line)
+	}
+	return nil
+}
+
+// noop sends a binary protocol NOOP and validates the response.
+func (c *memcacheConn) noop() error {
+	var opaque [4]byte
+	if _, err := rand.Read(opaque[:]); err != nil {
+		return err
+	}
+	req := make([]byte, memcacheBinaryHeaderSize)
+	req[0] = memcacheBinaryRequest
+	req[1] = memcacheBinaryNoop
+	copy(req[12:16], opaque[:])
+	if err := writeFull(c.conn, req); err != nil {
+		return err
+	}
+
+	resp := make([]byte, memcacheBinaryHeaderSize)
+	if _, err := io.ReadFull(c.r, resp); err != nil {
+		return err
+	}
+	if resp[0] != memcacheBinaryResponse || resp[1] != memcacheBinaryNoop {
+		return fmt.Errorf("unexpected binary response magic 0x%02x opcode 0x%02x", resp[0], resp[1])
+	}
+	if !bytes.Equal(resp[12:16], opaque[:]) {
+		return errors.New("binary response opaque mismatch")
+	}
+	if status := binary.BigEndian.Uint16(resp[6:8]); status != 0 {
+		return fmt.Errorf("binary NOOP returned status 0x%04x", status)
+	}
+	if n := binary.BigEndian.Uint32(resp[8:12]); n != 0 {
+		return fmt.Errorf("unexpected binary NOOP body of %d bytes", n)
+	}
+	return nil
+}
+
+// Check executes a memcache healthcheck.
+func (hc *MemcacheChecker) Check(timeout time.Duration) *Result {
+	msg := fmt.Sprintf("Memcache connect to %s", hc.addr())
+	start := time.Now()
+	if timeout == time.Duration(0) {
+		timeout = defaultMemcacheTimeout
+	}
+	deadline := start.Add(timeout)
+
+	conn, err := dialTCP(hc.network(), hc.addr(), timeout, hc.Mark)
+	if err != nil {
+		msg = fmt.Sprintf("%s; failed to connect", msg)
+		return complete(start, msg, false, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(deadline); err != nil {
+		msg = fmt.Sprintf("%s; failed to set deadline", msg)
+		return complete(start, msg, false, err)
+	}
+	c := newMemcacheConn(conn)
+
+	if hc.Binary {
+		if err := c.noop(); err != nil {
+			msg = fmt.Sprintf("%s; NOOP failed", msg)
+			return complete(start, msg, false, err)
+		}
+		return complete(start, msg, true, nil)
+	}
+
+	version, err := c.version()
+	if err != nil {
+		msg = fmt.Sprintf("%s; version failed", msg)
+		return complete(start, msg, false, err)
+	}
+	msg = fmt.Sprintf("%s; server version %s", msg, version)
+
+	if hc.StatsKey != "" {
+		value, found, err := c.stat(hc.StatsKey)
+		if err != nil {
+			msg = fmt.Sprintf("%s; stats failed", msg)
+			return complete(start, msg, false, err)
+		}
+		if !found {
+			msg = fmt.Sprintf("%s; stat %s not found", msg, hc.StatsKey)
+			return complete(start, msg, false, nil)
+		}
+		if hc.StatsValue != "" && value != hc.StatsValue {
+			msg = fmt.Sprintf("%s; stat %s is %q, want %q", msg, hc.StatsKey, value, hc.StatsValue)
+			return complete(start, msg, false, nil)
+		}
+	}
+
+	if hc.KeyPrefix != "" {
+		suffix := make([]byte, 8)
+		if _, err := rand.Read(suffix); err != nil {
+			return complete(start, msg, false, err)
+		}
+		if err := c.roundTrip(hc.KeyPrefix + hex.EncodeToString(suffix)); err != nil {
+			msg = fmt.Sprintf("%s; set/get round trip failed", msg)
+			return complete(start, msg, false, err)
+		}
+	}
+	return complete(start, msg, true, nil)
+}
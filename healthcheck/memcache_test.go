@@ -0,0 +1,191 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memcacheStub is a stub memcache server supporting the ASCII version,
+// stats, set, get and delete commands, and the binary NOOP command.
+type memcacheStub struct {
+	mu     sync.Mutex
+	values map[string]string
+	broken bool // Reply to set with SERVER_ERROR.
+}
+
+func (ms *memcacheStub) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		b, err := r.Peek(1)
+		if err != nil {
+			return
+		}
+		if b[0] == memcacheBinaryRequest {
+			req := make([]byte, memcacheBinaryHeaderSize)
+			if _, err := io.ReadFull(r, req); err != nil {
+				return
+			}
+			resp := make([]byte, memcacheBinaryHeaderSize)
+			resp[0] = memcacheBinaryResponse
+			resp[1] = req[1]
+			copy(resp[12:16], req[12:16])
+			if req[1] != memcacheBinaryNoop {
+				resp[7] = 0x81 // Unknown command.
+			}
+			conn.Write(resp)
+			continue
+		}
+
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		var reply string
+		ms.mu.Lock()
+		switch fields[0] {
+		case "version":
+			reply = "VERSION 1.6.21\r\n"
+		case "stats":
+			reply = "STAT pid 1\r\nSTAT accepting_conns 1\r\nSTAT curr_connections 2\r\nEND\r\n"
+		case "set":
+			n, _ := strconv.Atoi(fields[4])
+			data := make([]byte, n+2)
+			if _, err := io.ReadFull(r, data); err != nil {
+				ms.mu.Unlock()
+				return
+			}
+			if ms.broken {
+				reply = "SERVER_ERROR out of memory storing object\r\n"
+				break
+			}
+			ms.values[fields[1]] = string(data[:n])
+			reply = "STORED\r\n"
+		case "get":
+			if v, ok := ms.values[fields[1]]; ok {
+				reply = fmt.Sprintf("VALUE %s 0 %d\r\n%s\r\n", fields[1], len(v), v)
+			}
+			reply += "END\r\n"
+		case "delete":
+			reply = "NOT_FOUND\r\n"
+			if _, ok := ms.values[fields[1]]; ok {
+				delete(ms.values, fields[1])
+				reply = "DELETED\r\n"
+			}
+		default:
+			reply = "ERROR\r\n"
+		}
+		ms.mu.Unlock()
+		io.WriteString(conn, reply)
+	}
+}
+
+var memcacheCheckerTests = []struct {
+	desc       string
+	binary     bool
+	statsKey   string
+	statsValue string
+	keyPrefix  string
+	broken     bool
+	success    bool
+	message    string
+}{
+	{"version", false, "", "", "", false, true, "server version 1.6.21"},
+	{"binary noop", true, "", "", "", false, true, "Memcache connect"},
+	{"stat present", false, "accepting_conns", "", "", false, true, "server version 1.6.21"},
+	{"stat value", false, "accepting_conns", "1", "", false, true, "server version 1.6.21"},
+	{"stat wrong value", false, "accepting_conns", "0", "", false, false, `stat accepting_conns is "1", want "0"`},
+	{"stat missing", false, "no_such_stat", "", "", false, false, "stat no_such_stat not found"},
+	{"round trip", false, "", "", "seesaw-hc-", false, true, "server version 1.6.21"},
+	{"round trip failure", false, "", "", "seesaw-hc-", true, false, "set/get round trip failed"},
+}
+
+func TestMemcacheChecker(t *testing.T) {
+	for _, test := range memcacheCheckerTests {
+		l, addr, err := newLocalTCPListener("tcp4")
+		if err != nil {
+			t.Fatalf("Failed to create local TCP listener: %v", err)
+		}
+		ms := &memcacheStub{values: make(map[string]string), broken: test.broken}
+		go serveConns(l, ms.handle)
+
+		hc := NewMemcacheChecker(addr.IP, addr.Port)
+		hc.Binary = test.binary
+		hc.StatsKey = test.statsKey
+		hc.StatsValue = test.statsValue
+		hc.KeyPrefix = test.keyPrefix
+		result := hc.Check(time.Second)
+		l.Close()
+		if result.Success != test.success {
+			t.Errorf("%s: got success %v, want %v: %v", test.desc, result.Success, test.success, result)
+		}
+		if !strings.Contains(result.Message, test.message) {
+			t.Errorf("%s: got message %q, want it to contain %q", test.desc, result.Message, test.message)
+		}
+		ms.mu.Lock()
+		if len(ms.values) != 0 {
+			t.Errorf("%s: round trip left %d values behind", test.desc, len(ms.values))
+		}
+		ms.mu.Unlock()
+	}
+}
+
+func TestMemcacheCheckerBoundedRead(t *testing.T) {
+	l, addr, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to create local TCP listener: %v", err)
+	}
+	defer l.Close()
+	// Respond with an endless line.
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buf := []byte(strings.Repeat("x", 4096))
+				for {
+					if _, err := conn.Write(buf); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+
+	hc := NewMemcacheChecker(addr.IP, addr.Port)
+	result := hc.Check(5 * time.Second)
+	if result.Success {
+		t.Fatalf("Memcache healthcheck succeeded against an endless response: %v", result)
+	}
+	if result.Err == nil || !strings.Contains(result.Err.Error(), "too large") {
+		t.Errorf("got error %v, want a response size error", result.Err)
+	}
+}
@@ -0,0 +1,525 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// MySQL healthcheck implementation.
+
+package healthcheck
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/seesaw/common/seesaw"
+)
+
+const (
+	defaultMySQLTimeout = 10 * time.Second
+
+	mysqlMaxPacketSize = 1 << 20
+	mysqlMaxAuthRounds = 4
+	mysqlReadOnlyQuery = "SELECT @@global.read_only"
+)
+
+// MySQL capability flags.
+const (
+	mysqlClientLongPassword     = 0x00000001
+	mysqlClientProtocol41       = 0x00000200
+	mysqlClientTransactions     = 0x00002000
+	mysqlClientSecureConnection = 0x00008000
+	mysqlClientPluginAuth       = 0x00080000
+)
+
+// MySQL command and response packet types.
+const (
+	mysqlComQuit  = 0x01
+	mysqlComQuery = 0x03
+
+	mysqlPacketOK         = 0x00
+	mysqlPacketMoreData   = 0x01
+	mysqlPacketAuthSwitch = 0xfe
+	mysqlPacketEOF        = 0xfe
+	mysqlPacketError      = 0xff
+)
+
+const (
+	mysqlNativePassword       = "mysql_native_password"
+	mysqlCachingSHA2Password  = "caching_sha2_password"
+	mysqlCachingSHA2FastAuth  = 0x03
+	mysqlCachingSHA2FullAuth  = 0x04
+	mysqlCharsetUTF8GeneralCI = 33
+)
+
+// MySQLChecker contains configuration specific to a MySQL healthcheck.
+//
+// If User is empty, the healthcheck succeeds once the server greeting has
+// been received, without authenticating. Otherwise the healthcheck logs in,
+// runs Query if set and, if ExpectWritable is set, fails if the server is
+// read-only.
+type MySQLChecker struct {
+	Target
+	User           string
+	Password       string
+	Query          string // A query to run after logging in, e.g. "SELECT 1".
+	ExpectWritable bool
+}
+
+// NewMySQLChecker returns an initialised MySQLChecker.
+func NewMySQLChecker(ip net.IP, port int) *MySQLChecker {
+	return &MySQLChecker{
+		Target: Target{
+			IP:    ip,
+			Port:  port,
+			Proto: seesaw.IPProtoTCP,
+		},
+	}
+}
+
+// String returns the string representation of a MySQL healthcheck.
+func (hc *MySQLChecker) String() string {
+	attr := []string{}
+	if hc.User == "" {
+		attr = append(attr, "greeting only")
+	} else {
+		attr = append(attr, fmt.Sprintf("user %q", hc.User))
+		if hc.Query != "" {
+			attr = append(attr, fmt.Sprintf("query %q", hc.Query))
+		}
+		if hc.ExpectWritable {
+			attr = append(attr, "writable")
+		}
+	}
+	return fmt.Sprintf("MySQL [%s] %s", strings.Join(attr, "; "), hc.Target)
+}
+
+// mysqlError is an error returned by a MySQL server.
+type mysqlError struct {
+	Code    uint16
+	State   string
+	Message string
+}
+
+// Error returns the string representation of a MySQL server error.
+func (e *mysqlError) Error() string {
+	if e.State != "" {
+		return fmt.Sprintf("ERROR %d (%s): %s", e.Code, e.State, e.Message)
+	}
+	return fmt.Sprintf("ERROR %d: %s", e.Code, e.Message)
+}
+
+// parseMySQLError parses a MySQL ERR packet.
+func parseMySQLError(b []byte) error {
+	if len(b) < 3 || b[0] != mysqlPacketError {
+		return errors.New("malformed error packet")
+	}
+	e := &mysqlError{Code: binary.LittleEndian.Uint16(b[1:3])}
+	b = b[3:]
+	if len(b) >= 6 && b[0] == '#' {
+		e.State = string(b[1:6])
+		b = b[6:]
+	}
+	e.Message = string(b)
+	return e
+}
+
+// mysqlGreeting contains the fields of a MySQL initial handshake packet
+// that are needed to log in.
+type mysqlGreeting struct {
+	version      string
+	capabilities uint32
+	authData     []byte
+	authPlugin   string
+}
+
+// parseMySQLGreeting parses a MySQL protocol version 10 initial handshake.
+func parseMySQLGreeting(b []byte) (*mysqlGreeting, error) {
+	if len(b) == 0 {
+		return nil, errors.New("empty greeting")
+	}
+	if b[0] == mysqlPacketError {
+		return nil, parseMySQLError(b)
+	}
+	if b[0] != 10 {
+		return nil, fmt.Errorf("unsupported protocol version %d", b[0])
+	}
+	b = b[1:]
+	i := bytes.IndexByte(b, 0)
+	if i < 0 {
+		return nil, errors.New("malformed server version")
+	}
+	g := &mysqlGreeting{version: string(b[:i])}
+	b = b[i+1:]
+
+	// Connection ID, first part of the auth data, filler and the lower
+	// capability flags.
+	if len(b) < 15 {
+		return nil, errors.New("short greeting")
+	}
+	g.authData = append(g.authData, b[4:12]...)
+	g.capabilities = uint32(binary.LittleEndian.Uint16(b[13:15]))
+	b = b[15:]
+	if len(b) == 0 {
+		return g, nil
+	}
+
+	// Character set, status flags, upper capability flags, auth data
+	// length and reserved bytes.
+	if len(b) < 16 {
+		return nil, errors.New("short greeting")
+	}
+	g.capabilities |= uint32(binary.LittleEndian.Uint16(b[3:5])) << 16
+	authLen := int(b[5])
+	b = b[16:]
+	if g.capabilities&mysqlClientSecureConnection != 0 {
+		n := authLen - 8
+		if n < 13 {
+			n = 13
+		}
+		if len(b) < n {
+			return nil, errors.New("short auth data")
+		}
+		g.authData = append(g.authData, bytes.TrimRight(b[:n], "\x00")...)
+		b = b[n:]
+	}
+	if g.capabilities&mysqlClientPluginAuth != 0 {
+		if i := bytes.IndexByte(b, 0); i >= 0 {
+			b = b[:i]
+		}
+		g.authPlugin = string(b)
+	}
+	return g, nil
+}
+
+// mysqlScramble returns the authentication response for the given plugin,
+// password and server nonce.
+func mysqlScramble(plugin, password string, nonce []byte) ([]byte, error) {
+	if password == "" {
+		return nil, nil
+	}
+	switch plugin {
+	case mysqlNativePassword, "":
+		// SHA1(password) XOR SHA1(nonce + SHA1(SHA1(password)))
+		h1 := sha1.Sum([]byte(password))
+		h2 := sha1.Sum(h1[:])
+		h := sha1.New()
+		h.Write(nonce)
+		h.Write(h2[:])
+		scramble := h.Sum(nil)
+		for i := range scramble {
+			scramble[i] ^= h1[i]
+		}
+		return scramble, nil
+	case mysqlCachingSHA2Password:
+		// SHA256(password) XOR SHA256(SHA256(SHA256(password)) + nonce)
+		h1 := sha256.Sum256([]byte(password))
+		h2 := sha256.Sum256(h1[:])
+		h := sha256.New()
+		h.Write(h2[:])
+		h.Write(nonce)
+		scramble := h.Sum(nil)
+		for i := range scramble {
+			scramble[i] ^= h1[i]
+		}
+		return scramble, nil
+	}
+	return nil, fmt.Errorf("unsupported authentication plugin %q", plugin)
+}
+
+// mysqlConn reads and writes MySQL protocol packets.
+type mysqlConn struct {
+	conn net.Conn
+	seq  byte
+}
+
+// readPacket reads a single MySQL packet.
+func (c *mysqlConn) readPacket() ([]byte, error) {
+	var hdr [4]byte
+	if _, err := io.ReadFull(c.conn, hdr[:]); err != nil {
+		return nil, err
+	}
+	n := int(hdr[0]) | int(hdr[1])<<8 | int(hdr[2])<<16
+	if n > mysqlMaxPacketSize {
+		return nil, fmt.Errorf("packet too large (%d bytes)", n)
+	}
+	c.seq = hdr[3] + 1
+	b := make([]byte, n)
+	if _, err := io.ReadFull(c.conn, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// writePacket writes a single MySQL packet.
+func (c *mysqlConn) writePacket(b []byte) error {
+	pkt := make([]byte, 4, 4+len(b))
+	pkt[0] = byte(len(b))
+	pkt[1] = byte(len(b) >> 8)
+	pkt[2] = byte(len(b) >> 16)
+	pkt[3] = c.seq
+	c.seq++
+	return writeFull(c.conn, append(pkt, b...))
+}
+
+// command starts a new command, resetting the packet sequence.
+func (c *mysqlConn) command(cmd byte, arg string) error {
+	c.seq = 0
+	return c.writePacket(append([]byte{cmd}, arg...))
+}
+
+// login authenticates with the server following the given greeting.
+func (c *mysqlConn) login(g *mysqlGreeting, user, password string) error {
+	if g.capabilities&mysqlClientProtocol41 == 0 {
+		return errors.New("server does not support protocol 4.1")
+	}
+	plugin := g.authPlugin
+	if plugin == "" {
+		plugin = mysqlNativePassword
+	}
+	scramble, err := mysqlScramble(plugin, password, g.authData)
+	if err != nil {
+		return err
+	}
+	caps := uint32(mysqlClientLongPassword | mysqlClientProtocol41 | mysqlClientTransactions |
+		mysqlClientSecureConnection | mysqlClientPluginAuth)
+	caps &= g.capabilities | mysqlClientProtocol41
+
+	b := make([]byte, 32)
+	binary.LittleEndian.PutUint32(b[0:4], caps)
+	binary.LittleEndian.PutUint32(b[4:8], mysqlMaxPacketSize)
+	b[8] = mysqlCharsetUTF8GeneralCI
+	b = append(b, user...)
+	b = append(b, 0, byte(len(scramble)))
+	b = append(b, scramble...)
+	if caps&mysqlClientPluginAuth != 0 {
+		b = append(b, plugin...)
+		b = append(b, 0)
+	}
+	if err := c.writePacket(b); err != nil {
+		return err
+	}
+
+	for i := 0; i < mysqlMaxAuthRounds; i++ {
+		b, err := c.readPacket()
+		if err != nil {
+			return err
+		}
+		if len(b) == 0 {
+			return errors.New("empty authentication response")
+		}
+		switch b[0] {
+		case mysqlPacketOK:
+			return nil
+		case mysqlPacketError:
+			return parseMySQLError(b)
+		case mysqlPacketAuthSwitch:
+			b = b[1:]
+			i := bytes.IndexByte(b, 0)
+			if i < 0 {
+				return errors.New("malformed authentication switch request")
+			}
+			plugin = string(b[:i])
+			scramble, err := mysqlScramble(plugin, password, bytes.TrimRight(b[i+1:], "\x00"))
+			if err != nil {
+				return err
+			}
+			if err := c.writePacket(scramble); err != nil {
+				return err
+			}
+		case mysqlPacketMoreData:
+			if plugin != mysqlCachingSHA2Password || len(b) != 2 {
+				return errors.New("unexpected authentication data")
+			}
+			switch b[1] {
+			case mysqlCachingSHA2FastAuth:
+				// The OK packet follows.
+			case mysqlCachingSHA2FullAuth:
+				return errors.New("caching_sha2_password full authentication is not supported without TLS")
+			default:
+				return fmt.Errorf("unexpected caching_sha2_password status %d", b[1])
+			}
+		default:
+			return fmt.Errorf("unexpected authentication response 0x%02x", b[0])
+		}
+	}
+	return errors.New("too many authentication rounds")
+}
+
+// readLengthEncoded reads a MySQL length-encoded string, returning the
+// string, whether it is NULL and the remaining bytes.
+func readLengthEncoded(b []byte) (string, bool, []byte, error) {
+	if len(b) == 0 {
+		return "", false, nil, io.ErrUnexpectedEOF
+	}
+	var n, size int
+	switch {
+	case b[0] < 0xfb:
+		n, size = int(b[0]), 1
+	case b[0] == 0xfb:
+		return "", true, b[1:], nil
+	case b[0] == 0xfc && len(b) >= 3:
+		n, size = int(binary.LittleEndian.Uint16(b[1:3])), 3
+	case b[0] == 0xfd && len(b) >= 4:
+		n, size = int(b[1])|int(b[2])<<8|int(b[3])<<16, 4
+	default:
+		return "", false, nil, errors.New("malformed length-encoded string")
+	}
+	b = b[size:]
+	if len(b) < n {
+		return "", false, nil, io.ErrUnexpectedEOF
+	}
+	return string(b[:n]), false, b[n:], nil
+}
+
+// isMySQLEOF reports whether the packet is an EOF packet.
+func isMySQLEOF(b []byte) bool {
+	return len(b) > 0 && len(b) < 9 && b[0] == mysqlPacketEOF
+}
+
+// query runs a query and returns the values of the first row of the result
+// set, if any.
+func (c *mysqlConn) query(query string) ([]string, error) {
+	if err := c.command(mysqlComQuery, query); err != nil {
+		return nil, err
+	}
+	b, err := c.readPacket()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case len(b) == 0:
+		return nil, errors.New("empty query response")
+	case b[0] == mysqlPacketError:
+		return nil, parseMySQLError(b)
+	case b[0] == mysqlPacketOK:
+		return nil, nil
+	}
+
+	// Column count, column definitions and an EOF packet, followed by rows
+	// and a terminating EOF packet.
+	if b[0] >= 0xfb {
+		return nil, errors.New("malformed column count")
+	}
+	columns := int(b[0])
+	for i := 0; i <= columns; i++ {
+		if b, err = c.readPacket(); err != nil {
+			return nil, err
+		}
+		if len(b) > 0 && b[0] == mysqlPacketError {
+			return nil, parseMySQLError(b)
+		}
+	}
+	if !isMySQLEOF(b) {
+		return nil, errors.New("malformed column definitions")
+	}
+	var row []string
+	for {
+		b, err := c.readPacket()
+		if err != nil {
+			return nil, err
+		}
+		if isMySQLEOF(b) {
+			return row, nil
+		}
+		if len(b) > 0 && b[0] == mysqlPacketError {
+			return nil, parseMySQLError(b)
+		}
+		if row != nil {
+			continue
+		}
+		row = []string{}
+		for len(b) > 0 {
+			var v string
+			if v, _, b, err = readLengthEncoded(b); err != nil {
+				return nil, err
+			}
+			row = append(row, v)
+		}
+	}
+}
+
+// Check executes a MySQL healthcheck.
+func (hc *MySQLChecker) Check(timeout time.Duration) *Result {
+	msg := fmt.Sprintf("MySQL connect to %s", hc.addr())
+	start := time.Now()
+	if timeout == time.Duration(0) {
+		timeout = defaultMySQLTimeout
+	}
+	deadline := start.Add(timeout)
+
+	conn, err := dialTCP(hc.network(), hc.addr(), timeout, hc.Mark)
+	if err != nil {
+		msg = fmt.Sprintf("%s; failed to connect", msg)
+		return complete(start, msg, false, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(deadline); err != nil {
+		msg = fmt.Sprintf("%s; failed to set deadline", msg)
+		return complete(start, msg, false, err)
+	}
+
+	c := &mysqlConn{conn: conn}
+	b, err := c.readPacket()
+	if err != nil {
+		msg = fmt.Sprintf("%s; failed to read greeting", msg)
+		return complete(start, msg, false, err)
+	}
+	g, err := parseMySQLGreeting(b)
+	if err != nil {
+		msg = fmt.Sprintf("%s; handshake failed", msg)
+		return complete(start, msg, false, err)
+	}
+	msg = fmt.Sprintf("%s; server version %s", msg, g.version)
+	if hc.User == "" {
+		msg = fmt.Sprintf("%s; server greeting received", msg)
+		return complete(start, msg, true, nil)
+	}
+
+	if err := c.login(g, hc.User, hc.Password); err != nil {
+		msg = fmt.Sprintf("%s; authentication failed", msg)
+		return complete(start, msg, false, err)
+	}
+	// Log out once finished, so that the server does not count the
+	// session as aborted.
+	defer c.command(mysqlComQuit, "")
+
+	if hc.Query != "" {
+		if _, err := c.query(hc.Query); err != nil {
+			msg = fmt.Sprintf("%s; query failed", msg)
+			return complete(start, msg, false, err)
+		}
+	}
+	if hc.ExpectWritable {
+		row, err := c.query(mysqlReadOnlyQuery)
+		if err != nil {
+			msg = fmt.Sprintf("%s; read-only check failed", msg)
+			return complete(start, msg, false, err)
+		}
+		if len(row) != 1 {
+			msg = fmt.Sprintf("%s; read-only check returned no result", msg)
+			return complete(start, msg, false, nil)
+		}
+		if row[0] != "0" && !strings.EqualFold(row[0], "OFF") {
+			msg = fmt.Sprintf("%s; server is read-only", msg)
+			return complete(start, msg, false, nil)
+		}
+	}
+	return complete(start, msg, true, nil)
+}
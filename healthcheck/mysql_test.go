@@ -0,0 +1,232 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var mysqlTestNonce = []byte("abcdefghijklmnopqrst")
+
+// mysqlStub is a stub MySQL server. It accepts logins with the expected
+// password using mysql_native_password, optionally after an authentication
+// switch, and answers "SELECT 1" and the read-only query.
+type mysqlStub struct {
+	ln         net.Listener
+	password   string
+	readOnly   string
+	authSwitch bool
+	quits      atomic.Int32
+}
+
+func (ms *mysqlStub) serve() {
+	for {
+		conn, err := ms.ln.Accept()
+		if err != nil {
+			return
+		}
+		go ms.handle(conn)
+	}
+}
+
+func (ms *mysqlStub) writeResultSet(c *mysqlConn, value string) {
+	c.writePacket([]byte{1})
+	c.writePacket(append([]byte{3}, "def"...))
+	c.writePacket([]byte{mysqlPacketEOF, 0, 0, 2, 0})
+	c.writePacket(append([]byte{byte(len(value))}, value...))
+	c.writePacket([]byte{mysqlPacketEOF, 0, 0, 2, 0})
+}
+
+func (ms *mysqlStub) writeError(c *mysqlConn, code uint16, state, message string) {
+	b := []byte{mysqlPacketError, byte(code), byte(code >> 8), '#'}
+	b = append(b, state...)
+	c.writePacket(append(b, message...))
+}
+
+func (ms *mysqlStub) handle(conn net.Conn) {
+	defer conn.Close()
+	c := &mysqlConn{conn: conn}
+
+	plugin := mysqlNativePassword
+	if ms.authSwitch {
+		plugin = mysqlCachingSHA2Password
+	}
+	caps := uint32(mysqlClientLongPassword | mysqlClientProtocol41 | mysqlClientSecureConnection | mysqlClientPluginAuth)
+	g := append([]byte{10}, "8.0.0-stub\x00"...)
+	g = append(g, 1, 0, 0, 0)
+	g = append(g, mysqlTestNonce[:8]...)
+	g = append(g, 0, byte(caps), byte(caps>>8), mysqlCharsetUTF8GeneralCI, 2, 0, byte(caps>>16), byte(caps>>24), 21)
+	g = append(g, make([]byte, 10)...)
+	g = append(g, mysqlTestNonce[8:]...)
+	g = append(g, 0)
+	g = append(g, plugin...)
+	g = append(g, 0)
+	c.writePacket(g)
+
+	b, err := c.readPacket()
+	if err != nil || len(b) < 33 {
+		return
+	}
+	b = b[32:]
+	i := bytes.IndexByte(b, 0)
+	if i < 0 || len(b) < i+2 {
+		return
+	}
+	b = b[i+1:]
+	auth := b[1 : 1+int(b[0])]
+	if ms.authSwitch {
+		c.writePacket(append(append([]byte{mysqlPacketAuthSwitch}, mysqlNativePassword+"\x00"...), append(mysqlTestNonce, 0)...))
+		if auth, err = c.readPacket(); err != nil {
+			return
+		}
+	}
+	want, _ := mysqlScramble(mysqlNativePassword, ms.password, mysqlTestNonce)
+	if !bytes.Equal(auth, want) {
+		ms.writeError(c, 1045, "28000", "Access denied")
+		return
+	}
+	c.writePacket([]byte{mysqlPacketOK, 0, 0, 2, 0, 0, 0})
+
+	for {
+		b, err := c.readPacket()
+		if err != nil || len(b) == 0 {
+			return
+		}
+		switch {
+		case b[0] == mysqlComQuit:
+			ms.quits.Add(1)
+			return
+		case b[0] == mysqlComQuery && string(b[1:]) == "SELECT 1":
+			ms.writeResultSet(c, "1")
+		case b[0] == mysqlComQuery && string(b[1:]) == mysqlReadOnlyQuery:
+			ms.writeResultSet(c, ms.readOnly)
+		default:
+			ms.writeError(c, 1064, "42000", "You have an error in your SQL syntax")
+		}
+	}
+}
+
+func newMySQLStub(t *testing.T, readOnly string, authSwitch bool) (*mysqlStub, *net.TCPAddr) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	ms := &mysqlStub{ln: ln, password: "password", readOnly: readOnly, authSwitch: authSwitch}
+	go ms.serve()
+	return ms, ln.Addr().(*net.TCPAddr)
+}
+
+var mysqlCheckerTests = []struct {
+	desc       string
+	user       string
+	password   string
+	query      string
+	writable   bool
+	readOnly   string
+	authSwitch bool
+	success    bool
+	message    string
+}{
+	{"greeting", "", "", "", false, "0", false, true, "server greeting received"},
+	{"login", "monitor", "password", "", false, "0", false, true, "server version 8.0.0-stub"},
+	{"query", "monitor", "password", "SELECT 1", false, "0", false, true, "server version 8.0.0-stub"},
+	{"auth switch", "monitor", "password", "SELECT 1", false, "0", true, true, "server version 8.0.0-stub"},
+	{"bad password", "monitor", "wrong", "", false, "0", false, false, "authentication failed"},
+	{"bad query", "monitor", "password", "SELEC 1", false, "0", false, false, "query failed"},
+	{"writable", "monitor", "password", "", true, "0", false, true, "server version 8.0.0-stub"},
+	{"read-only", "monitor", "password", "", true, "1", false, false, "server is read-only"},
+}
+
+func TestMySQLChecker(t *testing.T) {
+	for _, test := range mysqlCheckerTests {
+		_, addr := newMySQLStub(t, test.readOnly, test.authSwitch)
+		hc := NewMySQLChecker(addr.IP, addr.Port)
+		hc.User = test.user
+		hc.Password = test.password
+		hc.Query = test.query
+		hc.ExpectWritable = test.writable
+		result := hc.Check(time.Second)
+		if result.Success != test.success {
+			t.Errorf("%s: got success %v, want %v: %v", test.desc, result.Success, test.success, result)
+		}
+		if !strings.Contains(result.Message, test.message) {
+			t.Errorf("%s: got message %q, want it to contain %q", test.desc, result.Message, test.message)
+		}
+	}
+}
+
+func TestMySQLCheckerQuit(t *testing.T) {
+	ms, addr := newMySQLStub(t, "0", false)
+	hc := NewMySQLChecker(addr.IP, addr.Port)
+	hc.User = "monitor"
+	hc.Password = "password"
+	hc.Query = "SELECT 1"
+	if result := hc.Check(time.Second); !result.Success {
+		t.Fatalf("MySQL healthcheck failed: %v", result)
+	}
+	for i := 0; i < 100 && ms.quits.Load() == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := ms.quits.Load(); got != 1 {
+		t.Errorf("server received %d COM_QUIT commands, want 1", got)
+	}
+}
+
+func TestMySQLCheckerTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer ln.Close()
+	// Accept connections but never send a greeting.
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	hc := NewMySQLChecker(addr.IP, addr.Port)
+	start := time.Now()
+	result := hc.Check(200 * time.Millisecond)
+	if result.Success {
+		t.Fatalf("MySQL healthcheck succeeded without a greeting: %v", result)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("MySQL healthcheck took %v, want it bounded by the timeout", elapsed)
+	}
+}
+
+func TestParseMySQLError(t *testing.T) {
+	b := []byte{mysqlPacketError, 0, 0, '#'}
+	binary.LittleEndian.PutUint16(b[1:3], 1045)
+	b = append(b, "28000Access denied"...)
+	got := parseMySQLError(b).Error()
+	if want := "ERROR 1045 (28000): Access denied"; got != want {
+		t.Errorf("parseMySQLError() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+// notifyQueue holds the notifications that are pending delivery to the
+// Seesaw Engine. State transitions are retained in the order in which they
+// were received, while steady-state notifications are coalesced so that only
+// the most recent notification for each healthcheck is retained.
+type notifyQueue struct {
+	transitions []*Notification
+	steady      map[Id]*Notification
+	order       []Id // Healthchecks with steady-state notifications, oldest first.
+}
+
+// newNotifyQueue returns an initialised notifyQueue.
+func newNotifyQueue() *notifyQueue {
+	return &notifyQueue{steady: make(map[Id]*Notification)}
+}
+
+// len returns the number of queued notifications.
+func (q *notifyQueue) len() int {
+	return len(q.transitions) + len(q.steady)
+}
+
+// full returns true if at least max notifications are queued. A queue is
+// never full if max is not positive.
+func (q *notifyQueue) full(max int) bool {
+	return max > 0 && q.len() >= max
+}
+
+// add queues a notification. Steady-state notifications that are older than
+// a state transition for the same healthcheck are discarded, so that they
+// are never delivered after it.
+func (q *notifyQueue) add(n *Notification) {
+	if n.transition {
+		if s, ok := q.steady[n.Id]; ok && s.seq < n.seq {
+			delete(q.steady, n.Id)
+		}
+		q.transitions = append(q.transitions, n)
+		return
+	}
+	if n.superseded() {
+		return
+	}
+	s, ok := q.steady[n.Id]
+	switch {
+	case !ok:
+		q.order = append(q.order, n.Id)
+	case s.seq > n.seq:
+		return
+	}
+	q.steady[n.Id] = n
+}
+
+// next removes and returns up to max queued notifications, or all queued
+// notifications if max is not positive. State transitions are returned in the
+// order in which they were queued, ahead of any steady-state notifications.
+func (q *notifyQueue) next(max int) []*Notification {
+	if max <= 0 {
+		max = q.len()
+	}
+	n := len(q.transitions)
+	if n > max {
+		n = max
+	}
+	batch := make([]*Notification, 0, max)
+	batch = append(batch, q.transitions[:n]...)
+	q.transitions = q.transitions[n:]
+	for len(batch) < max && len(q.order) > 0 {
+		id := q.order[0]
+		q.order = q.order[1:]
+		if s, ok := q.steady[id]; ok {
+			batch = append(batch, s)
+			delete(q.steady, id)
+		}
+	}
+	return batch
+}
+
+// superseded returns true if this is a steady-state notification that is
+// older than the most recent state transition for its healthcheck.
+func (n *Notification) superseded() bool {
+	if n.transition || n.check == nil {
+		return false
+	}
+	n.check.lock.RLock()
+	defer n.check.lock.RUnlock()
+	return n.seq < n.check.transitionSeq
+}
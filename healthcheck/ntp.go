@@ -0,0 +1,211 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// NTP healthcheck implementation.
+
+package healthcheck
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/seesaw/common/seesaw"
+)
+
+const (
+	defaultNTPTimeout = 5 * time.Second
+
+	// DefaultNTPMaxStratum is the highest stratum accepted from a
+	// synchronised NTP server.
+	DefaultNTPMaxStratum = 15
+
+	ntpPacketSize    = 48
+	ntpVersion       = 4
+	ntpModeClient    = 3
+	ntpModeServer    = 4
+	ntpLeapNotSync   = 3
+	ntpStratumKoD    = 0
+	ntpEpochOffset   = 2208988800 // Seconds between 1900 and 1970.
+	ntpStratumUnsync = 16
+)
+
+// NTPChecker contains configuration specific to an NTP healthcheck.
+//
+// The healthcheck sends a client mode request and succeeds if the server
+// replies in server mode, is synchronised and has a stratum no higher than
+// MaxStratum. If MaxRootDispersion or MaxOffset are non-zero, the server's
+// root dispersion and the clock offset from the server must not exceed
+// them. The offset is always reported in the result message.
+type NTPChecker struct {
+	Target
+	MaxStratum        int
+	MaxRootDispersion time.Duration
+	MaxOffset         time.Duration
+}
+
+// NewNTPChecker returns an initialised NTPChecker.
+func NewNTPChecker(ip net.IP, port int) *NTPChecker {
+	return &NTPChecker{
+		Target: Target{
+			IP:    ip,
+			Port:  port,
+			Proto: seesaw.IPProtoUDP,
+		},
+		MaxStratum: DefaultNTPMaxStratum,
+	}
+}
+
+// String returns the string representation of an NTP healthcheck.
+func (hc *NTPChecker) String() string {
+	attr := []string{fmt.Sprintf("max stratum %d", hc.MaxStratum)}
+	if hc.MaxRootDispersion > 0 {
+		attr = append(attr, fmt.Sprintf("max root dispersion %v", hc.MaxRootDispersion))
+	}
+	if hc.MaxOffset > 0 {
+		attr = append(attr, fmt.Sprintf("max offset %v", hc.MaxOffset))
+	}
+	return fmt.Sprintf("NTP [%s] %s", strings.Join(attr, "; "), hc.Target)
+}
+
+// ntpTime converts a time to a 64 bit NTP timestamp.
+func ntpTime(t time.Time) uint64 {
+	secs := uint64(t.Unix() + ntpEpochOffset)
+	frac := uint64(t.Nanosecond()) << 32 / uint64(time.Second)
+	return secs<<32 | frac
+}
+
+// ntpTimeToTime converts a 64 bit NTP timestamp to a time.
+func ntpTimeToTime(ts uint64) time.Time {
+	secs := int64(ts>>32) - ntpEpochOffset
+	nsecs := int64((ts & 0xffffffff) * uint64(time.Second) >> 32)
+	return time.Unix(secs, nsecs)
+}
+
+// ntpShortToDuration converts a 32 bit NTP short format value to a
+// duration.
+func ntpShortToDuration(v uint32) time.Duration {
+	return time.Duration(uint64(v) * uint64(time.Second) >> 16)
+}
+
+// ntpResponse is the subset of an NTP response used by the healthcheck.
+type ntpResponse struct {
+	leap           uint8
+	mode           uint8
+	stratum        uint8
+	rootDispersion time.Duration
+	referenceID    [4]byte
+	origin         uint64
+	receive        time.Time
+	transmit       time.Time
+}
+
+func (r *ntpResponse) decode(b []byte) error {
+	if len(b) < ntpPacketSize {
+		return fmt.Errorf("short NTP response of %d bytes", len(b))
+	}
+	r.leap = b[0] >> 6
+	r.mode = b[0] & 0x7
+	r.stratum = b[1]
+	r.rootDispersion = ntpShortToDuration(binary.BigEndian.Uint32(b[8:12]))
+	copy(r.referenceID[:], b[12:16])
+	r.origin = binary.BigEndian.Uint64(b[24:32])
+	r.receive = ntpTimeToTime(binary.BigEndian.Uint64(b[32:40]))
+	r.transmit = ntpTimeToTime(binary.BigEndian.Uint64(b[40:48]))
+	return nil
+}
+
+// Check executes an NTP healthcheck.
+func (hc *NTPChecker) Check(timeout time.Duration) *Result {
+	msg := fmt.Sprintf("NTP query to %s", hc.addr())
+	start := time.Now()
+	if timeout == time.Duration(0) {
+		timeout = defaultNTPTimeout
+	}
+	deadline := start.Add(timeout)
+
+	conn, err := dialUDP(hc.network(), hc.addr(), timeout, hc.Mark)
+	if err != nil {
+		msg = fmt.Sprintf("%s; failed to connect", msg)
+		return complete(start, msg, false, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(deadline); err != nil {
+		msg = fmt.Sprintf("%s; failed to set deadline", msg)
+		return complete(start, msg, false, err)
+	}
+
+	req := make([]byte, ntpPacketSize)
+	req[0] = ntpVersion<<3 | ntpModeClient
+	sent := time.Now()
+	transmit := ntpTime(sent)
+	binary.BigEndian.PutUint64(req[40:48], transmit)
+	if _, err := conn.Write(req); err != nil {
+		msg = fmt.Sprintf("%s; failed to send request", msg)
+		return complete(start, msg, false, err)
+	}
+
+	// Read until a response to our request arrives, ignoring stray
+	// datagrams.
+	var resp ntpResponse
+	buf := make([]byte, 512)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			msg = fmt.Sprintf("%s; failed to read response", msg)
+			return complete(start, msg, false, err)
+		}
+		if resp.decode(buf[:n]) == nil && resp.origin == transmit {
+			break
+		}
+	}
+	received := time.Now()
+
+	if resp.mode != ntpModeServer {
+		msg = fmt.Sprintf("%s; unexpected mode %d in response", msg, resp.mode)
+		return complete(start, msg, false, nil)
+	}
+	if resp.stratum == ntpStratumKoD {
+		msg = fmt.Sprintf("%s; kiss-o'-death %q", msg, strings.TrimRight(string(resp.referenceID[:]), "\x00"))
+		return complete(start, msg, false, nil)
+	}
+	if resp.leap == ntpLeapNotSync || resp.stratum >= ntpStratumUnsync {
+		msg = fmt.Sprintf("%s; server is unsynchronised", msg)
+		return complete(start, msg, false, nil)
+	}
+
+	// The clock offset is ((T2 - T1) + (T3 - T4)) / 2.
+	offset := (resp.receive.Sub(sent) + resp.transmit.Sub(received)) / 2
+	msg = fmt.Sprintf("%s; stratum %d, offset %v, root dispersion %v", msg, resp.stratum, offset, resp.rootDispersion)
+
+	maxStratum := hc.MaxStratum
+	if maxStratum == 0 {
+		maxStratum = DefaultNTPMaxStratum
+	}
+	if int(resp.stratum) > maxStratum {
+		msg = fmt.Sprintf("%s; stratum exceeds %d", msg, maxStratum)
+		return complete(start, msg, false, nil)
+	}
+	if hc.MaxRootDispersion > 0 && resp.rootDispersion > hc.MaxRootDispersion {
+		msg = fmt.Sprintf("%s; root dispersion exceeds %v", msg, hc.MaxRootDispersion)
+		return complete(start, msg, false, nil)
+	}
+	if hc.MaxOffset > 0 && (offset > hc.MaxOffset || offset < -hc.MaxOffset) {
+		msg = fmt.Sprintf("%s; offset exceeds %v", msg, hc.MaxOffset)
+		return complete(start, msg, false, nil)
+	}
+	return complete(start, msg, true, nil)
+}
@@ -0,0 +1,199 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"encoding/binary"
+	"net"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ntpStub is a stub NTP server. Its clock runs offset from the local clock.
+type ntpStub struct {
+	conn           *net.UDPConn
+	leap           uint8
+	mode           uint8
+	stratum        uint8
+	rootDispersion uint32 // NTP short format.
+	kissCode       string
+	offset         time.Duration
+	silent         bool
+}
+
+func (ns *ntpStub) serve() {
+	buf := make([]byte, 512)
+	for {
+		n, raddr, err := ns.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		if ns.silent || n < ntpPacketSize || buf[0]&0x7 != ntpModeClient {
+			continue
+		}
+		now := ntpTime(time.Now().Add(ns.offset))
+		resp := make([]byte, ntpPacketSize)
+		resp[0] = ns.leap<<6 | ntpVersion<<3 | ns.mode
+		resp[1] = ns.stratum
+		binary.BigEndian.PutUint32(resp[8:12], ns.rootDispersion)
+		copy(resp[12:16], ns.kissCode)
+		binary.BigEndian.PutUint64(resp[32:40], now)
+		binary.BigEndian.PutUint64(resp[40:48], now)
+
+		// Send a stray response first, which must be ignored.
+		ns.conn.WriteToUDP(resp, raddr)
+		copy(resp[24:32], buf[40:48])
+		ns.conn.WriteToUDP(resp, raddr)
+	}
+}
+
+var ntpCheckerTests = []struct {
+	desc       string
+	stub       ntpStub
+	maxStratum int
+	maxDisp    time.Duration
+	maxOffset  time.Duration
+	success    bool
+	message    string
+}{
+	{
+		"synchronised",
+		ntpStub{mode: ntpModeServer, stratum: 2, rootDispersion: 0x0100},
+		0, 0, 0,
+		true, "stratum 2, offset",
+	},
+	{
+		"offset exceeded",
+		ntpStub{mode: ntpModeServer, stratum: 2, offset: -time.Hour},
+		0, 0, time.Second,
+		false, "offset exceeds 1s",
+	},
+	{
+		"unsynchronised",
+		ntpStub{leap: ntpLeapNotSync, mode: ntpModeServer, stratum: 2},
+		0, 0, 0,
+		false, "server is unsynchronised",
+	},
+	{
+		"stratum 16",
+		ntpStub{mode: ntpModeServer, stratum: 16},
+		0, 0, 0,
+		false, "server is unsynchronised",
+	},
+	{
+		"stratum exceeded",
+		ntpStub{mode: ntpModeServer, stratum: 5},
+		4, 0, 0,
+		false, "stratum exceeds 4",
+	},
+	{
+		"root dispersion",
+		ntpStub{mode: ntpModeServer, stratum: 2, rootDispersion: 0x8000},
+		0, time.Second, 0,
+		true, "root dispersion 500ms",
+	},
+	{
+		"root dispersion exceeded",
+		ntpStub{mode: ntpModeServer, stratum: 2, rootDispersion: 0x18000},
+		0, time.Second, 0,
+		false, "root dispersion exceeds 1s",
+	},
+	{
+		"kiss-o'-death",
+		ntpStub{mode: ntpModeServer, stratum: 0, kissCode: "RATE"},
+		0, 0, 0,
+		false, `kiss-o'-death "RATE"`,
+	},
+	{
+		"wrong mode",
+		ntpStub{mode: ntpModeClient, stratum: 2},
+		0, 0, 0,
+		false, "unexpected mode 3",
+	},
+	{
+		"no response",
+		ntpStub{silent: true},
+		0, 0, 0,
+		false, "failed to read response",
+	},
+}
+
+func TestNTPChecker(t *testing.T) {
+	for _, test := range ntpCheckerTests {
+		conn, addr, err := newLocalUDPConn("udp4")
+		if err != nil {
+			t.Fatalf("Failed to create local UDP connection: %v", err)
+		}
+		ns := test.stub
+		ns.conn = conn
+		go ns.serve()
+
+		hc := NewNTPChecker(addr.IP, addr.Port)
+		if test.maxStratum != 0 {
+			hc.MaxStratum = test.maxStratum
+		}
+		hc.MaxRootDispersion = test.maxDisp
+		hc.MaxOffset = test.maxOffset
+		result := hc.Check(200 * time.Millisecond)
+		conn.Close()
+		if result.Success != test.success {
+			t.Errorf("%s: got success %v, want %v: %v", test.desc, result.Success, test.success, result)
+		}
+		if !strings.Contains(result.Message, test.message) {
+			t.Errorf("%s: got message %q, want it to contain %q", test.desc, result.Message, test.message)
+		}
+	}
+}
+
+func TestNTPCheckerOffset(t *testing.T) {
+	conn, addr, err := newLocalUDPConn("udp4")
+	if err != nil {
+		t.Fatalf("Failed to create local UDP connection: %v", err)
+	}
+	defer conn.Close()
+	ns := &ntpStub{conn: conn, mode: ntpModeServer, stratum: 2, offset: time.Hour}
+	go ns.serve()
+
+	// A large offset is reported, but does not fail the healthcheck by
+	// default.
+	hc := NewNTPChecker(addr.IP, addr.Port)
+	result := hc.Check(time.Second)
+	if !result.Success {
+		t.Fatalf("NTP healthcheck failed: %v", result)
+	}
+	m := regexp.MustCompile(`offset (\S+),`).FindStringSubmatch(result.Message)
+	if m == nil {
+		t.Fatalf("Message %q does not report the offset", result.Message)
+	}
+	offset, err := time.ParseDuration(m[1])
+	if err != nil {
+		t.Fatalf("Failed to parse offset %q: %v", m[1], err)
+	}
+	if d := offset - time.Hour; d > time.Second || d < -time.Second {
+		t.Errorf("Got offset %v, want about %v", offset, time.Hour)
+	}
+}
+
+func TestNTPTime(t *testing.T) {
+	now := time.Now()
+	if got := ntpTimeToTime(ntpTime(now)); now.Sub(got) > time.Microsecond || got.Sub(now) > time.Microsecond {
+		t.Errorf("NTP timestamp round trip of %v gave %v", now, got)
+	}
+	if got, want := ntpShortToDuration(0x00018000), 1500*time.Millisecond; got != want {
+		t.Errorf("ntpShortToDuration(0x00018000) = %v, want %v", got, want)
+	}
+}
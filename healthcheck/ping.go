@@ -20,10 +20,13 @@ package healthcheck
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"math/rand"
 	"net"
 	"os"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/google/seesaw/common/seesaw"
@@ -33,11 +36,11 @@ const (
 	defaultPingTimeout = time.Second
 )
 
-var nextPingCheckerID uint16
+var nextPingCheckerID uint32
 
 func init() {
 	s := rand.NewSource(int64(os.Getpid()))
-	nextPingCheckerID = uint16(s.Int63() & 0xffff)
+	nextPingCheckerID = uint32(s.Int63() & 0xffff)
 }
 
 // PingChecker contains configuration specific to a ping healthcheck.
@@ -45,6 +48,12 @@ type PingChecker struct {
 	Target
 	ID     uint16
 	Seqnum uint16
+
+	// Unprivileged uses an ICMP datagram socket rather than a raw socket,
+	// which only requires the caller's group to be permitted by the
+	// net.ipv4.ping_group_range sysctl. The kernel replaces the ID with
+	// one that is unique to the socket.
+	Unprivileged bool
 }
 
 // NewPingChecker returns an initialised PingChecker.
@@ -53,8 +62,7 @@ func NewPingChecker(ip net.IP) *PingChecker {
 	if ip.To4() == nil {
 		proto = seesaw.IPProtoICMPv6
 	}
-	id := nextPingCheckerID
-	nextPingCheckerID++
+	id := uint16(atomic.AddUint32(&nextPingCheckerID, 1))
 	return &PingChecker{
 		Target: Target{
 			IP:    ip,
@@ -79,7 +87,12 @@ func (hc *PingChecker) Check(timeout time.Duration) *Result {
 	if timeout == time.Duration(0) {
 		timeout = defaultPingTimeout
 	}
-	err := exchangeICMPEcho(hc.network(), hc.IP, timeout, echo)
+	var err error
+	if hc.Unprivileged {
+		err = exchangeICMPEchoDatagram(hc.IP, timeout, echo, hc.Mark)
+	} else {
+		err = exchangeICMPEcho(hc.network(), hc.IP, timeout, echo, hc.Mark)
+	}
 	success := err == nil
 	return complete(start, msg, success, err)
 }
@@ -158,44 +171,111 @@ func parseICMPEchoReply(msg icmpMsg) (id, seqnum, chksum uint16) {
 	return
 }
 
-func exchangeICMPEcho(network string, ip net.IP, timeout time.Duration, echo icmpMsg) error {
-	c, err := net.ListenPacket(network, "")
+// exchangeICMPEcho sends an ICMP echo request from a raw socket and waits
+// for the matching reply. A mark of zero results in a normal (non-marked)
+// socket.
+func exchangeICMPEcho(network string, ip net.IP, timeout time.Duration, echo icmpMsg, mark int) error {
+	c := &conn{mark: mark}
+	lc := net.ListenConfig{Control: c.control}
+	pc, err := lc.ListenPacket(context.Background(), network, "")
 	if err != nil {
 		return err
 	}
-	defer c.Close()
+	defer pc.Close()
 
-	_, err = c.WriteTo(echo, &net.IPAddr{IP: ip})
+	if _, err := pc.WriteTo(echo, &net.IPAddr{IP: ip}); err != nil {
+		return err
+	}
+	xid, _, _ := parseICMPEchoReply(echo)
+	return readICMPEchoReply(pc, ip, timeout, echo, xid)
+}
+
+// exchangeICMPEchoDatagram sends an ICMP echo request from an unprivileged
+// ICMP datagram socket and waits for the matching reply. The kernel replaces
+// the echo identifier with the socket's local port and only delivers replies
+// carrying that identifier to the socket.
+func exchangeICMPEchoDatagram(ip net.IP, timeout time.Duration, echo icmpMsg, mark int) error {
+	family, proto := syscall.AF_INET, syscall.IPPROTO_ICMP
+	var sa syscall.Sockaddr = &syscall.SockaddrInet4{}
+	if ip.To4() == nil {
+		family, proto = syscall.AF_INET6, syscall.IPPROTO_ICMPV6
+		sa = &syscall.SockaddrInet6{}
+	}
+	fd, err := syscall.Socket(family, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, proto)
 	if err != nil {
+		return os.NewSyscallError("socket", err)
+	}
+	f := os.NewFile(uintptr(fd), "icmp")
+	defer f.Close()
+	if mark != 0 {
+		if err := setSocketMark(fd, mark); err != nil {
+			return err
+		}
+	}
+	if err := syscall.Bind(fd, sa); err != nil {
+		return os.NewSyscallError("bind", err)
+	}
+	pc, err := net.FilePacketConn(f)
+	if err != nil {
+		return err
+	}
+	defer pc.Close()
+
+	if _, err := pc.WriteTo(echo, &net.UDPAddr{IP: ip}); err != nil {
 		return err
 	}
+	laddr, ok := pc.LocalAddr().(*net.UDPAddr)
+	if !ok {
+		return fmt.Errorf("unexpected ICMP socket address %v", pc.LocalAddr())
+	}
+	return readICMPEchoReply(pc, ip, timeout, echo, uint16(laddr.Port))
+}
+
+// addrIP returns the IP address for a raw or datagram socket address.
+func addrIP(addr net.Addr) net.IP {
+	switch addr := addr.(type) {
+	case *net.IPAddr:
+		return addr.IP
+	case *net.UDPAddr:
+		return addr.IP
+	}
+	return nil
+}
+
+// readICMPEchoReply reads from the given socket until an echo reply from ip
+// that matches the echo request and identifier is received.
+func readICMPEchoReply(c net.PacketConn, ip net.IP, timeout time.Duration, echo icmpMsg, xid uint16) error {
+	replyType := byte(ICMP4_ECHO_REPLY)
+	if echo[0] == ICMP6_ECHO_REQUEST {
+		replyType = ICMP6_ECHO_REPLY
+	}
 
 	c.SetDeadline(time.Now().Add(timeout))
 	reply := make([]byte, 256)
 	for {
-		_, addr, err := c.ReadFrom(reply)
+		n, addr, err := c.ReadFrom(reply)
 		if err != nil {
 			return err
 		}
-		if !ip.Equal(net.ParseIP(addr.String())) {
+		if n < 8 || !ip.Equal(addrIP(addr)) {
 			continue
 		}
-		if reply[0] != ICMP4_ECHO_REPLY && reply[0] != ICMP6_ECHO_REPLY {
+		if reply[0] != replyType {
 			continue
 		}
-		xid, xseqnum, _ := parseICMPEchoReply(echo)
+		_, xseqnum, _ := parseICMPEchoReply(echo)
 		rid, rseqnum, rchksum := parseICMPEchoReply(reply)
 		if rid != xid || rseqnum != xseqnum {
 			continue
 		}
 		if reply[0] == ICMP4_ECHO_REPLY {
-			cs := icmpChecksum(reply)
+			cs := icmpChecksum(reply[:n])
 			if cs != 0 {
 				return fmt.Errorf("Bad ICMP checksum: %x", rchksum)
 			}
 		}
-		// IPv6 ICMP checksums are computed and verified by the kernel for raw
-		// sockets, so no application-level validation is needed here.
+		// IPv6 ICMP checksums are computed and verified by the kernel, so no
+		// application-level validation is needed here.
 		break
 	}
 	return nil
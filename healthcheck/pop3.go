@@ -0,0 +1,154 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// POP3 healthcheck implementation.
+
+package healthcheck
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/seesaw/common/seesaw"
+)
+
+const (
+	defaultPOP3Timeout = 10 * time.Second
+
+	// pop3MaxResponseSize bounds the amount of data read from the server
+	// on each connection.
+	pop3MaxResponseSize = 64 * 1024
+)
+
+// POP3Checker contains configuration specific to a POP3 healthcheck.
+//
+// The healthcheck expects a "+OK" greeting and, if User is set, logs in with
+// USER and PASS before sending QUIT. If Secure is set the connection uses
+// implicit TLS, otherwise if StartTLS is set it is upgraded with STLS after
+// the greeting.
+type POP3Checker struct {
+	Target
+	User       string
+	Password   string
+	Secure     bool
+	StartTLS   bool
+	TLSVerify  bool
+	ServerName string // TLS ServerName override. If empty, derived from target address.
+}
+
+// NewPOP3Checker returns an initialised POP3Checker.
+func NewPOP3Checker(ip net.IP, port int) *POP3Checker {
+	return &POP3Checker{
+		Target: Target{
+			IP:    ip,
+			Port:  port,
+			Proto: seesaw.IPProtoTCP,
+		},
+	}
+}
+
+func (hc *POP3Checker) proto() string {
+	if hc.Secure {
+		return "POP3S"
+	}
+	return "POP3"
+}
+
+// String returns the string representation of a POP3 healthcheck.
+func (hc *POP3Checker) String() string {
+	var attr []string
+	if hc.User != "" {
+		attr = append(attr, "user "+hc.User)
+	}
+	if hc.StartTLS && !hc.Secure {
+		attr = append(attr, "stls")
+	}
+	if (hc.Secure || hc.StartTLS) && hc.TLSVerify {
+		attr = append(attr, "verify")
+	}
+	return fmt.Sprintf("%s [%s] %s", hc.proto(), strings.Join(attr, "; "), hc.Target)
+}
+
+// Check executes a POP3 healthcheck.
+func (hc *POP3Checker) Check(timeout time.Duration) *Result {
+	msg := fmt.Sprintf("%s connect to %s", hc.proto(), hc.addr())
+	start := time.Now()
+	if timeout == time.Duration(0) {
+		timeout = defaultPOP3Timeout
+	}
+	deadline := start.Add(timeout)
+
+	conn, err := dialTCP(hc.network(), hc.addr(), timeout, hc.Mark)
+	if err != nil {
+		msg = fmt.Sprintf("%s; failed to connect", msg)
+		return complete(start, msg, false, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(deadline); err != nil {
+		msg = fmt.Sprintf("%s; failed to set deadline", msg)
+		return complete(start, msg, false, err)
+	}
+
+	c := newLineConn(conn, deadline, pop3MaxResponseSize)
+	failed := func(step string, err error) *Result {
+		msg = fmt.Sprintf("%s; %s failed", msg, step)
+		if c.unexpected != "" {
+			msg = fmt.Sprintf("%s (unexpected response %q)", msg, c.unexpected)
+		}
+		return complete(start, msg, false, err)
+	}
+
+	if hc.Secure {
+		tlsConn, err := clientTLS(conn, hc.IP, hc.ServerName, hc.TLSVerify)
+		if err != nil {
+			return failed("TLS handshake", err)
+		}
+		defer tlsConn.Close()
+		c = newLineConn(tlsConn, deadline, pop3MaxResponseSize)
+	}
+
+	if _, err := c.expect("+OK"); err != nil {
+		return failed("greeting", err)
+	}
+
+	if hc.StartTLS && !hc.Secure {
+		if _, err := c.command("STLS", "+OK"); err != nil {
+			return failed("STLS", err)
+		}
+		tlsConn, err := clientTLS(conn, hc.IP, hc.ServerName, hc.TLSVerify)
+		if err != nil {
+			return failed("TLS handshake", err)
+		}
+		defer tlsConn.Close()
+		c = newLineConn(tlsConn, deadline, pop3MaxResponseSize)
+	}
+
+	status := "greeting received"
+	if hc.User != "" {
+		if _, err := c.command("USER "+hc.User, "+OK"); err != nil {
+			return failed("USER", err)
+		}
+		if _, err := c.command("PASS "+hc.Password, "+OK"); err != nil {
+			return failed("PASS", err)
+		}
+		status = "logged in as " + hc.User
+	}
+	msg = fmt.Sprintf("%s; %s", msg, status)
+
+	// Nothing was marked for deletion, so the QUIT reply is not checked.
+	c.command("QUIT", "+OK")
+	return complete(start, msg, true, nil)
+}
@@ -0,0 +1,221 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"bufio"
+	"crypto/tls"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// pop3Stub is a scripted POP3 server.
+type pop3Stub struct {
+	greeting  string
+	user      string
+	pass      string
+	implicit  bool // Use implicit TLS.
+	tlsConfig *tls.Config
+
+	mu       sync.Mutex
+	commands []string
+}
+
+func (ps *pop3Stub) handle(conn net.Conn) {
+	defer func() { conn.Close() }()
+	if ps.implicit {
+		tlsConn := tls.Server(conn, ps.tlsConfig)
+		if err := tlsConn.Handshake(); err != nil {
+			return
+		}
+		conn = tlsConn
+	}
+	io.WriteString(conn, ps.greeting)
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimRight(line, "\r\n")
+		ps.mu.Lock()
+		ps.commands = append(ps.commands, cmd)
+		ps.mu.Unlock()
+
+		switch strings.ToUpper(strings.Fields(cmd + " ")[0]) {
+		case "STLS":
+			io.WriteString(conn, "+OK Begin TLS negotiation\r\n")
+			tlsConn := tls.Server(conn, ps.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			r = bufio.NewReader(conn)
+		case "USER":
+			io.WriteString(conn, ps.user)
+		case "PASS":
+			io.WriteString(conn, ps.pass)
+		case "QUIT":
+			io.WriteString(conn, "+OK Logging out.\r\n")
+			return
+		default:
+			io.WriteString(conn, "-ERR Unknown command.\r\n")
+		}
+	}
+}
+
+const (
+	pop3Greeting = "+OK Dovecot ready.\r\n"
+	pop3OK       = "+OK\r\n"
+)
+
+var pop3CheckerTests = []struct {
+	desc     string
+	stub     *pop3Stub
+	user     string
+	secure   bool
+	starttls bool
+	success  bool
+	message  string
+}{
+	{
+		"greeting",
+		&pop3Stub{greeting: pop3Greeting},
+		"", false, false,
+		true, "greeting received",
+	},
+	{
+		"login",
+		&pop3Stub{greeting: pop3Greeting, user: pop3OK, pass: "+OK Logged in.\r\n"},
+		"mailcheck", false, false,
+		true, "logged in as mailcheck",
+	},
+	{
+		"bad password",
+		&pop3Stub{greeting: pop3Greeting, user: pop3OK, pass: "-ERR [AUTH] Authentication failed.\r\n"},
+		"mailcheck", false, false,
+		false, `PASS failed (unexpected response "-ERR [AUTH] Authentication failed.")`,
+	},
+	{
+		"unknown user",
+		&pop3Stub{greeting: pop3Greeting, user: "-ERR No such user\r\n"},
+		"mailcheck", false, false,
+		false, `USER failed (unexpected response "-ERR No such user")`,
+	},
+	{
+		"error greeting",
+		&pop3Stub{greeting: "-ERR Server busy\r\n"},
+		"", false, false,
+		false, `greeting failed (unexpected response "-ERR Server busy")`,
+	},
+	{
+		"stls",
+		&pop3Stub{greeting: pop3Greeting, user: pop3OK, pass: pop3OK},
+		"mailcheck", false, true,
+		true, "logged in as mailcheck",
+	},
+	{
+		"implicit tls",
+		&pop3Stub{greeting: pop3Greeting, implicit: true},
+		"", true, false,
+		true, "POP3S connect",
+	},
+	{
+		"not pop3",
+		&pop3Stub{greeting: "* OK IMAP4rev1 ready\r\n"},
+		"", false, false,
+		false, `greeting failed (unexpected response "* OK IMAP4rev1 ready")`,
+	},
+}
+
+func TestPOP3Checker(t *testing.T) {
+	cert, _ := newServerCert(t, "pop.example.com")
+	for _, test := range pop3CheckerTests {
+		l, addr, err := newLocalTCPListener("tcp4")
+		if err != nil {
+			t.Fatalf("Failed to create local TCP listener: %v", err)
+		}
+		ps := test.stub
+		ps.tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+		go serveConns(l, ps.handle)
+
+		hc := NewPOP3Checker(addr.IP, addr.Port)
+		hc.User = test.user
+		hc.Password = "secret"
+		hc.Secure = test.secure
+		hc.StartTLS = test.starttls
+		result := hc.Check(time.Second)
+		l.Close()
+		if result.Success != test.success {
+			t.Errorf("%s: got success %v, want %v: %v", test.desc, result.Success, test.success, result)
+		}
+		if !strings.Contains(result.Message, test.message) {
+			t.Errorf("%s: got message %q, want it to contain %q", test.desc, result.Message, test.message)
+		}
+		if test.success {
+			// The stub records QUIT before replying to it.
+			ps.mu.Lock()
+			commands := ps.commands
+			ps.mu.Unlock()
+			if len(commands) == 0 || commands[len(commands)-1] != "QUIT" {
+				t.Errorf("%s: got commands %q, want QUIT last", test.desc, commands)
+			}
+			if test.starttls && commands[0] != "STLS" {
+				t.Errorf("%s: got commands %q, want STLS first", test.desc, commands)
+			}
+			if test.user != "" && !strings.Contains(strings.Join(commands, "\n"), "PASS secret") {
+				t.Errorf("%s: got commands %q, want PASS secret", test.desc, commands)
+			}
+		}
+	}
+}
+
+func TestPOP3CheckerGreetingTimeout(t *testing.T) {
+	l, addr, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to create local TCP listener: %v", err)
+	}
+	defer l.Close()
+	// Accept connections but never send the greeting.
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				io.Copy(io.Discard, conn)
+				conn.Close()
+			}()
+		}
+	}()
+
+	hc := NewPOP3Checker(addr.IP, addr.Port)
+	start := time.Now()
+	result := hc.Check(200 * time.Millisecond)
+	if result.Success {
+		t.Fatalf("POP3 healthcheck succeeded without a greeting: %v", result)
+	}
+	if !strings.Contains(result.Message, "greeting failed") {
+		t.Errorf("got message %q, want it to contain %q", result.Message, "greeting failed")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("POP3 healthcheck took %v, want it bounded by the timeout", elapsed)
+	}
+}
@@ -0,0 +1,529 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// PostgreSQL healthcheck implementation.
+
+package healthcheck
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/seesaw/common/seesaw"
+)
+
+const (
+	defaultPostgresTimeout = 10 * time.Second
+
+	postgresMaxMessageSize  = 1 << 20
+	postgresProtocolVersion = 3 << 16
+	postgresSSLRequestCode  = 80877103
+	postgresRecoveryQuery   = "SELECT pg_is_in_recovery()"
+	postgresSCRAMSHA256     = "SCRAM-SHA-256"
+)
+
+// PostgreSQL authentication request types.
+const (
+	postgresAuthOK           = 0
+	postgresAuthCleartext    = 3
+	postgresAuthMD5          = 5
+	postgresAuthSASL         = 10
+	postgresAuthSASLContinue = 11
+	postgresAuthSASLFinal    = 12
+)
+
+// PostgresChecker contains configuration specific to a PostgreSQL
+// healthcheck.
+//
+// The healthcheck performs the startup handshake as User, authenticating
+// with Password if the server requests it. If Secure is set, TLS is
+// requested and used if the server supports it. If RequireTLS is also set,
+// the healthcheck fails if the server declines TLS. If ExpectPrimary is set,
+// the healthcheck fails if the server is in recovery, i.e. is a standby.
+type PostgresChecker struct {
+	Target
+	User          string
+	Password      string
+	Database      string // Defaults to the user name if empty.
+	Secure        bool
+	RequireTLS    bool
+	TLSVerify     bool
+	ServerName    string // TLS ServerName override. If empty, derived from target address.
+	ExpectPrimary bool
+}
+
+// NewPostgresChecker returns an initialised PostgresChecker.
+func NewPostgresChecker(ip net.IP, port int) *PostgresChecker {
+	return &PostgresChecker{
+		Target: Target{
+			IP:    ip,
+			Port:  port,
+			Proto: seesaw.IPProtoTCP,
+		},
+	}
+}
+
+// String returns the string representation of a PostgreSQL healthcheck.
+func (hc *PostgresChecker) String() string {
+	attr := []string{fmt.Sprintf("user %q", hc.User)}
+	if hc.Database != "" {
+		attr = append(attr, fmt.Sprintf("database %q", hc.Database))
+	}
+	if hc.Secure {
+		attr = append(attr, "secure")
+		if hc.RequireTLS {
+			attr = append(attr, "require")
+		}
+		if hc.TLSVerify {
+			attr = append(attr, "verify")
+		}
+	}
+	if hc.ExpectPrimary {
+		attr = append(attr, "primary")
+	}
+	return fmt.Sprintf("PostgreSQL [%s] %s", strings.Join(attr, "; "), hc.Target)
+}
+
+// postgresError is an ErrorResponse returned by a PostgreSQL server.
+type postgresError struct {
+	Severity string
+	Code     string
+	Message  string
+}
+
+// Error returns the string representation of a PostgreSQL server error.
+func (e *postgresError) Error() string {
+	return fmt.Sprintf("%s: %s (SQLSTATE %s)", e.Severity, e.Message, e.Code)
+}
+
+// authentication reports whether the error is an authentication failure.
+func (e *postgresError) authentication() bool {
+	// Class 28 - Invalid Authorization Specification.
+	return strings.HasPrefix(e.Code, "28")
+}
+
+// parsePostgresError parses the body of an ErrorResponse message.
+func parsePostgresError(b []byte) *postgresError {
+	e := &postgresError{}
+	for len(b) > 0 && b[0] != 0 {
+		field := b[0]
+		i := bytes.IndexByte(b[1:], 0)
+		if i < 0 {
+			break
+		}
+		value := string(b[1 : i+1])
+		b = b[i+2:]
+		switch field {
+		case 'S':
+			e.Severity = value
+		case 'C':
+			e.Code = value
+		case 'M':
+			e.Message = value
+		}
+	}
+	return e
+}
+
+// postgresProtocolError is a malformed or unexpected message from a
+// PostgreSQL server.
+type postgresProtocolError struct {
+	msg string
+}
+
+func (e *postgresProtocolError) Error() string {
+	return e.msg
+}
+
+func postgresProtocolErrorf(format string, a ...interface{}) error {
+	return &postgresProtocolError{fmt.Sprintf(format, a...)}
+}
+
+// postgresConn reads and writes PostgreSQL protocol messages.
+type postgresConn struct {
+	conn net.Conn
+}
+
+// readMessage reads a single message, returning its type and body.
+func (c *postgresConn) readMessage() (byte, []byte, error) {
+	var hdr [5]byte
+	if _, err := io.ReadFull(c.conn, hdr[:]); err != nil {
+		return 0, nil, err
+	}
+	n := int(binary.BigEndian.Uint32(hdr[1:]))
+	if n < 4 || n > postgresMaxMessageSize {
+		return 0, nil, postgresProtocolErrorf("invalid message length %d", n)
+	}
+	b := make([]byte, n-4)
+	if _, err := io.ReadFull(c.conn, b); err != nil {
+		return 0, nil, err
+	}
+	return hdr[0], b, nil
+}
+
+// writeMessage writes a single message. A zero type writes an untyped
+// startup message.
+func (c *postgresConn) writeMessage(typ byte, body []byte) error {
+	var b []byte
+	if typ != 0 {
+		b = append(b, typ)
+	}
+	b = binary.BigEndian.AppendUint32(b, uint32(len(body)+4))
+	return writeFull(c.conn, append(b, body...))
+}
+
+// negotiateTLS sends an SSLRequest and, if the server accepts, performs the
+// TLS handshake. It reports whether TLS is in use.
+func (c *postgresConn) negotiateTLS(config *tls.Config) (bool, error) {
+	if err := c.writeMessage(0, binary.BigEndian.AppendUint32(nil, postgresSSLRequestCode)); err != nil {
+		return false, err
+	}
+	var resp [1]byte
+	if _, err := io.ReadFull(c.conn, resp[:]); err != nil {
+		return false, err
+	}
+	switch resp[0] {
+	case 'N':
+		return false, nil
+	case 'S':
+	case 'E':
+		return false, postgresProtocolErrorf("server rejected SSLRequest")
+	default:
+		return false, postgresProtocolErrorf("unexpected SSLRequest response %q", resp[0])
+	}
+	tlsConn := tls.Client(c.conn, config)
+	if err := tlsConn.Handshake(); err != nil {
+		return false, err
+	}
+	c.conn = tlsConn
+	return true, nil
+}
+
+// postgresMD5Password returns the response to an MD5 password request.
+func postgresMD5Password(user, password string, salt []byte) string {
+	inner := md5.Sum([]byte(password + user))
+	outer := md5.Sum(append([]byte(hex.EncodeToString(inner[:])), salt...))
+	return "md5" + hex.EncodeToString(outer[:])
+}
+
+// postgresSCRAM holds the state of a SCRAM-SHA-256 exchange (RFC 7677).
+type postgresSCRAM struct {
+	password        string
+	clientNonce     string
+	clientFirstBare string
+	serverSignature []byte
+}
+
+func newPostgresSCRAM(password string) (*postgresSCRAM, error) {
+	nonce := make([]byte, 18)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	s := &postgresSCRAM{
+		password:    password,
+		clientNonce: base64.StdEncoding.EncodeToString(nonce),
+	}
+	// The server uses the user name from the startup message.
+	s.clientFirstBare = "n=,r=" + s.clientNonce
+	return s, nil
+}
+
+// clientFirst returns the client-first-message.
+func (s *postgresSCRAM) clientFirst() []byte {
+	return []byte("n,," + s.clientFirstBare)
+}
+
+func scramHMAC(key []byte, msg string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(msg))
+	return mac.Sum(nil)
+}
+
+// clientFinal returns the client-final-message for the given
+// server-first-message.
+func (s *postgresSCRAM) clientFinal(serverFirst []byte) ([]byte, error) {
+	var nonce, salt string
+	iterations := 0
+	for _, attr := range strings.Split(string(serverFirst), ",") {
+		k, v, ok := strings.Cut(attr, "=")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "r":
+			nonce = v
+		case "s":
+			salt = v
+		case "i":
+			iterations, _ = strconv.Atoi(v)
+		}
+	}
+	if !strings.HasPrefix(nonce, s.clientNonce) || len(nonce) == len(s.clientNonce) {
+		return nil, postgresProtocolErrorf("invalid SCRAM server nonce")
+	}
+	saltBytes, err := base64.StdEncoding.DecodeString(salt)
+	if err != nil || len(saltBytes) == 0 {
+		return nil, postgresProtocolErrorf("invalid SCRAM salt")
+	}
+	if iterations <= 0 {
+		return nil, postgresProtocolErrorf("invalid SCRAM iteration count")
+	}
+
+	salted, err := pbkdf2.Key(sha256.New, s.password, saltBytes, iterations, sha256.Size)
+	if err != nil {
+		return nil, err
+	}
+	clientKey := scramHMAC(salted, "Client Key")
+	storedKey := sha256.Sum256(clientKey)
+	clientFinalBare := "c=biws,r=" + nonce
+	authMessage := s.clientFirstBare + "," + string(serverFirst) + "," + clientFinalBare
+	proof := scramHMAC(storedKey[:], authMessage)
+	for i := range proof {
+		proof[i] ^= clientKey[i]
+	}
+	s.serverSignature = scramHMAC(scramHMAC(salted, "Server Key"), authMessage)
+	return []byte(clientFinalBare + ",p=" + base64.StdEncoding.EncodeToString(proof)), nil
+}
+
+// verifyServerFinal verifies the server-final-message.
+func (s *postgresSCRAM) verifyServerFinal(serverFinal []byte) error {
+	v, ok := strings.CutPrefix(string(serverFinal), "v=")
+	if !ok {
+		return postgresProtocolErrorf("invalid SCRAM server-final-message")
+	}
+	sig, err := base64.StdEncoding.DecodeString(v)
+	if err != nil || !hmac.Equal(sig, s.serverSignature) {
+		return postgresProtocolErrorf("SCRAM server signature mismatch")
+	}
+	return nil
+}
+
+// startup performs the startup handshake, returning the server version
+// once the server is ready for queries.
+func (c *postgresConn) startup(user, password, database string) (string, error) {
+	var b []byte
+	b = binary.BigEndian.AppendUint32(b, postgresProtocolVersion)
+	for _, p := range [][2]string{{"user", user}, {"database", database}, {"application_name", "seesaw"}} {
+		if p[1] == "" {
+			continue
+		}
+		b = append(b, p[0]...)
+		b = append(b, 0)
+		b = append(b, p[1]...)
+		b = append(b, 0)
+	}
+	if err := c.writeMessage(0, append(b, 0)); err != nil {
+		return "", err
+	}
+
+	var scram *postgresSCRAM
+	var version string
+	for {
+		typ, b, err := c.readMessage()
+		if err != nil {
+			return "", err
+		}
+		switch typ {
+		case 'E':
+			return "", parsePostgresError(b)
+		case 'R':
+			if len(b) < 4 {
+				return "", postgresProtocolErrorf("short authentication request")
+			}
+			code, data := binary.BigEndian.Uint32(b), b[4:]
+			switch code {
+			case postgresAuthOK:
+			case postgresAuthCleartext:
+				err = c.writeMessage('p', append([]byte(password), 0))
+			case postgresAuthMD5:
+				if len(data) != 4 {
+					return "", postgresProtocolErrorf("invalid MD5 salt")
+				}
+				err = c.writeMessage('p', append([]byte(postgresMD5Password(user, password, data)), 0))
+			case postgresAuthSASL:
+				mechanisms := strings.Split(string(bytes.TrimRight(data, "\x00")), "\x00")
+				found := false
+				for _, m := range mechanisms {
+					found = found || m == postgresSCRAMSHA256
+				}
+				if !found {
+					return "", fmt.Errorf("unsupported SASL mechanisms %q", mechanisms)
+				}
+				if scram, err = newPostgresSCRAM(password); err != nil {
+					return "", err
+				}
+				first := scram.clientFirst()
+				msg := append([]byte(postgresSCRAMSHA256), 0)
+				msg = binary.BigEndian.AppendUint32(msg, uint32(len(first)))
+				err = c.writeMessage('p', append(msg, first...))
+			case postgresAuthSASLContinue:
+				if scram == nil {
+					return "", postgresProtocolErrorf("unexpected SASL continue")
+				}
+				final, ferr := scram.clientFinal(data)
+				if ferr != nil {
+					return "", ferr
+				}
+				err = c.writeMessage('p', final)
+			case postgresAuthSASLFinal:
+				if scram == nil {
+					return "", postgresProtocolErrorf("unexpected SASL final")
+				}
+				err = scram.verifyServerFinal(data)
+			default:
+				return "", fmt.Errorf("unsupported authentication request %d", code)
+			}
+			if err != nil {
+				return "", err
+			}
+		case 'S':
+			if name, value, ok := bytes.Cut(b, []byte{0}); ok && string(name) == "server_version" {
+				version = string(bytes.TrimRight(value, "\x00"))
+			}
+		case 'K', 'N':
+			// Backend key data and notices.
+		case 'Z':
+			return version, nil
+		default:
+			return "", postgresProtocolErrorf("unexpected message type %q during startup", typ)
+		}
+	}
+}
+
+// query runs a simple query and returns the first column of the first row.
+func (c *postgresConn) query(query string) (string, error) {
+	if err := c.writeMessage('Q', append([]byte(query), 0)); err != nil {
+		return "", err
+	}
+	var value string
+	var qerr error
+	for {
+		typ, b, err := c.readMessage()
+		if err != nil {
+			return "", err
+		}
+		switch typ {
+		case 'E':
+			qerr = parsePostgresError(b)
+		case 'D':
+			if len(b) < 6 || binary.BigEndian.Uint16(b) < 1 {
+				return "", postgresProtocolErrorf("malformed data row")
+			}
+			n := int32(binary.BigEndian.Uint32(b[2:]))
+			if n >= 0 && int(n) <= len(b)-6 {
+				value = string(b[6 : 6+n])
+			}
+		case 'T', 'C', 'N', 'S', 'I':
+			// Row description, command completion, notices, parameter
+			// changes and empty queries.
+		case 'Z':
+			return value, qerr
+		default:
+			return "", postgresProtocolErrorf("unexpected message type %q during query", typ)
+		}
+	}
+}
+
+// Check executes a PostgreSQL healthcheck.
+func (hc *PostgresChecker) Check(timeout time.Duration) *Result {
+	msg := fmt.Sprintf("PostgreSQL connect to %s", hc.addr())
+	start := time.Now()
+	if timeout == time.Duration(0) {
+		timeout = defaultPostgresTimeout
+	}
+	deadline := start.Add(timeout)
+
+	conn, err := dialTCP(hc.network(), hc.addr(), timeout, hc.Mark)
+	if err != nil {
+		msg = fmt.Sprintf("%s; failed to connect", msg)
+		return complete(start, msg, false, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(deadline); err != nil {
+		msg = fmt.Sprintf("%s; failed to set deadline", msg)
+		return complete(start, msg, false, err)
+	}
+
+	c := &postgresConn{conn: conn}
+	if hc.Secure || hc.RequireTLS {
+		serverName := hc.ServerName
+		if serverName == "" {
+			serverName = hc.IP.String()
+		}
+		secure, err := c.negotiateTLS(&tls.Config{
+			InsecureSkipVerify: !hc.TLSVerify,
+			ServerName:         serverName,
+		})
+		if err != nil {
+			msg = fmt.Sprintf("%s; TLS negotiation failed", msg)
+			return complete(start, msg, false, err)
+		}
+		if !secure && hc.RequireTLS {
+			msg = fmt.Sprintf("%s; server does not support TLS", msg)
+			return complete(start, msg, false, nil)
+		}
+		if secure {
+			// The TLS connection is closed before the TCP connection.
+			defer c.conn.Close()
+		}
+	}
+
+	version, err := c.startup(hc.User, hc.Password, hc.Database)
+	if err != nil {
+		var pgErr *postgresError
+		switch {
+		case errors.As(err, &pgErr) && pgErr.authentication():
+			msg = fmt.Sprintf("%s; authentication failed", msg)
+		case errors.As(err, &pgErr):
+			msg = fmt.Sprintf("%s; startup failed", msg)
+		default:
+			msg = fmt.Sprintf("%s; protocol error", msg)
+		}
+		return complete(start, msg, false, err)
+	}
+	// Terminate the session once finished, so that the server does not
+	// log an unexpected EOF.
+	defer c.writeMessage('X', nil)
+	if version != "" {
+		msg = fmt.Sprintf("%s; server version %s", msg, version)
+	}
+
+	if hc.ExpectPrimary {
+		recovery, err := c.query(postgresRecoveryQuery)
+		if err != nil {
+			msg = fmt.Sprintf("%s; recovery check failed", msg)
+			return complete(start, msg, false, err)
+		}
+		if recovery != "f" {
+			msg = fmt.Sprintf("%s; server is in recovery", msg)
+			return complete(start, msg, false, nil)
+		}
+	}
+	return complete(start, msg, true, nil)
+}
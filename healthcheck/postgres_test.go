@@ -0,0 +1,341 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// postgresStub is a stub PostgreSQL server. It authenticates users with the
+// configured method and answers the recovery query.
+type postgresStub struct {
+	ln         net.Listener
+	auth       string // "trust", "password", "md5" or "scram".
+	password   string
+	tlsConfig  *tls.Config
+	recovery   bool
+	terminates atomic.Int32
+}
+
+func (ps *postgresStub) serve() {
+	for {
+		conn, err := ps.ln.Accept()
+		if err != nil {
+			return
+		}
+		go ps.handle(conn)
+	}
+}
+
+func (ps *postgresStub) sendAuth(c *postgresConn, code uint32, data string) {
+	c.writeMessage('R', append(binary.BigEndian.AppendUint32(nil, code), data...))
+}
+
+func (ps *postgresStub) sendError(c *postgresConn, code, message string) {
+	c.writeMessage('E', []byte("SFATAL\x00C"+code+"\x00M"+message+"\x00\x00"))
+}
+
+// readStartup reads an untyped startup message.
+func (ps *postgresStub) readStartup(conn net.Conn) ([]byte, error) {
+	var hdr [4]byte
+	if _, err := io.ReadFull(conn, hdr[:]); err != nil {
+		return nil, err
+	}
+	b := make([]byte, binary.BigEndian.Uint32(hdr[:])-4)
+	_, err := io.ReadFull(conn, b)
+	return b, err
+}
+
+// authenticate runs the configured authentication exchange, reporting
+// whether it succeeded.
+func (ps *postgresStub) authenticate(c *postgresConn, user string) bool {
+	switch ps.auth {
+	case "trust":
+		return true
+	case "password":
+		ps.sendAuth(c, postgresAuthCleartext, "")
+		_, b, err := c.readMessage()
+		return err == nil && string(b) == ps.password+"\x00"
+	case "md5":
+		salt := "salt"
+		ps.sendAuth(c, postgresAuthMD5, salt)
+		_, b, err := c.readMessage()
+		return err == nil && string(b) == postgresMD5Password(user, ps.password, []byte(salt))+"\x00"
+	case "scram":
+		ps.sendAuth(c, postgresAuthSASL, postgresSCRAMSHA256+"\x00\x00")
+		_, b, err := c.readMessage()
+		if err != nil {
+			return false
+		}
+		mechanism, rest, _ := bytes.Cut(b, []byte{0})
+		if string(mechanism) != postgresSCRAMSHA256 || len(rest) < 4 {
+			return false
+		}
+		clientFirstBare, ok := strings.CutPrefix(string(rest[4:]), "n,,")
+		if !ok {
+			return false
+		}
+		_, clientNonce, _ := strings.Cut(clientFirstBare, "r=")
+		salt := []byte("saltsalt")
+		nonce := clientNonce + "server"
+		serverFirst := "r=" + nonce + ",s=" + base64.StdEncoding.EncodeToString(salt) + ",i=4096"
+		ps.sendAuth(c, postgresAuthSASLContinue, serverFirst)
+
+		if _, b, err = c.readMessage(); err != nil {
+			return false
+		}
+		clientFinalBare, proof, ok := strings.Cut(string(b), ",p=")
+		if !ok || clientFinalBare != "c=biws,r="+nonce {
+			return false
+		}
+		proofBytes, err := base64.StdEncoding.DecodeString(proof)
+		if err != nil || len(proofBytes) != sha256.Size {
+			return false
+		}
+		salted, _ := pbkdf2.Key(sha256.New, ps.password, salt, 4096, sha256.Size)
+		storedKey := sha256.Sum256(scramHMAC(salted, "Client Key"))
+		authMessage := clientFirstBare + "," + serverFirst + "," + clientFinalBare
+		clientKey := scramHMAC(storedKey[:], authMessage)
+		for i := range clientKey {
+			clientKey[i] ^= proofBytes[i]
+		}
+		if got := sha256.Sum256(clientKey); !hmac.Equal(got[:], storedKey[:]) {
+			return false
+		}
+		serverSignature := scramHMAC(scramHMAC(salted, "Server Key"), authMessage)
+		ps.sendAuth(c, postgresAuthSASLFinal, "v="+base64.StdEncoding.EncodeToString(serverSignature))
+		return true
+	}
+	return false
+}
+
+func (ps *postgresStub) handle(conn net.Conn) {
+	defer conn.Close()
+	b, err := ps.readStartup(conn)
+	if err != nil || len(b) < 4 {
+		return
+	}
+	if binary.BigEndian.Uint32(b) == postgresSSLRequestCode {
+		if ps.tlsConfig == nil {
+			conn.Write([]byte{'N'})
+		} else {
+			conn.Write([]byte{'S'})
+			tlsConn := tls.Server(conn, ps.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+		}
+		if b, err = ps.readStartup(conn); err != nil || len(b) < 4 {
+			return
+		}
+	}
+	params := strings.Split(string(b[4:]), "\x00")
+	var user string
+	for i := 0; i+1 < len(params); i += 2 {
+		if params[i] == "user" {
+			user = params[i+1]
+		}
+	}
+
+	c := &postgresConn{conn: conn}
+	if !ps.authenticate(c, user) {
+		ps.sendError(c, "28P01", `password authentication failed for user "`+user+`"`)
+		return
+	}
+	ps.sendAuth(c, postgresAuthOK, "")
+	c.writeMessage('S', []byte("server_version\x0016.2-stub\x00"))
+	c.writeMessage('K', make([]byte, 8))
+	c.writeMessage('Z', []byte{'I'})
+
+	for {
+		typ, b, err := c.readMessage()
+		if err != nil {
+			return
+		}
+		switch {
+		case typ == 'X':
+			ps.terminates.Add(1)
+			return
+		case typ == 'Q' && string(b) == postgresRecoveryQuery+"\x00":
+			value := "f"
+			if ps.recovery {
+				value = "t"
+			}
+			c.writeMessage('T', []byte("\x00\x01pg_is_in_recovery\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10\x00\x01\xff\xff\xff\xff\x00\x00"))
+			row := binary.BigEndian.AppendUint16(nil, 1)
+			row = binary.BigEndian.AppendUint32(row, uint32(len(value)))
+			c.writeMessage('D', append(row, value...))
+			c.writeMessage('C', []byte("SELECT 1\x00"))
+			c.writeMessage('Z', []byte{'I'})
+		default:
+			ps.sendError(c, "42601", "syntax error")
+			c.writeMessage('Z', []byte{'I'})
+		}
+	}
+}
+
+func newPostgresStub(t *testing.T, ps *postgresStub) *net.TCPAddr {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	ps.ln = ln
+	go ps.serve()
+	return ln.Addr().(*net.TCPAddr)
+}
+
+var postgresCheckerTests = []struct {
+	desc       string
+	auth       string
+	password   string
+	serverTLS  bool
+	recovery   bool
+	secure     bool
+	requireTLS bool
+	primary    bool
+	success    bool
+	message    string
+}{
+	{"trust", "trust", "", false, false, false, false, false, true, "server version 16.2-stub"},
+	{"cleartext", "password", "password", false, false, false, false, false, true, "server version 16.2-stub"},
+	{"md5", "md5", "password", false, false, false, false, false, true, "server version 16.2-stub"},
+	{"md5 bad password", "md5", "wrong", false, false, false, false, false, false, "authentication failed"},
+	{"scram", "scram", "password", false, false, false, false, false, true, "server version 16.2-stub"},
+	{"scram bad password", "scram", "wrong", false, false, false, false, false, false, "authentication failed"},
+	{"primary", "trust", "", false, false, false, false, true, true, "server version 16.2-stub"},
+	{"in recovery", "trust", "", false, true, false, false, true, false, "server is in recovery"},
+	{"tls", "scram", "password", true, false, true, true, false, true, "server version 16.2-stub"},
+	{"tls preferred", "trust", "", false, false, true, false, false, true, "server version 16.2-stub"},
+	{"tls required", "trust", "", false, false, true, true, false, false, "server does not support TLS"},
+}
+
+func TestPostgresChecker(t *testing.T) {
+	cert, _ := newServerCert(t, "db.example.com")
+	for _, test := range postgresCheckerTests {
+		ps := &postgresStub{auth: test.auth, password: "password", recovery: test.recovery}
+		if test.serverTLS {
+			ps.tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+		}
+		addr := newPostgresStub(t, ps)
+		hc := NewPostgresChecker(addr.IP, addr.Port)
+		hc.User = "seesaw"
+		hc.Password = test.password
+		hc.Secure = test.secure
+		hc.RequireTLS = test.requireTLS
+		hc.ExpectPrimary = test.primary
+		result := hc.Check(time.Second)
+		if result.Success != test.success {
+			t.Errorf("%s: got success %v, want %v: %v", test.desc, result.Success, test.success, result)
+		}
+		if !strings.Contains(result.Message, test.message) {
+			t.Errorf("%s: got message %q, want it to contain %q", test.desc, result.Message, test.message)
+		}
+	}
+}
+
+func TestPostgresCheckerTerminate(t *testing.T) {
+	ps := &postgresStub{auth: "trust"}
+	addr := newPostgresStub(t, ps)
+	hc := NewPostgresChecker(addr.IP, addr.Port)
+	hc.User = "seesaw"
+	hc.ExpectPrimary = true
+	if result := hc.Check(time.Second); !result.Success {
+		t.Fatalf("PostgreSQL healthcheck failed: %v", result)
+	}
+	for i := 0; i < 100 && ps.terminates.Load() == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := ps.terminates.Load(); got != 1 {
+		t.Errorf("server received %d Terminate messages, want 1", got)
+	}
+}
+
+func TestPostgresCheckerProtocolError(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte("HTTP/1.1 400 Bad Request\r\n\r\n"))
+			conn.Close()
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	hc := NewPostgresChecker(addr.IP, addr.Port)
+	hc.User = "seesaw"
+	result := hc.Check(time.Second)
+	if result.Success {
+		t.Fatalf("PostgreSQL healthcheck succeeded against a non-PostgreSQL server: %v", result)
+	}
+	if !strings.Contains(result.Message, "protocol error") {
+		t.Errorf("got message %q, want it to contain %q", result.Message, "protocol error")
+	}
+}
+
+func TestPostgresCheckerTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer ln.Close()
+	// Accept connections but never respond.
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				io.Copy(io.Discard, conn)
+				conn.Close()
+			}()
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	hc := NewPostgresChecker(addr.IP, addr.Port)
+	hc.User = "seesaw"
+	hc.Secure = true
+	start := time.Now()
+	result := hc.Check(200 * time.Millisecond)
+	if result.Success {
+		t.Fatalf("PostgreSQL healthcheck succeeded without a response: %v", result)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("PostgreSQL healthcheck took %v, want it bounded by the timeout", elapsed)
+	}
+}
@@ -20,6 +20,7 @@ package healthcheck
 
 import (
 	"bytes"
+	"crypto/hmac"
 	"crypto/md5"
 	cryptorand "crypto/rand"
 	"encoding/binary"
@@ -128,6 +129,8 @@ const (
 	ratServiceType   radiusAttributeType = 6
 	ratNASIdentifier radiusAttributeType = 32
 	ratNASPortType   radiusAttributeType = 61
+
+	ratMessageAuthenticator radiusAttributeType = 80
 )
 
 var ratNames = map[radiusAttributeType]string{
@@ -138,6 +141,8 @@ var ratNames = map[radiusAttributeType]string{
 	ratServiceType:   "Service-Type",
 	ratNASIdentifier: "NAS-Identifier",
 	ratNASPortType:   "NAS-Port-Type",
+
+	ratMessageAuthenticator: "Message-Authenticator",
 }
 
 // String returns the string representation of a RADIUS attribute type.
@@ -281,6 +286,17 @@ func responseAuthenticator(rp *radiusPacket, requestAuthenticator *radiusAuthent
 	return &authenticator, nil
 }
 
+// addMessageAuthenticator adds a Message-Authenticator attribute to a RADIUS
+// packet, as per RFC3579 section 3.2. It must be the last attribute added.
+func (rp *radiusPacket) addMessageAuthenticator(secret string) {
+	ra := &radiusAttribute{raType: ratMessageAuthenticator}
+	ra.value = make([]byte, md5.Size)
+	rp.addAttribute(ra)
+	mac := hmac.New(md5.New, []byte(secret))
+	mac.Write(rp.encode())
+	ra.value = mac.Sum(nil)
+}
+
 // RADIUSChecker contains configuration specific to a RADIUS healthcheck.
 type RADIUSChecker struct {
 	Target
@@ -289,6 +305,10 @@ type RADIUSChecker struct {
 	Secret           string
 	Response         string
 	SkipResponseAuth bool // Skip response authenticator validation (allows checks without a valid secret).
+
+	// StatusServer sends a Status-Server request (RFC5997) instead of an
+	// Access-Request, which does not require a username or password.
+	StatusServer bool
 }
 
 // NewRADIUSChecker returns an initialised RADIUSChecker.
@@ -310,7 +330,11 @@ func (hc *RADIUSChecker) String() string {
 
 // Check executes a RADIUS healthcheck.
 func (hc *RADIUSChecker) Check(timeout time.Duration) *Result {
-	msg := fmt.Sprintf("RADIUS %s to port %d", rcAccessRequest, hc.Port)
+	code := rcAccessRequest
+	if hc.StatusServer {
+		code = rcStatusServer
+	}
+	msg := fmt.Sprintf("RADIUS %s to port %d", code, hc.Port)
 	start := time.Now()
 	if timeout == time.Duration(0) {
 		timeout = defaultRADIUSTimeout
@@ -323,7 +347,7 @@ func (hc *RADIUSChecker) Check(timeout time.Duration) *Result {
 	}
 	defer conn.Close()
 
-	// Build a RADIUS Access-Request packet.
+	// Build a RADIUS Access-Request or Status-Server packet.
 	authenticator, err := newRADIUSAuthenticator()
 	if err != nil {
 		return complete(start, msg, false, err)
@@ -331,7 +355,7 @@ func (hc *RADIUSChecker) Check(timeout time.Duration) *Result {
 	identifier := newRADIUSIdentifier()
 	rp := &radiusPacket{
 		radiusHeader: radiusHeader{
-			Code:       rcAccessRequest,
+			Code:       code,
 			Identifier: identifier,
 		},
 	}
@@ -346,33 +370,38 @@ func (hc *RADIUSChecker) Check(timeout time.Duration) *Result {
 	ra.value = []byte(hostname)
 	rp.addAttribute(ra)
 
-	// Username.
-	ra = &radiusAttribute{raType: ratUserName}
-	ra.value = []byte(hc.Username)
-	rp.addAttribute(ra)
-
-	// User Password.
-	ra = &radiusAttribute{raType: ratUserPassword}
-	ra.value = radiusPassword(hc.Password, hc.Secret, authenticator)
-	rp.addAttribute(ra)
+	if hc.StatusServer {
+		// Status-Server requests must be authenticated with a
+		// Message-Authenticator, as per RFC5997 section 3.
+		rp.addMessageAuthenticator(hc.Secret)
+	} else {
+		// Username.
+		ra = &radiusAttribute{raType: ratUserName}
+		ra.value = []byte(hc.Username)
+		rp.addAttribute(ra)
 
-	// NAS IP Address.
-	nasIP := net.ParseIP(conn.LocalAddr().String())
-	if ip := nasIP.To4(); ip != nil {
-		ra := &radiusAttribute{raType: ratNASIPAddress}
-		ra.value = ip
+		// User Password.
+		ra = &radiusAttribute{raType: ratUserPassword}
+		ra.value = radiusPassword(hc.Password, hc.Secret, authenticator)
 		rp.addAttribute(ra)
-	}
 
-	// NAS Port Type (virtual).
-	ra = &radiusAttribute{raType: ratNASPortType}
-	ra.value = []byte{0x0, 0x0, 0x0, 0x5}
-	rp.addAttribute(ra)
+		// NAS IP Address.
+		if laddr, ok := conn.LocalAddr().(*net.UDPAddr); ok && laddr.IP.To4() != nil {
+			ra := &radiusAttribute{raType: ratNASIPAddress}
+			ra.value = laddr.IP.To4()
+			rp.addAttribute(ra)
+		}
 
-	// Service Type (login).
-	ra = &radiusAttribute{raType: ratServiceType}
-	ra.value = []byte{0x0, 0x0, 0x0, 0x1}
-	rp.addAttribute(ra)
+		// NAS Port Type (virtual).
+		ra = &radiusAttribute{raType: ratNASPortType}
+		ra.value = []byte{0x0, 0x0, 0x0, 0x5}
+		rp.addAttribute(ra)
+
+		// Service Type (login).
+		ra = &radiusAttribute{raType: ratServiceType}
+		ra.value = []byte{0x0, 0x0, 0x0, 0x1}
+		rp.addAttribute(ra)
+	}
 
 	// Send a RADIUS request.
 	rpb := rp.encode()
@@ -18,9 +18,13 @@ package healthcheck
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"net"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 var (
@@ -306,3 +310,118 @@ func TestRADIUSPacket(t *testing.T) {
 		}
 	}
 }
+
+// radiusStub is a stub RADIUS server. It accepts Access-Requests carrying
+// the expected password and Status-Server requests with a valid
+// Message-Authenticator, rejects other Access-Requests and silently discards
+// invalid Status-Server requests.
+type radiusStub struct {
+	conn     *net.UDPConn
+	secret   string
+	password string
+}
+
+func (rs *radiusStub) validMessageAuthenticator(rp *radiusPacket) bool {
+	for _, ra := range rp.attributes {
+		if ra.raType != ratMessageAuthenticator {
+			continue
+		}
+		got := ra.value
+		ra.value = make([]byte, md5.Size)
+		mac := hmac.New(md5.New, []byte(rs.secret))
+		mac.Write(rp.encode())
+		ra.value = got
+		return hmac.Equal(got, mac.Sum(nil))
+	}
+	return false
+}
+
+func (rs *radiusStub) serve() {
+	buf := make([]byte, radiusMaximumSize)
+	for {
+		n, addr, err := rs.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		req := &radiusPacket{}
+		if err := req.decode(bytes.NewReader(buf[:n])); err != nil {
+			continue
+		}
+		code := rcAccessReject
+		switch req.Code {
+		case rcAccessRequest:
+			want := radiusPassword(rs.password, rs.secret, &req.Authenticator)
+			for _, ra := range req.attributes {
+				if ra.raType == ratUserPassword && bytes.Equal(ra.value, want) {
+					code = rcAccessAccept
+				}
+			}
+		case rcStatusServer:
+			if !rs.validMessageAuthenticator(req) {
+				continue
+			}
+			code = rcAccessAccept
+		default:
+			continue
+		}
+		resp := &radiusPacket{
+			radiusHeader: radiusHeader{
+				Code:       code,
+				Identifier: req.Identifier,
+			},
+		}
+		resp.encode()
+		auth, err := responseAuthenticator(resp, &req.Authenticator, rs.secret)
+		if err != nil {
+			continue
+		}
+		resp.Authenticator = *auth
+		rs.conn.WriteTo(resp.encode(), addr)
+	}
+}
+
+var radiusCheckerTests = []struct {
+	desc         string
+	statusServer bool
+	password     string
+	secret       string
+	response     string
+	expected     bool
+	msg          string
+}{
+	{"accept", false, "password", "secret", "accept", true, "got RADIUS Access-Accept response"},
+	{"any", false, "password", "secret", "any", true, "got RADIUS Access-Accept response"},
+	{"wrong password", false, "wrong", "secret", "accept", false, "want accept response"},
+	{"expected reject", false, "wrong", "secret", "reject", true, "got RADIUS Access-Reject response"},
+	{"wrong secret", false, "password", "wrong", "accept", false, "response authenticator mismatch"},
+	{"status server", true, "", "secret", "accept", true, "RADIUS Status-Server to port"},
+	{"status server wrong secret", true, "", "wrong", "accept", false, "failed to read response"},
+}
+
+func TestRADIUSChecker(t *testing.T) {
+	c, a, err := newLocalUDPConn("udp4")
+	if err != nil {
+		t.Fatalf("Failed to get UDPConn: %v", err)
+	}
+	defer c.Close()
+	rs := &radiusStub{conn: c, secret: "secret", password: "password"}
+	go rs.serve()
+
+	for _, test := range radiusCheckerTests {
+		hc := NewRADIUSChecker(a.IP, a.Port)
+		hc.Username = "monitor"
+		hc.Password = test.password
+		hc.Secret = test.secret
+		hc.Response = test.response
+		hc.StatusServer = test.statusServer
+		result := hc.Check(200 * time.Millisecond)
+		if result.Success != test.expected {
+			t.Errorf("%s: RADIUS healthcheck %v got success %v, want %v: %v",
+				test.desc, hc, result.Success, test.expected, result)
+		}
+		if !strings.Contains(result.Message, test.msg) {
+			t.Errorf("%s: RADIUS healthcheck %v message %q does not contain %q",
+				test.desc, hc, result.Message, test.msg)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Redis healthcheck implementation.
+
+package healthcheck
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/seesaw/common/seesaw"
+)
+
+const (
+	defaultRedisTimeout = 10 * time.Second
+
+	redisMaxBulkLength  = 1 << 20
+	redisMaxArrayLength = 1024
+	redisMaxLineLength  = 64 * 1024
+)
+
+// RedisChecker contains configuration specific to a Redis healthcheck.
+//
+// The healthcheck sends AUTH if a Password is set, followed by PING, and
+// succeeds on PONG. If RequireRole is set to "master" or "replica", the
+// healthcheck also fails unless the ROLE command reports that role.
+type RedisChecker struct {
+	Target
+	Username    string // ACL user name for AUTH, if any.
+	Password    string
+	RequireRole string
+}
+
+// NewRedisChecker returns an initialised RedisChecker.
+func NewRedisChecker(ip net.IP, port int) *RedisChecker {
+	return &RedisChecker{
+		Target: Target{
+			IP:    ip,
+			Port:  port,
+			Proto: seesaw.IPProtoTCP,
+		},
+	}
+}
+
+// String returns the string representation of a Redis healthcheck.
+func (hc *RedisChecker) String() string {
+	attr := []string{}
+	if hc.Password != "" {
+		attr = append(attr, "auth")
+	}
+	if hc.RequireRole != "" {
+		attr = append(attr, "role "+hc.RequireRole)
+	}
+	var s string
+	if len(attr) > 0 {
+		s = fmt.Sprintf(" [%s]", strings.Join(attr, "; "))
+	}
+	return fmt.Sprintf("Redis%s %s", s, hc.Target)
+}
+
+// redisError is an error reply from a Redis server, such as
+// "LOADING Redis is loading the dataset in memory".
+type redisError string
+
+func (e redisError) Error() string {
+	return string(e)
+}
+
+// redisValue is a RESP value. Simple strings, bulk strings and integers are
+// stored in str, and arrays in array. Nil bulk strings and arrays are
+// flagged as null.
+type redisValue struct {
+	str   string
+	array []redisValue
+	null  bool
+}
+
+// readRESPLine reads a CRLF terminated line.
+func readRESPLine(r *bufio.Reader) (string, error) {
+	var line []byte
+	for {
+		b, isPrefix, err := r.ReadLine()
+		if err != nil {
+			return "", err
+		}
+		line = append(line, b...)
+		if len(line) > redisMaxLineLength {
+			return "", errors.New("RESP line too long")
+		}
+		if !isPrefix {
+			return string(line), nil
+		}
+	}
+}
+
+// readRESP reads a single RESP value. Error replies at the top level are
+// returned as a redisError.
+func readRESP(r *bufio.Reader) (redisValue, error) {
+	line, err := readRESPLine(r)
+	if err != nil {
+		return redisValue{}, err
+	}
+	if line == "" {
+		return redisValue{}, errors.New("empty RESP line")
+	}
+	switch line[0] {
+	case '+', ':':
+		return redisValue{str: line[1:]}, nil
+	case '-':
+		return redisValue{}, redisError(line[1:])
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < -1 || n > redisMaxBulkLength {
+			return redisValue{}, fmt.Errorf("invalid RESP bulk length %q", line[1:])
+		}
+		if n == -1 {
+			return redisValue{null: true}, nil
+		}
+		b := make([]byte, n+2)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return redisValue{}, err
+		}
+		if string(b[n:]) != "\r\n" {
+			return redisValue{}, errors.New("RESP bulk string not terminated by CRLF")
+		}
+		return redisValue{str: string(b[:n])}, nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < -1 || n > redisMaxArrayLength {
+			return redisValue{}, fmt.Errorf("invalid RESP array length %q", line[1:])
+		}
+		if n == -1 {
+			return redisValue{null: true}, nil
+		}
+		v := redisValue{array: make([]redisValue, 0, n)}
+		for i := 0; i < n; i++ {
+			e, err := readRESP(r)
+			if err != nil {
+				return redisValue{}, err
+			}
+			v.array = append(v.array, e)
+		}
+		return v, nil
+	}
+	return redisValue{}, fmt.Errorf("unexpected RESP type %q", line[0])
+}
+
+// redisCommand encodes a command as a RESP array of bulk strings.
+func redisCommand(args ...string) []byte {
+	b := []byte(fmt.Sprintf("*%d\r\n", len(args)))
+	for _, arg := range args {
+		b = append(b, fmt.Sprintf("$%d\r\n%s\r\n", len(arg), arg)...)
+	}
+	return b
+}
+
+// redisConn sends commands to and reads replies from a Redis server.
+type redisConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// do sends a command and reads its reply.
+func (c *redisConn) do(args ...string) (redisValue, error) {
+	if err := writeFull(c.conn, redisCommand(args...)); err != nil {
+		return redisValue{}, err
+	}
+	return readRESP(c.r)
+}
+
+// redisRole returns the normalised role name reported by ROLE.
+func redisRole(v redisValue) (string, error) {
+	if len(v.array) == 0 {
+		return "", errors.New("empty ROLE reply")
+	}
+	switch role := v.array[0].str; role {
+	case "slave":
+		return "replica", nil
+	default:
+		return role, nil
+	}
+}
+
+// Check executes a Redis healthcheck.
+func (hc *RedisChecker) Check(timeout time.Duration) *Result {
+	msg := fmt.Sprintf("Redis connect to %s", hc.addr())
+	start := time.Now()
+	if timeout == time.Duration(0) {
+		timeout = defaultRedisTimeout
+	}
+	deadline := start.Add(timeout)
+
+	conn, err := dialTCP(hc.network(), hc.addr(), timeout, hc.Mark)
+	if err != nil {
+		msg = fmt.Sprintf("%s; failed to connect", msg)
+		return complete(start, msg, false, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(deadline); err != nil {
+		msg = fmt.Sprintf("%s; failed to set deadline", msg)
+		return complete(start, msg, false, err)
+	}
+	c := &redisConn{conn: conn, r: bufio.NewReader(conn)}
+
+	// Server error replies are reported verbatim in the message, since
+	// they explain why the server is unavailable (e.g. LOADING).
+	var rerr redisError
+	if hc.Password != "" {
+		args := []string{"AUTH", hc.Password}
+		if hc.Username != "" {
+			args = []string{"AUTH", hc.Username, hc.Password}
+		}
+		if _, err := c.do(args...); err != nil {
+			if errors.As(err, &rerr) {
+				msg = fmt.Sprintf("%s; AUTH failed: %s", msg, rerr)
+				return complete(start, msg, false, nil)
+			}
+			msg = fmt.Sprintf("%s; AUTH failed", msg)
+			return complete(start, msg, false, err)
+		}
+	}
+
+	v, err := c.do("PING")
+	if err != nil {
+		if errors.As(err, &rerr) {
+			msg = fmt.Sprintf("%s; PING failed: %s", msg, rerr)
+			return complete(start, msg, false, nil)
+		}
+		msg = fmt.Sprintf("%s; PING failed", msg)
+		return complete(start, msg, false, err)
+	}
+	if v.str != "PONG" {
+		msg = fmt.Sprintf("%s; unexpected PING response %q", msg, v.str)
+		return complete(start, msg, false, nil)
+	}
+
+	if hc.RequireRole != "" {
+		v, err := c.do("ROLE")
+		if err != nil {
+			if errors.As(err, &rerr) {
+				msg = fmt.Sprintf("%s; ROLE failed: %s", msg, rerr)
+				return complete(start, msg, false, nil)
+			}
+			msg = fmt.Sprintf("%s; ROLE failed", msg)
+			return complete(start, msg, false, err)
+		}
+		role, err := redisRole(v)
+		if err != nil {
+			msg = fmt.Sprintf("%s; ROLE failed", msg)
+			return complete(start, msg, false, err)
+		}
+		if role != hc.RequireRole {
+			msg = fmt.Sprintf("%s; role is %s, want %s", msg, role, hc.RequireRole)
+			return complete(start, msg, false, nil)
+		}
+		msg = fmt.Sprintf("%s; role is %s", msg, role)
+	}
+	return complete(start, msg, true, nil)
+}
@@ -0,0 +1,187 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// redisScript maps a command line, e.g. "AUTH secret", to the raw RESP reply
+// sent by the scripted Redis server. Unknown commands get an error reply.
+type redisScript map[string]string
+
+func (rs redisScript) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		v, err := readRESP(r)
+		if err != nil {
+			return
+		}
+		var args []string
+		for _, arg := range v.array {
+			args = append(args, arg.str)
+		}
+		reply, ok := rs[strings.Join(args, " ")]
+		if !ok {
+			reply = "-ERR unknown command\r\n"
+		}
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+var redisCheckerTests = []struct {
+	desc     string
+	script   redisScript
+	username string
+	password string
+	role     string
+	success  bool
+	message  string
+}{
+	{
+		"pong",
+		redisScript{"PING": "+PONG\r\n"},
+		"", "", "",
+		true, "Redis connect",
+	},
+	{
+		"bulk pong",
+		redisScript{"PING": "$4\r\nPONG\r\n"},
+		"", "", "",
+		true, "Redis connect",
+	},
+	{
+		"loading",
+		redisScript{"PING": "-LOADING Redis is loading the dataset in memory\r\n"},
+		"", "", "",
+		false, "PING failed: LOADING Redis is loading the dataset in memory",
+	},
+	{
+		"auth",
+		redisScript{"AUTH secret": "+OK\r\n", "PING": "+PONG\r\n"},
+		"", "secret", "",
+		true, "Redis connect",
+	},
+	{
+		"acl auth",
+		redisScript{"AUTH seesaw secret": "+OK\r\n", "PING": "+PONG\r\n"},
+		"seesaw", "secret", "",
+		true, "Redis connect",
+	},
+	{
+		"wrong password",
+		redisScript{"AUTH secret": "+OK\r\n", "AUTH wrong": "-WRONGPASS invalid username-password pair or user is disabled.\r\n"},
+		"", "wrong", "",
+		false, "AUTH failed: WRONGPASS invalid username-password pair or user is disabled.",
+	},
+	{
+		"no auth",
+		redisScript{"PING": "-NOAUTH Authentication required.\r\n"},
+		"", "", "",
+		false, "PING failed: NOAUTH Authentication required.",
+	},
+	{
+		"master",
+		redisScript{"PING": "+PONG\r\n", "ROLE": "*3\r\n$6\r\nmaster\r\n:3129659\r\n*0\r\n"},
+		"", "", "master",
+		true, "role is master",
+	},
+	{
+		"replica wanted",
+		redisScript{"PING": "+PONG\r\n", "ROLE": "*5\r\n$5\r\nslave\r\n$9\r\n127.0.0.1\r\n:6379\r\n$9\r\nconnected\r\n:3167038\r\n"},
+		"", "", "replica",
+		true, "role is replica",
+	},
+	{
+		"replica not master",
+		redisScript{"PING": "+PONG\r\n", "ROLE": "*5\r\n$5\r\nslave\r\n$9\r\n127.0.0.1\r\n:6379\r\n$9\r\nconnected\r\n:3167038\r\n"},
+		"", "", "master",
+		false, "role is replica, want master",
+	},
+	{
+		"unexpected",
+		redisScript{"PING": "+HELLO\r\n"},
+		"", "", "",
+		false, `unexpected PING response "HELLO"`,
+	},
+	{
+		"not redis",
+		redisScript{"PING": "HTTP/1.1 400 Bad Request\r\n"},
+		"", "", "",
+		false, "PING failed",
+	},
+}
+
+func TestRedisChecker(t *testing.T) {
+	for _, test := range redisCheckerTests {
+		l, addr, err := newLocalTCPListener("tcp4")
+		if err != nil {
+			t.Fatalf("Failed to create local TCP listener: %v", err)
+		}
+		go serveConns(l, test.script.handle)
+
+		hc := NewRedisChecker(addr.IP, addr.Port)
+		hc.Username = test.username
+		hc.Password = test.password
+		hc.RequireRole = test.role
+		result := hc.Check(time.Second)
+		l.Close()
+		if result.Success != test.success {
+			t.Errorf("%s: got success %v, want %v: %v", test.desc, result.Success, test.success, result)
+		}
+		if !strings.Contains(result.Message, test.message) {
+			t.Errorf("%s: got message %q, want it to contain %q", test.desc, result.Message, test.message)
+		}
+	}
+}
+
+func TestRedisCheckerTimeout(t *testing.T) {
+	l, addr, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to create local TCP listener: %v", err)
+	}
+	defer l.Close()
+	// An empty script never replies to PING.
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				io.Copy(io.Discard, conn)
+				conn.Close()
+			}()
+		}
+	}()
+
+	hc := NewRedisChecker(addr.IP, addr.Port)
+	start := time.Now()
+	result := hc.Check(200 * time.Millisecond)
+	if result.Success {
+		t.Fatalf("Redis healthcheck succeeded without a reply: %v", result)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Redis healthcheck took %v, want it bounded by the timeout", elapsed)
+	}
+}
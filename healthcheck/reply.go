@@ -0,0 +1,62 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Support for protocols with numeric, possibly multi-line replies, such as
+// SMTP and FTP.
+
+package healthcheck
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/textproto"
+)
+
+// replyConn sends commands to and reads numeric replies from a server,
+// recording the last reply code seen. The total amount of data read is
+// bounded.
+type replyConn struct {
+	conn     net.Conn
+	r        *textproto.Reader
+	w        *textproto.Writer
+	lastCode int
+}
+
+func newReplyConn(conn net.Conn, maxResponseSize int64) *replyConn {
+	return &replyConn{
+		conn: conn,
+		r:    textproto.NewReader(bufio.NewReader(io.LimitReader(conn, maxResponseSize))),
+		w:    textproto.NewWriter(bufio.NewWriter(conn)),
+	}
+}
+
+// readReply reads a possibly multi-line reply. If expect is non-zero, an
+// error is returned unless the reply code matches it, as described for
+// textproto.Reader.ReadResponse.
+func (c *replyConn) readReply(expect int) (int, string, error) {
+	code, msg, err := c.r.ReadResponse(expect)
+	if code != 0 {
+		c.lastCode = code
+	}
+	return code, msg, err
+}
+
+// command sends a command and reads the reply.
+func (c *replyConn) command(expect int, cmd string) (int, string, error) {
+	if err := c.w.PrintfLine("%s", cmd); err != nil {
+		return 0, "", err
+	}
+	return c.readReply(expect)
+}
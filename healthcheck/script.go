@@ -0,0 +1,223 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Script healthcheck implementation.
+
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/seesaw/common/seesaw"
+)
+
+const (
+	defaultScriptTimeout = 10 * time.Second
+
+	// scriptMaxOutput bounds the amount of script output included in the
+	// result message.
+	scriptMaxOutput = 512
+
+	// scriptWaitDelay bounds the time spent waiting for output to be
+	// closed once a script has been killed.
+	scriptWaitDelay = time.Second
+)
+
+// ScriptChecker contains configuration specific to a script healthcheck.
+//
+// The healthcheck runs Command from the script directory configured on the
+// healthcheck server, with Args followed by the target IP address and port.
+// The target is also provided in the SEESAW_TARGET_IP, SEESAW_TARGET_PORT
+// and SEESAW_TARGET_PROTO environment variables. An exit status of 0 is
+// healthy. The first part of the output is included in the result message.
+// When the timeout expires the script's process group is killed.
+type ScriptChecker struct {
+	Target
+	Command string   // The script name, relative to the script directory.
+	Args    []string // Arguments passed before the target address and port.
+
+	// dir and sem are set by the healthcheck server from its own
+	// configuration, so that they cannot be changed by the engine.
+	dir string
+	sem chan struct{}
+}
+
+// NewScriptChecker returns an initialised ScriptChecker.
+func NewScriptChecker(ip net.IP, port int) *ScriptChecker {
+	return &ScriptChecker{
+		Target: Target{
+			IP:    ip,
+			Port:  port,
+			Proto: seesaw.IPProtoTCP,
+		},
+	}
+}
+
+// ValidScriptCommand reports whether the given command names a script
+// within the script directory.
+func ValidScriptCommand(cmd string) bool {
+	if cmd == "" || filepath.IsAbs(cmd) || !ValidLineArgument(cmd) {
+		return false
+	}
+	clean := filepath.Clean(cmd)
+	return clean != ".." && !strings.HasPrefix(clean, "../")
+}
+
+// String returns the string representation of a script healthcheck.
+func (hc *ScriptChecker) String() string {
+	cmd := strings.Join(append([]string{hc.Command}, hc.Args...), " ")
+	return fmt.Sprintf("SCRIPT [%s] %s", cmd, hc.Target)
+}
+
+// scriptPath returns the path of the script to run, ensuring that it is a
+// regular, executable file within the script directory once symbolic links
+// are resolved.
+func (hc *ScriptChecker) scriptPath() (string, error) {
+	if hc.dir == "" {
+		return "", errors.New("script healthchecks are not enabled")
+	}
+	if !ValidScriptCommand(hc.Command) {
+		return "", fmt.Errorf("invalid script %q", hc.Command)
+	}
+	dir, err := filepath.EvalSymlinks(hc.dir)
+	if err != nil {
+		return "", err
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	path, err := filepath.EvalSymlinks(filepath.Join(dir, hc.Command))
+	if err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(dir, path); err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", fmt.Errorf("script %q is outside %s", hc.Command, hc.dir)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !fi.Mode().IsRegular() || fi.Mode().Perm()&0111 == 0 {
+		return "", fmt.Errorf("script %q is not an executable file", hc.Command)
+	}
+	return path, nil
+}
+
+// limitedBuffer retains the first max bytes written to it and discards the
+// remainder.
+type limitedBuffer struct {
+	mu        sync.Mutex
+	buf       []byte
+	max       int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := len(p)
+	if room := b.max - len(b.buf); n > room {
+		p = p[:room]
+		b.truncated = true
+	}
+	b.buf = append(b.buf, p...)
+	return n, nil
+}
+
+func (b *limitedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := strings.TrimSpace(string(b.buf))
+	if b.truncated {
+		s += "..."
+	}
+	return s
+}
+
+// Check executes a script healthcheck.
+func (hc *ScriptChecker) Check(timeout time.Duration) *Result {
+	msg := fmt.Sprintf("Script %s for %s", hc.Command, hc.addr())
+	start := time.Now()
+	if timeout == time.Duration(0) {
+		timeout = defaultScriptTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	path, err := hc.scriptPath()
+	if err != nil {
+		msg = fmt.Sprintf("%s; not permitted", msg)
+		return complete(start, msg, false, err)
+	}
+
+	if hc.sem != nil {
+		select {
+		case hc.sem <- struct{}{}:
+			defer func() { <-hc.sem }()
+		case <-ctx.Done():
+			msg = fmt.Sprintf("%s; timed out waiting for a concurrent script to finish", msg)
+			return complete(start, msg, false, ctx.Err())
+		}
+	}
+
+	port := strconv.Itoa(hc.Port)
+	args := append(append([]string{}, hc.Args...), hc.IP.String(), port)
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Dir = filepath.Dir(path)
+	cmd.Env = []string{
+		"PATH=/usr/local/bin:/usr/bin:/bin",
+		"SEESAW_TARGET_IP=" + hc.IP.String(),
+		"SEESAW_TARGET_PORT=" + port,
+		"SEESAW_TARGET_PROTO=" + hc.Proto.String(),
+	}
+	output := &limitedBuffer{max: scriptMaxOutput}
+	cmd.Stdout = output
+	cmd.Stderr = output
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error { return killProcessGroup(cmd) }
+	cmd.WaitDelay = scriptWaitDelay
+
+	err = cmd.Run()
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		msg = fmt.Sprintf("%s; killed after %v", msg, timeout)
+	case err != nil:
+		msg = fmt.Sprintf("%s; %v", msg, err)
+	default:
+		msg = fmt.Sprintf("%s; exited with status 0", msg)
+	}
+	if out := output.String(); out != "" {
+		msg = fmt.Sprintf("%s; output %q", msg, out)
+	}
+	if ctx.Err() != nil {
+		return complete(start, msg, false, ctx.Err())
+	}
+	// A non-zero exit status is a healthcheck failure, not an error.
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return complete(start, msg, false, nil)
+	}
+	return complete(start, msg, err == nil, err)
+}
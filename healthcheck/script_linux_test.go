@@ -0,0 +1,186 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// writeScript writes an executable shell script to dir.
+func writeScript(t *testing.T, dir, name, body string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+body), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// processRunning reports whether the given process exists and is not a
+// zombie.
+func processRunning(pid int) bool {
+	b, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return false
+	}
+	// The state follows the parenthesised command name.
+	fields := strings.Fields(string(b[strings.LastIndexByte(string(b), ')')+1:]))
+	return len(fields) > 0 && fields[0] != "Z"
+}
+
+func newTestScriptChecker(dir, cmd string, args ...string) *ScriptChecker {
+	hc := NewScriptChecker(net.ParseIP("192.0.2.1"), 8080)
+	hc.Command = cmd
+	hc.Args = args
+	hc.dir = dir
+	return hc
+}
+
+func TestScriptChecker(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, dir, "ok", `echo "args $*"; echo "env $SEESAW_TARGET_IP $SEESAW_TARGET_PORT $SEESAW_TARGET_PROTO" >&2`)
+	writeScript(t, dir, "fail", "echo backend draining; exit 3\n")
+	writeScript(t, dir, "noisy", "i=0; while [ $i -lt 200 ]; do echo 0123456789; i=$((i+1)); done\n")
+	if err := os.WriteFile(filepath.Join(dir, "noexec"), []byte("#!/bin/sh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("/bin/true", filepath.Join(dir, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeScript(t, filepath.Join(dir, "sub"), "nested", "exit 0\n")
+
+	for _, test := range []struct {
+		desc    string
+		dir     string
+		cmd     string
+		args    []string
+		success bool
+		message string
+	}{
+		{"success", dir, "ok", []string{"-v"}, true, "exited with status 0"},
+		{"arguments", dir, "ok", []string{"-v"}, true, "args -v 192.0.2.1 8080"},
+		{"environment", dir, "ok", nil, true, "env 192.0.2.1 8080 TCP"},
+		{"subdirectory", dir, "sub/nested", nil, true, "exited with status 0"},
+		{"failure", dir, "fail", nil, false, `exit status 3; output "backend draining"`},
+		{"truncated output", dir, "noisy", nil, true, `\n012345..."`},
+		{"missing", dir, "missing", nil, false, "not permitted"},
+		{"not executable", dir, "noexec", nil, false, "not permitted"},
+		{"symlink outside directory", dir, "escape", nil, false, "not permitted"},
+		{"parent directory", dir, "../ok", nil, false, "not permitted"},
+		{"absolute path", dir, "/bin/true", nil, false, "not permitted"},
+		{"not enabled", "", "ok", nil, false, "not permitted"},
+	} {
+		hc := newTestScriptChecker(test.dir, test.cmd, test.args...)
+		result := hc.Check(5 * time.Second)
+		if result.Success != test.success {
+			t.Errorf("%s: got success %v, want %v: %v", test.desc, result.Success, test.success, result)
+		}
+		if !strings.Contains(result.Message, test.message) {
+			t.Errorf("%s: got message %q, want it to contain %q", test.desc, result.Message, test.message)
+		}
+	}
+}
+
+func TestScriptCheckerTimeout(t *testing.T) {
+	dir := t.TempDir()
+	pidFile := filepath.Join(dir, "child.pid")
+	writeScript(t, dir, "wedged", "sleep 30 &\necho $! > "+pidFile+"\nwait\n")
+
+	hc := newTestScriptChecker(dir, "wedged")
+	start := time.Now()
+	result := hc.Check(500 * time.Millisecond)
+	if result.Success {
+		t.Fatalf("Script healthcheck succeeded: %v", result)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Script healthcheck took %v, want it bounded by the timeout", elapsed)
+	}
+	if !strings.Contains(result.Message, "killed after 500ms") {
+		t.Errorf("Got message %q, want it to report the kill", result.Message)
+	}
+
+	// The background child must have been killed along with the script.
+	b, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatalf("Failed to read child PID: %v", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil {
+		t.Fatalf("Bad child PID %q: %v", b, err)
+	}
+	for i := 0; ; i++ {
+		if !processRunning(pid) {
+			break
+		}
+		if i == 50 {
+			syscall.Kill(pid, syscall.SIGKILL)
+			t.Fatalf("Child process %d survived the timeout", pid)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestScriptCheckerConcurrencyLimit(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, dir, "ok", "exit 0\n")
+
+	hc := newTestScriptChecker(dir, "ok")
+	hc.sem = make(chan struct{}, 1)
+	hc.sem <- struct{}{}
+	result := hc.Check(200 * time.Millisecond)
+	if result.Success {
+		t.Fatalf("Script healthcheck succeeded over the concurrency limit: %v", result)
+	}
+	if want := "timed out waiting for a concurrent script"; !strings.Contains(result.Message, want) {
+		t.Errorf("Got message %q, want it to contain %q", result.Message, want)
+	}
+
+	<-hc.sem
+	if result := hc.Check(5 * time.Second); !result.Success {
+		t.Fatalf("Script healthcheck failed: %v", result)
+	}
+	if len(hc.sem) != 0 {
+		t.Errorf("Script healthcheck did not release the semaphore")
+	}
+}
+
+func TestValidScriptCommand(t *testing.T) {
+	for _, test := range []struct {
+		cmd   string
+		valid bool
+	}{
+		{"check_backend", true},
+		{"mail/check_imap", true},
+		{"./check_backend", true},
+		{"", false},
+		{"/usr/bin/true", false},
+		{"../check_backend", false},
+		{"mail/../../check_backend", false},
+		{"check\nbackend", false},
+	} {
+		if got := ValidScriptCommand(test.cmd); got != test.valid {
+			t.Errorf("ValidScriptCommand(%q) = %v, want %v", test.cmd, got, test.valid)
+		}
+	}
+}
@@ -0,0 +1,378 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// SIP healthcheck implementation.
+
+package healthcheck
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/seesaw/common/seesaw"
+)
+
+const (
+	defaultSIPTimeout = 10 * time.Second
+
+	// sipT1 and sipT2 are the RFC 3261 retransmission timer values for
+	// non-INVITE requests over UDP.
+	sipT1 = 500 * time.Millisecond
+	sipT2 = 4 * time.Second
+
+	// sipMaxMessageSize bounds the size of a SIP response.
+	sipMaxMessageSize = 64 * 1024
+
+	// sipBranchMagic prefixes RFC 3261 compliant Via branch parameters.
+	sipBranchMagic = "z9hG4bK"
+)
+
+// sipCompactHeaders maps compact header names to their canonical forms.
+var sipCompactHeaders = map[string]string{
+	"I": "Call-Id",
+	"L": "Content-Length",
+	"V": "Via",
+}
+
+// SIPChecker contains configuration specific to a SIP healthcheck.
+//
+// The healthcheck sends an OPTIONS request over UDP or TCP, depending on the
+// target protocol, and succeeds on a final response with ExpectedStatus, or
+// any 2xx response if ExpectedStatus is zero. Over UDP the request is
+// retransmitted using RFC 3261 timers until the timeout expires.
+type SIPChecker struct {
+	Target
+	ExpectedStatus int
+}
+
+// NewSIPChecker returns an initialised SIPChecker.
+func NewSIPChecker(ip net.IP, port int) *SIPChecker {
+	return &SIPChecker{
+		Target: Target{
+			IP:    ip,
+			Port:  port,
+			Proto: seesaw.IPProtoUDP,
+		},
+	}
+}
+
+// String returns the string representation of a SIP healthcheck.
+func (hc *SIPChecker) String() string {
+	var s string
+	if hc.ExpectedStatus != 0 {
+		s = fmt.Sprintf(" [status %d]", hc.ExpectedStatus)
+	}
+	return fmt.Sprintf("SIP/%s%s %s", hc.transport(), s, hc.Target)
+}
+
+// transport returns the SIP transport name for the target protocol.
+func (hc *SIPChecker) transport() string {
+	if hc.Proto == seesaw.IPProtoTCP {
+		return "TCP"
+	}
+	return "UDP"
+}
+
+// sipRequest is an OPTIONS request and the identifiers used to match its
+// responses.
+type sipRequest struct {
+	callID string
+	branch string
+	msg    []byte
+}
+
+// sipRandom returns a random hex string of n bytes.
+func sipRandom(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// newSIPRequest builds an OPTIONS request from the local address laddr to
+// the target address raddr.
+func newSIPRequest(transport string, laddr, raddr net.Addr) (*sipRequest, error) {
+	callID, err := sipRandom(16)
+	if err != nil {
+		return nil, err
+	}
+	branch, err := sipRandom(8)
+	if err != nil {
+		return nil, err
+	}
+	tag, err := sipRandom(4)
+	if err != nil {
+		return nil, err
+	}
+	host, _, err := net.SplitHostPort(laddr.String())
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	req := &sipRequest{
+		callID: fmt.Sprintf("%s@%s", callID, host),
+		branch: sipBranchMagic + branch,
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "OPTIONS sip:%s SIP/2.0\r\n", raddr)
+	fmt.Fprintf(&b, "Via: SIP/2.0/%s %s;branch=%s;rport\r\n", transport, laddr, req.branch)
+	fmt.Fprintf(&b, "Max-Forwards: 70\r\n")
+	fmt.Fprintf(&b, "From: <sip:seesaw@%s>;tag=%s\r\n", host, tag)
+	fmt.Fprintf(&b, "To: <sip:%s>\r\n", raddr)
+	fmt.Fprintf(&b, "Call-ID: %s\r\n", req.callID)
+	fmt.Fprintf(&b, "CSeq: 1 OPTIONS\r\n")
+	fmt.Fprintf(&b, "Contact: <sip:seesaw@%s;transport=%s>\r\n", laddr, strings.ToLower(transport))
+	fmt.Fprintf(&b, "Accept: application/sdp\r\n")
+	fmt.Fprintf(&b, "Content-Length: 0\r\n\r\n")
+	req.msg = b.Bytes()
+	return req, nil
+}
+
+// sipResponse is a parsed SIP response.
+type sipResponse struct {
+	status int
+	reason string
+	header textproto.MIMEHeader
+}
+
+// readSIPResponse reads a SIP response, including any body.
+func readSIPResponse(r *bufio.Reader) (*sipResponse, error) {
+	tp := textproto.NewReader(r)
+	line, err := tp.ReadLine()
+	if err != nil {
+		return nil, err
+	}
+	version, status, ok := strings.Cut(line, " ")
+	if !ok || version != "SIP/2.0" {
+		return nil, fmt.Errorf("malformed SIP status line %q", line)
+	}
+	code, reason, _ := strings.Cut(status, " ")
+	resp := &sipResponse{reason: reason}
+	if resp.status, err = strconv.Atoi(code); err != nil || resp.status < 100 || resp.status > 699 {
+		return nil, fmt.Errorf("malformed SIP status line %q", line)
+	}
+	header, err := tp.ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	resp.header = make(textproto.MIMEHeader)
+	for name, values := range header {
+		if long, ok := sipCompactHeaders[name]; ok {
+			name = long
+		}
+		resp.header[name] = append(resp.header[name], values...)
+	}
+
+	// Discard the body, so that the next message can be read on a stream
+	// transport.
+	if cl := resp.header.Get("Content-Length"); cl != "" {
+		n, err := strconv.Atoi(strings.TrimSpace(cl))
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid SIP Content-Length %q", cl)
+		}
+		if _, err := io.CopyN(io.Discard, r, int64(n)); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// sipParam returns the value of the named parameter in a header value.
+func sipParam(value, name string) string {
+	params := strings.Split(value, ";")
+	for _, p := range params[1:] {
+		k, v, _ := strings.Cut(strings.TrimSpace(p), "=")
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
+
+// matches reports whether the response belongs to the given request.
+func (r *sipResponse) matches(req *sipRequest) bool {
+	if r.header.Get("Call-Id") != req.callID {
+		return false
+	}
+	// Our Via should be the only one left in a response, but it may be
+	// combined with others in a single header, or spread between compact
+	// and long form headers.
+	var found bool
+	for _, value := range r.header["Via"] {
+		for _, via := range strings.Split(value, ",") {
+			if sipParam(via, "branch") == req.branch {
+				found = true
+			}
+		}
+	}
+	if !found {
+		return false
+	}
+	_, method, _ := strings.Cut(strings.TrimSpace(r.header.Get("Cseq")), " ")
+	return strings.EqualFold(strings.TrimSpace(method), "OPTIONS")
+}
+
+// Check executes a SIP healthcheck.
+func (hc *SIPChecker) Check(timeout time.Duration) *Result {
+	msg := fmt.Sprintf("SIP/%s OPTIONS to %s", hc.transport(), hc.addr())
+	start := time.Now()
+	if timeout == time.Duration(0) {
+		timeout = defaultSIPTimeout
+	}
+	deadline := start.Add(timeout)
+
+	var resp *sipResponse
+	var err error
+	if hc.Proto == seesaw.IPProtoTCP {
+		resp, err = hc.checkTCP(deadline)
+	} else {
+		resp, err = hc.checkUDP(deadline)
+	}
+	if err != nil {
+		var serr *sipStepError
+		if errors.As(err, &serr) {
+			msg = fmt.Sprintf("%s; %s", msg, serr.step)
+			err = serr.err
+		}
+		return complete(start, msg, false, err)
+	}
+
+	msg = fmt.Sprintf("%s; received %d %s", msg, resp.status, resp.reason)
+	success := resp.status >= 200 && resp.status <= 299
+	if hc.ExpectedStatus != 0 {
+		success = resp.status == hc.ExpectedStatus
+	}
+	return complete(start, msg, success, nil)
+}
+
+// sipStepError is a failed step in a SIP healthcheck, with the underlying
+// error, if any.
+type sipStepError struct {
+	step string
+	err  error
+}
+
+func (e *sipStepError) Error() string {
+	if e.err == nil {
+		return e.step
+	}
+	return fmt.Sprintf("%s: %v", e.step, e.err)
+}
+
+func sipError(step string, err error) error {
+	return &sipStepError{step: step, err: err}
+}
+
+// checkUDP sends an OPTIONS request over UDP, retransmitting it until a
+// final response is received or the deadline expires.
+func (hc *SIPChecker) checkUDP(deadline time.Time) (*sipResponse, error) {
+	conn, err := dialUDP(hc.network(), hc.addr(), time.Until(deadline), hc.Mark)
+	if err != nil {
+		return nil, sipError("failed to connect", err)
+	}
+	defer conn.Close()
+	req, err := newSIPRequest("UDP", conn.LocalAddr(), conn.RemoteAddr())
+	if err != nil {
+		return nil, sipError("failed to build request", err)
+	}
+
+	buf := make([]byte, sipMaxMessageSize)
+	interval := sipT1
+	transmissions := 0
+	for {
+		if _, err := conn.Write(req.msg); err != nil {
+			return nil, sipError("failed to send request", err)
+		}
+		transmissions++
+		retransmit := time.Now().Add(interval)
+		if retransmit.After(deadline) {
+			retransmit = deadline
+		}
+		if err := conn.SetReadDeadline(retransmit); err != nil {
+			return nil, sipError("failed to set deadline", err)
+		}
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				var nerr net.Error
+				if errors.As(err, &nerr) && nerr.Timeout() {
+					break
+				}
+				return nil, sipError("failed to read response", err)
+			}
+			resp, err := readSIPResponse(bufio.NewReader(bytes.NewReader(buf[:n])))
+			if err != nil || !resp.matches(req) {
+				// Ignore malformed and stray datagrams.
+				continue
+			}
+			if resp.status >= 200 {
+				return resp, nil
+			}
+			// A provisional response stops T1 backoff (RFC 3261 17.1.2.2).
+			interval = sipT2
+		}
+		if !time.Now().Before(deadline) {
+			return nil, sipError(fmt.Sprintf("no response after %d transmissions", transmissions), nil)
+		}
+		if interval *= 2; interval > sipT2 {
+			interval = sipT2
+		}
+	}
+}
+
+// checkTCP sends an OPTIONS request over TCP and reads the final response.
+func (hc *SIPChecker) checkTCP(deadline time.Time) (*sipResponse, error) {
+	conn, err := dialTCP(hc.network(), hc.addr(), time.Until(deadline), hc.Mark)
+	if err != nil {
+		return nil, sipError("failed to connect", err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, sipError("failed to set deadline", err)
+	}
+	req, err := newSIPRequest("TCP", conn.LocalAddr(), conn.RemoteAddr())
+	if err != nil {
+		return nil, sipError("failed to build request", err)
+	}
+	if err := writeFull(conn, req.msg); err != nil {
+		return nil, sipError("failed to send request", err)
+	}
+	r := bufio.NewReader(io.LimitReader(conn, sipMaxMessageSize))
+	for {
+		resp, err := readSIPResponse(r)
+		if err != nil {
+			return nil, sipError("failed to read response", err)
+		}
+		if !resp.matches(req) {
+			return nil, sipError("response does not match request", nil)
+		}
+		if resp.status >= 200 {
+			return resp, nil
+		}
+	}
+}
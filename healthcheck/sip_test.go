@@ -0,0 +1,243 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/seesaw/common/seesaw"
+)
+
+// sipReply builds a response to a SIP request. The response repeats the
+// Via, Call-ID and CSeq headers, using compact and folded forms and multiple
+// headers of the same name.
+func sipReply(req []byte, status int, reason string, callID string) ([]byte, error) {
+	tp := textproto.NewReader(bufio.NewReader(bytes.NewReader(req)))
+	line, err := tp.ReadLine()
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "OPTIONS sip:") {
+		return nil, fmt.Errorf("unexpected request line %q", line)
+	}
+	header, err := tp.ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	if callID == "" {
+		callID = header.Get("Call-Id")
+	}
+	body := "v=0\r\n"
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "SIP/2.0 %d %s\r\n", status, reason)
+	fmt.Fprintf(&b, "v: %s;received=127.0.0.1\r\n", header.Get("Via"))
+	fmt.Fprintf(&b, "From: %s\r\n", header.Get("From"))
+	fmt.Fprintf(&b, "To: %s;tag=stub\r\n", header.Get("To"))
+	fmt.Fprintf(&b, "i: %s\r\n", callID)
+	fmt.Fprintf(&b, "CSeq: %s\r\n", header.Get("Cseq"))
+	fmt.Fprintf(&b, "Allow: INVITE, ACK, CANCEL,\r\n BYE, OPTIONS\r\n")
+	fmt.Fprintf(&b, "Supported: replaces\r\n")
+	fmt.Fprintf(&b, "Supported: timer\r\n")
+	fmt.Fprintf(&b, "Content-Type: application/sdp\r\n")
+	fmt.Fprintf(&b, "l: %d\r\n\r\n%s", len(body), body)
+	return b.Bytes(), nil
+}
+
+// sipUDPStub replies to OPTIONS requests over UDP, after dropping the given
+// number of requests.
+type sipUDPStub struct {
+	conn     *net.UDPConn
+	drop     int32
+	status   int
+	reason   string
+	requests int32
+}
+
+func (ss *sipUDPStub) serve() {
+	buf := make([]byte, 65536)
+	for {
+		n, raddr, err := ss.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		if atomic.AddInt32(&ss.requests, 1) <= ss.drop {
+			continue
+		}
+		// Send a stray response first, which must be ignored.
+		stray, err := sipReply(buf[:n], 200, "OK", "stray@127.0.0.1")
+		if err != nil {
+			continue
+		}
+		ss.conn.WriteToUDP(stray, raddr)
+		if ss.status >= 200 {
+			trying, _ := sipReply(buf[:n], 100, "Trying", "")
+			ss.conn.WriteToUDP(trying, raddr)
+		}
+		reply, _ := sipReply(buf[:n], ss.status, ss.reason, "")
+		ss.conn.WriteToUDP(reply, raddr)
+	}
+}
+
+var sipUDPCheckerTests = []struct {
+	desc     string
+	drop     int32
+	status   int
+	reason   string
+	expected int
+	success  bool
+	message  string
+}{
+	{"ok", 0, 200, "OK", 0, true, "received 200 OK"},
+	{"accepted", 0, 202, "Accepted", 0, true, "received 202 Accepted"},
+	{"unavailable", 0, 503, "Service Unavailable", 0, false, "received 503 Service Unavailable"},
+	{"expected status", 0, 404, "Not Found", 404, true, "received 404 Not Found"},
+	{"unexpected status", 0, 200, "OK", 404, false, "received 200 OK"},
+	{"retransmission", 1, 200, "OK", 0, true, "received 200 OK"},
+	{"provisional only", 0, 180, "Ringing", 0, false, "no response after"},
+	{"no response", 100, 200, "OK", 0, false, "no response after"},
+}
+
+func TestSIPCheckerUDP(t *testing.T) {
+	for _, test := range sipUDPCheckerTests {
+		conn, addr, err := newLocalUDPConn("udp4")
+		if err != nil {
+			t.Fatalf("Failed to create local UDP connection: %v", err)
+		}
+		ss := &sipUDPStub{conn: conn, drop: test.drop, status: test.status, reason: test.reason}
+		go ss.serve()
+
+		hc := NewSIPChecker(addr.IP, addr.Port)
+		hc.ExpectedStatus = test.expected
+		result := hc.Check(time.Second)
+		conn.Close()
+		if result.Success != test.success {
+			t.Errorf("%s: got success %v, want %v: %v", test.desc, result.Success, test.success, result)
+		}
+		if !strings.Contains(result.Message, test.message) {
+			t.Errorf("%s: got message %q, want it to contain %q", test.desc, result.Message, test.message)
+		}
+	}
+}
+
+func TestSIPCheckerUDPRetransmission(t *testing.T) {
+	conn, addr, err := newLocalUDPConn("udp4")
+	if err != nil {
+		t.Fatalf("Failed to create local UDP connection: %v", err)
+	}
+	defer conn.Close()
+	ss := &sipUDPStub{conn: conn, drop: 100}
+	go ss.serve()
+
+	// With T1 of 500ms, requests are sent at 0, 0.5 and 1.5 seconds.
+	hc := NewSIPChecker(addr.IP, addr.Port)
+	start := time.Now()
+	result := hc.Check(2 * time.Second)
+	if result.Success {
+		t.Fatalf("SIP healthcheck succeeded without a response: %v", result)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("SIP healthcheck took %v, want it bounded by the timeout", elapsed)
+	}
+	if got, want := atomic.LoadInt32(&ss.requests), int32(3); got != want {
+		t.Errorf("Got %d transmissions, want %d", got, want)
+	}
+	if want := "no response after 3 transmissions"; !strings.Contains(result.Message, want) {
+		t.Errorf("got message %q, want it to contain %q", result.Message, want)
+	}
+}
+
+func TestSIPCheckerTCP(t *testing.T) {
+	l, addr, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to create local TCP listener: %v", err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				var req bytes.Buffer
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					req.WriteString(line)
+					if line == "\r\n" {
+						break
+					}
+				}
+				trying, err := sipReply(req.Bytes(), 100, "Trying", "")
+				if err != nil {
+					return
+				}
+				reply, _ := sipReply(req.Bytes(), 200, "OK", "")
+				conn.Write(append(trying, reply...))
+			}()
+		}
+	}()
+
+	hc := NewSIPChecker(addr.IP, addr.Port)
+	hc.Proto = seesaw.IPProtoTCP
+	result := hc.Check(time.Second)
+	if !result.Success {
+		t.Fatalf("SIP/TCP healthcheck failed: %v", result)
+	}
+	if want := "SIP/TCP OPTIONS"; !strings.Contains(result.Message, want) {
+		t.Errorf("got message %q, want it to contain %q", result.Message, want)
+	}
+}
+
+func TestSIPRequest(t *testing.T) {
+	laddr := &net.UDPAddr{IP: net.ParseIP("192.0.2.10"), Port: 40000}
+	raddr := &net.UDPAddr{IP: net.ParseIP("192.0.2.20"), Port: 5060}
+	req, err := newSIPRequest("UDP", laddr, raddr)
+	if err != nil {
+		t.Fatalf("newSIPRequest failed: %v", err)
+	}
+	msg := string(req.msg)
+	for _, want := range []string{
+		"OPTIONS sip:192.0.2.20:5060 SIP/2.0\r\n",
+		"Via: SIP/2.0/UDP 192.0.2.10:40000;branch=" + req.branch + ";rport\r\n",
+		"Contact: <sip:seesaw@192.0.2.10:40000;transport=udp>\r\n",
+		"Call-ID: " + req.callID + "\r\n",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("Request %q does not contain %q", msg, want)
+		}
+	}
+	if !strings.HasPrefix(req.branch, sipBranchMagic) {
+		t.Errorf("Branch %q does not have the RFC 3261 magic cookie", req.branch)
+	}
+	if !strings.HasSuffix(req.callID, "@192.0.2.10") {
+		t.Errorf("Call-ID %q does not include the local address", req.callID)
+	}
+	if other, _ := newSIPRequest("UDP", laddr, raddr); other.callID == req.callID || other.branch == req.branch {
+		t.Errorf("Requests reused Call-ID %q or branch %q", req.callID, req.branch)
+	}
+}
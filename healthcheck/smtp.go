@@ -0,0 +1,188 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// SMTP healthcheck implementation.
+
+package healthcheck
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/seesaw/common/seesaw"
+)
+
+const (
+	defaultSMTPTimeout  = 10 * time.Second
+	defaultSMTPHostname = "localhost"
+
+	// smtpMaxResponseSize bounds the amount of data read from the server
+	// on each connection.
+	smtpMaxResponseSize = 64 * 1024
+)
+
+// SMTPChecker contains configuration specific to an SMTP healthcheck.
+//
+// The healthcheck reads the 220 banner, sends EHLO and succeeds on a 250
+// response, optionally after upgrading the connection with STARTTLS. If
+// ExpectedCapability is set, the EHLO response must advertise it.
+type SMTPChecker struct {
+	Target
+	Hostname           string // The EHLO hostname.
+	StartTLS           bool
+	TLSVerify          bool
+	ServerName         string // TLS ServerName override. If empty, derived from target address.
+	ExpectedCapability string
+}
+
+// NewSMTPChecker returns an initialised SMTPChecker.
+func NewSMTPChecker(ip net.IP, port int) *SMTPChecker {
+	return &SMTPChecker{
+		Target: Target{
+			IP:    ip,
+			Port:  port,
+			Proto: seesaw.IPProtoTCP,
+		},
+		Hostname: defaultSMTPHostname,
+	}
+}
+
+// ValidSMTPHostname reports whether the given hostname can be sent in EHLO.
+func ValidSMTPHostname(hostname string) bool {
+	if hostname == "" || len(hostname) > 255 {
+		return false
+	}
+	for _, r := range hostname {
+		if r <= ' ' || r >= 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the string representation of an SMTP healthcheck.
+func (hc *SMTPChecker) String() string {
+	attr := []string{"ehlo " + hc.Hostname}
+	if hc.StartTLS {
+		attr = append(attr, "starttls")
+		if hc.TLSVerify {
+			attr = append(attr, "verify")
+		}
+	}
+	if hc.ExpectedCapability != "" {
+		attr = append(attr, "capability "+hc.ExpectedCapability)
+	}
+	return fmt.Sprintf("SMTP [%s] %s", strings.Join(attr, "; "), hc.Target)
+}
+
+// sendEHLO sends EHLO and returns the advertised capabilities.
+func sendEHLO(c *replyConn, hostname string) (map[string]bool, error) {
+	_, msg, err := c.command(250, "EHLO "+hostname)
+	if err != nil {
+		return nil, err
+	}
+	caps := make(map[string]bool)
+	lines := strings.Split(msg, "\n")
+	for _, line := range lines[1:] {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			caps[strings.ToUpper(fields[0])] = true
+		}
+	}
+	return caps, nil
+}
+
+// Check executes an SMTP healthcheck.
+func (hc *SMTPChecker) Check(timeout time.Duration) *Result {
+	msg := fmt.Sprintf("SMTP connect to %s", hc.addr())
+	start := time.Now()
+	if timeout == time.Duration(0) {
+		timeout = defaultSMTPTimeout
+	}
+	deadline := start.Add(timeout)
+
+	conn, err := dialTCP(hc.network(), hc.addr(), timeout, hc.Mark)
+	if err != nil {
+		msg = fmt.Sprintf("%s; failed to connect", msg)
+		return complete(start, msg, false, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(deadline); err != nil {
+		msg = fmt.Sprintf("%s; failed to set deadline", msg)
+		return complete(start, msg, false, err)
+	}
+
+	c := newReplyConn(conn, smtpMaxResponseSize)
+	failed := func(step string, err error) *Result {
+		msg = fmt.Sprintf("%s; %s failed", msg, step)
+		if c.lastCode != 0 {
+			msg = fmt.Sprintf("%s (last code %d)", msg, c.lastCode)
+		}
+		return complete(start, msg, false, err)
+	}
+
+	if _, _, err := c.readReply(220); err != nil {
+		return failed("banner", err)
+	}
+	hostname := hc.Hostname
+	if hostname == "" {
+		hostname = defaultSMTPHostname
+	}
+	caps, err := sendEHLO(c, hostname)
+	if err != nil {
+		return failed("EHLO", err)
+	}
+
+	if hc.StartTLS {
+		if !caps["STARTTLS"] {
+			return failed("STARTTLS", fmt.Errorf("STARTTLS not advertised"))
+		}
+		if _, _, err := c.command(220, "STARTTLS"); err != nil {
+			return failed("STARTTLS", err)
+		}
+		serverName := hc.ServerName
+		if serverName == "" {
+			serverName = hc.IP.String()
+		}
+		tlsConn := tls.Client(conn, &tls.Config{
+			InsecureSkipVerify: !hc.TLSVerify,
+			ServerName:         serverName,
+		})
+		if err := tlsConn.Handshake(); err != nil {
+			return failed("TLS handshake", err)
+		}
+		defer tlsConn.Close()
+		lastCode := c.lastCode
+		c = newReplyConn(tlsConn, smtpMaxResponseSize)
+		c.lastCode = lastCode
+
+		// Capabilities must be discarded and requested again once TLS is
+		// in use (RFC 3207).
+		if caps, err = sendEHLO(c, hostname); err != nil {
+			return failed("EHLO after STARTTLS", err)
+		}
+	}
+
+	if hc.ExpectedCapability != "" && !caps[strings.ToUpper(hc.ExpectedCapability)] {
+		return failed("capability check", fmt.Errorf("%s not advertised", hc.ExpectedCapability))
+	}
+
+	msg = fmt.Sprintf("%s; EHLO accepted (code %d)", msg, c.lastCode)
+
+	// A failed QUIT does not fail the healthcheck.
+	c.command(221, "QUIT")
+	return complete(start, msg, true, nil)
+}
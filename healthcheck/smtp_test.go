@@ -0,0 +1,212 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"bufio"
+	"crypto/tls"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// smtpStub is a scripted SMTP server. Replies are sent verbatim, and may
+// span multiple lines.
+type smtpStub struct {
+	banner    string
+	ehlo      string
+	ehloTLS   string // The EHLO reply once TLS is in use.
+	tlsConfig *tls.Config
+
+	mu       sync.Mutex
+	commands []string
+}
+
+func (ss *smtpStub) handle(conn net.Conn) {
+	defer func() { conn.Close() }()
+	io.WriteString(conn, ss.banner)
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimRight(line, "\r\n")
+		ss.mu.Lock()
+		ss.commands = append(ss.commands, cmd)
+		ss.mu.Unlock()
+
+		verb := strings.ToUpper(strings.Fields(cmd + " ")[0])
+		switch {
+		case verb == "EHLO" && ss.tlsConfig != nil && isTLS(conn):
+			io.WriteString(conn, ss.ehloTLS)
+		case verb == "EHLO":
+			io.WriteString(conn, ss.ehlo)
+		case verb == "STARTTLS" && ss.tlsConfig != nil:
+			io.WriteString(conn, "220 2.0.0 Ready to start TLS\r\n")
+			tlsConn := tls.Server(conn, ss.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			r = bufio.NewReader(conn)
+		case verb == "QUIT":
+			io.WriteString(conn, "221 2.0.0 Bye\r\n")
+			return
+		default:
+			io.WriteString(conn, "502 5.5.2 Command not recognized\r\n")
+		}
+	}
+}
+
+func isTLS(conn net.Conn) bool {
+	_, ok := conn.(*tls.Conn)
+	return ok
+}
+
+const (
+	smtpBanner = "220 mx.example.com ESMTP\r\n"
+	smtpEHLO   = "250-mx.example.com\r\n250-PIPELINING\r\n250-SIZE 10240000\r\n250 STARTTLS\r\n"
+)
+
+var smtpCheckerTests = []struct {
+	desc       string
+	banner     string
+	ehlo       string
+	starttls   bool
+	capability string
+	success    bool
+	message    string
+}{
+	{"ehlo", smtpBanner, smtpEHLO, false, "", true, "EHLO accepted (code 250)"},
+	{
+		"multi-line banner",
+		"220-mx.example.com ESMTP\r\n220-No UCE\r\n220 Ready\r\n",
+		smtpEHLO,
+		false, "", true, "EHLO accepted (code 250)",
+	},
+	{"single line ehlo", smtpBanner, "250 mx.example.com\r\n", false, "", true, "EHLO accepted"},
+	{"capability", smtpBanner, smtpEHLO, false, "pipelining", true, "EHLO accepted"},
+	{"capability with parameters", smtpBanner, smtpEHLO, false, "SIZE", true, "EHLO accepted"},
+	{"missing capability", smtpBanner, smtpEHLO, false, "8BITMIME", false, "capability check failed (last code 250)"},
+	{"rejected banner", "554 5.3.2 No service\r\n", smtpEHLO, false, "", false, "banner failed (last code 554)"},
+	{"rejected ehlo", smtpBanner, "550-Denied\r\n550 Go away\r\n", false, "", false, "EHLO failed (last code 550)"},
+	{"starttls", smtpBanner, smtpEHLO, true, "", true, "EHLO accepted (code 250)"},
+	{"starttls capability", smtpBanner, smtpEHLO, true, "AUTH", true, "EHLO accepted"},
+	{
+		"starttls not advertised",
+		smtpBanner,
+		"250-mx.example.com\r\n250 PIPELINING\r\n",
+		true, "", false, "STARTTLS failed (last code 250)",
+	},
+	{"not smtp", "HTTP/1.1 400 Bad Request\r\n", smtpEHLO, false, "", false, "banner failed"},
+}
+
+func TestSMTPChecker(t *testing.T) {
+	cert, _ := newServerCert(t, "mx.example.com")
+	for _, test := range smtpCheckerTests {
+		l, addr, err := newLocalTCPListener("tcp4")
+		if err != nil {
+			t.Fatalf("Failed to create local TCP listener: %v", err)
+		}
+		ss := &smtpStub{
+			banner:    test.banner,
+			ehlo:      test.ehlo,
+			ehloTLS:   "250-mx.example.com\r\n250-PIPELINING\r\n250 AUTH PLAIN\r\n",
+			tlsConfig: &tls.Config{Certificates: []tls.Certificate{cert}},
+		}
+		go serveConns(l, ss.handle)
+
+		hc := NewSMTPChecker(addr.IP, addr.Port)
+		hc.Hostname = "seesaw.example.com"
+		hc.StartTLS = test.starttls
+		hc.ExpectedCapability = test.capability
+		result := hc.Check(time.Second)
+		l.Close()
+		if result.Success != test.success {
+			t.Errorf("%s: got success %v, want %v: %v", test.desc, result.Success, test.success, result)
+		}
+		if !strings.Contains(result.Message, test.message) {
+			t.Errorf("%s: got message %q, want it to contain %q", test.desc, result.Message, test.message)
+		}
+		if test.success {
+			// The stub records QUIT before replying to it.
+			ss.mu.Lock()
+			commands := ss.commands
+			ss.mu.Unlock()
+			if len(commands) == 0 || commands[0] != "EHLO seesaw.example.com" {
+				t.Errorf("%s: got commands %q, want EHLO seesaw.example.com first", test.desc, commands)
+			}
+			if commands[len(commands)-1] != "QUIT" {
+				t.Errorf("%s: got commands %q, want QUIT last", test.desc, commands)
+			}
+		}
+	}
+}
+
+func TestSMTPCheckerGreetingPause(t *testing.T) {
+	l, addr, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to create local TCP listener: %v", err)
+	}
+	defer l.Close()
+	// Accept connections but never send the banner.
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				io.Copy(io.Discard, conn)
+				conn.Close()
+			}()
+		}
+	}()
+
+	hc := NewSMTPChecker(addr.IP, addr.Port)
+	start := time.Now()
+	result := hc.Check(200 * time.Millisecond)
+	if result.Success {
+		t.Fatalf("SMTP healthcheck succeeded without a banner: %v", result)
+	}
+	if !strings.Contains(result.Message, "banner failed") {
+		t.Errorf("got message %q, want it to contain %q", result.Message, "banner failed")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("SMTP healthcheck took %v, want it bounded by the timeout", elapsed)
+	}
+}
+
+func TestValidSMTPHostname(t *testing.T) {
+	for _, test := range []struct {
+		hostname string
+		valid    bool
+	}{
+		{"seesaw.example.com", true},
+		{"[192.0.2.1]", true},
+		{"", false},
+		{"bad host", false},
+		{"evil\r\nQUIT", false},
+		{strings.Repeat("a", 256), false},
+	} {
+		if got := ValidSMTPHostname(test.hostname); got != test.valid {
+			t.Errorf("ValidSMTPHostname(%q) = %v, want %v", test.hostname, got, test.valid)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+// This file contains socket options that may be applied to healthcheck
+// connections.
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// SocketOptions specifies socket options that are applied to a TCP
+// healthcheck connection once it has been established.
+type SocketOptions struct {
+	NoDelay       bool          // Enable TCP_NODELAY.
+	UserTimeout   time.Duration // TCP_USER_TIMEOUT, if non-zero.
+	Linger        bool          // Enable SO_LINGER using LingerTimeout.
+	LingerTimeout time.Duration // SO_LINGER timeout, in whole seconds.
+}
+
+// Validate checks the socket options, returning an error that describes each
+// of the invalid options.
+func (o *SocketOptions) Validate() error {
+	var errs []string
+	if o.UserTimeout < 0 {
+		errs = append(errs, "TCP_USER_TIMEOUT must not be negative")
+	} else if o.UserTimeout > 0 && o.UserTimeout < time.Millisecond {
+		errs = append(errs, "TCP_USER_TIMEOUT must be at least 1ms")
+	} else if o.UserTimeout/time.Millisecond > math.MaxInt32 {
+		errs = append(errs, "TCP_USER_TIMEOUT is too large")
+	}
+	if o.LingerTimeout < 0 {
+		errs = append(errs, "SO_LINGER timeout must not be negative")
+	} else if o.LingerTimeout%time.Second != 0 {
+		errs = append(errs, "SO_LINGER timeout must be a whole number of seconds")
+	} else if o.LingerTimeout/time.Second > math.MaxInt32 {
+		errs = append(errs, "SO_LINGER timeout is too large")
+	}
+	if !o.Linger && o.LingerTimeout != 0 {
+		errs = append(errs, "SO_LINGER timeout given without enabling SO_LINGER")
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid socket options: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// String returns the string representation of the socket options.
+func (o *SocketOptions) String() string {
+	var opts []string
+	if o.NoDelay {
+		opts = append(opts, "nodelay")
+	}
+	if o.UserTimeout > 0 {
+		opts = append(opts, fmt.Sprintf("user-timeout %v", o.UserTimeout))
+	}
+	if o.Linger {
+		opts = append(opts, fmt.Sprintf("linger %v", o.LingerTimeout))
+	}
+	return strings.Join(opts, ", ")
+}
+
+// setSocketOptions applies the socket options to the given connection.
+func setSocketOptions(c net.Conn, o *SocketOptions) error {
+	if o == nil {
+		return nil
+	}
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	if mc, ok := c.(*conn); ok {
+		c = mc.Conn
+	}
+	sc, ok := c.(syscall.Conn)
+	if !ok {
+		return errors.New("connection does not support socket options")
+	}
+	rawc, err := sc.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var optErr error
+	if err := rawc.Control(func(fd uintptr) {
+		optErr = applySocketOptions(int(fd), o)
+	}); err != nil {
+		return err
+	}
+	return optErr
+}
@@ -0,0 +1,47 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// tcpUserTimeout is TCP_USER_TIMEOUT from linux/tcp.h, which is not provided
+// by the syscall package.
+const tcpUserTimeout = 0x12
+
+// applySocketOptions applies the socket options to the given socket.
+func applySocketOptions(fd int, o *SocketOptions) error {
+	if o.NoDelay {
+		if err := syscall.SetsockoptInt(fd, syscall.IPPROTO_TCP, syscall.TCP_NODELAY, 1); err != nil {
+			return os.NewSyscallError("failed to set TCP_NODELAY", err)
+		}
+	}
+	if o.UserTimeout > 0 {
+		ms := int(o.UserTimeout / time.Millisecond)
+		if err := syscall.SetsockoptInt(fd, syscall.IPPROTO_TCP, tcpUserTimeout, ms); err != nil {
+			return os.NewSyscallError("failed to set TCP_USER_TIMEOUT", err)
+		}
+	}
+	if o.Linger {
+		l := &syscall.Linger{Onoff: 1, Linger: int32(o.LingerTimeout / time.Second)}
+		if err := syscall.SetsockoptLinger(fd, syscall.SOL_SOCKET, syscall.SO_LINGER, l); err != nil {
+			return os.NewSyscallError("failed to set SO_LINGER", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+var socketOptionsTests = []struct {
+	desc string
+	opts SocketOptions
+	ok   bool
+}{
+	{"empty", SocketOptions{}, true},
+	{"all", SocketOptions{NoDelay: true, UserTimeout: 2500 * time.Millisecond, Linger: true, LingerTimeout: 3 * time.Second}, true},
+	{"linger zero", SocketOptions{Linger: true}, true},
+	{"negative user timeout", SocketOptions{UserTimeout: -time.Second}, false},
+	{"sub-millisecond user timeout", SocketOptions{UserTimeout: time.Microsecond}, false},
+	{"fractional linger", SocketOptions{Linger: true, LingerTimeout: 1500 * time.Millisecond}, false},
+	{"linger timeout without linger", SocketOptions{LingerTimeout: time.Second}, false},
+}
+
+func TestSocketOptionsValidate(t *testing.T) {
+	for _, test := range socketOptionsTests {
+		err := test.opts.Validate()
+		if test.ok && err != nil {
+			t.Errorf("%s: Validate() failed: %v", test.desc, err)
+		}
+		if !test.ok && err == nil {
+			t.Errorf("%s: Validate() succeeded, want error", test.desc)
+		}
+	}
+
+	// Each invalid option must be reported.
+	o := &SocketOptions{UserTimeout: -time.Second, LingerTimeout: -time.Second}
+	hc := NewTCPChecker(net.ParseIP("127.0.0.1"), 80)
+	if err := hc.SetSocketOptions(o); err == nil {
+		t.Errorf("SetSocketOptions succeeded with invalid options")
+	} else if got, want := err.Error(), "invalid socket options: TCP_USER_TIMEOUT must not be negative; SO_LINGER timeout must not be negative; SO_LINGER timeout given without enabling SO_LINGER"; got != want {
+		t.Errorf("SetSocketOptions error = %q, want %q", got, want)
+	}
+	if hc.SocketOptions != nil {
+		t.Errorf("SetSocketOptions set invalid options")
+	}
+}
+
+func getsockoptLinger(fd int) (*syscall.Linger, error) {
+	var l syscall.Linger
+	size := uint32(unsafe.Sizeof(l))
+	_, _, errno := syscall.Syscall6(syscall.SYS_GETSOCKOPT, uintptr(fd), syscall.SOL_SOCKET,
+		syscall.SO_LINGER, uintptr(unsafe.Pointer(&l)), uintptr(unsafe.Pointer(&size)), 0)
+	if errno != 0 {
+		return nil, errno
+	}
+	return &l, nil
+}
+
+func TestSetSocketOptions(t *testing.T) {
+	ln, addr, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to create listener: %v", err)
+	}
+	defer ln.Close()
+
+	c, err := dialTCP("tcp4", addr.String(), timeout, 0)
+	if err != nil {
+		t.Fatalf("Failed to dial %v: %v", addr, err)
+	}
+	defer c.Close()
+
+	o := &SocketOptions{
+		NoDelay:       true,
+		UserTimeout:   2500 * time.Millisecond,
+		Linger:        true,
+		LingerTimeout: 3 * time.Second,
+	}
+	if err := setSocketOptions(c, o); err != nil {
+		t.Fatalf("Failed to set socket options: %v", err)
+	}
+
+	rawc, err := c.(*conn).Conn.(*net.TCPConn).SyscallConn()
+	if err != nil {
+		t.Fatalf("Failed to get raw connection: %v", err)
+	}
+	rawc.Control(func(fd uintptr) {
+		if v, err := syscall.GetsockoptInt(int(fd), syscall.IPPROTO_TCP, syscall.TCP_NODELAY); err != nil || v == 0 {
+			t.Errorf("TCP_NODELAY = %d (%v), want non-zero", v, err)
+		}
+		if v, err := syscall.GetsockoptInt(int(fd), syscall.IPPROTO_TCP, tcpUserTimeout); err != nil || v != 2500 {
+			t.Errorf("TCP_USER_TIMEOUT = %d (%v), want 2500", v, err)
+		}
+		if l, err := getsockoptLinger(int(fd)); err != nil || l.Onoff == 0 || l.Linger != 3 {
+			t.Errorf("SO_LINGER = %+v (%v), want enabled with 3s", l, err)
+		}
+	})
+}
+
+func TestTCPCheckerSocketOptions(t *testing.T) {
+	ln, addr, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to create listener: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			c.Close()
+		}
+	}()
+
+	hc := NewTCPChecker(addr.IP, addr.Port)
+	if err := hc.SetSocketOptions(&SocketOptions{NoDelay: true, UserTimeout: time.Second}); err != nil {
+		t.Fatalf("SetSocketOptions failed: %v", err)
+	}
+	if result := hc.Check(timeout); !result.Success {
+		t.Errorf("TCP healthcheck with socket options failed: %v", result)
+	}
+
+	// Options that bypass validation must fail the check.
+	hc.SocketOptions = &SocketOptions{UserTimeout: -time.Second}
+	if result := hc.Check(timeout); result.Success {
+		t.Errorf("TCP healthcheck with invalid socket options succeeded")
+	}
+}
+
+func TestHTTPCheckerSocketOptions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	addr := ts.Listener.Addr().(*net.TCPAddr)
+
+	hc := NewHTTPChecker(addr.IP, addr.Port)
+	if err := hc.SetSocketOptions(&SocketOptions{NoDelay: true, UserTimeout: time.Second}); err != nil {
+		t.Fatalf("SetSocketOptions failed: %v", err)
+	}
+	if result := hc.Check(timeout); !result.Success {
+		t.Errorf("HTTP healthcheck with socket options failed: %v", result)
+	}
+
+	hc.SocketOptions = &SocketOptions{LingerTimeout: time.Second}
+	if result := hc.Check(timeout); result.Success {
+		t.Errorf("HTTP healthcheck with invalid socket options succeeded")
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !linux
+
+package healthcheck
+
+import (
+	"sync"
+
+	log "github.com/golang/glog"
+)
+
+var sockoptWarning sync.Once
+
+// applySocketOptions ignores the socket options, since they are not
+// supported on this platform.
+func applySocketOptions(fd int, o *SocketOptions) error {
+	sockoptWarning.Do(func() {
+		log.Warningf("Healthcheck socket options are not supported on this platform; ignoring %v", o)
+	})
+	return nil
+}
@@ -0,0 +1,189 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// TCP half-open (SYN) healthcheck implementation.
+
+package healthcheck
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/google/seesaw/common/seesaw"
+
+	log "github.com/golang/glog"
+)
+
+const (
+	defaultSYNTimeout = 10 * time.Second
+
+	tcpHeaderLen = 20
+
+	tcpFlagSYN = 0x02
+	tcpFlagRST = 0x04
+	tcpFlagACK = 0x10
+)
+
+var (
+	rawTCPOnce sync.Once
+	rawTCPErr  error
+)
+
+// rawTCPAvailable reports whether raw TCP sockets can be opened, which
+// requires CAP_NET_RAW. The check is only performed once.
+func rawTCPAvailable() bool {
+	rawTCPOnce.Do(func() {
+		if rawTCPErr = probeRawTCP(); rawTCPErr != nil {
+			log.Warningf("Raw TCP sockets are unavailable, SYN healthchecks will use full TCP connects: %v", rawTCPErr)
+		}
+	})
+	return rawTCPErr == nil
+}
+
+// SYNChecker contains configuration specific to a TCP half-open (SYN)
+// healthcheck.
+//
+// The healthcheck sends a SYN from a raw socket and succeeds if a SYN/ACK is
+// received within the timeout. The SYN/ACK is answered with a RST, so that
+// no connection is established on the backend. If raw sockets cannot be
+// opened, a full TCP connect is used instead.
+type SYNChecker struct {
+	Target
+}
+
+// NewSYNChecker returns an initialised SYNChecker.
+func NewSYNChecker(ip net.IP, port int) *SYNChecker {
+	return &SYNChecker{
+		Target: Target{
+			IP:    ip,
+			Port:  port,
+			Proto: seesaw.IPProtoTCP,
+		},
+	}
+}
+
+// String returns the string representation of a SYN healthcheck.
+func (hc *SYNChecker) String() string {
+	return fmt.Sprintf("TCP [half-open] %s", hc.Target)
+}
+
+// Check executes a SYN healthcheck.
+func (hc *SYNChecker) Check(timeout time.Duration) *Result {
+	return hc.check(timeout, rawTCPAvailable())
+}
+
+func (hc *SYNChecker) check(timeout time.Duration, raw bool) *Result {
+	if timeout == time.Duration(0) {
+		timeout = defaultSYNTimeout
+	}
+	if !raw {
+		tcp := &TCPChecker{Target: hc.Target}
+		result := tcp.Check(timeout)
+		result.Message = fmt.Sprintf("%s; raw sockets unavailable, used full connect", result.Message)
+		return result
+	}
+
+	msg := fmt.Sprintf("TCP SYN to %s", hc.addr())
+	start := time.Now()
+	flags, err := exchangeSYN(hc.IP, hc.Port, timeout, hc.Mark)
+	switch {
+	case err != nil:
+		msg = fmt.Sprintf("%s; no SYN/ACK received", msg)
+		return complete(start, msg, false, err)
+	case flags&tcpFlagRST != 0:
+		msg = fmt.Sprintf("%s; connection refused (RST)", msg)
+		return complete(start, msg, false, nil)
+	}
+	msg = fmt.Sprintf("%s; SYN/ACK received", msg)
+	return complete(start, msg, true, nil)
+}
+
+// tcpHeader is the subset of a TCP header used by the SYN healthcheck.
+type tcpHeader struct {
+	srcPort int
+	dstPort int
+	seq     uint32
+	ack     uint32
+	flags   byte
+}
+
+func (h *tcpHeader) decode(b []byte) error {
+	if len(b) < tcpHeaderLen {
+		return fmt.Errorf("short TCP segment of %d bytes", len(b))
+	}
+	h.srcPort = int(binary.BigEndian.Uint16(b[0:2]))
+	h.dstPort = int(binary.BigEndian.Uint16(b[2:4]))
+	h.seq = binary.BigEndian.Uint32(b[4:8])
+	h.ack = binary.BigEndian.Uint32(b[8:12])
+	h.flags = b[13]
+	return nil
+}
+
+// newTCPSegment builds a TCP segment, without payload, from src to dst. A
+// SYN carries an MSS option appropriate to the address family.
+func newTCPSegment(src, dst net.IP, h *tcpHeader) []byte {
+	hlen := tcpHeaderLen
+	if h.flags&tcpFlagSYN != 0 {
+		hlen += 4
+	}
+	b := make([]byte, hlen)
+	binary.BigEndian.PutUint16(b[0:2], uint16(h.srcPort))
+	binary.BigEndian.PutUint16(b[2:4], uint16(h.dstPort))
+	binary.BigEndian.PutUint32(b[4:8], h.seq)
+	binary.BigEndian.PutUint32(b[8:12], h.ack)
+	b[12] = byte(hlen/4) << 4
+	b[13] = h.flags
+	if h.flags&tcpFlagSYN != 0 {
+		binary.BigEndian.PutUint16(b[14:16], 65535) // window
+		mss := uint16(1460)
+		if dst.To4() == nil {
+			mss = 1440
+		}
+		b[20], b[21] = 2, 4 // MSS option
+		binary.BigEndian.PutUint16(b[22:24], mss)
+	}
+	binary.BigEndian.PutUint16(b[16:18], tcpChecksum(src, dst, b))
+	return b
+}
+
+// tcpChecksum returns the checksum of a TCP segment from src to dst,
+// including the pseudo-header for the address family.
+func tcpChecksum(src, dst net.IP, seg []byte) uint16 {
+	var sum uint32
+	add := func(b []byte) {
+		for i := 0; i+1 < len(b); i += 2 {
+			sum += uint32(b[i])<<8 | uint32(b[i+1])
+		}
+		if len(b)%2 == 1 {
+			sum += uint32(b[len(b)-1]) << 8
+		}
+	}
+	if src4, dst4 := src.To4(), dst.To4(); src4 != nil && dst4 != nil {
+		add(src4)
+		add(dst4)
+	} else {
+		add(src.To16())
+		add(dst.To16())
+	}
+	sum += uint32(seesaw.IPProtoTCP)
+	sum += uint32(len(seg))
+	add(seg)
+	for sum>>16 != 0 {
+		sum = sum&0xffff + sum>>16
+	}
+	return ^uint16(sum)
+}
@@ -0,0 +1,172 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// probeRawTCP checks that a raw TCP socket can be opened.
+func probeRawTCP() error {
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, syscall.IPPROTO_TCP)
+	if err != nil {
+		return os.NewSyscallError("socket", err)
+	}
+	return syscall.Close(fd)
+}
+
+// synSockaddr returns a socket address for ip and port.
+func synSockaddr(ip net.IP, port int) (int, syscall.Sockaddr) {
+	if ip4 := ip.To4(); ip4 != nil {
+		sa := &syscall.SockaddrInet4{Port: port}
+		copy(sa.Addr[:], ip4)
+		return syscall.AF_INET, sa
+	}
+	sa := &syscall.SockaddrInet6{Port: port}
+	copy(sa.Addr[:], ip.To16())
+	return syscall.AF_INET6, sa
+}
+
+// synFilter returns a socket filter that only accepts TCP segments from
+// port to lport. Data on raw IPv4 sockets starts with the IP header, while
+// on IPv6 sockets it starts with the TCP header.
+func synFilter(family, port, lport int) []syscall.SockFilter {
+	ldx := syscall.LsfStmt(syscall.BPF_LDX|syscall.BPF_W|syscall.BPF_IMM, 0)
+	if family == syscall.AF_INET {
+		// X = 4 * IHL
+		ldx = syscall.LsfStmt(syscall.BPF_LDX|syscall.BPF_B|syscall.BPF_MSH, 0)
+	}
+	return []syscall.SockFilter{
+		*ldx,
+		*syscall.LsfStmt(syscall.BPF_LD|syscall.BPF_H|syscall.BPF_IND, 0),
+		*syscall.LsfJump(syscall.BPF_JMP|syscall.BPF_JEQ|syscall.BPF_K, port, 0, 3),
+		*syscall.LsfStmt(syscall.BPF_LD|syscall.BPF_H|syscall.BPF_IND, 2),
+		*syscall.LsfJump(syscall.BPF_JMP|syscall.BPF_JEQ|syscall.BPF_K, lport, 0, 1),
+		*syscall.LsfStmt(syscall.BPF_RET|syscall.BPF_K, 0xffff),
+		*syscall.LsfStmt(syscall.BPF_RET|syscall.BPF_K, 0),
+	}
+}
+
+// exchangeSYN sends a SYN to the given IP address and port from a raw
+// socket and waits for the matching SYN/ACK or RST, returning its flags. A
+// SYN/ACK is answered with a RST. A mark of zero results in a normal
+// (non-marked) socket.
+func exchangeSYN(ip net.IP, port int, timeout time.Duration, mark int) (byte, error) {
+	deadline := time.Now().Add(timeout)
+
+	// Find the source address that the kernel uses to reach the target.
+	network := "udp4"
+	if ip.To4() == nil {
+		network = "udp6"
+	}
+	uc, err := dialUDP(network, net.JoinHostPort(ip.String(), strconv.Itoa(port)), timeout, mark)
+	if err != nil {
+		return 0, err
+	}
+	src := uc.LocalAddr().(*net.UDPAddr).IP
+	uc.Close()
+
+	// Reserve a local port, so that the probe cannot be confused with a
+	// real connection. The socket is bound but never connected, so the
+	// kernel also answers the SYN/ACK with a RST.
+	family, srcSA := synSockaddr(src, 0)
+	pfd, err := syscall.Socket(family, syscall.SOCK_STREAM|syscall.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return 0, os.NewSyscallError("socket", err)
+	}
+	defer syscall.Close(pfd)
+	if err := syscall.Bind(pfd, srcSA); err != nil {
+		return 0, os.NewSyscallError("bind", err)
+	}
+	sa, err := syscall.Getsockname(pfd)
+	if err != nil {
+		return 0, os.NewSyscallError("getsockname", err)
+	}
+	var lport int
+	switch sa := sa.(type) {
+	case *syscall.SockaddrInet4:
+		lport = sa.Port
+	case *syscall.SockaddrInet6:
+		lport = sa.Port
+	}
+
+	fd, err := syscall.Socket(family, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, syscall.IPPROTO_TCP)
+	if err != nil {
+		return 0, os.NewSyscallError("socket", err)
+	}
+	f := os.NewFile(uintptr(fd), "tcp")
+	defer f.Close()
+	if mark != 0 {
+		if err := setSocketMark(fd, mark); err != nil {
+			return 0, err
+		}
+	}
+	// Raw sockets receive every TCP segment, so only queue those that may
+	// be a response to this probe.
+	if err := syscall.AttachLsf(fd, synFilter(family, port, lport)); err != nil {
+		return 0, os.NewSyscallError("setsockopt", err)
+	}
+	if err := syscall.Bind(fd, srcSA); err != nil {
+		return 0, os.NewSyscallError("bind", err)
+	}
+	pc, err := net.FilePacketConn(f)
+	if err != nil {
+		return 0, err
+	}
+	defer pc.Close()
+
+	iss := rand.Uint32()
+	syn := newTCPSegment(src, ip, &tcpHeader{srcPort: lport, dstPort: port, seq: iss, flags: tcpFlagSYN})
+	if _, err := pc.WriteTo(syn, &net.IPAddr{IP: ip}); err != nil {
+		return 0, err
+	}
+	if err := pc.SetReadDeadline(deadline); err != nil {
+		return 0, err
+	}
+
+	// Segments queued before the filter was attached and segments for
+	// earlier probes are skipped by matching the ports and acknowledgement
+	// number. The IPv4 header is removed by the IPConn.
+	b := make([]byte, 1500)
+	for {
+		n, addr, err := pc.ReadFrom(b)
+		if err != nil {
+			return 0, err
+		}
+		var h tcpHeader
+		if !ip.Equal(addrIP(addr)) || h.decode(b[:n]) != nil {
+			continue
+		}
+		if h.srcPort != port || h.dstPort != lport || h.flags&tcpFlagACK == 0 || h.ack != iss+1 {
+			continue
+		}
+		switch {
+		case h.flags&tcpFlagRST != 0:
+			return h.flags, nil
+		case h.flags&tcpFlagSYN != 0:
+			rst := newTCPSegment(src, ip, &tcpHeader{srcPort: lport, dstPort: port, seq: iss + 1, flags: tcpFlagRST})
+			pc.WriteTo(rst, &net.IPAddr{IP: ip})
+			return h.flags, nil
+		default:
+			return h.flags, fmt.Errorf("unexpected TCP flags 0x%02x", h.flags)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !linux
+
+package healthcheck
+
+import (
+	"errors"
+	"net"
+	"time"
+)
+
+var errSYNUnsupported = errors.New("SYN healthchecks are not supported on this platform")
+
+// probeRawTCP reports that raw TCP sockets are not supported, so that SYN
+// healthchecks use full TCP connects.
+func probeRawTCP() error {
+	return errSYNUnsupported
+}
+
+func exchangeSYN(ip net.IP, port int, timeout time.Duration, mark int) (byte, error) {
+	return 0, errSYNUnsupported
+}
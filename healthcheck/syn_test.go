@@ -0,0 +1,167 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var tcpSegmentTests = []struct {
+	src, dst net.IP
+	hdr      tcpHeader
+	wantLen  int
+}{
+	{
+		net.ParseIP("192.0.2.1"), net.ParseIP("192.0.2.2"),
+		tcpHeader{srcPort: 40000, dstPort: 80, seq: 0xdeadbeef, flags: tcpFlagSYN},
+		24,
+	},
+	{
+		net.ParseIP("192.0.2.1"), net.ParseIP("192.0.2.2"),
+		tcpHeader{srcPort: 40000, dstPort: 80, seq: 0xdeadbef0, flags: tcpFlagRST},
+		20,
+	},
+	{
+		net.ParseIP("2001:db8::1"), net.ParseIP("2001:db8::2"),
+		tcpHeader{srcPort: 40001, dstPort: 443, seq: 1, flags: tcpFlagSYN},
+		24,
+	},
+}
+
+func TestTCPSegment(t *testing.T) {
+	for _, test := range tcpSegmentTests {
+		seg := newTCPSegment(test.src, test.dst, &test.hdr)
+		if len(seg) != test.wantLen {
+			t.Errorf("%v -> %v: got segment length %d, want %d", test.src, test.dst, len(seg), test.wantLen)
+		}
+		// The checksum of a segment including its checksum is zero.
+		if sum := tcpChecksum(test.src, test.dst, seg); sum != 0 {
+			t.Errorf("%v -> %v: got checksum residue 0x%04x, want 0", test.src, test.dst, sum)
+		}
+		var got tcpHeader
+		if err := got.decode(seg); err != nil {
+			t.Errorf("%v -> %v: failed to decode segment: %v", test.src, test.dst, err)
+			continue
+		}
+		if got != test.hdr {
+			t.Errorf("%v -> %v: got header %+v, want %+v", test.src, test.dst, got, test.hdr)
+		}
+	}
+	var h tcpHeader
+	if err := h.decode(make([]byte, tcpHeaderLen-1)); err == nil {
+		t.Error("Decoded a short TCP segment")
+	}
+}
+
+func TestSYNCheckerFallback(t *testing.T) {
+	l, addr, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to create local TCP listener: %v", err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	hc := NewSYNChecker(addr.IP, addr.Port)
+	result := hc.check(time.Second, false)
+	if !result.Success {
+		t.Fatalf("SYN healthcheck fallback failed: %v", result)
+	}
+	if want := "raw sockets unavailable, used full connect"; !strings.Contains(result.Message, want) {
+		t.Errorf("got message %q, want it to contain %q", result.Message, want)
+	}
+}
+
+func TestSYNChecker(t *testing.T) {
+	if !rawTCPAvailable() {
+		t.Skip("Raw TCP sockets are unavailable")
+	}
+	for _, network := range []string{"tcp4", "tcp6"} {
+		l, addr, err := newLocalTCPListener(network)
+		if err != nil {
+			t.Logf("Skipping %s: %v", network, err)
+			continue
+		}
+		hc := NewSYNChecker(addr.IP, addr.Port)
+		result := hc.Check(time.Second)
+		if !result.Success {
+			t.Errorf("%s: SYN healthcheck failed: %v", network, result)
+		} else if !strings.Contains(result.Message, "SYN/ACK received") {
+			t.Errorf("%s: got message %q, want SYN/ACK received", network, result.Message)
+		}
+		// Only the SYN was sent, so no connection is accepted.
+		l.SetDeadline(time.Now().Add(100 * time.Millisecond))
+		if conn, err := l.Accept(); err == nil {
+			conn.Close()
+			t.Errorf("%s: SYN healthcheck established a connection", network)
+		}
+
+		// Nothing is listening once the listener is closed.
+		l.Close()
+		result = hc.Check(time.Second)
+		if result.Success {
+			t.Errorf("%s: SYN healthcheck to a closed port succeeded: %v", network, result)
+		} else if !strings.Contains(result.Message, "connection refused (RST)") {
+			t.Errorf("%s: got message %q, want connection refused (RST)", network, result.Message)
+		}
+	}
+}
+
+func TestSYNCheckerConcurrent(t *testing.T) {
+	if !rawTCPAvailable() {
+		t.Skip("Raw TCP sockets are unavailable")
+	}
+	l, addr, err := newLocalTCPListener("tcp4")
+	if err != nil {
+		t.Fatalf("Failed to create local TCP listener: %v", err)
+	}
+	defer l.Close()
+	closed, err := net.ListenTCP("tcp4", &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("Failed to create local TCP listener: %v", err)
+	}
+	closedAddr := closed.Addr().(*net.TCPAddr)
+	closed.Close()
+
+	// Probes to the same targets run concurrently and each must be matched
+	// with its own response.
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			target, want := addr, true
+			if i%2 == 1 {
+				target, want = closedAddr, false
+			}
+			hc := NewSYNChecker(target.IP, target.Port)
+			if result := hc.Check(time.Second); result.Success != want {
+				t.Errorf("Check %d: got success %v, want %v: %v", i, result.Success, want, result)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -19,21 +19,29 @@
 package healthcheck
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"io"
 	"net"
+	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/seesaw/common/seesaw"
 )
 
 const (
-	defaultTCPTimeout = 10 * time.Second
+	defaultTCPTimeout    = 10 * time.Second
+	defaultTCPMaxReceive = 4 << 10
 )
 
 // TCPChecker contains configuration specific to a TCP healthcheck.
+//
+// If Send is set it is written once connected, after which the response must
+// start with Receive and, if ReceiveRegexp is set, the first MaxReceiveBytes
+// of the response must match it. The timeout applies to the whole exchange.
 type TCPChecker struct {
 	Target
 	Receive    string
@@ -41,6 +49,16 @@ type TCPChecker struct {
 	Secure     bool
 	TLSVerify  bool
 	ServerName string // TLS ServerName override. If empty, derived from target address.
+
+	// ReceiveRegexp should be set via SetReceiveRegexp so that an invalid
+	// expression is detected at configuration time.
+	ReceiveRegexp   string
+	MaxReceiveBytes int // Defaults to 4KB if zero.
+
+	SocketOptions *SocketOptions // Applied once connected, if non-nil.
+
+	reLock    sync.Mutex
+	receiveRe *regexp.Regexp
 }
 
 // NewTCPChecker returns an initialised TCPChecker.
@@ -54,6 +72,48 @@ func NewTCPChecker(ip net.IP, port int) *TCPChecker {
 	}
 }
 
+// SetSocketOptions validates and sets the socket options for the healthcheck.
+func (hc *TCPChecker) SetSocketOptions(o *SocketOptions) error {
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	hc.SocketOptions = o
+	return nil
+}
+
+// SetReceiveRegexp compiles and sets the regular expression that the
+// response must match.
+func (hc *TCPChecker) SetReceiveRegexp(expr string) error {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return fmt.Errorf("invalid receive regexp %q: %v", expr, err)
+	}
+	hc.reLock.Lock()
+	defer hc.reLock.Unlock()
+	hc.ReceiveRegexp = expr
+	hc.receiveRe = re
+	return nil
+}
+
+// receiveRegexp returns the compiled receive regexp, if any. The expression
+// is compiled at most once, including after the checker has been transferred
+// to the healthcheck server.
+func (hc *TCPChecker) receiveRegexp() (*regexp.Regexp, error) {
+	hc.reLock.Lock()
+	defer hc.reLock.Unlock()
+	if hc.ReceiveRegexp == "" {
+		return nil, nil
+	}
+	if hc.receiveRe == nil || hc.receiveRe.String() != hc.ReceiveRegexp {
+		re, err := regexp.Compile(hc.ReceiveRegexp)
+		if err != nil {
+			return nil, err
+		}
+		hc.receiveRe = re
+	}
+	return hc.receiveRe, nil
+}
+
 // String returns the string representation of a TCP healthcheck.
 func (hc *TCPChecker) String() string {
 	attr := []string{}
@@ -72,6 +132,12 @@ func (hc *TCPChecker) String() string {
 
 // Check executes a TCP healthcheck.
 func (hc *TCPChecker) Check(timeout time.Duration) *Result {
+	return hc.CheckContext(context.Background(), timeout)
+}
+
+// CheckContext executes a TCP healthcheck, which is aborted if the given
+// context is cancelled.
+func (hc *TCPChecker) CheckContext(ctx context.Context, timeout time.Duration) *Result {
 	msg := fmt.Sprintf("TCP connect to %s", hc.addr())
 	start := time.Now()
 	if timeout == time.Duration(0) {
@@ -79,7 +145,7 @@ func (hc *TCPChecker) Check(timeout time.Duration) *Result {
 	}
 	deadline := start.Add(timeout)
 
-	tcpConn, err := dialTCP(hc.network(), hc.addr(), timeout, hc.Mark)
+	tcpConn, err := dialTCPContext(ctx, hc.network(), hc.addr(), timeout, hc.Mark)
 	if err != nil {
 		msg = fmt.Sprintf("%s; failed to connect", msg)
 		return complete(start, msg, false, err)
@@ -87,6 +153,15 @@ func (hc *TCPChecker) Check(timeout time.Duration) *Result {
 	conn := net.Conn(tcpConn)
 	defer conn.Close()
 
+	// Closing the connection unblocks any pending I/O on cancellation.
+	stop := context.AfterFunc(ctx, func() { tcpConn.Close() })
+	defer stop()
+
+	if err := setSocketOptions(conn, hc.SocketOptions); err != nil {
+		msg = fmt.Sprintf("%s; failed to set socket options", msg)
+		return complete(start, msg, false, err)
+	}
+
 	// Negotiate TLS if this is required.
 	if hc.Secure {
 		serverName := hc.ServerName
@@ -109,7 +184,7 @@ func (hc *TCPChecker) Check(timeout time.Duration) *Result {
 		conn = tlsConn
 	}
 
-	if hc.Send == "" && hc.Receive == "" {
+	if hc.Send == "" && hc.Receive == "" && hc.ReceiveRegexp == "" {
 		return complete(start, msg, true, err)
 	}
 
@@ -127,6 +202,17 @@ func (hc *TCPChecker) Check(timeout time.Duration) *Result {
 		}
 	}
 
+	if hc.ReceiveRegexp != "" {
+		re, err := hc.receiveRegexp()
+		if err != nil {
+			msg = fmt.Sprintf("%s; invalid receive regexp", msg)
+			return complete(start, msg, false, err)
+		}
+		ok, desc, err := hc.matchResponse(conn, re)
+		msg = fmt.Sprintf("%s; %s", msg, desc)
+		return complete(start, msg, ok, err)
+	}
+
 	if hc.Receive != "" {
 		buf := make([]byte, len(hc.Receive))
 		n, err := io.ReadFull(conn, buf)
@@ -143,6 +229,41 @@ func (hc *TCPChecker) Check(timeout time.Duration) *Result {
 	return complete(start, msg, true, err)
 }
 
+// matchResponse reads the response until it starts with Receive and matches
+// re, or until MaxReceiveBytes have been read, the connection is closed or
+// the deadline expires. It returns whether the response matched, along with
+// a description of the outcome.
+func (hc *TCPChecker) matchResponse(conn net.Conn, re *regexp.Regexp) (bool, string, error) {
+	limit := hc.MaxReceiveBytes
+	if limit <= 0 {
+		limit = defaultTCPMaxReceive
+	}
+	if limit < len(hc.Receive) {
+		limit = len(hc.Receive)
+	}
+	buf := make([]byte, 0, limit)
+	for {
+		n, err := conn.Read(buf[len(buf):limit])
+		buf = buf[:len(buf)+n]
+		prefix := len(buf)
+		if prefix > len(hc.Receive) {
+			prefix = len(hc.Receive)
+		}
+		switch {
+		case string(buf[:prefix]) != hc.Receive[:prefix]:
+			return false, fmt.Sprintf("unexpected response - %s", snippet(buf)), nil
+		case prefix == len(hc.Receive) && re.Match(buf):
+			return true, "response matched", nil
+		case len(buf) == limit, err == io.EOF:
+			return false, fmt.Sprintf("response does not match %q - %s", hc.ReceiveRegexp, snippet(buf)), nil
+		case err != nil && len(buf) > 0:
+			return false, fmt.Sprintf("response does not match %q - %s", hc.ReceiveRegexp, snippet(buf)), err
+		case err != nil:
+			return false, "failed to read response", err
+		}
+	}
+}
+
 func writeFull(conn net.Conn, b []byte) error {
 	for len(b) > 0 {
 		n, err := conn.Write(b)
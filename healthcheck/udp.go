@@ -19,8 +19,11 @@
 package healthcheck
 
 import (
+	"errors"
 	"fmt"
 	"net"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/google/seesaw/common/seesaw"
@@ -28,9 +31,13 @@ import (
 
 const (
 	defaultUDPTimeout = 5 * time.Second
+	maxUDPResponse    = 65535
 )
 
-// UDPChecker contains configuration specific to a UDP healthcheck.
+// UDPChecker contains configuration specific to a UDP healthcheck. The Send
+// payload is written to the target and the response must begin with Receive.
+// If Receive is empty the healthcheck succeeds provided that the payload is
+// written and no ICMP port unreachable is received within the timeout.
 type UDPChecker struct {
 	Target
 	Receive string
@@ -80,15 +87,35 @@ func (hc *UDPChecker) Check(timeout time.Duration) *Result {
 		return complete(start, msg, false, err)
 	}
 
-	buf := make([]byte, len(hc.Receive))
+	buf := make([]byte, maxUDPResponse)
 	n, _, err := conn.ReadFrom(buf)
+	if hc.Receive == "" {
+		// An ICMP port unreachable is reported as a refused connection on
+		// a connected UDP socket - anything else means the port is open.
+		var ne net.Error
+		switch {
+		case errors.Is(err, syscall.ECONNREFUSED):
+			msg = fmt.Sprintf("%s; port unreachable", msg)
+			return complete(start, msg, false, err)
+		case err == nil:
+			return complete(start, msg, true, nil)
+		case errors.As(err, &ne) && ne.Timeout():
+			msg = fmt.Sprintf("%s; no response", msg)
+			return complete(start, msg, true, nil)
+		}
+		msg = fmt.Sprintf("%s; failed to read response", msg)
+		return complete(start, msg, false, err)
+	}
 	if err != nil {
 		msg = fmt.Sprintf("%s; failed to read response", msg)
 		return complete(start, msg, false, err)
 	}
 
 	got := string(buf[0:n])
-	if got != hc.Receive {
+	if !strings.HasPrefix(got, hc.Receive) {
+		if len(got) > len(hc.Receive) {
+			got = got[:len(hc.Receive)]
+		}
 		msg = fmt.Sprintf("%s; unexpected response - %q", msg, got)
 		return complete(start, msg, false, err)
 	}
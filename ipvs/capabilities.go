@@ -0,0 +1,187 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipvs
+
+// This file implements detection of the IPVS features that are supported by
+// the running kernel.
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// schedulers is the list of IPVS schedulers that may be provided by the
+// kernel, each as an ip_vs_<name> module.
+var schedulers = []string{
+	"dh", "fo", "lblc", "lblcr", "lc", "mh", "nq", "ovf", "rr", "sed", "sh", "twos", "wlc", "wrr",
+}
+
+// onePacketMinKernel is the minimum kernel version, as major and minor numbers,
+// that supports one-packet scheduling.
+var onePacketMinKernel = [2]int{3, 7}
+
+// Capabilities describes the IPVS features supported by the running kernel.
+// A nil list indicates that support could not be determined, in which case
+// the features are assumed to be available.
+type Capabilities struct {
+	Version       IPVSVersion
+	ConnTableSize uint32
+	KernelRelease string
+	Schedulers    []string     // Schedulers that are built in, loaded or loadable.
+	Tunnels       []TunnelType // Encapsulations supported for tunnelled destinations.
+}
+
+// HasScheduler returns true if the named scheduler is available.
+func (c *Capabilities) HasScheduler(name string) bool {
+	if c.Schedulers == nil {
+		return true
+	}
+	for _, s := range c.Schedulers {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTunnel returns true if the given tunnel type is supported.
+func (c *Capabilities) HasTunnel(t TunnelType) bool {
+	if c.Tunnels == nil {
+		return true
+	}
+	for _, tt := range c.Tunnels {
+		if tt == t {
+			return true
+		}
+	}
+	return false
+}
+
+// MixedFamilies returns true if destinations may have a different address
+// family to their service.
+func (c *Capabilities) MixedFamilies() bool {
+	if c.KernelRelease == "" {
+		return true
+	}
+	return checkMixedFamilyRelease(c.KernelRelease) == nil
+}
+
+// OnePacket returns true if one-packet scheduling is supported. Older kernels
+// silently ignore the flag and create connection entries as usual.
+func (c *Capabilities) OnePacket() bool {
+	if c.KernelRelease == "" {
+		return true
+	}
+	ok, err := kernelAtLeast(c.KernelRelease, onePacketMinKernel)
+	return err == nil && ok
+}
+
+// String returns a string representation of the capabilities.
+func (c *Capabilities) String() string {
+	scheds := "unknown"
+	if c.Schedulers != nil {
+		scheds = strings.Join(c.Schedulers, ",")
+	}
+	tunnels := "unknown"
+	if c.Tunnels != nil {
+		var names []string
+		for _, t := range c.Tunnels {
+			names = append(names, t.String())
+		}
+		tunnels = strings.Join(names, ",")
+	}
+	return fmt.Sprintf("IPVS %v (kernel %s), connection table size %d, schedulers %s, tunnels %s",
+		c.Version, c.KernelRelease, c.ConnTableSize, scheds, tunnels)
+}
+
+// GetCapabilities returns the IPVS features supported by the running kernel.
+// Available schedulers are determined from the loaded, built in and
+// installed kernel modules.
+func GetCapabilities() (*Capabilities, error) {
+	c := &Capabilities{
+		Version:       Version(),
+		ConnTableSize: info.ConnTableSize,
+	}
+	release, err := kernelRelease()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kernel release: %v", err)
+	}
+	c.KernelRelease = release
+
+	for _, t := range []TunnelType{TunnelIPIP, TunnelGUE, TunnelGRE} {
+		if CheckTunnelSupport(t) == nil {
+			c.Tunnels = append(c.Tunnels, t)
+		}
+	}
+
+	available := make(map[string]bool)
+	found := false
+	for _, name := range []string{
+		"/proc/modules",
+		filepath.Join("/lib/modules", release, "modules.builtin"),
+		filepath.Join("/lib/modules", release, "modules.dep"),
+	} {
+		f, err := os.Open(name)
+		if err != nil {
+			continue
+		}
+		err = readSchedulerModules(f, available)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", name, err)
+		}
+		found = true
+	}
+	if found {
+		c.Schedulers = make([]string, 0, len(available))
+		for s := range available {
+			c.Schedulers = append(c.Schedulers, s)
+		}
+		sort.Strings(c.Schedulers)
+	}
+	return c, nil
+}
+
+// readSchedulerModules records the IPVS schedulers named by a kernel module
+// list, in the format used by /proc/modules, modules.builtin or modules.dep.
+func readSchedulerModules(r io.Reader, available map[string]bool) error {
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		module := filepath.Base(strings.TrimSuffix(fields[0], ":"))
+		if i := strings.Index(module, ".ko"); i >= 0 {
+			module = module[:i]
+		}
+		if !strings.HasPrefix(module, "ip_vs_") {
+			continue
+		}
+		name := strings.TrimPrefix(module, "ip_vs_")
+		for _, sched := range schedulers {
+			if name == sched {
+				available[name] = true
+				break
+			}
+		}
+	}
+	return s.Err()
+}
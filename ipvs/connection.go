@@ -0,0 +1,187 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipvs
+
+// This file implements inspection of the IPVS connection table, which is not
+// available via netlink and is instead read from procfs.
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const connectionTable = "/proc/net/ip_vs_conn"
+
+// errUnknownProtocol is returned when a connection table entry has a protocol
+// name that is not recognised. Such entries are skipped when listing.
+var errUnknownProtocol = errors.New("unknown protocol")
+
+// connectionProtocols maps the protocol names used in the IPVS connection
+// table to protocol numbers. Other protocols are listed as IP_<number>.
+var connectionProtocols = map[string]IPProto{
+	"IP":     syscall.IPPROTO_IP,
+	"TCP":    syscall.IPPROTO_TCP,
+	"UDP":    syscall.IPPROTO_UDP,
+	"SCTP":   syscall.IPPROTO_SCTP,
+	"ICMP":   syscall.IPPROTO_ICMP,
+	"ICMPv6": syscall.IPPROTO_ICMPV6,
+	"AH":     syscall.IPPROTO_AH,
+	"ESP":    syscall.IPPROTO_ESP,
+}
+
+// Connection is an entry in the IPVS connection table.
+type Connection struct {
+	Protocol           IPProto
+	ClientAddress      net.IP
+	ClientPort         uint16
+	VirtualAddress     net.IP
+	VirtualPort        uint16
+	DestinationAddress net.IP
+	DestinationPort    uint16
+	State              string
+	Expires            time.Duration
+}
+
+// String returns a string representation of a Connection.
+func (c Connection) String() string {
+	return fmt.Sprintf("%v %v -> %v -> %v %s expires %v", c.Protocol,
+		net.JoinHostPort(c.ClientAddress.String(), strconv.Itoa(int(c.ClientPort))),
+		net.JoinHostPort(c.VirtualAddress.String(), strconv.Itoa(int(c.VirtualPort))),
+		net.JoinHostPort(c.DestinationAddress.String(), strconv.Itoa(int(c.DestinationPort))),
+		c.State, c.Expires)
+}
+
+// ConnectionFilter selects IPVS connections by their virtual service. Unset
+// fields match all connections.
+type ConnectionFilter struct {
+	Protocol       IPProto
+	VirtualAddress net.IP
+	VirtualPort    uint16
+}
+
+// match returns true if the connection is selected by the filter.
+func (f ConnectionFilter) match(c *Connection) bool {
+	return (f.Protocol == 0 || f.Protocol == c.Protocol) &&
+		(f.VirtualAddress == nil || f.VirtualAddress.Equal(c.VirtualAddress)) &&
+		(f.VirtualPort == 0 || f.VirtualPort == c.VirtualPort)
+}
+
+// ListConnections calls fn for each connection in the IPVS connection table
+// that is selected by the filter, stopping early if fn returns false. The
+// table can be very large, hence connections are read as they are listed
+// rather than being returned together.
+func ListConnections(filter ConnectionFilter, fn func(*Connection) bool) error {
+	f, err := os.Open(connectionTable)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return readConnections(f, filter, fn)
+}
+
+// readConnections parses an IPVS connection table in the format used by
+// /proc/net/ip_vs_conn.
+func readConnections(r io.Reader, filter ConnectionFilter, fn func(*Connection) bool) error {
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		if line == 1 {
+			// Skip the header.
+			continue
+		}
+		c, err := parseConnection(s.Text())
+		if err == errUnknownProtocol {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%s line %d: %v", connectionTable, line, err)
+		}
+		if filter.match(c) && !fn(c) {
+			return nil
+		}
+	}
+	return s.Err()
+}
+
+// parseConnection parses a single entry from the IPVS connection table.
+func parseConnection(entry string) (*Connection, error) {
+	fields := strings.Fields(entry)
+	if len(fields) < 9 {
+		return nil, fmt.Errorf("got %d fields, want at least 9", len(fields))
+	}
+	proto, err := parseConnectionProto(fields[0])
+	if err != nil {
+		return nil, err
+	}
+	c := &Connection{Protocol: proto, State: fields[7]}
+	addrs := []*net.IP{&c.ClientAddress, &c.VirtualAddress, &c.DestinationAddress}
+	ports := []*uint16{&c.ClientPort, &c.VirtualPort, &c.DestinationPort}
+	for i := range addrs {
+		ip, err := parseConnectionIP(fields[1+2*i])
+		if err != nil {
+			return nil, err
+		}
+		port, err := strconv.ParseUint(fields[2+2*i], 16, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port %q", fields[2+2*i])
+		}
+		*addrs[i] = ip
+		*ports[i] = uint16(port)
+	}
+	expires, err := strconv.ParseUint(fields[8], 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expiry %q", fields[8])
+	}
+	c.Expires = time.Duration(expires) * time.Second
+	return c, nil
+}
+
+// parseConnectionProto parses a protocol name from the IPVS connection table.
+func parseConnectionProto(s string) (IPProto, error) {
+	if proto, ok := connectionProtocols[s]; ok {
+		return proto, nil
+	}
+	if strings.HasPrefix(s, "IP_") {
+		if proto, err := strconv.ParseUint(s[len("IP_"):], 10, 8); err == nil {
+			return IPProto(proto), nil
+		}
+	}
+	return 0, errUnknownProtocol
+}
+
+// parseConnectionIP parses an address from the IPVS connection table, where
+// IPv4 addresses are listed in hexadecimal.
+func parseConnectionIP(s string) (net.IP, error) {
+	if len(s) == 2*net.IPv4len && !strings.Contains(s, ":") {
+		b, err := hex.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q", s)
+		}
+		return net.IP(b).To16(), nil
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid address %q", s)
+	}
+	return ip, nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipvs
+
+// This file implements control of the IPVS connection synchronisation daemon,
+// as provided by ipvsadm --start-daemon and --stop-daemon.
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/seesaw/netlink"
+)
+
+/*
+#include <linux/types.h>
+#include <linux/ip_vs.h>
+*/
+import "C"
+
+// SyncState specifies the role of an IPVS connection synchronisation daemon.
+type SyncState uint32
+
+const (
+	SyncStateMaster SyncState = C.IP_VS_STATE_MASTER
+	SyncStateBackup SyncState = C.IP_VS_STATE_BACKUP
+)
+
+var syncStateNames = map[SyncState]string{
+	SyncStateMaster: "master",
+	SyncStateBackup: "backup",
+}
+
+// String returns the name of a SyncState.
+func (s SyncState) String() string {
+	if name, ok := syncStateNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("(unknown %d)", uint32(s))
+}
+
+// SyncDaemon specifies an IPVS connection synchronisation daemon.
+type SyncDaemon struct {
+	State     SyncState
+	Interface string // The multicast interface.
+	SyncID    uint8  // Identifies the daemons for a cluster.
+}
+
+// String returns a string representation of a SyncDaemon.
+func (d SyncDaemon) String() string {
+	return fmt.Sprintf("%v sync daemon on %s (syncid %d)", d.State, d.Interface, d.SyncID)
+}
+
+type ipvsDaemon struct {
+	State     SyncState `netlink:"attr:1"`
+	Interface string    `netlink:"attr:2,omitempty,optional"`
+	SyncID    uint32    `netlink:"attr:3,omitempty,optional"`
+}
+
+type ipvsDaemonCommand struct {
+	Daemon *ipvsDaemon `netlink:"attr:3"`
+}
+
+// StartSyncDaemon starts the specified IPVS connection synchronisation daemon.
+// A master and a backup daemon may run at the same time.
+func StartSyncDaemon(d SyncDaemon) error {
+	if _, ok := syncStateNames[d.State]; !ok {
+		return fmt.Errorf("invalid sync daemon state %v", d.State)
+	}
+	if d.Interface == "" {
+		return errors.New("sync daemon requires a multicast interface")
+	}
+	ic := &ipvsDaemonCommand{
+		Daemon: &ipvsDaemon{
+			State:     d.State,
+			Interface: d.Interface,
+			SyncID:    uint32(d.SyncID),
+		},
+	}
+	return netlink.SendMessageMarshalled(C.IPVS_CMD_NEW_DAEMON, family, 0, ic)
+}
+
+// StopSyncDaemon stops any IPVS connection synchronisation daemons that are
+// running. Stopping a daemon that is not running is not an error.
+func StopSyncDaemon() error {
+	for _, state := range []SyncState{SyncStateMaster, SyncStateBackup} {
+		ic := &ipvsDaemonCommand{Daemon: &ipvsDaemon{State: state}}
+		err := netlink.SendMessageMarshalled(C.IPVS_CMD_DEL_DAEMON, family, 0, ic)
+		if err != nil && !netlink.IsNotFound(err) {
+			return fmt.Errorf("failed to stop %v sync daemon: %v", state, err)
+		}
+	}
+	return nil
+}
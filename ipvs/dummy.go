@@ -0,0 +1,365 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipvs
+
+// This file implements an in-memory IPVS table, for use in tests and dry runs.
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// DummyBackend is an in-memory IPVS table. Operations are validated and fail
+// in the same way as they would for the kernel IPVS table, but the services
+// and destinations are only recorded. It is safe for concurrent use.
+type DummyBackend struct {
+	lock     sync.Mutex
+	services map[string]*Service
+}
+
+// NewDummyBackend returns a new, empty DummyBackend.
+func NewDummyBackend() *DummyBackend {
+	return &DummyBackend{services: make(map[string]*Service)}
+}
+
+// dummyServiceKey returns the key that identifies a service in the IPVS table.
+func dummyServiceKey(svc Service) string {
+	if svc.FirewallMark > 0 {
+		return fmt.Sprintf("FWM %d (IPv4 %v)", svc.FirewallMark, svc.Address.To4() != nil)
+	}
+	return fmt.Sprintf("%v %v:%d", svc.Protocol, svc.Address, svc.Port)
+}
+
+// copyService returns a copy of a service and its destinations.
+func copyService(svc *Service) *Service {
+	s := *svc
+	s.Statistics = &ServiceStats{}
+	s.Destinations = make([]*Destination, 0, len(svc.Destinations))
+	for _, dst := range svc.Destinations {
+		d := *dst
+		d.Statistics = &DestinationStats{}
+		s.Destinations = append(s.Destinations, &d)
+	}
+	return &s
+}
+
+// service returns the service in the table that matches the given service.
+// The lock must be held by the caller.
+func (b *DummyBackend) service(svc Service) (*Service, error) {
+	s, ok := b.services[dummyServiceKey(svc)]
+	if !ok {
+		return nil, fmt.Errorf("%w: service %v", ErrNotFound, svc)
+	}
+	return s, nil
+}
+
+// destinationIndex returns the index of the destination in the given service
+// that matches the given destination, or -1 if there is none.
+func destinationIndex(svc *Service, dst Destination) int {
+	for i, d := range svc.Destinations {
+		if d.Address.Equal(dst.Address) && d.Port == dst.Port {
+			return i
+		}
+	}
+	return -1
+}
+
+// Flush deletes all services and destinations.
+func (b *DummyBackend) Flush() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.services = make(map[string]*Service)
+	return nil
+}
+
+// GetServices returns the services in the table, along with their
+// destinations.
+func (b *DummyBackend) GetServices() ([]*Service, error) {
+	return b.Snapshot(), nil
+}
+
+// GetService returns the service in the table that matches the given service,
+// along with its destinations.
+func (b *DummyBackend) GetService(svc Service) (*Service, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	s, err := b.service(svc)
+	if err != nil {
+		return nil, err
+	}
+	return copyService(s), nil
+}
+
+// AddService adds the specified service to the table, along with any
+// destinations associated with it.
+func (b *DummyBackend) AddService(svc Service) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.addService(svc)
+}
+
+func (b *DummyBackend) addService(svc Service) error {
+	if err := svc.validate(); err != nil {
+		return err
+	}
+	key := dummyServiceKey(svc)
+	if _, ok := b.services[key]; ok {
+		return fmt.Errorf("%w: service %v", ErrServiceExists, svc)
+	}
+	dsts := svc.Destinations
+	svc.Destinations = nil
+	b.services[key] = copyService(&svc)
+	for _, dst := range dsts {
+		if err := b.addDestination(svc, *dst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateService updates the specified service in the table.
+func (b *DummyBackend) UpdateService(svc Service) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.updateService(svc)
+}
+
+func (b *DummyBackend) updateService(svc Service) error {
+	if err := svc.validate(); err != nil {
+		return err
+	}
+	s, err := b.service(svc)
+	if err != nil {
+		return err
+	}
+	dsts := s.Destinations
+	*s = *copyService(&svc)
+	s.Destinations = dsts
+	return nil
+}
+
+// DeleteService deletes the specified service from the table.
+func (b *DummyBackend) DeleteService(svc Service) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.deleteService(svc)
+}
+
+func (b *DummyBackend) deleteService(svc Service) error {
+	if _, err := b.service(svc); err != nil {
+		return err
+	}
+	delete(b.services, dummyServiceKey(svc))
+	return nil
+}
+
+// FlushService deletes the specified service and its destinations from the
+// table. A service that does not exist is not treated as an error.
+func (b *DummyBackend) FlushService(svc Service) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if err := b.deleteService(svc); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// EnsureService adds the specified service and its destinations to the table,
+// updating them if they already exist.
+func (b *DummyBackend) EnsureService(svc Service) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.ensureService(svc)
+}
+
+func (b *DummyBackend) ensureService(svc Service) error {
+	err := b.addService(svc)
+	if !errors.Is(err, ErrServiceExists) {
+		return err
+	}
+	if err := b.updateService(svc); err != nil {
+		return err
+	}
+	for _, dst := range svc.Destinations {
+		if err := b.ensureDestination(svc, *dst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddDestination adds the specified destination to a service in the table.
+func (b *DummyBackend) AddDestination(svc Service, dst Destination) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.addDestination(svc, dst)
+}
+
+func (b *DummyBackend) addDestination(svc Service, dst Destination) error {
+	if err := dst.validate(); err != nil {
+		return err
+	}
+	if err := validateFamily(svc, dst, nil); err != nil {
+		return err
+	}
+	s, err := b.service(svc)
+	if err != nil {
+		return err
+	}
+	if destinationIndex(s, dst) >= 0 {
+		return fmt.Errorf("%w: destination %v for %v", ErrDestinationExists, dst, svc)
+	}
+	dst.Statistics = &DestinationStats{}
+	s.Destinations = append(s.Destinations, &dst)
+	return nil
+}
+
+// UpdateDestination updates the specified destination of a service in the
+// table.
+func (b *DummyBackend) UpdateDestination(svc Service, dst Destination) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.updateDestination(svc, dst)
+}
+
+func (b *DummyBackend) updateDestination(svc Service, dst Destination) error {
+	if err := dst.validate(); err != nil {
+		return err
+	}
+	if err := validateFamily(svc, dst, nil); err != nil {
+		return err
+	}
+	s, err := b.service(svc)
+	if err != nil {
+		return err
+	}
+	i := destinationIndex(s, dst)
+	if i < 0 {
+		return fmt.Errorf("%w: destination %v for %v", ErrNotFound, dst, svc)
+	}
+	dst.Statistics = &DestinationStats{}
+	s.Destinations[i] = &dst
+	return nil
+}
+
+// DrainDestination sets the weight of the specified destination to zero.
+func (b *DummyBackend) DrainDestination(svc Service, dst Destination) error {
+	dst.Weight = 0
+	return b.UpdateDestination(svc, dst)
+}
+
+// DeleteDestination deletes the specified destination from a service in the
+// table.
+func (b *DummyBackend) DeleteDestination(svc Service, dst Destination) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.deleteDestination(svc, dst)
+}
+
+func (b *DummyBackend) deleteDestination(svc Service, dst Destination) error {
+	s, err := b.service(svc)
+	if err != nil {
+		return err
+	}
+	i := destinationIndex(s, dst)
+	if i < 0 {
+		return fmt.Errorf("%w: destination %v for %v", ErrNotFound, dst, svc)
+	}
+	s.Destinations = append(s.Destinations[:i], s.Destinations[i+1:]...)
+	return nil
+}
+
+// EnsureDestination adds the specified destination to a service in the table,
+// updating it if it already exists.
+func (b *DummyBackend) EnsureDestination(svc Service, dst Destination) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.ensureDestination(svc, dst)
+}
+
+func (b *DummyBackend) ensureDestination(svc Service, dst Destination) error {
+	err := b.addDestination(svc, dst)
+	if !errors.Is(err, ErrDestinationExists) {
+		return err
+	}
+	return b.updateDestination(svc, dst)
+}
+
+// Apply applies a transaction to the table, rolling it back if any of its
+// operations fail.
+func (b *DummyBackend) Apply(txn *Transaction) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return txn.commit(dummyTransactor{b})
+}
+
+// dummyTransactor applies transactions to a DummyBackend, whose lock must be
+// held for the duration of the transaction.
+type dummyTransactor struct {
+	b *DummyBackend
+}
+
+func (t dummyTransactor) service(svc Service) (*Service, error) {
+	s, err := t.b.service(svc)
+	if err != nil {
+		return nil, err
+	}
+	return copyService(s), nil
+}
+
+func (t dummyTransactor) apply(op *Operation) error {
+	switch op.Type {
+	case OpAddService:
+		return t.b.addService(op.Service)
+	case OpUpdateService:
+		return t.b.updateService(op.Service)
+	case OpDeleteService:
+		return t.b.deleteService(op.Service)
+	case OpAddDestination:
+		return t.b.addDestination(op.Service, *op.Destination)
+	case OpUpdateDestination:
+		return t.b.updateDestination(op.Service, *op.Destination)
+	case OpDeleteDestination:
+		return t.b.deleteDestination(op.Service, *op.Destination)
+	case OpEnsureService:
+		return t.b.ensureService(op.Service)
+	case OpEnsureDestination:
+		return t.b.ensureDestination(op.Service, *op.Destination)
+	}
+	return fmt.Errorf("unknown operation type %v", op.Type)
+}
+
+// Snapshot returns a copy of the services in the table, along with their
+// destinations. Services and destinations are returned in a stable order.
+func (b *DummyBackend) Snapshot() []*Service {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	keys := make([]string, 0, len(b.services))
+	for key := range b.services {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	svcs := make([]*Service, 0, len(keys))
+	for _, key := range keys {
+		s := copyService(b.services[key])
+		sort.Slice(s.Destinations, func(i, j int) bool {
+			return s.Destinations[i].String() < s.Destinations[j].String()
+		})
+		svcs = append(svcs, s)
+	}
+	return svcs
+}
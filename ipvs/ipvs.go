@@ -18,10 +18,13 @@
 package ipvs
 
 import (
+	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"net"
 	"syscall"
+	"time"
 	"unsafe"
 
 	"github.com/google/seesaw/netlink"
@@ -40,6 +43,35 @@ var (
 	info   ipvsInfo
 )
 
+var (
+	// ErrServiceExists is returned when adding a service that is already
+	// in the IPVS table.
+	ErrServiceExists = errors.New("service already exists")
+
+	// ErrDestinationExists is returned when adding a destination that is
+	// already in the IPVS table.
+	ErrDestinationExists = errors.New("destination already exists")
+
+	// ErrNotFound is returned when a service or destination is not in the
+	// IPVS table.
+	ErrNotFound = errors.New("not found")
+)
+
+// ipvsError maps netlink errors for objects that already exist, or do not
+// exist, to the given sentinel error and ErrNotFound respectively. The
+// original error is retained in the message.
+func ipvsError(err error, exists error) error {
+	switch {
+	case err == nil:
+		return nil
+	case exists != nil && netlink.IsExists(err):
+		return fmt.Errorf("%w: %v", exists, err)
+	case netlink.IsNotFound(err):
+		return fmt.Errorf("%w: %v", ErrNotFound, err)
+	}
+	return err
+}
+
 type ipvsInfo struct {
 	Version       uint32 `netlink:"attr:1"`
 	ConnTableSize uint32 `netlink:"attr:2"`
@@ -56,6 +88,10 @@ type ipvsDestination struct {
 	InactiveConns  uint32            `netlink:"attr:8,omitempty"`
 	PersistConns   uint32            `netlink:"attr:9,omitempty"`
 	Stats          *DestinationStats `netlink:"attr:10,optional"`
+	AddrFamily     uint16            `netlink:"attr:11,omitempty,optional"`
+	TunnelType     TunnelType        `netlink:"attr:12,omitempty,optional"`
+	TunnelPort     uint16            `netlink:"attr:13,network,omitempty,optional"`
+	TunnelFlags    TunnelChecksum    `netlink:"attr:14,omitempty,optional"`
 }
 
 type ipvsService struct {
@@ -72,6 +108,12 @@ type ipvsService struct {
 	PersistenceEngine string        `netlink:"attr:11,omitempty,optional"`
 }
 
+type ipvsTimeouts struct {
+	TCP    uint32 `netlink:"attr:4,omitempty,optional"`
+	TCPFin uint32 `netlink:"attr:5,omitempty,optional"`
+	UDP    uint32 `netlink:"attr:6,omitempty,optional"`
+}
+
 type ipvsCommand struct {
 	Service     *ipvsService     `netlink:"attr:1,omitempty,optional"`
 	Destination *ipvsDestination `netlink:"attr:2,omitempty,optional"`
@@ -89,13 +131,27 @@ func newIPVSService(svc *Service) *ipvsService {
 		Timeout:           svc.Timeout,
 		PersistenceEngine: svc.PersistenceEngine,
 	}
+	if svc.Timeout > 0 {
+		ipvsSvc.Flags |= SFPersistent
+	}
+	if svc.OnePacket {
+		ipvsSvc.Flags |= SFOnePacket
+	}
 
+	// The kernel expects an IPv4 netmask in network byte order and an IPv6
+	// netmask as a prefix length.
 	if ip4 := svc.Address.To4(); ip4 != nil {
 		ipvsSvc.AddrFamily = syscall.AF_INET
 		ipvsSvc.Netmask = 0xffffffff
+		if len(svc.PersistenceNetmask) == net.IPv4len {
+			ipvsSvc.Netmask = binary.NativeEndian.Uint32(svc.PersistenceNetmask)
+		}
 	} else {
 		ipvsSvc.AddrFamily = syscall.AF_INET6
 		ipvsSvc.Netmask = 128
+		if ones, bits := svc.PersistenceNetmask.Size(); bits == 8*net.IPv6len {
+			ipvsSvc.Netmask = uint32(ones)
+		}
 	}
 
 	return ipvsSvc
@@ -103,13 +159,24 @@ func newIPVSService(svc *Service) *ipvsService {
 
 // newIPVSDestination converts a destination to its IPVS representation.
 func newIPVSDestination(dst *Destination) *ipvsDestination {
+	var af uint16
+	switch {
+	case dst.Address.To4() != nil:
+		af = syscall.AF_INET
+	case dst.Address != nil:
+		af = syscall.AF_INET6
+	}
 	return &ipvsDestination{
+		AddrFamily:     af,
 		Address:        dst.Address,
 		Port:           dst.Port,
 		Flags:          dst.Flags,
 		Weight:         dst.Weight,
 		UpperThreshold: dst.UpperThreshold,
 		LowerThreshold: dst.LowerThreshold,
+		TunnelType:     dst.TunnelType,
+		TunnelPort:     dst.TunnelPort,
+		TunnelFlags:    dst.TunnelChecksum,
 	}
 }
 
@@ -126,6 +193,17 @@ func (ipvsSvc ipvsService) toService() *Service {
 		Timeout:           ipvsSvc.Timeout,
 		PersistenceEngine: ipvsSvc.PersistenceEngine,
 		Statistics:        &ServiceStats{},
+		OnePacket:         ipvsSvc.Flags&SFOnePacket != 0,
+	}
+
+	// Host masks are represented by a nil persistence netmask.
+	switch {
+	case ipvsSvc.AddrFamily == syscall.AF_INET && ipvsSvc.Netmask != 0xffffffff:
+		mask := make(net.IPMask, net.IPv4len)
+		binary.NativeEndian.PutUint32(mask, ipvsSvc.Netmask)
+		svc.PersistenceNetmask = mask
+	case ipvsSvc.AddrFamily == syscall.AF_INET6 && ipvsSvc.Netmask > 0 && ipvsSvc.Netmask < 128:
+		svc.PersistenceNetmask = net.CIDRMask(int(ipvsSvc.Netmask), 8*net.IPv6len)
 	}
 
 	// Various callers of this package expect that a service will always
@@ -156,9 +234,20 @@ func (ipvsDst ipvsDestination) toDestination() *Destination {
 		Flags:          ipvsDst.Flags,
 		LowerThreshold: ipvsDst.LowerThreshold,
 		UpperThreshold: ipvsDst.UpperThreshold,
+		TunnelType:     ipvsDst.TunnelType,
+		TunnelPort:     ipvsDst.TunnelPort,
+		TunnelChecksum: ipvsDst.TunnelFlags,
 		Statistics:     &DestinationStats{},
 	}
 
+	// The netlink package treats a zero padded address as IPv4, which is
+	// wrong if the kernel tells us that this is an IPv6 destination.
+	if ipvsDst.AddrFamily == syscall.AF_INET6 {
+		if ip4 := dst.Address.To4(); ip4 != nil {
+			dst.Address = append(net.IP(ip4), make(net.IP, net.IPv6len-net.IPv4len)...)
+		}
+	}
+
 	if ipvsDst.Stats != nil {
 		*dst.Statistics = *ipvsDst.Stats
 	}
@@ -225,8 +314,33 @@ const (
 	SFSchedSHPort     ServiceFlags = ipvsSvcFlagSchedSHPort
 	SFSchedMHFallback ServiceFlags = ipvsSvcFlagSchedMHFallback
 	SFSchedMHPort     ServiceFlags = ipvsSvcFlagSchedMHPort
+
+	// Scheduler flags, the meaning of which depends on the scheduler. They
+	// correspond to flag-1, flag-2 and flag-3 in --sched-flags for ipvsadm.
+	SFSchedFlag1 ServiceFlags = ipvsSvcFlagSchedOpt1
+	SFSchedFlag2 ServiceFlags = ipvsSvcFlagSchedOpt2
+	SFSchedFlag3 ServiceFlags = ipvsSvcFlagSchedOpt3
+	SFSchedMask               = SFSchedFlag1 | SFSchedFlag2 | SFSchedFlag3
 )
 
+// schedFlags specifies the scheduler flags supported by each scheduler.
+var schedFlags = map[string]ServiceFlags{
+	"sh": SFSchedSHFallback | SFSchedSHPort,
+	"mh": SFSchedMHFallback | SFSchedMHPort,
+}
+
+// ValidateSchedFlags returns an error if the given scheduler flags are not
+// supported by the named scheduler.
+func ValidateSchedFlags(scheduler string, flags ServiceFlags) error {
+	if flags&^SFSchedMask != 0 {
+		return fmt.Errorf("flags 0x%x are not scheduler flags", uint32(flags&^SFSchedMask))
+	}
+	if unsupported := flags &^ schedFlags[scheduler]; unsupported != 0 {
+		return fmt.Errorf("scheduler flags 0x%x are not supported by scheduler %q", uint32(unsupported), scheduler)
+	}
+	return nil
+}
+
 // Service represents an IPVS service.
 type Service struct {
 	Address           net.IP
@@ -235,10 +349,19 @@ type Service struct {
 	FirewallMark      uint32
 	Scheduler         string
 	Flags             ServiceFlags
-	Timeout           uint32
+	Timeout           uint32 // The persistence timeout in seconds, if persistent.
 	PersistenceEngine string
 	Statistics        *ServiceStats
 	Destinations      []*Destination
+
+	// OnePacket enables one-packet scheduling, where each UDP datagram is
+	// scheduled independently and no connection entry is created.
+	OnePacket bool
+
+	// PersistenceNetmask groups clients for persistence, such that clients
+	// within the same network are sent to the same destination. A nil mask
+	// groups clients by address.
+	PersistenceNetmask net.IPMask
 }
 
 // Equal returns true if two Services are the same.
@@ -250,7 +373,68 @@ func (svc Service) Equal(other Service) bool {
 		svc.Scheduler == other.Scheduler &&
 		svc.Flags == other.Flags &&
 		svc.Timeout == other.Timeout &&
-		svc.PersistenceEngine == other.PersistenceEngine
+		svc.PersistenceEngine == other.PersistenceEngine &&
+		svc.OnePacket == other.OnePacket &&
+		bytes.Equal(svc.PersistenceNetmask, other.PersistenceNetmask)
+}
+
+// validate returns an error if the service cannot be programmed.
+func (svc Service) validate() error {
+	if err := ValidateSchedFlags(svc.Scheduler, svc.Flags&SFSchedMask); err != nil {
+		return fmt.Errorf("%v: %v", svc, err)
+	}
+	onePacket := svc.OnePacket || svc.Flags&SFOnePacket != 0
+	if onePacket && svc.FirewallMark == 0 && svc.Protocol != syscall.IPPROTO_UDP {
+		return fmt.Errorf("one-packet scheduling is only supported for UDP, not %v", svc)
+	}
+	if svc.PersistenceNetmask == nil {
+		return nil
+	}
+	bits := 8 * net.IPv6len
+	if svc.Address.To4() != nil {
+		bits = 8 * net.IPv4len
+	}
+	if ones, size := svc.PersistenceNetmask.Size(); size != bits || ones == 0 {
+		return fmt.Errorf("invalid persistence netmask %v for %v", svc.PersistenceNetmask, svc)
+	}
+	return nil
+}
+
+// SetSchedFlags replaces the scheduler flags for a service, provided that they
+// are supported by its scheduler.
+func (svc *Service) SetSchedFlags(flags ServiceFlags) error {
+	if err := ValidateSchedFlags(svc.Scheduler, flags); err != nil {
+		return err
+	}
+	svc.Flags = svc.Flags&^SFSchedMask | flags
+	return nil
+}
+
+// SetMHFallback enables or disables mh-fallback for a service using the
+// Maglev hashing scheduler. When enabled, connections are rescheduled to
+// another destination if the hashed destination is unavailable.
+func (svc *Service) SetMHFallback(enabled bool) error {
+	return svc.setMHFlag(SFSchedMHFallback, enabled)
+}
+
+// SetMHPort enables or disables mh-port for a service using the Maglev
+// hashing scheduler. When enabled, the source port is included in the hash.
+func (svc *Service) SetMHPort(enabled bool) error {
+	return svc.setMHFlag(SFSchedMHPort, enabled)
+}
+
+// setMHFlag sets or clears a scheduler flag for a service using the Maglev
+// hashing scheduler.
+func (svc *Service) setMHFlag(flag ServiceFlags, enabled bool) error {
+	if svc.Scheduler != "mh" {
+		return fmt.Errorf("%v: mh scheduler flags are not supported by scheduler %q", svc, svc.Scheduler)
+	}
+	if enabled {
+		svc.Flags |= flag
+	} else {
+		svc.Flags &^= flag
+	}
+	return nil
 }
 
 // String returns a string representation of a Service.
@@ -286,6 +470,11 @@ type Destination struct {
 	LowerThreshold uint32
 	UpperThreshold uint32
 	Statistics     *DestinationStats
+
+	// The encapsulation for tunnelled destinations, which defaults to IPIP.
+	TunnelType     TunnelType
+	TunnelPort     uint16 // The UDP destination port for GUE.
+	TunnelChecksum TunnelChecksum
 }
 
 // Equal returns true if two Destinations are the same.
@@ -295,7 +484,24 @@ func (dest Destination) Equal(other Destination) bool {
 		dest.Weight == other.Weight &&
 		dest.Flags == other.Flags &&
 		dest.LowerThreshold == other.LowerThreshold &&
-		dest.UpperThreshold == other.UpperThreshold
+		dest.UpperThreshold == other.UpperThreshold &&
+		dest.TunnelType == other.TunnelType &&
+		dest.TunnelPort == other.TunnelPort &&
+		dest.TunnelChecksum == other.TunnelChecksum
+}
+
+// validate returns an error if the destination cannot be programmed.
+func (dest Destination) validate() error {
+	if dest.TunnelType == TunnelIPIP && dest.TunnelPort == 0 && dest.TunnelChecksum == TunnelChecksumNone {
+		return nil
+	}
+	if dest.Flags&DFForwardMask != DFForwardTunnel {
+		return fmt.Errorf("tunnel options for %v require tunnel forwarding", dest)
+	}
+	if err := ValidateTunnel(dest.TunnelType, dest.TunnelPort, dest.TunnelChecksum); err != nil {
+		return fmt.Errorf("invalid tunnel options for %v: %v", dest, err)
+	}
+	return nil
 }
 
 // String returns a string representation of a Destination.
@@ -339,6 +545,7 @@ const (
 	ipvsSvcFlagOnePacket = 0x4
 	ipvsSvcFlagSchedOpt1 = 0x8
 	ipvsSvcFlagSchedOpt2 = 0x10
+	ipvsSvcFlagSchedOpt3 = 0x20
 
 	// Depending on schedulers, the bit stands for different options.
 	ipvsSvcFlagSchedSHFallback = ipvsSvcFlagSchedOpt1
@@ -385,17 +592,141 @@ func Version() IPVSVersion {
 	}
 }
 
-// Flush flushes all services and destinations from the IPVS table.
+// Flush flushes all services and destinations from the IPVS table. Flushing
+// an empty IPVS table is not an error.
 func Flush() error {
-	return netlink.SendMessage(C.IPVS_CMD_FLUSH, family, 0)
+	if err := netlink.SendMessage(C.IPVS_CMD_FLUSH, family, 0); err != nil && !netlink.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// maxTimeout is the largest IPVS timeout that is accepted by the kernel,
+// regardless of its tick rate.
+const maxTimeout = (1<<31 - 1) / 1000 * time.Second
+
+// Timeouts specifies the IPVS connection timeouts, in whole seconds.
+type Timeouts struct {
+	TCP    time.Duration
+	TCPFin time.Duration
+	UDP    time.Duration
+}
+
+// String returns a string representation of the timeouts.
+func (t Timeouts) String() string {
+	return fmt.Sprintf("tcp %v, tcpfin %v, udp %v", t.TCP, t.TCPFin, t.UDP)
+}
+
+// Validate returns an error if the timeouts cannot be set. A zero timeout
+// leaves the current value unchanged, but at least one timeout must be given.
+func (t Timeouts) Validate() error {
+	if t == (Timeouts{}) {
+		return errors.New("no IPVS timeouts specified")
+	}
+	for _, timeout := range []struct {
+		name string
+		d    time.Duration
+	}{{"tcp", t.TCP}, {"tcpfin", t.TCPFin}, {"udp", t.UDP}} {
+		switch {
+		case timeout.d < 0 || timeout.d > maxTimeout:
+			return fmt.Errorf("IPVS %s timeout %v out of range", timeout.name, timeout.d)
+		case timeout.d%time.Second != 0:
+			return fmt.Errorf("IPVS %s timeout %v is not a whole number of seconds", timeout.name, timeout.d)
+		}
+	}
+	return nil
+}
+
+// GetTimeouts returns the current IPVS connection timeouts.
+func GetTimeouts() (*Timeouts, error) {
+	var it ipvsTimeouts
+	if err := netlink.SendMessageUnmarshal(C.IPVS_CMD_GET_CONFIG, family, 0, &it); err != nil {
+		return nil, err
+	}
+	return &Timeouts{
+		TCP:    time.Duration(it.TCP) * time.Second,
+		TCPFin: time.Duration(it.TCPFin) * time.Second,
+		UDP:    time.Duration(it.UDP) * time.Second,
+	}, nil
+}
+
+// SetTimeouts sets the IPVS connection timeouts. Zero timeouts are left
+// unchanged.
+func SetTimeouts(t Timeouts) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	it := &ipvsTimeouts{
+		TCP:    uint32(t.TCP / time.Second),
+		TCPFin: uint32(t.TCPFin / time.Second),
+		UDP:    uint32(t.UDP / time.Second),
+	}
+	return netlink.SendMessageMarshalled(C.IPVS_CMD_SET_CONFIG, family, 0, it)
+}
+
+// serviceFlusher provides the IPVS operations needed to flush a service.
+type serviceFlusher interface {
+	destinations(svc *Service) ([]*Destination, error)
+	deleteDestination(svc Service, dst Destination) error
+	deleteService(svc Service) error
+	isNotFound(err error) bool
+}
+
+// kernelFlusher flushes services from the kernel IPVS table.
+type kernelFlusher struct{}
+
+func (kernelFlusher) destinations(svc *Service) ([]*Destination, error) {
+	return destinations(svc)
+}
+
+func (kernelFlusher) deleteDestination(svc Service, dst Destination) error {
+	return DeleteDestination(svc, dst)
+}
+
+func (kernelFlusher) deleteService(svc Service) error {
+	return DeleteService(svc)
+}
+
+func (kernelFlusher) isNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// FlushService deletes all destinations for the specified service, then
+// deletes the service itself from the IPVS table. Destinations or a service
+// that no longer exist are not treated as errors.
+func FlushService(svc Service) error {
+	return flushService(kernelFlusher{}, svc)
+}
+
+// flushService flushes the specified service using the given serviceFlusher.
+func flushService(f serviceFlusher, svc Service) error {
+	dsts, err := f.destinations(&svc)
+	if err != nil {
+		if f.isNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get destinations: %v", err)
+	}
+	for _, dst := range dsts {
+		if err := f.deleteDestination(svc, *dst); err != nil && !f.isNotFound(err) {
+			return fmt.Errorf("failed to delete destination %v: %v", dst, err)
+		}
+	}
+	if err := f.deleteService(svc); err != nil && !f.isNotFound(err) {
+		return fmt.Errorf("failed to delete service: %v", err)
+	}
+	return nil
 }
 
 // AddService adds the specified service to the IPVS table. Any destinations
 // associated with the given service will also be added.
 func AddService(svc Service) error {
+	if err := svc.validate(); err != nil {
+		return err
+	}
 	ic := &ipvsCommand{Service: newIPVSService(&svc)}
 	if err := netlink.SendMessageMarshalled(C.IPVS_CMD_NEW_SERVICE, family, 0, ic); err != nil {
-		return err
+		return ipvsError(err, ErrServiceExists)
 	}
 	for _, dst := range svc.Destinations {
 		if err := AddDestination(svc, *dst); err != nil {
@@ -407,32 +738,55 @@ func AddService(svc Service) error {
 
 // UpdateService updates the specified service in the IPVS table.
 func UpdateService(svc Service) error {
+	if err := svc.validate(); err != nil {
+		return err
+	}
 	ic := &ipvsCommand{Service: newIPVSService(&svc)}
-	return netlink.SendMessageMarshalled(C.IPVS_CMD_SET_SERVICE, family, 0, ic)
+	return ipvsError(netlink.SendMessageMarshalled(C.IPVS_CMD_SET_SERVICE, family, 0, ic), nil)
 }
 
 // DeleteService deletes the specified service from the IPVS table.
 func DeleteService(svc Service) error {
 	ic := &ipvsCommand{Service: newIPVSService(&svc)}
-	return netlink.SendMessageMarshalled(C.IPVS_CMD_DEL_SERVICE, family, 0, ic)
+	return ipvsError(netlink.SendMessageMarshalled(C.IPVS_CMD_DEL_SERVICE, family, 0, ic), nil)
 }
 
 // AddDestination adds the specified destination to the IPVS table.
 func AddDestination(svc Service, dst Destination) error {
+	if err := dst.validate(); err != nil {
+		return err
+	}
+	if err := validateFamily(svc, dst, CheckMixedFamilySupport); err != nil {
+		return err
+	}
 	ic := &ipvsCommand{
 		Service:     newIPVSService(&svc),
 		Destination: newIPVSDestination(&dst),
 	}
-	return netlink.SendMessageMarshalled(C.IPVS_CMD_NEW_DEST, family, 0, ic)
+	return ipvsError(netlink.SendMessageMarshalled(C.IPVS_CMD_NEW_DEST, family, 0, ic), ErrDestinationExists)
 }
 
 // UpdateDestination updates the specified destination in the IPVS table.
 func UpdateDestination(svc Service, dst Destination) error {
+	if err := dst.validate(); err != nil {
+		return err
+	}
+	if err := validateFamily(svc, dst, CheckMixedFamilySupport); err != nil {
+		return err
+	}
 	ic := &ipvsCommand{
 		Service:     newIPVSService(&svc),
 		Destination: newIPVSDestination(&dst),
 	}
-	return netlink.SendMessageMarshalled(C.IPVS_CMD_SET_DEST, family, 0, ic)
+	return ipvsError(netlink.SendMessageMarshalled(C.IPVS_CMD_SET_DEST, family, 0, ic), nil)
+}
+
+// DrainDestination sets the weight of the specified destination to zero, so
+// that it receives no new connections while existing connections complete.
+// The destination's thresholds are retained.
+func DrainDestination(svc Service, dst Destination) error {
+	dst.Weight = 0
+	return UpdateDestination(svc, dst)
 }
 
 // DeleteDestination deletes the specified destination from the IPVS table.
@@ -441,7 +795,36 @@ func DeleteDestination(svc Service, dst Destination) error {
 		Service:     newIPVSService(&svc),
 		Destination: newIPVSDestination(&dst),
 	}
-	return netlink.SendMessageMarshalled(C.IPVS_CMD_DEL_DEST, family, 0, ic)
+	return ipvsError(netlink.SendMessageMarshalled(C.IPVS_CMD_DEL_DEST, family, 0, ic), nil)
+}
+
+// EnsureService adds the specified service and its destinations to the IPVS
+// table, updating them if they already exist. Destinations that exist in the
+// IPVS table but not in the given service are left unchanged.
+func EnsureService(svc Service) error {
+	err := AddService(svc)
+	if !errors.Is(err, ErrServiceExists) {
+		return err
+	}
+	if err := UpdateService(svc); err != nil {
+		return err
+	}
+	for _, dst := range svc.Destinations {
+		if err := EnsureDestination(svc, *dst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnsureDestination adds the specified destination to the IPVS table,
+// updating it if it already exists.
+func EnsureDestination(svc Service, dst Destination) error {
+	err := AddDestination(svc, dst)
+	if !errors.Is(err, ErrDestinationExists) {
+		return err
+	}
+	return UpdateDestination(svc, dst)
 }
 
 // destinations returns a list of destinations that are currently
@@ -471,7 +854,7 @@ func destinations(svc *Service) ([]*Destination, error) {
 		return nil
 	}
 	if err := msg.SendCallback(cb, nil); err != nil {
-		return nil, err
+		return nil, ipvsError(err, nil)
 	}
 	return dsts, nil
 }
@@ -530,10 +913,10 @@ func services(svc *Service) ([]*Service, error) {
 func GetService(svc *Service) (*Service, error) {
 	svcs, err := services(svc)
 	if err != nil {
-		return nil, err
+		return nil, ipvsError(err, nil)
 	}
 	if len(svcs) == 0 {
-		return nil, errors.New("no service found")
+		return nil, fmt.Errorf("%w: no service found", ErrNotFound)
 	}
 	return svcs[0], nil
 }
@@ -18,10 +18,15 @@ package ipvs
 
 import (
 	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
 	"net"
 	"reflect"
+	"strings"
 	"syscall"
 	"testing"
+	"time"
 
 	"github.com/google/seesaw/netlink"
 )
@@ -112,6 +117,47 @@ var ipvsServiceTests = []struct {
 			Statistics:        &ServiceStats{Stats: testStats},
 		},
 	},
+	{
+		"IPv4 1.2.3.4 with UDP/53 using one-packet scheduling",
+		ipvsService{
+			Protocol:   syscall.IPPROTO_UDP,
+			Port:       53,
+			Scheduler:  "wrr",
+			Flags:      SFOnePacket | SFHashed,
+			Netmask:    0xffffffff,
+			AddrFamily: syscall.AF_INET,
+			Address:    net.ParseIP("1.2.3.4"),
+		},
+		Service{
+			Address:    net.ParseIP("1.2.3.4"),
+			Protocol:   syscall.IPPROTO_UDP,
+			Port:       53,
+			Scheduler:  "wrr",
+			Flags:      SFOnePacket | SFHashed,
+			Statistics: &ServiceStats{},
+			OnePacket:  true,
+		},
+	},
+	{
+		"IPv4 1.2.3.4 with TCP/443 using mh",
+		ipvsService{
+			Protocol:   syscall.IPPROTO_TCP,
+			Port:       443,
+			Scheduler:  "mh",
+			Flags:      SFSchedMHFallback | SFSchedMHPort | SFHashed,
+			Netmask:    0xffffffff,
+			AddrFamily: syscall.AF_INET,
+			Address:    net.ParseIP("1.2.3.4"),
+		},
+		Service{
+			Address:    net.ParseIP("1.2.3.4"),
+			Protocol:   syscall.IPPROTO_TCP,
+			Port:       443,
+			Scheduler:  "mh",
+			Flags:      SFSchedMHFallback | SFSchedMHPort | SFHashed,
+			Statistics: &ServiceStats{},
+		},
+	},
 	{
 		"IPv4 FWM 4 using lc",
 		ipvsService{
@@ -168,6 +214,73 @@ var ipvsServiceTests = []struct {
 	},
 }
 
+func TestIPVSServicePersistenceNetmask(t *testing.T) {
+	for _, test := range serviceTests {
+		if test.service.PersistenceNetmask == nil {
+			continue
+		}
+		got := newIPVSService(&test.service).toService()
+		if !bytes.Equal(got.PersistenceNetmask, test.service.PersistenceNetmask) {
+			t.Errorf("%s: got persistence netmask %v, want %v",
+				test.desc, got.PersistenceNetmask, test.service.PersistenceNetmask)
+		}
+	}
+}
+
+func TestServiceValidate(t *testing.T) {
+	tests := []struct {
+		addr    string
+		mask    net.IPMask
+		wantErr bool
+	}{
+		{"1.2.3.4", nil, false},
+		{"1.2.3.4", net.CIDRMask(24, 32), false},
+		{"1.2.3.4", net.CIDRMask(64, 128), true},
+		{"1.2.3.4", net.CIDRMask(0, 32), true},
+		{"1.2.3.4", net.IPMask{0xff, 0, 0xff, 0}, true},
+		{"2002::cafe", net.CIDRMask(64, 128), false},
+		{"2002::cafe", net.CIDRMask(24, 32), true},
+	}
+	for _, test := range tests {
+		svc := Service{Address: net.ParseIP(test.addr), PersistenceNetmask: test.mask}
+		if err := svc.validate(); (err != nil) != test.wantErr {
+			t.Errorf("validate() for %v with mask %v = %v, want error %v", test.addr, test.mask, err, test.wantErr)
+		}
+	}
+
+	onePacketTests := []struct {
+		svc     Service
+		wantErr bool
+	}{
+		{Service{Address: net.ParseIP("1.2.3.4"), Protocol: syscall.IPPROTO_UDP, Port: 53, OnePacket: true}, false},
+		{Service{Address: net.ParseIP("1.2.3.4"), Protocol: syscall.IPPROTO_TCP, Port: 53, OnePacket: true}, true},
+		{Service{Address: net.ParseIP("1.2.3.4"), Protocol: syscall.IPPROTO_TCP, Port: 53, Flags: SFOnePacket}, true},
+		{Service{Address: net.ParseIP("1.2.3.4"), FirewallMark: 1, OnePacket: true}, false},
+	}
+	for _, test := range onePacketTests {
+		if err := test.svc.validate(); (err != nil) != test.wantErr {
+			t.Errorf("validate() for %v with one-packet scheduling = %v, want error %v", test.svc, err, test.wantErr)
+		}
+	}
+
+	schedFlagTests := []struct {
+		scheduler string
+		flags     ServiceFlags
+		wantErr   bool
+	}{
+		{"mh", SFSchedMHFallback | SFSchedMHPort, false},
+		{"mh", SFSchedFlag3, true},
+		{"wrr", SFSchedMHFallback, true},
+		{"wrr", SFPersistent, false},
+	}
+	for _, test := range schedFlagTests {
+		svc := Service{Address: net.ParseIP("1.2.3.4"), Scheduler: test.scheduler, Flags: test.flags}
+		if err := svc.validate(); (err != nil) != test.wantErr {
+			t.Errorf("validate() for %v with flags 0x%x = %v, want error %v", svc, uint32(test.flags), err, test.wantErr)
+		}
+	}
+}
+
 func TestIPVSServiceToService(t *testing.T) {
 	for _, test := range ipvsServiceTests {
 		got := test.ipvsService.toService()
@@ -289,7 +402,7 @@ var serviceTests = []struct {
 			Port:              54321,
 			FirewallMark:      1,
 			Scheduler:         "wlc",
-			Flags:             0,
+			Flags:             SFPersistent,
 			Timeout:           100000,
 			Netmask:           0xffffffff,
 			AddrFamily:        syscall.AF_INET,
@@ -297,6 +410,86 @@ var serviceTests = []struct {
 			PersistenceEngine: "",
 		},
 	},
+	{
+		"IPv4 1.2.3.4 with TCP/443 persistent per /24",
+		Service{
+			Address:            net.ParseIP("1.2.3.4"),
+			Protocol:           syscall.IPPROTO_TCP,
+			Port:               443,
+			Scheduler:          "wrr",
+			Timeout:            300,
+			PersistenceNetmask: net.CIDRMask(24, 32),
+		},
+		ipvsService{
+			Protocol:   syscall.IPPROTO_TCP,
+			Port:       443,
+			Scheduler:  "wrr",
+			Flags:      SFPersistent,
+			Timeout:    300,
+			Netmask:    binary.NativeEndian.Uint32([]byte{0xff, 0xff, 0xff, 0}),
+			AddrFamily: syscall.AF_INET,
+			Address:    net.ParseIP("1.2.3.4"),
+		},
+	},
+	{
+		"IPv6 2002::cafe with TCP/443 persistent per /64",
+		Service{
+			Address:            net.ParseIP("2002::cafe"),
+			Protocol:           syscall.IPPROTO_TCP,
+			Port:               443,
+			Scheduler:          "wrr",
+			Timeout:            300,
+			PersistenceNetmask: net.CIDRMask(64, 128),
+		},
+		ipvsService{
+			Protocol:   syscall.IPPROTO_TCP,
+			Port:       443,
+			Scheduler:  "wrr",
+			Flags:      SFPersistent,
+			Timeout:    300,
+			Netmask:    64,
+			AddrFamily: syscall.AF_INET6,
+			Address:    net.ParseIP("2002::cafe"),
+		},
+	},
+	{
+		"IPv4 1.2.3.4 with UDP/53 using one-packet scheduling",
+		Service{
+			Address:   net.ParseIP("1.2.3.4"),
+			Protocol:  syscall.IPPROTO_UDP,
+			Port:      53,
+			Scheduler: "wrr",
+			OnePacket: true,
+		},
+		ipvsService{
+			Protocol:   syscall.IPPROTO_UDP,
+			Port:       53,
+			Scheduler:  "wrr",
+			Flags:      SFOnePacket,
+			Netmask:    0xffffffff,
+			AddrFamily: syscall.AF_INET,
+			Address:    net.ParseIP("1.2.3.4"),
+		},
+	},
+	{
+		"IPv4 1.2.3.4 with TCP/443 using mh",
+		Service{
+			Address:   net.ParseIP("1.2.3.4"),
+			Protocol:  syscall.IPPROTO_TCP,
+			Port:      443,
+			Scheduler: "mh",
+			Flags:     SFSchedMHFallback | SFSchedMHPort,
+		},
+		ipvsService{
+			Protocol:   syscall.IPPROTO_TCP,
+			Port:       443,
+			Scheduler:  "mh",
+			Flags:      SFSchedMHFallback | SFSchedMHPort,
+			Netmask:    0xffffffff,
+			AddrFamily: syscall.AF_INET,
+			Address:    net.ParseIP("1.2.3.4"),
+		},
+	},
 	{
 		"IPv6 2002::cafe with UDP/53",
 		Service{
@@ -359,6 +552,7 @@ var destinationTests = []struct {
 			Weight:         2,
 			UpperThreshold: 100000,
 			LowerThreshold: 10000,
+			AddrFamily:     syscall.AF_INET,
 			Address:        net.ParseIP("1.2.3.4"),
 		},
 	},
@@ -378,6 +572,7 @@ var destinationTests = []struct {
 			Weight:         3,
 			UpperThreshold: 0,
 			LowerThreshold: 0,
+			AddrFamily:     syscall.AF_INET6,
 			Address:        net.ParseIP("2002::cafe"),
 		},
 	},
@@ -610,3 +805,838 @@ func TestServiceNetlinkUnmarshal(t *testing.T) {
 		t.Errorf("Got IPVS service %#v, want %#v", got.Service, &want)
 	}
 }
+
+var errFakeNotFound = errors.New("not found")
+
+// fakeFlusher is a serviceFlusher that records the operations performed.
+type fakeFlusher struct {
+	dsts    []*Destination
+	dstsErr error
+	dstErrs map[string]error
+	svcErr  error
+	ops     []string
+}
+
+func (f *fakeFlusher) destinations(svc *Service) ([]*Destination, error) {
+	f.ops = append(f.ops, "get")
+	return f.dsts, f.dstsErr
+}
+
+func (f *fakeFlusher) deleteDestination(svc Service, dst Destination) error {
+	f.ops = append(f.ops, fmt.Sprintf("delete destination %v", dst.Address))
+	return f.dstErrs[dst.Address.String()]
+}
+
+func (f *fakeFlusher) deleteService(svc Service) error {
+	f.ops = append(f.ops, "delete service")
+	return f.svcErr
+}
+
+func (f *fakeFlusher) isNotFound(err error) bool {
+	return err == errFakeNotFound
+}
+
+func TestFlushService(t *testing.T) {
+	svc := Service{Address: net.ParseIP("192.168.1.1"), Protocol: syscall.IPPROTO_TCP, Port: 80}
+	dsts := []*Destination{
+		{Address: net.ParseIP("10.0.0.1"), Port: 80},
+		{Address: net.ParseIP("10.0.0.2"), Port: 80},
+	}
+	allOps := []string{"get", "delete destination 10.0.0.1", "delete destination 10.0.0.2", "delete service"}
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		desc    string
+		flusher *fakeFlusher
+		wantOps []string
+		wantErr bool
+	}{
+		{
+			desc:    "destinations before service",
+			flusher: &fakeFlusher{dsts: dsts},
+			wantOps: allOps,
+		},
+		{
+			desc:    "no destinations",
+			flusher: &fakeFlusher{},
+			wantOps: []string{"get", "delete service"},
+		},
+		{
+			desc:    "service not found",
+			flusher: &fakeFlusher{dstsErr: errFakeNotFound},
+			wantOps: []string{"get"},
+		},
+		{
+			desc:    "destination not found",
+			flusher: &fakeFlusher{dsts: dsts, dstErrs: map[string]error{"10.0.0.1": errFakeNotFound}},
+			wantOps: allOps,
+		},
+		{
+			desc:    "service removed after destinations",
+			flusher: &fakeFlusher{dsts: dsts, svcErr: errFakeNotFound},
+			wantOps: allOps,
+		},
+		{
+			desc:    "get destinations failure",
+			flusher: &fakeFlusher{dstsErr: errFailed},
+			wantOps: []string{"get"},
+			wantErr: true,
+		},
+		{
+			desc:    "delete destination failure",
+			flusher: &fakeFlusher{dsts: dsts, dstErrs: map[string]error{"10.0.0.1": errFailed}},
+			wantOps: []string{"get", "delete destination 10.0.0.1"},
+			wantErr: true,
+		},
+		{
+			desc:    "delete service failure",
+			flusher: &fakeFlusher{dsts: dsts, svcErr: errFailed},
+			wantOps: allOps,
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		err := flushService(test.flusher, svc)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("%s: flushService() error = %v, want error %v", test.desc, err, test.wantErr)
+		}
+		if !reflect.DeepEqual(test.flusher.ops, test.wantOps) {
+			t.Errorf("%s: flushService() operations = %q, want %q", test.desc, test.flusher.ops, test.wantOps)
+		}
+	}
+}
+
+// fakeTransactor is a transactor that records the operations applied. Services
+// that are ensured are added to its IPVS state.
+type fakeTransactor struct {
+	svcs   map[string]*Service
+	errs   map[string]error
+	ops    []string
+	svcErr error
+}
+
+func (f *fakeTransactor) service(svc Service) (*Service, error) {
+	if s, ok := f.svcs[svc.Address.String()]; ok {
+		return s, nil
+	}
+	if f.svcErr != nil {
+		return nil, f.svcErr
+	}
+	return nil, ErrNotFound
+}
+
+func (f *fakeTransactor) apply(op *Operation) error {
+	desc := fmt.Sprintf("%v %v", op.Type, op.Service.Address)
+	if op.Destination != nil {
+		desc = fmt.Sprintf("%v %v/%v weight %d", op.Type, op.Service.Address, op.Destination.Address, op.Destination.Weight)
+	}
+	f.ops = append(f.ops, desc)
+	err := f.errs[desc]
+	if _, ok := f.svcs[op.Service.Address.String()]; !ok && err == nil && op.Type == OpEnsureService {
+		svc := op.Service
+		f.svcs[svc.Address.String()] = &svc
+	}
+	return err
+}
+
+func TestTransaction(t *testing.T) {
+	newSvc := Service{Address: net.ParseIP("192.168.1.1"), Protocol: syscall.IPPROTO_TCP, Port: 80}
+	oldSvc := Service{Address: net.ParseIP("192.168.1.2"), Protocol: syscall.IPPROTO_TCP, Port: 80}
+	dst1 := Destination{Address: net.ParseIP("10.0.0.1"), Port: 80, Weight: 1}
+	dst2 := Destination{Address: net.ParseIP("10.0.0.2"), Port: 80, Weight: 1}
+	current := map[string]*Service{
+		"192.168.1.2": {
+			Address:      oldSvc.Address,
+			Protocol:     oldSvc.Protocol,
+			Port:         oldSvc.Port,
+			Destinations: []*Destination{{Address: dst1.Address, Port: 80, Weight: 5}},
+		},
+	}
+	errFailed := errors.New("failed")
+
+	newTxn := func() *Transaction {
+		txn := Begin()
+		// Recorded out of dependency order.
+		txn.DeleteService(oldSvc)
+		txn.AddDestination(newSvc, dst2)
+		txn.DeleteDestination(oldSvc, dst1)
+		svc := newSvc
+		svc.Destinations = []*Destination{&dst1}
+		txn.AddService(svc)
+		return txn
+	}
+	allOps := []string{
+		"add service 192.168.1.1",
+		"delete destination 192.168.1.2/10.0.0.1 weight 1",
+		"add destination 192.168.1.1/10.0.0.2 weight 1",
+		"add destination 192.168.1.1/10.0.0.1 weight 1",
+		"delete service 192.168.1.2",
+	}
+
+	tests := []struct {
+		desc       string
+		transactor *fakeTransactor
+		wantOps    []string
+		wantErr    bool
+	}{
+		{
+			desc:       "success",
+			transactor: &fakeTransactor{svcs: current},
+			wantOps:    allOps,
+		},
+		{
+			desc: "add destination failure",
+			transactor: &fakeTransactor{svcs: current, errs: map[string]error{
+				"add destination 192.168.1.1/10.0.0.1 weight 1": errFailed,
+			}},
+			wantOps: append(allOps[:4:4],
+				"delete destination 192.168.1.1/10.0.0.2 weight 1",
+				"add destination 192.168.1.2/10.0.0.1 weight 5",
+				"delete service 192.168.1.1",
+			),
+			wantErr: true,
+		},
+		{
+			desc:       "current state unavailable",
+			transactor: &fakeTransactor{svcErr: errFailed},
+			wantOps:    []string{"add service 192.168.1.1", "delete service 192.168.1.1"},
+			wantErr:    true,
+		},
+		{
+			desc: "rollback failure",
+			transactor: &fakeTransactor{svcs: current, errs: map[string]error{
+				"delete service 192.168.1.2": errFailed,
+				"delete service 192.168.1.1": errFailed,
+			}},
+			wantOps: append(allOps[:5:5],
+				"delete destination 192.168.1.1/10.0.0.1 weight 1",
+				"delete destination 192.168.1.1/10.0.0.2 weight 1",
+				"add destination 192.168.1.2/10.0.0.1 weight 5",
+				"delete service 192.168.1.1",
+			),
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		err := newTxn().commit(test.transactor)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("%s: commit() error = %v, want error %v", test.desc, err, test.wantErr)
+		}
+		if !reflect.DeepEqual(test.transactor.ops, test.wantOps) {
+			t.Errorf("%s: commit() operations = %q, want %q", test.desc, test.transactor.ops, test.wantOps)
+		}
+	}
+}
+
+func TestTransactionEnsure(t *testing.T) {
+	existingSvc := Service{Address: net.ParseIP("192.168.1.1"), Protocol: syscall.IPPROTO_TCP, Port: 80}
+	newSvc := Service{Address: net.ParseIP("192.168.1.2"), Protocol: syscall.IPPROTO_TCP, Port: 80}
+	dst1 := Destination{Address: net.ParseIP("10.0.0.1"), Port: 80, Weight: 1}
+	dst2 := Destination{Address: net.ParseIP("10.0.0.2"), Port: 80, Weight: 1}
+	errFailed := errors.New("failed")
+
+	// The kernel already has the existing service with a single destination,
+	// for example after an engine restart.
+	current := func() map[string]*Service {
+		return map[string]*Service{
+			"192.168.1.1": {
+				Address:      existingSvc.Address,
+				Protocol:     existingSvc.Protocol,
+				Port:         existingSvc.Port,
+				Destinations: []*Destination{{Address: dst1.Address, Port: 80, Weight: 5}},
+			},
+		}
+	}
+
+	newTxn := func(svc Service) *Transaction {
+		txn := Begin()
+		svc.Destinations = []*Destination{&dst1, &dst2}
+		txn.EnsureService(svc)
+		return txn
+	}
+	ensureOps := func(addr string) []string {
+		return []string{
+			"ensure service " + addr,
+			"ensure destination " + addr + "/10.0.0.1 weight 1",
+			"ensure destination " + addr + "/10.0.0.2 weight 1",
+		}
+	}
+
+	tests := []struct {
+		desc       string
+		svc        Service
+		transactor *fakeTransactor
+		wantOps    []string
+		wantErr    bool
+	}{
+		{
+			desc:       "existing service",
+			svc:        existingSvc,
+			transactor: &fakeTransactor{svcs: current()},
+			wantOps:    ensureOps("192.168.1.1"),
+		},
+		{
+			desc:       "new service",
+			svc:        newSvc,
+			transactor: &fakeTransactor{svcs: current()},
+			wantOps:    ensureOps("192.168.1.2"),
+		},
+		{
+			desc: "existing service rolled back to its current state",
+			svc:  existingSvc,
+			transactor: &fakeTransactor{svcs: current(), errs: map[string]error{
+				"ensure destination 192.168.1.1/10.0.0.2 weight 1": errFailed,
+			}},
+			wantOps: append(ensureOps("192.168.1.1"),
+				"update destination 192.168.1.1/10.0.0.1 weight 5",
+				"update service 192.168.1.1",
+			),
+			wantErr: true,
+		},
+		{
+			desc: "new service rolled back by deletion",
+			svc:  newSvc,
+			transactor: &fakeTransactor{svcs: current(), errs: map[string]error{
+				"ensure destination 192.168.1.2/10.0.0.1 weight 1": errFailed,
+			}},
+			wantOps: append(ensureOps("192.168.1.2")[:2:2],
+				"delete service 192.168.1.2",
+			),
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		err := newTxn(test.svc).commit(test.transactor)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("%s: commit() error = %v, want error %v", test.desc, err, test.wantErr)
+		}
+		if !reflect.DeepEqual(test.transactor.ops, test.wantOps) {
+			t.Errorf("%s: commit() operations = %q, want %q", test.desc, test.transactor.ops, test.wantOps)
+		}
+	}
+}
+
+func TestSetSchedFlags(t *testing.T) {
+	tests := []struct {
+		scheduler string
+		flags     ServiceFlags
+		want      ServiceFlags
+		wantErr   bool
+	}{
+		{"sh", SFSchedSHFallback | SFSchedSHPort, SFPersistent | SFSchedSHFallback | SFSchedSHPort, false},
+		{"sh", SFSchedSHPort, SFPersistent | SFSchedSHPort, false},
+		{"mh", SFSchedMHFallback, SFPersistent | SFSchedMHFallback, false},
+		{"wrr", 0, SFPersistent, false},
+		{"sh", SFSchedFlag3, SFPersistent | SFSchedFlag1, true},
+		{"wrr", SFSchedFlag1, SFPersistent | SFSchedFlag1, true},
+		{"sh", SFOnePacket, SFPersistent | SFSchedFlag1, true},
+	}
+	for _, test := range tests {
+		svc := &Service{Scheduler: test.scheduler, Flags: SFPersistent | SFSchedFlag1}
+		err := svc.SetSchedFlags(test.flags)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("SetSchedFlags(%q, 0x%x) error = %v, want error %v", test.scheduler, uint32(test.flags), err, test.wantErr)
+		}
+		if svc.Flags != test.want {
+			t.Errorf("SetSchedFlags(%q, 0x%x) flags = 0x%x, want 0x%x", test.scheduler, uint32(test.flags), uint32(svc.Flags), uint32(test.want))
+		}
+	}
+}
+
+func TestSetMHFlags(t *testing.T) {
+	svc := &Service{Scheduler: "mh", Flags: SFPersistent}
+	if err := svc.SetMHFallback(true); err != nil {
+		t.Fatalf("SetMHFallback(true) failed: %v", err)
+	}
+	if err := svc.SetMHPort(true); err != nil {
+		t.Fatalf("SetMHPort(true) failed: %v", err)
+	}
+	if want := SFPersistent | SFSchedMHFallback | SFSchedMHPort; svc.Flags != want {
+		t.Errorf("Got flags 0x%x, want 0x%x", uint32(svc.Flags), uint32(want))
+	}
+	if err := svc.SetMHFallback(false); err != nil {
+		t.Fatalf("SetMHFallback(false) failed: %v", err)
+	}
+	if want := SFPersistent | SFSchedMHPort; svc.Flags != want {
+		t.Errorf("Got flags 0x%x, want 0x%x", uint32(svc.Flags), uint32(want))
+	}
+
+	svc = &Service{Scheduler: "sh"}
+	if err := svc.SetMHFallback(true); err == nil {
+		t.Error("SetMHFallback(true) succeeded for sh scheduler, want error")
+	}
+	if err := svc.SetMHPort(true); err == nil {
+		t.Error("SetMHPort(true) succeeded for sh scheduler, want error")
+	}
+	if svc.Flags != 0 {
+		t.Errorf("Got flags 0x%x for sh scheduler, want 0", uint32(svc.Flags))
+	}
+}
+
+func TestDummyBackendUpdateScheduler(t *testing.T) {
+	b := NewDummyBackend()
+	svc := Service{
+		Address:   net.ParseIP("1.2.3.4"),
+		Protocol:  syscall.IPPROTO_TCP,
+		Port:      80,
+		Scheduler: "wrr",
+	}
+	dst := Destination{Address: net.ParseIP("10.0.0.1"), Port: 80, Weight: 1}
+	svc.Destinations = []*Destination{&dst}
+	if err := b.AddService(svc); err != nil {
+		t.Fatalf("AddService failed: %v", err)
+	}
+
+	mh := svc
+	mh.Destinations = nil
+	mh.Scheduler = "mh"
+	if err := mh.SetMHFallback(true); err != nil {
+		t.Fatalf("SetMHFallback failed: %v", err)
+	}
+	if err := mh.SetMHPort(true); err != nil {
+		t.Fatalf("SetMHPort failed: %v", err)
+	}
+	if err := b.UpdateService(mh); err != nil {
+		t.Fatalf("UpdateService failed: %v", err)
+	}
+	got, err := b.GetService(svc)
+	if err != nil {
+		t.Fatalf("GetService failed: %v", err)
+	}
+	if got.Scheduler != "mh" || got.Flags != SFSchedMHFallback|SFSchedMHPort {
+		t.Errorf("Got service %v with flags 0x%x, want mh with flags 0x%x", got, uint32(got.Flags), uint32(SFSchedMHFallback|SFSchedMHPort))
+	}
+	if len(got.Destinations) != 1 {
+		t.Errorf("Got %d destinations after update, want 1", len(got.Destinations))
+	}
+
+	// The mh flags must be cleared when changing to another scheduler.
+	wrr := mh
+	wrr.Scheduler = "wrr"
+	if err := b.UpdateService(wrr); err == nil {
+		t.Error("UpdateService succeeded with mh flags for wrr scheduler, want error")
+	}
+	if err := wrr.SetSchedFlags(0); err != nil {
+		t.Fatalf("SetSchedFlags failed: %v", err)
+	}
+	if err := b.UpdateService(wrr); err != nil {
+		t.Fatalf("UpdateService failed: %v", err)
+	}
+	if got, err = b.GetService(svc); err != nil {
+		t.Fatalf("GetService failed: %v", err)
+	}
+	if got.Scheduler != "wrr" || got.Flags != 0 {
+		t.Errorf("Got service %v with flags 0x%x, want wrr with flags 0", got, uint32(got.Flags))
+	}
+}
+
+const testConnectionTable = `Pro FromIP   FPrt ToIP     TPrt DestIP   DPrt State       Expires PEName PEData
+TCP C0A82401 D4E2 C0A8FF01 0050 01010A0A 0050 ESTABLISHED     899
+UDP C0A82402 8F1C C0A8FF01 0035 01010A0B 0035 UDP             298
+TCP 2012:0000:0000:0000:0000:0000:0000:0036 C350 2012:0000:0000:0000:0000:0000:0000:0001 0050 2012:0000:0000:0000:0000:0000:0000:000a 0050 FIN_WAIT        100
+ESP C0A82403 0000 C0A8FF02 0000 01010A0C 0000 NONE            60
+IP_47 C0A82404 0000 C0A8FF02 0000 01010A0C 0000 NONE            60
+XYZ C0A82405 0000 C0A8FF02 0000 01010A0C 0000 NONE            60
+`
+
+func TestReadConnections(t *testing.T) {
+	tests := []struct {
+		desc   string
+		filter ConnectionFilter
+		max    int
+		want   []string
+	}{
+		{
+			"all connections",
+			ConnectionFilter{},
+			0,
+			[]string{
+				"TCP 192.168.36.1:54498 -> 192.168.255.1:80 -> 1.1.10.10:80 ESTABLISHED expires 14m59s",
+				"UDP 192.168.36.2:36636 -> 192.168.255.1:53 -> 1.1.10.11:53 UDP expires 4m58s",
+				"TCP [2012::36]:50000 -> [2012::1]:80 -> [2012::a]:80 FIN_WAIT expires 1m40s",
+				"IP(50) 192.168.36.3:0 -> 192.168.255.2:0 -> 1.1.10.12:0 NONE expires 1m0s",
+				"IP(47) 192.168.36.4:0 -> 192.168.255.2:0 -> 1.1.10.12:0 NONE expires 1m0s",
+			},
+		},
+		{
+			"TCP connections",
+			ConnectionFilter{Protocol: syscall.IPPROTO_TCP},
+			0,
+			[]string{
+				"TCP 192.168.36.1:54498 -> 192.168.255.1:80 -> 1.1.10.10:80 ESTABLISHED expires 14m59s",
+				"TCP [2012::36]:50000 -> [2012::1]:80 -> [2012::a]:80 FIN_WAIT expires 1m40s",
+			},
+		},
+		{
+			"connections to a service",
+			ConnectionFilter{Protocol: syscall.IPPROTO_UDP, VirtualAddress: net.ParseIP("192.168.255.1"), VirtualPort: 53},
+			0,
+			[]string{
+				"UDP 192.168.36.2:36636 -> 192.168.255.1:53 -> 1.1.10.11:53 UDP expires 4m58s",
+			},
+		},
+		{
+			"first connection",
+			ConnectionFilter{VirtualAddress: net.ParseIP("192.168.255.1")},
+			1,
+			[]string{
+				"TCP 192.168.36.1:54498 -> 192.168.255.1:80 -> 1.1.10.10:80 ESTABLISHED expires 14m59s",
+			},
+		},
+	}
+	for _, test := range tests {
+		var got []string
+		err := readConnections(strings.NewReader(testConnectionTable), test.filter, func(c *Connection) bool {
+			got = append(got, c.String())
+			return test.max == 0 || len(got) < test.max
+		})
+		if err != nil {
+			t.Errorf("%s: readConnections failed: %v", test.desc, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got connections %q, want %q", test.desc, got, test.want)
+		}
+	}
+}
+
+func TestParseConnectionErrors(t *testing.T) {
+	for _, entry := range []string{
+		"TCP C0A82401 D4E2 C0A8FF01 0050 01010A0A 0050 ESTABLISHED",
+		"XYZ C0A82401 D4E2 C0A8FF01 0050 01010A0A 0050 ESTABLISHED 899",
+		"IP_256 C0A82401 D4E2 C0A8FF01 0050 01010A0A 0050 ESTABLISHED 899",
+		"TCP C0A8240Z D4E2 C0A8FF01 0050 01010A0A 0050 ESTABLISHED 899",
+		"TCP C0A82401 D4E2X C0A8FF01 0050 01010A0A 0050 ESTABLISHED 899",
+		"TCP C0A82401 D4E2 C0A8FF01 0050 01010A0A 0050 ESTABLISHED -1",
+	} {
+		if c, err := parseConnection(entry); err == nil {
+			t.Errorf("parseConnection(%q) = %v, want error", entry, c)
+		}
+	}
+	if c, err := parseConnection("TCP C0A82401 D4E2 C0A8FF01 0050 01010A0A 0050 ESTABLISHED 899"); err != nil {
+		t.Errorf("parseConnection failed: %v", err)
+	} else if c.Expires != 899*time.Second {
+		t.Errorf("parseConnection got expiry %v, want %v", c.Expires, 899*time.Second)
+	}
+}
+
+func TestTimeoutsValidate(t *testing.T) {
+	tests := []struct {
+		timeouts Timeouts
+		wantErr  bool
+	}{
+		{Timeouts{TCP: 900 * time.Second, TCPFin: 120 * time.Second, UDP: 300 * time.Second}, false},
+		{Timeouts{UDP: 30 * time.Second}, false},
+		{Timeouts{}, true},
+		{Timeouts{TCP: -time.Second}, true},
+		{Timeouts{TCP: 1500 * time.Millisecond}, true},
+		{Timeouts{TCPFin: maxTimeout + time.Second}, true},
+	}
+	for _, test := range tests {
+		if err := test.timeouts.Validate(); (err != nil) != test.wantErr {
+			t.Errorf("Validate() for %v = %v, want error %v", test.timeouts, err, test.wantErr)
+		}
+	}
+}
+
+func TestDestinationTunnel(t *testing.T) {
+	tests := []struct {
+		dst     Destination
+		wantErr bool
+	}{
+		{Destination{Flags: DFForwardRoute}, false},
+		{Destination{Flags: DFForwardTunnel}, false},
+		{Destination{Flags: DFForwardTunnel, TunnelType: TunnelGUE, TunnelPort: 6080}, false},
+		{Destination{Flags: DFForwardTunnel, TunnelType: TunnelGUE, TunnelPort: 6080, TunnelChecksum: TunnelChecksumRemote}, false},
+		{Destination{Flags: DFForwardTunnel, TunnelType: TunnelGRE, TunnelChecksum: TunnelChecksumCSum}, false},
+		{Destination{Flags: DFForwardRoute, TunnelType: TunnelGUE, TunnelPort: 6080}, true},
+		{Destination{Flags: DFForwardTunnel, TunnelType: TunnelGUE}, true},
+		{Destination{Flags: DFForwardTunnel, TunnelType: TunnelGRE, TunnelPort: 6080}, true},
+		{Destination{Flags: DFForwardTunnel, TunnelType: TunnelGRE, TunnelChecksum: TunnelChecksumRemote}, true},
+		{Destination{Flags: DFForwardTunnel, TunnelPort: 6080}, true},
+		{Destination{Flags: DFForwardTunnel, TunnelType: 3}, true},
+		{Destination{Flags: DFForwardTunnel, TunnelType: TunnelGUE, TunnelPort: 6080, TunnelChecksum: 4}, true},
+	}
+	for _, test := range tests {
+		if err := test.dst.validate(); (err != nil) != test.wantErr {
+			t.Errorf("validate() for %+v = %v, want error %v", test.dst, err, test.wantErr)
+		}
+	}
+
+	dst := Destination{
+		Address:        net.ParseIP("10.0.0.1"),
+		Flags:          DFForwardTunnel,
+		TunnelType:     TunnelGUE,
+		TunnelPort:     6080,
+		TunnelChecksum: TunnelChecksumCSum,
+	}
+	ipvsDst := newIPVSDestination(&dst)
+	if ipvsDst.TunnelType != TunnelGUE || ipvsDst.TunnelPort != 6080 || ipvsDst.TunnelFlags != TunnelChecksumCSum {
+		t.Errorf("newIPVSDestination got tunnel %v/%d/%v, want gue/6080/csum", ipvsDst.TunnelType, ipvsDst.TunnelPort, ipvsDst.TunnelFlags)
+	}
+	if got := ipvsDst.toDestination(); !got.Equal(dst) {
+		t.Errorf("toDestination() = %+v, want %+v", got, dst)
+	}
+}
+
+func TestParseTunnel(t *testing.T) {
+	for _, tt := range []TunnelType{TunnelIPIP, TunnelGUE, TunnelGRE} {
+		if got, err := ParseTunnelType(tt.String()); err != nil || got != tt {
+			t.Errorf("ParseTunnelType(%q) = %v, %v, want %v", tt.String(), got, err, tt)
+		}
+	}
+	if _, err := ParseTunnelType("vxlan"); err == nil {
+		t.Error("ParseTunnelType(\"vxlan\") succeeded, want error")
+	}
+	for _, c := range []TunnelChecksum{TunnelChecksumNone, TunnelChecksumCSum, TunnelChecksumRemote} {
+		if got, err := ParseTunnelChecksum(c.String()); err != nil || got != c {
+			t.Errorf("ParseTunnelChecksum(%q) = %v, %v, want %v", c.String(), got, err, c)
+		}
+	}
+	if _, err := ParseTunnelChecksum("nocsum"); err == nil {
+		t.Error("ParseTunnelChecksum(\"nocsum\") succeeded, want error")
+	}
+}
+
+func TestCheckTunnelSupport(t *testing.T) {
+	origKernelRelease := kernelRelease
+	defer func() { kernelRelease = origKernelRelease }()
+
+	tests := []struct {
+		release string
+		tunnel  TunnelType
+		wantErr bool
+	}{
+		{"4.19.0-17-amd64", TunnelIPIP, false},
+		{"4.19.0-17-amd64", TunnelGUE, true},
+		{"5.2.0", TunnelGUE, false},
+		{"5.2.0", TunnelGRE, true},
+		{"5.3.18-lp152", TunnelGRE, false},
+		{"6.1.0", TunnelGRE, false},
+		{"unknown", TunnelGUE, true},
+	}
+	for _, test := range tests {
+		release := test.release
+		kernelRelease = func() (string, error) { return release, nil }
+		if err := CheckTunnelSupport(test.tunnel); (err != nil) != test.wantErr {
+			t.Errorf("CheckTunnelSupport(%v) with kernel %s = %v, want error %v", test.tunnel, test.release, err, test.wantErr)
+		}
+	}
+}
+
+func TestValidateFamily(t *testing.T) {
+	origKernelRelease := kernelRelease
+	defer func() { kernelRelease = origKernelRelease }()
+
+	svc4 := Service{Address: net.ParseIP("1.1.1.1"), Protocol: syscall.IPPROTO_TCP, Port: 80}
+	svc6 := Service{Address: net.ParseIP("2001:db8::1"), Protocol: syscall.IPPROTO_TCP, Port: 80}
+	tests := []struct {
+		desc    string
+		release string
+		svc     Service
+		dst     Destination
+		wantErr bool
+	}{
+		{
+			desc:    "same family",
+			release: "3.16.0",
+			svc:     svc4,
+			dst:     Destination{Address: net.ParseIP("10.0.0.1"), Port: 80, Flags: DFForwardRoute},
+		},
+		{
+			desc:    "mixed family without tunnel",
+			release: "5.10.0",
+			svc:     svc6,
+			dst:     Destination{Address: net.ParseIP("10.0.0.1"), Port: 80, Flags: DFForwardRoute},
+			wantErr: true,
+		},
+		{
+			desc:    "mixed family tunnel",
+			release: "5.10.0",
+			svc:     svc6,
+			dst:     Destination{Address: net.ParseIP("10.0.0.1"), Port: 80, Flags: DFForwardTunnel},
+		},
+		{
+			desc:    "mixed family tunnel on 3.18",
+			release: "3.18.0",
+			svc:     svc4,
+			dst:     Destination{Address: net.ParseIP("2001:db8::10"), Port: 80, Flags: DFForwardTunnel},
+		},
+		{
+			desc:    "mixed family tunnel on old kernel",
+			release: "3.16.0-4-amd64",
+			svc:     svc4,
+			dst:     Destination{Address: net.ParseIP("2001:db8::10"), Port: 80, Flags: DFForwardTunnel},
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		release := test.release
+		kernelRelease = func() (string, error) { return release, nil }
+		if err := validateFamily(test.svc, test.dst, CheckMixedFamilySupport); (err != nil) != test.wantErr {
+			t.Errorf("%s: validateFamily() = %v, want error %v", test.desc, err, test.wantErr)
+		}
+	}
+}
+
+func TestDestinationAddrFamily(t *testing.T) {
+	dst := &Destination{Address: net.ParseIP("10.0.0.1"), Port: 80, Flags: DFForwardTunnel}
+	if got := newIPVSDestination(dst).AddrFamily; got != syscall.AF_INET {
+		t.Errorf("IPv4 destination has address family %d, want %d", got, syscall.AF_INET)
+	}
+
+	// An IPv6 address that is zero padded must not be mistaken for IPv4.
+	ip := net.ParseIP("2001:db8::")
+	ipvsDst := ipvsDestination{AddrFamily: syscall.AF_INET6, Address: net.IPv4(0x20, 0x01, 0x0d, 0xb8)}
+	if got := ipvsDst.toDestination().Address; !got.Equal(ip) {
+		t.Errorf("Got destination address %v, want %v", got, ip)
+	}
+}
+
+func TestReadSchedulerModules(t *testing.T) {
+	procModules := "ip_vs_wrr 16384 1 - Live 0x0000000000000000\n" +
+		"ip_vs 184320 5 ip_vs_wrr, Live 0x0000000000000000\n"
+	builtin := "kernel/net/netfilter/ipvs/ip_vs_rr.ko\n" +
+		"kernel/net/netfilter/nf_conntrack.ko\n"
+	dep := "kernel/net/netfilter/ipvs/ip_vs_mh.ko.zst: kernel/net/netfilter/ipvs/ip_vs.ko.zst\n" +
+		"kernel/net/netfilter/ipvs/ip_vs_ftp.ko.zst: kernel/net/netfilter/ipvs/ip_vs.ko.zst\n" +
+		"kernel/net/netfilter/ipvs/ip_vs_sh.ko: kernel/net/netfilter/ipvs/ip_vs.ko\n"
+
+	available := make(map[string]bool)
+	for _, modules := range []string{procModules, builtin, dep} {
+		if err := readSchedulerModules(strings.NewReader(modules), available); err != nil {
+			t.Fatalf("readSchedulerModules failed: %v", err)
+		}
+	}
+	want := map[string]bool{"mh": true, "rr": true, "sh": true, "wrr": true}
+	if !reflect.DeepEqual(available, want) {
+		t.Errorf("readSchedulerModules got %v, want %v", available, want)
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	unknown := &Capabilities{}
+	if !unknown.HasScheduler("mh") || !unknown.HasTunnel(TunnelGRE) || !unknown.OnePacket() || !unknown.MixedFamilies() {
+		t.Errorf("Capabilities with unknown support should allow all features")
+	}
+	for _, test := range []struct {
+		release       string
+		onePacket     bool
+		mixedFamilies bool
+	}{
+		{"3.2.0-4-amd64", false, false},
+		{"3.7.0", true, false},
+		{"3.18.0", true, true},
+		{"5.10.0-8-amd64", true, true},
+	} {
+		caps := &Capabilities{KernelRelease: test.release}
+		if caps.OnePacket() != test.onePacket || caps.MixedFamilies() != test.mixedFamilies {
+			t.Errorf("Kernel %s supports one-packet %v, mixed families %v, want %v, %v",
+				test.release, caps.OnePacket(), caps.MixedFamilies(), test.onePacket, test.mixedFamilies)
+		}
+	}
+	caps := &Capabilities{
+		Schedulers: []string{"rr", "wrr"},
+		Tunnels:    []TunnelType{TunnelIPIP, TunnelGUE},
+	}
+	if !caps.HasScheduler("wrr") || caps.HasScheduler("mh") {
+		t.Errorf("HasScheduler got wrr %v, mh %v, want true, false", caps.HasScheduler("wrr"), caps.HasScheduler("mh"))
+	}
+	if !caps.HasTunnel(TunnelGUE) || caps.HasTunnel(TunnelGRE) {
+		t.Errorf("HasTunnel got gue %v, gre %v, want true, false", caps.HasTunnel(TunnelGUE), caps.HasTunnel(TunnelGRE))
+	}
+}
+
+func TestStartSyncDaemonInvalid(t *testing.T) {
+	for _, d := range []SyncDaemon{
+		{State: 0, Interface: "eth0"},
+		{State: 3, Interface: "eth0"},
+		{State: SyncStateMaster},
+	} {
+		if err := StartSyncDaemon(d); err == nil {
+			t.Errorf("StartSyncDaemon(%v) succeeded, want error", d)
+		}
+	}
+}
+
+func TestDummyBackend(t *testing.T) {
+	b := NewDummyBackend()
+	svc := Service{
+		Address:   net.ParseIP("1.1.1.1"),
+		Protocol:  syscall.IPPROTO_TCP,
+		Port:      80,
+		Scheduler: "wrr",
+		Destinations: []*Destination{
+			{Address: net.ParseIP("10.0.0.1"), Port: 80, Weight: 1, Flags: DFForwardRoute},
+		},
+	}
+	dst := Destination{Address: net.ParseIP("10.0.0.2"), Port: 80, Weight: 1, Flags: DFForwardRoute}
+
+	if err := b.AddService(svc); err != nil {
+		t.Fatalf("AddService failed: %v", err)
+	}
+	if err := b.AddService(svc); !errors.Is(err, ErrServiceExists) {
+		t.Errorf("AddService for existing service = %v, want %v", err, ErrServiceExists)
+	}
+	if err := b.AddDestination(svc, dst); err != nil {
+		t.Errorf("AddDestination failed: %v", err)
+	}
+	if err := b.AddDestination(svc, dst); !errors.Is(err, ErrDestinationExists) {
+		t.Errorf("AddDestination for existing destination = %v, want %v", err, ErrDestinationExists)
+	}
+	if err := b.DrainDestination(svc, dst); err != nil {
+		t.Errorf("DrainDestination failed: %v", err)
+	}
+
+	other := svc
+	other.Port = 443
+	if err := b.DeleteService(other); !errors.Is(err, ErrNotFound) {
+		t.Errorf("DeleteService for missing service = %v, want %v", err, ErrNotFound)
+	}
+	if err := b.AddDestination(other, dst); !errors.Is(err, ErrNotFound) {
+		t.Errorf("AddDestination for missing service = %v, want %v", err, ErrNotFound)
+	}
+	missing := Destination{Address: net.ParseIP("10.0.0.3"), Port: 80}
+	if err := b.DeleteDestination(svc, missing); !errors.Is(err, ErrNotFound) {
+		t.Errorf("DeleteDestination for missing destination = %v, want %v", err, ErrNotFound)
+	}
+	if err := b.EnsureService(svc); err != nil {
+		t.Errorf("EnsureService for existing service failed: %v", err)
+	}
+
+	svcs := b.Snapshot()
+	if len(svcs) != 1 {
+		t.Fatalf("Got %d services, want 1", len(svcs))
+	}
+	if !svcs[0].Equal(svc) {
+		t.Errorf("Got service %v, want %v", svcs[0], svc)
+	}
+	want := []Destination{*svc.Destinations[0], dst}
+	want[1].Weight = 0
+	if len(svcs[0].Destinations) != len(want) {
+		t.Fatalf("Got %d destinations, want %d", len(svcs[0].Destinations), len(want))
+	}
+	for i, d := range svcs[0].Destinations {
+		if !d.Equal(want[i]) {
+			t.Errorf("Got destination %v with weight %d, want weight %d", d, d.Weight, want[i].Weight)
+		}
+	}
+
+	// A failed transaction must leave the table unchanged.
+	txn := Begin()
+	txn.AddService(other)
+	txn.AddDestination(svc, dst)
+	if err := b.Apply(txn); err == nil {
+		t.Error("Apply succeeded for existing destination, want error")
+	}
+	if got := b.Snapshot(); !reflect.DeepEqual(got, svcs) {
+		t.Errorf("Got services %v after failed transaction, want %v", got, svcs)
+	}
+
+	if err := b.Flush(); err != nil {
+		t.Errorf("Flush failed: %v", err)
+	}
+	if svcs := b.Snapshot(); len(svcs) != 0 {
+		t.Errorf("Got %d services after flush, want 0", len(svcs))
+	}
+}
@@ -0,0 +1,291 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipvs
+
+// This file implements transactions, which apply a set of IPVS operations
+// together and roll them back if any of them fail.
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// OpType specifies the type of an IPVS operation.
+type OpType int
+
+const (
+	OpAddService OpType = iota
+	OpUpdateService
+	OpDeleteService
+	OpAddDestination
+	OpUpdateDestination
+	OpDeleteDestination
+	OpEnsureService
+	OpEnsureDestination
+)
+
+var opTypeNames = map[OpType]string{
+	OpAddService:        "add service",
+	OpUpdateService:     "update service",
+	OpDeleteService:     "delete service",
+	OpAddDestination:    "add destination",
+	OpUpdateDestination: "update destination",
+	OpDeleteDestination: "delete destination",
+	OpEnsureService:     "ensure service",
+	OpEnsureDestination: "ensure destination",
+}
+
+// String returns the string representation of an OpType.
+func (t OpType) String() string {
+	if name, ok := opTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("(unknown %d)", int(t))
+}
+
+// Operation is an IPVS operation recorded by a Transaction.
+type Operation struct {
+	Type        OpType
+	Service     Service
+	Destination *Destination // Only set for destination operations.
+}
+
+// String returns a string representation of an Operation.
+func (op *Operation) String() string {
+	if op.Destination != nil {
+		return fmt.Sprintf("%v %v for %v", op.Type, op.Destination, op.Service)
+	}
+	return fmt.Sprintf("%v %v", op.Type, op.Service)
+}
+
+// phase returns the order in which an operation is applied. Services are
+// added or updated before their destinations are changed, destinations are
+// deleted before others are added, and services are deleted last.
+func (op *Operation) phase() int {
+	switch op.Type {
+	case OpAddService, OpUpdateService, OpEnsureService:
+		return 0
+	case OpDeleteDestination:
+		return 1
+	case OpAddDestination, OpUpdateDestination, OpEnsureDestination:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// Transaction records IPVS operations so that they can be applied together.
+type Transaction struct {
+	Operations []*Operation
+}
+
+// Begin returns a new, empty Transaction.
+func Begin() *Transaction {
+	return &Transaction{}
+}
+
+func (t *Transaction) record(opType OpType, svc Service, dst *Destination) {
+	t.Operations = append(t.Operations, &Operation{Type: opType, Service: svc, Destination: dst})
+}
+
+// AddService records the addition of a service, along with any destinations
+// associated with it. Each destination is recorded as a separate operation,
+// so that it is rolled back individually.
+func (t *Transaction) AddService(svc Service) {
+	dsts := svc.Destinations
+	svc.Destinations = nil
+	t.record(OpAddService, svc, nil)
+	for _, dst := range dsts {
+		t.AddDestination(svc, *dst)
+	}
+}
+
+// UpdateService records an update to a service.
+func (t *Transaction) UpdateService(svc Service) {
+	t.record(OpUpdateService, svc, nil)
+}
+
+// DeleteService records the deletion of a service.
+func (t *Transaction) DeleteService(svc Service) {
+	t.record(OpDeleteService, svc, nil)
+}
+
+// AddDestination records the addition of a destination to a service.
+func (t *Transaction) AddDestination(svc Service, dst Destination) {
+	t.record(OpAddDestination, svc, &dst)
+}
+
+// UpdateDestination records an update to a destination of a service.
+func (t *Transaction) UpdateDestination(svc Service, dst Destination) {
+	t.record(OpUpdateDestination, svc, &dst)
+}
+
+// DeleteDestination records the deletion of a destination from a service.
+func (t *Transaction) DeleteDestination(svc Service, dst Destination) {
+	t.record(OpDeleteDestination, svc, &dst)
+}
+
+// EnsureService records the addition of a service, or its update if it
+// already exists, along with any destinations associated with it. As with
+// AddService, each destination is recorded as a separate operation.
+func (t *Transaction) EnsureService(svc Service) {
+	dsts := svc.Destinations
+	svc.Destinations = nil
+	t.record(OpEnsureService, svc, nil)
+	for _, dst := range dsts {
+		t.EnsureDestination(svc, *dst)
+	}
+}
+
+// EnsureDestination records the addition of a destination to a service, or
+// its update if it already exists.
+func (t *Transaction) EnsureDestination(svc Service, dst Destination) {
+	t.record(OpEnsureDestination, svc, &dst)
+}
+
+// Commit applies the recorded operations to the kernel IPVS table in
+// dependency order. If an operation fails, the operations that have already
+// been applied are rolled back on a best-effort basis and any failures to do
+// so are included in the returned error.
+func (t *Transaction) Commit() error {
+	return t.commit(kernelTransactor{})
+}
+
+// transactor provides the IPVS operations needed to apply a transaction.
+type transactor interface {
+	service(svc Service) (*Service, error)
+	apply(op *Operation) error
+}
+
+// kernelTransactor applies transactions to the kernel IPVS table.
+type kernelTransactor struct{}
+
+func (kernelTransactor) service(svc Service) (*Service, error) {
+	return GetService(&svc)
+}
+
+func (kernelTransactor) apply(op *Operation) error {
+	switch op.Type {
+	case OpAddService:
+		return AddService(op.Service)
+	case OpUpdateService:
+		return UpdateService(op.Service)
+	case OpDeleteService:
+		return DeleteService(op.Service)
+	case OpAddDestination:
+		return AddDestination(op.Service, *op.Destination)
+	case OpUpdateDestination:
+		return UpdateDestination(op.Service, *op.Destination)
+	case OpDeleteDestination:
+		return DeleteDestination(op.Service, *op.Destination)
+	case OpEnsureService:
+		return EnsureService(op.Service)
+	case OpEnsureDestination:
+		return EnsureDestination(op.Service, *op.Destination)
+	}
+	return fmt.Errorf("unknown operation type %v", op.Type)
+}
+
+// commit applies the recorded operations using the given transactor.
+func (t *Transaction) commit(tr transactor) error {
+	ops := make([]*Operation, len(t.Operations))
+	copy(ops, t.Operations)
+	sort.SliceStable(ops, func(i, j int) bool {
+		return ops[i].phase() < ops[j].phase()
+	})
+
+	var undo []*Operation
+	for _, op := range ops {
+		inv, err := inverse(tr, op)
+		if err == nil {
+			err = tr.apply(op)
+		}
+		if err != nil {
+			err = fmt.Errorf("failed to %v: %v", op, err)
+			if rbErr := rollback(tr, undo); rbErr != nil {
+				return fmt.Errorf("%v; rollback failed: %v", err, rbErr)
+			}
+			return err
+		}
+		undo = append(undo, inv)
+	}
+	return nil
+}
+
+// inverse returns the operation that reverts the given operation, based on
+// the current state of the IPVS table.
+func inverse(tr transactor, op *Operation) (*Operation, error) {
+	switch op.Type {
+	case OpAddService:
+		return &Operation{Type: OpDeleteService, Service: op.Service}, nil
+	case OpAddDestination:
+		return &Operation{Type: OpDeleteDestination, Service: op.Service, Destination: op.Destination}, nil
+	}
+
+	current, err := tr.service(op.Service)
+	if op.Type == OpEnsureService && errors.Is(err, ErrNotFound) {
+		return &Operation{Type: OpDeleteService, Service: op.Service}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current state: %v", err)
+	}
+	svc := *current
+	switch op.Type {
+	case OpUpdateService, OpEnsureService:
+		return &Operation{Type: OpUpdateService, Service: svc}, nil
+	case OpDeleteService:
+		// Adding the service also adds its destinations.
+		return &Operation{Type: OpAddService, Service: svc}, nil
+	}
+
+	var dst *Destination
+	for _, d := range svc.Destinations {
+		if d.Address.Equal(op.Destination.Address) && d.Port == op.Destination.Port {
+			dst = d
+			break
+		}
+	}
+	if dst == nil && op.Type == OpEnsureDestination {
+		return &Operation{Type: OpDeleteDestination, Service: op.Service, Destination: op.Destination}, nil
+	}
+	if dst == nil {
+		return nil, fmt.Errorf("destination %v not found", op.Destination)
+	}
+	svc.Destinations = nil
+	switch op.Type {
+	case OpUpdateDestination, OpEnsureDestination:
+		return &Operation{Type: OpUpdateDestination, Service: svc, Destination: dst}, nil
+	case OpDeleteDestination:
+		return &Operation{Type: OpAddDestination, Service: svc, Destination: dst}, nil
+	}
+	return nil, fmt.Errorf("unknown operation type %v", op.Type)
+}
+
+// rollback applies the given undo operations in reverse order, continuing
+// past any failures.
+func rollback(tr transactor, undo []*Operation) error {
+	var errs []string
+	for i := len(undo) - 1; i >= 0; i-- {
+		if err := tr.apply(undo[i]); err != nil {
+			errs = append(errs, fmt.Sprintf("failed to %v: %v", undo[i], err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
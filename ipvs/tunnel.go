@@ -0,0 +1,218 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipvs
+
+// This file implements the encapsulation options for tunnelled destinations.
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"syscall"
+)
+
+// TunnelType specifies the encapsulation used for a tunnelled destination.
+type TunnelType uint8
+
+const (
+	TunnelIPIP TunnelType = iota
+	TunnelGUE
+	TunnelGRE
+)
+
+var tunnelTypeNames = map[TunnelType]string{
+	TunnelIPIP: "ipip",
+	TunnelGUE:  "gue",
+	TunnelGRE:  "gre",
+}
+
+// String returns the name of a TunnelType.
+func (t TunnelType) String() string {
+	if name, ok := tunnelTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("(unknown %d)", uint8(t))
+}
+
+// ParseTunnelType returns the TunnelType with the given name.
+func ParseTunnelType(name string) (TunnelType, error) {
+	for t, n := range tunnelTypeNames {
+		if strings.EqualFold(name, n) {
+			return t, nil
+		}
+	}
+	return TunnelIPIP, fmt.Errorf("unknown tunnel type %q", name)
+}
+
+// TunnelChecksum specifies the checksum handling for GUE and GRE
+// encapsulation.
+type TunnelChecksum uint16
+
+const (
+	TunnelChecksumNone   TunnelChecksum = 0
+	TunnelChecksumCSum   TunnelChecksum = 1 << 0
+	TunnelChecksumRemote TunnelChecksum = 1 << 1
+)
+
+var tunnelChecksumNames = map[TunnelChecksum]string{
+	TunnelChecksumNone:   "none",
+	TunnelChecksumCSum:   "csum",
+	TunnelChecksumRemote: "remcsum",
+}
+
+// String returns the name of a TunnelChecksum.
+func (c TunnelChecksum) String() string {
+	if name, ok := tunnelChecksumNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("(unknown %d)", uint16(c))
+}
+
+// ParseTunnelChecksum returns the TunnelChecksum with the given name.
+func ParseTunnelChecksum(name string) (TunnelChecksum, error) {
+	for c, n := range tunnelChecksumNames {
+		if strings.EqualFold(name, n) {
+			return c, nil
+		}
+	}
+	return TunnelChecksumNone, fmt.Errorf("unknown tunnel checksum %q", name)
+}
+
+// ValidateTunnel returns an error if the given encapsulation options are not
+// consistent.
+func ValidateTunnel(t TunnelType, port uint16, csum TunnelChecksum) error {
+	switch t {
+	case TunnelIPIP:
+		if port != 0 || csum != TunnelChecksumNone {
+			return errors.New("ipip tunnels do not support a port or checksum")
+		}
+	case TunnelGUE:
+		if port == 0 {
+			return errors.New("gue tunnels require a port")
+		}
+	case TunnelGRE:
+		if port != 0 {
+			return errors.New("gre tunnels do not support a port")
+		}
+		if csum == TunnelChecksumRemote {
+			return errors.New("gre tunnels do not support remote checksum offload")
+		}
+	default:
+		return fmt.Errorf("unknown tunnel type %v", t)
+	}
+	if _, ok := tunnelChecksumNames[csum]; !ok {
+		return fmt.Errorf("unknown tunnel checksum %v", csum)
+	}
+	return nil
+}
+
+// tunnelMinKernel is the minimum kernel version, as major and minor numbers,
+// that supports each tunnel type.
+var tunnelMinKernel = map[TunnelType][2]int{
+	TunnelGUE: {5, 2},
+	TunnelGRE: {5, 3},
+}
+
+// kernelRelease returns the release of the running kernel.
+var kernelRelease = func() (string, error) {
+	var u syscall.Utsname
+	if err := syscall.Uname(&u); err != nil {
+		return "", err
+	}
+	var b []byte
+	for _, c := range u.Release {
+		if c == 0 {
+			break
+		}
+		b = append(b, byte(c))
+	}
+	return string(b), nil
+}
+
+// kernelAtLeast returns true if the given kernel release is at least the
+// specified major and minor version.
+func kernelAtLeast(release string, min [2]int) (bool, error) {
+	var major, minor int
+	if _, err := fmt.Sscanf(release, "%d.%d", &major, &minor); err != nil {
+		return false, fmt.Errorf("failed to parse kernel release %q: %v", release, err)
+	}
+	return major > min[0] || major == min[0] && minor >= min[1], nil
+}
+
+// CheckTunnelSupport returns a descriptive error if the running kernel does
+// not support the given tunnel type, which it would otherwise reject with
+// EINVAL when the destination is added.
+func CheckTunnelSupport(t TunnelType) error {
+	min, ok := tunnelMinKernel[t]
+	if !ok {
+		return nil
+	}
+	release, err := kernelRelease()
+	if err != nil {
+		return fmt.Errorf("failed to get kernel release: %v", err)
+	}
+	if ok, err := kernelAtLeast(release, min); err != nil {
+		return err
+	} else if !ok {
+		return fmt.Errorf("%v tunnels require Linux %d.%d or later, running %s", t, min[0], min[1], release)
+	}
+	return nil
+}
+
+// mixedFamilyMinKernel is the minimum kernel version, as major and minor
+// numbers, that supports IPVS_DEST_ATTR_ADDR_FAMILY.
+var mixedFamilyMinKernel = [2]int{3, 18}
+
+// checkMixedFamilyRelease returns a descriptive error if the given kernel
+// release does not support destinations with a different address family to
+// their service.
+func checkMixedFamilyRelease(release string) error {
+	if ok, err := kernelAtLeast(release, mixedFamilyMinKernel); err != nil {
+		return err
+	} else if !ok {
+		return fmt.Errorf("kernel lacks IPVS_DEST_ATTR_ADDR_FAMILY support (requires Linux %d.%d or later, running %s)",
+			mixedFamilyMinKernel[0], mixedFamilyMinKernel[1], release)
+	}
+	return nil
+}
+
+// CheckMixedFamilySupport returns a descriptive error if the running kernel
+// does not support destinations with a different address family to their
+// service. Older kernels ignore the destination address family and would
+// misinterpret the destination address.
+func CheckMixedFamilySupport() error {
+	release, err := kernelRelease()
+	if err != nil {
+		return fmt.Errorf("failed to get kernel release: %v", err)
+	}
+	return checkMixedFamilyRelease(release)
+}
+
+// validateFamily returns an error if a destination's address family differs
+// from that of its service and this is not supported. The kernel only allows
+// this for tunnelled destinations. If given, checkKernel is called to confirm
+// that the kernel supports mixed address families.
+func validateFamily(svc Service, dst Destination, checkKernel func() error) error {
+	if (svc.Address.To4() != nil) == (dst.Address.To4() != nil) {
+		return nil
+	}
+	if dst.Flags&DFForwardMask != DFForwardTunnel {
+		return fmt.Errorf("destination %v for %v has a different address family and requires tunnel forwarding", dst, svc)
+	}
+	if checkKernel == nil {
+		return nil
+	}
+	return checkKernel()
+}
@@ -163,3 +163,25 @@ func (ncc *SeesawNCC) BGPWithdrawVIP(vip seesaw.VIP, unused *int) error {
 	defer bgp.Close()
 	return bgp.Withdraw(&net.IPNet{IP: ip, Mask: hostMask(ip)})
 }
+
+// BGPAdvertiseNetwork requests the Quagga BGP daemon to advertise the given
+// network.
+func (ncc *SeesawNCC) BGPAdvertiseNetwork(network *net.IPNet, unused *int) error {
+	bgp, err := quaggaBGP(seesawASN)
+	if err != nil {
+		return err
+	}
+	defer bgp.Close()
+	return bgp.Advertise(network)
+}
+
+// BGPWithdrawNetwork requests the Quagga BGP daemon to withdraw the given
+// network.
+func (ncc *SeesawNCC) BGPWithdrawNetwork(network *net.IPNet, unused *int) error {
+	bgp, err := quaggaBGP(seesawASN)
+	if err != nil {
+		return err
+	}
+	defer bgp.Close()
+	return bgp.Withdraw(network)
+}
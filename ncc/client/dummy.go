@@ -47,19 +47,90 @@ func (nc *dummyNCC) ARPSendGratuitous(arpMap map[string][]net.IP) error
 func (nc *dummyNCC) BGPConfig() ([]string, error)                                         { return nil, nil }
 func (nc *dummyNCC) BGPNeighbors() ([]*quagga.Neighbor, error)                            { return nil, nil }
 func (nc *dummyNCC) BGPWithdrawAll() error                                                { return nil }
-func (nc *dummyNCC) BGPAdvertiseVIP(vip seesaw.VIP) error                                  { return nil }
+func (nc *dummyNCC) BGPAdvertiseVIP(vip seesaw.VIP) error                                 { return nil }
 func (nc *dummyNCC) BGPWithdrawVIP(vip seesaw.VIP) error                                  { return nil }
+func (nc *dummyNCC) BGPAdvertiseNetwork(network *net.IPNet) error                         { return nil }
+func (nc *dummyNCC) BGPWithdrawNetwork(network *net.IPNet) error                          { return nil }
 func (nc *dummyNCC) IPVSFlush() error                                                     { return nil }
 func (nc *dummyNCC) IPVSGetServices() ([]*ipvs.Service, error)                            { return nil, nil }
 func (nc *dummyNCC) IPVSGetService(svc *ipvs.Service) (*ipvs.Service, error)              { return nil, nil }
 func (nc *dummyNCC) IPVSAddService(svc *ipvs.Service) error                               { return nil }
 func (nc *dummyNCC) IPVSUpdateService(svc *ipvs.Service) error                            { return nil }
 func (nc *dummyNCC) IPVSDeleteService(svc *ipvs.Service) error                            { return nil }
+func (nc *dummyNCC) IPVSFlushService(svc *ipvs.Service) error                             { return nil }
+func (nc *dummyNCC) IPVSEnsureService(svc *ipvs.Service) error                            { return nil }
 func (nc *dummyNCC) IPVSAddDestination(svc *ipvs.Service, dst *ipvs.Destination) error    { return nil }
 func (nc *dummyNCC) IPVSUpdateDestination(svc *ipvs.Service, dst *ipvs.Destination) error { return nil }
+func (nc *dummyNCC) IPVSDrainDestination(svc *ipvs.Service, dst *ipvs.Destination) error  { return nil }
 func (nc *dummyNCC) IPVSDeleteDestination(svc *ipvs.Service, dst *ipvs.Destination) error { return nil }
+func (nc *dummyNCC) IPVSEnsureDestination(svc *ipvs.Service, dst *ipvs.Destination) error { return nil }
+func (nc *dummyNCC) IPVSApply(txn *ipvs.Transaction) error                                { return nil }
+func (nc *dummyNCC) IPVSCapabilities() (*ipvs.Capabilities, error)                        { return &ipvs.Capabilities{}, nil }
+func (nc *dummyNCC) IPVSGetTimeouts() (*ipvs.Timeouts, error)                             { return &ipvs.Timeouts{}, nil }
+func (nc *dummyNCC) IPVSSetTimeouts(t *ipvs.Timeouts) error                               { return nil }
+func (nc *dummyNCC) IPVSStartSyncDaemon(d *ipvs.SyncDaemon) error                         { return nil }
+func (nc *dummyNCC) IPVSStopSyncDaemon() error                                            { return nil }
 func (nc *dummyNCC) RouteDefaultIPv4() (net.IP, error)                                    { return nil, nil }
 
+func (nc *dummyNCC) IPVSConnections(filter ipvs.ConnectionFilter, max int) (*ncctypes.IPVSConnections, error) {
+	return &ncctypes.IPVSConnections{}, nil
+}
+
+type ipvsDummyNCC struct {
+	dummyNCC
+	backend *ipvs.DummyBackend
+}
+
+// NewIPVSDummyNCC returns a dummy NCC client for testing purpose, which
+// applies IPVS operations to the given in-memory backend so that they can be
+// inspected. All other methods are no-ops.
+func NewIPVSDummyNCC(backend *ipvs.DummyBackend) NCC {
+	return &ipvsDummyNCC{backend: backend}
+}
+
+func (nc *ipvsDummyNCC) IPVSFlush() error {
+	return nc.backend.Flush()
+}
+func (nc *ipvsDummyNCC) IPVSGetServices() ([]*ipvs.Service, error) {
+	return nc.backend.GetServices()
+}
+func (nc *ipvsDummyNCC) IPVSGetService(svc *ipvs.Service) (*ipvs.Service, error) {
+	return nc.backend.GetService(*svc)
+}
+func (nc *ipvsDummyNCC) IPVSAddService(svc *ipvs.Service) error {
+	return nc.backend.AddService(*svc)
+}
+func (nc *ipvsDummyNCC) IPVSUpdateService(svc *ipvs.Service) error {
+	return nc.backend.UpdateService(*svc)
+}
+func (nc *ipvsDummyNCC) IPVSDeleteService(svc *ipvs.Service) error {
+	return nc.backend.DeleteService(*svc)
+}
+func (nc *ipvsDummyNCC) IPVSFlushService(svc *ipvs.Service) error {
+	return nc.backend.FlushService(*svc)
+}
+func (nc *ipvsDummyNCC) IPVSEnsureService(svc *ipvs.Service) error {
+	return nc.backend.EnsureService(*svc)
+}
+func (nc *ipvsDummyNCC) IPVSApply(txn *ipvs.Transaction) error {
+	return nc.backend.Apply(txn)
+}
+func (nc *ipvsDummyNCC) IPVSAddDestination(svc *ipvs.Service, dst *ipvs.Destination) error {
+	return nc.backend.AddDestination(*svc, *dst)
+}
+func (nc *ipvsDummyNCC) IPVSUpdateDestination(svc *ipvs.Service, dst *ipvs.Destination) error {
+	return nc.backend.UpdateDestination(*svc, *dst)
+}
+func (nc *ipvsDummyNCC) IPVSDrainDestination(svc *ipvs.Service, dst *ipvs.Destination) error {
+	return nc.backend.DrainDestination(*svc, *dst)
+}
+func (nc *ipvsDummyNCC) IPVSDeleteDestination(svc *ipvs.Service, dst *ipvs.Destination) error {
+	return nc.backend.DeleteDestination(*svc, *dst)
+}
+func (nc *ipvsDummyNCC) IPVSEnsureDestination(svc *ipvs.Service, dst *ipvs.Destination) error {
+	return nc.backend.EnsureDestination(*svc, *dst)
+}
+
 type DummyLBInterface struct {
 	Vips     map[seesaw.VIP]bool
 	Vlans    map[uint16]bool
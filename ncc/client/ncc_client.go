@@ -67,6 +67,14 @@ type NCC interface {
 	// specified VIP.
 	BGPWithdrawVIP(vip seesaw.VIP) error
 
+	// BGPAdvertiseNetwork requests the Quagga BGP daemon to advertise the
+	// specified network.
+	BGPAdvertiseNetwork(network *net.IPNet) error
+
+	// BGPWithdrawNetwork requests the Quagga BGP daemon to withdraw the
+	// specified network.
+	BGPWithdrawNetwork(network *net.IPNet) error
+
 	// IPVSFlush flushes all services and destinations from the IPVS table.
 	IPVSFlush() error
 
@@ -86,6 +94,14 @@ type NCC interface {
 	// IPVSDeleteService deletes the specified service from the IPVS table.
 	IPVSDeleteService(svc *ipvs.Service) error
 
+	// IPVSFlushService deletes all destinations for the specified service,
+	// then deletes the service from the IPVS table.
+	IPVSFlushService(svc *ipvs.Service) error
+
+	// IPVSEnsureService adds the specified service and its destinations
+	// to the IPVS table, updating them if they already exist.
+	IPVSEnsureService(svc *ipvs.Service) error
+
 	// IPVSAddDestination adds the specified destination to the IPVS table.
 	IPVSAddDestination(svc *ipvs.Service, dst *ipvs.Destination) error
 
@@ -93,10 +109,45 @@ type NCC interface {
 	// the IPVS table.
 	IPVSUpdateDestination(svc *ipvs.Service, dst *ipvs.Destination) error
 
+	// IPVSDrainDestination sets the weight of the specified destination
+	// in the IPVS table to zero.
+	IPVSDrainDestination(svc *ipvs.Service, dst *ipvs.Destination) error
+
 	// IPVSDeleteDestination deletes the specified destination from
 	// the IPVS table.
 	IPVSDeleteDestination(svc *ipvs.Service, dst *ipvs.Destination) error
 
+	// IPVSEnsureDestination adds the specified destination to the IPVS
+	// table, updating it if it already exists.
+	IPVSEnsureDestination(svc *ipvs.Service, dst *ipvs.Destination) error
+
+	// IPVSCapabilities returns the IPVS features supported by the
+	// running kernel.
+	IPVSCapabilities() (*ipvs.Capabilities, error)
+
+	// IPVSGetTimeouts returns the current IPVS connection timeouts.
+	IPVSGetTimeouts() (*ipvs.Timeouts, error)
+
+	// IPVSStartSyncDaemon starts the specified IPVS connection
+	// synchronisation daemon.
+	IPVSStartSyncDaemon(d *ipvs.SyncDaemon) error
+
+	// IPVSStopSyncDaemon stops any running IPVS connection
+	// synchronisation daemons.
+	IPVSStopSyncDaemon() error
+
+	// IPVSSetTimeouts sets the IPVS connection timeouts, leaving zero
+	// timeouts unchanged.
+	IPVSSetTimeouts(t *ipvs.Timeouts) error
+
+	// IPVSConnections returns up to max connections from the IPVS
+	// connection table that match the given filter.
+	IPVSConnections(filter ipvs.ConnectionFilter, max int) (*ncctypes.IPVSConnections, error)
+
+	// IPVSApply applies the operations recorded by a transaction to the
+	// IPVS table, rolling back the applied operations if any of them fail.
+	IPVSApply(txn *ipvs.Transaction) error
+
 	// RouteDefaultIPv4 returns the default route for IPv4 traffic.
 	RouteDefaultIPv4() (net.IP, error)
 }
@@ -228,6 +279,14 @@ func (nc *nccClient) BGPWithdrawVIP(vip seesaw.VIP) error {
 	return nc.call("SeesawNCC.BGPWithdrawVIP", vip, nil)
 }
 
+func (nc *nccClient) BGPAdvertiseNetwork(network *net.IPNet) error {
+	return nc.call("SeesawNCC.BGPAdvertiseNetwork", network, nil)
+}
+
+func (nc *nccClient) BGPWithdrawNetwork(network *net.IPNet) error {
+	return nc.call("SeesawNCC.BGPWithdrawNetwork", network, nil)
+}
+
 func (nc *nccClient) IPVSFlush() error {
 	return nc.call("SeesawNCC.IPVSFlush", 0, nil)
 }
@@ -260,6 +319,14 @@ func (nc *nccClient) IPVSDeleteService(svc *ipvs.Service) error {
 	return nc.call("SeesawNCC.IPVSDeleteService", svc, nil)
 }
 
+func (nc *nccClient) IPVSEnsureService(svc *ipvs.Service) error {
+	return nc.call("SeesawNCC.IPVSEnsureService", svc, nil)
+}
+
+func (nc *nccClient) IPVSFlushService(svc *ipvs.Service) error {
+	return nc.call("SeesawNCC.IPVSFlushService", svc, nil)
+}
+
 func (nc *nccClient) IPVSAddDestination(svc *ipvs.Service, dst *ipvs.Destination) error {
 	ipvsDst := ncctypes.IPVSDestination{Service: svc, Destination: dst}
 	return nc.call("SeesawNCC.IPVSAddDestination", ipvsDst, nil)
@@ -270,11 +337,62 @@ func (nc *nccClient) IPVSUpdateDestination(svc *ipvs.Service, dst *ipvs.Destinat
 	return nc.call("SeesawNCC.IPVSUpdateDestination", ipvsDst, nil)
 }
 
+func (nc *nccClient) IPVSDrainDestination(svc *ipvs.Service, dst *ipvs.Destination) error {
+	ipvsDst := ncctypes.IPVSDestination{Service: svc, Destination: dst}
+	return nc.call("SeesawNCC.IPVSDrainDestination", ipvsDst, nil)
+}
+
 func (nc *nccClient) IPVSDeleteDestination(svc *ipvs.Service, dst *ipvs.Destination) error {
 	ipvsDst := ncctypes.IPVSDestination{Service: svc, Destination: dst}
 	return nc.call("SeesawNCC.IPVSDeleteDestination", ipvsDst, nil)
 }
 
+func (nc *nccClient) IPVSEnsureDestination(svc *ipvs.Service, dst *ipvs.Destination) error {
+	ipvsDst := ncctypes.IPVSDestination{Service: svc, Destination: dst}
+	return nc.call("SeesawNCC.IPVSEnsureDestination", ipvsDst, nil)
+}
+
+func (nc *nccClient) IPVSCapabilities() (*ipvs.Capabilities, error) {
+	c := &ipvs.Capabilities{}
+	if err := nc.call("SeesawNCC.IPVSCapabilities", 0, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (nc *nccClient) IPVSGetTimeouts() (*ipvs.Timeouts, error) {
+	t := &ipvs.Timeouts{}
+	if err := nc.call("SeesawNCC.IPVSGetTimeouts", 0, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (nc *nccClient) IPVSSetTimeouts(t *ipvs.Timeouts) error {
+	return nc.call("SeesawNCC.IPVSSetTimeouts", t, nil)
+}
+
+func (nc *nccClient) IPVSStartSyncDaemon(d *ipvs.SyncDaemon) error {
+	return nc.call("SeesawNCC.IPVSStartSyncDaemon", d, nil)
+}
+
+func (nc *nccClient) IPVSStopSyncDaemon() error {
+	return nc.call("SeesawNCC.IPVSStopSyncDaemon", 0, nil)
+}
+
+func (nc *nccClient) IPVSConnections(filter ipvs.ConnectionFilter, max int) (*ncctypes.IPVSConnections, error) {
+	q := &ncctypes.IPVSConnectionQuery{Filter: filter, Max: max}
+	c := &ncctypes.IPVSConnections{}
+	if err := nc.call("SeesawNCC.IPVSConnections", q, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (nc *nccClient) IPVSApply(txn *ipvs.Transaction) error {
+	return nc.call("SeesawNCC.IPVSApply", txn, nil)
+}
+
 func (nc *nccClient) RouteDefaultIPv4() (net.IP, error) {
 	var ip net.IP
 	err := nc.call("SeesawNCC.RouteDefaultIPv4", 0, &ip)
@@ -104,6 +104,22 @@ func (ncc *SeesawNCC) IPVSDeleteService(svc *ipvs.Service, out *int) error {
 	return ipvs.DeleteService(*svc)
 }
 
+// IPVSFlushService deletes all destinations for the specified service, then
+// deletes the service from the IPVS table.
+func (ncc *SeesawNCC) IPVSFlushService(svc *ipvs.Service, out *int) error {
+	ipvsMutex.Lock()
+	defer ipvsMutex.Unlock()
+	return ipvs.FlushService(*svc)
+}
+
+// IPVSEnsureService adds the specified service and its destinations to the
+// IPVS table, updating them if they already exist.
+func (ncc *SeesawNCC) IPVSEnsureService(svc *ipvs.Service, out *int) error {
+	ipvsMutex.Lock()
+	defer ipvsMutex.Unlock()
+	return ipvs.EnsureService(*svc)
+}
+
 // IPVSAddDestination adds the specified destination to the IPVS table.
 func (ncc *SeesawNCC) IPVSAddDestination(dst *ncctypes.IPVSDestination, out *int) error {
 	ipvsMutex.Lock()
@@ -118,9 +134,95 @@ func (ncc *SeesawNCC) IPVSUpdateDestination(dst *ncctypes.IPVSDestination, out *
 	return ipvs.UpdateDestination(*dst.Service, *dst.Destination)
 }
 
+// IPVSDrainDestination sets the weight of the specified destination in the
+// IPVS table to zero.
+func (ncc *SeesawNCC) IPVSDrainDestination(dst *ncctypes.IPVSDestination, out *int) error {
+	ipvsMutex.Lock()
+	defer ipvsMutex.Unlock()
+	return ipvs.DrainDestination(*dst.Service, *dst.Destination)
+}
+
 // IPVSDeleteDestination deletes the specified destination from the IPVS table.
 func (ncc *SeesawNCC) IPVSDeleteDestination(dst *ncctypes.IPVSDestination, out *int) error {
 	ipvsMutex.Lock()
 	defer ipvsMutex.Unlock()
 	return ipvs.DeleteDestination(*dst.Service, *dst.Destination)
 }
+
+// IPVSEnsureDestination adds the specified destination to the IPVS table,
+// updating it if it already exists.
+func (ncc *SeesawNCC) IPVSEnsureDestination(dst *ncctypes.IPVSDestination, out *int) error {
+	ipvsMutex.Lock()
+	defer ipvsMutex.Unlock()
+	return ipvs.EnsureDestination(*dst.Service, *dst.Destination)
+}
+
+// IPVSCapabilities returns the IPVS features supported by the running kernel.
+func (ncc *SeesawNCC) IPVSCapabilities(in int, c *ipvs.Capabilities) error {
+	ipvsMutex.Lock()
+	defer ipvsMutex.Unlock()
+	caps, err := ipvs.GetCapabilities()
+	if err != nil {
+		return err
+	}
+	*c = *caps
+	return nil
+}
+
+// IPVSGetTimeouts gets the current IPVS connection timeouts.
+func (ncc *SeesawNCC) IPVSGetTimeouts(in int, t *ipvs.Timeouts) error {
+	ipvsMutex.Lock()
+	defer ipvsMutex.Unlock()
+	timeouts, err := ipvs.GetTimeouts()
+	if err != nil {
+		return err
+	}
+	*t = *timeouts
+	return nil
+}
+
+// IPVSSetTimeouts sets the IPVS connection timeouts.
+func (ncc *SeesawNCC) IPVSSetTimeouts(t *ipvs.Timeouts, out *int) error {
+	ipvsMutex.Lock()
+	defer ipvsMutex.Unlock()
+	return ipvs.SetTimeouts(*t)
+}
+
+// IPVSStartSyncDaemon starts the specified IPVS connection synchronisation
+// daemon.
+func (ncc *SeesawNCC) IPVSStartSyncDaemon(d *ipvs.SyncDaemon, out *int) error {
+	ipvsMutex.Lock()
+	defer ipvsMutex.Unlock()
+	return ipvs.StartSyncDaemon(*d)
+}
+
+// IPVSStopSyncDaemon stops any running IPVS connection synchronisation
+// daemons.
+func (ncc *SeesawNCC) IPVSStopSyncDaemon(in int, out *int) error {
+	ipvsMutex.Lock()
+	defer ipvsMutex.Unlock()
+	return ipvs.StopSyncDaemon()
+}
+
+// IPVSConnections lists connections from the IPVS connection table that match
+// the given filter, up to the requested maximum.
+func (ncc *SeesawNCC) IPVSConnections(q *ncctypes.IPVSConnectionQuery, c *ncctypes.IPVSConnections) error {
+	c.Connections = nil
+	c.Truncated = false
+	return ipvs.ListConnections(q.Filter, func(conn *ipvs.Connection) bool {
+		if len(c.Connections) >= q.Max {
+			c.Truncated = true
+			return false
+		}
+		c.Connections = append(c.Connections, conn)
+		return true
+	})
+}
+
+// IPVSApply applies the operations recorded by a transaction to the IPVS
+// table, rolling back the applied operations if any of them fail.
+func (ncc *SeesawNCC) IPVSApply(txn *ipvs.Transaction, out *int) error {
+	ipvsMutex.Lock()
+	defer ipvsMutex.Unlock()
+	return txn.Commit()
+}
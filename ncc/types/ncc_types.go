@@ -41,6 +41,20 @@ type IPVSServices struct {
 	Services []*ipvs.Service
 }
 
+// IPVSConnectionQuery specifies the IPVS connections to list, up to a maximum
+// number of connections.
+type IPVSConnectionQuery struct {
+	Filter ipvs.ConnectionFilter
+	Max    int
+}
+
+// IPVSConnections contains an array of IPVS connections, which is truncated
+// if more connections matched than were requested.
+type IPVSConnections struct {
+	Connections []*ipvs.Connection
+	Truncated   bool
+}
+
 // IPVSDestination specifies an IPVS destination and its associated service.
 type IPVSDestination struct {
 	Service     *ipvs.Service
@@ -542,6 +542,32 @@ func (e *Error) Error() string {
 	return fmt.Sprintf("%s: %s", e.msg, strings.ToLower(nle))
 }
 
+// IsNotFound returns true if the given error is a netlink error indicating
+// that the requested object does not exist.
+func IsNotFound(err error) bool {
+	return hasErrno(err, C.NLE_OBJ_NOTFOUND)
+}
+
+// IsExists returns true if the given error is a netlink error indicating
+// that the object being created already exists.
+func IsExists(err error) bool {
+	return hasErrno(err, C.NLE_EXIST)
+}
+
+// hasErrno returns true if the given error is, or wraps, a netlink error with
+// the specified libnl error code.
+func hasErrno(err error, nle C.int) bool {
+	var e *Error
+	if !errors.As(err, &e) {
+		return false
+	}
+	errno := e.errno
+	if errno < 0 {
+		errno = -errno
+	}
+	return errno == nle
+}
+
 // Family returns the family identifier for the specified family name.
 func Family(name string) (int, error) {
 	s, err := newSocket()
@@ -174,37 +174,94 @@ func (Host_Status) EnumDescriptor() ([]byte, []int) {
 type Healthcheck_Type int32
 
 const (
-	Healthcheck_ICMP_PING Healthcheck_Type = 1
-	Healthcheck_UDP       Healthcheck_Type = 2
-	Healthcheck_TCP       Healthcheck_Type = 3
-	Healthcheck_HTTP      Healthcheck_Type = 4
-	Healthcheck_HTTPS     Healthcheck_Type = 5
-	Healthcheck_DNS       Healthcheck_Type = 6
-	Healthcheck_TCP_TLS   Healthcheck_Type = 7
-	Healthcheck_RADIUS    Healthcheck_Type = 8
+	Healthcheck_ICMP_PING  Healthcheck_Type = 1
+	Healthcheck_UDP        Healthcheck_Type = 2
+	Healthcheck_TCP        Healthcheck_Type = 3
+	Healthcheck_HTTP       Healthcheck_Type = 4
+	Healthcheck_HTTPS      Healthcheck_Type = 5
+	Healthcheck_DNS        Healthcheck_Type = 6
+	Healthcheck_TCP_TLS    Healthcheck_Type = 7
+	Healthcheck_RADIUS     Healthcheck_Type = 8
+	Healthcheck_GRPC       Healthcheck_Type = 9
+	Healthcheck_GRPC_TLS   Healthcheck_Type = 10
+	Healthcheck_MYSQL      Healthcheck_Type = 11
+	Healthcheck_POSTGRESQL Healthcheck_Type = 12
+	Healthcheck_REDIS      Healthcheck_Type = 13
+	Healthcheck_MEMCACHE   Healthcheck_Type = 14
+	Healthcheck_SMTP       Healthcheck_Type = 15
+	Healthcheck_LDAP       Healthcheck_Type = 16
+	Healthcheck_LDAPS      Healthcheck_Type = 17
+	Healthcheck_SIP        Healthcheck_Type = 18
+	Healthcheck_SIP_TCP    Healthcheck_Type = 19
+	Healthcheck_NTP        Healthcheck_Type = 20
+	Healthcheck_FTP        Healthcheck_Type = 21
+	Healthcheck_FTPS       Healthcheck_Type = 22
+	Healthcheck_IMAP       Healthcheck_Type = 23
+	Healthcheck_IMAPS      Healthcheck_Type = 24
+	Healthcheck_POP3       Healthcheck_Type = 25
+	Healthcheck_POP3S      Healthcheck_Type = 26
+	Healthcheck_SCRIPT     Healthcheck_Type = 27
 )
 
 // Enum value maps for Healthcheck_Type.
 var (
 	Healthcheck_Type_name = map[int32]string{
-		1: "ICMP_PING",
-		2: "UDP",
-		3: "TCP",
-		4: "HTTP",
-		5: "HTTPS",
-		6: "DNS",
-		7: "TCP_TLS",
-		8: "RADIUS",
+		1:  "ICMP_PING",
+		2:  "UDP",
+		3:  "TCP",
+		4:  "HTTP",
+		5:  "HTTPS",
+		6:  "DNS",
+		7:  "TCP_TLS",
+		8:  "RADIUS",
+		9:  "GRPC",
+		10: "GRPC_TLS",
+		11: "MYSQL",
+		12: "POSTGRESQL",
+		13: "REDIS",
+		14: "MEMCACHE",
+		15: "SMTP",
+		16: "LDAP",
+		17: "LDAPS",
+		18: "SIP",
+		19: "SIP_TCP",
+		20: "NTP",
+		21: "FTP",
+		22: "FTPS",
+		23: "IMAP",
+		24: "IMAPS",
+		25: "POP3",
+		26: "POP3S",
+		27: "SCRIPT",
 	}
 	Healthcheck_Type_value = map[string]int32{
-		"ICMP_PING": 1,
-		"UDP":       2,
-		"TCP":       3,
-		"HTTP":      4,
-		"HTTPS":     5,
-		"DNS":       6,
-		"TCP_TLS":   7,
-		"RADIUS":    8,
+		"ICMP_PING":  1,
+		"UDP":        2,
+		"TCP":        3,
+		"HTTP":       4,
+		"HTTPS":      5,
+		"DNS":        6,
+		"TCP_TLS":    7,
+		"RADIUS":     8,
+		"GRPC":       9,
+		"GRPC_TLS":   10,
+		"MYSQL":      11,
+		"POSTGRESQL": 12,
+		"REDIS":      13,
+		"MEMCACHE":   14,
+		"SMTP":       15,
+		"LDAP":       16,
+		"LDAPS":      17,
+		"SIP":        18,
+		"SIP_TCP":    19,
+		"NTP":        20,
+		"FTP":        21,
+		"FTPS":       22,
+		"IMAP":       23,
+		"IMAPS":      24,
+		"POP3":       25,
+		"POP3S":      26,
+		"SCRIPT":     27,
 	}
 )
 
@@ -748,9 +805,8 @@ func (x *Vlan) GetHost() *Host {
 }
 
 // HTTPS and TCP_TLS types are retained for backward compatibility but map to
-// HTTP and TCP with TLS enabled on the Go side. RADIUS-specific fields
-// (radius_username, radius_password, radius_secret, radius_response) are
-// defined in config.proto but require protoc regeneration to be usable here.
+// HTTP and TCP with TLS enabled on the Go side. Per-type fields are added as
+// optional extensions (e.g., RADIUS fields below).
 type Healthcheck struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -769,7 +825,8 @@ type Healthcheck struct {
 	Send *string `protobuf:"bytes,5,opt,name=send" json:"send,omitempty"`
 	// Expected response for UDP/TCP/HTTP(S) healthcheck.
 	Receive *string `protobuf:"bytes,6,opt,name=receive" json:"receive,omitempty"`
-	// Expected response code for healthcheck.
+	// Expected response code for healthcheck. For a SIP health check, any 2xx
+	// response is accepted if unset.
 	Code *int32 `protobuf:"varint,7,opt,name=code" json:"code,omitempty"`
 	// The Mode of this healthcheck.
 	Mode *Healthcheck_Mode `protobuf:"varint,8,opt,name=mode,enum=Healthcheck_Mode,def=1" json:"mode,omitempty"`
@@ -781,6 +838,126 @@ type Healthcheck struct {
 	TlsVerify *bool `protobuf:"varint,11,opt,name=tls_verify,json=tlsVerify,def=1" json:"tls_verify,omitempty"`
 	// Number of retries before a healthcheck is considered to have failed.
 	Retries *int32 `protobuf:"varint,12,opt,name=retries" json:"retries,omitempty"`
+	// RADIUS-specific fields (used when type is RADIUS).
+	RadiusUsername *string `protobuf:"bytes,13,opt,name=radius_username,json=radiusUsername" json:"radius_username,omitempty"`
+	RadiusPassword *string `protobuf:"bytes,14,opt,name=radius_password,json=radiusPassword" json:"radius_password,omitempty"`
+	RadiusSecret   *string `protobuf:"bytes,15,opt,name=radius_secret,json=radiusSecret" json:"radius_secret,omitempty"`
+	RadiusResponse *string `protobuf:"bytes,16,opt,name=radius_response,json=radiusResponse" json:"radius_response,omitempty"`
+	// Additional request headers for an HTTP(S) healthcheck, in the form
+	// "Name: value".
+	Header []string `protobuf:"bytes,17,rep,name=header" json:"header,omitempty"`
+	// Regular expression that the response body of an HTTP(S) healthcheck, or
+	// the response to send for a TCP or TCP_TLS healthcheck, must match.
+	ReceiveRegexp *string `protobuf:"bytes,18,opt,name=receive_regexp,json=receiveRegexp" json:"receive_regexp,omitempty"`
+	// Expected response codes for an HTTP(S) healthcheck, such as
+	// "200,204,301-302" or "2xx". Takes precedence over code.
+	Codes *string `protobuf:"bytes,19,opt,name=codes" json:"codes,omitempty"`
+	// Number of same-host redirects to follow for an HTTP(S) healthcheck.
+	MaxRedirects *int32 `protobuf:"varint,20,opt,name=max_redirects,json=maxRedirects" json:"max_redirects,omitempty"`
+	// Client certificate and key files to present for an HTTPS healthcheck.
+	TlsCertFile *string `protobuf:"bytes,21,opt,name=tls_cert_file,json=tlsCertFile" json:"tls_cert_file,omitempty"`
+	TlsKeyFile  *string `protobuf:"bytes,22,opt,name=tls_key_file,json=tlsKeyFile" json:"tls_key_file,omitempty"`
+	// CA certificates file used to verify the server for an HTTPS healthcheck.
+	TlsCaFile *string `protobuf:"bytes,23,opt,name=tls_ca_file,json=tlsCaFile" json:"tls_ca_file,omitempty"`
+	// Response headers that an HTTP(S) healthcheck requires, in the form
+	// "Name: value".
+	ExpectHeader []string `protobuf:"bytes,24,rep,name=expect_header,json=expectHeader" json:"expect_header,omitempty"`
+	// Names of response headers that fail an HTTP(S) healthcheck if present.
+	ForbidHeader []string `protobuf:"bytes,25,rep,name=forbid_header,json=forbidHeader" json:"forbid_header,omitempty"`
+	// Treat expect_header values as regular expressions.
+	ExpectHeaderRegexp *bool `protobuf:"varint,26,opt,name=expect_header_regexp,json=expectHeaderRegexp" json:"expect_header_regexp,omitempty"`
+	// URL of an HTTP proxy through which an HTTP(S) healthcheck is tunnelled.
+	ProxyUrl *string `protobuf:"bytes,27,opt,name=proxy_url,json=proxyUrl" json:"proxy_url,omitempty"`
+	// Successful healthchecks slower than this many milliseconds fail.
+	MaxLatencyMs *int32 `protobuf:"varint,28,opt,name=max_latency_ms,json=maxLatencyMs" json:"max_latency_ms,omitempty"`
+	// Request body and content type for a POST or PUT HTTP(S) healthcheck.
+	RequestBody []byte  `protobuf:"bytes,29,opt,name=request_body,json=requestBody" json:"request_body,omitempty"`
+	ContentType *string `protobuf:"bytes,30,opt,name=content_type,json=contentType" json:"content_type,omitempty"`
+	// Server name used for SNI and certificate verification.
+	TlsServerName *string `protobuf:"bytes,31,opt,name=tls_server_name,json=tlsServerName" json:"tls_server_name,omitempty"`
+	// Resume TLS sessions across HTTPS healthchecks.
+	TlsResumeSessions *bool `protobuf:"varint,32,opt,name=tls_resume_sessions,json=tlsResumeSessions" json:"tls_resume_sessions,omitempty"`
+	// Hold a keep-alive connection open between HTTP(S) healthchecks.
+	ReuseConnections *bool `protobuf:"varint,33,opt,name=reuse_connections,json=reuseConnections" json:"reuse_connections,omitempty"`
+	// Require DNS answers to be authoritative.
+	DnsAuthoritative *bool `protobuf:"varint,34,opt,name=dns_authoritative,json=dnsAuthoritative" json:"dns_authoritative,omitempty"`
+	// The expected DNS response code, e.g. "NXDOMAIN".
+	DnsRcode *string `protobuf:"bytes,35,opt,name=dns_rcode,json=dnsRcode" json:"dns_rcode,omitempty"`
+	// The EDNS0 UDP buffer size advertised by DNS healthchecks.
+	DnsUdpSize *int32 `protobuf:"varint,36,opt,name=dns_udp_size,json=dnsUdpSize" json:"dns_udp_size,omitempty"`
+	// Require DNSSEC validation of DNS answers, either "AD" or "RRSIG".
+	Dnssec *string `protobuf:"bytes,37,opt,name=dnssec" json:"dnssec,omitempty"`
+	// DNS query latency, in milliseconds, above which a warning is reported.
+	DnsWarnLatencyMs *int32 `protobuf:"varint,38,opt,name=dns_warn_latency_ms,json=dnsWarnLatencyMs" json:"dns_warn_latency_ms,omitempty"`
+	// DNS query latency, in milliseconds, above which the healthcheck fails.
+	DnsMaxLatencyMs *int32 `protobuf:"varint,39,opt,name=dns_max_latency_ms,json=dnsMaxLatencyMs" json:"dns_max_latency_ms,omitempty"`
+	// Number of times a DNS query is sent before the healthcheck fails.
+	DnsQueryAttempts *int32 `protobuf:"varint,40,opt,name=dns_query_attempts,json=dnsQueryAttempts" json:"dns_query_attempts,omitempty"`
+	// Reject DNS responses whose ID does not match the query.
+	DnsStrictId *bool `protobuf:"varint,41,opt,name=dns_strict_id,json=dnsStrictId" json:"dns_strict_id,omitempty"`
+	// Randomise the case of DNS query names (DNS 0x20 encoding).
+	DnsCaseRandomization *bool `protobuf:"varint,42,opt,name=dns_case_randomization,json=dnsCaseRandomization" json:"dns_case_randomization,omitempty"`
+	// Send a RADIUS Status-Server request instead of an Access-Request.
+	RadiusStatusServer *bool `protobuf:"varint,43,opt,name=radius_status_server,json=radiusStatusServer" json:"radius_status_server,omitempty"`
+	// The service name to query with a gRPC health check.
+	GrpcService *string `protobuf:"bytes,44,opt,name=grpc_service,json=grpcService" json:"grpc_service,omitempty"`
+	// MySQL healthcheck credentials and query.
+	MysqlUser           *string `protobuf:"bytes,45,opt,name=mysql_user,json=mysqlUser" json:"mysql_user,omitempty"`
+	MysqlPassword       *string `protobuf:"bytes,46,opt,name=mysql_password,json=mysqlPassword" json:"mysql_password,omitempty"`
+	MysqlQuery          *string `protobuf:"bytes,47,opt,name=mysql_query,json=mysqlQuery" json:"mysql_query,omitempty"`
+	MysqlExpectWritable *bool   `protobuf:"varint,48,opt,name=mysql_expect_writable,json=mysqlExpectWritable" json:"mysql_expect_writable,omitempty"`
+	// PostgreSQL healthcheck credentials, TLS mode and primary check.
+	PostgresUser          *string `protobuf:"bytes,49,opt,name=postgres_user,json=postgresUser" json:"postgres_user,omitempty"`
+	PostgresPassword      *string `protobuf:"bytes,50,opt,name=postgres_password,json=postgresPassword" json:"postgres_password,omitempty"`
+	PostgresDatabase      *string `protobuf:"bytes,51,opt,name=postgres_database,json=postgresDatabase" json:"postgres_database,omitempty"`
+	PostgresSslmode       *string `protobuf:"bytes,52,opt,name=postgres_sslmode,json=postgresSslmode" json:"postgres_sslmode,omitempty"`
+	PostgresExpectPrimary *bool   `protobuf:"varint,53,opt,name=postgres_expect_primary,json=postgresExpectPrimary" json:"postgres_expect_primary,omitempty"`
+	// Redis healthcheck credentials and required role.
+	RedisUsername *string `protobuf:"bytes,54,opt,name=redis_username,json=redisUsername" json:"redis_username,omitempty"`
+	RedisPassword *string `protobuf:"bytes,55,opt,name=redis_password,json=redisPassword" json:"redis_password,omitempty"`
+	RedisRole     *string `protobuf:"bytes,56,opt,name=redis_role,json=redisRole" json:"redis_role,omitempty"`
+	// Memcache healthcheck protocol, stats assertion and round trip key prefix.
+	MemcacheBinary     *bool   `protobuf:"varint,57,opt,name=memcache_binary,json=memcacheBinary" json:"memcache_binary,omitempty"`
+	MemcacheStatsKey   *string `protobuf:"bytes,58,opt,name=memcache_stats_key,json=memcacheStatsKey" json:"memcache_stats_key,omitempty"`
+	MemcacheStatsValue *string `protobuf:"bytes,59,opt,name=memcache_stats_value,json=memcacheStatsValue" json:"memcache_stats_value,omitempty"`
+	MemcacheKeyPrefix  *string `protobuf:"bytes,60,opt,name=memcache_key_prefix,json=memcacheKeyPrefix" json:"memcache_key_prefix,omitempty"`
+	// SMTP healthcheck EHLO hostname, STARTTLS and required extension.
+	SmtpHostname   *string `protobuf:"bytes,61,opt,name=smtp_hostname,json=smtpHostname" json:"smtp_hostname,omitempty"`
+	SmtpStarttls   *bool   `protobuf:"varint,62,opt,name=smtp_starttls,json=smtpStarttls" json:"smtp_starttls,omitempty"`
+	SmtpCapability *string `protobuf:"bytes,63,opt,name=smtp_capability,json=smtpCapability" json:"smtp_capability,omitempty"`
+	// LDAP healthcheck bind credentials, search DN and StartTLS.
+	LdapBindDn   *string `protobuf:"bytes,64,opt,name=ldap_bind_dn,json=ldapBindDn" json:"ldap_bind_dn,omitempty"`
+	LdapPassword *string `protobuf:"bytes,65,opt,name=ldap_password,json=ldapPassword" json:"ldap_password,omitempty"`
+	LdapSearchDn *string `protobuf:"bytes,66,opt,name=ldap_search_dn,json=ldapSearchDn" json:"ldap_search_dn,omitempty"`
+	LdapStarttls *bool   `protobuf:"varint,67,opt,name=ldap_starttls,json=ldapStarttls" json:"ldap_starttls,omitempty"`
+	// NTP healthcheck stratum, root dispersion and clock offset limits.
+	NtpMaxStratum          *int32 `protobuf:"varint,68,opt,name=ntp_max_stratum,json=ntpMaxStratum" json:"ntp_max_stratum,omitempty"`
+	NtpMaxRootDispersionMs *int32 `protobuf:"varint,69,opt,name=ntp_max_root_dispersion_ms,json=ntpMaxRootDispersionMs" json:"ntp_max_root_dispersion_ms,omitempty"`
+	NtpMaxOffsetMs         *int32 `protobuf:"varint,70,opt,name=ntp_max_offset_ms,json=ntpMaxOffsetMs" json:"ntp_max_offset_ms,omitempty"`
+	// FTP healthcheck login credentials, command and path.
+	FtpUser     *string `protobuf:"bytes,71,opt,name=ftp_user,json=ftpUser" json:"ftp_user,omitempty"`
+	FtpPassword *string `protobuf:"bytes,72,opt,name=ftp_password,json=ftpPassword" json:"ftp_password,omitempty"`
+	FtpCommand  *string `protobuf:"bytes,73,opt,name=ftp_command,json=ftpCommand" json:"ftp_command,omitempty"`
+	FtpPath     *string `protobuf:"bytes,74,opt,name=ftp_path,json=ftpPath" json:"ftp_path,omitempty"`
+	// IMAP and POP3 healthcheck credentials, mailbox and STARTTLS.
+	ImapUser     *string `protobuf:"bytes,75,opt,name=imap_user,json=imapUser" json:"imap_user,omitempty"`
+	ImapPassword *string `protobuf:"bytes,76,opt,name=imap_password,json=imapPassword" json:"imap_password,omitempty"`
+	ImapMailbox  *string `protobuf:"bytes,77,opt,name=imap_mailbox,json=imapMailbox" json:"imap_mailbox,omitempty"`
+	ImapStarttls *bool   `protobuf:"varint,78,opt,name=imap_starttls,json=imapStarttls" json:"imap_starttls,omitempty"`
+	Pop3User     *string `protobuf:"bytes,79,opt,name=pop3_user,json=pop3User" json:"pop3_user,omitempty"`
+	Pop3Password *string `protobuf:"bytes,80,opt,name=pop3_password,json=pop3Password" json:"pop3_password,omitempty"`
+	Pop3Starttls *bool   `protobuf:"varint,81,opt,name=pop3_starttls,json=pop3Starttls" json:"pop3_starttls,omitempty"`
+	// Script healthcheck command and arguments.
+	ScriptCommand *string  `protobuf:"bytes,82,opt,name=script_command,json=scriptCommand" json:"script_command,omitempty"`
+	ScriptArg     []string `protobuf:"bytes,83,rep,name=script_arg,json=scriptArg" json:"script_arg,omitempty"`
+	// TCP half-open (SYN) healthcheck.
+	TcpHalfOpen *bool `protobuf:"varint,84,opt,name=tcp_half_open,json=tcpHalfOpen" json:"tcp_half_open,omitempty"`
+	// Consecutive results required for state transitions.
+	Rise *int32 `protobuf:"varint,85,opt,name=rise" json:"rise,omitempty"`
+	Fall *int32 `protobuf:"varint,86,opt,name=fall" json:"fall,omitempty"`
+	// Passive healthchecks.
+	Passive     *bool `protobuf:"varint,87,opt,name=passive" json:"passive,omitempty"`
+	PassiveHold *bool `protobuf:"varint,88,opt,name=passive_hold,json=passiveHold" json:"passive_hold,omitempty"`
 }
 
 // Default values for Healthcheck fields.
@@ -907,6 +1084,538 @@ func (x *Healthcheck) GetRetries() int32 {
 	return 0
 }
 
+func (x *Healthcheck) GetRadiusUsername() string {
+	if x != nil && x.RadiusUsername != nil {
+		return *x.RadiusUsername
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetRadiusPassword() string {
+	if x != nil && x.RadiusPassword != nil {
+		return *x.RadiusPassword
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetRadiusSecret() string {
+	if x != nil && x.RadiusSecret != nil {
+		return *x.RadiusSecret
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetRadiusResponse() string {
+	if x != nil && x.RadiusResponse != nil {
+		return *x.RadiusResponse
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetHeader() []string {
+	if x != nil {
+		return x.Header
+	}
+	return nil
+}
+
+func (x *Healthcheck) GetReceiveRegexp() string {
+	if x != nil && x.ReceiveRegexp != nil {
+		return *x.ReceiveRegexp
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetCodes() string {
+	if x != nil && x.Codes != nil {
+		return *x.Codes
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetMaxRedirects() int32 {
+	if x != nil && x.MaxRedirects != nil {
+		return *x.MaxRedirects
+	}
+	return 0
+}
+
+func (x *Healthcheck) GetTlsCertFile() string {
+	if x != nil && x.TlsCertFile != nil {
+		return *x.TlsCertFile
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetTlsKeyFile() string {
+	if x != nil && x.TlsKeyFile != nil {
+		return *x.TlsKeyFile
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetTlsCaFile() string {
+	if x != nil && x.TlsCaFile != nil {
+		return *x.TlsCaFile
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetExpectHeader() []string {
+	if x != nil {
+		return x.ExpectHeader
+	}
+	return nil
+}
+
+func (x *Healthcheck) GetForbidHeader() []string {
+	if x != nil {
+		return x.ForbidHeader
+	}
+	return nil
+}
+
+func (x *Healthcheck) GetExpectHeaderRegexp() bool {
+	if x != nil && x.ExpectHeaderRegexp != nil {
+		return *x.ExpectHeaderRegexp
+	}
+	return false
+}
+
+func (x *Healthcheck) GetProxyUrl() string {
+	if x != nil && x.ProxyUrl != nil {
+		return *x.ProxyUrl
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetMaxLatencyMs() int32 {
+	if x != nil && x.MaxLatencyMs != nil {
+		return *x.MaxLatencyMs
+	}
+	return 0
+}
+
+func (x *Healthcheck) GetRequestBody() []byte {
+	if x != nil {
+		return x.RequestBody
+	}
+	return nil
+}
+
+func (x *Healthcheck) GetContentType() string {
+	if x != nil && x.ContentType != nil {
+		return *x.ContentType
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetTlsServerName() string {
+	if x != nil && x.TlsServerName != nil {
+		return *x.TlsServerName
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetTlsResumeSessions() bool {
+	if x != nil && x.TlsResumeSessions != nil {
+		return *x.TlsResumeSessions
+	}
+	return false
+}
+
+func (x *Healthcheck) GetReuseConnections() bool {
+	if x != nil && x.ReuseConnections != nil {
+		return *x.ReuseConnections
+	}
+	return false
+}
+
+func (x *Healthcheck) GetDnsAuthoritative() bool {
+	if x != nil && x.DnsAuthoritative != nil {
+		return *x.DnsAuthoritative
+	}
+	return false
+}
+
+func (x *Healthcheck) GetDnsRcode() string {
+	if x != nil && x.DnsRcode != nil {
+		return *x.DnsRcode
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetDnsUdpSize() int32 {
+	if x != nil && x.DnsUdpSize != nil {
+		return *x.DnsUdpSize
+	}
+	return 0
+}
+
+func (x *Healthcheck) GetDnssec() string {
+	if x != nil && x.Dnssec != nil {
+		return *x.Dnssec
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetDnsWarnLatencyMs() int32 {
+	if x != nil && x.DnsWarnLatencyMs != nil {
+		return *x.DnsWarnLatencyMs
+	}
+	return 0
+}
+
+func (x *Healthcheck) GetDnsMaxLatencyMs() int32 {
+	if x != nil && x.DnsMaxLatencyMs != nil {
+		return *x.DnsMaxLatencyMs
+	}
+	return 0
+}
+
+func (x *Healthcheck) GetDnsQueryAttempts() int32 {
+	if x != nil && x.DnsQueryAttempts != nil {
+		return *x.DnsQueryAttempts
+	}
+	return 0
+}
+
+func (x *Healthcheck) GetDnsStrictId() bool {
+	if x != nil && x.DnsStrictId != nil {
+		return *x.DnsStrictId
+	}
+	return false
+}
+
+func (x *Healthcheck) GetDnsCaseRandomization() bool {
+	if x != nil && x.DnsCaseRandomization != nil {
+		return *x.DnsCaseRandomization
+	}
+	return false
+}
+
+func (x *Healthcheck) GetRadiusStatusServer() bool {
+	if x != nil && x.RadiusStatusServer != nil {
+		return *x.RadiusStatusServer
+	}
+	return false
+}
+
+func (x *Healthcheck) GetGrpcService() string {
+	if x != nil && x.GrpcService != nil {
+		return *x.GrpcService
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetMysqlUser() string {
+	if x != nil && x.MysqlUser != nil {
+		return *x.MysqlUser
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetMysqlPassword() string {
+	if x != nil && x.MysqlPassword != nil {
+		return *x.MysqlPassword
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetMysqlQuery() string {
+	if x != nil && x.MysqlQuery != nil {
+		return *x.MysqlQuery
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetMysqlExpectWritable() bool {
+	if x != nil && x.MysqlExpectWritable != nil {
+		return *x.MysqlExpectWritable
+	}
+	return false
+}
+
+func (x *Healthcheck) GetPostgresUser() string {
+	if x != nil && x.PostgresUser != nil {
+		return *x.PostgresUser
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetPostgresPassword() string {
+	if x != nil && x.PostgresPassword != nil {
+		return *x.PostgresPassword
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetPostgresDatabase() string {
+	if x != nil && x.PostgresDatabase != nil {
+		return *x.PostgresDatabase
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetPostgresSslmode() string {
+	if x != nil && x.PostgresSslmode != nil {
+		return *x.PostgresSslmode
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetPostgresExpectPrimary() bool {
+	if x != nil && x.PostgresExpectPrimary != nil {
+		return *x.PostgresExpectPrimary
+	}
+	return false
+}
+
+func (x *Healthcheck) GetRedisUsername() string {
+	if x != nil && x.RedisUsername != nil {
+		return *x.RedisUsername
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetRedisPassword() string {
+	if x != nil && x.RedisPassword != nil {
+		return *x.RedisPassword
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetRedisRole() string {
+	if x != nil && x.RedisRole != nil {
+		return *x.RedisRole
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetMemcacheBinary() bool {
+	if x != nil && x.MemcacheBinary != nil {
+		return *x.MemcacheBinary
+	}
+	return false
+}
+
+func (x *Healthcheck) GetMemcacheStatsKey() string {
+	if x != nil && x.MemcacheStatsKey != nil {
+		return *x.MemcacheStatsKey
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetMemcacheStatsValue() string {
+	if x != nil && x.MemcacheStatsValue != nil {
+		return *x.MemcacheStatsValue
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetMemcacheKeyPrefix() string {
+	if x != nil && x.MemcacheKeyPrefix != nil {
+		return *x.MemcacheKeyPrefix
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetSmtpHostname() string {
+	if x != nil && x.SmtpHostname != nil {
+		return *x.SmtpHostname
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetSmtpStarttls() bool {
+	if x != nil && x.SmtpStarttls != nil {
+		return *x.SmtpStarttls
+	}
+	return false
+}
+
+func (x *Healthcheck) GetSmtpCapability() string {
+	if x != nil && x.SmtpCapability != nil {
+		return *x.SmtpCapability
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetLdapBindDn() string {
+	if x != nil && x.LdapBindDn != nil {
+		return *x.LdapBindDn
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetLdapPassword() string {
+	if x != nil && x.LdapPassword != nil {
+		return *x.LdapPassword
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetLdapSearchDn() string {
+	if x != nil && x.LdapSearchDn != nil {
+		return *x.LdapSearchDn
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetLdapStarttls() bool {
+	if x != nil && x.LdapStarttls != nil {
+		return *x.LdapStarttls
+	}
+	return false
+}
+
+func (x *Healthcheck) GetNtpMaxStratum() int32 {
+	if x != nil && x.NtpMaxStratum != nil {
+		return *x.NtpMaxStratum
+	}
+	return 0
+}
+
+func (x *Healthcheck) GetNtpMaxRootDispersionMs() int32 {
+	if x != nil && x.NtpMaxRootDispersionMs != nil {
+		return *x.NtpMaxRootDispersionMs
+	}
+	return 0
+}
+
+func (x *Healthcheck) GetNtpMaxOffsetMs() int32 {
+	if x != nil && x.NtpMaxOffsetMs != nil {
+		return *x.NtpMaxOffsetMs
+	}
+	return 0
+}
+
+func (x *Healthcheck) GetFtpUser() string {
+	if x != nil && x.FtpUser != nil {
+		return *x.FtpUser
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetFtpPassword() string {
+	if x != nil && x.FtpPassword != nil {
+		return *x.FtpPassword
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetFtpCommand() string {
+	if x != nil && x.FtpCommand != nil {
+		return *x.FtpCommand
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetFtpPath() string {
+	if x != nil && x.FtpPath != nil {
+		return *x.FtpPath
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetImapUser() string {
+	if x != nil && x.ImapUser != nil {
+		return *x.ImapUser
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetImapPassword() string {
+	if x != nil && x.ImapPassword != nil {
+		return *x.ImapPassword
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetImapMailbox() string {
+	if x != nil && x.ImapMailbox != nil {
+		return *x.ImapMailbox
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetImapStarttls() bool {
+	if x != nil && x.ImapStarttls != nil {
+		return *x.ImapStarttls
+	}
+	return false
+}
+
+func (x *Healthcheck) GetPop3User() string {
+	if x != nil && x.Pop3User != nil {
+		return *x.Pop3User
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetPop3Password() string {
+	if x != nil && x.Pop3Password != nil {
+		return *x.Pop3Password
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetPop3Starttls() bool {
+	if x != nil && x.Pop3Starttls != nil {
+		return *x.Pop3Starttls
+	}
+	return false
+}
+
+func (x *Healthcheck) GetScriptCommand() string {
+	if x != nil && x.ScriptCommand != nil {
+		return *x.ScriptCommand
+	}
+	return ""
+}
+
+func (x *Healthcheck) GetScriptArg() []string {
+	if x != nil {
+		return x.ScriptArg
+	}
+	return nil
+}
+
+func (x *Healthcheck) GetTcpHalfOpen() bool {
+	if x != nil && x.TcpHalfOpen != nil {
+		return *x.TcpHalfOpen
+	}
+	return false
+}
+
+func (x *Healthcheck) GetRise() int32 {
+	if x != nil && x.Rise != nil {
+		return *x.Rise
+	}
+	return 0
+}
+
+func (x *Healthcheck) GetFall() int32 {
+	if x != nil && x.Fall != nil {
+		return *x.Fall
+	}
+	return 0
+}
+
+func (x *Healthcheck) GetPassive() bool {
+	if x != nil && x.Passive != nil {
+		return *x.Passive
+	}
+	return false
+}
+
+func (x *Healthcheck) GetPassiveHold() bool {
+	if x != nil && x.PassiveHold != nil {
+		return *x.PassiveHold
+	}
+	return false
+}
+
 type VserverEntry struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -937,12 +1646,44 @@ type VserverEntry struct {
 	Healthcheck []*Healthcheck `protobuf:"bytes,13,rep,name=healthcheck" json:"healthcheck,omitempty"`
 	// Use "one packet" load balancing.
 	OnePacket *bool `protobuf:"varint,14,opt,name=one_packet,json=onePacket" json:"one_packet,omitempty"`
+	// The number of consecutive healthy results required before a newly added
+	// backend is inserted into IPVS.
+	WarmupHealthyCount *int32 `protobuf:"varint,15,opt,name=warmup_healthy_count,json=warmupHealthyCount" json:"warmup_healthy_count,omitempty"`
+	// Fall back to another backend when the hashed backend is unavailable.
+	// See sh-fallback and mh-fallback in --sched-flags in man ipvsadm(8).
+	// Only supported by the SH and MH schedulers.
+	SchedFallback *bool `protobuf:"varint,16,opt,name=sched_fallback,json=schedFallback,def=1" json:"sched_fallback,omitempty"`
+	// Include the source port in the hash. See sh-port and mh-port in
+	// --sched-flags in man ipvsadm(8). Only supported by the SH and MH
+	// schedulers.
+	SchedPort *bool `protobuf:"varint,17,opt,name=sched_port,json=schedPort,def=1" json:"sched_port,omitempty"`
+	// The prefix length used to group IPv4 clients for persistence, such that
+	// clients within the same network are sent to the same backend. See
+	// --netmask in man ipvsadm(8). Valid values are 1 to 32; if unset, clients
+	// are grouped by address.
+	PersistenceIpv4PrefixLength *int32 `protobuf:"varint,18,opt,name=persistence_ipv4_prefix_length,json=persistenceIpv4PrefixLength" json:"persistence_ipv4_prefix_length,omitempty"`
+	// The prefix length used to group IPv6 clients for persistence. Valid values
+	// are 1 to 128; if unset, clients are grouped by address.
+	PersistenceIpv6PrefixLength *int32 `protobuf:"varint,19,opt,name=persistence_ipv6_prefix_length,json=persistenceIpv6PrefixLength" json:"persistence_ipv6_prefix_length,omitempty"`
+	// Drain unhealthy backends by setting their weight to zero, so that existing
+	// connections can complete, rather than removing them from IPVS.
+	DrainUnhealthy *bool `protobuf:"varint,20,opt,name=drain_unhealthy,json=drainUnhealthy" json:"drain_unhealthy,omitempty"`
+	// The encapsulation used for TUN mode, one of "ipip", "gue" or "gre". GUE
+	// requires Linux 5.2 or later and GRE requires Linux 5.3 or later.
+	TunnelType *string `protobuf:"bytes,21,opt,name=tunnel_type,json=tunnelType" json:"tunnel_type,omitempty"`
+	// The UDP destination port for GUE encapsulation.
+	TunnelPort *int32 `protobuf:"varint,22,opt,name=tunnel_port,json=tunnelPort" json:"tunnel_port,omitempty"`
+	// The checksum handling for GUE or GRE encapsulation, one of "none", "csum"
+	// or "remcsum". Remote checksum offload is only supported for GUE.
+	TunnelChecksum *string `protobuf:"bytes,23,opt,name=tunnel_checksum,json=tunnelChecksum" json:"tunnel_checksum,omitempty"`
 }
 
 // Default values for VserverEntry fields.
 const (
-	Default_VserverEntry_Scheduler = VserverEntry_WLC
-	Default_VserverEntry_Mode      = VserverEntry_DSR
+	Default_VserverEntry_Scheduler     = VserverEntry_WLC
+	Default_VserverEntry_Mode          = VserverEntry_DSR
+	Default_VserverEntry_SchedFallback = bool(true)
+	Default_VserverEntry_SchedPort     = bool(true)
 )
 
 func (x *VserverEntry) Reset() {
@@ -1061,6 +1802,69 @@ func (x *VserverEntry) GetOnePacket() bool {
 	return false
 }
 
+func (x *VserverEntry) GetWarmupHealthyCount() int32 {
+	if x != nil && x.WarmupHealthyCount != nil {
+		return *x.WarmupHealthyCount
+	}
+	return 0
+}
+
+func (x *VserverEntry) GetSchedFallback() bool {
+	if x != nil && x.SchedFallback != nil {
+		return *x.SchedFallback
+	}
+	return Default_VserverEntry_SchedFallback
+}
+
+func (x *VserverEntry) GetSchedPort() bool {
+	if x != nil && x.SchedPort != nil {
+		return *x.SchedPort
+	}
+	return Default_VserverEntry_SchedPort
+}
+
+func (x *VserverEntry) GetPersistenceIpv4PrefixLength() int32 {
+	if x != nil && x.PersistenceIpv4PrefixLength != nil {
+		return *x.PersistenceIpv4PrefixLength
+	}
+	return 0
+}
+
+func (x *VserverEntry) GetPersistenceIpv6PrefixLength() int32 {
+	if x != nil && x.PersistenceIpv6PrefixLength != nil {
+		return *x.PersistenceIpv6PrefixLength
+	}
+	return 0
+}
+
+func (x *VserverEntry) GetDrainUnhealthy() bool {
+	if x != nil && x.DrainUnhealthy != nil {
+		return *x.DrainUnhealthy
+	}
+	return false
+}
+
+func (x *VserverEntry) GetTunnelType() string {
+	if x != nil && x.TunnelType != nil {
+		return *x.TunnelType
+	}
+	return ""
+}
+
+func (x *VserverEntry) GetTunnelPort() int32 {
+	if x != nil && x.TunnelPort != nil {
+		return *x.TunnelPort
+	}
+	return 0
+}
+
+func (x *VserverEntry) GetTunnelChecksum() string {
+	if x != nil && x.TunnelChecksum != nil {
+		return *x.TunnelChecksum
+	}
+	return ""
+}
+
 type AccessGrant struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -1208,6 +2012,16 @@ type Vserver struct {
 	Warning []string `protobuf:"bytes,9,rep,name=warning" json:"warning,omitempty"`
 	// The list of backends for this vserver.
 	Backend []*Backend `protobuf:"bytes,10,rep,name=backend" json:"backend,omitempty"`
+	// Healthcheck backends that have both IPv4 and IPv6 addresses once, using
+	// the engine's preferred healthcheck address family, and apply the result
+	// to the destinations for both address families. This should not be set if
+	// the IPv4 and IPv6 addresses of a backend refer to different servers.
+	ShareHealthchecks *bool `protobuf:"varint,11,opt,name=share_healthchecks,json=shareHealthchecks" json:"share_healthchecks,omitempty"`
+	// Send traffic for an address family to backends that only have an
+	// address in the other family, for example to front IPv4-only backends
+	// with an IPv6 VIP. This requires TUN mode for all entries and Linux 3.18
+	// or later.
+	MixedAddressFamilies *bool `protobuf:"varint,12,opt,name=mixed_address_families,json=mixedAddressFamilies" json:"mixed_address_families,omitempty"`
 }
 
 func (x *Vserver) Reset() {
@@ -1305,6 +2119,20 @@ func (x *Vserver) GetBackend() []*Backend {
 	return nil
 }
 
+func (x *Vserver) GetShareHealthchecks() bool {
+	if x != nil && x.ShareHealthchecks != nil {
+		return *x.ShareHealthchecks
+	}
+	return false
+}
+
+func (x *Vserver) GetMixedAddressFamilies() bool {
+	if x != nil && x.MixedAddressFamilies != nil {
+		return *x.MixedAddressFamilies
+	}
+	return false
+}
+
 type MisconfiguredVserver struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -1648,7 +2476,7 @@ var file_config_proto_rawDesc = []byte{
 	0x07, 0x76, 0x6c, 0x61, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x02, 0x28, 0x05, 0x52, 0x06,
 	0x76, 0x6c, 0x61, 0x6e, 0x49, 0x64, 0x12, 0x19, 0x0a, 0x04, 0x68, 0x6f, 0x73, 0x74, 0x18, 0x02,
 	0x20, 0x02, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x52, 0x04, 0x68, 0x6f, 0x73,
-	0x74, 0x22, 0xe7, 0x03, 0x0a, 0x0b, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x63, 0x68, 0x65, 0x63,
+	0x74, 0x22, 0xf3, 0x1b, 0x0a, 0x0b, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x63, 0x68, 0x65, 0x63,
 	0x6b, 0x12, 0x25, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x0e, 0x32,
 	0x11, 0x2e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x2e, 0x54, 0x79,
 	0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x1e, 0x0a, 0x08, 0x69, 0x6e, 0x74, 0x65,
@@ -1670,139 +2498,364 @@ var file_config_proto_rawDesc = []byte{
 	0x65, 0x72, 0x69, 0x66, 0x79, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x08, 0x3a, 0x04, 0x74, 0x72, 0x75,
 	0x65, 0x52, 0x09, 0x74, 0x6c, 0x73, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x12, 0x18, 0x0a, 0x07,
 	0x72, 0x65, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x72,
-	0x65, 0x74, 0x72, 0x69, 0x65, 0x73, 0x22, 0x5e, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0d,
-	0x0a, 0x09, 0x49, 0x43, 0x4d, 0x50, 0x5f, 0x50, 0x49, 0x4e, 0x47, 0x10, 0x01, 0x12, 0x07, 0x0a,
-	0x03, 0x55, 0x44, 0x50, 0x10, 0x02, 0x12, 0x07, 0x0a, 0x03, 0x54, 0x43, 0x50, 0x10, 0x03, 0x12,
-	0x08, 0x0a, 0x04, 0x48, 0x54, 0x54, 0x50, 0x10, 0x04, 0x12, 0x09, 0x0a, 0x05, 0x48, 0x54, 0x54,
-	0x50, 0x53, 0x10, 0x05, 0x12, 0x07, 0x0a, 0x03, 0x44, 0x4e, 0x53, 0x10, 0x06, 0x12, 0x0b, 0x0a,
-	0x07, 0x54, 0x43, 0x50, 0x5f, 0x54, 0x4c, 0x53, 0x10, 0x07, 0x12, 0x0a, 0x0a, 0x06, 0x52, 0x41,
-	0x44, 0x49, 0x55, 0x53, 0x10, 0x08, 0x22, 0x23, 0x0a, 0x04, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x09,
-	0x0a, 0x05, 0x50, 0x4c, 0x41, 0x49, 0x4e, 0x10, 0x01, 0x12, 0x07, 0x0a, 0x03, 0x44, 0x53, 0x52,
-	0x10, 0x02, 0x12, 0x07, 0x0a, 0x03, 0x54, 0x55, 0x4e, 0x10, 0x03, 0x22, 0xc9, 0x04, 0x0a, 0x0c,
-	0x56, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x25, 0x0a, 0x08,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x18, 0x01, 0x20, 0x02, 0x28, 0x0e, 0x32, 0x09,
-	0x2e, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x63, 0x6f, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x02, 0x20, 0x02, 0x28,
-	0x05, 0x52, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x3a, 0x0a, 0x09, 0x73, 0x63, 0x68, 0x65, 0x64,
-	0x75, 0x6c, 0x65, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x56, 0x73, 0x65,
-	0x72, 0x76, 0x65, 0x72, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x2e, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75,
-	0x6c, 0x65, 0x72, 0x3a, 0x03, 0x57, 0x4c, 0x43, 0x52, 0x09, 0x73, 0x63, 0x68, 0x65, 0x64, 0x75,
-	0x6c, 0x65, 0x72, 0x12, 0x2b, 0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28,
-	0x0e, 0x32, 0x12, 0x2e, 0x56, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x45, 0x6e, 0x74, 0x72, 0x79,
-	0x2e, 0x4d, 0x6f, 0x64, 0x65, 0x3a, 0x03, 0x44, 0x53, 0x52, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65,
-	0x12, 0x20, 0x0a, 0x0b, 0x70, 0x65, 0x72, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x63, 0x65, 0x18,
-	0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x70, 0x65, 0x72, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e,
-	0x63, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x71, 0x75, 0x69, 0x65, 0x73, 0x63, 0x65, 0x6e, 0x74, 0x18,
-	0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x71, 0x75, 0x69, 0x65, 0x73, 0x63, 0x65, 0x6e, 0x74,
-	0x12, 0x30, 0x0a, 0x14, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x6c, 0x6f, 0x77, 0x5f, 0x77,
-	0x61, 0x74, 0x65, 0x72, 0x6d, 0x61, 0x72, 0x6b, 0x18, 0x09, 0x20, 0x01, 0x28, 0x02, 0x52, 0x12,
-	0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x4c, 0x6f, 0x77, 0x57, 0x61, 0x74, 0x65, 0x72, 0x6d, 0x61,
-	0x72, 0x6b, 0x12, 0x32, 0x0a, 0x15, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x68, 0x69, 0x67,
-	0x68, 0x5f, 0x77, 0x61, 0x74, 0x65, 0x72, 0x6d, 0x61, 0x72, 0x6b, 0x18, 0x0a, 0x20, 0x01, 0x28,
-	0x02, 0x52, 0x13, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x48, 0x69, 0x67, 0x68, 0x57, 0x61, 0x74,
-	0x65, 0x72, 0x6d, 0x61, 0x72, 0x6b, 0x12, 0x1e, 0x0a, 0x0a, 0x6c, 0x74, 0x68, 0x72, 0x65, 0x73,
-	0x68, 0x6f, 0x6c, 0x64, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x6c, 0x74, 0x68, 0x72,
-	0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x75, 0x74, 0x68, 0x72, 0x65, 0x73,
-	0x68, 0x6f, 0x6c, 0x64, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x75, 0x74, 0x68, 0x72,
-	0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x12, 0x2e, 0x0a, 0x0b, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68,
-	0x63, 0x68, 0x65, 0x63, 0x6b, 0x18, 0x0d, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x48, 0x65,
-	0x61, 0x6c, 0x74, 0x68, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x0b, 0x68, 0x65, 0x61, 0x6c, 0x74,
-	0x68, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x12, 0x1d, 0x0a, 0x0a, 0x6f, 0x6e, 0x65, 0x5f, 0x70, 0x61,
-	0x63, 0x6b, 0x65, 0x74, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x6f, 0x6e, 0x65, 0x50,
-	0x61, 0x63, 0x6b, 0x65, 0x74, 0x22, 0x3d, 0x0a, 0x09, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c,
-	0x65, 0x72, 0x12, 0x06, 0x0a, 0x02, 0x52, 0x52, 0x10, 0x01, 0x12, 0x07, 0x0a, 0x03, 0x57, 0x52,
-	0x52, 0x10, 0x02, 0x12, 0x06, 0x0a, 0x02, 0x4c, 0x43, 0x10, 0x03, 0x12, 0x07, 0x0a, 0x03, 0x57,
-	0x4c, 0x43, 0x10, 0x04, 0x12, 0x06, 0x0a, 0x02, 0x53, 0x48, 0x10, 0x05, 0x12, 0x06, 0x0a, 0x02,
-	0x4d, 0x48, 0x10, 0x06, 0x22, 0x21, 0x0a, 0x04, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x07, 0x0a, 0x03,
-	0x44, 0x53, 0x52, 0x10, 0x01, 0x12, 0x07, 0x0a, 0x03, 0x4e, 0x41, 0x54, 0x10, 0x02, 0x12, 0x07,
-	0x0a, 0x03, 0x54, 0x55, 0x4e, 0x10, 0x03, 0x22, 0xae, 0x01, 0x0a, 0x0b, 0x41, 0x63, 0x63, 0x65,
-	0x73, 0x73, 0x47, 0x72, 0x61, 0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x67, 0x72, 0x61, 0x6e, 0x74,
-	0x65, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x07, 0x67, 0x72, 0x61, 0x6e, 0x74, 0x65,
-	0x65, 0x12, 0x25, 0x0a, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x02, 0x28, 0x0e, 0x32,
-	0x11, 0x2e, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x47, 0x72, 0x61, 0x6e, 0x74, 0x2e, 0x52, 0x6f,
-	0x6c, 0x65, 0x52, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x12, 0x25, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65,
-	0x18, 0x03, 0x20, 0x02, 0x28, 0x0e, 0x32, 0x11, 0x2e, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x47,
-	0x72, 0x61, 0x6e, 0x74, 0x2e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x22,
-	0x1a, 0x0a, 0x04, 0x52, 0x6f, 0x6c, 0x65, 0x12, 0x09, 0x0a, 0x05, 0x41, 0x44, 0x4d, 0x49, 0x4e,
-	0x10, 0x01, 0x12, 0x07, 0x0a, 0x03, 0x4f, 0x50, 0x53, 0x10, 0x02, 0x22, 0x1b, 0x0a, 0x04, 0x54,
-	0x79, 0x70, 0x65, 0x12, 0x08, 0x0a, 0x04, 0x55, 0x53, 0x45, 0x52, 0x10, 0x01, 0x12, 0x09, 0x0a,
-	0x05, 0x47, 0x52, 0x4f, 0x55, 0x50, 0x10, 0x02, 0x22, 0x39, 0x0a, 0x0b, 0x41, 0x63, 0x63, 0x65,
-	0x73, 0x73, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18,
-	0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x6d,
-	0x65, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x65, 0x6d,
-	0x62, 0x65, 0x72, 0x22, 0xdb, 0x02, 0x0a, 0x07, 0x56, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x12,
-	0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x6e,
-	0x61, 0x6d, 0x65, 0x12, 0x2a, 0x0a, 0x0d, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x5f, 0x61, 0x64, 0x64,
-	0x72, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x02, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x48, 0x6f, 0x73,
-	0x74, 0x52, 0x0c, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12,
-	0x0e, 0x0a, 0x02, 0x72, 0x70, 0x18, 0x03, 0x20, 0x02, 0x28, 0x09, 0x52, 0x02, 0x72, 0x70, 0x12,
-	0x17, 0x0a, 0x07, 0x75, 0x73, 0x65, 0x5f, 0x66, 0x77, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08,
-	0x52, 0x06, 0x75, 0x73, 0x65, 0x46, 0x77, 0x6d, 0x12, 0x32, 0x0a, 0x0d, 0x76, 0x73, 0x65, 0x72,
-	0x76, 0x65, 0x72, 0x5f, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32,
-	0x0d, 0x2e, 0x56, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0c,
-	0x76, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x2e, 0x0a, 0x0b,
-	0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x18, 0x07, 0x20, 0x03, 0x28,
-	0x0b, 0x32, 0x0c, 0x2e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x52,
-	0x0b, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x12, 0x2f, 0x0a, 0x0c,
-	0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x5f, 0x67, 0x72, 0x61, 0x6e, 0x74, 0x18, 0x08, 0x20, 0x03,
-	0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x47, 0x72, 0x61, 0x6e, 0x74,
-	0x52, 0x0b, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x47, 0x72, 0x61, 0x6e, 0x74, 0x12, 0x18, 0x0a,
-	0x07, 0x77, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x09, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07,
-	0x77, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x22, 0x0a, 0x07, 0x62, 0x61, 0x63, 0x6b, 0x65,
-	0x6e, 0x64, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65,
-	0x6e, 0x64, 0x52, 0x07, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x4a, 0x04, 0x08, 0x06, 0x10,
-	0x07, 0x52, 0x0e, 0x6c, 0x65, 0x67, 0x61, 0x63, 0x79, 0x5f, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e,
-	0x64, 0x22, 0x4f, 0x0a, 0x14, 0x4d, 0x69, 0x73, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72,
-	0x65, 0x64, 0x56, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
-	0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x23, 0x0a,
-	0x0d, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61,
-	0x67, 0x65, 0x22, 0x35, 0x0a, 0x09, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x12,
-	0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x6e,
-	0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x02,
-	0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x57, 0x0a, 0x08, 0x4d, 0x65, 0x74,
-	0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x21, 0x0a, 0x0c, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x75, 0x70,
-	0x64, 0x61, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20, 0x02, 0x28, 0x03, 0x52, 0x0b, 0x6c, 0x61, 0x73,
-	0x74, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x12, 0x28, 0x0a, 0x09, 0x61, 0x74, 0x74, 0x72,
-	0x69, 0x62, 0x75, 0x74, 0x65, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x41, 0x74,
-	0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x52, 0x09, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75,
-	0x74, 0x65, 0x22, 0xfb, 0x03, 0x0a, 0x07, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x12, 0x24,
-	0x0a, 0x0a, 0x73, 0x65, 0x65, 0x73, 0x61, 0x77, 0x5f, 0x76, 0x69, 0x70, 0x18, 0x01, 0x20, 0x02,
-	0x28, 0x0b, 0x32, 0x05, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x52, 0x09, 0x73, 0x65, 0x65, 0x73, 0x61,
-	0x77, 0x56, 0x69, 0x70, 0x12, 0x19, 0x0a, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x03,
-	0x28, 0x0b, 0x32, 0x05, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x52, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x12,
-	0x25, 0x0a, 0x04, 0x76, 0x6d, 0x61, 0x63, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x3a, 0x11, 0x30,
-	0x30, 0x3a, 0x30, 0x30, 0x3a, 0x35, 0x45, 0x3a, 0x30, 0x30, 0x3a, 0x30, 0x31, 0x3a, 0x30, 0x31,
-	0x52, 0x04, 0x76, 0x6d, 0x61, 0x63, 0x12, 0x29, 0x0a, 0x0d, 0x62, 0x67, 0x70, 0x5f, 0x6c, 0x6f,
-	0x63, 0x61, 0x6c, 0x5f, 0x61, 0x73, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x3a, 0x05, 0x36,
-	0x34, 0x35, 0x31, 0x32, 0x52, 0x0b, 0x62, 0x67, 0x70, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x41, 0x73,
-	0x6e, 0x12, 0x24, 0x0a, 0x0e, 0x62, 0x67, 0x70, 0x5f, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f,
-	0x61, 0x73, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x62, 0x67, 0x70, 0x52, 0x65,
-	0x6d, 0x6f, 0x74, 0x65, 0x41, 0x73, 0x6e, 0x12, 0x20, 0x0a, 0x08, 0x62, 0x67, 0x70, 0x5f, 0x70,
-	0x65, 0x65, 0x72, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x48, 0x6f, 0x73, 0x74,
-	0x52, 0x07, 0x62, 0x67, 0x70, 0x50, 0x65, 0x65, 0x72, 0x12, 0x22, 0x0a, 0x07, 0x76, 0x73, 0x65,
-	0x72, 0x76, 0x65, 0x72, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x56, 0x73, 0x65,
-	0x72, 0x76, 0x65, 0x72, 0x52, 0x07, 0x76, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x12, 0x19, 0x0a,
-	0x04, 0x76, 0x6c, 0x61, 0x6e, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x56, 0x6c,
-	0x61, 0x6e, 0x52, 0x04, 0x76, 0x6c, 0x61, 0x6e, 0x12, 0x4a, 0x0a, 0x15, 0x6d, 0x69, 0x73, 0x63,
-	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x65, 0x64, 0x5f, 0x76, 0x73, 0x65, 0x72, 0x76, 0x65,
-	0x72, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x4d, 0x69, 0x73, 0x63, 0x6f, 0x6e,
-	0x66, 0x69, 0x67, 0x75, 0x72, 0x65, 0x64, 0x56, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x52, 0x14,
-	0x6d, 0x69, 0x73, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x65, 0x64, 0x56, 0x73, 0x65,
-	0x72, 0x76, 0x65, 0x72, 0x12, 0x25, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61,
-	0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74,
-	0x61, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x30, 0x0a, 0x14, 0x64,
-	0x65, 0x64, 0x69, 0x63, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x76, 0x69, 0x70, 0x5f, 0x73, 0x75, 0x62,
-	0x6e, 0x65, 0x74, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x09, 0x52, 0x12, 0x64, 0x65, 0x64, 0x69, 0x63,
-	0x61, 0x74, 0x65, 0x64, 0x56, 0x69, 0x70, 0x53, 0x75, 0x62, 0x6e, 0x65, 0x74, 0x12, 0x31, 0x0a,
-	0x0d, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x5f, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x18, 0x0c,
-	0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x47, 0x72, 0x6f,
-	0x75, 0x70, 0x52, 0x0c, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x73,
-	0x2a, 0x1c, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12, 0x07, 0x0a, 0x03,
-	0x54, 0x43, 0x50, 0x10, 0x01, 0x12, 0x07, 0x0a, 0x03, 0x55, 0x44, 0x50, 0x10, 0x02, 0x42, 0x24,
-	0x5a, 0x22, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x6f, 0x6f,
-	0x67, 0x6c, 0x65, 0x2f, 0x73, 0x65, 0x65, 0x73, 0x61, 0x77, 0x2f, 0x70, 0x62, 0x2f, 0x63, 0x6f,
-	0x6e, 0x66, 0x69, 0x67,
+	0x65, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72,
+	0x18, 0x11, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x25,
+	0x0a, 0x0e, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x5f, 0x72, 0x65, 0x67, 0x65, 0x78, 0x70,
+	0x18, 0x12, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x52,
+	0x65, 0x67, 0x65, 0x78, 0x70, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x13,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x63, 0x6f, 0x64, 0x65, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x6d,
+	0x61, 0x78, 0x5f, 0x72, 0x65, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x73, 0x18, 0x14, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x0c, 0x6d, 0x61, 0x78, 0x52, 0x65, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x73,
+	0x12, 0x22, 0x0a, 0x0d, 0x74, 0x6c, 0x73, 0x5f, 0x63, 0x65, 0x72, 0x74, 0x5f, 0x66, 0x69, 0x6c,
+	0x65, 0x18, 0x15, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x74, 0x6c, 0x73, 0x43, 0x65, 0x72, 0x74,
+	0x46, 0x69, 0x6c, 0x65, 0x12, 0x20, 0x0a, 0x0c, 0x74, 0x6c, 0x73, 0x5f, 0x6b, 0x65, 0x79, 0x5f,
+	0x66, 0x69, 0x6c, 0x65, 0x18, 0x16, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x74, 0x6c, 0x73, 0x4b,
+	0x65, 0x79, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x1e, 0x0a, 0x0b, 0x74, 0x6c, 0x73, 0x5f, 0x63, 0x61,
+	0x5f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x17, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x74, 0x6c, 0x73,
+	0x43, 0x61, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74,
+	0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x18, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x65,
+	0x78, 0x70, 0x65, 0x63, 0x74, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x23, 0x0a, 0x0d, 0x66,
+	0x6f, 0x72, 0x62, 0x69, 0x64, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x19, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x0c, 0x66, 0x6f, 0x72, 0x62, 0x69, 0x64, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72,
+	0x12, 0x30, 0x0a, 0x14, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65,
+	0x72, 0x5f, 0x72, 0x65, 0x67, 0x65, 0x78, 0x70, 0x18, 0x1a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x12,
+	0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x65, 0x67, 0x65,
+	0x78, 0x70, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x72, 0x6f, 0x78, 0x79, 0x5f, 0x75, 0x72, 0x6c, 0x18,
+	0x1b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x78, 0x79, 0x55, 0x72, 0x6c, 0x12,
+	0x24, 0x0a, 0x0e, 0x6d, 0x61, 0x78, 0x5f, 0x6c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x5f, 0x6d,
+	0x73, 0x18, 0x1c, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x6d, 0x61, 0x78, 0x4c, 0x61, 0x74, 0x65,
+	0x6e, 0x63, 0x79, 0x4d, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x5f, 0x62, 0x6f, 0x64, 0x79, 0x18, 0x1d, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0b, 0x72, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x42, 0x6f, 0x64, 0x79, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6f, 0x6e, 0x74,
+	0x65, 0x6e, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x1e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
+	0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x26, 0x0a, 0x0f, 0x74,
+	0x6c, 0x73, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x1f,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x74, 0x6c, 0x73, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x4e,
+	0x61, 0x6d, 0x65, 0x12, 0x2e, 0x0a, 0x13, 0x74, 0x6c, 0x73, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6d,
+	0x65, 0x5f, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x20, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x11, 0x74, 0x6c, 0x73, 0x52, 0x65, 0x73, 0x75, 0x6d, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x73, 0x12, 0x2b, 0x0a, 0x11, 0x72, 0x65, 0x75, 0x73, 0x65, 0x5f, 0x63, 0x6f, 0x6e,
+	0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x21, 0x20, 0x01, 0x28, 0x08, 0x52, 0x10,
+	0x72, 0x65, 0x75, 0x73, 0x65, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x12, 0x2b, 0x0a, 0x11, 0x64, 0x6e, 0x73, 0x5f, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x74,
+	0x61, 0x74, 0x69, 0x76, 0x65, 0x18, 0x22, 0x20, 0x01, 0x28, 0x08, 0x52, 0x10, 0x64, 0x6e, 0x73,
+	0x41, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x74, 0x61, 0x74, 0x69, 0x76, 0x65, 0x12, 0x1b, 0x0a,
+	0x09, 0x64, 0x6e, 0x73, 0x5f, 0x72, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x23, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x64, 0x6e, 0x73, 0x52, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x20, 0x0a, 0x0c, 0x64, 0x6e,
+	0x73, 0x5f, 0x75, 0x64, 0x70, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x24, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x0a, 0x64, 0x6e, 0x73, 0x55, 0x64, 0x70, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x16, 0x0a, 0x06,
+	0x64, 0x6e, 0x73, 0x73, 0x65, 0x63, 0x18, 0x25, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x6e,
+	0x73, 0x73, 0x65, 0x63, 0x12, 0x2d, 0x0a, 0x13, 0x64, 0x6e, 0x73, 0x5f, 0x77, 0x61, 0x72, 0x6e,
+	0x5f, 0x6c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x5f, 0x6d, 0x73, 0x18, 0x26, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x10, 0x64, 0x6e, 0x73, 0x57, 0x61, 0x72, 0x6e, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63,
+	0x79, 0x4d, 0x73, 0x12, 0x2b, 0x0a, 0x12, 0x64, 0x6e, 0x73, 0x5f, 0x6d, 0x61, 0x78, 0x5f, 0x6c,
+	0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x5f, 0x6d, 0x73, 0x18, 0x27, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x0f, 0x64, 0x6e, 0x73, 0x4d, 0x61, 0x78, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x4d, 0x73,
+	0x12, 0x2c, 0x0a, 0x12, 0x64, 0x6e, 0x73, 0x5f, 0x71, 0x75, 0x65, 0x72, 0x79, 0x5f, 0x61, 0x74,
+	0x74, 0x65, 0x6d, 0x70, 0x74, 0x73, 0x18, 0x28, 0x20, 0x01, 0x28, 0x05, 0x52, 0x10, 0x64, 0x6e,
+	0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x41, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x73, 0x12, 0x22,
+	0x0a, 0x0d, 0x64, 0x6e, 0x73, 0x5f, 0x73, 0x74, 0x72, 0x69, 0x63, 0x74, 0x5f, 0x69, 0x64, 0x18,
+	0x29, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x64, 0x6e, 0x73, 0x53, 0x74, 0x72, 0x69, 0x63, 0x74,
+	0x49, 0x64, 0x12, 0x34, 0x0a, 0x16, 0x64, 0x6e, 0x73, 0x5f, 0x63, 0x61, 0x73, 0x65, 0x5f, 0x72,
+	0x61, 0x6e, 0x64, 0x6f, 0x6d, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x2a, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x14, 0x64, 0x6e, 0x73, 0x43, 0x61, 0x73, 0x65, 0x52, 0x61, 0x6e, 0x64, 0x6f,
+	0x6d, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x27, 0x0a, 0x0f, 0x72, 0x61, 0x64, 0x69,
+	0x75, 0x73, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x0d, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0e, 0x72, 0x61, 0x64, 0x69, 0x75, 0x73, 0x55, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d,
+	0x65, 0x12, 0x27, 0x0a, 0x0f, 0x72, 0x61, 0x64, 0x69, 0x75, 0x73, 0x5f, 0x70, 0x61, 0x73, 0x73,
+	0x77, 0x6f, 0x72, 0x64, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x72, 0x61, 0x64, 0x69,
+	0x75, 0x73, 0x50, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x61,
+	0x64, 0x69, 0x75, 0x73, 0x5f, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x18, 0x0f, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0c, 0x72, 0x61, 0x64, 0x69, 0x75, 0x73, 0x53, 0x65, 0x63, 0x72, 0x65, 0x74, 0x12,
+	0x27, 0x0a, 0x0f, 0x72, 0x61, 0x64, 0x69, 0x75, 0x73, 0x5f, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x18, 0x10, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x72, 0x61, 0x64, 0x69, 0x75, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x30, 0x0a, 0x14, 0x72, 0x61, 0x64, 0x69,
+	0x75, 0x73, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72,
+	0x18, 0x2b, 0x20, 0x01, 0x28, 0x08, 0x52, 0x12, 0x72, 0x61, 0x64, 0x69, 0x75, 0x73, 0x53, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x12, 0x21, 0x0a, 0x0c, 0x67, 0x72,
+	0x70, 0x63, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x18, 0x2c, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0b, 0x67, 0x72, 0x70, 0x63, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x1d, 0x0a,
+	0x0a, 0x6d, 0x79, 0x73, 0x71, 0x6c, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x18, 0x2d, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x6d, 0x79, 0x73, 0x71, 0x6c, 0x55, 0x73, 0x65, 0x72, 0x12, 0x25, 0x0a, 0x0e,
+	0x6d, 0x79, 0x73, 0x71, 0x6c, 0x5f, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x18, 0x2e,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6d, 0x79, 0x73, 0x71, 0x6c, 0x50, 0x61, 0x73, 0x73, 0x77,
+	0x6f, 0x72, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x6d, 0x79, 0x73, 0x71, 0x6c, 0x5f, 0x71, 0x75, 0x65,
+	0x72, 0x79, 0x18, 0x2f, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6d, 0x79, 0x73, 0x71, 0x6c, 0x51,
+	0x75, 0x65, 0x72, 0x79, 0x12, 0x32, 0x0a, 0x15, 0x6d, 0x79, 0x73, 0x71, 0x6c, 0x5f, 0x65, 0x78,
+	0x70, 0x65, 0x63, 0x74, 0x5f, 0x77, 0x72, 0x69, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x30, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x13, 0x6d, 0x79, 0x73, 0x71, 0x6c, 0x45, 0x78, 0x70, 0x65, 0x63, 0x74,
+	0x57, 0x72, 0x69, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x70, 0x6f, 0x73, 0x74,
+	0x67, 0x72, 0x65, 0x73, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x18, 0x31, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0c, 0x70, 0x6f, 0x73, 0x74, 0x67, 0x72, 0x65, 0x73, 0x55, 0x73, 0x65, 0x72, 0x12, 0x2b, 0x0a,
+	0x11, 0x70, 0x6f, 0x73, 0x74, 0x67, 0x72, 0x65, 0x73, 0x5f, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f,
+	0x72, 0x64, 0x18, 0x32, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x70, 0x6f, 0x73, 0x74, 0x67, 0x72,
+	0x65, 0x73, 0x50, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x12, 0x2b, 0x0a, 0x11, 0x70, 0x6f,
+	0x73, 0x74, 0x67, 0x72, 0x65, 0x73, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x18,
+	0x33, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x70, 0x6f, 0x73, 0x74, 0x67, 0x72, 0x65, 0x73, 0x44,
+	0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x12, 0x29, 0x0a, 0x10, 0x70, 0x6f, 0x73, 0x74, 0x67,
+	0x72, 0x65, 0x73, 0x5f, 0x73, 0x73, 0x6c, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x34, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0f, 0x70, 0x6f, 0x73, 0x74, 0x67, 0x72, 0x65, 0x73, 0x53, 0x73, 0x6c, 0x6d, 0x6f,
+	0x64, 0x65, 0x12, 0x36, 0x0a, 0x17, 0x70, 0x6f, 0x73, 0x74, 0x67, 0x72, 0x65, 0x73, 0x5f, 0x65,
+	0x78, 0x70, 0x65, 0x63, 0x74, 0x5f, 0x70, 0x72, 0x69, 0x6d, 0x61, 0x72, 0x79, 0x18, 0x35, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x15, 0x70, 0x6f, 0x73, 0x74, 0x67, 0x72, 0x65, 0x73, 0x45, 0x78, 0x70,
+	0x65, 0x63, 0x74, 0x50, 0x72, 0x69, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x25, 0x0a, 0x0e, 0x72, 0x65,
+	0x64, 0x69, 0x73, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x36, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0d, 0x72, 0x65, 0x64, 0x69, 0x73, 0x55, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d,
+	0x65, 0x12, 0x25, 0x0a, 0x0e, 0x72, 0x65, 0x64, 0x69, 0x73, 0x5f, 0x70, 0x61, 0x73, 0x73, 0x77,
+	0x6f, 0x72, 0x64, 0x18, 0x37, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x72, 0x65, 0x64, 0x69, 0x73,
+	0x50, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x64, 0x69,
+	0x73, 0x5f, 0x72, 0x6f, 0x6c, 0x65, 0x18, 0x38, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65,
+	0x64, 0x69, 0x73, 0x52, 0x6f, 0x6c, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x6d, 0x65, 0x6d, 0x63, 0x61,
+	0x63, 0x68, 0x65, 0x5f, 0x62, 0x69, 0x6e, 0x61, 0x72, 0x79, 0x18, 0x39, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x0e, 0x6d, 0x65, 0x6d, 0x63, 0x61, 0x63, 0x68, 0x65, 0x42, 0x69, 0x6e, 0x61, 0x72, 0x79,
+	0x12, 0x2c, 0x0a, 0x12, 0x6d, 0x65, 0x6d, 0x63, 0x61, 0x63, 0x68, 0x65, 0x5f, 0x73, 0x74, 0x61,
+	0x74, 0x73, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x3a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x6d, 0x65,
+	0x6d, 0x63, 0x61, 0x63, 0x68, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x4b, 0x65, 0x79, 0x12, 0x30,
+	0x0a, 0x14, 0x6d, 0x65, 0x6d, 0x63, 0x61, 0x63, 0x68, 0x65, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x73,
+	0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x3b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x12, 0x6d, 0x65,
+	0x6d, 0x63, 0x61, 0x63, 0x68, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x56, 0x61, 0x6c, 0x75, 0x65,
+	0x12, 0x2e, 0x0a, 0x13, 0x6d, 0x65, 0x6d, 0x63, 0x61, 0x63, 0x68, 0x65, 0x5f, 0x6b, 0x65, 0x79,
+	0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x3c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x6d,
+	0x65, 0x6d, 0x63, 0x61, 0x63, 0x68, 0x65, 0x4b, 0x65, 0x79, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78,
+	0x12, 0x23, 0x0a, 0x0d, 0x73, 0x6d, 0x74, 0x70, 0x5f, 0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x3d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x73, 0x6d, 0x74, 0x70, 0x48, 0x6f, 0x73,
+	0x74, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x73, 0x6d, 0x74, 0x70, 0x5f, 0x73, 0x74,
+	0x61, 0x72, 0x74, 0x74, 0x6c, 0x73, 0x18, 0x3e, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x73, 0x6d,
+	0x74, 0x70, 0x53, 0x74, 0x61, 0x72, 0x74, 0x74, 0x6c, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x73, 0x6d,
+	0x74, 0x70, 0x5f, 0x63, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x3f, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0e, 0x73, 0x6d, 0x74, 0x70, 0x43, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c,
+	0x69, 0x74, 0x79, 0x12, 0x20, 0x0a, 0x0c, 0x6c, 0x64, 0x61, 0x70, 0x5f, 0x62, 0x69, 0x6e, 0x64,
+	0x5f, 0x64, 0x6e, 0x18, 0x40, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6c, 0x64, 0x61, 0x70, 0x42,
+	0x69, 0x6e, 0x64, 0x44, 0x6e, 0x12, 0x23, 0x0a, 0x0d, 0x6c, 0x64, 0x61, 0x70, 0x5f, 0x70, 0x61,
+	0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x18, 0x41, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6c, 0x64,
+	0x61, 0x70, 0x50, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x12, 0x24, 0x0a, 0x0e, 0x6c, 0x64,
+	0x61, 0x70, 0x5f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x5f, 0x64, 0x6e, 0x18, 0x42, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0c, 0x6c, 0x64, 0x61, 0x70, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x44, 0x6e,
+	0x12, 0x23, 0x0a, 0x0d, 0x6c, 0x64, 0x61, 0x70, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x74, 0x74, 0x6c,
+	0x73, 0x18, 0x43, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x6c, 0x64, 0x61, 0x70, 0x53, 0x74, 0x61,
+	0x72, 0x74, 0x74, 0x6c, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x6e, 0x74, 0x70, 0x5f, 0x6d, 0x61, 0x78,
+	0x5f, 0x73, 0x74, 0x72, 0x61, 0x74, 0x75, 0x6d, 0x18, 0x44, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d,
+	0x6e, 0x74, 0x70, 0x4d, 0x61, 0x78, 0x53, 0x74, 0x72, 0x61, 0x74, 0x75, 0x6d, 0x12, 0x3a, 0x0a,
+	0x1a, 0x6e, 0x74, 0x70, 0x5f, 0x6d, 0x61, 0x78, 0x5f, 0x72, 0x6f, 0x6f, 0x74, 0x5f, 0x64, 0x69,
+	0x73, 0x70, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x73, 0x18, 0x45, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x16, 0x6e, 0x74, 0x70, 0x4d, 0x61, 0x78, 0x52, 0x6f, 0x6f, 0x74, 0x44, 0x69, 0x73,
+	0x70, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x12, 0x29, 0x0a, 0x11, 0x6e, 0x74, 0x70,
+	0x5f, 0x6d, 0x61, 0x78, 0x5f, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x5f, 0x6d, 0x73, 0x18, 0x46,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x6e, 0x74, 0x70, 0x4d, 0x61, 0x78, 0x4f, 0x66, 0x66, 0x73,
+	0x65, 0x74, 0x4d, 0x73, 0x12, 0x19, 0x0a, 0x08, 0x66, 0x74, 0x70, 0x5f, 0x75, 0x73, 0x65, 0x72,
+	0x18, 0x47, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x66, 0x74, 0x70, 0x55, 0x73, 0x65, 0x72, 0x12,
+	0x21, 0x0a, 0x0c, 0x66, 0x74, 0x70, 0x5f, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x18,
+	0x48, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x66, 0x74, 0x70, 0x50, 0x61, 0x73, 0x73, 0x77, 0x6f,
+	0x72, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x66, 0x74, 0x70, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
+	0x64, 0x18, 0x49, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x66, 0x74, 0x70, 0x43, 0x6f, 0x6d, 0x6d,
+	0x61, 0x6e, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x66, 0x74, 0x70, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x18,
+	0x4a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x66, 0x74, 0x70, 0x50, 0x61, 0x74, 0x68, 0x12, 0x1b,
+	0x0a, 0x09, 0x69, 0x6d, 0x61, 0x70, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x18, 0x4b, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x69, 0x6d, 0x61, 0x70, 0x55, 0x73, 0x65, 0x72, 0x12, 0x23, 0x0a, 0x0d, 0x69,
+	0x6d, 0x61, 0x70, 0x5f, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x18, 0x4c, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0c, 0x69, 0x6d, 0x61, 0x70, 0x50, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64,
+	0x12, 0x21, 0x0a, 0x0c, 0x69, 0x6d, 0x61, 0x70, 0x5f, 0x6d, 0x61, 0x69, 0x6c, 0x62, 0x6f, 0x78,
+	0x18, 0x4d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x69, 0x6d, 0x61, 0x70, 0x4d, 0x61, 0x69, 0x6c,
+	0x62, 0x6f, 0x78, 0x12, 0x23, 0x0a, 0x0d, 0x69, 0x6d, 0x61, 0x70, 0x5f, 0x73, 0x74, 0x61, 0x72,
+	0x74, 0x74, 0x6c, 0x73, 0x18, 0x4e, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x69, 0x6d, 0x61, 0x70,
+	0x53, 0x74, 0x61, 0x72, 0x74, 0x74, 0x6c, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x6f, 0x70, 0x33,
+	0x5f, 0x75, 0x73, 0x65, 0x72, 0x18, 0x4f, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x6f, 0x70,
+	0x33, 0x55, 0x73, 0x65, 0x72, 0x12, 0x23, 0x0a, 0x0d, 0x70, 0x6f, 0x70, 0x33, 0x5f, 0x70, 0x61,
+	0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x18, 0x50, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x70, 0x6f,
+	0x70, 0x33, 0x50, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x12, 0x23, 0x0a, 0x0d, 0x70, 0x6f,
+	0x70, 0x33, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x74, 0x74, 0x6c, 0x73, 0x18, 0x51, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x0c, 0x70, 0x6f, 0x70, 0x33, 0x53, 0x74, 0x61, 0x72, 0x74, 0x74, 0x6c, 0x73, 0x12,
+	0x25, 0x0a, 0x0e, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
+	0x64, 0x18, 0x52, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x43,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74,
+	0x5f, 0x61, 0x72, 0x67, 0x18, 0x53, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x73, 0x63, 0x72, 0x69,
+	0x70, 0x74, 0x41, 0x72, 0x67, 0x12, 0x22, 0x0a, 0x0d, 0x74, 0x63, 0x70, 0x5f, 0x68, 0x61, 0x6c,
+	0x66, 0x5f, 0x6f, 0x70, 0x65, 0x6e, 0x18, 0x54, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x74, 0x63,
+	0x70, 0x48, 0x61, 0x6c, 0x66, 0x4f, 0x70, 0x65, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x69, 0x73,
+	0x65, 0x18, 0x55, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x72, 0x69, 0x73, 0x65, 0x12, 0x12, 0x0a,
+	0x04, 0x66, 0x61, 0x6c, 0x6c, 0x18, 0x56, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x66, 0x61, 0x6c,
+	0x6c, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x73, 0x73, 0x69, 0x76, 0x65, 0x18, 0x57, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x07, 0x70, 0x61, 0x73, 0x73, 0x69, 0x76, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x70,
+	0x61, 0x73, 0x73, 0x69, 0x76, 0x65, 0x5f, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x58, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x0b, 0x70, 0x61, 0x73, 0x73, 0x69, 0x76, 0x65, 0x48, 0x6f, 0x6c, 0x64, 0x22, 0xb1,
+	0x02, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0d, 0x0a, 0x09, 0x49, 0x43, 0x4d, 0x50, 0x5f,
+	0x50, 0x49, 0x4e, 0x47, 0x10, 0x01, 0x12, 0x07, 0x0a, 0x03, 0x55, 0x44, 0x50, 0x10, 0x02, 0x12,
+	0x07, 0x0a, 0x03, 0x54, 0x43, 0x50, 0x10, 0x03, 0x12, 0x08, 0x0a, 0x04, 0x48, 0x54, 0x54, 0x50,
+	0x10, 0x04, 0x12, 0x09, 0x0a, 0x05, 0x48, 0x54, 0x54, 0x50, 0x53, 0x10, 0x05, 0x12, 0x07, 0x0a,
+	0x03, 0x44, 0x4e, 0x53, 0x10, 0x06, 0x12, 0x0b, 0x0a, 0x07, 0x54, 0x43, 0x50, 0x5f, 0x54, 0x4c,
+	0x53, 0x10, 0x07, 0x12, 0x0a, 0x0a, 0x06, 0x52, 0x41, 0x44, 0x49, 0x55, 0x53, 0x10, 0x08, 0x12,
+	0x08, 0x0a, 0x04, 0x47, 0x52, 0x50, 0x43, 0x10, 0x09, 0x12, 0x0c, 0x0a, 0x08, 0x47, 0x52, 0x50,
+	0x43, 0x5f, 0x54, 0x4c, 0x53, 0x10, 0x0a, 0x12, 0x09, 0x0a, 0x05, 0x4d, 0x59, 0x53, 0x51, 0x4c,
+	0x10, 0x0b, 0x12, 0x0e, 0x0a, 0x0a, 0x50, 0x4f, 0x53, 0x54, 0x47, 0x52, 0x45, 0x53, 0x51, 0x4c,
+	0x10, 0x0c, 0x12, 0x09, 0x0a, 0x05, 0x52, 0x45, 0x44, 0x49, 0x53, 0x10, 0x0d, 0x12, 0x0c, 0x0a,
+	0x08, 0x4d, 0x45, 0x4d, 0x43, 0x41, 0x43, 0x48, 0x45, 0x10, 0x0e, 0x12, 0x08, 0x0a, 0x04, 0x53,
+	0x4d, 0x54, 0x50, 0x10, 0x0f, 0x12, 0x08, 0x0a, 0x04, 0x4c, 0x44, 0x41, 0x50, 0x10, 0x10, 0x12,
+	0x09, 0x0a, 0x05, 0x4c, 0x44, 0x41, 0x50, 0x53, 0x10, 0x11, 0x12, 0x07, 0x0a, 0x03, 0x53, 0x49,
+	0x50, 0x10, 0x12, 0x12, 0x0b, 0x0a, 0x07, 0x53, 0x49, 0x50, 0x5f, 0x54, 0x43, 0x50, 0x10, 0x13,
+	0x12, 0x07, 0x0a, 0x03, 0x4e, 0x54, 0x50, 0x10, 0x14, 0x12, 0x07, 0x0a, 0x03, 0x46, 0x54, 0x50,
+	0x10, 0x15, 0x12, 0x08, 0x0a, 0x04, 0x46, 0x54, 0x50, 0x53, 0x10, 0x16, 0x12, 0x08, 0x0a, 0x04,
+	0x49, 0x4d, 0x41, 0x50, 0x10, 0x17, 0x12, 0x09, 0x0a, 0x05, 0x49, 0x4d, 0x41, 0x50, 0x53, 0x10,
+	0x18, 0x12, 0x08, 0x0a, 0x04, 0x50, 0x4f, 0x50, 0x33, 0x10, 0x19, 0x12, 0x09, 0x0a, 0x05, 0x50,
+	0x4f, 0x50, 0x33, 0x53, 0x10, 0x1a, 0x12, 0x0a, 0x0a, 0x06, 0x53, 0x43, 0x52, 0x49, 0x50, 0x54,
+	0x10, 0x1b, 0x22, 0x23, 0x0a, 0x04, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x09, 0x0a, 0x05, 0x50, 0x4c,
+	0x41, 0x49, 0x4e, 0x10, 0x01, 0x12, 0x07, 0x0a, 0x03, 0x44, 0x53, 0x52, 0x10, 0x02, 0x12, 0x07,
+	0x0a, 0x03, 0x54, 0x55, 0x4e, 0x10, 0x03, 0x22, 0xeb, 0x07, 0x0a, 0x0c, 0x56, 0x73, 0x65, 0x72,
+	0x76, 0x65, 0x72, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x25, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x63, 0x6f, 0x6c, 0x18, 0x01, 0x20, 0x02, 0x28, 0x0e, 0x32, 0x09, 0x2e, 0x50, 0x72, 0x6f,
+	0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12,
+	0x12, 0x0a, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x02, 0x20, 0x02, 0x28, 0x05, 0x52, 0x04, 0x70,
+	0x6f, 0x72, 0x74, 0x12, 0x3a, 0x0a, 0x09, 0x73, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x72,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x56, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x2e, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x72, 0x3a,
+	0x03, 0x57, 0x4c, 0x43, 0x52, 0x09, 0x73, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x72, 0x12,
+	0x2b, 0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x12, 0x2e,
+	0x56, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x2e, 0x4d, 0x6f, 0x64,
+	0x65, 0x3a, 0x03, 0x44, 0x53, 0x52, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x12, 0x20, 0x0a, 0x0b,
+	0x70, 0x65, 0x72, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0b, 0x70, 0x65, 0x72, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x1c,
+	0x0a, 0x09, 0x71, 0x75, 0x69, 0x65, 0x73, 0x63, 0x65, 0x6e, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x09, 0x71, 0x75, 0x69, 0x65, 0x73, 0x63, 0x65, 0x6e, 0x74, 0x12, 0x30, 0x0a, 0x14,
+	0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x6c, 0x6f, 0x77, 0x5f, 0x77, 0x61, 0x74, 0x65, 0x72,
+	0x6d, 0x61, 0x72, 0x6b, 0x18, 0x09, 0x20, 0x01, 0x28, 0x02, 0x52, 0x12, 0x73, 0x65, 0x72, 0x76,
+	0x65, 0x72, 0x4c, 0x6f, 0x77, 0x57, 0x61, 0x74, 0x65, 0x72, 0x6d, 0x61, 0x72, 0x6b, 0x12, 0x32,
+	0x0a, 0x15, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x68, 0x69, 0x67, 0x68, 0x5f, 0x77, 0x61,
+	0x74, 0x65, 0x72, 0x6d, 0x61, 0x72, 0x6b, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x02, 0x52, 0x13, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x72, 0x48, 0x69, 0x67, 0x68, 0x57, 0x61, 0x74, 0x65, 0x72, 0x6d, 0x61,
+	0x72, 0x6b, 0x12, 0x1e, 0x0a, 0x0a, 0x6c, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x6c, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f,
+	0x6c, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x75, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64,
+	0x18, 0x0c, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x75, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f,
+	0x6c, 0x64, 0x12, 0x2e, 0x0a, 0x0b, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x63, 0x68, 0x65, 0x63,
+	0x6b, 0x18, 0x0d, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68,
+	0x63, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x0b, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x63, 0x68, 0x65,
+	0x63, 0x6b, 0x12, 0x1d, 0x0a, 0x0a, 0x6f, 0x6e, 0x65, 0x5f, 0x70, 0x61, 0x63, 0x6b, 0x65, 0x74,
+	0x18, 0x0e, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x6f, 0x6e, 0x65, 0x50, 0x61, 0x63, 0x6b, 0x65,
+	0x74, 0x12, 0x30, 0x0a, 0x14, 0x77, 0x61, 0x72, 0x6d, 0x75, 0x70, 0x5f, 0x68, 0x65, 0x61, 0x6c,
+	0x74, 0x68, 0x79, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x12, 0x77, 0x61, 0x72, 0x6d, 0x75, 0x70, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x79, 0x43, 0x6f,
+	0x75, 0x6e, 0x74, 0x12, 0x2b, 0x0a, 0x0e, 0x73, 0x63, 0x68, 0x65, 0x64, 0x5f, 0x66, 0x61, 0x6c,
+	0x6c, 0x62, 0x61, 0x63, 0x6b, 0x18, 0x10, 0x20, 0x01, 0x28, 0x08, 0x3a, 0x04, 0x74, 0x72, 0x75,
+	0x65, 0x52, 0x0d, 0x73, 0x63, 0x68, 0x65, 0x64, 0x46, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b,
+	0x12, 0x23, 0x0a, 0x0a, 0x73, 0x63, 0x68, 0x65, 0x64, 0x5f, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x11,
+	0x20, 0x01, 0x28, 0x08, 0x3a, 0x04, 0x74, 0x72, 0x75, 0x65, 0x52, 0x09, 0x73, 0x63, 0x68, 0x65,
+	0x64, 0x50, 0x6f, 0x72, 0x74, 0x12, 0x43, 0x0a, 0x1e, 0x70, 0x65, 0x72, 0x73, 0x69, 0x73, 0x74,
+	0x65, 0x6e, 0x63, 0x65, 0x5f, 0x69, 0x70, 0x76, 0x34, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78,
+	0x5f, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x12, 0x20, 0x01, 0x28, 0x05, 0x52, 0x1b, 0x70,
+	0x65, 0x72, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x63, 0x65, 0x49, 0x70, 0x76, 0x34, 0x50, 0x72,
+	0x65, 0x66, 0x69, 0x78, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12, 0x43, 0x0a, 0x1e, 0x70, 0x65,
+	0x72, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x63, 0x65, 0x5f, 0x69, 0x70, 0x76, 0x36, 0x5f, 0x70,
+	0x72, 0x65, 0x66, 0x69, 0x78, 0x5f, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x13, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x1b, 0x70, 0x65, 0x72, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x63, 0x65, 0x49,
+	0x70, 0x76, 0x36, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12,
+	0x27, 0x0a, 0x0f, 0x64, 0x72, 0x61, 0x69, 0x6e, 0x5f, 0x75, 0x6e, 0x68, 0x65, 0x61, 0x6c, 0x74,
+	0x68, 0x79, 0x18, 0x14, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x64, 0x72, 0x61, 0x69, 0x6e, 0x55,
+	0x6e, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x79, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x75, 0x6e, 0x6e,
+	0x65, 0x6c, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x15, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x74,
+	0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x75, 0x6e,
+	0x6e, 0x65, 0x6c, 0x5f, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x16, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a,
+	0x74, 0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x50, 0x6f, 0x72, 0x74, 0x12, 0x27, 0x0a, 0x0f, 0x74, 0x75,
+	0x6e, 0x6e, 0x65, 0x6c, 0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x18, 0x17, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0e, 0x74, 0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x73, 0x75, 0x6d, 0x22, 0x3d, 0x0a, 0x09, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x72,
+	0x12, 0x06, 0x0a, 0x02, 0x52, 0x52, 0x10, 0x01, 0x12, 0x07, 0x0a, 0x03, 0x57, 0x52, 0x52, 0x10,
+	0x02, 0x12, 0x06, 0x0a, 0x02, 0x4c, 0x43, 0x10, 0x03, 0x12, 0x07, 0x0a, 0x03, 0x57, 0x4c, 0x43,
+	0x10, 0x04, 0x12, 0x06, 0x0a, 0x02, 0x53, 0x48, 0x10, 0x05, 0x12, 0x06, 0x0a, 0x02, 0x4d, 0x48,
+	0x10, 0x06, 0x22, 0x21, 0x0a, 0x04, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x07, 0x0a, 0x03, 0x44, 0x53,
+	0x52, 0x10, 0x01, 0x12, 0x07, 0x0a, 0x03, 0x4e, 0x41, 0x54, 0x10, 0x02, 0x12, 0x07, 0x0a, 0x03,
+	0x54, 0x55, 0x4e, 0x10, 0x03, 0x22, 0xae, 0x01, 0x0a, 0x0b, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73,
+	0x47, 0x72, 0x61, 0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x67, 0x72, 0x61, 0x6e, 0x74, 0x65, 0x65,
+	0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x07, 0x67, 0x72, 0x61, 0x6e, 0x74, 0x65, 0x65, 0x12,
+	0x25, 0x0a, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x02, 0x28, 0x0e, 0x32, 0x11, 0x2e,
+	0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x47, 0x72, 0x61, 0x6e, 0x74, 0x2e, 0x52, 0x6f, 0x6c, 0x65,
+	0x52, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x12, 0x25, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03,
+	0x20, 0x02, 0x28, 0x0e, 0x32, 0x11, 0x2e, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x47, 0x72, 0x61,
+	0x6e, 0x74, 0x2e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x22, 0x1a, 0x0a,
+	0x04, 0x52, 0x6f, 0x6c, 0x65, 0x12, 0x09, 0x0a, 0x05, 0x41, 0x44, 0x4d, 0x49, 0x4e, 0x10, 0x01,
+	0x12, 0x07, 0x0a, 0x03, 0x4f, 0x50, 0x53, 0x10, 0x02, 0x22, 0x1b, 0x0a, 0x04, 0x54, 0x79, 0x70,
+	0x65, 0x12, 0x08, 0x0a, 0x04, 0x55, 0x53, 0x45, 0x52, 0x10, 0x01, 0x12, 0x09, 0x0a, 0x05, 0x47,
+	0x52, 0x4f, 0x55, 0x50, 0x10, 0x02, 0x22, 0x39, 0x0a, 0x0b, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73,
+	0x47, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x02, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x6d,
+	0x62, 0x65, 0x72, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x65, 0x6d, 0x62, 0x65,
+	0x72, 0x22, 0xc0, 0x03, 0x0a, 0x07, 0x56, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x12, 0x12, 0x0a,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x12, 0x2a, 0x0a, 0x0d, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65,
+	0x73, 0x73, 0x18, 0x02, 0x20, 0x02, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x52,
+	0x0c, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x0e, 0x0a,
+	0x02, 0x72, 0x70, 0x18, 0x03, 0x20, 0x02, 0x28, 0x09, 0x52, 0x02, 0x72, 0x70, 0x12, 0x17, 0x0a,
+	0x07, 0x75, 0x73, 0x65, 0x5f, 0x66, 0x77, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06,
+	0x75, 0x73, 0x65, 0x46, 0x77, 0x6d, 0x12, 0x32, 0x0a, 0x0d, 0x76, 0x73, 0x65, 0x72, 0x76, 0x65,
+	0x72, 0x5f, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e,
+	0x56, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0c, 0x76, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x72, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x2e, 0x0a, 0x0b, 0x68, 0x65,
+	0x61, 0x6c, 0x74, 0x68, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x0c, 0x2e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x0b, 0x68,
+	0x65, 0x61, 0x6c, 0x74, 0x68, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x12, 0x2f, 0x0a, 0x0c, 0x61, 0x63,
+	0x63, 0x65, 0x73, 0x73, 0x5f, 0x67, 0x72, 0x61, 0x6e, 0x74, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x0c, 0x2e, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x47, 0x72, 0x61, 0x6e, 0x74, 0x52, 0x0b,
+	0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x47, 0x72, 0x61, 0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x77,
+	0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x09, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x77, 0x61,
+	0x72, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x22, 0x0a, 0x07, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64,
+	0x18, 0x0a, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64,
+	0x52, 0x07, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x12, 0x2d, 0x0a, 0x12, 0x73, 0x68, 0x61,
+	0x72, 0x65, 0x5f, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x18,
+	0x0b, 0x20, 0x01, 0x28, 0x08, 0x52, 0x11, 0x73, 0x68, 0x61, 0x72, 0x65, 0x48, 0x65, 0x61, 0x6c,
+	0x74, 0x68, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x12, 0x34, 0x0a, 0x16, 0x6d, 0x69, 0x78, 0x65,
+	0x64, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x5f, 0x66, 0x61, 0x6d, 0x69, 0x6c, 0x69,
+	0x65, 0x73, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x08, 0x52, 0x14, 0x6d, 0x69, 0x78, 0x65, 0x64, 0x41,
+	0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x46, 0x61, 0x6d, 0x69, 0x6c, 0x69, 0x65, 0x73, 0x4a, 0x04,
+	0x08, 0x06, 0x10, 0x07, 0x52, 0x0e, 0x6c, 0x65, 0x67, 0x61, 0x63, 0x79, 0x5f, 0x62, 0x61, 0x63,
+	0x6b, 0x65, 0x6e, 0x64, 0x22, 0x4f, 0x0a, 0x14, 0x4d, 0x69, 0x73, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x75, 0x72, 0x65, 0x64, 0x56, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04,
+	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x12, 0x23, 0x0a, 0x0d, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x4d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x35, 0x0a, 0x09, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75,
+	0x74, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x02, 0x20, 0x02, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x57, 0x0a, 0x08,
+	0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x21, 0x0a, 0x0c, 0x6c, 0x61, 0x73, 0x74,
+	0x5f, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20, 0x02, 0x28, 0x03, 0x52, 0x0b,
+	0x6c, 0x61, 0x73, 0x74, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x12, 0x28, 0x0a, 0x09, 0x61,
+	0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0a,
+	0x2e, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x52, 0x09, 0x61, 0x74, 0x74, 0x72,
+	0x69, 0x62, 0x75, 0x74, 0x65, 0x22, 0xfb, 0x03, 0x0a, 0x07, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65,
+	0x72, 0x12, 0x24, 0x0a, 0x0a, 0x73, 0x65, 0x65, 0x73, 0x61, 0x77, 0x5f, 0x76, 0x69, 0x70, 0x18,
+	0x01, 0x20, 0x02, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x52, 0x09, 0x73, 0x65,
+	0x65, 0x73, 0x61, 0x77, 0x56, 0x69, 0x70, 0x12, 0x19, 0x0a, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x52, 0x04, 0x6e, 0x6f,
+	0x64, 0x65, 0x12, 0x25, 0x0a, 0x04, 0x76, 0x6d, 0x61, 0x63, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x3a, 0x11, 0x30, 0x30, 0x3a, 0x30, 0x30, 0x3a, 0x35, 0x45, 0x3a, 0x30, 0x30, 0x3a, 0x30, 0x31,
+	0x3a, 0x30, 0x31, 0x52, 0x04, 0x76, 0x6d, 0x61, 0x63, 0x12, 0x29, 0x0a, 0x0d, 0x62, 0x67, 0x70,
+	0x5f, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x5f, 0x61, 0x73, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d,
+	0x3a, 0x05, 0x36, 0x34, 0x35, 0x31, 0x32, 0x52, 0x0b, 0x62, 0x67, 0x70, 0x4c, 0x6f, 0x63, 0x61,
+	0x6c, 0x41, 0x73, 0x6e, 0x12, 0x24, 0x0a, 0x0e, 0x62, 0x67, 0x70, 0x5f, 0x72, 0x65, 0x6d, 0x6f,
+	0x74, 0x65, 0x5f, 0x61, 0x73, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x62, 0x67,
+	0x70, 0x52, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x41, 0x73, 0x6e, 0x12, 0x20, 0x0a, 0x08, 0x62, 0x67,
+	0x70, 0x5f, 0x70, 0x65, 0x65, 0x72, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x48,
+	0x6f, 0x73, 0x74, 0x52, 0x07, 0x62, 0x67, 0x70, 0x50, 0x65, 0x65, 0x72, 0x12, 0x22, 0x0a, 0x07,
+	0x76, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x08, 0x2e,
+	0x56, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x52, 0x07, 0x76, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72,
+	0x12, 0x19, 0x0a, 0x04, 0x76, 0x6c, 0x61, 0x6e, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x05,
+	0x2e, 0x56, 0x6c, 0x61, 0x6e, 0x52, 0x04, 0x76, 0x6c, 0x61, 0x6e, 0x12, 0x4a, 0x0a, 0x15, 0x6d,
+	0x69, 0x73, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x65, 0x64, 0x5f, 0x76, 0x73, 0x65,
+	0x72, 0x76, 0x65, 0x72, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x4d, 0x69, 0x73,
+	0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x65, 0x64, 0x56, 0x73, 0x65, 0x72, 0x76, 0x65,
+	0x72, 0x52, 0x14, 0x6d, 0x69, 0x73, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x65, 0x64,
+	0x56, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x12, 0x25, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64,
+	0x61, 0x74, 0x61, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x4d, 0x65, 0x74, 0x61,
+	0x64, 0x61, 0x74, 0x61, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x30,
+	0x0a, 0x14, 0x64, 0x65, 0x64, 0x69, 0x63, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x76, 0x69, 0x70, 0x5f,
+	0x73, 0x75, 0x62, 0x6e, 0x65, 0x74, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x09, 0x52, 0x12, 0x64, 0x65,
+	0x64, 0x69, 0x63, 0x61, 0x74, 0x65, 0x64, 0x56, 0x69, 0x70, 0x53, 0x75, 0x62, 0x6e, 0x65, 0x74,
+	0x12, 0x31, 0x0a, 0x0d, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x5f, 0x67, 0x72, 0x6f, 0x75, 0x70,
+	0x73, 0x18, 0x0c, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73,
+	0x47, 0x72, 0x6f, 0x75, 0x70, 0x52, 0x0c, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x47, 0x72, 0x6f,
+	0x75, 0x70, 0x73, 0x2a, 0x1c, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12,
+	0x07, 0x0a, 0x03, 0x54, 0x43, 0x50, 0x10, 0x01, 0x12, 0x07, 0x0a, 0x03, 0x55, 0x44, 0x50, 0x10,
+	0x02, 0x42, 0x24, 0x5a, 0x22, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x73, 0x65, 0x65, 0x73, 0x61, 0x77, 0x2f, 0x70, 0x62,
+	0x2f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
 }
 
 var (